@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/selfcheck"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// runCheck implements `indexer check`: it probes every external dependency,
+// prints a table and returns the process exit code.
+func runCheck(cfg config.Config) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	pool, poolErr := store.NewPool(ctx, cfg.DBDSN)
+	if pool != nil {
+		defer pool.Close()
+	}
+	var replicaErr error
+	if cfg.DBReplicaDSN != "" {
+		replica, err := store.NewPool(ctx, cfg.DBReplicaDSN)
+		if replica != nil {
+			defer replica.Close()
+		}
+		replicaErr = err
+	}
+	errNoDB := errors.New("database unavailable")
+
+	deps := selfcheck.Deps{
+		PingDB:      func(context.Context) error { return poolErr },
+		PingReplica: func(context.Context) error { return replicaErr },
+		CheckSchema: func(ctx context.Context) error {
+			if pool == nil {
+				return errNoDB
+			}
+			return store.CheckSchema(ctx, pool)
+		},
+		DialChain: func(ctx context.Context, rpcURL string, ch config.ChainConfig) (selfcheck.ChainProbe, error) {
+			return chain.DialRPC(ctx, rpcURL, ch)
+		},
+		LookupHost: net.DefaultResolver.LookupHost,
+	}
+
+	results := selfcheck.Run(ctx, selfcheck.Checks(cfg, deps), 15*time.Second)
+	selfcheck.WriteTable(os.Stdout, results)
+
+	if failed := selfcheck.Failed(results); len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d check(s) failed:\n", len(failed))
+		for _, r := range failed {
+			fmt.Fprintf(os.Stderr, "  - %s: %s\n", r.Name, r.Detail)
+		}
+		return 1
+	}
+	return 0
+}
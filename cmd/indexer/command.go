@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const usage = `usage: indexer [command]
+
+commands:
+  serve [--skip-migrations]  run the indexer (default); --skip-migrations
+                             expects the schema to be migrated already
+  migrate                    apply pending migrations and exit
+  check                      verify the deployment and exit`
+
+// command is a parsed command line.
+type command struct {
+	name           string // "serve", "migrate" or "check"
+	skipMigrations bool
+}
+
+// parseCommand parses the arguments after the program name. No command, or
+// flags alone, means serve.
+func parseCommand(args []string) (command, error) {
+	cmd := command{name: "serve"}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd.name, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	switch cmd.name {
+	case "serve":
+		fs.BoolVar(&cmd.skipMigrations, "skip-migrations", false, "")
+	case "migrate", "check":
+	default:
+		return command{}, fmt.Errorf("unknown command %q", cmd.name)
+	}
+	if err := fs.Parse(args); err != nil {
+		return command{}, fmt.Errorf("%s: %w", cmd.name, err)
+	}
+	if fs.NArg() > 0 {
+		return command{}, fmt.Errorf("%s: unexpected argument %q", cmd.name, fs.Arg(0))
+	}
+	return cmd, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want command
+		err  string
+	}{
+		{nil, command{name: "serve"}, ""},
+		{[]string{"serve"}, command{name: "serve"}, ""},
+		{[]string{"serve", "--skip-migrations"}, command{name: "serve", skipMigrations: true}, ""},
+		{[]string{"--skip-migrations"}, command{name: "serve", skipMigrations: true}, ""},
+		{[]string{"migrate"}, command{name: "migrate"}, ""},
+		{[]string{"check"}, command{name: "check"}, ""},
+		{[]string{"migrate", "--skip-migrations"}, command{}, "migrate: flag provided but not defined"},
+		{[]string{"serve", "extra"}, command{}, `unexpected argument "extra"`},
+		{[]string{"--verbose"}, command{}, "serve: flag provided but not defined"},
+		{[]string{"deploy"}, command{}, `unknown command "deploy"`},
+	} {
+		got, err := parseCommand(tc.args)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%q: err = %v, want %q", tc.args, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%q = %+v, %v; want %+v", tc.args, got, err, tc.want)
+		}
+	}
+}
@@ -2,23 +2,60 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/AgentMesh-Net/indexer-go/internal/api"
 	"github.com/AgentMesh-Net/indexer-go/internal/chain"
 	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/deadline"
+	"github.com/AgentMesh-Net/indexer-go/internal/memlimit"
 	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/telemetry"
+	"github.com/AgentMesh-Net/indexer-go/internal/verifypool"
 	"github.com/AgentMesh-Net/indexer-go/migrations"
 )
 
 func main() {
+	cmd, err := parseCommand(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s\n", err, usage)
+		os.Exit(2)
+	}
 	cfg := config.Load()
 
+	switch cmd.name {
+	case "check":
+		os.Exit(runCheck(cfg))
+	case "migrate":
+		os.Exit(runMigrate(cfg))
+	}
+	serve(cfg, cmd.skipMigrations)
+}
+
+// serve runs the indexer until SIGINT or SIGTERM. Unless skipMigrations is
+// set it applies pending migrations first; with it, pending migrations are
+// fatal, since the schema is expected to be migrated by `indexer migrate`.
+func serve(cfg config.Config, skipMigrations bool) {
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if cfg.DevMode {
+		log.Printf("WARNING: AMN_DEV_MODE is on: /v1/dev signing helpers are mounted; never run this in production")
+	}
+	for _, ch := range cfg.SupportedChains {
+		if ch.RPCInsecureSkipVerify {
+			log.Printf("WARNING: chain %d: RPC TLS certificate verification is disabled (rpc_insecure_skip_verify)", ch.ChainID)
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -28,22 +65,53 @@ func main() {
 	}
 	defer pool.Close()
 
-	for _, migFile := range []string{"001_init.sql", "002_tasks.sql", "003_onchain_sync.sql"} {
-		migrationSQL, err := migrations.FS.ReadFile(migFile)
+	// Reads on GET requests may use the replica; see store.AllowReplica.
+	readPool := pool
+	if cfg.DBReplicaDSN != "" {
+		readPool, err = store.NewPool(ctx, cfg.DBReplicaDSN)
+		if err != nil {
+			log.Fatalf("replica database connection failed: %v", err)
+		}
+		defer readPool.Close()
+		log.Printf("read replica enabled for GET requests")
+	}
+
+	if skipMigrations {
+		pending, err := migrations.NewRunner().Pending(ctx, pool)
 		if err != nil {
-			log.Fatalf("read migration file %s: %v", migFile, err)
+			log.Fatalf("check migrations: %v", err)
 		}
-		if err := store.RunMigrations(ctx, pool, string(migrationSQL)); err != nil {
-			log.Fatalf("migration %s failed: %v", migFile, err)
+		if len(pending) > 0 {
+			log.Fatalf("--skip-migrations: %d migration(s) not applied (%s); run `indexer migrate` first",
+				len(pending), strings.Join(pending, ", "))
 		}
-		log.Printf("migration %s applied", migFile)
+	} else if _, err := migrations.NewRunner().Apply(ctx, pool); err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	repo := store.NewPostgresRepo(pool)
-	taskRepo := store.NewPostgresTaskRepo(pool)
-	router := api.NewRouter(repo, taskRepo, cfg)
+	if n, err := store.RecoverStuckTasks(ctx, pool); err != nil {
+		log.Printf("stuck task recovery failed: %v", err)
+	} else if n > 0 {
+		log.Printf("recovered %d stuck task(s)", n)
+	}
+
+	var repo store.Repo = store.NewPostgresRepo(pool).WithReplica(readPool)
+	var ingest *store.QueuedRepo
+	if cfg.IngestAsync {
+		ingest = store.NewQueuedRepo(repo, cfg.IngestWorkers, cfg.IngestQueueSize, cfg.IngestBatchSize)
+		ingest.RegisterMetrics()
+		repo = ingest
+		log.Printf("async object ingestion enabled: %d workers, queue %d, batch %d",
+			cfg.IngestWorkers, cfg.IngestQueueSize, cfg.IngestBatchSize)
+	}
+	taskRepo := store.NewHookedTaskRepo(store.NewPostgresTaskRepo(pool).WithReplica(readPool))
+
+	// Config is only read at startup, so a chain dropped from
+	// AMN_SUPPORTED_CHAINS_JSON gets no watcher below; flag its open tasks.
+	markRetiredChains(ctx, cfg, taskRepo)
 
 	// B4: Start one watcher goroutine per configured chain
+	var watchers []*chain.Watcher
 	for _, chainCfg := range cfg.SupportedChains {
 		rpcURL, ok := cfg.RPCURLs[chainCfg.ChainID]
 		if !ok || rpcURL == "" {
@@ -55,26 +123,62 @@ func main() {
 			log.Printf("failed to create watcher for chain %d: %v — skipping", chainCfg.ChainID, err)
 			continue
 		}
-		go w.Run(ctx)
-		log.Printf("chain watcher started for chain=%d contract=%s", chainCfg.ChainID, chainCfg.SettlementContract)
-	}
-
-	srv := &http.Server{
-		Addr:              cfg.HTTPAddr,
-		Handler:           router,
-		ReadHeaderTimeout: 10 * time.Second,
-		WriteTimeout:      30 * time.Second,
-		IdleTimeout:       60 * time.Second,
-		MaxHeaderBytes:    1 << 20, // 1MB
+		watchers = append(watchers, w)
 	}
-
+	// Watchers only start once the schema is in place: with
+	// --skip-migrations, or after a partly failed migration, every event
+	// would otherwise fail against missing tables.
 	go func() {
-		log.Printf("indexer listening on %s", cfg.HTTPAddr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %v", err)
+		if !waitForSchema(ctx, func(ctx context.Context) error { return store.SchemaReady(ctx, pool) }, time.Second, time.Minute) {
+			return
+		}
+		for _, w := range watchers {
+			go w.Run(ctx)
+			log.Printf("chain watcher started for chain=%d", w.ChainID())
 		}
 	}()
 
+	chain.RegisterMetrics(watchers)
+	verifypool.RegisterMetrics()
+
+	memlimit.Default.SetLimits(cfg.CacheMemoryBudget, cfg.HeapSoftLimit)
+	memlimit.RegisterMetrics()
+	go memlimit.Default.Run(ctx, memlimit.Interval)
+
+	// Reads and writes share one listener unless AMN_HTTP_WRITE_ADDR splits
+	// writes, admin, metrics and pprof onto a second one.
+	var servers []*http.Server
+	if cfg.HTTPWriteAddr == "" {
+		servers = append(servers, newServer(cfg.HTTPAddr, api.NewRouter(repo, taskRepo, cfg, watchers)))
+	} else {
+		public, private := api.NewSplitRouters(repo, taskRepo, cfg, watchers)
+		servers = append(servers, newServer(cfg.HTTPAddr, public), newServer(cfg.HTTPWriteAddr, private))
+	}
+
+	if len(cfg.DeadlineWarnings) > 0 {
+		go deadline.NewNotifier(taskRepo, cfg.DeadlineWarnings, taskRepo.Emit).Run(ctx, cfg.DeadlineScanInterval)
+		log.Printf("deadline notifier enabled: windows %v, scan every %s", cfg.DeadlineWarnings, cfg.DeadlineScanInterval)
+	}
+
+	if cfg.UnfundedAcceptTimeout > 0 {
+		go deadline.NewReverter(taskRepo, cfg.UnfundedAcceptTimeout).Run(ctx, cfg.DeadlineScanInterval)
+		log.Printf("unfunded accept revert enabled: after %s, scan every %s", cfg.UnfundedAcceptTimeout, cfg.DeadlineScanInterval)
+	}
+
+	if cfg.TelemetryURL != "" {
+		go telemetry.NewReporter(cfg, taskRepo, repo).Run(ctx)
+		log.Printf("telemetry reporter enabled: %s every %s", cfg.TelemetryURL, cfg.TelemetryInterval)
+	}
+
+	for _, srv := range servers {
+		go func() {
+			log.Printf("indexer listening on %s", srv.Addr)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("listen: %v", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -82,8 +186,77 @@ func main() {
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("shutdown: %v", err)
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				log.Printf("shutdown %s: %v", srv.Addr, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if ingest != nil {
+		ingest.Close()
+		log.Println("ingestion queue drained")
 	}
 	log.Println("server stopped")
 }
+
+// waitForSchema runs check until it passes, backing off from minBackoff to
+// maxBackoff between attempts. It returns false if ctx is cancelled first.
+func waitForSchema(ctx context.Context, check func(context.Context) error, minBackoff, maxBackoff time.Duration) bool {
+	backoff := minBackoff
+	for {
+		err := check(ctx)
+		if err == nil {
+			return true
+		}
+		log.Printf("chain watchers waiting for the database schema: %v — retrying in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// newServer returns an HTTP server for handler on addr with the indexer's
+// timeouts and header limit.
+func newServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    1 << 20, // 1MB
+	}
+}
+
+// markRetiredChains flags the open tasks on chains that are no longer
+// configured, and clears the flag on chains configured again.
+func markRetiredChains(ctx context.Context, cfg config.Config, tasks store.TaskRepo) {
+	chains, err := tasks.ListOpenTaskChains(ctx)
+	if err != nil {
+		log.Printf("retired chain check failed: %v", err)
+		return
+	}
+	for _, id := range chains {
+		if _, ok := cfg.Chain(id); ok {
+			continue
+		}
+		if n, err := tasks.SetChainRetired(ctx, id, true); err != nil {
+			log.Printf("flag retired chain %d: %v", id, err)
+		} else if n > 0 {
+			log.Printf("chain %d is no longer supported: flagged %d open task(s)", id, n)
+		}
+	}
+	for _, ch := range cfg.SupportedChains {
+		if _, err := tasks.SetChainRetired(ctx, ch.ChainID, false); err != nil {
+			log.Printf("clear retired chain %d: %v", ch.ChainID, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForSchema(t *testing.T) {
+	calls := 0
+	check := func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("missing tables unknown_logs")
+		}
+		return nil
+	}
+	if !waitForSchema(context.Background(), check, time.Millisecond, 2*time.Millisecond) || calls != 3 {
+		t.Errorf("calls = %d, want ready on the third check", calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	never := func(context.Context) error { return errors.New("missing tables tasks") }
+	if waitForSchema(ctx, never, time.Hour, time.Hour) {
+		t.Error("waitForSchema reported ready after cancel")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/migrations"
+)
+
+// runMigrate implements `indexer migrate`: it applies pending migrations to
+// DB_DSN, prints the ones it ran and returns the process exit code.
+func runMigrate(cfg config.Config) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	pool, err := store.NewPool(ctx, cfg.DBDSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "database connection failed: %v\n", err)
+		return 1
+	}
+	defer pool.Close()
+
+	runner := migrations.NewRunner()
+	runner.Logf = nil
+	applied, err := runner.Apply(ctx, pool)
+	for _, name := range applied {
+		fmt.Printf("applied %s\n", name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if len(applied) == 0 {
+		fmt.Println("schema is up to date")
+	}
+	return 0
+}
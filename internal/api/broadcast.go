@@ -0,0 +1,348 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+const (
+	feedSendBuffer   = 64
+	feedWriteTimeout = 10 * time.Second
+	feedPongTimeout  = 60 * time.Second
+	feedPingInterval = 30 * time.Second
+)
+
+// ErrFeedFull is returned by Subscribe when MaxFeedClients are connected.
+var ErrFeedFull = errors.New("feed client limit reached")
+
+// Feed namespaces a client can select with namespace=. Task events are the
+// default; indexer events (indexer.chain_lagging, ...) are opt-in so clients
+// that only expect tasks never see them.
+const (
+	feedNamespaceTask    = "task"
+	feedNamespaceIndexer = "indexer"
+	feedNamespaceAll     = "all"
+)
+
+// feedFilter selects the events a feed client receives. Zero values match
+// every task event. Status and EmployerAddress apply to task events only.
+type feedFilter struct {
+	Namespace       string
+	ChainID         int
+	Status          string
+	EmployerAddress string
+}
+
+func (f feedFilter) match(m feedMessage) bool {
+	if m.Task == nil {
+		if f.Namespace != feedNamespaceIndexer && f.Namespace != feedNamespaceAll {
+			return false
+		}
+		return f.ChainID == 0 || m.ChainID == f.ChainID
+	}
+	if f.Namespace == feedNamespaceIndexer {
+		return false
+	}
+	t := m.Task
+	if f.ChainID != 0 && t.ChainID != f.ChainID {
+		return false
+	}
+	if f.Status != "" && t.Status != f.Status {
+		return false
+	}
+	if f.EmployerAddress != "" && !strings.EqualFold(t.EmployerAddress, f.EmployerAddress) {
+		return false
+	}
+	return true
+}
+
+func parseFeedFilter(q url.Values) (feedFilter, error) {
+	var f feedFilter
+	switch f.Namespace = q.Get("namespace"); f.Namespace {
+	case "", feedNamespaceTask, feedNamespaceIndexer, feedNamespaceAll:
+	default:
+		return f, errors.New("namespace must be task, indexer or all")
+	}
+	if s := q.Get("chain_id"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return f, errors.New("chain_id must be a positive integer")
+		}
+		f.ChainID = n
+	}
+	f.Status = q.Get("status")
+	if s := q.Get("employer_address"); s != "" {
+		if !reHexAddr.MatchString(s) {
+			return f, errors.New("employer_address must be a 0x address")
+		}
+		f.EmployerAddress = s
+	}
+	return f, nil
+}
+
+// feedClient is one connected feed subscriber. Messages are queued on send;
+// the broadcaster closes send when the client is dropped.
+type feedClient struct {
+	filter feedFilter
+	redact bool
+	send   chan []byte
+}
+
+// feedMessage is one encoded feed event. Redacted is sent to clients that
+// must not see full addresses. Task is nil for an indexer event, which is
+// about ChainID.
+type feedMessage struct {
+	Task     *store.Task
+	ChainID  int
+	Plain    []byte
+	Redacted []byte
+}
+
+// FeedBroadcaster fans task events out to every connected feed client. Each
+// client has its own buffered channel; a client that falls behind by more
+// than the buffer is dropped rather than slowing down the publisher.
+type FeedBroadcaster struct {
+	max int
+
+	mu      sync.Mutex
+	clients map[*feedClient]struct{}
+}
+
+// NewFeedBroadcaster creates a broadcaster accepting at most max clients.
+// max <= 0 means unlimited.
+func NewFeedBroadcaster(max int) *FeedBroadcaster {
+	return &FeedBroadcaster{max: max, clients: make(map[*feedClient]struct{})}
+}
+
+// Subscribe registers a client. It returns ErrFeedFull when the client
+// limit has been reached.
+func (b *FeedBroadcaster) Subscribe(f feedFilter, redact bool) (*feedClient, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.max > 0 && len(b.clients) >= b.max {
+		return nil, ErrFeedFull
+	}
+	c := &feedClient{filter: f, redact: redact, send: make(chan []byte, feedSendBuffer)}
+	b.clients[c] = struct{}{}
+	return c, nil
+}
+
+// Unsubscribe removes c and closes its channel. It is safe to call more than
+// once.
+func (b *FeedBroadcaster) Unsubscribe(c *feedClient) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(c)
+}
+
+func (b *FeedBroadcaster) drop(c *feedClient) {
+	if _, ok := b.clients[c]; ok {
+		delete(b.clients, c)
+		close(c.send)
+	}
+}
+
+// Clients returns the number of connected clients.
+func (b *FeedBroadcaster) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+// Publish queues m for every client whose filter matches m. It never
+// blocks.
+func (b *FeedBroadcaster) Publish(m feedMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for c := range b.clients {
+		if !c.filter.match(m) {
+			continue
+		}
+		msg := m.Plain
+		if c.redact {
+			msg = m.Redacted
+		}
+		select {
+		case c.send <- msg:
+		default:
+			log.Printf("[feed] dropping slow client")
+			b.drop(c)
+		}
+	}
+}
+
+// feedEvent is the wire shape of one feed message. RemainingSeconds is set
+// only on task_deadline_approaching events.
+type feedEvent struct {
+	// ID is stable for one logical event; see feedEventID.
+	ID               string       `json:"id"`
+	Event            string       `json:"event"`
+	Task             taskResponse `json:"task"`
+	RemainingSeconds *int64       `json:"remaining_seconds,omitempty"`
+}
+
+// feedEventID returns the id of event on t, which happened at at. Events
+// caused by a contract log are chain_id:tx_hash:log_index:event, the same
+// however often the log is applied; others are task:task_id:event:at.
+func feedEventID(ctx context.Context, event string, t *store.Task, at time.Time) string {
+	if src, ok := store.EventSourceFrom(ctx); ok {
+		return src.EventID(event)
+	}
+	return fmt.Sprintf("task:%s:%s:%d", t.TaskID, event, at.UnixNano())
+}
+
+// publishTransition is the store.TransitionHook that feeds the broadcaster.
+// Private tasks are not published, nor is an event whose id was published
+// within the dedup window (AMN_FEED_DEDUP_SIZE, AMN_FEED_DEDUP_TTL_SECONDS).
+func (h *handlers) publishTransition(ctx context.Context, event string, t *store.Task) {
+	if t.Visibility == store.TaskVisibilityPrivate {
+		return
+	}
+	var remaining *int64
+	at := t.UpdatedAt
+	if event == store.TaskEventDeadlineApproaching {
+		now := time.Now()
+		secs := t.DeadlineUnix - now.Unix()
+		remaining, at = &secs, now
+	}
+	id := feedEventID(ctx, event, t, at)
+	if h.feedSeen != nil && !h.feedSeen.add(id) {
+		feedEventsSuppressed.Inc()
+		return
+	}
+	plain, err := h.encodeFeedEvent(feedEvent{ID: id, Event: event, Task: h.renderTask(t, false), RemainingSeconds: remaining}, at)
+	if err != nil {
+		log.Printf("[feed] encode %s: %v", t.TaskID, err)
+		return
+	}
+	redacted, err := h.encodeFeedEvent(feedEvent{ID: id, Event: event, Task: h.renderTask(t, true), RemainingSeconds: remaining}, at)
+	if err != nil {
+		log.Printf("[feed] encode %s: %v", t.TaskID, err)
+		return
+	}
+	h.feed.Publish(feedMessage{Task: t, Plain: plain, Redacted: redacted})
+}
+
+// indexerEvent is the wire shape of an indexer.* feed message: the indexer
+// reporting on itself rather than on a task.
+type indexerEvent struct {
+	// ID is chain.LagAlarm.ID: one id per event and lagging episode.
+	ID    string        `json:"id"`
+	Event string        `json:"event"`
+	Chain chainLagEvent `json:"chain"`
+}
+
+// chainLagEvent is the chain state behind an indexer.chain_lagging or
+// indexer.chain_recovered.
+type chainLagEvent struct {
+	ChainID         int       `json:"chain_id"`
+	HeadBlock       uint64    `json:"head_block"`
+	SyncedBlock     uint64    `json:"synced_block"`
+	LagBlocks       uint64    `json:"lag_blocks"`
+	ThresholdBlocks int       `json:"threshold_blocks"`
+	LaggingSince    time.Time `json:"lagging_since"`
+	At              time.Time `json:"at"`
+}
+
+// publishLagAlarm is the chain.LagHook that feeds the broadcaster. Like
+// task events, an id published within the dedup window is not published
+// again.
+func (h *handlers) publishLagAlarm(a chain.LagAlarm) {
+	id := a.ID()
+	if h.feedSeen != nil && !h.feedSeen.add(id) {
+		feedEventsSuppressed.Inc()
+		return
+	}
+	msg, err := h.encodeIndexerEvent(indexerEvent{ID: id, Event: a.Event, Chain: chainLagEvent{
+		ChainID: a.ChainID, HeadBlock: a.HeadBlock, SyncedBlock: a.SyncedBlock, LagBlocks: a.LagBlocks,
+		ThresholdBlocks: a.ThresholdBlocks, LaggingSince: a.Since.UTC(), At: a.At.UTC(),
+	}})
+	if err != nil {
+		log.Printf("[feed] encode %s: %v", id, err)
+		return
+	}
+	// Nothing to redact: the event carries no addresses.
+	h.feed.Publish(feedMessage{ChainID: a.ChainID, Plain: msg, Redacted: msg})
+}
+
+// The feed carries the same data as GET /v1/tasks, which is readable from any
+// origin, so cross-origin upgrades are allowed.
+var feedUpgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// ── GET /v1/ws/feed ──────────────────────────────────────────────────────────
+
+// GetFeed upgrades to a WebSocket and streams every event matching the
+// namespace, chain_id, status and employer_address query filters.
+func (h *handlers) GetFeed(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFeedFilter(r.URL.Query())
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	client, err := h.feed.Subscribe(filter, h.redactAddresses(r))
+	if err != nil {
+		util.WriteError(w, http.StatusServiceUnavailable, "feed_client_limit_exceeded", "too many feed clients connected")
+		return
+	}
+	conn, err := feedUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the error response.
+		h.feed.Unsubscribe(client)
+		return
+	}
+	defer conn.Close()
+
+	// The feed is send-only; reading handles pongs and notices disconnects.
+	go func() {
+		defer h.feed.Unsubscribe(client)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(feedPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(feedPongTimeout))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(feedPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case msg, ok := <-client.send:
+			conn.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				h.feed.Unsubscribe(client)
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				h.feed.Unsubscribe(client)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func dialFeed(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/ws/feed" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", query, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestFeed_StreamsMatchingTransitions(t *testing.T) {
+	repo := store.NewHookedTaskRepo(&acceptRepo{tasks: map[string]*store.Task{
+		"t-1": {TaskID: "t-1", ChainID: 11155111, Status: store.TaskStatusCreated, EmployerAddress: "0x1111111111111111111111111111111111111111"},
+		"t-2": {TaskID: "t-2", ChainID: 1, Status: store.TaskStatusCreated, EmployerAddress: "0x2222222222222222222222222222222222222222"},
+	}})
+	srv := httptest.NewServer(NewRouter(nil, repo, config.Config{}, nil))
+	defer srv.Close()
+
+	all := dialFeed(t, srv, "")
+	sepolia := dialFeed(t, srv, "?chain_id=11155111&status=accepted")
+
+	ctx := context.Background()
+	worker := "0x3333333333333333333333333333333333333333"
+	if err := repo.UpdateTaskWorker(ctx, "t-2", worker, store.TaskStatusAccepted); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateTaskWorker(ctx, "t-1", worker, store.TaskStatusAccepted); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(conn *websocket.Conn) feedEvent {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var ev feedEvent
+		if err := json.Unmarshal(msg, &ev); err != nil {
+			t.Fatalf("decode %s: %v", msg, err)
+		}
+		return ev
+	}
+
+	if ev := read(all); ev.Event != store.TaskEventAccepted || ev.Task.TaskID != "t-2" {
+		t.Errorf("unfiltered client: first event = %+v", ev)
+	}
+	if ev := read(all); ev.Task.TaskID != "t-1" || ev.Task.WorkerAddress != worker {
+		t.Errorf("unfiltered client: second event = %+v", ev)
+	}
+	if ev := read(sepolia); ev.Task.TaskID != "t-1" {
+		t.Errorf("filtered client got %+v, want only t-1", ev)
+	}
+}
+
+func TestFeed_RejectsBadFilterAndFullFeed(t *testing.T) {
+	repo := store.NewHookedTaskRepo(&acceptRepo{})
+	srv := httptest.NewServer(NewRouter(nil, repo, config.Config{MaxFeedClients: 1}, nil))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/ws/feed?employer_address=nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad filter: status %d, want 400", resp.StatusCode)
+	}
+
+	dialFeed(t, srv, "")
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/ws/feed"
+	_, resp, err = websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("second client connected past MaxFeedClients")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("full feed: response %v, want 503", resp)
+	}
+}
+
+func TestFeedBroadcaster_DropsSlowClient(t *testing.T) {
+	b := NewFeedBroadcaster(0)
+	c, err := b.Subscribe(feedFilter{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := &store.Task{TaskID: "t-1"}
+	for i := 0; i <= feedSendBuffer; i++ {
+		b.Publish(feedMessage{Task: task, Plain: []byte("{}")})
+	}
+	if n := b.Clients(); n != 0 {
+		t.Errorf("clients = %d, want slow client dropped", n)
+	}
+	b.Unsubscribe(c) // must not panic on an already dropped client
+}
+
+func TestFeed_DeadlineApproachingCarriesRemainingSeconds(t *testing.T) {
+	repo := store.NewHookedTaskRepo(&acceptRepo{})
+	srv := httptest.NewServer(NewRouter(nil, repo, config.Config{}, nil))
+	defer srv.Close()
+	conn := dialFeed(t, srv, "")
+
+	deadline := time.Now().Add(time.Hour).Unix()
+	repo.Emit(context.Background(), store.TaskEventDeadlineApproaching,
+		&store.Task{TaskID: "t-1", Status: store.TaskStatusAccepted, DeadlineUnix: deadline})
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var ev feedEvent
+	if err := conn.ReadJSON(&ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != store.TaskEventDeadlineApproaching || ev.RemainingSeconds == nil {
+		t.Fatalf("event = %+v", ev)
+	}
+	if r := *ev.RemainingSeconds; r < 3590 || r > 3600 {
+		t.Errorf("remaining_seconds = %d, want about 3600", r)
+	}
+}
+
+// releaseRepo serves one released task by hash for HookedTaskRepo to reload.
+type releaseRepo struct {
+	store.TaskRepo
+}
+
+func (releaseRepo) UpdateOnchainReleased(context.Context, int, string, string, time.Time) error {
+	return nil
+}
+
+func (releaseRepo) GetTaskByHash(_ context.Context, chainID int, taskHash string) (*store.Task, error) {
+	return &store.Task{TaskID: "t-1", TaskHash: taskHash, ChainID: chainID, Status: store.TaskStatusReleased}, nil
+}
+
+func TestFeed_SuppressesReprocessedChainEvents(t *testing.T) {
+	repo := store.NewHookedTaskRepo(releaseRepo{})
+	cfg := config.Config{FeedDedupSize: 16, FeedDedupTTL: time.Hour}
+	srv := httptest.NewServer(NewRouter(nil, repo, cfg, nil))
+	defer srv.Close()
+	conn := dialFeed(t, srv, "")
+
+	// The same Released log applied twice, as after a reorg or
+	// POST /v1/admin/reprocess-tx, then a different log.
+	tx := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	first := store.WithEventSource(context.Background(), store.EventSource{ChainID: 11155111, TxHash: tx, LogIndex: 2})
+	for range 2 {
+		if err := repo.UpdateOnchainReleased(first, 11155111, "0x01", tx, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := store.WithEventSource(context.Background(), store.EventSource{ChainID: 11155111, TxHash: tx, LogIndex: 3})
+	if err := repo.UpdateOnchainReleased(other, 11155111, "0x01", tx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for range 2 {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var ev feedEvent
+		if err := conn.ReadJSON(&ev); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ev.ID)
+	}
+	want := []string{"11155111:" + tx + ":2:released", "11155111:" + tx + ":3:released"}
+	if got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("event ids = %v, want %v", got, want)
+	}
+}
+
+func TestFeedFilter_Namespace(t *testing.T) {
+	task := feedMessage{Task: &store.Task{TaskID: "t-1", ChainID: 1, Status: store.TaskStatusCreated}}
+	lag := feedMessage{ChainID: 1}
+	cases := []struct {
+		query         string
+		task, indexer bool
+	}{
+		{"", true, false},
+		{"namespace=task", true, false},
+		{"namespace=indexer", false, true},
+		{"namespace=all", true, true},
+		{"namespace=all&chain_id=2", false, false},
+		// status applies to task events only.
+		{"namespace=all&status=released", false, true},
+	}
+	for _, tc := range cases {
+		q, _ := url.ParseQuery(tc.query)
+		f, err := parseFeedFilter(q)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.query, err)
+		}
+		if f.match(task) != tc.task || f.match(lag) != tc.indexer {
+			t.Errorf("%q: task %v indexer %v, want %v %v", tc.query, f.match(task), f.match(lag), tc.task, tc.indexer)
+		}
+	}
+	if _, err := parseFeedFilter(url.Values{"namespace": {"webhooks"}}); err == nil {
+		t.Error("unknown namespace accepted")
+	}
+}
+
+func TestPublishLagAlarm(t *testing.T) {
+	for _, format := range []string{"native", feedFormatCloudEvents} {
+		t.Run(format, func(t *testing.T) {
+			h := &handlers{
+				cfg:      config.Config{FeedFormat: format, IndexerBaseURL: "https://indexer.example"},
+				feed:     NewFeedBroadcaster(0),
+				feedSeen: newRecentIDs(16, time.Hour),
+			}
+			tasksOnly, _ := h.feed.Subscribe(feedFilter{}, false)
+			indexer, _ := h.feed.Subscribe(feedFilter{Namespace: feedNamespaceIndexer}, true)
+
+			since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			lagging := chain.LagAlarm{Event: chain.EventChainLagging, ChainID: 11155111, HeadBlock: 1100, SyncedBlock: 1000,
+				LagBlocks: 100, ThresholdBlocks: 50, Since: since, At: since}
+			recovered := lagging
+			recovered.Event, recovered.SyncedBlock, recovered.LagBlocks, recovered.ThresholdBlocks, recovered.At =
+				chain.EventChainRecovered, 1090, 10, 25, since.Add(time.Minute)
+			h.publishLagAlarm(lagging)
+			h.publishLagAlarm(lagging) // same episode: suppressed
+			h.publishLagAlarm(recovered)
+
+			if n := len(tasksOnly.send); n != 0 {
+				t.Errorf("task-only client got %d indexer events", n)
+			}
+			if n := len(indexer.send); n != 2 {
+				t.Fatalf("indexer client got %d events, want 2", n)
+			}
+			var got []indexerEvent
+			for range 2 {
+				msg := <-indexer.send
+				var ev indexerEvent
+				if format == feedFormatCloudEvents {
+					var ce cloudEvent[indexerEvent]
+					if err := json.Unmarshal(msg, &ce); err != nil {
+						t.Fatal(err)
+					}
+					if ce.Type != "net.agentmesh."+ce.Data.Event || ce.Subject != "chain/11155111" || ce.ID != ce.Data.ID {
+						t.Errorf("cloudevent = %+v", ce)
+					}
+					ev = ce.Data
+				} else if err := json.Unmarshal(msg, &ev); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, ev)
+			}
+			if got[0].Event != "indexer.chain_lagging" || got[0].Chain.LagBlocks != 100 || got[0].Chain.ThresholdBlocks != 50 ||
+				got[1].Event != "indexer.chain_recovered" || got[1].Chain.LagBlocks != 10 ||
+				!got[1].Chain.LaggingSince.Equal(since) || got[0].ID == got[1].ID {
+				t.Errorf("events = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/ratelimit"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// maxClientBuckets bounds the per-IP and per-token buckets held in memory.
+const maxClientBuckets = 100000
+
+// clientTokenKey is the context key under which identifyClient stores the
+// request's valid bearer token ("" for anonymous requests).
+type clientTokenKey struct{}
+
+// identifyClient checks the bearer token once and stores the result in the
+// request context for limitClients and clientToken.
+func (h *handlers) identifyClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientTokenKey{}, h.clientToken(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// limitClients rate-limits requests per bearer token when one is valid and
+// per client IP otherwise, so authenticated partners get their own, usually
+// larger, quota (AMN_TOKEN_RATE_LIMIT_PER_MINUTE) instead of sharing their
+// IP's (AMN_IP_RATE_LIMIT_PER_MINUTE). Health checks, metrics and task
+// previews, which external sites embed, are never limited.
+func (h *handlers) limitClients(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1/health") || r.URL.Path == "/metrics" || isTaskPreview(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var limiter *ratelimit.Keyed
+		var key string
+		if token := h.clientToken(r); token != "" {
+			limiter, key = h.tokenLimiter, token
+		} else {
+			limiter, key = h.ipLimiter, clientIP(r)
+		}
+		if limiter != nil && !limiter.Allow(key) {
+			w.Header().Set("Retry-After", "60")
+			util.WriteError(w, http.StatusTooManyRequests, "rate_limit_exceeded", "rate limit exceeded; retry later")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isTaskPreview reports whether r reads GET /v1/tasks/{id}/preview.
+func isTaskPreview(r *http.Request) bool {
+	id, ok := strings.CutPrefix(r.URL.Path, "/v1/tasks/")
+	if !ok || r.Method != http.MethodGet {
+		return false
+	}
+	id, ok = strings.CutSuffix(id, "/preview")
+	return ok && id != "" && !strings.Contains(id, "/")
+}
+
+// realIP replaces r.RemoteAddr with the client address a trusted proxy
+// forwarded (AMN_TRUSTED_PROXIES). X-Forwarded-For and X-Real-IP from any
+// other peer are ignored, so a client cannot pick its own rate limit bucket.
+func (h *handlers) realIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := forwardedClient(r, h.trustedProxies); ok {
+			r.RemoteAddr = ip.String()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedClient returns the client a trusted proxy forwarded r for. The
+// peer must be trusted. X-Forwarded-For is read from the right, skipping
+// hops that are trusted proxies themselves, since entries further left are
+// whatever the client sent; without it X-Real-IP is used.
+func forwardedClient(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	isTrusted := func(a netip.Addr) bool {
+		return slices.ContainsFunc(trusted, func(p netip.Prefix) bool { return p.Contains(a.Unmap()) })
+	}
+	peer, err := netip.ParseAddr(clientIP(r))
+	if err != nil || !isTrusted(peer) {
+		return netip.Addr{}, false
+	}
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		a, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if !isTrusted(a) {
+			return a.Unmap(), true
+		}
+	}
+	if a, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return a.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+// clientIP returns the request's client address without the port. realIP
+// has already applied a trusted proxy's X-Forwarded-For / X-Real-IP.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
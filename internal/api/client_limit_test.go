@@ -0,0 +1,153 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+func TestLimitClients_TokenAndIPBuckets(t *testing.T) {
+	router := NewRouter(nil, nil, config.Config{
+		APITokens:               []string{"partner"},
+		IPRateLimitPerMinute:    2,
+		TokenRateLimitPerMinute: 4,
+	}, nil)
+
+	get := func(ip, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/meta", nil)
+		req.RemoteAddr = ip + ":1234"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Anonymous requests share their IP's bucket.
+	for i := 0; i < 2; i++ {
+		if code := get("192.0.2.1", ""); code != http.StatusOK {
+			t.Fatalf("anonymous #%d: status %d", i+1, code)
+		}
+	}
+	if code := get("192.0.2.1", ""); code != http.StatusTooManyRequests {
+		t.Fatalf("anonymous over limit: status %d, want 429", code)
+	}
+	// An invalid token does not escape the IP bucket.
+	if code := get("192.0.2.1", "guess"); code != http.StatusTooManyRequests {
+		t.Errorf("invalid token: status %d, want 429", code)
+	}
+	if code := get("192.0.2.2", ""); code != http.StatusOK {
+		t.Errorf("other IP: status %d, want 200", code)
+	}
+
+	// The partner token has its own, larger bucket wherever it comes from.
+	for i, ip := range []string{"192.0.2.1", "198.51.100.7", "192.0.2.1", "198.51.100.7"} {
+		if code := get(ip, "partner"); code != http.StatusOK {
+			t.Fatalf("token #%d: status %d", i+1, code)
+		}
+	}
+	if code := get("203.0.113.9", "partner"); code != http.StatusTooManyRequests {
+		t.Errorf("token over limit: status %d, want 429", code)
+	}
+
+	// Health checks are never limited.
+	req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code == http.StatusTooManyRequests {
+		t.Error("health check was rate limited")
+	}
+
+	// Neither are task previews, but other task reads are.
+	for path, wantLimited := range map[string]bool{
+		"/v1/tasks/t1/preview":   false,
+		"/v1/tasks/t1":           true,
+		"/v1/tasks/t1/x/preview": true,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if limited := rec.Code == http.StatusTooManyRequests; limited != wantLimited {
+			t.Errorf("%s: status %d, want limited=%v", path, rec.Code, wantLimited)
+		}
+	}
+}
+
+func TestLimitClients_ForwardedHeadersOnlyFromTrustedProxies(t *testing.T) {
+	router := NewRouter(nil, nil, config.Config{
+		IPRateLimitPerMinute: 1,
+		TrustedProxies:       []string{"10.0.0.0/8"},
+	}, nil)
+	get := func(peer string, header ...string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/meta", nil)
+		req.RemoteAddr = peer + ":1234"
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Add(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A direct client cannot pick a fresh bucket with forwarded headers.
+	if code := get("192.0.2.1"); code != http.StatusOK {
+		t.Fatalf("first request: status %d", code)
+	}
+	for i, h := range [][]string{
+		{"X-Forwarded-For", "198.51.100.1"},
+		{"X-Real-IP", "198.51.100.2"},
+		{"X-Forwarded-For", "198.51.100.3, 10.0.0.1"},
+	} {
+		if code := get("192.0.2.1", h...); code != http.StatusTooManyRequests {
+			t.Errorf("spoofed %v (#%d): status %d, want 429", h, i, code)
+		}
+	}
+
+	// Behind a trusted proxy the client is the right-most untrusted hop;
+	// entries the client prepended do not change it.
+	if code := get("10.0.0.1", "X-Forwarded-For", "198.51.100.9"); code != http.StatusOK {
+		t.Fatalf("proxied client: status %d", code)
+	}
+	if code := get("10.0.0.2", "X-Forwarded-For", "203.0.113.5, 198.51.100.9, 10.0.0.1"); code != http.StatusTooManyRequests {
+		t.Errorf("proxied client with prepended hop: status %d, want 429", code)
+	}
+	if code := get("10.0.0.1", "X-Real-IP", "198.51.100.10"); code != http.StatusOK {
+		t.Errorf("X-Real-IP from proxy: status %d, want 200", code)
+	}
+}
+
+func TestForwardedClient(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+	cases := []struct {
+		peer, xff, realIP string
+		want              string // "" = not forwarded
+	}{
+		{"192.0.2.1:1", "198.51.100.1", "", ""},
+		{"10.0.0.1:1", "198.51.100.1", "", "198.51.100.1"},
+		{"10.0.0.1:1", "198.51.100.1, 10.1.1.1", "", "198.51.100.1"},
+		{"10.0.0.1:1", "10.2.2.2", "198.51.100.4", "198.51.100.4"},
+		{"10.0.0.1:1", "not-an-ip, 198.51.100.1", "", "198.51.100.1"},
+		{"10.0.0.1:1", "198.51.100.1, garbage", "", ""},
+		{"[fd00::1]:1", "2001:db8::7", "", "2001:db8::7"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.peer
+		if tc.xff != "" {
+			req.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		if tc.realIP != "" {
+			req.Header.Set("X-Real-IP", tc.realIP)
+		}
+		got, ok := forwardedClient(req, trusted)
+		if (tc.want == "") == ok || (ok && got.String() != tc.want) {
+			t.Errorf("%s %q %q: got %v %v, want %q", tc.peer, tc.xff, tc.realIP, got, ok, tc.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// feedFormatCloudEvents is the AMN_FEED_FORMAT value selecting CloudEvents.
+const feedFormatCloudEvents = "cloudevents"
+
+// cloudEventTypePrefix is prepended to the task event name, so a release is
+// net.agentmesh.task.released. Indexer events keep their own indexer.
+// prefix under cloudEventNamespace: net.agentmesh.indexer.chain_lagging.
+const (
+	cloudEventTypePrefix = "net.agentmesh.task."
+	cloudEventNamespace  = "net.agentmesh."
+)
+
+// cloudEvent is a feed event in CloudEvents 1.0 structured JSON mode. Data is
+// the native feed message, so both formats carry the same content.
+type cloudEvent[D any] struct {
+	SpecVersion     string `json:"specversion"`
+	Type            string `json:"type"`
+	Source          string `json:"source"`
+	ID              string `json:"id"`
+	Time            string `json:"time"`
+	Subject         string `json:"subject"`
+	DataContentType string `json:"datacontenttype"`
+	Data            D      `json:"data"`
+}
+
+// newCloudEvent wraps ev, which happened at at, as a CloudEvent from source.
+// The id is the feed event id, so consumers can deduplicate on it.
+func newCloudEvent(source string, ev feedEvent, at time.Time) cloudEvent[feedEvent] {
+	return cloudEvent[feedEvent]{
+		SpecVersion:     "1.0",
+		Type:            cloudEventTypePrefix + strings.TrimPrefix(ev.Event, "task_"),
+		Source:          source,
+		ID:              ev.ID,
+		Time:            at.UTC().Format(time.RFC3339Nano),
+		Subject:         ev.Task.TaskID,
+		DataContentType: "application/json",
+		Data:            ev,
+	}
+}
+
+// newIndexerCloudEvent wraps ev like newCloudEvent. The subject is the chain,
+// chain/<chain_id>.
+func newIndexerCloudEvent(source string, ev indexerEvent) cloudEvent[indexerEvent] {
+	return cloudEvent[indexerEvent]{
+		SpecVersion:     "1.0",
+		Type:            cloudEventNamespace + ev.Event,
+		Source:          source,
+		ID:              ev.ID,
+		Time:            ev.Chain.At.UTC().Format(time.RFC3339Nano),
+		Subject:         fmt.Sprintf("chain/%d", ev.Chain.ChainID),
+		DataContentType: "application/json",
+		Data:            ev,
+	}
+}
+
+// encodeFeedEvent encodes ev in the configured feed format.
+func (h *handlers) encodeFeedEvent(ev feedEvent, at time.Time) ([]byte, error) {
+	if h.cfg.FeedFormat == feedFormatCloudEvents {
+		return json.Marshal(newCloudEvent(h.cfg.IndexerBaseURL, ev, at))
+	}
+	return json.Marshal(ev)
+}
+
+// encodeIndexerEvent is encodeFeedEvent for indexer events.
+func (h *handlers) encodeIndexerEvent(ev indexerEvent) ([]byte, error) {
+	if h.cfg.FeedFormat == feedFormatCloudEvents {
+		return json.Marshal(newIndexerCloudEvent(h.cfg.IndexerBaseURL, ev))
+	}
+	return json.Marshal(ev)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+func TestCloudEvent_Golden(t *testing.T) {
+	task := fixtureTask(true)
+	id := store.EventSource{ChainID: task.ChainID, TxHash: task.OnchainTxHash, LogIndex: 3}.EventID(store.TaskEventReleased)
+	ev := newCloudEvent("https://indexer.example", feedEvent{ID: id, Event: store.TaskEventReleased, Task: newTaskResponse(task)}, task.UpdatedAt)
+
+	rec := httptest.NewRecorder()
+	util.WriteJSON(rec, http.StatusOK, ev)
+	want, err := os.ReadFile(filepath.Join("testdata", "task_released.cloudevent.json"))
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if got := rec.Body.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wire format changed\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestCloudEvent_Type(t *testing.T) {
+	ev := newCloudEvent("https://indexer.example", feedEvent{Event: store.TaskEventDeadlineApproaching}, time.Now())
+	if ev.Type != "net.agentmesh.task.deadline_approaching" {
+		t.Errorf("deadline type = %q", ev.Type)
+	}
+}
+
+func TestFeed_CloudEventsFormat(t *testing.T) {
+	repo := store.NewHookedTaskRepo(&acceptRepo{})
+	cfg := config.Config{IndexerBaseURL: "https://indexer.example", FeedFormat: "cloudevents"}
+	srv := httptest.NewServer(NewRouter(nil, repo, cfg, nil))
+	defer srv.Close()
+	conn := dialFeed(t, srv, "")
+
+	repo.Emit(context.Background(), store.TaskEventReleased, &store.Task{TaskID: "t-1", Status: store.TaskStatusReleased})
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var ev cloudEvent[feedEvent]
+	if err := conn.ReadJSON(&ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.SpecVersion != "1.0" || ev.Type != "net.agentmesh.task.released" || ev.Source != cfg.IndexerBaseURL ||
+		ev.ID == "" || ev.Subject != "t-1" || ev.Data.Event != store.TaskEventReleased || ev.Data.Task.TaskID != "t-1" {
+		t.Errorf("event = %+v", ev)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, ev.Time); err != nil {
+		t.Errorf("time %q: %v", ev.Time, err)
+	}
+}
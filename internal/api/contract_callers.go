@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+)
+
+// chainReader is the subset of *ethclient.Client used while handling
+// requests: contract view calls and code lookups.
+type chainReader = service.ChainReader
+
+// contractCallers lazily dials and caches one RPC client per chain for
+// contract reads made while handling requests.
+type contractCallers struct {
+	rpcURLs map[int]string
+	dial    func(ctx context.Context, chainID int, rpcURL string) (chainReader, error)
+
+	mu      sync.Mutex
+	clients map[int]chainReader
+}
+
+func newContractCallers(cfg config.Config) *contractCallers {
+	return &contractCallers{
+		rpcURLs: cfg.RPCURLs,
+		dial: func(ctx context.Context, chainID int, rpcURL string) (chainReader, error) {
+			ch, _ := cfg.Chain(chainID)
+			return chain.DialRPC(ctx, rpcURL, ch)
+		},
+		clients: make(map[int]chainReader),
+	}
+}
+
+// Client returns the client for chainID, dialing it on first use.
+func (c *contractCallers) Client(ctx context.Context, chainID int) (chainReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[chainID]; ok {
+		return client, nil
+	}
+	rpcURL := c.rpcURLs[chainID]
+	if rpcURL == "" {
+		return nil, fmt.Errorf("no RPC URL configured for chain_id %d", chainID)
+	}
+	client, err := c.dial(ctx, chainID, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial chain_id %d: %w", chainID, err)
+	}
+	c.clients[chainID] = client
+	return client, nil
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// fixedHashCaller returns hash from every contract call and code for every
+// address.
+type fixedHashCaller struct {
+	hash common.Hash
+	code []byte
+}
+
+func (c fixedHashCaller) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return c.hash.Bytes(), nil
+}
+
+func (c fixedHashCaller) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return c.code, nil
+}
+
+type insertTaskRepo struct {
+	store.TaskRepo
+	inserted []*store.Task
+}
+
+func (r *insertTaskRepo) InsertTask(_ context.Context, t *store.Task, _ int) error {
+	r.inserted = append(r.inserted, t)
+	return nil
+}
+
+func signedTaskBody(t *testing.T, key *ecdsa.PrivateKey, taskID string) string {
+	t.Helper()
+	prefixed := ethutil.Keccak256(append([]byte("\x19Ethereum Signed Message:\n32"), ethutil.Keccak256([]byte(taskID))...))
+	sig, err := crypto.Sign(prefixed, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	body, _ := json.Marshal(map[string]any{
+		"task_id":          taskID,
+		"task_hash":        ethutil.Keccak256Hex([]byte(taskID)),
+		"chain_id":         11155111,
+		"employer_address": crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		"amount_wei":       "1000",
+		"deadline_unix":    1767225600,
+		"signature":        "0x" + hex.EncodeToString(sig),
+	})
+	return string(body)
+}
+
+func TestPostTask_OnchainHashVerification(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const taskID = "task-onchain-verify"
+	cfg := config.Config{
+		FeeBPS:                        20,
+		EnableOnchainHashVerification: true,
+		SupportedChains:               []config.ChainConfig{{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+		RPCURLs:                       map[int]string{11155111: "https://rpc.example"},
+	}
+
+	cases := []struct {
+		name       string
+		onchain    common.Hash
+		wantStatus int
+		wantCode   string
+	}{
+		{"match", common.BytesToHash(ethutil.Keccak256([]byte(taskID))), http.StatusCreated, ""},
+		{"mismatch", common.HexToHash("0x01"), http.StatusUnprocessableEntity, "task_hash_onchain_mismatch"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &insertTaskRepo{}
+			h := &handlers{taskRepo: repo, maxBody: 1 << 20, cfg: cfg, contractCallers: newContractCallers(cfg)}
+			h.contractCallers.dial = func(context.Context, int, string) (chainReader, error) {
+				return fixedHashCaller{hash: tc.onchain}, nil
+			}
+
+			rec := httptest.NewRecorder()
+			h.PostTask(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks", strings.NewReader(signedTaskBody(t, key, taskID))))
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if tc.wantCode != "" && !strings.Contains(rec.Body.String(), tc.wantCode) {
+				t.Errorf("body %s missing code %q", rec.Body, tc.wantCode)
+			}
+			if stored := len(repo.inserted) == 1; stored != (tc.wantStatus == http.StatusCreated) {
+				t.Errorf("stored = %v for status %d", stored, rec.Code)
+			}
+		})
+	}
+}
+
+func TestPostTask_EscrowCodeVerification(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := []byte{0x60, 0x80, 0x60, 0x40}
+	codeHash := crypto.Keccak256Hash(code).Hex()
+
+	cases := []struct {
+		name       string
+		code       []byte
+		codeHash   string
+		wantStatus int
+	}{
+		{"contract", code, "", http.StatusCreated},
+		{"matching_code_hash", code, codeHash, http.StatusCreated},
+		{"eoa", nil, "", http.StatusUnprocessableEntity},
+		{"other_contract", []byte{0x00}, codeHash, http.StatusUnprocessableEntity},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Config{
+				FeeBPS:                       20,
+				EnableEscrowCodeVerification: true,
+				SupportedChains: []config.ChainConfig{{
+					ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C", EscrowCodeHash: tc.codeHash,
+				}},
+				RPCURLs: map[int]string{11155111: "https://rpc.example"},
+			}
+			repo := &insertTaskRepo{}
+			h := &handlers{taskRepo: repo, maxBody: 1 << 20, cfg: cfg, contractCallers: newContractCallers(cfg)}
+			h.contractCallers.dial = func(context.Context, int, string) (chainReader, error) {
+				return fixedHashCaller{code: tc.code}, nil
+			}
+
+			rec := httptest.NewRecorder()
+			h.PostTask(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks", strings.NewReader(signedTaskBody(t, key, "task-escrow-"+tc.name))))
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if tc.wantStatus == http.StatusUnprocessableEntity && !strings.Contains(rec.Body.String(), "invalid_request") {
+				t.Errorf("body %s missing invalid_request", rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+func TestEndpointFamilies(t *testing.T) {
+	// Requests that fail validation before touching a repo, so an enabled
+	// route answers 400 (or 200) and a disabled one 404 endpoint_disabled.
+	legacy := []struct{ method, path string }{
+		{http.MethodGet, "/v1/indexer/info"},
+		{http.MethodGet, "/v1/bids?created_after=x"},
+		{http.MethodGet, "/v1/accepts?created_after=x"},
+		{http.MethodGet, "/v1/artifacts?created_after=x"},
+		{http.MethodPost, "/v1/bids"},
+		{http.MethodPost, "/v1/accepts"},
+		{http.MethodPost, "/v1/artifacts"},
+	}
+	tasks := []struct{ method, path string }{
+		{http.MethodGet, "/v1/tasks?fields=bogus"},
+		{http.MethodGet, "/v1/tasks/compute-hash"},
+		{http.MethodPost, "/v1/tasks"},
+		{http.MethodPost, "/v1/tasks/batch"},
+		{http.MethodPost, "/v1/tasks/t-1/accept"},
+	}
+
+	for _, tc := range []struct {
+		name                string
+		noLegacy, noV2Tasks bool
+	}{
+		{"both", false, false},
+		{"v2_only", true, false},
+		{"envelope_only", false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Config{MaxBodyBytes: 1 << 20, DisableLegacyObjects: tc.noLegacy, DisableV2Tasks: tc.noV2Tasks}
+			router := NewRouter(nil, nil, cfg, nil)
+			check := func(method, path string, disabled bool) {
+				t.Helper()
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader("{")))
+				gotDisabled := rec.Code == http.StatusNotFound && strings.Contains(rec.Body.String(), `"endpoint_disabled"`)
+				if gotDisabled != disabled {
+					t.Errorf("%s %s: status %d %s, want disabled=%v", method, path, rec.Code, rec.Body, disabled)
+				}
+				if disabled && !strings.Contains(rec.Body.String(), "/v1/meta") {
+					t.Errorf("%s %s: body %s does not point at /v1/meta", method, path, rec.Body)
+				}
+			}
+			for _, r := range legacy {
+				check(r.method, r.path, tc.noLegacy)
+			}
+			for _, r := range tasks {
+				check(r.method, r.path, tc.noV2Tasks)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/meta", nil))
+			var meta struct {
+				Capabilities map[string]bool `json:"capabilities"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &meta); err != nil {
+				t.Fatalf("meta: %v", err)
+			}
+			if meta.Capabilities["legacy_objects"] == tc.noLegacy || meta.Capabilities["v2_tasks"] == tc.noV2Tasks {
+				t.Errorf("meta capabilities = %v", meta.Capabilities)
+			}
+		})
+	}
+}
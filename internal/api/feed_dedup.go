@@ -0,0 +1,57 @@
+package api
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+var feedEventsSuppressed = metrics.NewCounterVec("amn_feed_events_suppressed_total",
+	"Feed events not published because their id was published within the dedup window.")
+
+// recentIDs is a bounded LRU of feed event ids published within ttl. Chain
+// events are applied at least once: a reorg or POST /v1/admin/reprocess-tx
+// runs a log through the handlers again, and the store fires the same
+// transition. The watcher's log dedup window only covers redeliveries it
+// sees itself and is forgotten on reorg, so the feed keeps its own.
+type recentIDs struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	now   func() time.Time
+	order *list.List // of *recentID, most recent first
+	byID  map[string]*list.Element
+}
+
+type recentID struct {
+	id string
+	at time.Time
+}
+
+func newRecentIDs(size int, ttl time.Duration) *recentIDs {
+	return &recentIDs{size: size, ttl: ttl, now: time.Now, order: list.New(), byID: make(map[string]*list.Element)}
+}
+
+// add records id as published now. It returns false, recording nothing, if
+// id was already published within the TTL.
+func (d *recentIDs) add(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if el, ok := d.byID[id]; ok {
+		if d.now().Sub(el.Value.(*recentID).at) < d.ttl {
+			return false
+		}
+		el.Value.(*recentID).at = d.now()
+		d.order.MoveToFront(el)
+		return true
+	}
+	d.byID[id] = d.order.PushFront(&recentID{id: id, at: d.now()})
+	for d.order.Len() > d.size {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.byID, oldest.Value.(*recentID).id)
+	}
+	return true
+}
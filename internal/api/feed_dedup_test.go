@@ -0,0 +1,28 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecentIDs(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newRecentIDs(2, time.Minute)
+	d.now = func() time.Time { return now }
+
+	if !d.add("a") || d.add("a") {
+		t.Fatal("a: want first add true, repeat false")
+	}
+	now = now.Add(time.Minute)
+	if !d.add("a") {
+		t.Error("a after the TTL was suppressed")
+	}
+	d.add("b")
+	d.add("c") // evicts a, the least recently added
+	if !d.add("a") {
+		t.Error("a after eviction was suppressed")
+	}
+	if d.add("c") {
+		t.Error("c within the TTL was not suppressed")
+	}
+}
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// acceptRepo is an in-memory TaskRepo covering the accept flow.
+type acceptRepo struct {
+	store.TaskRepo
+	tasks   map[string]*store.Task
+	accepts map[string]*store.Accept // by task_id + "/" + accept_id
+}
+
+func (r *acceptRepo) GetTask(_ context.Context, id string) (*store.Task, error) {
+	if t, ok := r.tasks[id]; ok {
+		return t, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r *acceptRepo) GetAccept(_ context.Context, taskID, acceptID string) (*store.Accept, error) {
+	if a, ok := r.accepts[taskID+"/"+acceptID]; ok {
+		return a, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r *acceptRepo) InsertAccept(_ context.Context, a *store.Accept) error {
+	if _, ok := r.accepts[a.TaskID+"/"+a.AcceptID]; ok {
+		return store.ErrConflict
+	}
+	if a.IfUpdatedAt != nil && !a.IfUpdatedAt.Equal(r.tasks[a.TaskID].UpdatedAt) {
+		return store.ErrTaskChanged
+	}
+	r.accepts[a.TaskID+"/"+a.AcceptID] = a
+	return nil
+}
+
+func (r *acceptRepo) UpdateTaskWorker(_ context.Context, taskID, worker, status string) error {
+	r.tasks[taskID].WorkerAddress, r.tasks[taskID].Status = worker, status
+	return nil
+}
+
+func (r *acceptRepo) GetWorkerTier(context.Context, string) (*store.WorkerTier, error) {
+	return nil, store.ErrNotFound
+}
+
+func acceptBody(t *testing.T, key *ecdsa.PrivateKey, taskID, acceptID string) string {
+	t.Helper()
+	msg := ethutil.Keccak256([]byte(taskID + acceptID))
+	sig, err := crypto.Sign(ethutil.Keccak256(append([]byte("\x19Ethereum Signed Message:\n32"), msg...)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	body, _ := json.Marshal(map[string]string{
+		"accept_id":      acceptID,
+		"worker_address": crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		"signature":      "0x" + hex.EncodeToString(sig),
+	})
+	return string(body)
+}
+
+func acceptConfig() config.Config {
+	return config.Config{MaxBodyBytes: 1 << 20, SupportedChains: []config.ChainConfig{{ChainID: 11155111}}}
+}
+
+func TestPostTaskAccept_IdempotentRetry(t *testing.T) {
+	worker, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+
+	task := fixtureTask(false)
+	sibling := fixtureTask(false)
+	sibling.TaskID = "task-golden-002"
+	repo := &acceptRepo{
+		tasks:   map[string]*store.Task{task.TaskID: task, sibling.TaskID: sibling},
+		accepts: map[string]*store.Accept{},
+	}
+	router := NewRouter(nil, repo, acceptConfig(), nil)
+
+	postTo := func(taskID, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks/"+taskID+"/accept", strings.NewReader(body)))
+		return rec
+	}
+	post := func(body string) *httptest.ResponseRecorder { return postTo(task.TaskID, body) }
+
+	first := acceptBody(t, worker, task.TaskID, "acc-1")
+	if rec := post(first); rec.Code != http.StatusCreated {
+		t.Fatalf("first accept: status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	t.Run("identical_retry", func(t *testing.T) {
+		rec := post(first)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body)
+		}
+		var resp map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp["accept_id"] != "acc-1" || resp["task_id"] != task.TaskID || resp["status"] != "accepted" {
+			t.Errorf("response = %v", resp)
+		}
+	})
+
+	t.Run("reuse_by_other_worker", func(t *testing.T) {
+		if rec := post(acceptBody(t, other, task.TaskID, "acc-1")); rec.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", rec.Code)
+		}
+	})
+
+	t.Run("new_accept_on_accepted_task", func(t *testing.T) {
+		if rec := post(acceptBody(t, other, task.TaskID, "acc-2")); rec.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", rec.Code)
+		}
+	})
+
+	// accept_id is scoped to the task, so reusing it on another task is a
+	// new accept rather than a conflict.
+	t.Run("reuse_on_other_task", func(t *testing.T) {
+		if rec := postTo(sibling.TaskID, acceptBody(t, other, sibling.TaskID, "acc-1")); rec.Code != http.StatusCreated {
+			t.Errorf("status = %d, want 201; body = %s", rec.Code, rec.Body)
+		}
+	})
+}
+
+func TestPostTaskAccept_IfMatch(t *testing.T) {
+	worker, _ := crypto.GenerateKey()
+
+	for _, tc := range []struct {
+		name    string
+		ifMatch string
+		field   string // expected_updated_at
+		want    int
+	}{
+		{"absent", "", "", http.StatusCreated},
+		{"etag_matches", `"2025-01-01T00:01:00.123456Z"`, "", http.StatusCreated},
+		{"field_matches", "", "2025-01-01T00:01:00.123456Z", http.StatusCreated},
+		{"wildcard", "*", "", http.StatusCreated},
+		{"stale_etag", `"2025-01-01T00:00:00.123456Z"`, "", http.StatusPreconditionFailed},
+		{"stale_field", "", "2025-01-01T00:00:00Z", http.StatusPreconditionFailed},
+		{"malformed", "yesterday", "", http.StatusBadRequest},
+		{"disagree", `"2025-01-01T00:01:00.123456Z"`, "2025-01-01T00:00:00Z", http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			task := fixtureTask(false)
+			repo := &acceptRepo{
+				tasks:   map[string]*store.Task{task.TaskID: task},
+				accepts: map[string]*store.Accept{},
+			}
+			router := NewRouter(nil, repo, acceptConfig(), nil)
+
+			body := acceptBody(t, worker, task.TaskID, "acc-"+tc.name)
+			if tc.field != "" {
+				body = strings.TrimSuffix(body, "}") + `,"expected_updated_at":"` + tc.field + `"}`
+			}
+			req := httptest.NewRequest(http.MethodPost, "/v1/tasks/"+task.TaskID+"/accept", strings.NewReader(body))
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tc.want, rec.Body)
+			}
+			if tc.want != http.StatusPreconditionFailed {
+				return
+			}
+			var resp struct {
+				Error struct{ Code string } `json:"error"`
+				Task  taskResponse          `json:"task"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Error.Code != "precondition_failed" || !resp.Task.UpdatedAt.Equal(task.UpdatedAt) ||
+				rec.Header().Get("ETag") != taskETag(task) || len(repo.accepts) != 0 {
+				t.Errorf("412 response = %+v, ETag %q, %d accepts stored", resp, rec.Header().Get("ETag"), len(repo.accepts))
+			}
+		})
+	}
+}
+
+// Accepts have no 422 cases: the rules they can break already have more
+// specific statuses, which take precedence over 422.
+func TestPostTaskAccept_StatusByFailureClass(t *testing.T) {
+	worker, _ := crypto.GenerateKey()
+	task := fixtureTask(false)
+	taken := fixtureTask(false)
+	taken.TaskID, taken.Status = "task-taken", store.TaskStatusAccepted
+	repo := &acceptRepo{
+		tasks:   map[string]*store.Task{task.TaskID: task, taken.TaskID: taken},
+		accepts: map[string]*store.Accept{},
+	}
+	router := NewRouter(nil, repo, acceptConfig(), nil)
+
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(acceptBody(t, worker, task.TaskID, "acc-1")), &fields); err != nil {
+		t.Fatal(err)
+	}
+	with := func(k, v string) string {
+		m := maps.Clone(fields)
+		m[k] = v
+		b, _ := json.Marshal(m)
+		return string(b)
+	}
+	cases := []struct {
+		name, taskID, body string
+		want               int
+	}{
+		{"malformed_json", task.TaskID, `{"accept_id":`, http.StatusBadRequest},
+		{"missing_accept_id", task.TaskID, with("accept_id", ""), http.StatusBadRequest},
+		{"bad_address_format", task.TaskID, with("worker_address", "0x12"), http.StatusBadRequest},
+		{"bad_signature_format", task.TaskID, with("signature", "0x12"), http.StatusBadRequest},
+		{"unknown_task", "task-missing", acceptBody(t, worker, "task-missing", "acc-1"), http.StatusNotFound},
+		{"task_not_open", taken.TaskID, acceptBody(t, worker, taken.TaskID, "acc-1"), http.StatusConflict},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks/"+tc.taskID+"/accept", strings.NewReader(tc.body)))
+		if rec.Code != tc.want {
+			t.Errorf("%s: status %d, want %d; body = %s", tc.name, rec.Code, tc.want, rec.Body)
+		}
+	}
+}
+
+// tieredAcceptRepo gives the workers in tiers their stored tier.
+type tieredAcceptRepo struct {
+	*acceptRepo
+	tiers map[string]*store.WorkerTier // by lowercase address
+}
+
+func (r *tieredAcceptRepo) GetWorkerTier(_ context.Context, addr string) (*store.WorkerTier, error) {
+	if t, ok := r.tiers[strings.ToLower(addr)]; ok {
+		return t, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func TestPostTaskAccept_TierLimit(t *testing.T) {
+	junior, _ := crypto.GenerateKey()
+	senior, _ := crypto.GenerateKey()
+	newcomer, _ := crypto.GenerateKey()
+	addr := func(k *ecdsa.PrivateKey) string { return strings.ToLower(crypto.PubkeyToAddress(k.PublicKey).Hex()) }
+
+	// The fixture task escrows 1 ETH.
+	task := fixtureTask(false)
+	repo := &tieredAcceptRepo{
+		acceptRepo: &acceptRepo{tasks: map[string]*store.Task{task.TaskID: task}, accepts: map[string]*store.Accept{}},
+		tiers: map[string]*store.WorkerTier{
+			addr(junior): {WorkerAddress: addr(junior), Tier: 1, MaxTaskAmountWei: "999999999999999999"},
+			addr(senior): {WorkerAddress: addr(senior), Tier: 3, MaxTaskAmountWei: "1000000000000000000"},
+		},
+	}
+	cfg := acceptConfig()
+	cfg.DefaultWorkerMaxTaskWei = "1"
+	router := NewRouter(nil, repo, cfg, nil)
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks/"+task.TaskID+"/accept", strings.NewReader(body)))
+		return rec
+	}
+
+	for _, tc := range []struct {
+		name string
+		key  *ecdsa.PrivateKey
+	}{{"below_task_value", junior}, {"default_tier", newcomer}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := post(acceptBody(t, tc.key, task.TaskID, "acc-"+tc.name))
+			if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "tier_limit_exceeded") {
+				t.Errorf("status = %d, body = %s; want 403 tier_limit_exceeded", rec.Code, rec.Body)
+			}
+		})
+	}
+	if len(repo.accepts) != 0 || task.WorkerAddress != "" {
+		t.Fatalf("rejected accepts were stored: %v, worker %q", repo.accepts, task.WorkerAddress)
+	}
+
+	if rec := post(acceptBody(t, senior, task.TaskID, "acc-senior")); rec.Code != http.StatusCreated {
+		t.Errorf("at the limit: status = %d, body = %s; want 201", rec.Code, rec.Body)
+	}
+}
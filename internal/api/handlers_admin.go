@@ -0,0 +1,538 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/telemetry"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// requireAdmin guards /v1/admin/* with a static bearer token (AMN_ADMIN_TOKEN).
+// When no token is configured the admin API is disabled entirely.
+func (h *handlers) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.AdminToken == "" {
+			util.WriteError(w, http.StatusForbidden, "forbidden", "admin API is disabled")
+			return
+		}
+		if !h.isAdmin(r) {
+			util.WriteError(w, http.StatusUnauthorized, "unauthorized", "invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAdmin reports whether r carries the admin bearer token.
+func (h *handlers) isAdmin(r *http.Request) bool {
+	if h.cfg.AdminToken == "" {
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.AdminToken)) == 1
+}
+
+// includeDeleted serves ?include_deleted=true on task and object reads:
+// soft-deleted rows are returned too, with deleted_at set. Only the admin
+// token may ask for them.
+func (h *handlers) includeDeleted(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.URL.Query().Get("include_deleted")
+		if raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "include_deleted must be a boolean")
+			return
+		}
+		if !include {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !h.isAdmin(r) {
+			util.WriteError(w, http.StatusForbidden, "forbidden", "include_deleted requires the admin token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(store.WithDeleted(r.Context())))
+	})
+}
+
+// GetTelemetryPreview handles GET /v1/admin/telemetry-preview and returns
+// exactly the report the telemetry reporter would send.
+func (h *handlers) GetTelemetryPreview(w http.ResponseWriter, r *http.Request) {
+	if h.cfg.SigningKeyHex == "" {
+		util.WriteError(w, http.StatusConflict, "signing_key_required", "telemetry reports are signed with INDEXER_SIGNING_KEY, which is not set")
+		return
+	}
+	rep, err := telemetry.Build(r.Context(), h.cfg, h.taskRepo, h.repo)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to build telemetry report")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"enabled": h.cfg.TelemetryURL != "",
+		"report":  rep,
+	})
+}
+
+type reprocessTxReq struct {
+	ChainID int    `json:"chain_id"`
+	TxHash  string `json:"tx_hash"`
+}
+
+// PostReprocessTx handles POST /v1/admin/reprocess-tx. It re-applies the
+// settlement logs of a single transaction through the chain watcher.
+func (h *handlers) PostReprocessTx(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
+		return
+	}
+	var req reprocessTxReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
+		return
+	}
+	if !reHexHash.MatchString(req.TxHash) {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "tx_hash must be 0x + 64 hex chars")
+		return
+	}
+
+	watcher := h.watcherFor(req.ChainID)
+	if watcher == nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request",
+			fmt.Sprintf("no chain watcher running for chain_id %d", req.ChainID))
+		return
+	}
+
+	logs, err := watcher.ReprocessTx(r.Context(), common.HexToHash(req.TxHash))
+	if err != nil {
+		if errors.Is(err, chain.ErrTxNotFound) {
+			util.WriteError(w, http.StatusNotFound, "not_found", "transaction receipt not found")
+			return
+		}
+		util.WriteError(w, http.StatusBadGateway, "upstream_error", "reprocess failed: "+err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"chain_id": req.ChainID,
+		"tx_hash":  strings.ToLower(req.TxHash),
+		"logs":     logs,
+	})
+}
+
+// ── POST /v1/admin/tasks/{taskID}/reverify ─────────────────────────────────
+
+// PostTaskReverify re-verifies a task's employer signature from the creation
+// request stored with it. A request that no longer verifies is reported with
+// valid=false and a 200; see service.ReverifyTask.
+func (h *handlers) PostTaskReverify(w http.ResponseWriter, r *http.Request) {
+	out, err := h.taskService().ReverifyTask(r.Context(), chi.URLParam(r, "taskID"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, out)
+}
+
+// ── GET / PATCH / DELETE /v1/admin/objects/{objectID} ──────────────────────
+
+type objectNotesResponse struct {
+	ObjectID            string     `json:"object_id"`
+	AdminNotes          string     `json:"admin_notes"`
+	AdminNotesUpdatedAt *time.Time `json:"admin_notes_updated_at,omitempty"`
+}
+
+func newObjectNotesResponse(n *store.ObjectNotes) objectNotesResponse {
+	return objectNotesResponse{ObjectID: n.ObjectID, AdminNotes: n.Notes, AdminNotesUpdatedAt: n.UpdatedAt}
+}
+
+// objectPatchReq is a JSON merge patch of an object's unsigned fields. Only
+// admin_notes may be set; null or "" clears it. Any other member is rejected
+// so a patch can never be mistaken for an edit of the signed envelope.
+type objectPatchReq struct {
+	AdminNotes *string `json:"admin_notes"`
+}
+
+// maxAdminNotesLen bounds admin_notes in bytes.
+const maxAdminNotesLen = 4096
+
+// GetObjectNotes returns an object's operator annotation.
+func (h *handlers) GetObjectNotes(w http.ResponseWriter, r *http.Request) {
+	n, err := h.repo.GetObjectNotes(r.Context(), chi.URLParam(r, "objectID"))
+	if err != nil {
+		writeObjectNotesError(w, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, newObjectNotesResponse(n))
+}
+
+// PatchObject updates an object's operator annotation. The signed envelope
+// is immutable and is never changed.
+func (h *handlers) PatchObject(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	var req objectPatchReq
+	if err := dec.Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request",
+			"only admin_notes can be patched; the signed envelope is immutable: "+err.Error())
+		return
+	}
+	notes := ""
+	if req.AdminNotes != nil {
+		notes = strings.TrimSpace(*req.AdminNotes)
+	}
+	if len(notes) > maxAdminNotesLen {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request",
+			fmt.Sprintf("admin_notes must be at most %d bytes", maxAdminNotesLen))
+		return
+	}
+
+	objectID := chi.URLParam(r, "objectID")
+	n, err := h.repo.SetObjectNotes(r.Context(), objectID, notes)
+	if err != nil {
+		writeObjectNotesError(w, err)
+		return
+	}
+	if err := audit.Record(r.Context(), h.taskRepo, audit.ObjectNotesChanged, nil,
+		map[string]any{"object_id": objectID, "admin_notes": notes}); err != nil {
+		log.Printf("[admin] object notes audit: %v", err)
+	}
+	util.WriteJSON(w, http.StatusOK, newObjectNotesResponse(n))
+}
+
+// DeleteObject handles DELETE /v1/admin/objects/{objectID}: 204 once the
+// object is gone from every read. With AMN_OBJECT_TOMBSTONES or
+// AMN_SOFT_DELETE the row is kept, and deleting it again answers 410 or 404
+// respectively.
+func (h *handlers) DeleteObject(w http.ResponseWriter, r *http.Request) {
+	objectID := chi.URLParam(r, "objectID")
+	soft := h.cfg.ObjectTombstones || h.cfg.SoftDelete
+	if err := h.repo.DeleteObject(r.Context(), objectID, soft); err != nil {
+		h.writeObjectError(w, err, "failed to delete object")
+		return
+	}
+	if err := audit.Record(r.Context(), h.taskRepo, audit.ObjectDeleted, nil,
+		map[string]any{"object_id": objectID, "tombstone": soft}); err != nil {
+		log.Printf("[admin] object delete audit: %v", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteTask handles DELETE /v1/admin/tasks/{taskID}: 204 once the task is
+// gone from every read, kept with deleted_at set under AMN_SOFT_DELETE.
+// Tasks already created onchain are refused with 409; their escrow still
+// has to settle and the watcher must be able to find them.
+func (h *handlers) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	t, err := h.taskRepo.GetTask(r.Context(), taskID)
+	if err != nil {
+		writeTaskDeleteError(w, err)
+		return
+	}
+	if err := h.taskRepo.DeleteTask(r.Context(), taskID, h.cfg.SoftDelete); err != nil {
+		writeTaskDeleteError(w, err)
+		return
+	}
+	if err := audit.Record(r.Context(), h.taskRepo, audit.TaskDeleted, &t.ChainID,
+		map[string]any{"task_id": taskID, "soft": h.cfg.SoftDelete}); err != nil {
+		log.Printf("[admin] task delete audit: %v", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeTaskDeleteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		util.WriteError(w, http.StatusNotFound, "not_found", "task not found")
+		return
+	}
+	if errors.Is(err, store.ErrTaskOnchain) {
+		util.WriteError(w, http.StatusConflict, "task_onchain", "task was created onchain and cannot be deleted")
+		return
+	}
+	util.WriteError(w, http.StatusInternalServerError, "internal", "failed to delete task")
+}
+
+func writeObjectNotesError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		util.WriteError(w, http.StatusNotFound, "not_found", "object not found")
+		return
+	}
+	util.WriteError(w, http.StatusInternalServerError, "internal", "failed to access object notes")
+}
+
+// watcherFor returns the running watcher for chainID, or nil.
+func (h *handlers) watcherFor(chainID int) *chain.Watcher {
+	for _, w := range h.watchers {
+		if w.ChainID() == chainID {
+			return w
+		}
+	}
+	return nil
+}
+
+// ── GET /v1/admin/audit ───────────────────────────────────────────────────────
+
+type auditEventResponse struct {
+	ID        int64          `json:"id"`
+	Type      string         `json:"type"`
+	Severity  string         `json:"severity"`
+	ChainID   *int           `json:"chain_id,omitempty"`
+	Detail    map[string]any `json:"detail"`
+	CreatedAt string         `json:"created_at"`
+
+	AcknowledgedAt string `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string `json:"acknowledged_by,omitempty"`
+}
+
+func newAuditEventResponse(e *store.AuditEvent) auditEventResponse {
+	resp := auditEventResponse{
+		ID:             e.ID,
+		Type:           e.Type,
+		Severity:       e.Severity,
+		ChainID:        e.ChainID,
+		Detail:         e.Detail,
+		CreatedAt:      e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		AcknowledgedBy: e.AcknowledgedBy,
+	}
+	if e.AcknowledgedAt != nil {
+		resp.AcknowledgedAt = e.AcknowledgedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return resp
+}
+
+// ListAuditEvents handles GET /v1/admin/audit. Optional filters: severity,
+// type, chain_id, since and until (RFC 3339; since inclusive, until exclusive)
+// and unacknowledged=true.
+// Results are newest first with cursor pagination.
+func (h *handlers) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var f store.AuditFilter
+
+	f.Severity = q.Get("severity")
+	if f.Severity != "" && !store.ValidAuditSeverities[f.Severity] {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "severity must be one of info, warn, critical")
+		return
+	}
+	f.Type = q.Get("type")
+	if s := q.Get("chain_id"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "chain_id must be a positive integer")
+			return
+		}
+		f.ChainID = n
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", p.name+" must be an RFC 3339 timestamp")
+			return
+		}
+		*p.dst = &t
+	}
+
+	if s := q.Get("unacknowledged"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "unacknowledged must be true or false")
+			return
+		}
+		f.Unacknowledged = b
+	}
+
+	limit := util.ParseLimit(r, 50, 200)
+	cursor, err := util.ParseCursor(r, h.cfg.CursorTTL)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	events, next, err := h.taskRepo.ListAuditEvents(r.Context(), f, limit, cursor)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list audit events")
+		return
+	}
+
+	items := make([]auditEventResponse, len(events))
+	for i, e := range events {
+		items[i] = newAuditEventResponse(e)
+	}
+	resp := map[string]any{
+		"items": items,
+	}
+	if next != nil {
+		resp["next_cursor"] = util.EncodeCursor(next)
+	}
+	util.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ── POST/DELETE /v1/admin/audit/{id}/ack ──────────────────────────────────────
+
+type ackAuditReq struct {
+	AcknowledgedBy string `json:"acknowledged_by"`
+}
+
+// PostAuditAck acknowledges an audit event.
+func (h *handlers) PostAuditAck(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
+		return
+	}
+	var req ackAuditReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
+		return
+	}
+	req.AcknowledgedBy = strings.TrimSpace(req.AcknowledgedBy)
+	if req.AcknowledgedBy == "" {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "acknowledged_by is required")
+		return
+	}
+	h.setAuditAck(w, r, true, req.AcknowledgedBy)
+}
+
+// DeleteAuditAck clears an audit event's acknowledgement.
+func (h *handlers) DeleteAuditAck(w http.ResponseWriter, r *http.Request) {
+	h.setAuditAck(w, r, false, "")
+}
+
+func (h *handlers) setAuditAck(w http.ResponseWriter, r *http.Request, ack bool, by string) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "id must be a positive integer")
+		return
+	}
+	e, err := h.taskRepo.AckAuditEvent(r.Context(), id, ack, by)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			util.WriteError(w, http.StatusNotFound, "not_found", "audit event not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to update audit event")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, newAuditEventResponse(e))
+}
+
+// ── GET /v1/admin/fees ────────────────────────────────────────────────────────
+
+type feeTotalResponse struct {
+	ChainID    int    `json:"chain_id"`
+	Tasks      int64  `json:"tasks"`
+	FeeWei     string `json:"fee_wei"`
+	Mismatches int64  `json:"mismatches"`
+}
+
+// feeCSVHeader is the column order of GET /v1/admin/fees?format=csv.
+var feeCSVHeader = []string{
+	"task_id", "chain_id", "fee_wei", "computed_fee_wei", "event_fee_wei", "mismatch", "released_at", "tx_hash",
+}
+
+// GetFees handles GET /v1/admin/fees. Optional filters: chain_id, from and to
+// (RFC 3339 on released_at; from inclusive, to exclusive). It returns
+// per-chain totals, or with format=csv one ledger row per released task.
+func (h *handlers) GetFees(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var f store.FeeFilter
+	if s := q.Get("chain_id"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "chain_id must be a positive integer")
+			return
+		}
+		f.ChainID = n
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", p.name+" must be an RFC 3339 timestamp")
+			return
+		}
+		*p.dst = &t
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "from must be before to")
+		return
+	}
+
+	switch q.Get("format") {
+	case "", "json":
+	case "csv":
+		h.writeFeesCSV(w, r, f)
+		return
+	default:
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "format must be json or csv")
+		return
+	}
+
+	totals, err := h.taskRepo.SumFees(r.Context(), f)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to sum fees")
+		return
+	}
+	items := make([]feeTotalResponse, len(totals))
+	for i, t := range totals {
+		items[i] = feeTotalResponse(t)
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *handlers) writeFeesCSV(w http.ResponseWriter, r *http.Request, f store.FeeFilter) {
+	entries, err := h.taskRepo.ListFees(r.Context(), f)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list fees")
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="fees.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(feeCSVHeader)
+	for _, e := range entries {
+		_ = cw.Write([]string{
+			e.TaskID, strconv.Itoa(e.ChainID), e.FeeWei, e.ComputedFeeWei, e.EventFeeWei,
+			strconv.FormatBool(e.Mismatch), e.ReleasedAt.UTC().Format(time.RFC3339), e.TxHash,
+		})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// configView is the effective configuration served by GET
+// /v1/admin/config. It is an allowlist: every field is copied explicitly, and
+// secrets (DSN credentials, the signing key, admin and API tokens, RPC auth
+// and provider keys in RPC URLs) appear only as whether they are set.
+type configView struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+
+	Name    string `json:"name"`
+	BaseURL string `json:"base_url"`
+	Owner   string `json:"owner"`
+	Contact string `json:"contact"`
+	FeeBPS  int    `json:"fee_bps"`
+
+	HTTPAddr         string `json:"http_addr"`
+	HTTPWriteAddr    string `json:"http_write_addr,omitempty"`
+	MaxBodyBytes     int64  `json:"max_body_bytes"`
+	CursorTTLSeconds int64  `json:"cursor_ttl_seconds"`
+	SyncLagSeconds   int64  `json:"sync_lag_seconds"`
+
+	// host:port/database only.
+	Database        string `json:"database"`
+	DatabaseReplica string `json:"database_replica,omitempty"`
+
+	SigningKeyConfigured bool `json:"signing_key_configured"`
+	APITokens            int  `json:"api_tokens"`
+	RevokedSigners       int  `json:"revoked_signers"`
+
+	Features configFeatures `json:"features"`
+	Limits   configLimits   `json:"limits"`
+	Timeouts configTimeouts `json:"timeouts"`
+	Chains   []configChain  `json:"chains"`
+}
+
+type configFeatures struct {
+	LegacyObjects           bool   `json:"legacy_objects"`
+	V2Tasks                 bool   `json:"v2_tasks"`
+	DevMode                 bool   `json:"dev_mode"`
+	RequireSignedMeta       bool   `json:"require_signed_meta"`
+	RequireUTCCreatedAt     bool   `json:"require_utc_created_at"`
+	RedactAddresses         bool   `json:"redact_addresses"`
+	MaintenanceMode         bool   `json:"maintenance_mode"`
+	IngestAsync             bool   `json:"ingest_async"`
+	ObjectTombstones        bool   `json:"object_tombstones"`
+	SoftDelete              bool   `json:"soft_delete"`
+	OnchainHashVerification bool   `json:"onchain_hash_verification"`
+	EscrowCodeVerification  bool   `json:"escrow_code_verification"`
+	ENS                     bool   `json:"ens"`
+	Telemetry               bool   `json:"telemetry"`
+	TelemetrySignature      string `json:"telemetry_signature"`
+	SnapshotCORSOrigin      string `json:"snapshot_cors_origin"`
+	FeedFormat              string `json:"feed_format"`
+}
+
+type configLimits struct {
+	DefaultWorkerMaxTaskWei  string   `json:"default_worker_max_task_wei,omitempty"`
+	MinAmountWei             string   `json:"min_amount_wei,omitempty"`
+	MaxOpenTasksPerEmployer  int      `json:"max_open_tasks_per_employer"`
+	MaxBatchTasks            int      `json:"max_batch_tasks"`
+	SignedResponsesPerMinute int      `json:"signed_responses_per_minute"`
+	IPRateLimitPerMinute     int      `json:"ip_rate_limit_per_minute"`
+	TokenRateLimitPerMinute  int      `json:"token_rate_limit_per_minute"`
+	TrustedProxies           []string `json:"trusted_proxies,omitempty"`
+	MaxFeedClients           int      `json:"max_feed_clients"`
+	FeedDedupSize            int      `json:"feed_dedup_size"`
+	VerifyWorkers            int      `json:"verify_workers"`
+	VerifyQueueSize          int      `json:"verify_queue_size"`
+	IngestWorkers            int      `json:"ingest_workers"`
+	IngestQueueSize          int      `json:"ingest_queue_size"`
+	IngestBatchSize          int      `json:"ingest_batch_size"`
+	CacheMemoryBudget        int64    `json:"cache_memory_budget"`
+	HeapSoftLimit            uint64   `json:"heap_soft_limit"`
+	PreviewOmittedFields     []string `json:"preview_omitted_fields"`
+}
+
+type configTimeouts struct {
+	VerifyQueueTimeoutSeconds    float64 `json:"verify_queue_timeout_seconds"`
+	DeadlineWarningsSeconds      []int64 `json:"deadline_warnings_seconds"`
+	DeadlineScanIntervalSeconds  int64   `json:"deadline_scan_interval_seconds"`
+	UnfundedAcceptTimeoutSeconds int64   `json:"unfunded_accept_timeout_seconds"`
+	ChainRetiredGraceSeconds     int64   `json:"chain_retired_grace_seconds"`
+	TelemetryIntervalSeconds     int64   `json:"telemetry_interval_seconds"`
+	SlowRequestMS                int64   `json:"slow_request_ms"`
+	FeedDedupTTLSeconds          int64   `json:"feed_dedup_ttl_seconds"`
+}
+
+type configChain struct {
+	ChainID               int    `json:"chain_id"`
+	SettlementContract    string `json:"settlement_contract"`
+	MinConfirmations      int    `json:"min_confirmations"`
+	MaxTasksPerMinute     int    `json:"max_tasks_per_minute"`
+	EscrowCodeHash        string `json:"escrow_code_hash,omitempty"`
+	MaxLogDataBytes       int    `json:"max_log_data_bytes"`
+	LogDedupSize          int    `json:"log_dedup_size"`
+	LogDedupTTLSeconds    int    `json:"log_dedup_ttl_seconds"`
+	LogBufferSize         int    `json:"log_buffer_size"`
+	LogQueueSize          int    `json:"log_queue_size"`
+	MaxLagBlocks          int    `json:"max_lag_blocks"`
+	LagAlarmBlocks        int    `json:"lag_alarm_blocks,omitempty"`
+	LagAlarmClearBlocks   int    `json:"lag_alarm_clear_blocks,omitempty"`
+	MinAmountWei          string `json:"min_amount_wei,omitempty"`
+	Name                  string `json:"name,omitempty"`
+	Symbol                string `json:"symbol,omitempty"`
+	Decimals              int    `json:"decimals,omitempty"`
+	ExplorerTxURLTemplate string `json:"explorer_tx_url_template,omitempty"`
+	// RPC is scheme://host[:port]; paths and queries often carry provider keys.
+	RPC                   string   `json:"rpc,omitempty"`
+	RPCCAFile             string   `json:"rpc_ca_file,omitempty"`
+	RPCInsecureSkipVerify bool     `json:"rpc_insecure_skip_verify,omitempty"`
+	RPCHeaderNames        []string `json:"rpc_header_names,omitempty"`
+	RPCBasicAuth          bool     `json:"rpc_basic_auth,omitempty"`
+}
+
+// GetAdminConfig handles GET /v1/admin/config: the configuration this process
+// loaded, without secrets.
+func (h *handlers) GetAdminConfig(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSON(w, http.StatusOK, newConfigView(h.cfg, h.maintenance.get().Enabled))
+}
+
+func newConfigView(c config.Config, maintenance bool) configView {
+	v := configView{
+		Version: c.Version, Commit: c.Commit,
+		Name: c.IndexerName, BaseURL: c.IndexerBaseURL, Owner: c.IndexerOwner, Contact: c.IndexerContact, FeeBPS: c.FeeBPS,
+		HTTPAddr: c.HTTPAddr, HTTPWriteAddr: c.HTTPWriteAddr, MaxBodyBytes: c.MaxBodyBytes,
+		CursorTTLSeconds:     int64(c.CursorTTL.Seconds()),
+		SyncLagSeconds:       int64(c.SyncLag.Seconds()),
+		Database:             store.DescribeDSN(c.DBDSN),
+		SigningKeyConfigured: c.SigningKeyHex != "",
+		APITokens:            len(c.APITokens),
+		RevokedSigners:       len(c.RevokedSigners),
+		Features: configFeatures{
+			LegacyObjects:           !c.DisableLegacyObjects,
+			V2Tasks:                 !c.DisableV2Tasks,
+			DevMode:                 c.DevMode,
+			RequireSignedMeta:       c.RequireSignedMeta,
+			RequireUTCCreatedAt:     c.RequireUTCCreatedAt,
+			RedactAddresses:         c.RedactAddresses,
+			MaintenanceMode:         maintenance,
+			IngestAsync:             c.IngestAsync,
+			ObjectTombstones:        c.ObjectTombstones,
+			SoftDelete:              c.SoftDelete,
+			OnchainHashVerification: c.EnableOnchainHashVerification,
+			EscrowCodeVerification:  c.EnableEscrowCodeVerification,
+			ENS:                     c.ENSRPCEndpoint != "",
+			Telemetry:               c.TelemetryURL != "",
+			TelemetrySignature:      telemetrySignature(c),
+			SnapshotCORSOrigin:      c.SnapshotCORSOrigin,
+			FeedFormat:              c.FeedFormat,
+		},
+		Limits: configLimits{
+			DefaultWorkerMaxTaskWei:  c.DefaultWorkerMaxTaskWei,
+			MinAmountWei:             c.MinAmountWei,
+			MaxOpenTasksPerEmployer:  c.MaxOpenTasksPerEmployer,
+			MaxBatchTasks:            c.MaxBatchTasks,
+			SignedResponsesPerMinute: c.SignedResponsesPerMinute,
+			IPRateLimitPerMinute:     c.IPRateLimitPerMinute,
+			TokenRateLimitPerMinute:  c.TokenRateLimitPerMinute,
+			TrustedProxies:           c.TrustedProxies,
+			MaxFeedClients:           c.MaxFeedClients,
+			FeedDedupSize:            c.FeedDedupSize,
+			VerifyWorkers:            c.VerifyWorkers,
+			VerifyQueueSize:          c.VerifyQueueSize,
+			IngestWorkers:            c.IngestWorkers,
+			IngestQueueSize:          c.IngestQueueSize,
+			IngestBatchSize:          c.IngestBatchSize,
+			CacheMemoryBudget:        c.CacheMemoryBudget,
+			HeapSoftLimit:            c.HeapSoftLimit,
+			PreviewOmittedFields:     c.PreviewOmittedFields,
+		},
+		Timeouts: configTimeouts{
+			VerifyQueueTimeoutSeconds:    c.VerifyQueueTimeout.Seconds(),
+			DeadlineWarningsSeconds:      []int64{},
+			DeadlineScanIntervalSeconds:  int64(c.DeadlineScanInterval.Seconds()),
+			UnfundedAcceptTimeoutSeconds: int64(c.UnfundedAcceptTimeout.Seconds()),
+			ChainRetiredGraceSeconds:     int64(c.ChainRetiredGrace.Seconds()),
+			TelemetryIntervalSeconds:     int64(c.TelemetryInterval.Seconds()),
+			SlowRequestMS:                c.SlowRequestThreshold.Milliseconds(),
+			FeedDedupTTLSeconds:          int64(c.FeedDedupTTL.Seconds()),
+		},
+		Chains: make([]configChain, len(c.SupportedChains)),
+	}
+	if c.DBReplicaDSN != "" {
+		v.DatabaseReplica = store.DescribeDSN(c.DBReplicaDSN)
+	}
+	for _, d := range c.DeadlineWarnings {
+		v.Timeouts.DeadlineWarningsSeconds = append(v.Timeouts.DeadlineWarningsSeconds, int64(d.Seconds()))
+	}
+	for i, ch := range c.SupportedChains {
+		cv := configChain{
+			ChainID: ch.ChainID, SettlementContract: ch.SettlementContract, MinConfirmations: ch.MinConfirmations,
+			MaxTasksPerMinute: ch.MaxTasksPerMinute, EscrowCodeHash: ch.EscrowCodeHash,
+			MaxLogDataBytes: ch.MaxLogDataBytes, LogDedupSize: ch.LogDedupSize, LogDedupTTLSeconds: ch.LogDedupTTLSeconds,
+			LogBufferSize: ch.LogBufferSize, LogQueueSize: ch.LogQueueSize, MaxLagBlocks: ch.FreshnessMaxLagBlocks(), MinAmountWei: ch.MinAmountWei,
+			Name: ch.Name, Symbol: ch.Symbol, Decimals: ch.Decimals, ExplorerTxURLTemplate: ch.ExplorerTxURLTemplate,
+			RPC:       urlOrigin(c.RPCURLs[ch.ChainID]),
+			RPCCAFile: ch.RPCCAFile, RPCInsecureSkipVerify: ch.RPCInsecureSkipVerify,
+			RPCBasicAuth: ch.RPCBasicAuthUser != "" || ch.RPCBasicAuthPassword != "",
+		}
+		if ch.LagAlarmBlocks > 0 {
+			cv.LagAlarmBlocks, cv.LagAlarmClearBlocks = ch.LagAlarmBlocks, ch.LagAlarmClear()
+		}
+		for name := range ch.RPCHeaders {
+			cv.RPCHeaderNames = append(cv.RPCHeaderNames, name)
+		}
+		slices.Sort(cv.RPCHeaderNames)
+		if u, err := url.Parse(c.RPCURLs[ch.ChainID]); err == nil && u.User != nil {
+			cv.RPCBasicAuth = true
+		}
+		v.Chains[i] = cv
+	}
+	return v
+}
+
+// urlOrigin returns scheme://host of raw, dropping credentials, path and
+// query, or "" if raw is empty or not a URL.
+func urlOrigin(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// telemetrySignature names the scheme signing telemetry POSTs, or "none"
+// when the HMAC scheme has no secret. The secret itself is never shown.
+func telemetrySignature(c config.Config) string {
+	if c.TelemetrySignatureScheme == "ed25519" {
+		return "ed25519"
+	}
+	if c.TelemetryHMACSecret == "" {
+		return "none"
+	}
+	return "hmac-sha256"
+}
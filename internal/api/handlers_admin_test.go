@@ -0,0 +1,507 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// auditRepo records the filter passed to ListAuditEvents and returns one
+// page with a next cursor.
+type auditRepo struct {
+	store.TaskRepo
+	gotFilter store.AuditFilter
+	gotCursor *store.Cursor
+}
+
+func (r *auditRepo) ListAuditEvents(_ context.Context, f store.AuditFilter, limit int, cursor *store.Cursor) ([]*store.AuditEvent, *store.Cursor, error) {
+	r.gotFilter, r.gotCursor = f, cursor
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	chainID := 11155111
+	events := []*store.AuditEvent{{ID: 7, Type: "task_status_recovered", Severity: "warn", ChainID: &chainID, Detail: map[string]any{"task_id": "t1"}, CreatedAt: at}}
+	return events, &store.Cursor{CreatedAt: at.Format(time.RFC3339Nano), ObjectID: "7"}, nil
+}
+
+func TestListAuditEvents(t *testing.T) {
+	repo := &auditRepo{}
+	router := NewRouter(nil, repo, config.Config{AdminToken: "secret"}, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("filters_and_cursor", func(t *testing.T) {
+		rec := get("/v1/admin/audit?severity=warn&type=task_status_recovered&chain_id=11155111&since=2025-01-01T00:00:00Z&limit=1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+		}
+		f := repo.gotFilter
+		if f.Severity != "warn" || f.Type != "task_status_recovered" || f.ChainID != 11155111 || f.Since == nil || f.Until != nil {
+			t.Errorf("filter = %+v", f)
+		}
+
+		var resp struct {
+			Items      []auditEventResponse `json:"items"`
+			NextCursor string               `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Items) != 1 || resp.Items[0].ID != 7 || resp.NextCursor == "" {
+			t.Fatalf("unexpected response %s", rec.Body)
+		}
+
+		// The returned cursor round-trips to the repo.
+		if rec := get("/v1/admin/audit?cursor=" + resp.NextCursor); rec.Code != http.StatusOK {
+			t.Fatalf("second page status = %d", rec.Code)
+		}
+		if repo.gotCursor == nil || repo.gotCursor.ObjectID != "7" {
+			t.Errorf("cursor = %+v", repo.gotCursor)
+		}
+	})
+
+	for _, bad := range []string{"severity=debug", "chain_id=abc", "since=yesterday"} {
+		t.Run("invalid_"+bad, func(t *testing.T) {
+			if rec := get("/v1/admin/audit?" + bad); rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+
+	t.Run("expired_cursor", func(t *testing.T) {
+		router := NewRouter(nil, repo, config.Config{AdminToken: "secret", CursorTTL: time.Minute}, nil)
+		old := util.EncodeCursor(&store.Cursor{CreatedAt: "2025-01-01T00:00:00Z", ObjectID: "1", IssuedAt: 1})
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/audit?cursor="+old, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func (r *auditRepo) AckAuditEvent(_ context.Context, id int64, ack bool, by string) (*store.AuditEvent, error) {
+	if id != 7 {
+		return nil, store.ErrNotFound
+	}
+	e := &store.AuditEvent{ID: id, Type: "task_status_recovered", Severity: "warn", CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if ack {
+		at := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+		e.AcknowledgedAt, e.AcknowledgedBy = &at, by
+	}
+	return e, nil
+}
+
+func TestAuditAck(t *testing.T) {
+	repo := &auditRepo{}
+	router := NewRouter(nil, repo, config.Config{AdminToken: "secret", MaxBodyBytes: 1 << 20}, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/v1/admin/audit/7/ack", `{"acknowledged_by":"oncall@example.com"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ack: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var got auditEventResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.AcknowledgedBy != "oncall@example.com" || got.AcknowledgedAt == "" {
+		t.Errorf("ack response = %+v", got)
+	}
+
+	rec = do(http.MethodDelete, "/v1/admin/audit/7/ack", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "acknowledged_") {
+		t.Errorf("unack: status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	if rec := do(http.MethodPost, "/v1/admin/audit/7/ack", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing acknowledged_by: status = %d, want 400", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/v1/admin/audit/99/ack", `{"acknowledged_by":"x"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown id: status = %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/admin/audit?unacknowledged=true", ""); rec.Code != http.StatusOK || !repo.gotFilter.Unacknowledged {
+		t.Errorf("unacknowledged filter not applied: status = %d, filter = %+v", rec.Code, repo.gotFilter)
+	}
+}
+
+// feesRepo returns a fixed ledger and records the filter it was given.
+type feesRepo struct {
+	store.TaskRepo
+	gotFilter store.FeeFilter
+}
+
+func (r *feesRepo) SumFees(_ context.Context, f store.FeeFilter) ([]store.FeeTotal, error) {
+	r.gotFilter = f
+	return []store.FeeTotal{{ChainID: 11155111, Tasks: 2, FeeWei: "2000", Mismatches: 1}}, nil
+}
+
+func (r *feesRepo) ListFees(_ context.Context, f store.FeeFilter) ([]*store.FeeEntry, error) {
+	r.gotFilter = f
+	return []*store.FeeEntry{{
+		TaskID: "t1", ChainID: 11155111, ComputedFeeWei: "2000", EventFeeWei: "1999", FeeWei: "1999", Mismatch: true,
+		ReleasedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), TxHash: "0xabc",
+	}}, nil
+}
+
+func TestGetFees(t *testing.T) {
+	repo := &feesRepo{}
+	router := NewRouter(nil, repo, config.Config{AdminToken: "secret"}, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/v1/admin/fees?chain_id=11155111&from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if f := repo.gotFilter; f.ChainID != 11155111 || f.From == nil || f.To == nil {
+		t.Errorf("filter = %+v", f)
+	}
+	var resp struct {
+		Items []feeTotalResponse `json:"items"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].FeeWei != "2000" || resp.Items[0].Mismatches != 1 {
+		t.Errorf("unexpected response %s", rec.Body)
+	}
+
+	rec = get("/v1/admin/fees?format=csv")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("csv: status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	want := "task_id,chain_id,fee_wei,computed_fee_wei,event_fee_wei,mismatch,released_at,tx_hash\n" +
+		"t1,11155111,1999,2000,1999,true,2025-01-02T03:04:05Z,0xabc\n"
+	if rec.Body.String() != want {
+		t.Errorf("csv body = %q", rec.Body)
+	}
+
+	for _, bad := range []string{"chain_id=0", "from=yesterday", "from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z", "format=xml"} {
+		if rec := get("/v1/admin/fees?" + bad); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", bad, rec.Code)
+		}
+	}
+}
+
+// reverifyRepo serves stored creation requests by task ID.
+type reverifyRepo struct {
+	store.TaskRepo
+	raw map[string][]byte
+}
+
+func (r *reverifyRepo) GetTaskRawRequest(_ context.Context, taskID string) ([]byte, error) {
+	raw, ok := r.raw[taskID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return raw, nil
+}
+
+func TestPostTaskReverify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	repo := &reverifyRepo{raw: map[string][]byte{
+		"t-signed": []byte(signedTaskBody(t, key, "t-signed")),
+		"t-legacy": nil,
+	}}
+	router := NewRouter(nil, repo, config.Config{AdminToken: "secret"}, nil)
+
+	post := func(taskID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/tasks/"+taskID+"/reverify", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("t-signed")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Valid         bool   `json:"valid"`
+		RequestSHA256 string `json:"request_sha256"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Valid || len(resp.RequestSHA256) != 64 {
+		t.Errorf("unexpected response %s", rec.Body)
+	}
+
+	if rec := post("t-legacy"); rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "raw_request_missing") {
+		t.Errorf("legacy task: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if rec := post("t-unknown"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown task: status = %d", rec.Code)
+	}
+}
+
+// notesRepo keeps object notes in memory for the objects it knows.
+type notesRepo struct {
+	store.Repo
+	notes map[string]string
+}
+
+func (r *notesRepo) GetObjectNotes(_ context.Context, id string) (*store.ObjectNotes, error) {
+	n, ok := r.notes[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &store.ObjectNotes{ObjectID: id, Notes: n}, nil
+}
+
+func (r *notesRepo) SetObjectNotes(_ context.Context, id, notes string) (*store.ObjectNotes, error) {
+	if _, ok := r.notes[id]; !ok {
+		return nil, store.ErrNotFound
+	}
+	r.notes[id] = notes
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &store.ObjectNotes{ObjectID: id, Notes: notes, UpdatedAt: &at}, nil
+}
+
+func TestPatchObject_AdminNotes(t *testing.T) {
+	repo := &notesRepo{notes: map[string]string{"bid-1": ""}}
+	audits := &maintenanceRepo{}
+	router := NewRouter(repo, audits, config.Config{AdminToken: "secret", MaxBodyBytes: 1 << 20}, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPatch, "/v1/admin/objects/bid-1", `{"admin_notes":"  duplicate of bid-0  "}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var resp objectNotesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.AdminNotes != "duplicate of bid-0" || resp.AdminNotesUpdatedAt == nil {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(audits.audits) != 1 || audits.audits[0].Type != "object_notes_changed" {
+		t.Errorf("audits = %+v", audits.audits)
+	}
+	if rec := do(http.MethodGet, "/v1/admin/objects/bid-1", ""); !strings.Contains(rec.Body.String(), "duplicate of bid-0") {
+		t.Errorf("GET: status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// Signed fields cannot be patched, and null clears the notes.
+	if rec := do(http.MethodPatch, "/v1/admin/objects/bid-1", `{"payload":{"task_id":"x"}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("patching payload: status = %d, want 400", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/v1/admin/objects/bid-1", `{"admin_notes":null}`); rec.Code != http.StatusOK || repo.notes["bid-1"] != "" {
+		t.Errorf("clearing: status = %d, notes %q", rec.Code, repo.notes["bid-1"])
+	}
+	if rec := do(http.MethodPatch, "/v1/admin/objects/bid-1", `{"admin_notes":"`+strings.Repeat("x", maxAdminNotesLen+1)+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("oversized notes: status = %d, want 400", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/v1/admin/objects/nope", `{"admin_notes":"x"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown object: status = %d, want 404", rec.Code)
+	}
+}
+
+// tombstoneRepo mirrors the store's delete semantics for in-memory objects.
+type tombstoneRepo struct {
+	store.Repo
+	objects map[string]*store.Object
+	deleted map[string]time.Time
+}
+
+func (r *tombstoneRepo) GetObjectByID(_ context.Context, id string) (*store.Object, error) {
+	if at, ok := r.deleted[id]; ok {
+		return nil, &store.GoneError{DeletedAt: at}
+	}
+	if obj, ok := r.objects[id]; ok {
+		return obj, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r *tombstoneRepo) DeleteObject(ctx context.Context, id string, tombstone bool) error {
+	if _, ok := r.objects[id]; !ok {
+		_, err := r.GetObjectByID(ctx, id)
+		return err
+	}
+	delete(r.objects, id)
+	if tombstone {
+		r.deleted[id] = time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	}
+	return nil
+}
+
+func TestDeleteObject_Tombstones(t *testing.T) {
+	for _, tombstones := range []bool{true, false} {
+		repo := &tombstoneRepo{
+			objects: map[string]*store.Object{"bid-1": {Envelope: envelope.Envelope{ObjectID: "bid-1"}}},
+			deleted: map[string]time.Time{},
+		}
+		audits := &maintenanceRepo{}
+		router := NewRouter(repo, audits, config.Config{AdminToken: "secret", ObjectTombstones: tombstones}, nil)
+		do := func(method, path string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec
+		}
+
+		if rec := do(http.MethodDelete, "/v1/admin/objects/bid-1"); rec.Code != http.StatusNoContent {
+			t.Fatalf("tombstones=%v: delete status = %d, body = %s", tombstones, rec.Code, rec.Body)
+		}
+		if len(audits.audits) != 1 || audits.audits[0].Type != "object_deleted" {
+			t.Errorf("tombstones=%v: audits = %+v", tombstones, audits.audits)
+		}
+
+		want := http.StatusNotFound
+		if tombstones {
+			want = http.StatusGone
+		}
+		rec := do(http.MethodGet, "/v1/objects/bid-1")
+		if rec.Code != want {
+			t.Errorf("tombstones=%v: GET deleted status = %d, want %d", tombstones, rec.Code, want)
+		}
+		if tombstones && !strings.Contains(rec.Body.String(), `"deleted_at":"2025-01-02T00:00:00Z"`) {
+			t.Errorf("410 body = %s, want deleted_at", rec.Body)
+		}
+		if rec := do(http.MethodDelete, "/v1/admin/objects/bid-1"); rec.Code != want {
+			t.Errorf("tombstones=%v: repeated delete status = %d, want %d", tombstones, rec.Code, want)
+		}
+		if rec := do(http.MethodGet, "/v1/objects/never"); rec.Code != http.StatusNotFound {
+			t.Errorf("tombstones=%v: unknown object status = %d, want 404", tombstones, rec.Code)
+		}
+	}
+}
+
+// softDeleteRepo mirrors the store's soft-delete semantics for in-memory
+// tasks, honoring store.WithDeleted.
+type softDeleteRepo struct {
+	maintenanceRepo
+	tasks map[string]*store.Task
+}
+
+func (r *softDeleteRepo) GetTask(ctx context.Context, taskID string) (*store.Task, error) {
+	t, ok := r.tasks[taskID]
+	if !ok || (t.DeletedAt != nil && !store.IncludesDeleted(ctx)) {
+		return nil, store.ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *softDeleteRepo) ListTasks(ctx context.Context, _ store.TaskFilter) ([]*store.Task, error) {
+	var out []*store.Task
+	for _, id := range []string{"t-1", "t-2", "t-onchain"} {
+		if t, err := r.GetTask(ctx, id); err == nil {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (r *softDeleteRepo) DeleteTask(ctx context.Context, taskID string, soft bool) error {
+	t, err := r.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if t.OnchainCreatedAt != nil {
+		return store.ErrTaskOnchain
+	}
+	if !soft {
+		delete(r.tasks, taskID)
+		return nil
+	}
+	at := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	r.tasks[taskID].DeletedAt = &at
+	return nil
+}
+
+func TestDeleteTask_SoftDelete(t *testing.T) {
+	onchain := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &softDeleteRepo{tasks: map[string]*store.Task{
+		"t-1":       {TaskID: "t-1", ChainID: 1, Status: store.TaskStatusCreated},
+		"t-2":       {TaskID: "t-2", ChainID: 1, Status: store.TaskStatusCreated},
+		"t-onchain": {TaskID: "t-onchain", ChainID: 1, Status: store.TaskStatusCreated, OnchainCreatedAt: &onchain},
+	}}
+	router := NewRouter(nil, repo, config.Config{AdminToken: "secret", SoftDelete: true}, nil)
+	do := func(method, path string, admin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if admin {
+			req.Header.Set("Authorization", "Bearer secret")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodDelete, "/v1/admin/tasks/t-1", true); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if len(repo.audits) != 1 || repo.audits[0].Type != "task_deleted" {
+		t.Errorf("audits = %+v", repo.audits)
+	}
+	if rec := do(http.MethodDelete, "/v1/admin/tasks/t-onchain", true); rec.Code != http.StatusConflict {
+		t.Errorf("delete onchain task: status = %d, want 409", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/v1/admin/tasks/t-1", true); rec.Code != http.StatusNotFound {
+		t.Errorf("repeated delete: status = %d, want 404", rec.Code)
+	}
+
+	// Hidden by default, even from the admin.
+	for _, admin := range []bool{false, true} {
+		if rec := do(http.MethodGet, "/v1/tasks/t-1", admin); rec.Code != http.StatusNotFound {
+			t.Errorf("admin=%v: GET deleted task status = %d, want 404", admin, rec.Code)
+		}
+		if rec := do(http.MethodGet, "/v1/tasks", admin); strings.Contains(rec.Body.String(), `"t-1"`) {
+			t.Errorf("admin=%v: list includes deleted task: %s", admin, rec.Body)
+		}
+	}
+
+	if rec := do(http.MethodGet, "/v1/tasks/t-1?include_deleted=true", false); rec.Code != http.StatusForbidden {
+		t.Errorf("include_deleted without admin token: status = %d, want 403", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/tasks?include_deleted=maybe", true); rec.Code != http.StatusBadRequest {
+		t.Errorf("include_deleted=maybe: status = %d, want 400", rec.Code)
+	}
+	rec := do(http.MethodGet, "/v1/tasks/t-1?include_deleted=true", true)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"deleted_at":"2025-01-02T00:00:00Z"`) {
+		t.Errorf("admin include_deleted GET: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	rec = do(http.MethodGet, "/v1/tasks?include_deleted=true", true)
+	if !strings.Contains(rec.Body.String(), `"t-1"`) || !strings.Contains(rec.Body.String(), `"t-2"`) {
+		t.Errorf("admin include_deleted list = %s, want t-1 and t-2", rec.Body)
+	}
+}
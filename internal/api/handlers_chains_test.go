@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestChainMetadata(t *testing.T) {
+	const txHash = "0xabc0000000000000000000000000000000000000000000000000000000000def"
+	sepolia := config.ChainConfig{
+		ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C",
+		Name: "Sepolia", Symbol: "SepoliaETH", Decimals: 18,
+		ExplorerTxURLTemplate: "https://sepolia.etherscan.io/tx/{tx_hash}",
+	}
+	bare := config.ChainConfig{ChainID: 84532, SettlementContract: "0x0000000000000000000000000000000000000001"}
+	repo := &acceptRepo{tasks: map[string]*store.Task{
+		"with-meta":   {TaskID: "with-meta", ChainID: sepolia.ChainID, OnchainTxHash: txHash},
+		"not-onchain": {TaskID: "not-onchain", ChainID: sepolia.ChainID},
+		"no-meta":     {TaskID: "no-meta", ChainID: bare.ChainID, OnchainTxHash: txHash},
+	}}
+	router := NewRouter(nil, repo, config.Config{SupportedChains: []config.ChainConfig{sepolia, bare}}, nil)
+
+	get := func(path string, v any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", path, rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+	}
+
+	for _, path := range []string{"/v1/chains", "/v1/meta"} {
+		var resp struct {
+			Chains []map[string]any `json:"chains"`
+		}
+		get(path, &resp)
+		if len(resp.Chains) != 2 {
+			t.Fatalf("%s: %d chains, want 2", path, len(resp.Chains))
+		}
+		if c := resp.Chains[0]; c["name"] != "Sepolia" || c["symbol"] != "SepoliaETH" || c["decimals"] != float64(18) ||
+			c["explorer_tx_url_template"] != sepolia.ExplorerTxURLTemplate {
+			t.Errorf("%s: sepolia = %v", path, c)
+		}
+		if _, ok := resp.Chains[1]["name"]; ok {
+			t.Errorf("%s: chain without metadata has name: %v", path, resp.Chains[1])
+		}
+	}
+
+	cases := []struct {
+		taskID string
+		want   string
+	}{
+		{"with-meta", "https://sepolia.etherscan.io/tx/" + txHash},
+		{"not-onchain", ""},
+		{"no-meta", ""},
+	}
+	for _, tc := range cases {
+		var task taskResponse
+		get("/v1/tasks/"+tc.taskID, &task)
+		if task.ExplorerURL != tc.want {
+			t.Errorf("%s: explorer_url = %q, want %q", tc.taskID, task.ExplorerURL, tc.want)
+		}
+	}
+}
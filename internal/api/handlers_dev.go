@@ -0,0 +1,172 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// The /v1/dev endpoints sign with a caller-supplied or freshly generated
+// throwaway key and show every intermediate value the indexer checks, so
+// client developers can diff their own signing against it. They are only
+// mounted under AMN_DEV_MODE, which Validate refuses on production-looking
+// configs. Nothing is stored.
+
+// devVerification is the result of running a dev signature through the
+// indexer's own verification.
+type devVerification struct {
+	Valid            bool   `json:"valid"`
+	RecoveredAddress string `json:"recovered_address,omitempty"` // eip191 only
+	Error            string `json:"error,omitempty"`
+}
+
+type devSignTaskReq struct {
+	TaskID     string `json:"task_id"`
+	AcceptID   string `json:"accept_id"`   // set: sign a worker accept
+	PrivateKey string `json:"private_key"` // 0x + 64 hex; empty generates one
+}
+
+// PostDevSignTask handles POST /v1/dev/sign-task: the EIP-191 personal_sign
+// an employer makes over keccak256(task_id), or with accept_id the one a
+// worker makes over keccak256(task_id + accept_id).
+func (h *handlers) PostDevSignTask(w http.ResponseWriter, r *http.Request) {
+	var req devSignTaskReq
+	if !h.readDevRequest(w, r, &req) {
+		return
+	}
+	if req.TaskID == "" {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "task_id is required")
+		return
+	}
+
+	generated := req.PrivateKey == ""
+	key, err := ethcrypto.GenerateKey()
+	if !generated {
+		key, err = ethcrypto.HexToECDSA(strings.TrimPrefix(req.PrivateKey, "0x"))
+	}
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "private_key must be 0x + 64 hex chars")
+		return
+	}
+	address := strings.ToLower(ethcrypto.PubkeyToAddress(key.PublicKey).Hex())
+
+	purpose, message := "task", req.TaskID
+	if req.AcceptID != "" {
+		purpose, message = "accept", req.TaskID+req.AcceptID
+	}
+	msgHash := ethutil.Keccak256([]byte(message))
+	digest := ethutil.PersonalSignHash(msgHash)
+	sig, err := ethcrypto.Sign(digest, key)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "sign: "+err.Error())
+		return
+	}
+	sig[64] += 27 // personal_sign V is 27 or 28
+	sigHex := "0x" + hex.EncodeToString(sig)
+
+	var v devVerification
+	if err := ethutil.VerifyPersonalSign([]byte(message), sigHex, address); err != nil {
+		v.Error = err.Error()
+	} else {
+		v.Valid = true
+	}
+	v.RecoveredAddress, _ = ethutil.RecoverPersonalSign(msgHash, sigHex)
+
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"scheme":        "eip191",
+		"purpose":       purpose,
+		"address":       address,
+		"private_key":   "0x" + hex.EncodeToString(ethcrypto.FromECDSA(key)),
+		"generated":     generated,
+		"message":       message,
+		"message_hex":   "0x" + hex.EncodeToString([]byte(message)),
+		"message_hash":  "0x" + hex.EncodeToString(msgHash), // the task_hash for a task
+		"signed_digest": "0x" + hex.EncodeToString(digest),
+		"signature":     sigHex,
+		"verification":  v,
+	})
+}
+
+type devSignEnvelopeReq struct {
+	Envelope   envelope.Envelope `json:"envelope"`    // signer and signature are filled in
+	PrivateKey string            `json:"private_key"` // 32-byte ed25519 seed, hex; empty generates one
+}
+
+// PostDevSignEnvelope handles POST /v1/dev/sign-envelope: the ed25519
+// signature over the canonical JSON of the envelope without its signature,
+// checked the way envelope submissions are.
+func (h *handlers) PostDevSignEnvelope(w http.ResponseWriter, r *http.Request) {
+	var req devSignEnvelopeReq
+	if !h.readDevRequest(w, r, &req) {
+		return
+	}
+
+	generated := req.PrivateKey == ""
+	var key ed25519.PrivateKey
+	var err error
+	if generated {
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		key, err = crypto.PrivateKeyFromSeedHex(strings.TrimPrefix(req.PrivateKey, "0x"))
+	}
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "private_key: "+err.Error())
+		return
+	}
+
+	env := req.Envelope
+	env.Signer = envelope.Signer{Algo: "ed25519", PubKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))}
+	preimage, err := env.SignedPreimageBytes()
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "envelope cannot be canonicalized: "+err.Error())
+		return
+	}
+	env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, preimage))
+
+	var v devVerification
+	for _, check := range []func() error{env.ValidateBasic, env.Verify, env.ValidatePayload} {
+		if err := check(); err != nil {
+			v.Error = err.Error()
+			break
+		}
+	}
+	v.Valid = v.Error == ""
+
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"scheme":       "ed25519",
+		"public_key":   env.Signer.PubKey,
+		"private_key":  hex.EncodeToString(key.Seed()),
+		"generated":    generated,
+		"preimage":     string(preimage),
+		"signature":    env.Signature,
+		"envelope":     env,
+		"verification": v,
+	})
+}
+
+// readDevRequest decodes a dev endpoint body into dst, answering 400 and
+// returning false if it cannot.
+func (h *handlers) readDevRequest(w http.ResponseWriter, r *http.Request, dst any) bool {
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
+		return false
+	}
+	if err := json.Unmarshal(body, dst); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func devPost(t *testing.T, router http.Handler, path, body string, out any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestDevEndpoints_OnlyInDevMode(t *testing.T) {
+	router := NewRouter(nil, nil, config.Config{MaxBodyBytes: 1 << 20}, nil)
+	for _, path := range []string{"/v1/dev/sign-task", "/v1/dev/sign-envelope"} {
+		if code := devPost(t, router, path, `{}`, nil); code != http.StatusNotFound && code != http.StatusMethodNotAllowed {
+			t.Errorf("%s without AMN_DEV_MODE: status %d", path, code)
+		}
+	}
+}
+
+func TestDevSignTask_VerifiesThroughAccept(t *testing.T) {
+	task := fixtureTask(false)
+	repo := &acceptRepo{tasks: map[string]*store.Task{task.TaskID: task}, accepts: map[string]*store.Accept{}}
+	cfg := acceptConfig()
+	cfg.DevMode = true
+	router := NewRouter(nil, repo, cfg, nil)
+
+	var employer struct {
+		Address     string          `json:"address"`
+		MessageHash string          `json:"message_hash"`
+		Signature   string          `json:"signature"`
+		Generated   bool            `json:"generated"`
+		Verify      devVerification `json:"verification"`
+	}
+	const key = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	if code := devPost(t, router, "/v1/dev/sign-task", `{"task_id":"`+task.TaskID+`","private_key":"`+key+`"}`, &employer); code != http.StatusOK {
+		t.Fatalf("sign-task: status %d", code)
+	}
+	if employer.Generated || !employer.Verify.Valid || employer.MessageHash != ethutil.Keccak256Hex([]byte(task.TaskID)) {
+		t.Errorf("sign-task = %+v", employer)
+	}
+	if err := ethutil.VerifyPersonalSign([]byte(task.TaskID), employer.Signature, employer.Address); err != nil {
+		t.Errorf("employer signature does not verify: %v", err)
+	}
+
+	var worker struct {
+		Address   string          `json:"address"`
+		Signature string          `json:"signature"`
+		Generated bool            `json:"generated"`
+		Verify    devVerification `json:"verification"`
+	}
+	if code := devPost(t, router, "/v1/dev/sign-task", `{"task_id":"`+task.TaskID+`","accept_id":"dev-1"}`, &worker); code != http.StatusOK {
+		t.Fatalf("sign-task accept: status %d", code)
+	}
+	if !worker.Generated || !worker.Verify.Valid || worker.Verify.RecoveredAddress != worker.Address {
+		t.Errorf("sign-task accept = %+v", worker)
+	}
+	body, _ := json.Marshal(map[string]string{"accept_id": "dev-1", "worker_address": worker.Address, "signature": worker.Signature})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks/"+task.TaskID+"/accept", strings.NewReader(string(body))))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("accept with the dev signature: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	if code := devPost(t, router, "/v1/dev/sign-task", `{"task_id":"t","private_key":"0x12"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("bad private_key: status %d, want 400", code)
+	}
+}
+
+func TestDevSignEnvelope_VerifiesThroughSubmit(t *testing.T) {
+	router := NewRouter(insertRepo{}, nil, config.Config{MaxBodyBytes: 1 << 20, DevMode: true}, nil)
+
+	var out struct {
+		Preimage  string            `json:"preimage"`
+		Envelope  envelope.Envelope `json:"envelope"`
+		Generated bool              `json:"generated"`
+		Verify    devVerification   `json:"verification"`
+	}
+	req := `{"envelope":{"object_type":"bid","object_version":"0.1","object_id":"dev-bid-1",` +
+		`"created_at":"2026-01-01T00:00:00Z","payload":{"task_id":"t-1","price":"10"}}}`
+	if code := devPost(t, router, "/v1/dev/sign-envelope", req, &out); code != http.StatusOK {
+		t.Fatalf("sign-envelope: status %d", code)
+	}
+	if !out.Generated || !out.Verify.Valid || !strings.HasPrefix(out.Preimage, `{"created_at":"2026-01-01T00:00:00Z","object_id":"dev-bid-1"`) {
+		t.Errorf("sign-envelope = %+v", out)
+	}
+
+	signed, _ := json.Marshal(out.Envelope)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/bids", strings.NewReader(string(signed))))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("submitting the dev envelope: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	// A payload the schema rejects is signed but reported invalid.
+	out.Verify = devVerification{}
+	bad := strings.Replace(req, `"task_id":"t-1",`, ``, 1)
+	if code := devPost(t, router, "/v1/dev/sign-envelope", bad, &out); code != http.StatusOK || out.Verify.Valid || out.Verify.Error == "" {
+		t.Errorf("schema-invalid payload: status %d, verification %+v", code, out.Verify)
+	}
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// headStaleAfter is how long a watcher may go without refreshing the chain
+// head before it is reported as not ready.
+const headStaleAfter = 2 * time.Minute
+
+// chainReadiness is the per-chain entry in GET /v1/health/ready.
+type chainReadiness struct {
+	ChainID               int        `json:"chain_id"`
+	Ready                 bool       `json:"ready"`
+	Connected             bool       `json:"connected"`
+	Mode                  string     `json:"mode,omitempty"`
+	HeadBlock             uint64     `json:"head_block"`
+	SyncedBlock           uint64     `json:"synced_block"`
+	BlockLag              uint64     `json:"block_lag"`
+	LastEventAt           *time.Time `json:"last_event_at,omitempty"`
+	SecondsSinceLastEvent *float64   `json:"seconds_since_last_event,omitempty"`
+	LastError             string     `json:"last_error,omitempty"`
+	DBPaused              bool       `json:"db_paused,omitempty"`
+	SchemaWaiting         bool       `json:"schema_waiting,omitempty"`
+	PendingLogs           int        `json:"pending_logs,omitempty"`
+	LostLogs              int        `json:"lost_logs,omitempty"`
+}
+
+// GetHealthReady handles GET /v1/health/ready. It reports per-chain watcher
+// liveness and returns 503 if no watcher is running (no chain has an RPC URL)
+// or any watcher is disconnected, has a stale head, is paused by its DB
+// circuit breaker or is waiting for the schema.
+// Maintenance mode is reported but does not affect readiness, since reads are
+// still served.
+func (h *handlers) GetHealthReady(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ready := len(h.watchers) > 0
+	chains := make([]chainReadiness, 0, len(h.watchers))
+	for _, wt := range h.watchers {
+		s := wt.Status()
+		c := chainReadiness{
+			ChainID:       s.ChainID,
+			Connected:     s.Connected,
+			Mode:          s.Mode,
+			HeadBlock:     s.HeadBlock,
+			SyncedBlock:   s.SyncedBlock,
+			BlockLag:      s.BlockLag(),
+			LastError:     s.LastError,
+			DBPaused:      s.DBPaused,
+			SchemaWaiting: s.SchemaWaiting,
+			PendingLogs:   s.PendingLogs,
+			LostLogs:      s.LostLogs,
+		}
+		c.Ready = s.Connected && !s.DBPaused && !s.SchemaWaiting && !s.HeadCheckedAt.IsZero() && now.Sub(s.HeadCheckedAt) < headStaleAfter
+		if !s.LastEventAt.IsZero() {
+			at := s.LastEventAt.UTC()
+			since := now.Sub(at).Seconds()
+			c.LastEventAt = &at
+			c.SecondsSinceLastEvent = &since
+		}
+		if !c.Ready {
+			ready = false
+		}
+		chains = append(chains, c)
+	}
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "degraded", http.StatusServiceUnavailable
+	}
+	resp := map[string]any{
+		"status":      status,
+		"time":        now.UTC().Format(time.RFC3339),
+		"chains":      chains,
+		"maintenance": h.maintenance.get(),
+	}
+	if h.cfg.ReadyChainFreshness {
+		warnings := []string{}
+		for _, f := range h.chainFreshness(now) {
+			if !f.OK {
+				warnings = append(warnings, fmt.Sprintf("chain %d is not fresh: %s", f.ChainID, f.Reason))
+			}
+		}
+		resp["warnings"] = warnings
+	}
+	util.WriteJSON(w, code, resp)
+}
+
+// chainFreshness is the per-chain entry in GET /v1/health/chains.
+type chainFreshness struct {
+	ChainID      int    `json:"chain_id"`
+	OK           bool   `json:"ok"`
+	HeadBlock    uint64 `json:"head_block"`
+	SyncedBlock  uint64 `json:"synced_block"`
+	LagBlocks    uint64 `json:"lag_blocks"`
+	MaxLagBlocks int    `json:"max_lag_blocks"`
+	// LagSeconds is how long ago SyncedBlock last advanced, or 0 when it is
+	// at the head.
+	LagSeconds float64 `json:"lag_seconds"`
+	// Reason is why OK is false: "no_head", "head_stale" or "lagging".
+	Reason string `json:"reason,omitempty"`
+}
+
+// freshnessOf reports whether s has processed the chain to within maxLag
+// blocks of a head read in the last headStaleAfter.
+func freshnessOf(s chain.Status, maxLag int, now time.Time) chainFreshness {
+	f := chainFreshness{
+		ChainID:      s.ChainID,
+		HeadBlock:    s.HeadBlock,
+		SyncedBlock:  s.SyncedBlock,
+		LagBlocks:    s.BlockLag(),
+		MaxLagBlocks: maxLag,
+	}
+	if f.LagBlocks > 0 && !s.SyncedAt.IsZero() {
+		f.LagSeconds = now.Sub(s.SyncedAt).Seconds()
+	}
+	switch {
+	case s.HeadCheckedAt.IsZero():
+		f.Reason = "no_head"
+	case now.Sub(s.HeadCheckedAt) >= headStaleAfter:
+		f.Reason = "head_stale"
+	case f.LagBlocks > uint64(maxLag):
+		f.Reason = "lagging"
+	default:
+		f.OK = true
+	}
+	return f
+}
+
+// chainFreshness returns the freshness of every watched chain against its
+// max_lag_blocks. The head is the one the watcher last read, so the check
+// makes no RPC calls.
+func (h *handlers) chainFreshness(now time.Time) []chainFreshness {
+	out := make([]chainFreshness, 0, len(h.watchers))
+	for _, wt := range h.watchers {
+		cc, _ := h.cfg.Chain(wt.ChainID())
+		out = append(out, freshnessOf(wt.Status(), cc.FreshnessMaxLagBlocks(), now))
+	}
+	return out
+}
+
+// GetHealthChains handles GET /v1/health/chains: per-chain data freshness and
+// an overall ok, false (with 503) if any chain is not fresh.
+func (h *handlers) GetHealthChains(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	chains := h.chainFreshness(now)
+	ok := true
+	for _, f := range chains {
+		ok = ok && f.OK
+	}
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusServiceUnavailable
+	}
+	util.WriteJSON(w, code, map[string]any{
+		"ok":     ok,
+		"time":   now.UTC().Format(time.RFC3339),
+		"chains": chains,
+	})
+}
+
+// GetMetrics handles GET /metrics in Prometheus text format.
+func (h *handlers) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.Default.WriteText(w)
+}
@@ -8,14 +8,20 @@ import (
 	"time"
 
 	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
 	"github.com/AgentMesh-Net/indexer-go/internal/util"
 )
 
-// chainInfo is the JSON shape for /v1/meta chains array.
+// chainInfo is the JSON shape for /v1/meta chains array and GET /v1/chains.
+// Display metadata is omitted when not configured.
 type chainInfo struct {
-	ChainID            int    `json:"chain_id"`
-	SettlementContract string `json:"settlement_contract"`
-	MinConfirmations   int    `json:"min_confirmations,omitempty"`
+	ChainID               int    `json:"chain_id"`
+	SettlementContract    string `json:"settlement_contract"`
+	MinConfirmations      int    `json:"min_confirmations,omitempty"`
+	Name                  string `json:"name,omitempty"`
+	Symbol                string `json:"symbol,omitempty"`
+	Decimals              int    `json:"decimals,omitempty"`
+	ExplorerTxURLTemplate string `json:"explorer_tx_url_template,omitempty"`
 }
 
 // metaSignPayload is the canonical payload that gets signed (sorted field names).
@@ -29,25 +35,25 @@ type metaSignPayload struct {
 // GetHealth handles GET /v1/health
 func (h *handlers) GetHealth(w http.ResponseWriter, r *http.Request) {
 	util.WriteJSON(w, http.StatusOK, map[string]any{
-		"status":  "ok",
-		"time":    time.Now().UTC().Format(time.RFC3339),
-		"version": h.cfg.Version,
-		"commit":  h.cfg.Commit,
+		"status":      "ok",
+		"time":        time.Now().UTC().Format(time.RFC3339),
+		"version":     h.cfg.Version,
+		"commit":      h.cfg.Commit,
+		"maintenance": h.maintenance.get(),
 	})
 }
 
-// GetMeta handles GET /v1/meta
+// GetMeta handles GET /v1/meta. signed tells clients whether public_key and
+// signature are present; with AMN_REQUIRE_SIGNED_META unsigned meta is never
+// served and the endpoint answers 503 instead.
 func (h *handlers) GetMeta(w http.ResponseWriter, r *http.Request) {
-	chains := make([]chainInfo, len(h.cfg.SupportedChains))
-	for i, c := range h.cfg.SupportedChains {
-		chains[i] = chainInfo{
-			ChainID:            c.ChainID,
-			SettlementContract: c.SettlementContract,
-			MinConfirmations:   c.MinConfirmations,
-		}
-	}
-
+	chains := h.chainInfos()
 	pubKeyHex, sigHex := h.signMeta(chains)
+	signed := sigHex != ""
+	if !signed && h.cfg.RequireSignedMeta {
+		util.WriteError(w, http.StatusServiceUnavailable, "meta_unsigned", "meta cannot be signed: no usable signing key")
+		return
+	}
 
 	resp := map[string]any{
 		"name":       h.cfg.IndexerName,
@@ -58,11 +64,40 @@ func (h *handlers) GetMeta(w http.ResponseWriter, r *http.Request) {
 		"chains":     chains,
 		"public_key": pubKeyHex,
 		"signature":  sigHex,
+		"signed":     signed,
 		"version":    h.cfg.Version,
+		// Task fields this indexer understands beyond the v0.1 set, and
+		// which endpoint families are served.
+		"capabilities": map[string]any{
+			"task_token_address": true,
+			"legacy_objects":     !h.cfg.DisableLegacyObjects,
+			"v2_tasks":           !h.cfg.DisableV2Tasks,
+		},
 	}
 	util.WriteJSON(w, http.StatusOK, resp)
 }
 
+// GetChains handles GET /v1/chains
+func (h *handlers) GetChains(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSON(w, http.StatusOK, map[string]any{"chains": h.chainInfos()})
+}
+
+func (h *handlers) chainInfos() []chainInfo {
+	chains := make([]chainInfo, len(h.cfg.SupportedChains))
+	for i, c := range h.cfg.SupportedChains {
+		chains[i] = chainInfo{
+			ChainID:               c.ChainID,
+			SettlementContract:    c.SettlementContract,
+			MinConfirmations:      c.MinConfirmations,
+			Name:                  c.Name,
+			Symbol:                c.Symbol,
+			Decimals:              c.Decimals,
+			ExplorerTxURLTemplate: c.ExplorerTxURLTemplate,
+		}
+	}
+	return chains
+}
+
 // GetInfo handles GET /v1/indexer/info (legacy, kept for backwards compat)
 func (h *handlers) GetInfo(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]any{
@@ -85,12 +120,11 @@ func (h *handlers) signMeta(chains []chainInfo) (string, string) {
 	if h.cfg.SigningKeyHex == "" {
 		return "", ""
 	}
-	raw, err := hex.DecodeString(h.cfg.SigningKeyHex)
-	if err != nil || len(raw) != 32 {
+	privKey, err := h.signingKey()
+	if err != nil {
 		log.Printf("invalid INDEXER_SIGNING_KEY: %v", err)
 		return "", ""
 	}
-	privKey := ed25519.NewKeyFromSeed(raw)
 	pubKey := privKey.Public().(ed25519.PublicKey)
 
 	payload := metaSignPayload{
@@ -107,3 +141,8 @@ func (h *handlers) signMeta(chains []chainInfo) (string, string) {
 	sig := ed25519.Sign(privKey, canonical)
 	return hex.EncodeToString(pubKey), hex.EncodeToString(sig)
 }
+
+// signingKey returns the configured ed25519 signing key.
+func (h *handlers) signingKey() (ed25519.PrivateKey, error) {
+	return crypto.PrivateKeyFromSeedHex(h.cfg.SigningKeyHex)
+}
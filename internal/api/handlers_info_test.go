@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+func TestGetMeta_SignedFlag(t *testing.T) {
+	const key = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	cases := []struct {
+		name       string
+		cfg        config.Config
+		wantStatus int
+		wantSigned bool
+	}{
+		{"signed", config.Config{SigningKeyHex: key}, http.StatusOK, true},
+		{"signed_required", config.Config{SigningKeyHex: key, RequireSignedMeta: true}, http.StatusOK, true},
+		{"unsigned", config.Config{}, http.StatusOK, false},
+		{"invalid_key", config.Config{SigningKeyHex: "abcd"}, http.StatusOK, false},
+		{"unsigned_required", config.Config{RequireSignedMeta: true}, http.StatusServiceUnavailable, false},
+		{"invalid_key_required", config.Config{SigningKeyHex: "abcd", RequireSignedMeta: true}, http.StatusServiceUnavailable, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewRouter(nil, nil, tc.cfg, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/meta", nil))
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var meta struct {
+				Signed    *bool  `json:"signed"`
+				PublicKey string `json:"public_key"`
+				Signature string `json:"signature"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &meta); err != nil {
+				t.Fatal(err)
+			}
+			if meta.Signed == nil || *meta.Signed != tc.wantSigned {
+				t.Errorf("signed = %v, want %v", meta.Signed, tc.wantSigned)
+			}
+			if (meta.Signature != "") != tc.wantSigned || (meta.PublicKey != "") != tc.wantSigned {
+				t.Errorf("public_key = %q, signature = %q with signed = %v", meta.PublicKey, meta.Signature, tc.wantSigned)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+func TestPostObject_CanonicalizationErrorCodes(t *testing.T) {
+	router := NewRouter(nil, nil, config.Config{MaxBodyBytes: 1 << 20}, nil)
+	cases := []struct {
+		payload string
+		code    string
+	}{
+		{`{"n":1e400}`, "unsupported_number"},
+		{`{"s":"\ud800"}`, "invalid_utf8"},
+		{"{\"s\":\"\xff\"}", "invalid_utf8"},
+		{`{"a":1,"a":2}`, "malformed_json"},
+	}
+	for _, tc := range cases {
+		body := `{"created_at":"2025-01-01T00:00:00Z","object_id":"01J0000000000000000000TEST",` +
+			`"object_type":"bid","object_version":"0.1","payload":` + tc.payload + `,` +
+			`"signature":"5vNLiFEPahJCdqvg8w7cRZhdMmEBh4OHfF00LV0xGCmU7x5Y4E8YklW+SjYXeCVRC0SxcegUllxfL6GLQA57Bg==",` +
+			`"signer":{"algo":"ed25519","pubkey":"5pCB+DwMAPVHm8aabzPlBWx3kBVX94EOijtjcU4/Gzc="}}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/bids", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusBadRequest || resp.Error.Code != tc.code {
+			t.Errorf("payload %q: got %d %s, want 400 %s", tc.payload, rec.Code, rec.Body, tc.code)
+		}
+	}
+}
+
+// queryRepo records the filter passed to QueryObjects and returns items.
+type queryRepo struct {
+	store.Repo
+	got   store.ObjectFilter
+	items []store.Object
+}
+
+func (r *queryRepo) QueryObjects(_ context.Context, f store.ObjectFilter) ([]store.Object, *store.Cursor, error) {
+	r.got = f
+	return r.items, nil, nil
+}
+
+func TestListObjectsBySigner_TimeWindow(t *testing.T) {
+	repo := &queryRepo{}
+	router := NewRouter(repo, nil, config.Config{}, nil)
+	signer := url.QueryEscape("5pCB+DwMAPVHm8aabzPlBWx3kBVX94EOijtjcU4/Gzc=")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+		"/v1/objects?signer_pubkey="+signer+"&object_type=task&since=2026-01-01T00:00:00Z&until=2026-02-01T00:00:00Z", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	f := repo.got
+	if f.ObjectType != "task" || f.Since == nil || f.Until == nil || f.Until.Month() != time.February || f.Limit != 50 {
+		t.Errorf("filter = %+v", f)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/objects?signer_pubkey="+signer+"&since=january", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad since: status %d, want 400", rec.Code)
+	}
+}
+
+func TestListObjects_CreatedRange(t *testing.T) {
+	repo := &queryRepo{}
+	router := NewRouter(repo, nil, config.Config{}, nil)
+	get := func(target string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec.Code
+	}
+
+	cursor := util.EncodeCursor(&store.Cursor{CreatedAt: "2026-01-20T00:00:00Z", ObjectID: "b-9"})
+	target := "/v1/bids?created_after=" + url.QueryEscape("2026-01-01T02:00:00+02:00") +
+		"&created_before=2026-02-01T00:00:00Z&cursor=" + cursor
+	if code := get(target); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	f := repo.got
+	if f.Since == nil || !f.Since.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || f.Since.Location() != time.UTC {
+		t.Errorf("created_after = %v, want 2026-01-01T00:00:00Z", f.Since)
+	}
+	if f.Until == nil || f.Until.Month() != time.February || f.Cursor == nil || f.ObjectType != "bid" {
+		t.Errorf("filter = %+v", f)
+	}
+
+	for _, target := range []string{
+		"/v1/bids?created_after=yesterday",
+		"/v1/bids?created_after=2026-02-01T00:00:00Z&created_before=2026-02-01T00:00:00Z",
+		"/v1/objects?signer_pubkey=" + url.QueryEscape("5pCB+DwMAPVHm8aabzPlBWx3kBVX94EOijtjcU4/Gzc=") +
+			"&since=2026-01-01T00:00:00Z&created_after=2026-01-01T00:00:00Z",
+	} {
+		if code := get(target); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", target, code)
+		}
+	}
+}
+
+func TestListObjects_PayloadFilters(t *testing.T) {
+	repo := &queryRepo{}
+	router := NewRouter(repo, nil, config.Config{}, nil)
+	get := func(target string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec.Code
+	}
+
+	if code := get("/v1/bids?task_id=t-1&min_amount_wei=0100&max_amount_wei=500"); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if f := repo.got; f.TaskID != "t-1" || f.MinAmountWei != "100" || f.MaxAmountWei != "500" || f.ContentHash != "" {
+		t.Errorf("filter = %+v", f)
+	}
+	if code := get("/v1/artifacts?content_hash=sha256:ab"); code != http.StatusOK || repo.got.ContentHash != "sha256:ab" {
+		t.Errorf("content_hash: status %d, filter %+v", code, repo.got)
+	}
+
+	for _, target := range []string{
+		"/v1/bids?min_amount_wei=-1",
+		"/v1/bids?max_amount_wei=1e18",
+		"/v1/bids?min_amount_wei=10&max_amount_wei=9",
+	} {
+		if code := get(target); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", target, code)
+		}
+	}
+}
+
+func TestListObjectsBySigner_DIDKeyNormalized(t *testing.T) {
+	repo := &queryRepo{}
+	router := NewRouter(repo, nil, config.Config{}, nil)
+	const did = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+	pub, err := crypto.DecodeDIDKey(did)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/objects?signer_pubkey="+url.QueryEscape(did), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if want := base64.StdEncoding.EncodeToString(pub); repo.got.SignerPubKey != want {
+		t.Errorf("signer = %q, want %q", repo.got.SignerPubKey, want)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+		"/v1/objects?signer_pubkey="+url.QueryEscape("did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme"), nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "secp256k1") {
+		t.Errorf("secp256k1 did:key: %d %s", rec.Code, rec.Body)
+	}
+}
+
+// pagedObjectsRepo answers QueryObjects by signer and type with keyset pages,
+// newest first with object_id breaking ties, like the Postgres repo.
+type pagedObjectsRepo struct {
+	store.Repo
+	items []store.Object // newest first
+	calls []store.ObjectFilter
+}
+
+func (r *pagedObjectsRepo) QueryObjects(_ context.Context, f store.ObjectFilter) ([]store.Object, *store.Cursor, error) {
+	r.calls = append(r.calls, f)
+	var out []store.Object
+	for _, o := range r.items {
+		if o.Signer.PubKey != f.SignerPubKey || (f.ObjectType != "" && o.ObjectType != f.ObjectType) {
+			continue
+		}
+		if c := f.Cursor; c != nil && (o.CreatedAt > c.CreatedAt || (o.CreatedAt == c.CreatedAt && o.ObjectID >= c.ObjectID)) {
+			continue
+		}
+		out = append(out, o)
+	}
+	if len(out) <= f.Limit {
+		return out, nil, nil
+	}
+	last := out[f.Limit-1]
+	return out[:f.Limit], &store.Cursor{CreatedAt: last.CreatedAt, ObjectID: last.ObjectID}, nil
+}
+
+func TestListObjectsBySigner_Pagination(t *testing.T) {
+	const signer = "5pCB+DwMAPVHm8aabzPlBWx3kBVX94EOijtjcU4/Gzc="
+	const other = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	object := func(id, typ, key, createdAt string) store.Object {
+		return store.Object{Envelope: envelope.Envelope{
+			ObjectID: id, ObjectType: typ, CreatedAt: createdAt, Signer: envelope.Signer{Algo: "ed25519", PubKey: key},
+		}}
+	}
+	repo := &pagedObjectsRepo{items: []store.Object{
+		object("s-5", "bid", signer, "2026-01-03T00:00:00Z"),
+		object("o-1", "bid", other, "2026-01-02T12:00:00Z"),
+		object("s-4", "artifact", signer, "2026-01-02T00:00:00Z"),
+		object("s-3", "bid", signer, "2026-01-02T00:00:00Z"),
+		object("s-2", "bid", signer, "2026-01-01T00:00:00Z"),
+		object("s-1", "bid", signer, "2026-01-01T00:00:00Z"),
+	}}
+	router := NewRouter(repo, nil, config.Config{}, nil)
+
+	walk := func(query string) []string {
+		t.Helper()
+		var ids []string
+		cursor := ""
+		for page := 0; ; page++ {
+			if page > len(repo.items) {
+				t.Fatal("pagination does not terminate")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+				"/v1/objects?signer_pubkey="+url.QueryEscape(signer)+"&limit=2"+query+cursor, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body)
+			}
+			var resp struct {
+				Items      []envelope.Envelope `json:"items"`
+				NextCursor string              `json:"next_cursor"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			for _, it := range resp.Items {
+				ids = append(ids, it.ObjectID)
+			}
+			if resp.NextCursor == "" {
+				return ids
+			}
+			cursor = "&cursor=" + resp.NextCursor
+		}
+	}
+
+	if got, want := strings.Join(walk(""), ","), "s-5,s-4,s-3,s-2,s-1"; got != want {
+		t.Errorf("pages = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(walk("&object_type=bid"), ","), "s-5,s-3,s-2,s-1"; got != want {
+		t.Errorf("bid pages = %s, want %s", got, want)
+	}
+	for _, f := range repo.calls {
+		if f.SignerPubKey != signer || f.Limit != 2 {
+			t.Fatalf("filter = %+v", f)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/objects?signer_pubkey="+url.QueryEscape(signer)+"&limit=1000", nil))
+	if rec.Code != http.StatusOK || repo.calls[len(repo.calls)-1].Limit != 200 {
+		t.Errorf("limit=1000: status %d, filter %+v", rec.Code, repo.calls[len(repo.calls)-1])
+	}
+	for _, target := range []string{
+		"/v1/objects",
+		"/v1/objects?signer_pubkey=not-a-key",
+		"/v1/objects?signer_pubkey=" + url.QueryEscape(signer) + "&object_type=nope",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+func TestListObjects_ReceivedOrder(t *testing.T) {
+	seen := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &queryRepo{items: []store.Object{{
+		Envelope: envelope.Envelope{ObjectType: "bid", ObjectID: "b-1", CreatedAt: "2020-01-01T00:00:00Z"}, FirstSeenAt: seen,
+	}}}
+	router := NewRouter(repo, nil, config.Config{SyncLag: time.Minute}, nil)
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	before := time.Now()
+	rec := get("/v1/bids?order=received")
+	if rec.Code != http.StatusOK || repo.got.Order != store.OrderReceived || repo.got.ObjectType != "bid" {
+		t.Fatalf("status %d, filter %+v: %s", rec.Code, repo.got, rec.Body)
+	}
+	if bound := repo.got.ReceivedBefore; bound.Before(before.Add(-time.Minute)) || bound.After(time.Now().Add(-time.Minute)) {
+		t.Errorf("ReceivedBefore = %s, want AMN_SYNC_LAG_SECONDS before the request", bound)
+	}
+	if get("/v1/bids"); !repo.got.ReceivedBefore.IsZero() {
+		t.Errorf("created order: ReceivedBefore = %s, want none", repo.got.ReceivedBefore)
+	}
+	var page struct {
+		Items []struct {
+			ObjectID    string    `json:"object_id"`
+			FirstSeenAt time.Time `json:"first_seen_at"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil || len(page.Items) != 1 || !page.Items[0].FirstSeenAt.Equal(seen) {
+		t.Errorf("items = %+v (%v), want first_seen_at %s", page.Items, err, seen)
+	}
+
+	// A consumer that has caught up gets its cursor back to poll with.
+	repo.items = nil
+	received := util.EncodeCursor(&store.Cursor{CreatedAt: "2026-03-01T12:00:00Z", ObjectID: "b-1", Order: store.OrderReceived})
+	rec = get("/v1/bids?order=received&cursor=" + received)
+	var empty struct {
+		NextCursor string `json:"next_cursor"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &empty)
+	if rec.Code != http.StatusOK || repo.got.Cursor == nil || empty.NextCursor == "" {
+		t.Errorf("caught up: status %d, cursor %+v, body %s", rec.Code, repo.got.Cursor, rec.Body)
+	}
+
+	for _, target := range []string{
+		"/v1/bids?cursor=" + received,
+		"/v1/bids?order=received&cursor=" + util.EncodeCursor(&store.Cursor{CreatedAt: "2026-03-01T12:00:00Z", ObjectID: "b-1"}),
+		"/v1/bids?order=newest",
+	} {
+		if rec := get(target); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+func TestPostObject_StatusByFailureClass(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, otherPriv, _ := ed25519.GenerateKey(nil)
+	sign := func(pub ed25519.PublicKey, priv ed25519.PrivateKey, objectType, id, payload string) string {
+		env := envelope.Envelope{
+			ObjectType: objectType, ObjectVersion: "0.1", ObjectID: id, CreatedAt: "2025-01-01T00:00:00Z",
+			Payload: json.RawMessage(payload),
+			Signer:  envelope.Signer{Algo: "ed25519", PubKey: base64.StdEncoding.EncodeToString(pub)},
+		}
+		preimage, _ := env.SignedPreimageBytes()
+		env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, preimage))
+		body, _ := json.Marshal(env)
+		return string(body)
+	}
+	repo := objectsByID{objects: map[string]*store.Object{}}
+	router := NewRouter(repo, nil, config.Config{MaxBodyBytes: 1 << 20}, nil)
+	post := func(path, body string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec.Code
+	}
+	var task envelope.Envelope
+	if err := json.Unmarshal([]byte(sign(pub, priv, "task", "obj-task", `{"title":"x"}`)), &task); err != nil {
+		t.Fatal(err)
+	}
+	repo.objects[task.ObjectID] = &store.Object{Envelope: task}
+	if code := post("/v1/bids", sign(pub, priv, "bid", "obj-bid", `{"task_id":"obj-task"}`)); code != http.StatusCreated {
+		t.Fatalf("seed bid: status %d", code)
+	}
+
+	cases := []struct {
+		name, path, body string
+		want             int
+	}{
+		{"malformed_json", "/v1/bids", `{"object_id":`, http.StatusBadRequest},
+		{"unsupported_version", "/v1/bids", strings.Replace(sign(pub, priv, "bid", "b-1", `{}`), `"0.1"`, `"9.9"`, 1), http.StatusBadRequest},
+		{"wrong_object_type", "/v1/bids", sign(pub, priv, "task", "b-2", `{}`), http.StatusBadRequest},
+		{"bad_signature", "/v1/bids", sign(pub, otherPriv, "bid", "b-3", `{}`), http.StatusBadRequest},
+		{"accept_without_task_id", "/v1/accepts", sign(pub, priv, "accept", "a-1", `{}`), http.StatusBadRequest},
+		{"accept_unknown_task", "/v1/accepts", sign(pub, priv, "accept", "a-2", `{"task_id":"nope"}`), http.StatusNotFound},
+		{"accept_of_non_task", "/v1/accepts", sign(pub, priv, "accept", "a-3", `{"task_id":"obj-bid"}`), http.StatusUnprocessableEntity},
+		{"accept_by_other_signer", "/v1/accepts", sign(otherPub, otherPriv, "accept", "a-4", `{"task_id":"obj-task"}`), http.StatusUnprocessableEntity},
+		{"valid_accept", "/v1/accepts", sign(pub, priv, "accept", "a-5", `{"task_id":"obj-task"}`), http.StatusCreated},
+	}
+	for _, tc := range cases {
+		if code := post(tc.path, tc.body); code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// defaultUnfundedOlderThan is the older_than of GET /v1/reports/unfunded-accepts
+// when none is given.
+const defaultUnfundedOlderThan = 24 * time.Hour
+
+type unfundedAcceptItem struct {
+	TaskID          string    `json:"task_id"`
+	ChainID         int       `json:"chain_id"`
+	EmployerAddress string    `json:"employer_address"`
+	WorkerAddress   string    `json:"worker_address"`
+	AmountWei       string    `json:"amount_wei"`
+	AcceptedAt      time.Time `json:"accepted_at"`
+	AgeSeconds      int64     `json:"age_seconds"`
+}
+
+type unfundedEmployer struct {
+	EmployerAddress string `json:"employer_address"`
+	UnfundedAccepts int64  `json:"unfunded_accepts"`
+}
+
+// ── GET /v1/reports/unfunded-accepts ────────────────────────────────────────
+
+// GetUnfundedAccepts reports tasks a worker accepted whose employer has not
+// created the escrow within older_than (default 24h), oldest first, plus
+// the count per employer as a reputation signal. Items are public tasks
+// only; employer counts include private ones.
+func (h *handlers) GetUnfundedAccepts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	olderThan := defaultUnfundedOlderThan
+	if s := q.Get("older_than"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "older_than must be a positive duration (e.g. 24h)")
+			return
+		}
+		olderThan = d
+	}
+	limit := 50
+	if s := q.Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+
+	now := time.Now().UTC()
+	before := now.Add(-olderThan)
+	rows, err := h.taskRepo.ListUnfundedAccepts(r.Context(), before, limit)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list unfunded accepts")
+		return
+	}
+	counts, err := h.taskRepo.CountUnfundedAcceptsByEmployer(r.Context(), before)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to count unfunded accepts")
+		return
+	}
+
+	redact := h.redactAddresses(r)
+	address := func(a string) string {
+		if redact {
+			return redactAddress(a)
+		}
+		return a
+	}
+	items := make([]unfundedAcceptItem, len(rows))
+	for i, u := range rows {
+		items[i] = unfundedAcceptItem{
+			TaskID:          u.TaskID,
+			ChainID:         u.ChainID,
+			EmployerAddress: address(u.EmployerAddress),
+			WorkerAddress:   address(u.WorkerAddress),
+			AmountWei:       u.AmountWei,
+			AcceptedAt:      u.AcceptedAt,
+			AgeSeconds:      int64(now.Sub(u.AcceptedAt) / time.Second),
+		}
+	}
+	employers := make([]unfundedEmployer, 0, len(counts))
+	for a, n := range counts {
+		employers = append(employers, unfundedEmployer{EmployerAddress: address(a), UnfundedAccepts: n})
+	}
+	// Most unfunded accepts first; address order keeps the output stable.
+	slices.SortFunc(employers, func(a, b unfundedEmployer) int {
+		return cmp.Or(cmp.Compare(b.UnfundedAccepts, a.UnfundedAccepts), cmp.Compare(a.EmployerAddress, b.EmployerAddress))
+	})
+
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"older_than": olderThan.String(),
+		"as_of":      now,
+		"items":      items,
+		"employers":  employers,
+	})
+}
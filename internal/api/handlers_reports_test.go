@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// unfundedRepo serves tasks accepted at fixed times and records the cutoff
+// it was asked for.
+type unfundedRepo struct {
+	store.TaskRepo
+	accepted []store.UnfundedAccept
+	before   time.Time
+}
+
+func (r *unfundedRepo) ListUnfundedAccepts(_ context.Context, before time.Time, limit int) ([]store.UnfundedAccept, error) {
+	r.before = before
+	var out []store.UnfundedAccept
+	for _, u := range r.accepted {
+		if u.AcceptedAt.Before(before) && len(out) < limit {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (r *unfundedRepo) CountUnfundedAcceptsByEmployer(_ context.Context, before time.Time) (map[string]int64, error) {
+	counts := map[string]int64{}
+	for _, u := range r.accepted {
+		if u.AcceptedAt.Before(before) {
+			counts[u.EmployerAddress]++
+		}
+	}
+	return counts, nil
+}
+
+func TestGetUnfundedAccepts(t *testing.T) {
+	const (
+		ghost  = "0x00000000000000000000000000000000000000e1"
+		other  = "0x00000000000000000000000000000000000000e2"
+		worker = "0x00000000000000000000000000000000000000a1"
+	)
+	now := time.Now().UTC()
+	repo := &unfundedRepo{accepted: []store.UnfundedAccept{
+		{TaskID: "t-old", ChainID: 1, EmployerAddress: ghost, WorkerAddress: worker, AmountWei: "1", AcceptedAt: now.Add(-72 * time.Hour)},
+		{TaskID: "t-mid", ChainID: 1, EmployerAddress: ghost, WorkerAddress: worker, AmountWei: "1", AcceptedAt: now.Add(-30 * time.Hour)},
+		{TaskID: "t-other", ChainID: 1, EmployerAddress: other, WorkerAddress: worker, AmountWei: "1", AcceptedAt: now.Add(-26 * time.Hour)},
+		{TaskID: "t-new", ChainID: 1, EmployerAddress: other, WorkerAddress: worker, AmountWei: "1", AcceptedAt: now.Add(-time.Hour)},
+	}}
+	router := NewRouter(nil, repo, config.Config{RedactAddresses: true}, nil)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/reports/unfunded-accepts"+query, nil))
+		return rec
+	}
+
+	var body struct {
+		OlderThan string               `json:"older_than"`
+		Items     []unfundedAcceptItem `json:"items"`
+		Employers []unfundedEmployer   `json:"employers"`
+	}
+	rec := get("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.OlderThan != "24h0m0s" || len(body.Items) != 3 {
+		t.Fatalf("default report = %+v, want 24h and 3 items", body)
+	}
+	if d := time.Since(repo.before) - 24*time.Hour; d < 0 || d > time.Minute {
+		t.Errorf("cutoff = %s, want 24h ago", repo.before)
+	}
+	if it := body.Items[0]; it.TaskID != "t-old" || it.AgeSeconds < 72*3600 || it.AgeSeconds > 72*3600+60 {
+		t.Errorf("first item = %+v, want t-old aged 72h", it)
+	}
+	if it := body.Items[0]; it.EmployerAddress != redactAddress(ghost) || it.WorkerAddress != redactAddress(worker) {
+		t.Errorf("addresses not redacted: %+v", it)
+	}
+	if len(body.Employers) != 2 || body.Employers[0].UnfundedAccepts != 2 || body.Employers[1].UnfundedAccepts != 1 {
+		t.Errorf("employers = %+v, want 2 then 1", body.Employers)
+	}
+
+	body.Items = nil
+	rec = get("?older_than=48h")
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Items) != 1 || body.Items[0].TaskID != "t-old" {
+		t.Errorf("older_than=48h items = %+v, want t-old", body.Items)
+	}
+
+	for _, q := range []string{"?older_than=-1h", "?older_than=soon"} {
+		if rec := get(q); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
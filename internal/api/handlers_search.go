@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// ── GET /v1/search/tx/{txHash} ────────────────────────────────────────────────
+
+type txTaskMatchResponse struct {
+	Event string       `json:"event"`
+	Task  taskResponse `json:"task"`
+}
+
+type txSearchResponse struct {
+	TxHash string                `json:"tx_hash"`
+	Tasks  []txTaskMatchResponse `json:"tasks"`
+}
+
+// SearchTx returns every task touched by an onchain transaction, across all
+// chains. A task appears once per event it recorded from that transaction.
+func (h *handlers) SearchTx(w http.ResponseWriter, r *http.Request) {
+	txHash := chi.URLParam(r, "txHash")
+	if !reHexHash.MatchString(txHash) {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "tx_hash must be 0x + 64 hex chars")
+		return
+	}
+	txHash = strings.ToLower(txHash)
+
+	matches, err := h.taskRepo.FindTasksByTxHash(r.Context(), txHash)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to search tasks")
+		return
+	}
+
+	// Private tasks the reader may not see are left out, as in GET /v1/tasks.
+	resp := txSearchResponse{TxHash: txHash, Tasks: make([]txTaskMatchResponse, 0, len(matches))}
+	for _, m := range matches {
+		full, ok := h.taskAccess(w, r, m.Task)
+		if !ok {
+			return
+		}
+		if full {
+			resp.Tasks = append(resp.Tasks, txTaskMatchResponse{Event: m.Event, Task: h.taskView(r, m.Task)})
+		}
+	}
+	util.WriteJSON(w, http.StatusOK, resp)
+}
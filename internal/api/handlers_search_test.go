@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// txSearchRepo serves FindTasksByTxHash from a fixed map keyed by tx hash.
+type txSearchRepo struct {
+	store.TaskRepo
+	byTx map[string][]*store.TxTaskMatch
+}
+
+func (r *txSearchRepo) FindTasksByTxHash(_ context.Context, txHash string) ([]*store.TxTaskMatch, error) {
+	return r.byTx[txHash], nil
+}
+
+func TestSearchTx(t *testing.T) {
+	const shared = "0x00000000000000000000000000000000000000000000000000000000000000aa"
+
+	created := fixtureTask(false)
+	created.TaskID = "task-a"
+	released := fixtureTask(true)
+	released.TaskID = "task-b"
+	released.ChainID = 1
+
+	repo := &txSearchRepo{byTx: map[string][]*store.TxTaskMatch{
+		shared: {
+			{Event: store.TxEventCreated, Task: created},
+			{Event: store.TxEventReleased, Task: released},
+		},
+	}}
+	router := NewRouter(nil, repo, config.Config{}, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("multiple_matches", func(t *testing.T) {
+		// Upper-case input is normalised before lookup.
+		rec := get("/v1/search/tx/" + strings.ToUpper(shared[:2]) + strings.ToUpper(shared[2:]))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+		}
+		var resp txSearchResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.TxHash != shared {
+			t.Errorf("tx_hash = %q, want %q", resp.TxHash, shared)
+		}
+		if len(resp.Tasks) != 2 {
+			t.Fatalf("expected 2 matches, got %+v", resp.Tasks)
+		}
+		if resp.Tasks[0].Event != "created" || resp.Tasks[0].Task.TaskID != "task-a" {
+			t.Errorf("first match = %+v", resp.Tasks[0])
+		}
+		if resp.Tasks[1].Event != "released" || resp.Tasks[1].Task.TaskID != "task-b" || resp.Tasks[1].Task.ChainID != 1 {
+			t.Errorf("second match = %+v", resp.Tasks[1])
+		}
+	})
+
+	t.Run("no_matches", func(t *testing.T) {
+		rec := get("/v1/search/tx/0x" + strings.Repeat("0", 64))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), `"tasks":[]`) {
+			t.Errorf("expected empty tasks array, got %s", rec.Body)
+		}
+	})
+
+	t.Run("invalid_hash", func(t *testing.T) {
+		if rec := get("/v1/search/tx/0x1234"); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
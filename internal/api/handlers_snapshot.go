@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// snapshotCacheTTL is how long GET /v1/snapshot serves the same body. Expiry
+// is the only invalidation: widgets poll it, and 30s of staleness is fine.
+const snapshotCacheTTL = 30 * time.Second
+
+// snapshotRecentTasks is how many recent public tasks a snapshot lists.
+const snapshotRecentTasks = 10
+
+// snapshotTask is the reduced task shape in a snapshot: no addresses.
+type snapshotTask struct {
+	TaskID    string `json:"task_id"`
+	Title     string `json:"title"`
+	AmountWei string `json:"amount_wei"`
+	Status    string `json:"status"`
+}
+
+type snapshotIdentity struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Owner     string `json:"owner"`
+	Contact   string `json:"contact"`
+	FeeBPS    int    `json:"fee_bps"`
+	Version   string `json:"version"`
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// snapshot is the signed part of a GET /v1/snapshot response.
+type snapshot struct {
+	Indexer       snapshotIdentity `json:"indexer"`
+	TasksByStatus map[string]int64 `json:"tasks_by_status"`
+	Totals        []tvlTotal       `json:"totals"`
+	RecentTasks   []snapshotTask   `json:"recent_tasks"`
+	AsOf          string           `json:"as_of"`
+}
+
+// snapshotCache holds the last encoded snapshot response. The zero value is
+// empty.
+type snapshotCache struct {
+	mu   sync.Mutex
+	body []byte
+	asOf time.Time
+}
+
+// get returns the cached body, rebuilding it with build when older than
+// snapshotCacheTTL.
+func (c *snapshotCache) get(ctx context.Context, build func(context.Context, time.Time) ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.asOf.IsZero() && time.Since(c.asOf) < snapshotCacheTTL {
+		return c.body, nil
+	}
+	now := time.Now().UTC()
+	body, err := build(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	c.body, c.asOf = body, now
+	return c.body, nil
+}
+
+// ── GET /v1/snapshot ───────────────────────────────────────────────────────
+
+// GetSnapshot serves a small dashboard summary for embeddable widgets: task
+// counts by status, escrowed totals per chain, the most recent public tasks
+// without addresses, and the indexer identity. It carries nothing beyond
+// what the public reads already disclose, so it is sent with a permissive
+// CORS header (AMN_SNAPSHOT_CORS_ORIGIN). With a signing key, signature is
+// ed25519 over the RFC 8785 canonical form of snapshot, verifiable with
+// public_key as in /v1/meta.
+func (h *handlers) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	if o := h.cfg.SnapshotCORSOrigin; o != "" && o != "none" {
+		w.Header().Set("Access-Control-Allow-Origin", o)
+	}
+	body, err := h.snapshot.get(r.Context(), h.buildSnapshot)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to build snapshot")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(body)
+}
+
+// buildSnapshot encodes the GET /v1/snapshot body as of now.
+func (h *handlers) buildSnapshot(ctx context.Context, now time.Time) ([]byte, error) {
+	counts, err := h.taskRepo.CountTasksByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totals, _, err := h.tvl.get(ctx, h.taskRepo.SumTVL)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := h.taskRepo.ListTasks(ctx, store.TaskFilter{Limit: snapshotRecentTasks})
+	if err != nil {
+		return nil, err
+	}
+	recent := make([]snapshotTask, 0, len(tasks))
+	for _, t := range tasks {
+		// ListTasks never returns private tasks; the widget is public, so
+		// make sure regardless.
+		if t.Visibility == store.TaskVisibilityPrivate {
+			continue
+		}
+		recent = append(recent, snapshotTask{TaskID: t.TaskID, Title: t.Title, AmountWei: t.AmountWei, Status: t.Status})
+	}
+	if totals == nil {
+		totals = []tvlTotal{}
+	}
+
+	snap := snapshot{
+		Indexer: snapshotIdentity{
+			Name:    h.cfg.IndexerName,
+			URL:     h.cfg.IndexerBaseURL,
+			Owner:   h.cfg.IndexerOwner,
+			Contact: h.cfg.IndexerContact,
+			FeeBPS:  h.cfg.FeeBPS,
+			Version: h.cfg.Version,
+		},
+		TasksByStatus: counts,
+		Totals:        totals,
+		RecentTasks:   recent,
+		AsOf:          now.Format(time.RFC3339),
+	}
+	resp := map[string]any{"snapshot": &snap}
+	if h.cfg.SigningKeyHex != "" {
+		if key, err := h.signingKey(); err != nil {
+			log.Printf("invalid INDEXER_SIGNING_KEY: %v", err)
+		} else {
+			snap.Indexer.PublicKey = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+			canonical, err := canonicaljson.Canonicalize(snap)
+			if err != nil {
+				return nil, err
+			}
+			resp["public_key"] = snap.Indexer.PublicKey
+			resp["signature"] = hex.EncodeToString(ed25519.Sign(key, canonical))
+		}
+	}
+	return json.Marshal(resp)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// snapshotRepo serves fixed stats and recent tasks, one of them private,
+// and counts ListTasks calls.
+type snapshotRepo struct {
+	store.TaskRepo
+	lists int
+}
+
+func (r *snapshotRepo) CountTasksByStatus(context.Context) (map[string]int64, error) {
+	return map[string]int64{store.TaskStatusCreated: 3, store.TaskStatusReleased: 1}, nil
+}
+
+func (r *snapshotRepo) SumTVL(context.Context) ([]store.TVLTotal, error) {
+	return []store.TVLTotal{{ChainID: 11155111, Tasks: 1, AmountWei: "500"}}, nil
+}
+
+func (r *snapshotRepo) ListTasks(_ context.Context, f store.TaskFilter) ([]*store.Task, error) {
+	r.lists++
+	if f.Limit != snapshotRecentTasks {
+		return nil, nil
+	}
+	return []*store.Task{
+		{TaskID: "t-public", Title: "public", AmountWei: "500", Status: store.TaskStatusCreated,
+			EmployerAddress: "0x00000000000000000000000000000000000000e1", WorkerAddress: "0x00000000000000000000000000000000000000a1"},
+		{TaskID: "t-private", Title: "private", AmountWei: "7", Status: store.TaskStatusCreated,
+			Visibility: store.TaskVisibilityPrivate, EmployerAddress: "0x00000000000000000000000000000000000000e1"},
+	}, nil
+}
+
+func TestGetSnapshot(t *testing.T) {
+	repo := &snapshotRepo{}
+	router := NewRouter(nil, repo, config.Config{
+		IndexerName:        "test-indexer",
+		SigningKeyHex:      "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		SnapshotCORSOrigin: "*",
+	}, nil)
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/snapshot", nil))
+		return rec
+	}
+
+	rec := get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "t-private") || strings.Contains(body, "0x00000000000000000000000000000000000000e1") {
+		t.Errorf("snapshot leaks a private task or an address: %s", body)
+	}
+
+	var resp struct {
+		Snapshot  json.RawMessage `json:"snapshot"`
+		PublicKey string          `json:"public_key"`
+		Signature string          `json:"signature"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var snap snapshot
+	if err := json.Unmarshal(resp.Snapshot, &snap); err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.RecentTasks) != 1 || snap.RecentTasks[0] != (snapshotTask{TaskID: "t-public", Title: "public", AmountWei: "500", Status: store.TaskStatusCreated}) {
+		t.Errorf("recent_tasks = %+v, want only t-public", snap.RecentTasks)
+	}
+	if snap.TasksByStatus[store.TaskStatusCreated] != 3 || len(snap.Totals) != 1 || snap.Indexer.Name != "test-indexer" {
+		t.Errorf("snapshot = %+v", snap)
+	}
+
+	pub, _ := hex.DecodeString(resp.PublicKey)
+	sig, _ := hex.DecodeString(resp.Signature)
+	canonical, err := canonicaljson.CanonicalizeRaw(resp.Snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, canonical, sig) {
+		t.Error("snapshot signature does not verify")
+	}
+	if snap.Indexer.PublicKey != resp.PublicKey {
+		t.Errorf("indexer.public_key = %q, want %q", snap.Indexer.PublicKey, resp.PublicKey)
+	}
+
+	if again := get(); again.Body.String() != rec.Body.String() || repo.lists != 1 {
+		t.Errorf("second request rebuilt the snapshot (ListTasks calls = %d)", repo.lists)
+	}
+}
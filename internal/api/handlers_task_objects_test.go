@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// taskObjectsRepo serves both repos for ListTaskObjects from in-memory data.
+type taskObjectsRepo struct {
+	store.Repo
+	store.TaskRepo
+	tasks   map[string]*store.Task
+	objects []envelope.Envelope
+}
+
+func (r *taskObjectsRepo) GetTask(_ context.Context, id string) (*store.Task, error) {
+	if t, ok := r.tasks[id]; ok {
+		return t, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r *taskObjectsRepo) ListObjectsForTask(_ context.Context, taskID, linked string, limit int) ([]envelope.Envelope, error) {
+	var out []envelope.Envelope
+	for _, o := range r.objects {
+		if len(out) == limit {
+			break
+		}
+		var p struct {
+			TaskID string `json:"task_id"`
+		}
+		_ = json.Unmarshal(o.Payload, &p)
+		if p.TaskID == taskID || (linked != "" && o.ObjectID == linked) {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func TestListTaskObjects(t *testing.T) {
+	linked := fixtureTask(false)
+	linked.TaskID, linked.EnvelopeObjectID = "linked", "env-task"
+	unlinked := fixtureTask(false)
+	unlinked.TaskID = "unlinked"
+
+	repo := &taskObjectsRepo{
+		tasks: map[string]*store.Task{"linked": linked, "unlinked": unlinked},
+		objects: []envelope.Envelope{
+			{ObjectID: "env-task", ObjectType: "task", Payload: json.RawMessage(`{"title":"t"}`)},
+			{ObjectID: "env-bid", ObjectType: "bid", Payload: json.RawMessage(`{"task_id":"linked"}`)},
+		},
+	}
+	router := NewRouter(repo, repo, config.Config{}, nil)
+
+	cases := []struct {
+		taskID     string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"linked", http.StatusOK, []string{"env-task", "env-bid"}},
+		{"unlinked", http.StatusOK, []string{}},
+		{"missing", http.StatusNotFound, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.taskID, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/"+tc.taskID+"/objects", nil))
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantIDs == nil {
+				return
+			}
+			var resp struct {
+				Task  taskResponse        `json:"task"`
+				Items []envelope.Envelope `json:"items"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Task.TaskID != tc.taskID || resp.Items == nil || len(resp.Items) != len(tc.wantIDs) {
+				t.Fatalf("response = %s", rec.Body)
+			}
+			for i, id := range tc.wantIDs {
+				if resp.Items[i].ObjectID != id {
+					t.Errorf("items[%d] = %s, want %s", i, resp.Items[i].ObjectID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestListTaskObjects_Truncated(t *testing.T) {
+	repo := &taskObjectsRepo{
+		tasks: map[string]*store.Task{"t1": fixtureTask(false)},
+		objects: []envelope.Envelope{
+			{ObjectID: "bid-1", ObjectType: "bid", Payload: json.RawMessage(`{"task_id":"t1"}`)},
+			{ObjectID: "bid-2", ObjectType: "bid", Payload: json.RawMessage(`{"task_id":"t1"}`)},
+		},
+	}
+	repo.tasks["t1"].TaskID = "t1"
+	router := NewRouter(repo, repo, config.Config{}, nil)
+
+	for _, tc := range []struct {
+		query         string
+		wantIDs       int
+		wantTruncated bool
+	}{
+		{"?limit=1", 1, true},
+		{"?limit=2", 2, false},
+		{"", 2, false},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/t1/objects"+tc.query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d", tc.query, rec.Code)
+		}
+		var resp struct {
+			Items     []envelope.Envelope `json:"items"`
+			Truncated bool                `json:"truncated"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Items) != tc.wantIDs || resp.Truncated != tc.wantTruncated {
+			t.Errorf("%q: %d items, truncated %v; want %d, %v", tc.query, len(resp.Items), resp.Truncated, tc.wantIDs, tc.wantTruncated)
+		}
+	}
+}
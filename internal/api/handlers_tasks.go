@@ -3,10 +3,18 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"math/big"
 	"net/http"
+	"net/url"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
 	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
 	"github.com/AgentMesh-Net/indexer-go/internal/store"
 	"github.com/AgentMesh-Net/indexer-go/internal/util"
 )
@@ -30,29 +38,13 @@ func (h *handlers) PostObject(expectedType string) http.HandlerFunc {
 			return
 		}
 
-		if err := env.ValidateBasic(); err != nil {
-			code := errorCode(err)
-			util.WriteError(w, http.StatusBadRequest, code, err.Error())
+		queued, err := h.objectService().Submit(r.Context(), &env, expectedType)
+		if err != nil {
+			writeServiceError(w, err)
 			return
 		}
-
-		if env.ObjectType != expectedType {
-			util.WriteError(w, http.StatusBadRequest, "invalid_request",
-				"object_type must be "+expectedType+" for this endpoint")
-			return
-		}
-
-		if err := env.Verify(); err != nil {
-			util.WriteError(w, http.StatusBadRequest, "invalid_signature", err.Error())
-			return
-		}
-
-		if err := h.repo.InsertObject(r.Context(), &env); err != nil {
-			if errors.Is(err, store.ErrConflict) {
-				util.WriteError(w, http.StatusConflict, "conflict", "object_id already exists")
-				return
-			}
-			util.WriteError(w, http.StatusInternalServerError, "internal", "failed to store object")
+		if queued {
+			util.WriteJSON(w, http.StatusAccepted, map[string]any{"object_id": env.ObjectID, "status": "queued"})
 			return
 		}
 
@@ -60,48 +52,270 @@ func (h *handlers) PostObject(expectedType string) http.HandlerFunc {
 	}
 }
 
-// ListObjects returns a handler that lists objects of the given type with pagination.
+// ListObjects returns a handler that lists objects of the given type with
+// pagination. order=received lists them in the order this indexer stored them.
+// created_after and created_before restrict the created_at window; see
+// parseCreatedRange. Payload fields filter as in parsePayloadFilter.
 func (h *handlers) ListObjects(objectType string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		limit := util.ParseLimit(r, 50, 200)
-		cursor := util.ParseCursor(r)
+		f := store.ObjectFilter{ObjectType: objectType, Limit: util.ParseLimit(r, 50, 200)}
+		if err := parseCreatedRange(r.URL.Query(), &f); err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		if err := parsePayloadFilter(r.URL.Query(), &f); err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		var err error
+		f.Cursor, err = util.ParseCursor(r, h.cfg.CursorTTL)
+		if err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		if f.Order, err = parseObjectOrder(r, f.Cursor); err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		if f.Order == store.OrderReceived {
+			f.ReceivedBefore = h.syncBound()
+		}
+		verify, err := parseVerify(r)
+		if err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
 
-		items, next, err := h.repo.ListObjects(r.Context(), objectType, limit, cursor)
+		items, next, err := h.repo.QueryObjects(r.Context(), f)
 		if err != nil {
 			util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list objects")
 			return
 		}
+		util.WriteJSON(w, http.StatusOK, h.objectListResponse(items, next, f, verify))
+	}
+}
 
-		resp := map[string]any{
-			"items": items,
+// parseCreatedRange reads the created_at window of an object listing into f:
+// created_after is inclusive and created_before exclusive, so consecutive
+// windows never overlap. since and until are older names for the same bounds.
+// Timestamps are RFC 3339 with any offset and are compared as instants.
+func parseCreatedRange(q url.Values, f *store.ObjectFilter) error {
+	for _, p := range []struct {
+		name, alias string
+		dst         **time.Time
+	}{{"created_after", "since", &f.Since}, {"created_before", "until", &f.Until}} {
+		name, s := p.name, q.Get(p.name)
+		if a := q.Get(p.alias); a != "" {
+			if s != "" {
+				return fmt.Errorf("use either %s or %s, not both", p.name, p.alias)
+			}
+			name, s = p.alias, a
 		}
-		if next != nil {
-			resp["next_cursor"] = util.EncodeCursor(next)
+		if s == "" {
+			continue
 		}
-		util.WriteJSON(w, http.StatusOK, resp)
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return errors.New(name + " must be an RFC 3339 timestamp")
+		}
+		t = t.UTC()
+		*p.dst = &t
 	}
+	if f.Since != nil && f.Until != nil && !f.Since.Before(*f.Until) {
+		return errors.New("created_after must be earlier than created_before")
+	}
+	return nil
 }
 
-func errorCode(err error) string {
-	msg := err.Error()
-	if contains(msg, "object_version") {
-		return "unsupported_version"
+// parsePayloadFilter reads the payload filters of an object listing into f:
+// task_id, an artifact's content_hash, and an inclusive min_amount_wei /
+// max_amount_wei range on a bid's amount_wei. Objects without the field
+// never match its filter.
+func parsePayloadFilter(q url.Values, f *store.ObjectFilter) error {
+	f.TaskID, f.ContentHash = q.Get("task_id"), q.Get("content_hash")
+	var bounds [2]*big.Int
+	for i, p := range []struct {
+		name string
+		dst  *string
+	}{{"min_amount_wei", &f.MinAmountWei}, {"max_amount_wei", &f.MaxAmountWei}} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok || n.Sign() < 0 || len(s) > 78 {
+			return errors.New(p.name + " must be a non-negative decimal integer")
+		}
+		bounds[i], *p.dst = n, n.String()
 	}
-	if contains(msg, "signature") || contains(msg, "pubkey") || contains(msg, "base64") {
-		return "invalid_signature"
+	if bounds[0] != nil && bounds[1] != nil && bounds[0].Cmp(bounds[1]) > 0 {
+		return errors.New("min_amount_wei must not exceed max_amount_wei")
 	}
-	return "invalid_request"
+	return nil
 }
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && searchString(s, substr)
+// parseObjectOrder reads the order query parameter of an object listing
+// ("created", the default, or "received") and checks that cursor, if any,
+// was issued for the same order.
+func parseObjectOrder(r *http.Request, cursor *store.Cursor) (string, error) {
+	var order string
+	switch o := r.URL.Query().Get("order"); o {
+	case "", "created":
+		order = store.OrderCreated
+	case store.OrderReceived:
+		order = store.OrderReceived
+	default:
+		return "", fmt.Errorf("invalid order %q (want created or received)", o)
+	}
+	if cursor != nil && cursor.Order != order {
+		return "", errors.New("cursor was issued for a different order; restart paging without a cursor")
+	}
+	return order, nil
 }
 
-func searchString(s, sub string) bool {
-	for i := 0; i <= len(s)-len(sub); i++ {
-		if s[i:i+len(sub)] == sub {
-			return true
-		}
+// syncBound is the upper bound of a forward-sync read: rows stored after it
+// may belong to transactions still committing; see config.SyncLag.
+func (h *handlers) syncBound() time.Time {
+	return time.Now().UTC().Add(-h.cfg.SyncLag)
+}
+
+// objectListResponse builds an object list body. An empty received-order page
+// hands the request cursor back, freshly stamped, so a consumer polling for
+// new objects never has to restart from the beginning. With verify, each item
+// carries its read-time verification.
+func (h *handlers) objectListResponse(items []store.Object, next *store.Cursor, f store.ObjectFilter, verify bool) map[string]any {
+	if next == nil && f.Order == store.OrderReceived && f.Cursor != nil {
+		c := *f.Cursor
+		c.IssuedAt = 0
+		next = &c
+	}
+	resp := map[string]any{
+		"items": items,
+	}
+	if verify {
+		resp["items"] = h.verifier.objects(items)
+	}
+	if next != nil {
+		resp["next_cursor"] = util.EncodeCursor(next)
+	}
+	return resp
+}
+
+// GetObject handles GET /v1/objects/{objectID}[?verify=true].
+func (h *handlers) GetObject(w http.ResponseWriter, r *http.Request) {
+	verify, err := parseVerify(r)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	env, err := h.repo.GetObjectByID(r.Context(), chi.URLParam(r, "objectID"))
+	if err != nil {
+		h.writeObjectError(w, err, "failed to get object")
+		return
+	}
+	if verify {
+		util.WriteJSON(w, http.StatusOK, h.verifier.objects([]store.Object{*env})[0])
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, env)
+}
+
+// writeObjectError answers a failed object lookup: 410 with the deletion time
+// for a tombstone when AMN_OBJECT_TOMBSTONES is set, else 404 for any miss.
+func (h *handlers) writeObjectError(w http.ResponseWriter, err error, internal string) {
+	var gone *store.GoneError
+	if h.cfg.ObjectTombstones && errors.As(err, &gone) {
+		util.WriteJSON(w, http.StatusGone, map[string]any{
+			"error":      util.APIError{Code: "gone", Message: "object was deleted"},
+			"deleted_at": gone.DeletedAt,
+		})
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		util.WriteError(w, http.StatusNotFound, "not_found", "object not found")
+		return
+	}
+	util.WriteError(w, http.StatusInternalServerError, "internal", internal)
+}
+
+// ListObjectsBySigner handles GET /v1/objects?signer_pubkey=<base64|did:key>[&object_type=...].
+// The created_at window and order are as for ListObjects.
+func (h *handlers) ListObjectsBySigner(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("signer_pubkey") == "" {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "signer_pubkey is required")
+		return
 	}
-	return false
+	// Objects are stored under the base64 key, so a did:key matches too.
+	signer, err := crypto.NormalizeSignerKey(q.Get("signer_pubkey"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "signer_pubkey: "+err.Error())
+		return
+	}
+	objectType := q.Get("object_type")
+	if objectType != "" && !envelope.ValidObjectTypes[objectType] {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid object_type: "+objectType)
+		return
+	}
+
+	f := store.ObjectFilter{ObjectType: objectType, SignerPubKey: signer}
+	if err := parseCreatedRange(q, &f); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if err := parsePayloadFilter(q, &f); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	f.Limit = util.ParseLimit(r, 50, 200)
+	cursor, err := util.ParseCursor(r, h.cfg.CursorTTL)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	f.Cursor = cursor
+	if f.Order, err = parseObjectOrder(r, cursor); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if f.Order == store.OrderReceived {
+		f.ReceivedBefore = h.syncBound()
+	}
+	verify, err := parseVerify(r)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	items, next, err := h.repo.QueryObjects(r.Context(), f)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list objects")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, h.objectListResponse(items, next, f, verify))
+}
+
+// writeServiceError writes a service error with the status for its kind.
+// Errors that are not *service.Error are reported as internal.
+func writeServiceError(w http.ResponseWriter, err error) {
+	var se *service.Error
+	if !errors.As(err, &se) {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "internal error")
+		return
+	}
+	util.WriteError(w, serviceErrorStatus[se.Kind], se.Code, se.Message)
+}
+
+var serviceErrorStatus = map[service.Kind]int{
+	service.KindInternal:           http.StatusInternalServerError,
+	service.KindInvalid:            http.StatusBadRequest,
+	service.KindUnauthorized:       http.StatusUnauthorized,
+	service.KindForbidden:          http.StatusForbidden,
+	service.KindNotFound:           http.StatusNotFound,
+	service.KindConflict:           http.StatusConflict,
+	service.KindRateLimited:        http.StatusTooManyRequests,
+	service.KindUnavailable:        http.StatusServiceUnavailable,
+	service.KindPreconditionFailed: http.StatusPreconditionFailed,
+	service.KindUnprocessable:      http.StatusUnprocessableEntity,
 }
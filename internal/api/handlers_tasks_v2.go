@@ -7,57 +7,26 @@ package api
 //   POST /v1/tasks/{taskID}/accept
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"math/big"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"golang.org/x/crypto/sha3"
 
-	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
 	"github.com/AgentMesh-Net/indexer-go/internal/store"
 	"github.com/AgentMesh-Net/indexer-go/internal/util"
 )
 
 var reHexAddr = regexp.MustCompile(`(?i)^0x[0-9a-fA-F]{40}$`)
 var reHexHash = regexp.MustCompile(`(?i)^0x[0-9a-fA-F]{64}$`)
-var reHexSig  = regexp.MustCompile(`(?i)^0x[0-9a-fA-F]{130}$`) // 65 bytes = 130 hex chars
-
-// ── Request types ──────────────────────────────────────────────────────────────
-
-type createTaskReq struct {
-	TaskID          string         `json:"task_id"`
-	Title           string         `json:"title"`
-	ChainID         int            `json:"chain_id"`
-	AmountWei       string         `json:"amount_wei"`
-	DeadlineUnix    int64          `json:"deadline_unix"`
-	EmployerAddress string         `json:"employer_address"`
-	TaskHash        string         `json:"task_hash"`
-	EscrowAddress   string         `json:"escrow_address"`
-	Signature       string         `json:"signature"`   // required: EIP-191 personal_sign over keccak256(task_id)
-	Payload         map[string]any `json:"payload"`     // optional extra metadata
-}
-
-type acceptTaskReq struct {
-	AcceptID      string `json:"accept_id"`
-	WorkerAddress string `json:"worker_address"`
-	Signature     string `json:"signature"` // required: EIP-191 personal_sign over keccak256(task_id + accept_id)
-}
-
-// ── keccak256 helper ───────────────────────────────────────────────────────────
-
-func keccak256Hex(data []byte) string {
-	h := sha3.NewLegacyKeccak256()
-	h.Write(data)
-	return "0x" + hex.EncodeToString(h.Sum(nil))
-}
 
 // ── POST /v1/tasks ─────────────────────────────────────────────────────────────
 
@@ -68,133 +37,186 @@ func (h *handlers) PostTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req createTaskReq
+	var req service.CreateTaskRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
 		return
 	}
 
-	// Validate required fields
-	if req.TaskID == "" {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "task_id is required")
+	task, err := h.taskService().CreateTask(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
-	if req.ChainID == 0 {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "chain_id is required")
-		return
+
+	resp := map[string]any{
+		"task_id":          task.TaskID,
+		"task_hash":        task.TaskHash,
+		"status":           task.Status,
+		"chain_id":         task.ChainID,
+		"escrow_address":   task.EscrowAddress,
+		"employer_address": task.EmployerAddress,
+		"amount_wei":       task.AmountWei,
+		"deadline_unix":    task.DeadlineUnix,
+		"indexer_fee_bps":  task.IndexerFeeBPS,
 	}
-	if !reHexAddr.MatchString(req.EmployerAddress) {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "employer_address must be 0x + 40 hex chars")
-		return
+	if task.EmployerSequence != nil {
+		resp["sequence"] = *task.EmployerSequence
 	}
-	if !reHexHash.MatchString(req.TaskHash) {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "task_hash must be 0x + 64 hex chars")
-		return
+	if task.Visibility == store.TaskVisibilityPrivate {
+		resp["visibility"] = task.Visibility
+		resp["allowed_workers"] = task.AllowedWorkers
 	}
-
-	// Validate amount_wei > 0
-	amtStr := strings.TrimSpace(req.AmountWei)
-	amt, ok := new(big.Int).SetString(amtStr, 10)
-	if !ok || amt.Sign() <= 0 {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "amount_wei must be a positive integer string")
-		return
+	if task.TokenAddress != "" {
+		resp["token_address"] = task.TokenAddress
 	}
+	util.WriteJSON(w, http.StatusCreated, resp)
+}
 
-	// Validate deadline
-	if req.DeadlineUnix <= 0 || req.DeadlineUnix > (1<<62) {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "deadline_unix out of valid range")
-		return
-	}
+// ── POST /v1/tasks/batch ───────────────────────────────────────────────────────
 
-	// Verify task_hash == keccak256(utf8(task_id))
-	expected := keccak256Hex([]byte(req.TaskID))
-	if !strings.EqualFold(req.TaskHash, expected) {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request",
-			fmt.Sprintf("task_hash mismatch: expected %s, got %s", expected, req.TaskHash))
-		return
-	}
+// taskBatchRequest is the body of POST /v1/tasks/batch.
+type taskBatchRequest struct {
+	Tasks []service.CreateTaskRequest `json:"tasks"`
+	// AllOrNothing stores no task unless every task is valid and stored.
+	AllOrNothing bool `json:"all_or_nothing"`
+}
+
+// taskBatchItem is the outcome of one task in a batch, by its index in the
+// request.
+type taskBatchItem struct {
+	Index  int            `json:"index"`
+	TaskID string         `json:"task_id"`
+	Status string         `json:"status,omitempty"`
+	Error  *util.APIError `json:"error,omitempty"`
+}
 
-	// A1: Employer signature verification (EIP-191 personal_sign over keccak256(task_id))
-	if req.Signature == "" {
-		util.WriteError(w, http.StatusUnauthorized, "unauthorized", "signature is required")
+// PostTaskBatch creates up to AMN_MAX_BATCH_TASKS tasks, each validated and
+// verified as by POST /v1/tasks, in one transaction. The response is 200
+// with a result per task and a summary, whether or not every task was
+// created.
+func (h *handlers) PostTaskBatch(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
 		return
 	}
-	if !reHexSig.MatchString(req.Signature) {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "signature must be 0x + 130 hex chars")
+
+	var req taskBatchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
 		return
 	}
-	if err := ethutil.VerifyPersonalSign([]byte(req.TaskID), req.Signature, req.EmployerAddress); err != nil {
-		if errors.Is(err, ethutil.ErrSignerMismatch) || errors.Is(err, ethutil.ErrInvalidSignature) {
-			util.WriteError(w, http.StatusUnauthorized, "unauthorized",
-				"signature verification failed: signer does not match employer_address")
-			return
-		}
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "signature error: "+err.Error())
+
+	results, err := h.taskService().CreateTasks(r.Context(), req.Tasks, req.AllOrNothing)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
-	// Validate chain_id is supported
-	escrow := req.EscrowAddress
-	chainOK := false
-	for _, c := range h.cfg.SupportedChains {
-		if c.ChainID == req.ChainID {
-			chainOK = true
-			if escrow == "" {
-				escrow = c.SettlementContract
+	items := make([]taskBatchItem, len(results))
+	created := 0
+	for i, res := range results {
+		items[i] = taskBatchItem{Index: i, TaskID: req.Tasks[i].TaskID}
+		if res.Err != nil {
+			var se *service.Error
+			if !errors.As(res.Err, &se) {
+				se = &service.Error{Code: "internal", Message: "internal error"}
 			}
-			break
+			items[i].Error = &util.APIError{Code: se.Code, Message: se.Message}
+			continue
 		}
+		items[i].Status = res.Task.Status
+		created++
 	}
-	if !chainOK {
-		supported := make([]string, len(h.cfg.SupportedChains))
-		for i, c := range h.cfg.SupportedChains {
-			supported[i] = strconv.Itoa(c.ChainID)
-		}
-		util.WriteError(w, http.StatusBadRequest, "invalid_request",
-			fmt.Sprintf("chain_id %d not supported (supported: %s)", req.ChainID, strings.Join(supported, ",")))
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"results": items,
+		"summary": map[string]int{"created": created, "failed": len(items) - created},
+	})
+}
+
+// ── GET /v1/tasks/compute-hash ────────────────────────────────────────────────
+
+// GetTaskComputeHash returns the task_hash POST /v1/tasks expects for the
+// task_id query parameter, so clients can pass the same value to the
+// settlement contract before creating the task.
+func (h *handlers) GetTaskComputeHash(w http.ResponseWriter, r *http.Request) {
+	taskID := r.URL.Query().Get("task_id")
+	if taskID == "" {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "task_id is required")
 		return
 	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"task_id":   taskID,
+		"task_hash": service.TaskHash(taskID),
+		"scheme":    service.TaskHashScheme,
+		// When set, the contract's getTaskHash must return the same value.
+		"onchain_verification": h.cfg.EnableOnchainHashVerification,
+	})
+}
 
-	task := &store.Task{
-		TaskID:            req.TaskID,
-		TaskHash:          strings.ToLower(req.TaskHash),
-		ChainID:           req.ChainID,
-		EscrowAddress:     escrow,
-		EmployerAddress:   strings.ToLower(req.EmployerAddress),
-		EmployerSignature: strings.ToLower(req.Signature),
-		AmountWei:         amtStr,
-		DeadlineUnix:      req.DeadlineUnix,
-		Title:             req.Title,
-		Status:            store.TaskStatusCreated,
-		IndexerFeeBPS:     h.cfg.FeeBPS,
-	}
+// ── GET /v1/employers/{address}/next-sequence ─────────────────────────────────
 
-	if err := h.taskRepo.InsertTask(r.Context(), task); err != nil {
-		if errors.Is(err, store.ErrConflict) {
-			util.WriteError(w, http.StatusConflict, "conflict", "task_id already exists")
-			return
-		}
-		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to store task")
+func (h *handlers) GetNextEmployerSequence(w http.ResponseWriter, r *http.Request) {
+	addr := chi.URLParam(r, "address")
+	if !reHexAddr.MatchString(addr) {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "address must be 0x + 40 hex chars")
 		return
 	}
+	next, err := h.taskRepo.NextEmployerSequence(r.Context(), addr)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to get next sequence")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"employer_address": strings.ToLower(addr),
+		"next_sequence":    next,
+	})
+}
 
-	util.WriteJSON(w, http.StatusCreated, map[string]any{
-		"task_id":          task.TaskID,
-		"task_hash":        task.TaskHash,
-		"status":           task.Status,
-		"chain_id":         task.ChainID,
-		"escrow_address":   task.EscrowAddress,
-		"employer_address": task.EmployerAddress,
-		"amount_wei":       task.AmountWei,
-		"deadline_unix":    task.DeadlineUnix,
-		"indexer_fee_bps":  task.IndexerFeeBPS,
+// ── GET /v1/employers/{address}/quota ─────────────────────────────────────────
+
+// GetEmployerQuota reports how many more tasks the employer may open under
+// AMN_MAX_OPEN_TASKS_PER_EMPLOYER. remaining is null when there is no limit.
+func (h *handlers) GetEmployerQuota(w http.ResponseWriter, r *http.Request) {
+	addr := chi.URLParam(r, "address")
+	if !reHexAddr.MatchString(addr) {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "address must be 0x + 40 hex chars")
+		return
+	}
+	open, err := h.taskRepo.CountOpenTasks(r.Context(), addr)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to count open tasks")
+		return
+	}
+	limit := h.cfg.MaxOpenTasksPerEmployer
+	var remaining *int64
+	if limit > 0 {
+		n := max(int64(limit)-open, 0)
+		remaining = &n
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"employer_address": strings.ToLower(addr),
+		"open_task_limit":  limit,
+		"open_tasks":       open,
+		"remaining":        remaining,
 	})
 }
 
 // ── GET /v1/tasks ──────────────────────────────────────────────────────────────
 
+// ListTasks lists public tasks newest first. onchain=synced|unsynced keeps
+// tasks whose creation has or has not been seen onchain, and older_than (a
+// duration such as 24h) those created at least that long ago; together they
+// surface registrations whose escrow was never funded. With updated_since it
+// switches to delta sync; see listTasksUpdatedSince.
 func (h *handlers) ListTasks(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
+	fields, err := parseTaskFields(r)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
 	chainID := 0
 	if s := q.Get("chain_id"); s != "" {
 		chainID, _ = strconv.Atoi(s)
@@ -213,23 +235,110 @@ func (h *handlers) ListTasks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tasks, err := h.taskRepo.ListTasks(r.Context(), chainID, status, limit, offset)
+	if q.Has("updated_since") {
+		if q.Has("onchain") || q.Has("older_than") {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "onchain and older_than cannot be combined with updated_since")
+			return
+		}
+		h.listTasksUpdatedSince(w, r, chainID, status, limit, fields)
+		return
+	}
+
+	f := store.TaskFilter{ChainID: chainID, Status: status, Limit: limit, Offset: offset}
+	switch o := q.Get("onchain"); o {
+	case "", store.OnchainSynced, store.OnchainUnsynced:
+		f.Onchain = o
+	default:
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid onchain %q (want synced or unsynced)", o))
+		return
+	}
+	if s := q.Get("older_than"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "older_than must be a positive duration (e.g. 24h)")
+			return
+		}
+		before := time.Now().UTC().Add(-d)
+		f.CreatedBefore = &before
+	}
+
+	tasks, err := h.taskRepo.ListTasks(r.Context(), f)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list tasks")
+		return
+	}
+
+	resp := taskListResponse{Items: make([]taskResponse, 0, len(tasks))}
+	for _, t := range tasks {
+		resp.Items = append(resp.Items, h.taskView(r, t))
+	}
+	if fields != nil {
+		util.WriteJSON(w, http.StatusOK, map[string]any{"items": fields.tasks(resp.Items)})
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, resp)
+}
+
+// listTasksUpdatedSince returns public tasks changed after updated_since
+// (RFC 3339, exclusive) and up to server_time, oldest change first, paged
+// with next_cursor. server_time trails the clock by AMN_SYNC_LAG_SECONDS so
+// no change still committing falls behind it; a client passes it as the
+// next updated_since once it has drained next_cursor. Soft-deleted tasks
+// are listed under deleted rather than items.
+func (h *handlers) listTasksUpdatedSince(w http.ResponseWriter, r *http.Request, chainID int, status string, limit int, fields taskFieldSet) {
+	since, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("updated_since"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "updated_since must be an RFC 3339 timestamp")
+		return
+	}
+	cursor, err := util.ParseCursor(r, h.cfg.CursorTTL)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if cursor != nil && cursor.Order != store.OrderUpdated {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "cursor was not issued for updated_since; restart paging without a cursor")
+		return
+	}
+
+	serverTime := h.syncBound()
+	tasks, next, err := h.taskRepo.ListTasksUpdatedSince(r.Context(), since, serverTime, chainID, status, limit, cursor)
 	if err != nil {
 		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list tasks")
 		return
 	}
 
-	items := make([]map[string]any, 0, len(tasks))
+	resp := taskDeltaResponse{Items: make([]taskResponse, 0, len(tasks)), Deleted: []taskDeletion{}, ServerTime: serverTime}
 	for _, t := range tasks {
-		items = append(items, taskToMap(t))
+		if t.DeletedAt != nil {
+			resp.Deleted = append(resp.Deleted, taskDeletion{TaskID: t.TaskID, DeletedAt: t.DeletedAt.UTC()})
+			continue
+		}
+		resp.Items = append(resp.Items, h.taskView(r, t))
+	}
+	if next != nil {
+		resp.NextCursor = util.EncodeCursor(next)
+	}
+	if fields != nil {
+		out := map[string]any{"items": fields.tasks(resp.Items), "deleted": resp.Deleted, "server_time": resp.ServerTime}
+		if resp.NextCursor != "" {
+			out["next_cursor"] = resp.NextCursor
+		}
+		util.WriteJSON(w, http.StatusOK, out)
+		return
 	}
-	util.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+	util.WriteJSON(w, http.StatusOK, resp)
 }
 
 // ── GET /v1/tasks/{taskID} ─────────────────────────────────────────────────────
 
 func (h *handlers) GetTask(w http.ResponseWriter, r *http.Request) {
 	taskID := chi.URLParam(r, "taskID")
+	fields, err := parseTaskFields(r)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
 	task, err := h.taskRepo.GetTask(r.Context(), taskID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -239,56 +348,100 @@ func (h *handlers) GetTask(w http.ResponseWriter, r *http.Request) {
 		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to get task")
 		return
 	}
-	util.WriteJSON(w, http.StatusOK, taskToMap(task))
+	full, ok := h.taskAccess(w, r, task)
+	if !ok {
+		return
+	}
+	if !full {
+		util.WriteJSON(w, http.StatusOK, newPrivateTaskView(task))
+		return
+	}
+	w.Header().Set("ETag", taskETag(task))
+	util.WriteJSON(w, http.StatusOK, fields.task(h.taskView(r, task)))
 }
 
-// ── POST /v1/tasks/{taskID}/accept ────────────────────────────────────────────
-
-func (h *handlers) PostTaskAccept(w http.ResponseWriter, r *http.Request) {
-	taskID := chi.URLParam(r, "taskID")
+// taskETag is the strong ETag of a task: its updated_at, which every change
+// to the task bumps. POST /v1/tasks/{taskID}/accept takes it in If-Match.
+func taskETag(t *store.Task) string {
+	return `"` + t.UpdatedAt.UTC().Format(time.RFC3339Nano) + `"`
+}
 
-	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
-	if err != nil || int64(len(body)) > h.maxBody {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
-		return
+// parseIfMatch reads the task updated_at from an If-Match header holding a
+// taskETag or a bare RFC 3339 timestamp. Empty and "*" impose no condition.
+func parseIfMatch(v string) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" || v == "*" {
+		return nil, nil
+	}
+	v = strings.Trim(strings.TrimPrefix(v, "W/"), `"`)
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return nil, errors.New("If-Match must be a task ETag or an RFC 3339 updated_at")
 	}
+	return &t, nil
+}
 
-	var req acceptTaskReq
-	if err := json.Unmarshal(body, &req); err != nil {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
+// ── GET /v1/tasks/{taskID}/objects ────────────────────────────────────────────
+
+// ListTaskObjects returns the envelope objects related to a structured task:
+// the linked envelope_object_id, if any, and the objects whose payload
+// carries the task_id. Oldest first, so the result reads as the negotiation
+// history, and at most ?limit of them: truncated reports that more exist,
+// to be paged with GET /v1/objects?task_id=. ?verify=true annotates each
+// with its read-time verification.
+func (h *handlers) ListTaskObjects(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	verify, err := parseVerify(r)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
 		return
 	}
-
-	if req.AcceptID == "" {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "accept_id is required")
+	task, err := h.taskRepo.GetTask(r.Context(), taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			util.WriteError(w, http.StatusNotFound, "not_found", "task not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to get task")
 		return
 	}
-	if !reHexAddr.MatchString(req.WorkerAddress) {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "worker_address must be 0x + 40 hex chars")
+	if full, ok := h.taskAccess(w, r, task); !ok {
+		return
+	} else if !full {
+		writeTaskPrivate(w)
 		return
 	}
 
-	// A2: Worker signature verification (EIP-191 personal_sign over keccak256(task_id + accept_id))
-	if req.Signature == "" {
-		util.WriteError(w, http.StatusUnauthorized, "unauthorized", "signature is required")
+	limit := util.ParseLimit(r, 100, 200)
+	items, err := h.repo.ListObjectsForTask(r.Context(), task.TaskID, task.EnvelopeObjectID, limit+1)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list objects")
 		return
 	}
-	if !reHexSig.MatchString(req.Signature) {
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "signature must be 0x + 130 hex chars")
-		return
+	truncated := len(items) > limit
+	if truncated {
+		items = items[:limit]
 	}
-	workerSigMsg := []byte(taskID + req.AcceptID)
-	if err := ethutil.VerifyPersonalSign(workerSigMsg, req.Signature, req.WorkerAddress); err != nil {
-		if errors.Is(err, ethutil.ErrSignerMismatch) || errors.Is(err, ethutil.ErrInvalidSignature) {
-			util.WriteError(w, http.StatusUnauthorized, "unauthorized",
-				"signature verification failed: signer does not match worker_address")
-			return
-		}
-		util.WriteError(w, http.StatusBadRequest, "invalid_request", "signature error: "+err.Error())
-		return
+	if items == nil {
+		items = []envelope.Envelope{}
+	}
+	resp := map[string]any{
+		"task":      h.taskView(r, task),
+		"items":     items,
+		"truncated": truncated,
+	}
+	if verify {
+		resp["items"] = h.verifier.envelopes(items)
 	}
+	util.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ── GET /v1/tasks/{taskID}/preview ────────────────────────────────────────────
 
-	// Verify task exists and is in created state
+// GetTaskPreview returns a redacted task suitable for public embedding. Fields
+// listed in cfg.PreviewOmittedFields are dropped as well.
+func (h *handlers) GetTaskPreview(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
 	task, err := h.taskRepo.GetTask(r.Context(), taskID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -298,69 +451,208 @@ func (h *handlers) PostTaskAccept(w http.ResponseWriter, r *http.Request) {
 		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to get task")
 		return
 	}
-	if task.Status != store.TaskStatusCreated {
-		util.WriteError(w, http.StatusConflict, "conflict",
-			fmt.Sprintf("task is not in 'created' state (current: %s)", task.Status))
+	full, ok := h.taskAccess(w, r, task)
+	if !ok {
 		return
 	}
+	if !full {
+		util.WriteJSON(w, http.StatusOK, newPrivateTaskView(task))
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, taskPreview(task, h.cfg.PreviewOmittedFields))
+}
 
-	accept := &store.Accept{
-		AcceptID:        req.AcceptID,
-		TaskID:          taskID,
-		WorkerAddress:   strings.ToLower(req.WorkerAddress),
-		WorkerSignature: strings.ToLower(req.Signature),
+// writeTaskPrivate rejects a request for details of a private task the
+// reader has not proven access to.
+func writeTaskPrivate(w http.ResponseWriter) {
+	util.WriteError(w, http.StatusForbidden, "task_private",
+		"task is private; sign a /v1/auth/challenge nonce as its employer or an invited worker")
+}
+
+// taskPreview holds the only fields a public preview may show. Addresses,
+// task_hash, signatures and tx hashes are deliberately absent.
+func taskPreview(t *store.Task, omit []string) map[string]any {
+	all := map[string]any{
+		"task_id":       t.TaskID,
+		"title":         t.Title,
+		"chain_id":      t.ChainID,
+		"status":        t.Status,
+		"amount_wei":    t.AmountWei,
+		"deadline_unix": t.DeadlineUnix,
+		"created_at":    t.CreatedAt,
+	}
+	for _, f := range omit {
+		delete(all, f)
+	}
+	return all
+}
+
+// ── POST /v1/tasks/{taskID}/accept ────────────────────────────────────────────
+
+// PostTaskAccept records a worker's accept. An If-Match header (see
+// taskETag) or expected_updated_at in the body makes it conditional on the
+// task being unchanged since the worker read it; otherwise it fails with 412
+// and the current task so the worker can re-confirm the terms.
+func (h *handlers) PostTaskAccept(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
+		return
 	}
-	if err := h.taskRepo.InsertAccept(r.Context(), accept); err != nil {
-		if errors.Is(err, store.ErrConflict) {
-			util.WriteError(w, http.StatusConflict, "conflict", "accept_id already exists")
+
+	var req service.AcceptTaskRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
+		return
+	}
+	ifMatch, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if ifMatch != nil {
+		if req.ExpectedUpdatedAt != nil && !req.ExpectedUpdatedAt.Equal(*ifMatch) {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request", "If-Match and expected_updated_at disagree")
 			return
 		}
-		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to store accept")
-		return
+		req.ExpectedUpdatedAt = ifMatch
 	}
 
-	if err := h.taskRepo.UpdateTaskWorker(r.Context(), taskID, strings.ToLower(req.WorkerAddress), store.TaskStatusAccepted); err != nil {
-		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to update task")
+	accept, replayed, err := h.taskService().AcceptTask(r.Context(), taskID, req)
+	if err != nil {
+		if service.KindOf(err) == service.KindPreconditionFailed {
+			h.writeTaskChanged(w, r, taskID, err)
+			return
+		}
+		writeServiceError(w, err)
 		return
 	}
 
-	util.WriteJSON(w, http.StatusCreated, map[string]any{
-		"task_id":        taskID,
-		"accept_id":      req.AcceptID,
+	status := http.StatusCreated
+	if replayed {
+		status = http.StatusOK
+	}
+	util.WriteJSON(w, status, map[string]any{
+		"task_id":        accept.TaskID,
+		"accept_id":      accept.AcceptID,
 		"status":         "accepted",
-		"worker_address": strings.ToLower(req.WorkerAddress),
+		"worker_address": accept.WorkerAddress,
+	})
+}
+
+// writeTaskChanged answers a failed accept precondition with 412, the error
+// and the task as it is now.
+func (h *handlers) writeTaskChanged(w http.ResponseWriter, r *http.Request, taskID string, err error) {
+	var se *service.Error
+	errors.As(err, &se)
+	task, gerr := h.taskRepo.GetTask(r.Context(), taskID)
+	if gerr != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("ETag", taskETag(task))
+	util.WriteJSON(w, http.StatusPreconditionFailed, map[string]any{
+		"error": util.APIError{Code: se.Code, Message: se.Message},
+		"task":  h.taskView(r, task),
 	})
 }
 
 // ── helper ─────────────────────────────────────────────────────────────────────
 
-func taskToMap(t *store.Task) map[string]any {
-	m := map[string]any{
-		"task_id":          t.TaskID,
-		"task_hash":        t.TaskHash,
-		"status":           t.Status,
-		"chain_id":         t.ChainID,
-		"escrow_address":   t.EscrowAddress,
-		"employer_address": t.EmployerAddress,
-		"worker_address":   t.WorkerAddress,
-		"amount_wei":       t.AmountWei,
-		"deadline_unix":    t.DeadlineUnix,
-		"title":            t.Title,
-		"indexer_fee_bps":  t.IndexerFeeBPS,
-		"created_at":       t.CreatedAt,
-		"updated_at":       t.UpdatedAt,
-	}
-	if t.OnchainCreatedAt != nil {
-		m["onchain_created_at"] = t.OnchainCreatedAt
-	}
-	if t.ReleasedAt != nil {
-		m["released_at"] = t.ReleasedAt
-	}
-	if t.RefundedAt != nil {
-		m["refunded_at"] = t.RefundedAt
-	}
-	if t.OnchainTxHash != "" {
-		m["onchain_tx_hash"] = t.OnchainTxHash
-	}
-	return m
+// taskResponse is the wire shape for a structured task. Fields are declared in
+// alphabetical order of their JSON names so the output matches the historical
+// map-based encoding byte for byte.
+type taskResponse struct {
+	AllowedWorkers   []string   `json:"allowed_workers,omitempty"`
+	AmountWei        string     `json:"amount_wei"`
+	ChainID          int        `json:"chain_id"`
+	ChainRetired     bool       `json:"chain_retired,omitempty"` // chain dropped from this indexer
+	CreatedAt        time.Time  `json:"created_at"`
+	DeadlineUnix     int64      `json:"deadline_unix"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"` // include_deleted only
+	EmployerAddress  string     `json:"employer_address"`
+	EmployerENS      string     `json:"employer_ens,omitempty"`
+	EmployerSequence *int64     `json:"employer_sequence,omitempty"`
+	EnvelopeObjectID string     `json:"envelope_object_id,omitempty"`
+	EscrowAddress    string     `json:"escrow_address"`
+	ExplorerURL      string     `json:"explorer_url,omitempty"`
+	IndexerFeeBPS    int        `json:"indexer_fee_bps"`
+	OnchainAmountWei string     `json:"onchain_amount_wei,omitempty"` // from the Created event
+	OnchainCreatedAt *time.Time `json:"onchain_created_at,omitempty"`
+	OnchainDeadline  *int64     `json:"onchain_deadline_unix,omitempty"` // from the Created event
+	OnchainTxHash    string     `json:"onchain_tx_hash,omitempty"`
+	OnchainWorker    string     `json:"onchain_worker_address,omitempty"`
+	RawRequestSHA256 string     `json:"raw_request_sha256,omitempty"` // GET /v1/tasks/{id} only
+	RefundedAt       *time.Time `json:"refunded_at,omitempty"`
+	ReleasedAt       *time.Time `json:"released_at,omitempty"`
+	Status           string     `json:"status"`
+	TaskHash         string     `json:"task_hash"`
+	TaskID           string     `json:"task_id"`
+	Title            string     `json:"title"`
+	TokenAddress     string     `json:"token_address,omitempty"` // empty for native currency
+	UpdatedAt        time.Time  `json:"updated_at"`
+	Visibility       string     `json:"visibility,omitempty"` // only set for private tasks
+	WorkerAddress    string     `json:"worker_address"`
+	WorkerENS        string     `json:"worker_ens,omitempty"`
+	WorkerMismatch   bool       `json:"worker_mismatch,omitempty"`
+}
+
+// taskListResponse is the wire shape for GET /v1/tasks.
+type taskListResponse struct {
+	Items []taskResponse `json:"items"`
+}
+
+// taskDeltaResponse is the wire shape for GET /v1/tasks?updated_since=...
+type taskDeltaResponse struct {
+	Items      []taskResponse `json:"items"`
+	Deleted    []taskDeletion `json:"deleted"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	ServerTime time.Time      `json:"server_time"`
+}
+
+// taskDeletion reports a soft-deleted task in a delta response.
+type taskDeletion struct {
+	TaskID    string    `json:"task_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func newTaskResponse(t *store.Task) taskResponse {
+	resp := taskResponse{
+		AmountWei:        t.AmountWei,
+		ChainID:          t.ChainID,
+		ChainRetired:     t.ChainRetiredAt != nil,
+		CreatedAt:        t.CreatedAt,
+		DeadlineUnix:     t.DeadlineUnix,
+		DeletedAt:        t.DeletedAt,
+		EmployerAddress:  t.EmployerAddress,
+		EmployerSequence: t.EmployerSequence,
+		EnvelopeObjectID: t.EnvelopeObjectID,
+		EscrowAddress:    t.EscrowAddress,
+		IndexerFeeBPS:    t.IndexerFeeBPS,
+		OnchainAmountWei: t.OnchainAmountWei,
+		OnchainCreatedAt: t.OnchainCreatedAt,
+		OnchainDeadline:  t.OnchainDeadlineUnix,
+		OnchainTxHash:    t.OnchainTxHash,
+		RawRequestSHA256: t.RawRequestSHA256,
+		RefundedAt:       t.RefundedAt,
+		ReleasedAt:       t.ReleasedAt,
+		Status:           t.Status,
+		TaskHash:         t.TaskHash,
+		TaskID:           t.TaskID,
+		Title:            t.Title,
+		TokenAddress:     t.TokenAddress,
+		UpdatedAt:        t.UpdatedAt,
+		WorkerAddress:    t.WorkerAddress,
+	}
+	if t.Visibility == store.TaskVisibilityPrivate {
+		resp.Visibility = t.Visibility
+		resp.AllowedWorkers = t.AllowedWorkers
+	}
+	if t.WorkerMismatch {
+		resp.WorkerMismatch = true
+		resp.OnchainWorker = t.OnchainWorkerAddress
+	}
+	return resp
 }
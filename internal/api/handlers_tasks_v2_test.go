@@ -0,0 +1,568 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+func fixtureTask(withOnchain bool) *store.Task {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 123456000, time.UTC)
+	t := &store.Task{
+		TaskID:          "task-golden-001",
+		TaskHash:        "0x8b1a944cf13a9a1c08facb2c9e98623ef3254d2ddb48113885c3e8e97fec8db9",
+		ChainID:         11155111,
+		EscrowAddress:   "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C",
+		EmployerAddress: "0x00000000000000000000000000000000000000e1",
+		WorkerAddress:   "",
+		AmountWei:       "1000000000000000000",
+		DeadlineUnix:    1767225600,
+		Title:           "golden <task> & friends",
+		Status:          store.TaskStatusCreated,
+		IndexerFeeBPS:   20,
+		CreatedAt:       created,
+		UpdatedAt:       created.Add(time.Minute),
+	}
+	if withOnchain {
+		onchain := created.Add(2 * time.Minute)
+		released := created.Add(time.Hour)
+		t.WorkerAddress = "0x00000000000000000000000000000000000000a1"
+		t.Status = store.TaskStatusReleased
+		t.OnchainCreatedAt = &onchain
+		t.ReleasedAt = &released
+		t.OnchainTxHash = "0xabc0000000000000000000000000000000000000000000000000000000000def"
+		deadline := t.DeadlineUnix
+		t.OnchainAmountWei, t.OnchainDeadlineUnix = t.AmountWei, &deadline
+	}
+	return t
+}
+
+func TestTaskResponse_Golden(t *testing.T) {
+	erc20 := fixtureTask(false)
+	erc20.TokenAddress = "0x1c7d4b196cb0c7b01d743fbc6116a902379c7238"
+	cases := []struct {
+		name   string
+		golden string
+		body   any
+	}{
+		{"minimal", "task_minimal.golden.json", newTaskResponse(fixtureTask(false))},
+		{"onchain", "task_onchain.golden.json", newTaskResponse(fixtureTask(true))},
+		{"erc20", "task_erc20.golden.json", newTaskResponse(erc20)},
+		{"list", "task_list.golden.json", taskListResponse{Items: []taskResponse{
+			newTaskResponse(fixtureTask(false)),
+			newTaskResponse(fixtureTask(true)),
+		}}},
+		{"empty_list", "task_list_empty.golden.json", taskListResponse{Items: []taskResponse{}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			util.WriteJSON(rec, http.StatusOK, tc.body)
+
+			want, err := os.ReadFile(filepath.Join("testdata", tc.golden))
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if got := rec.Body.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("wire format changed\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkListTasksEncode200(b *testing.B) {
+	tasks := make([]*store.Task, 200)
+	for i := range tasks {
+		tasks[i] = fixtureTask(i%2 == 0)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resp := taskListResponse{Items: make([]taskResponse, 0, len(tasks))}
+		for _, t := range tasks {
+			resp.Items = append(resp.Items, newTaskResponse(t))
+		}
+		rec := httptest.NewRecorder()
+		util.WriteJSON(rec, http.StatusOK, resp)
+	}
+}
+
+func TestTaskResponse_WorkerMismatch(t *testing.T) {
+	task := fixtureTask(true)
+	task.OnchainWorkerAddress = task.WorkerAddress
+	if resp := newTaskResponse(task); resp.WorkerMismatch || resp.OnchainWorker != "" {
+		t.Errorf("matching workers: %+v, want no mismatch fields", resp)
+	}
+
+	task.OnchainWorkerAddress, task.WorkerMismatch = "0x00000000000000000000000000000000000000b2", true
+	resp := newTaskResponse(task)
+	if !resp.WorkerMismatch || resp.OnchainWorker != task.OnchainWorkerAddress || resp.WorkerAddress != task.WorkerAddress {
+		t.Errorf("mismatch: %+v", resp)
+	}
+	h := &handlers{}
+	if got := h.renderTask(task, true).OnchainWorker; got == task.OnchainWorkerAddress {
+		t.Errorf("redacted onchain_worker_address = %s", got)
+	}
+}
+
+func TestTaskResponse_ChainRetired(t *testing.T) {
+	task := fixtureTask(false)
+	if newTaskResponse(task).ChainRetired {
+		t.Error("chain_retired set on a task without the flag")
+	}
+	flagged := task.CreatedAt.Add(time.Hour)
+	task.ChainRetiredAt = &flagged
+	rec := httptest.NewRecorder()
+	util.WriteJSON(rec, http.StatusOK, newTaskResponse(task))
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`"chain_retired":true`)) {
+		t.Errorf("body = %s, want chain_retired", rec.Body)
+	}
+}
+
+func TestTaskPreview_OmitsSensitiveFields(t *testing.T) {
+	task := fixtureTask(true)
+	task.EmployerSignature = "0xsig"
+
+	preview := taskPreview(task, nil)
+	for _, f := range []string{"employer_address", "worker_address", "task_hash", "employer_signature", "onchain_tx_hash", "escrow_address"} {
+		if _, ok := preview[f]; ok {
+			t.Errorf("preview exposes %s", f)
+		}
+	}
+	for _, f := range []string{"task_id", "title", "chain_id", "status", "amount_wei", "deadline_unix", "created_at"} {
+		if _, ok := preview[f]; !ok {
+			t.Errorf("preview missing %s", f)
+		}
+	}
+
+	preview = taskPreview(task, []string{"title", "amount_wei"})
+	if _, ok := preview["title"]; ok {
+		t.Error("configured omission of title ignored")
+	}
+	if _, ok := preview["amount_wei"]; ok {
+		t.Error("configured omission of amount_wei ignored")
+	}
+	if len(preview) != 5 {
+		t.Errorf("expected 5 fields, got %v", preview)
+	}
+}
+
+// deltaRepo serves ListTasksUpdatedSince from memory, ignoring chain and
+// status filters.
+type deltaRepo struct {
+	store.TaskRepo
+	tasks []*store.Task // ordered by (UpdatedAt, TaskID)
+}
+
+func (r *deltaRepo) ListTasksUpdatedSince(_ context.Context, since, until time.Time, _ int, _ string, limit int, cursor *store.Cursor) ([]*store.Task, *store.Cursor, error) {
+	var out []*store.Task
+	for _, t := range r.tasks {
+		if !t.UpdatedAt.After(since) || (!until.IsZero() && t.UpdatedAt.After(until)) {
+			continue
+		}
+		if cursor != nil {
+			ct, _ := time.Parse(time.RFC3339Nano, cursor.CreatedAt)
+			if t.UpdatedAt.Before(ct) || (t.UpdatedAt.Equal(ct) && t.TaskID <= cursor.ObjectID) {
+				continue
+			}
+		}
+		out = append(out, t)
+	}
+	if len(out) > limit {
+		last := out[limit-1]
+		return out[:limit], &store.Cursor{CreatedAt: last.UpdatedAt.Format(time.RFC3339Nano), ObjectID: last.TaskID, Order: store.OrderUpdated}, nil
+	}
+	return out, nil, nil
+}
+
+func TestListTasks_UpdatedSince(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &deltaRepo{}
+	for i, id := range []string{"d-1", "d-2", "d-3"} {
+		repo.tasks = append(repo.tasks, &store.Task{TaskID: id, Status: store.TaskStatusCreated, UpdatedAt: base.Add(time.Duration(i) * time.Second)})
+	}
+	router := NewRouter(nil, repo, config.Config{}, nil)
+
+	type delta struct {
+		Items      []taskResponse `json:"items"`
+		NextCursor string         `json:"next_cursor"`
+		ServerTime time.Time      `json:"server_time"`
+	}
+	get := func(target string) (int, delta) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var d delta
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &d); err != nil {
+				t.Fatalf("decode: %v %s", err, rec.Body)
+			}
+		}
+		return rec.Code, d
+	}
+	ids := func(items []taskResponse) (out []string) {
+		for _, it := range items {
+			out = append(out, it.TaskID)
+		}
+		return out
+	}
+
+	// Initial sync in pages of two.
+	since := base.Add(-time.Hour).Format(time.RFC3339)
+	code, page := get("/v1/tasks?limit=2&updated_since=" + since)
+	if code != http.StatusOK || len(page.Items) != 2 || page.NextCursor == "" || page.ServerTime.IsZero() {
+		t.Fatalf("first page: status %d, %+v", code, page)
+	}
+	_, rest := get("/v1/tasks?limit=2&updated_since=" + since + "&cursor=" + page.NextCursor)
+	if got := ids(rest.Items); len(got) != 1 || got[0] != "d-3" || rest.NextCursor != "" {
+		t.Fatalf("second page = %v (next %q), want [d-3]", got, rest.NextCursor)
+	}
+
+	// d-1 changes; the next sync from the last seen change returns only it.
+	repo.tasks = append(repo.tasks[1:], repo.tasks[0])
+	repo.tasks[2].UpdatedAt, repo.tasks[2].Status = base.Add(time.Minute), store.TaskStatusAccepted
+	_, changed := get("/v1/tasks?updated_since=" + base.Add(2*time.Second).Format(time.RFC3339Nano))
+	if got := ids(changed.Items); len(got) != 1 || got[0] != "d-1" || changed.Items[0].Status != store.TaskStatusAccepted {
+		t.Errorf("delta = %v, want [d-1] accepted", got)
+	}
+
+	if code, _ := get("/v1/tasks?updated_since=yesterday"); code != http.StatusBadRequest {
+		t.Errorf("bad timestamp: status %d, want 400", code)
+	}
+	offsetCursor := util.EncodeCursor(&store.Cursor{CreatedAt: base.Format(time.RFC3339Nano), ObjectID: "d-1"})
+	if code, _ := get("/v1/tasks?updated_since=" + since + "&cursor=" + offsetCursor); code != http.StatusBadRequest {
+		t.Errorf("cursor from another listing: status %d, want 400", code)
+	}
+}
+
+func TestListTasks_UpdatedSinceLagAndDeletes(t *testing.T) {
+	now := time.Now().UTC()
+	deleted := now.Add(-30 * time.Minute)
+	repo := &deltaRepo{tasks: []*store.Task{
+		{TaskID: "old", Status: store.TaskStatusCreated, UpdatedAt: now.Add(-time.Hour)},
+		{TaskID: "gone", Status: store.TaskStatusCreated, UpdatedAt: deleted, DeletedAt: &deleted},
+		{TaskID: "fresh", Status: store.TaskStatusCreated, UpdatedAt: now},
+	}}
+	router := NewRouter(nil, repo, config.Config{SyncLag: time.Minute}, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks?updated_since="+now.Add(-2*time.Hour).Format(time.RFC3339Nano), nil))
+	var d struct {
+		Items   []taskResponse `json:"items"`
+		Deleted []struct {
+			TaskID    string    `json:"task_id"`
+			DeletedAt time.Time `json:"deleted_at"`
+		} `json:"deleted"`
+		ServerTime time.Time `json:"server_time"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &d); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %v %s", rec.Code, err, rec.Body)
+	}
+	if len(d.Items) != 1 || d.Items[0].TaskID != "old" {
+		t.Errorf("items = %+v, want only old (fresh is within the sync lag)", d.Items)
+	}
+	if len(d.Deleted) != 1 || d.Deleted[0].TaskID != "gone" || !d.Deleted[0].DeletedAt.Equal(deleted) {
+		t.Errorf("deleted = %+v, want gone", d.Deleted)
+	}
+	if !d.ServerTime.Before(now) || d.ServerTime.Before(now.Add(-2*time.Minute)) {
+		t.Errorf("server_time = %s, want about a minute before %s", d.ServerTime, now)
+	}
+}
+
+// filterRepo records the filter passed to ListTasks.
+type filterRepo struct {
+	store.TaskRepo
+	got *store.TaskFilter
+}
+
+func (r *filterRepo) ListTasks(_ context.Context, f store.TaskFilter) ([]*store.Task, error) {
+	r.got = &f
+	return nil, nil
+}
+
+func TestListTasks_OnchainFilter(t *testing.T) {
+	repo := &filterRepo{}
+	router := NewRouter(nil, repo, config.Config{}, nil)
+	get := func(target string) int {
+		repo.got = nil
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec.Code
+	}
+
+	for target, want := range map[string]string{
+		"/v1/tasks":                  "",
+		"/v1/tasks?onchain=synced":   store.OnchainSynced,
+		"/v1/tasks?onchain=unsynced": store.OnchainUnsynced,
+	} {
+		if code := get(target); code != http.StatusOK || repo.got == nil {
+			t.Fatalf("%s: status %d", target, code)
+		}
+		if repo.got.Onchain != want || repo.got.CreatedBefore != nil {
+			t.Errorf("%s: filter = %+v, want onchain %q", target, repo.got, want)
+		}
+	}
+
+	start := time.Now().UTC()
+	if code := get("/v1/tasks?onchain=unsynced&older_than=24h&chain_id=1"); code != http.StatusOK {
+		t.Fatalf("older_than: status %d", code)
+	}
+	if f := repo.got; f.Onchain != store.OnchainUnsynced || f.ChainID != 1 || f.CreatedBefore == nil ||
+		f.CreatedBefore.After(start.Add(-24*time.Hour).Add(time.Minute)) || f.CreatedBefore.Before(start.Add(-25*time.Hour)) {
+		t.Errorf("filter = %+v, want unsynced created about 24h ago", f)
+	}
+
+	for _, target := range []string{
+		"/v1/tasks?onchain=yes",
+		"/v1/tasks?older_than=1d",
+		"/v1/tasks?older_than=-1h",
+		"/v1/tasks?onchain=synced&updated_since=2025-01-01T00:00:00Z",
+	} {
+		if code := get(target); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", target, code)
+		}
+	}
+}
+
+// fieldsRepo serves ListTasks and GetTask from the deltaRepo tasks.
+type fieldsRepo struct {
+	deltaRepo
+}
+
+func (r *fieldsRepo) ListTasks(context.Context, store.TaskFilter) ([]*store.Task, error) {
+	return r.tasks, nil
+}
+
+func (r *fieldsRepo) GetTask(_ context.Context, id string) (*store.Task, error) {
+	for _, t := range r.tasks {
+		if t.TaskID == id {
+			return t, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func TestTaskReads_FieldSelection(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fieldsRepo{}
+	for i, id := range []string{"f-1", "f-2"} {
+		repo.tasks = append(repo.tasks, &store.Task{TaskID: id, ChainID: 1, Status: store.TaskStatusCreated, UpdatedAt: base.Add(time.Duration(i) * time.Second)})
+	}
+	router := NewRouter(nil, repo, config.Config{}, nil)
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+	keys := func(m map[string]any) (out []string) {
+		for k := range m {
+			out = append(out, k)
+		}
+		slices.Sort(out)
+		return out
+	}
+	since := base.Add(-time.Hour).Format(time.RFC3339)
+
+	for _, target := range []string{
+		"/v1/tasks?fields=task_id,status",
+		"/v1/tasks?fields=status,%20task_id&updated_since=" + since,
+	} {
+		rec := get(target)
+		var resp struct {
+			Items []map[string]any `json:"items"`
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, body %s", target, rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Items) != 2 {
+			t.Fatalf("%s: %d items, want 2", target, len(resp.Items))
+		}
+		for _, it := range resp.Items {
+			if got := keys(it); !slices.Equal(got, []string{"status", "task_id"}) {
+				t.Errorf("%s: item keys = %v, want [status task_id]", target, got)
+			}
+		}
+	}
+	if rec := get("/v1/tasks?fields=task_id&limit=1&updated_since=" + since); !strings.Contains(rec.Body.String(), `"next_cursor"`) ||
+		!strings.Contains(rec.Body.String(), `"server_time"`) {
+		t.Errorf("delta with fields lost its paging keys: %s", rec.Body)
+	}
+
+	rec := get("/v1/tasks/f-1?fields=chain_id")
+	var one map[string]any
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &one) != nil {
+		t.Fatalf("get: status %d, body %s", rec.Code, rec.Body)
+	}
+	if got := keys(one); !slices.Equal(got, []string{"chain_id"}) {
+		t.Errorf("get keys = %v, want [chain_id]", got)
+	}
+
+	rec = get("/v1/tasks/f-1")
+	if err := json.Unmarshal(rec.Body.Bytes(), &one); err != nil || len(one) <= 1 {
+		t.Errorf("no fields: got %s, want the full task", rec.Body)
+	}
+
+	for _, target := range []string{
+		"/v1/tasks?fields=task_id,secret",
+		"/v1/tasks?fields=nope&updated_since=" + since,
+		"/v1/tasks/f-1?fields=TaskID",
+	} {
+		if rec := get(target); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+type quotaRepo struct {
+	store.TaskRepo
+	open int64
+}
+
+func (r *quotaRepo) CountOpenTasks(context.Context, string) (int64, error) {
+	return r.open, nil
+}
+
+func TestGetEmployerQuota(t *testing.T) {
+	const path = "/v1/employers/0x00000000000000000000000000000000000000E1/quota"
+	get := func(limit int, open int64) map[string]any {
+		router := NewRouter(nil, &quotaRepo{open: open}, config.Config{MaxOpenTasksPerEmployer: limit}, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		var resp map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get(5, 3)
+	if resp["employer_address"] != "0x00000000000000000000000000000000000000e1" ||
+		resp["open_task_limit"] != 5.0 || resp["open_tasks"] != 3.0 || resp["remaining"] != 2.0 {
+		t.Errorf("limited: %v", resp)
+	}
+	if resp := get(2, 3); resp["remaining"] != 0.0 {
+		t.Errorf("over limit: remaining = %v, want 0", resp["remaining"])
+	}
+	if resp := get(0, 3); resp["remaining"] != nil || resp["open_task_limit"] != 0.0 {
+		t.Errorf("unlimited: %v", resp)
+	}
+}
+
+func TestGetTaskComputeHash(t *testing.T) {
+	router := NewRouter(nil, nil, config.Config{EnableOnchainHashVerification: true}, nil)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/compute-hash"+query, nil))
+		return rec
+	}
+
+	rec := get("?task_id=abc")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// keccak256("abc")
+	if resp["task_id"] != "abc" || resp["task_hash"] != "0x4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45" ||
+		resp["scheme"] != "keccak256(utf8(task_id))" || resp["onchain_verification"] != true {
+		t.Errorf("response = %v", resp)
+	}
+
+	if rec := get(""); rec.Code != http.StatusBadRequest {
+		t.Errorf("no task_id: status %d, want 400", rec.Code)
+	}
+}
+
+// objectsByID is a Repo keeping objects in a map.
+type objectsByID struct {
+	store.Repo
+	objects map[string]*store.Object
+}
+
+func (r objectsByID) GetObjectByID(_ context.Context, id string) (*store.Object, error) {
+	if o, ok := r.objects[id]; ok {
+		return o, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r objectsByID) InsertObject(_ context.Context, env *envelope.Envelope) error {
+	r.objects[env.ObjectID] = &store.Object{Envelope: *env}
+	return nil
+}
+
+func TestPostTask_StatusByFailureClass(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{
+		MaxBodyBytes:    1 << 20,
+		SupportedChains: []config.ChainConfig{{ChainID: 11155111, MinAmountWei: "100"}},
+	}
+	repo := objectsByID{objects: map[string]*store.Object{
+		"env-bid": {Envelope: envelope.Envelope{ObjectID: "env-bid", ObjectType: "bid"}},
+	}}
+	router := NewRouter(repo, &insertTaskRepo{}, cfg, nil)
+
+	// body is a valid signed task for taskID with the given fields replaced.
+	body := func(taskID string, set map[string]any) string {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(signedTaskBody(t, key, taskID)), &m); err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range set {
+			m[k] = v
+		}
+		b, _ := json.Marshal(m)
+		return string(b)
+	}
+	cases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"malformed_json", `{"task_id":`, http.StatusBadRequest},
+		{"wrong_json_type", `{"chain_id":"one"}`, http.StatusBadRequest},
+		{"missing_field", body("t-missing", map[string]any{"chain_id": 0}), http.StatusBadRequest},
+		{"bad_address_format", body("t-address", map[string]any{"employer_address": "0x12"}), http.StatusBadRequest},
+		{"bad_amount_format", body("t-amount", map[string]any{"amount_wei": "ten"}), http.StatusBadRequest},
+		{"bad_visibility", body("t-visibility", map[string]any{"visibility": "secret"}), http.StatusBadRequest},
+		{"task_hash_mismatch", body("t-hash", map[string]any{"task_hash": ethutil.Keccak256Hex([]byte("other"))}), http.StatusUnprocessableEntity},
+		{"unsupported_chain", body("t-chain", map[string]any{"chain_id": 1}), http.StatusUnprocessableEntity},
+		{"below_minimum", body("t-min", map[string]any{"amount_wei": "99"}), http.StatusUnprocessableEntity},
+		{"unknown_envelope", body("t-env", map[string]any{"envelope_object_id": "nope"}), http.StatusUnprocessableEntity},
+		{"envelope_not_task", body("t-env-bid", map[string]any{"envelope_object_id": "env-bid"}), http.StatusUnprocessableEntity},
+		{"valid", body("t-ok", nil), http.StatusCreated},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks", strings.NewReader(tc.body)))
+		if rec.Code != tc.want {
+			t.Errorf("%s: status %d, want %d; body = %s", tc.name, rec.Code, tc.want, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// tvlCacheTTL bounds how stale GET /v1/tvl may be. The sum scans every open
+// task, so dashboards polling it share one query per window.
+const tvlCacheTTL = 30 * time.Second
+
+type tvlTotal struct {
+	ChainID      int    `json:"chain_id"`
+	TokenAddress string `json:"token_address,omitempty"`
+	Tasks        int64  `json:"tasks"`
+	AmountWei    string `json:"amount_wei"`
+}
+
+// tvlCache holds the last SumTVL result. The zero value is empty.
+type tvlCache struct {
+	mu     sync.Mutex
+	totals []tvlTotal
+	asOf   time.Time
+}
+
+// get returns the cached totals, refreshing them with sum when older than
+// tvlCacheTTL.
+func (c *tvlCache) get(ctx context.Context, sum func(context.Context) ([]store.TVLTotal, error)) ([]tvlTotal, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.asOf.IsZero() && time.Since(c.asOf) < tvlCacheTTL {
+		return c.totals, c.asOf, nil
+	}
+	rows, err := sum(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	totals := make([]tvlTotal, len(rows))
+	for i, t := range rows {
+		totals[i] = tvlTotal{ChainID: t.ChainID, TokenAddress: t.TokenAddress, Tasks: t.Tasks, AmountWei: t.AmountWei}
+	}
+	c.totals, c.asOf = totals, time.Now().UTC()
+	return c.totals, c.asOf, nil
+}
+
+// ── GET /v1/tvl ────────────────────────────────────────────────────────────
+
+// GetTVL returns the value escrowed onchain per chain and token: tasks seen
+// created onchain and not yet released, refunded or cancelled. Amounts are
+// decimal wei strings. Results are cached for tvlCacheTTL; as_of is when
+// they were computed.
+func (h *handlers) GetTVL(w http.ResponseWriter, r *http.Request) {
+	totals, asOf, err := h.tvl.get(r.Context(), h.taskRepo.SumTVL)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to sum tvl")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"totals": totals,
+		"as_of":  asOf.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// tvlRepo returns fixed totals and counts SumTVL calls.
+type tvlRepo struct {
+	store.TaskRepo
+	calls int
+	err   error
+}
+
+func (r *tvlRepo) SumTVL(context.Context) ([]store.TVLTotal, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return []store.TVLTotal{
+		{ChainID: 1, Tasks: 2, AmountWei: "100000000000000000000000000000"},
+		{ChainID: 11155111, TokenAddress: "0x00000000000000000000000000000000000000aa", Tasks: 1, AmountWei: "5"},
+	}, nil
+}
+
+func TestGetTVL(t *testing.T) {
+	repo := &tvlRepo{}
+	router := NewRouter(nil, repo, config.Config{}, nil)
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tvl", nil))
+		return rec
+	}
+
+	rec := get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Totals []map[string]any `json:"totals"`
+		AsOf   string           `json:"as_of"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Totals) != 2 || resp.AsOf == "" {
+		t.Fatalf("unexpected response %s", rec.Body)
+	}
+	if got := resp.Totals[0]["amount_wei"]; got != "100000000000000000000000000000" {
+		t.Errorf("amount_wei = %v, want a decimal string", got)
+	}
+	if _, ok := resp.Totals[0]["token_address"]; ok {
+		t.Errorf("native total has token_address: %v", resp.Totals[0])
+	}
+	if got := resp.Totals[1]["token_address"]; got != "0x00000000000000000000000000000000000000aa" {
+		t.Errorf("token_address = %v", got)
+	}
+
+	if rec := get(); rec.Code != http.StatusOK || repo.calls != 1 {
+		t.Errorf("second read: status = %d, SumTVL calls = %d, want cached", rec.Code, repo.calls)
+	}
+}
+
+func TestGetTVL_RepoError(t *testing.T) {
+	router := NewRouter(nil, &tvlRepo{err: errors.New("boom")}, config.Config{}, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tvl", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+}
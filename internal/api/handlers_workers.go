@@ -0,0 +1,173 @@
+package api
+
+// handlers_workers.go implements worker trust tiers:
+//   GET  /v1/workers/{address}/tier
+//   POST /v1/admin/workers/{address}/tier
+//   GET  /v1/tasks/{taskID}/accepts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+type setWorkerTierReq struct {
+	Tier             int    `json:"tier"`
+	MaxTaskAmountWei string `json:"max_task_amount_wei"` // empty = unlimited
+	UpdatedBy        string `json:"updated_by"`
+}
+
+// effectiveWorkerTier returns the stored tier for addr, or the default tier 0.
+func (h *handlers) effectiveWorkerTier(ctx context.Context, addr string) (*store.WorkerTier, error) {
+	return h.taskService().EffectiveWorkerTier(ctx, addr)
+}
+
+// tierAllows reports whether amountWei is within the tier's limit.
+func tierAllows(t *store.WorkerTier, amountWei string) bool {
+	return service.TierAllows(t, amountWei)
+}
+
+func workerTierToMap(t *store.WorkerTier) map[string]any {
+	m := map[string]any{
+		"worker_address":      t.WorkerAddress,
+		"tier":                t.Tier,
+		"max_task_amount_wei": t.MaxTaskAmountWei,
+	}
+	if !t.UpdatedAt.IsZero() {
+		m["updated_at"] = t.UpdatedAt
+	}
+	return m
+}
+
+// ── GET /v1/workers/{address}/tier ────────────────────────────────────────────
+
+func (h *handlers) GetWorkerTier(w http.ResponseWriter, r *http.Request) {
+	addr := chi.URLParam(r, "address")
+	if !reHexAddr.MatchString(addr) {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "address must be 0x + 40 hex chars")
+		return
+	}
+	t, err := h.effectiveWorkerTier(r.Context(), addr)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to get worker tier")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, workerTierToMap(t))
+}
+
+// ── POST /v1/admin/workers/{address}/tier ─────────────────────────────────────
+
+func (h *handlers) PostWorkerTier(w http.ResponseWriter, r *http.Request) {
+	addr := chi.URLParam(r, "address")
+	if !reHexAddr.MatchString(addr) {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "address must be 0x + 40 hex chars")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
+		return
+	}
+	var req setWorkerTierReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Tier < 0 {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "tier must be >= 0")
+		return
+	}
+	maxWei := strings.TrimSpace(req.MaxTaskAmountWei)
+	if maxWei != "" {
+		if n, ok := new(big.Int).SetString(maxWei, 10); !ok || n.Sign() < 0 {
+			util.WriteError(w, http.StatusBadRequest, "invalid_request",
+				"max_task_amount_wei must be a non-negative integer string")
+			return
+		}
+	}
+
+	t := &store.WorkerTier{
+		WorkerAddress:    addr,
+		Tier:             req.Tier,
+		MaxTaskAmountWei: maxWei,
+		UpdatedBy:        req.UpdatedBy,
+	}
+	if err := h.taskRepo.SetWorkerTier(r.Context(), t); err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to set worker tier")
+		return
+	}
+	if err := audit.Record(r.Context(), h.taskRepo, audit.WorkerTierChanged, nil, map[string]any{
+		"worker_address":      t.WorkerAddress,
+		"tier":                t.Tier,
+		"max_task_amount_wei": t.MaxTaskAmountWei,
+		"updated_by":          t.UpdatedBy,
+	}); err != nil {
+		log.Printf("[admin] worker tier audit: %v", err)
+	}
+	util.WriteJSON(w, http.StatusOK, workerTierToMap(t))
+}
+
+// ── GET /v1/tasks/{taskID}/accepts ────────────────────────────────────────────
+
+func (h *handlers) ListTaskAccepts(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	task, err := h.taskRepo.GetTask(r.Context(), taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			util.WriteError(w, http.StatusNotFound, "not_found", "task not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to get task")
+		return
+	}
+	if full, ok := h.taskAccess(w, r, task); !ok {
+		return
+	} else if !full {
+		writeTaskPrivate(w)
+		return
+	}
+
+	accepts, err := h.taskRepo.ListAccepts(r.Context(), taskID)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list accepts")
+		return
+	}
+	redact := h.redactAddresses(r)
+	items := make([]map[string]any, 0, len(accepts))
+	for _, a := range accepts {
+		worker := a.WorkerAddress
+		if redact {
+			worker = redactAddress(worker)
+		}
+		item := map[string]any{
+			"accept_id":      a.AcceptID,
+			"task_id":        a.TaskID,
+			"worker_address": worker,
+			"worker_tier":    a.WorkerTier,
+			"created_at":     a.CreatedAt,
+		}
+		if a.Terms != nil {
+			item["terms"] = map[string]any{
+				"amount_wei":      a.Terms.AmountWei,
+				"deadline_unix":   a.Terms.DeadlineUnix,
+				"title_hash":      a.Terms.TitleHash,
+				"indexer_fee_bps": a.Terms.IndexerFeeBPS,
+			}
+		}
+		items = append(items, item)
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestTierAllows(t *testing.T) {
+	cases := []struct {
+		limit  string
+		amount string
+		want   bool
+	}{
+		{"", "1000000000000000000000", true},
+		{"100", "100", true},
+		{"100", "101", false},
+		{"0", "1", false},
+		{"not-a-number", "1", false},
+	}
+	for _, tc := range cases {
+		tier := &store.WorkerTier{MaxTaskAmountWei: tc.limit}
+		if got := tierAllows(tier, tc.amount); got != tc.want {
+			t.Errorf("tierAllows(limit=%q, amount=%q) = %v, want %v", tc.limit, tc.amount, got, tc.want)
+		}
+	}
+}
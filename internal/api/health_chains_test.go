@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+func TestFreshnessOf(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name       string
+		status     chain.Status
+		wantOK     bool
+		wantReason string
+		wantLag    uint64
+		wantSecs   float64
+	}{
+		{"caught_up", chain.Status{HeadBlock: 1000, SyncedBlock: 998, SyncedAt: now.Add(-12 * time.Second), HeadCheckedAt: now.Add(-5 * time.Second)}, true, "", 2, 12},
+		{"at_threshold", chain.Status{HeadBlock: 1000, SyncedBlock: 990, SyncedAt: now.Add(-time.Minute), HeadCheckedAt: now}, true, "", 10, 60},
+		{"lagging", chain.Status{HeadBlock: 1000, SyncedBlock: 989, SyncedAt: now.Add(-5 * time.Minute), HeadCheckedAt: now}, false, "lagging", 11, 300},
+		{"synced_ahead", chain.Status{HeadBlock: 1000, SyncedBlock: 1001, SyncedAt: now, HeadCheckedAt: now}, true, "", 0, 0},
+		{"no_head", chain.Status{}, false, "no_head", 0, 0},
+		{"head_stale", chain.Status{HeadBlock: 1000, SyncedBlock: 1000, HeadCheckedAt: now.Add(-headStaleAfter)}, false, "head_stale", 0, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := freshnessOf(tc.status, 10, now)
+			if f.OK != tc.wantOK || f.Reason != tc.wantReason || f.LagBlocks != tc.wantLag || f.LagSeconds != tc.wantSecs || f.MaxLagBlocks != 10 {
+				t.Errorf("freshness = %+v", f)
+			}
+		})
+	}
+}
+
+func TestGetHealthChains(t *testing.T) {
+	cfg := config.Config{SupportedChains: []config.ChainConfig{{
+		ChainID: 11155111, SettlementContract: "0x1111111111111111111111111111111111111111", MinConfirmations: 3,
+	}}}
+	w, err := chain.NewWatcher("http://127.0.0.1:1", cfg.SupportedChains[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(cfg config.Config, path string) (int, map[string]json.RawMessage) {
+		rec := httptest.NewRecorder()
+		NewRouter(nil, nil, cfg, []*chain.Watcher{w}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v: %s", path, err, rec.Body)
+		}
+		return rec.Code, body
+	}
+
+	// The watcher has not read a head yet, so the chain is not fresh.
+	code, body := get(cfg, "/v1/health/chains")
+	if code != http.StatusServiceUnavailable || string(body["ok"]) != "false" {
+		t.Fatalf("status = %d, body = %v", code, body)
+	}
+	var chains []chainFreshness
+	if err := json.Unmarshal(body["chains"], &chains); err != nil {
+		t.Fatal(err)
+	}
+	if len(chains) != 1 || chains[0].Reason != "no_head" || chains[0].MaxLagBlocks != 23 {
+		t.Errorf("chains = %+v", chains)
+	}
+
+	// /v1/health/ready reports freshness as warnings only when asked to.
+	if _, body := get(cfg, "/v1/health/ready"); body["warnings"] != nil {
+		t.Errorf("warnings without AMN_READY_CHAIN_FRESHNESS: %s", body["warnings"])
+	}
+	cfg.ReadyChainFreshness = true
+	_, body = get(cfg, "/v1/health/ready")
+	var warnings []string
+	if err := json.Unmarshal(body["warnings"], &warnings); err != nil || len(warnings) != 1 {
+		t.Errorf("warnings = %s, %v", body["warnings"], err)
+	}
+}
+
+func TestGetHealthReady_NoWatchers(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewRouter(nil, nil, config.Config{}, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/health/ready", nil))
+	var body struct {
+		Status string           `json:"status"`
+		Chains []chainReadiness `json:"chains"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || body.Status != "degraded" || body.Chains == nil || len(body.Chains) != 0 {
+		t.Errorf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
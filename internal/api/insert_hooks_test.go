@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestInsertHooks_RunForSyncAndQueuedSubmissions(t *testing.T) {
+	// Routers pick up DefaultInsertHooks; give this test its own registry so
+	// the hook does not outlive it.
+	hooks := service.NewInsertHooks()
+	prev := service.DefaultInsertHooks
+	service.DefaultInsertHooks = hooks
+	t.Cleanup(func() { service.DefaultInsertHooks = prev })
+
+	var mu sync.Mutex
+	var seen []string
+	hooks.Register("bid", func(_ context.Context, env *envelope.Envelope) error {
+		if strings.HasPrefix(env.ObjectID, "hooked-") {
+			mu.Lock()
+			seen = append(seen, env.ObjectID)
+			mu.Unlock()
+		}
+		return nil
+	})
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	bid := func(id string) string {
+		env := envelope.Envelope{
+			ObjectType: "bid", ObjectVersion: "0.1", ObjectID: id, CreatedAt: "2025-01-01T00:00:00Z",
+			Payload: json.RawMessage(`{"task_id":"t"}`),
+			Signer:  envelope.Signer{Algo: "ed25519", PubKey: base64.StdEncoding.EncodeToString(pub)},
+		}
+		preimage, _ := env.SignedPreimageBytes()
+		env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, preimage))
+		body, _ := json.Marshal(env)
+		return string(body)
+	}
+	post := func(router http.Handler, body string, want int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/bids", strings.NewReader(body)))
+		if rec.Code != want {
+			t.Fatalf("status %d, want %d: %s", rec.Code, want, rec.Body)
+		}
+	}
+	cfg := config.Config{MaxBodyBytes: 1 << 20}
+
+	post(NewRouter(insertRepo{}, nil, cfg, nil), bid("hooked-sync"), http.StatusCreated)
+
+	queue := store.NewQueuedRepo(insertRepo{}, 1, 10, 1)
+	post(NewRouter(queue, nil, cfg, nil), bid("hooked-queued"), http.StatusAccepted)
+	queue.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(seen, []string{"hooked-sync", "hooked-queued"}) {
+		t.Errorf("hooks saw %v", seen)
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// defaultMaintenanceMessage is returned when the operator gives no message.
+const defaultMaintenanceMessage = "indexer is in maintenance mode; writes are temporarily disabled"
+
+// maintenanceRetryAfter is the Retry-After hint sent with maintenance 503s.
+const maintenanceRetryAfter = 60 * time.Second
+
+// maintenanceStatus is an immutable snapshot of the maintenance flag.
+type maintenanceStatus struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// maintenanceMode is the process-wide write freeze, swapped atomically so the
+// middleware never blocks.
+type maintenanceMode struct {
+	status atomic.Pointer[maintenanceStatus]
+}
+
+func newMaintenanceMode(enabled bool, message string) *maintenanceMode {
+	m := &maintenanceMode{}
+	m.set(enabled, message)
+	return m
+}
+
+func (m *maintenanceMode) get() maintenanceStatus {
+	return *m.status.Load()
+}
+
+func (m *maintenanceMode) set(enabled bool, message string) maintenanceStatus {
+	s := &maintenanceStatus{Enabled: enabled}
+	if enabled {
+		now := time.Now().UTC()
+		s.Since = &now
+		s.Message = strings.TrimSpace(message)
+		if s.Message == "" {
+			s.Message = defaultMaintenanceMessage
+		}
+	}
+	m.status.Store(s)
+	return *s
+}
+
+// isWriteMethod reports whether method can modify state.
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rejectWritesInMaintenance returns 503 for write requests while
+// maintenance mode is on. Reads, health, metrics and the admin API (so the
+// mode can be switched off again) are unaffected.
+func (h *handlers) rejectWritesInMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWriteMethod(r.Method) {
+			if s := h.maintenance.get(); s.Enabled && !strings.HasPrefix(r.URL.Path, "/v1/admin/") {
+				w.Header().Set("Retry-After", strconv.Itoa(int(maintenanceRetryAfter.Seconds())))
+				util.WriteError(w, http.StatusServiceUnavailable, "maintenance", s.Message)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ── POST /v1/admin/maintenance ────────────────────────────────────────────────
+
+type maintenanceReq struct {
+	Enabled *bool  `json:"enabled"`
+	Message string `json:"message"`
+}
+
+// PostMaintenance switches maintenance mode on or off.
+func (h *handlers) PostMaintenance(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil || int64(len(body)) > h.maxBody {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "body read error or too large")
+		return
+	}
+	var req maintenanceReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Enabled == nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", "enabled is required")
+		return
+	}
+
+	s := h.maintenance.set(*req.Enabled, req.Message)
+	log.Printf("[admin] maintenance mode enabled=%v message=%q", s.Enabled, s.Message)
+	if err := audit.Record(r.Context(), h.taskRepo, audit.MaintenanceModeChanged, nil,
+		map[string]any{"enabled": s.Enabled, "message": s.Message}); err != nil {
+		log.Printf("[admin] maintenance audit: %v", err)
+	}
+	util.WriteJSON(w, http.StatusOK, s)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// maintenanceRepo answers the few repo calls the maintenance test makes.
+type maintenanceRepo struct {
+	store.TaskRepo
+	audits []*store.AuditEvent
+}
+
+func (r *maintenanceRepo) InsertAuditEvent(_ context.Context, e *store.AuditEvent) error {
+	r.audits = append(r.audits, e)
+	return nil
+}
+
+func (r *maintenanceRepo) ListTasks(context.Context, store.TaskFilter) ([]*store.Task, error) {
+	return nil, nil
+}
+
+func TestMaintenanceMode_ToggleMidFlight(t *testing.T) {
+	repo := &maintenanceRepo{}
+	router := NewRouter(nil, repo, config.Config{AdminToken: "secret", MaxBodyBytes: 1 << 20}, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if strings.HasPrefix(path, "/v1/admin/") {
+			req.Header.Set("Authorization", "Bearer secret")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Off: writes reach the handler (which rejects the empty body with 400).
+	if rec := do(http.MethodPost, "/v1/tasks", "{}"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("before maintenance: status = %d, want 400", rec.Code)
+	}
+
+	if rec := do(http.MethodPost, "/v1/admin/maintenance", `{"enabled":true,"message":"db migration until 14:00 UTC"}`); rec.Code != http.StatusOK {
+		t.Fatalf("enable: status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	rec := do(http.MethodPost, "/v1/tasks", "{}")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("during maintenance: status = %d, want 503", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After header")
+	}
+	if !strings.Contains(rec.Body.String(), "db migration until 14:00 UTC") {
+		t.Errorf("body %s missing operator message", rec.Body)
+	}
+	for _, method := range []string{http.MethodPatch, http.MethodPut, http.MethodDelete} {
+		if rec := do(method, "/v1/tasks/x", "{}"); rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s during maintenance: status = %d, want 503", method, rec.Code)
+		}
+	}
+
+	// Reads, health and metrics keep working.
+	for _, path := range []string{"/v1/tasks", "/v1/health", "/metrics"} {
+		if rec := do(http.MethodGet, path, ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s during maintenance: status = %d, want 200", path, rec.Code)
+		}
+	}
+
+	for _, path := range []string{"/v1/health", "/v1/health/ready"} {
+		rec = do(http.MethodGet, path, "")
+		var health struct {
+			Maintenance maintenanceStatus `json:"maintenance"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+			t.Fatal(err)
+		}
+		if !health.Maintenance.Enabled || health.Maintenance.Since == nil {
+			t.Errorf("%s maintenance = %+v", path, health.Maintenance)
+		}
+	}
+
+	if rec := do(http.MethodPost, "/v1/admin/maintenance", `{"enabled":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("disable: status = %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/v1/tasks", "{}"); rec.Code != http.StatusBadRequest {
+		t.Errorf("after maintenance: status = %d, want 400", rec.Code)
+	}
+	if len(repo.audits) != 2 {
+		t.Errorf("expected 2 audit events, got %d", len(repo.audits))
+	}
+}
+
+func TestMaintenanceMode_BootDefault(t *testing.T) {
+	router := NewRouter(nil, &maintenanceRepo{}, config.Config{MaintenanceMode: true}, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks", strings.NewReader("{}")))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), defaultMaintenanceMessage) {
+		t.Errorf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
+
+func TestPostMaintenance_RequiresEnabled(t *testing.T) {
+	router := NewRouter(nil, &maintenanceRepo{}, config.Config{AdminToken: "secret", MaxBodyBytes: 1 << 20}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/maintenance", strings.NewReader(`{"message":"x"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// publicFeedMaxAge is the Cache-Control max-age of GET /v1/feed pages.
+const publicFeedMaxAge = 15 * time.Second
+
+// publicTaskRefLen is how much of task_hash ("0x" + 16 hex) identifies a
+// task in the public feed.
+const publicTaskRefLen = 18
+
+// publicFeedEvent is a status change as published by GET /v1/feed. It is
+// built only by redactStatusEvent.
+type publicFeedEvent struct {
+	ID      string `json:"id"`
+	TaskRef string `json:"task_ref"`
+	ChainID int    `json:"chain_id"`
+	// From is empty for a task's creation.
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	// AmountBucket is the power of ten the amount falls in: "1e18" covers
+	// 1e18 up to (not including) 1e19 wei.
+	AmountBucket string    `json:"amount_bucket,omitempty"`
+	At           time.Time `json:"at"`
+}
+
+// redactStatusEvent is the one place a store.TaskStatusEvent is turned into
+// public output. The task is identified by a task_hash prefix and the amount
+// by its bucket; task_id, addresses and the title are dropped. A field added
+// to store.TaskStatusEvent fails the tests until it is classified in
+// statusEventFields.
+func redactStatusEvent(e *store.TaskStatusEvent) publicFeedEvent {
+	return publicFeedEvent{
+		ID:           strconv.FormatInt(e.ID, 10),
+		TaskRef:      taskRef(e.TaskHash),
+		ChainID:      e.ChainID,
+		From:         e.OldStatus,
+		To:           e.NewStatus,
+		AmountBucket: amountBucket(e.AmountWei),
+		At:           e.CreatedAt.UTC(),
+	}
+}
+
+// taskRef returns the first publicTaskRefLen characters of taskHash.
+func taskRef(taskHash string) string {
+	if len(taskHash) <= publicTaskRefLen {
+		return taskHash
+	}
+	return taskHash[:publicTaskRefLen]
+}
+
+// amountBucket returns "1e<n>" for a positive wei amount in [10^n, 10^(n+1)),
+// or "" if amountWei is not a positive integer.
+func amountBucket(amountWei string) string {
+	n, ok := new(big.Int).SetString(amountWei, 10)
+	if !ok || n.Sign() <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("1e%d", len(n.String())-1)
+}
+
+// ── GET /v1/feed ──────────────────────────────────────────────────────────────
+
+// GetPublicFeed lists recent status changes of public tasks, newest first,
+// redacted for anonymous display (see redactStatusEvent). It needs no token
+// and may be cached for publicFeedMaxAge.
+func (h *handlers) GetPublicFeed(w http.ResponseWriter, r *http.Request) {
+	limit := util.ParseLimit(r, 50, 200)
+	cursor, err := util.ParseCursor(r, h.cfg.CursorTTL)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	events, next, err := h.taskRepo.ListTaskStatusEvents(r.Context(), limit, cursor)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to list task status events")
+		return
+	}
+
+	items := make([]publicFeedEvent, len(events))
+	for i, e := range events {
+		items[i] = redactStatusEvent(e)
+	}
+	resp := map[string]any{"items": items}
+	if next != nil {
+		resp["next_cursor"] = util.EncodeCursor(next)
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(publicFeedMaxAge.Seconds())))
+	util.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// statusEventFields classifies every store.TaskStatusEvent field for the
+// public feed: published as is, derived (reduced before publishing) or
+// withheld. An unclassified field fails TestRedactStatusEvent_ClassifiesEveryField.
+var statusEventFields = map[string]string{
+	"ID":              "published",
+	"ChainID":         "published",
+	"OldStatus":       "published",
+	"NewStatus":       "published",
+	"CreatedAt":       "published",
+	"TaskHash":        "derived",
+	"AmountWei":       "derived",
+	"TaskID":          "withheld",
+	"EmployerAddress": "withheld",
+	"WorkerAddress":   "withheld",
+	"Title":           "withheld",
+}
+
+func TestRedactStatusEvent_ClassifiesEveryField(t *testing.T) {
+	typ := reflect.TypeOf(store.TaskStatusEvent{})
+	for i := 0; i < typ.NumField(); i++ {
+		if name := typ.Field(i).Name; statusEventFields[name] == "" {
+			t.Errorf("store.TaskStatusEvent.%s is not classified for the public feed; add it to statusEventFields and decide in redactStatusEvent", name)
+		}
+	}
+}
+
+// statusEventRows are unredacted rows as ListTaskStatusEvents returns them.
+func statusEventRows() []*store.TaskStatusEvent {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []*store.TaskStatusEvent{
+		{
+			ID: 42, TaskID: "task-secret-001", ChainID: 11155111,
+			TaskHash:  "0x8b1a944cf13a9a1c08facb2c9e98623ef3254d2ddb48113885c3e8e97fec8db9",
+			OldStatus: store.TaskStatusAccepted, NewStatus: store.TaskStatusReleased,
+			AmountWei:       "1500000000000000000",
+			EmployerAddress: "0x00000000000000000000000000000000000000e1",
+			WorkerAddress:   "0x00000000000000000000000000000000000000a1",
+			Title:           "confidential audit", CreatedAt: at,
+		},
+		{
+			ID: 41, TaskID: "task-secret-002", ChainID: 1,
+			TaskHash:        "0x5c2f2bc1ca84466e217482ecb80f0d439beef5409441ce53c329b2b117e14440",
+			NewStatus:       store.TaskStatusCreated,
+			AmountWei:       "999",
+			EmployerAddress: "0x00000000000000000000000000000000000000e2",
+			Title:           "another secret", CreatedAt: at.Add(-time.Minute),
+		},
+	}
+}
+
+func TestRedactStatusEvent_WithholdsAndReduces(t *testing.T) {
+	for _, row := range statusEventRows() {
+		got := redactStatusEvent(row)
+		body, _ := json.Marshal(got)
+
+		rv := reflect.ValueOf(*row)
+		for name, class := range statusEventFields {
+			if class == "published" {
+				continue
+			}
+			v := rv.FieldByName(name)
+			if v.Kind() == reflect.String && v.String() != "" && strings.Contains(string(body), v.String()) {
+				t.Errorf("event %d: %s field %s leaked: %s", row.ID, class, name, body)
+			}
+		}
+
+		if got.TaskRef != row.TaskHash[:18] || !strings.HasPrefix(row.TaskHash, got.TaskRef) {
+			t.Errorf("task_ref = %q, want the task_hash prefix of %q", got.TaskRef, row.TaskHash)
+		}
+		if got.ChainID != row.ChainID || got.From != row.OldStatus || got.To != row.NewStatus || !got.At.Equal(row.CreatedAt) {
+			t.Errorf("event %d: %+v does not match row %+v", row.ID, got, row)
+		}
+	}
+}
+
+func TestAmountBucket(t *testing.T) {
+	for in, want := range map[string]string{
+		"1":                   "1e0",
+		"999":                 "1e2",
+		"1000":                "1e3",
+		"1500000000000000000": "1e18",
+		"0":                   "",
+		"-5":                  "",
+		"":                    "",
+		"12abc":               "",
+	} {
+		if got := amountBucket(in); got != want {
+			t.Errorf("amountBucket(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// statusEventRepo serves fixed status events, one page per call.
+type statusEventRepo struct {
+	store.TaskRepo
+	events []*store.TaskStatusEvent
+	cursor *store.Cursor
+}
+
+func (r *statusEventRepo) ListTaskStatusEvents(_ context.Context, limit int, cursor *store.Cursor) ([]*store.TaskStatusEvent, *store.Cursor, error) {
+	r.cursor = cursor
+	if len(r.events) > limit {
+		last := r.events[limit-1]
+		return r.events[:limit], &store.Cursor{CreatedAt: last.CreatedAt.Format(time.RFC3339Nano), ObjectID: "41"}, nil
+	}
+	return r.events, nil, nil
+}
+
+func TestGetPublicFeed(t *testing.T) {
+	rows := statusEventRows()
+	repo := &statusEventRepo{events: rows}
+	router := NewRouter(nil, repo, config.Config{}, nil)
+
+	get := func(query string) (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/feed"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return rec, resp
+	}
+
+	rec, resp := get("")
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=15" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	var items []publicFeedEvent
+	if err := json.Unmarshal(resp["items"], &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != len(rows) {
+		t.Fatalf("%d items, want %d", len(items), len(rows))
+	}
+	for i, row := range rows {
+		if want := redactStatusEvent(row); !reflect.DeepEqual(items[i], want) {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want)
+		}
+	}
+	for _, row := range rows {
+		for _, secret := range []string{row.TaskID, row.EmployerAddress, row.Title, row.TaskHash} {
+			if strings.Contains(rec.Body.String(), secret) {
+				t.Errorf("response leaks %q", secret)
+			}
+		}
+	}
+	if items[0].AmountBucket != "1e18" || items[1].From != "" || items[1].To != store.TaskStatusCreated {
+		t.Errorf("items = %+v", items)
+	}
+
+	// A page with more events left carries a cursor that reaches the repo.
+	_, resp = get("?limit=1")
+	var next string
+	if err := json.Unmarshal(resp["next_cursor"], &next); err != nil || next == "" {
+		t.Fatalf("next_cursor = %s, %v", resp["next_cursor"], err)
+	}
+	get("?limit=1&cursor=" + next)
+	if repo.cursor == nil || repo.cursor.ObjectID != "41" {
+		t.Errorf("cursor passed to repo = %+v", repo.cursor)
+	}
+}
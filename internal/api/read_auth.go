@@ -0,0 +1,193 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/memlimit"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// Signed-read headers. A reader proves control of an address by signing a
+// nonce from GET /v1/auth/challenge with EIP-191 personal_sign over
+// keccak256(readChallengePrefix + nonce). A nonce can be reused until it
+// expires.
+const (
+	headerAuthAddress   = "X-AMN-Auth-Address"
+	headerAuthNonce     = "X-AMN-Auth-Nonce"
+	headerAuthSignature = "X-AMN-Auth-Signature"
+
+	readChallengePrefix = "AgentMesh-Net read challenge: "
+	readChallengeTTL    = 5 * time.Minute
+	// maxReadChallenges bounds the outstanding nonces kept in memory.
+	maxReadChallenges = 100000
+)
+
+var (
+	errChallengeLimit   = errors.New("too many outstanding challenges")
+	errUnknownChallenge = errors.New("unknown or expired nonce; request a new challenge")
+)
+
+// challengeStore holds issued read nonces until they expire.
+type challengeStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	now     func() time.Time
+}
+
+func newChallengeStore() *challengeStore {
+	return &challengeStore{expires: make(map[string]time.Time), now: time.Now}
+}
+
+// issue returns a new random nonce and its expiry.
+func (s *challengeStore) issue() (string, time.Time, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", time.Time{}, err
+	}
+	nonce := hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if len(s.expires) >= maxReadChallenges {
+		for n, exp := range s.expires {
+			if !now.Before(exp) {
+				delete(s.expires, n)
+			}
+		}
+		if len(s.expires) >= maxReadChallenges {
+			return "", time.Time{}, errChallengeLimit
+		}
+	}
+	exp := now.Add(readChallengeTTL)
+	s.expires[nonce] = exp
+	return nonce, exp, nil
+}
+
+// valid reports whether nonce was issued and has not expired.
+func (s *challengeStore) valid(nonce string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.expires[nonce]
+	if ok && !s.now().Before(exp) {
+		delete(s.expires, nonce)
+		return false
+	}
+	return ok
+}
+
+// challengeBytes approximates the memory of one outstanding nonce.
+const challengeBytes = 64 + 2*32 + 24
+
+// Size returns the approximate bytes held by outstanding nonces.
+func (s *challengeStore) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.expires)) * challengeBytes
+}
+
+// Evict drops about fraction of the nonces (memlimit.Cache), expired ones
+// first. A client whose nonce is dropped gets a 401 and fetches a new one.
+func (s *challengeStore) Evict(fraction float64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	drop := memlimit.EvictCount(len(s.expires), fraction)
+	now, n := s.now(), 0
+	for nonce, exp := range s.expires {
+		if n == drop {
+			return n
+		}
+		if !now.Before(exp) {
+			delete(s.expires, nonce)
+			n++
+		}
+	}
+	for nonce := range s.expires {
+		if n == drop {
+			break
+		}
+		delete(s.expires, nonce)
+		n++
+	}
+	return n
+}
+
+// ── GET /v1/auth/challenge ────────────────────────────────────────────────────
+
+// GetAuthChallenge issues a nonce for the signed-read headers.
+func (h *handlers) GetAuthChallenge(w http.ResponseWriter, r *http.Request) {
+	nonce, exp, err := h.challenges.issue()
+	if err != nil {
+		if errors.Is(err, errChallengeLimit) {
+			util.WriteError(w, http.StatusServiceUnavailable, "challenge_limit_exceeded", "too many outstanding challenges; retry later")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, "internal", "failed to issue challenge")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"nonce":      nonce,
+		"message":    readChallengePrefix + nonce,
+		"expires_at": exp.UTC(),
+	})
+}
+
+// readerAddress returns the lowercase address proven by the signed-read
+// headers, or "" if the request carries none. Headers that are present but
+// do not verify are an error, so a client never silently gets a redacted
+// view because its proof was wrong.
+func (h *handlers) readerAddress(r *http.Request) (string, error) {
+	addr := r.Header.Get(headerAuthAddress)
+	nonce := r.Header.Get(headerAuthNonce)
+	sig := r.Header.Get(headerAuthSignature)
+	if addr == "" && nonce == "" && sig == "" {
+		return "", nil
+	}
+	if !reHexAddr.MatchString(addr) {
+		return "", errors.New(headerAuthAddress + " must be 0x + 40 hex chars")
+	}
+	if !h.challenges.valid(nonce) {
+		return "", errUnknownChallenge
+	}
+	if err := service.CountVerification(service.SchemeEIP191, ethutil.VerifyPersonalSign([]byte(readChallengePrefix+nonce), sig, addr)); err != nil {
+		return "", errors.New("signature does not match " + headerAuthAddress)
+	}
+	return strings.ToLower(addr), nil
+}
+
+// privateTaskView is all a private task shows to readers who have not proven
+// they are its employer or an invited worker.
+type privateTaskView struct {
+	ChainID    int    `json:"chain_id"`
+	Status     string `json:"status"`
+	TaskID     string `json:"task_id"`
+	Visibility string `json:"visibility"`
+}
+
+func newPrivateTaskView(t *store.Task) privateTaskView {
+	return privateTaskView{ChainID: t.ChainID, Status: t.Status, TaskID: t.TaskID, Visibility: store.TaskVisibilityPrivate}
+}
+
+// taskAccess reports whether r may see t in full; see service.CanView. The
+// signed-read headers are only checked for private tasks. If they are present
+// but do not verify, it writes a 401 and returns ok=false.
+func (h *handlers) taskAccess(w http.ResponseWriter, r *http.Request, t *store.Task) (full, ok bool) {
+	if t.Visibility != store.TaskVisibilityPrivate {
+		return true, true
+	}
+	addr, err := h.readerAddress(r)
+	if err != nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+		return false, false
+	}
+	return service.CanView(t, addr), true
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// listAcceptsRepo adds an empty ListAccepts to acceptRepo.
+type listAcceptsRepo struct{ *acceptRepo }
+
+func (listAcceptsRepo) ListAccepts(context.Context, string) ([]*store.Accept, error) { return nil, nil }
+
+func signReadChallenge(t *testing.T, key *ecdsa.PrivateKey, nonce string) string {
+	t.Helper()
+	msg := ethutil.Keccak256([]byte(readChallengePrefix + nonce))
+	sig, err := crypto.Sign(ethutil.Keccak256(append([]byte("\x19Ethereum Signed Message:\n32"), msg...)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig)
+}
+
+func TestPrivateTask_ViewerClasses(t *testing.T) {
+	employer, _ := crypto.GenerateKey()
+	invitee, _ := crypto.GenerateKey()
+	stranger, _ := crypto.GenerateKey()
+	addr := func(k *ecdsa.PrivateKey) string { return crypto.PubkeyToAddress(k.PublicKey).Hex() }
+
+	repo := listAcceptsRepo{&acceptRepo{tasks: map[string]*store.Task{
+		"p-1": {
+			TaskID: "p-1", ChainID: 11155111, Status: store.TaskStatusCreated, Title: "secret plans", AmountWei: "1",
+			EmployerAddress: strings.ToLower(addr(employer)),
+			Visibility:      store.TaskVisibilityPrivate, AllowedWorkers: []string{strings.ToLower(addr(invitee))},
+		},
+	}, accepts: map[string]*store.Accept{}}}
+	router := NewRouter(nil, repo, config.Config{MaxBodyBytes: 1 << 20}, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/auth/challenge", nil))
+	var challenge struct {
+		Nonce   string `json:"nonce"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &challenge); err != nil || challenge.Nonce == "" ||
+		challenge.Message != readChallengePrefix+challenge.Nonce {
+		t.Fatalf("challenge: %v %s", err, rec.Body)
+	}
+
+	get := func(target string, key *ecdsa.PrivateKey, nonce string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if key != nil {
+			req.Header.Set(headerAuthAddress, addr(key))
+			req.Header.Set(headerAuthNonce, nonce)
+			req.Header.Set(headerAuthSignature, signReadChallenge(t, key, nonce))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, tc := range []struct {
+		name string
+		key  *ecdsa.PrivateKey
+		full bool
+	}{
+		{"anonymous", nil, false},
+		{"stranger", stranger, false},
+		{"employer", employer, true},
+		{"invited_worker", invitee, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := get("/v1/tasks/p-1", tc.key, challenge.Nonce)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("task: status %d: %s", rec.Code, rec.Body)
+			}
+			var body map[string]any
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if _, hasTitle := body["title"]; hasTitle != tc.full || body["status"] != "created" || body["visibility"] != "private" {
+				t.Errorf("task view = %s, want full=%v", rec.Body, tc.full)
+			}
+
+			want := http.StatusForbidden
+			if tc.full {
+				want = http.StatusOK
+			}
+			if rec := get("/v1/tasks/p-1/accepts", tc.key, challenge.Nonce); rec.Code != want {
+				t.Errorf("accepts: status %d, want %d", rec.Code, want)
+			}
+		})
+	}
+
+	t.Run("bad_proof", func(t *testing.T) {
+		if rec := get("/v1/tasks/p-1", employer, "not-issued"); rec.Code != http.StatusUnauthorized {
+			t.Errorf("unknown nonce: status %d, want 401", rec.Code)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/v1/tasks/p-1", nil)
+		req.Header.Set(headerAuthAddress, addr(employer))
+		req.Header.Set(headerAuthNonce, challenge.Nonce)
+		req.Header.Set(headerAuthSignature, signReadChallenge(t, stranger, challenge.Nonce))
+		failed := func() uint64 {
+			for _, s := range service.SignatureStats() {
+				if s.Scheme == sigSchemeEIP191 {
+					return s.Failed
+				}
+			}
+			return 0
+		}
+		before := failed()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("wrong signer: status %d, want 401", rec.Code)
+		}
+		if n := failed() - before; n != 1 {
+			t.Errorf("counted %d failed verifications, want 1", n)
+		}
+	})
+
+	t.Run("uninvited_accept", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tasks/p-1/accept",
+			strings.NewReader(acceptBody(t, stranger, "p-1", "acc-stranger"))))
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "worker_not_invited") {
+			t.Errorf("status %d: %s", rec.Code, rec.Body)
+		}
+	})
+}
+
+func TestChallengeStore_EvictsExpiredFirst(t *testing.T) {
+	s := newChallengeStore()
+	clock := time.Unix(1000, 0)
+	s.now = func() time.Time { return clock }
+	var stale []string
+	for i := 0; i < 4; i++ {
+		n, _, err := s.issue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stale = append(stale, n)
+	}
+	clock = clock.Add(readChallengeTTL)
+	for i := 0; i < 4; i++ {
+		if _, _, err := s.issue(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := s.Evict(0.5); got != 4 {
+		t.Fatalf("Evict(0.5) = %d, want 4", got)
+	}
+	for _, n := range stale {
+		if _, ok := s.expires[n]; ok {
+			t.Errorf("expired nonce %s kept while live ones remain", n)
+		}
+	}
+	if s.Size() != 4*challengeBytes {
+		t.Errorf("Size = %d, want %d", s.Size(), 4*challengeBytes)
+	}
+}
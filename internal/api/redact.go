@@ -0,0 +1,89 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// authenticated reports whether the request carries a valid bearer token:
+// one of cfg.APITokens or the admin token.
+func (h *handlers) authenticated(r *http.Request) bool {
+	return h.clientToken(r) != ""
+}
+
+// clientToken returns the request's bearer token if it is valid, else "".
+// The result of identifyClient is reused when the request went through it.
+func (h *handlers) clientToken(r *http.Request) string {
+	if token, ok := r.Context().Value(clientTokenKey{}).(string); ok {
+		return token
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return ""
+	}
+	match := false
+	for _, t := range h.cfg.APITokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			match = true
+		}
+	}
+	if h.cfg.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.AdminToken)) == 1 {
+		match = true
+	}
+	if !match {
+		return ""
+	}
+	return token
+}
+
+// redactAddresses reports whether addresses in read responses must be
+// redacted for this request.
+func (h *handlers) redactAddresses(r *http.Request) bool {
+	return h.cfg.RedactAddresses && !h.authenticated(r)
+}
+
+// redactAddress keeps the first and last 4 hex characters of a 0x address,
+// e.g. 0x1234…cdef. Empty and malformed values are returned unchanged.
+func redactAddress(addr string) string {
+	if !reHexAddr.MatchString(addr) {
+		return addr
+	}
+	return addr[:6] + "…" + addr[len(addr)-4:]
+}
+
+// taskView builds the task read representation for r; see renderTask.
+func (h *handlers) taskView(r *http.Request, t *store.Task) taskResponse {
+	return h.renderTask(t, h.redactAddresses(r))
+}
+
+// renderTask builds the task read representation, with an explorer link for
+// the onchain transaction when the chain has a template, redacting addresses
+// when redact is set. Otherwise cached ENS names are added when ENS resolution is
+// configured; a name would defeat redaction, so it is never shown then.
+func (h *handlers) renderTask(t *store.Task, redact bool) taskResponse {
+	resp := newTaskResponse(t)
+	if c, ok := h.cfg.Chain(t.ChainID); ok {
+		resp.ExplorerURL = c.ExplorerTxURL(t.OnchainTxHash)
+	}
+	if redact {
+		resp.EmployerAddress = redactAddress(resp.EmployerAddress)
+		resp.WorkerAddress = redactAddress(resp.WorkerAddress)
+		resp.OnchainWorker = redactAddress(resp.OnchainWorker)
+		if resp.AllowedWorkers != nil {
+			allowed := make([]string, len(resp.AllowedWorkers))
+			for i, a := range resp.AllowedWorkers {
+				allowed[i] = redactAddress(a)
+			}
+			resp.AllowedWorkers = allowed
+		}
+		return resp
+	}
+	if h.ens != nil {
+		resp.EmployerENS = h.ens.Name(resp.EmployerAddress)
+		resp.WorkerENS = h.ens.Name(resp.WorkerAddress)
+	}
+	return resp
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestRedactAddress(t *testing.T) {
+	cases := map[string]string{
+		"0x00000000000000000000000000000000000000e1": "0x0000…00e1",
+		"":               "",
+		"not-an-address": "not-an-address",
+	}
+	for in, want := range cases {
+		if got := redactAddress(in); got != want {
+			t.Errorf("redactAddress(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGetTask_AddressRedaction(t *testing.T) {
+	task := fixtureTask(true)
+	repo := &taskObjectsRepo{tasks: map[string]*store.Task{task.TaskID: task}}
+
+	cases := []struct {
+		name       string
+		redact     bool
+		token      string
+		wantRedact bool
+	}{
+		{"disabled", false, "", false},
+		{"anonymous", true, "", true},
+		{"wrong_token", true, "nope", true},
+		{"api_token", true, "reader", false},
+		{"admin_token", true, "admin", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Config{RedactAddresses: tc.redact, APITokens: []string{"reader"}, AdminToken: "admin"}
+			router := NewRouter(repo, repo, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/tasks/"+task.TaskID, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			var got taskResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			wantEmployer, wantWorker := task.EmployerAddress, task.WorkerAddress
+			if tc.wantRedact {
+				wantEmployer, wantWorker = "0x0000…00e1", "0x0000…00a1"
+			}
+			if got.EmployerAddress != wantEmployer || got.WorkerAddress != wantWorker {
+				t.Errorf("addresses = %s / %s, want %s / %s", got.EmployerAddress, got.WorkerAddress, wantEmployer, wantWorker)
+			}
+		})
+	}
+}
@@ -1,50 +1,250 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
 	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/ens"
+	"github.com/AgentMesh-Net/indexer-go/internal/memlimit"
+	"github.com/AgentMesh-Net/indexer-go/internal/ratelimit"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
 	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+	"github.com/AgentMesh-Net/indexer-go/internal/verifypool"
 )
 
-// NewRouter creates the HTTP router with all v1 endpoints.
-func NewRouter(repo store.Repo, taskRepo store.TaskRepo, cfg config.Config) http.Handler {
-	r := chi.NewRouter()
+// NewRouter creates the HTTP router with all v1 endpoints. watchers are the
+// running chain watchers whose liveness is reported by /v1/health/ready.
+func NewRouter(repo store.Repo, taskRepo store.TaskRepo, cfg config.Config, watchers []*chain.Watcher) http.Handler {
+	h := newHandlers(repo, taskRepo, cfg, watchers)
+	r := h.newMux()
+	h.mountReads(r)
+	h.mountWrites(r)
+	return r
+}
+
+// NewSplitRouters creates the routers for separate read and write listeners
+// (AMN_HTTP_WRITE_ADDR). public serves reads, health, meta and the live
+// feed; private serves writes, admin, metrics and pprof, plus health for
+// probes. Both share one set of handlers, so maintenance mode, limiters and
+// the feed are common to the two. A route served by the other listener is
+// reported as not found rather than method not allowed.
+func NewSplitRouters(repo store.Repo, taskRepo store.TaskRepo, cfg config.Config, watchers []*chain.Watcher) (public, private http.Handler) {
+	h := newHandlers(repo, taskRepo, cfg, watchers)
+
+	pub := h.newMux()
+	pub.MethodNotAllowed(http.NotFound)
+	h.mountReads(pub)
+
+	priv := h.newMux()
+	priv.MethodNotAllowed(http.NotFound)
+	h.mountHealth(priv)
+	h.mountWrites(priv)
+	priv.Mount("/debug", middleware.Profiler())
+
+	return pub, priv
+}
+
+// newHandlers builds the handler state shared by every router over repo and
+// taskRepo.
+func newHandlers(repo store.Repo, taskRepo store.TaskRepo, cfg config.Config, watchers []*chain.Watcher) *handlers {
+	h := &handlers{repo: repo, taskRepo: taskRepo, maxBody: cfg.MaxBodyBytes, cfg: cfg, watchers: watchers}
+	h.maintenance = newMaintenanceMode(cfg.MaintenanceMode, cfg.MaintenanceMessage)
+	h.challenges = newChallengeStore()
+	memlimit.Register("read_challenges", h.challenges)
+	h.verifier = newReadVerifier(cfg.RevokedSigners)
+
+	if cfg.ENSRPCEndpoint != "" {
+		resolver, err := ens.Dial(context.Background(), cfg.ENSRPCEndpoint)
+		if err != nil {
+			log.Printf("[ens] disabled: %v", err)
+		} else {
+			h.ens = resolver
+			memlimit.Register("ens_names", resolver)
+		}
+	}
+
+	// Live feed: task transitions are published when the repo supports hooks,
+	// and the watchers' lag alarms as indexer events.
+	h.feed = NewFeedBroadcaster(cfg.MaxFeedClients)
+	if cfg.FeedDedupSize > 0 {
+		h.feedSeen = newRecentIDs(cfg.FeedDedupSize, cfg.FeedDedupTTL)
+	}
+	if hooked, ok := taskRepo.(interface{ OnTransition(store.TransitionHook) }); ok {
+		hooked.OnTransition(h.publishTransition)
+	}
+	for _, w := range watchers {
+		w.OnLagAlarm(h.publishLagAlarm)
+	}
+
+	// Insert hooks run after synchronous inserts in objectService and after
+	// queued ones on the ingestion workers.
+	h.insertHooks = service.DefaultInsertHooks
+	if q, ok := repo.(interface {
+		OnStored(func(context.Context, *envelope.Envelope))
+	}); ok {
+		q.OnStored(h.insertHooks.Run)
+	}
+	h.objectQueue, _ = repo.(service.ObjectQueue)
+
+	// Slow-request logs break requests down by repo call; the repos are
+	// wrapped after the optional interfaces above have been looked up.
+	if cfg.SlowRequestThreshold > 0 {
+		if repo != nil {
+			h.repo = store.NewTimedRepo(repo)
+		}
+		if taskRepo != nil {
+			h.taskRepo = store.NewTimedTaskRepo(taskRepo)
+		}
+	}
+
+	if cfg.SignedResponsesPerMinute > 0 {
+		h.signLimiter = ratelimit.PerMinute(cfg.SignedResponsesPerMinute)
+	}
+	// Validate has rejected malformed entries.
+	h.trustedProxies, _ = cfg.TrustedProxyPrefixes()
+	if cfg.IPRateLimitPerMinute > 0 {
+		h.ipLimiter = ratelimit.NewKeyed(cfg.IPRateLimitPerMinute, maxClientBuckets)
+		memlimit.Register("ip_rate_limits", h.ipLimiter)
+	}
+	if cfg.TokenRateLimitPerMinute > 0 {
+		h.tokenLimiter = ratelimit.NewKeyed(cfg.TokenRateLimitPerMinute, maxClientBuckets)
+		memlimit.Register("token_rate_limits", h.tokenLimiter)
+	}
 
+	if cfg.EnableOnchainHashVerification || cfg.EnableEscrowCodeVerification {
+		h.contractCallers = newContractCallers(cfg)
+	}
+
+	if cfg.VerifyWorkers > 0 {
+		h.verifyPool = verifypool.New(cfg.VerifyWorkers, cfg.VerifyQueueSize, cfg.VerifyQueueTimeout)
+	}
+
+	h.chainTaskLimiters = make(map[int]*ratelimit.Limiter)
+	for _, c := range cfg.SupportedChains {
+		if c.MaxTasksPerMinute > 0 {
+			h.chainTaskLimiters[c.ChainID] = ratelimit.PerMinute(c.MaxTasksPerMinute)
+		}
+	}
+	return h
+}
+
+// newMux returns a router with the middleware every listener shares.
+func (h *handlers) newMux() chi.Router {
+	r := chi.NewRouter()
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Timeout(30 * time.Second))
+	if h.cfg.SlowRequestThreshold > 0 {
+		r.Use(unlessPrefix("/v1/ws/", h.logSlowRequests))
+	}
+	r.Use(h.realIP)
+	r.Use(h.identifyClient)
+	r.Use(h.limitClients)
+	r.Use(unlessPrefix("/v1/ws/", middleware.Timeout(30*time.Second)))
+	r.Use(h.rejectWritesInMaintenance)
+	r.Use(allowReplicaReads)
+	return r
+}
 
-	h := &handlers{repo: repo, taskRepo: taskRepo, maxBody: cfg.MaxBodyBytes, cfg: cfg}
+// allowReplicaReads lets GET and HEAD requests be served from the read
+// replica when one is configured; see store.AllowReplica. A GET right after
+// a write may therefore not see it yet.
+func allowReplicaReads(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			r = r.WithContext(store.AllowReplica(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-	// Phase 5: structured task endpoints
+// mountHealth mounts the liveness, readiness and chain freshness probes.
+func (h *handlers) mountHealth(r chi.Router) {
 	r.Get("/v1/health", h.GetHealth)
+	r.Get("/v1/health/ready", h.GetHealthReady)
+	r.Get("/v1/health/chains", h.GetHealthChains)
+}
+
+// mountReads mounts the public read endpoints: health, meta, task and
+// envelope lookups and listings, and the live feed.
+func (h *handlers) mountReads(r chi.Router) {
+	h.mountHealth(r)
 	r.Get("/v1/meta", h.GetMeta)
-	r.Post("/v1/tasks", h.PostTask)
-	r.Get("/v1/tasks", h.ListTasks)
-	r.Get("/v1/tasks/{taskID}", h.GetTask)
-	r.Post("/v1/tasks/{taskID}/accept", h.PostTaskAccept)
+	r.Get("/v1/chains", h.GetChains)
+	r.Get("/v1/stats/signatures", h.GetSignatureStats)
+	r.Get("/v1/auth/challenge", h.GetAuthChallenge)
+	r.With(h.includeDeleted).Get("/v1/tasks", h.v2Tasks(h.ListTasks))
+	r.Get("/v1/tasks/compute-hash", h.v2Tasks(h.GetTaskComputeHash))
+	r.With(h.includeDeleted, h.signResponse).Get("/v1/tasks/{taskID}", h.v2Tasks(h.GetTask))
+	r.Get("/v1/tasks/{taskID}/preview", h.v2Tasks(h.GetTaskPreview))
+	r.With(h.includeDeleted).Get("/v1/tasks/{taskID}/objects", h.ListTaskObjects)
+	r.Get("/v1/tasks/{taskID}/accepts", h.v2Tasks(h.ListTaskAccepts))
+	r.Get("/v1/workers/{address}/tier", h.GetWorkerTier)
+	r.Get("/v1/employers/{address}/next-sequence", h.GetNextEmployerSequence)
+	r.Get("/v1/employers/{address}/quota", h.GetEmployerQuota)
+	r.Get("/v1/search/tx/{txHash}", h.SearchTx)
+	r.Get("/v1/tvl", h.GetTVL)
+	r.Get("/v1/snapshot", h.GetSnapshot)
+	r.Get("/v1/feed", h.v2Tasks(h.GetPublicFeed))
+	r.Get("/v1/reports/unfunded-accepts", h.GetUnfundedAccepts)
+	r.Get("/v1/ws/feed", h.GetFeed)
 
 	// Legacy envelope endpoints
-	r.Route("/v1", func(r chi.Router) {
-		r.Get("/indexer/info", h.GetInfo)
+	r.Get("/v1/indexer/info", h.legacyObjects(h.GetInfo))
+	r.With(h.includeDeleted).Get("/v1/bids", h.legacyObjects(h.ListObjects("bid")))
+	r.With(h.includeDeleted).Get("/v1/accepts", h.legacyObjects(h.ListObjects("accept")))
+	r.With(h.includeDeleted).Get("/v1/artifacts", h.legacyObjects(h.ListObjects("artifact")))
+	r.With(h.includeDeleted).Get("/v1/objects", h.ListObjectsBySigner)
+	r.With(h.includeDeleted, h.signResponse).Get("/v1/objects/{objectID}", h.GetObject)
+}
+
+// mountWrites mounts the submission endpoints, the admin API and metrics.
+func (h *handlers) mountWrites(r chi.Router) {
+	r.Get("/metrics", h.GetMetrics)
+	r.Post("/v1/tasks", h.v2Tasks(h.PostTask))
+	r.Post("/v1/tasks/batch", h.v2Tasks(h.PostTaskBatch))
+	r.Post("/v1/tasks/{taskID}/accept", h.v2Tasks(h.PostTaskAccept))
 
-		r.Post("/bids", h.PostObject("bid"))
-		r.Get("/bids", h.ListObjects("bid"))
+	// Legacy envelope endpoints
+	r.Post("/v1/bids", h.legacyObjects(h.PostObject("bid")))
+	r.Post("/v1/accepts", h.legacyObjects(h.PostAccept))
+	r.Post("/v1/artifacts", h.legacyObjects(h.PostObject("artifact")))
 
-		r.Post("/accepts", h.PostAccept)
-		r.Get("/accepts", h.ListObjects("accept"))
+	// Signing helpers for client developers (AMN_DEV_MODE)
+	if h.cfg.DevMode {
+		r.Post("/v1/dev/sign-task", h.PostDevSignTask)
+		r.Post("/v1/dev/sign-envelope", h.PostDevSignEnvelope)
+	}
 
-		r.Post("/artifacts", h.PostObject("artifact"))
-		r.Get("/artifacts", h.ListObjects("artifact"))
+	// Admin endpoints (AMN_ADMIN_TOKEN)
+	r.Route("/v1/admin", func(r chi.Router) {
+		r.Use(h.requireAdmin)
+		r.Get("/telemetry-preview", h.GetTelemetryPreview)
+		r.Post("/reprocess-tx", h.PostReprocessTx)
+		r.Post("/tasks/{taskID}/reverify", h.PostTaskReverify)
+		r.Delete("/tasks/{taskID}", h.DeleteTask)
+		r.Get("/objects/{objectID}", h.GetObjectNotes)
+		r.Patch("/objects/{objectID}", h.PatchObject)
+		r.Delete("/objects/{objectID}", h.DeleteObject)
+		r.Post("/workers/{address}/tier", h.PostWorkerTier)
+		r.Get("/audit", h.ListAuditEvents)
+		r.Post("/audit/{id}/ack", h.PostAuditAck)
+		r.Delete("/audit/{id}/ack", h.DeleteAuditAck)
+		r.Post("/maintenance", h.PostMaintenance)
+		r.Get("/fees", h.GetFees)
+		r.Get("/config", h.GetAdminConfig)
 	})
-
-	return r
 }
 
 type handlers struct {
@@ -52,4 +252,119 @@ type handlers struct {
 	taskRepo store.TaskRepo
 	maxBody  int64
 	cfg      config.Config
+	watchers []*chain.Watcher
+
+	// chainTaskLimiters rate-limits task creation per chain_id. Chains without
+	// a configured limit have no entry.
+	chainTaskLimiters map[int]*ratelimit.Limiter
+
+	// contractCallers serves contract reads for task creation checks. Nil
+	// unless onchain task hash or escrow code verification is enabled.
+	contractCallers *contractCallers
+
+	maintenance *maintenanceMode
+
+	// challenges holds nonces issued for signed reads of private tasks.
+	challenges *challengeStore
+
+	// ens resolves display names for addresses. Nil when not configured.
+	ens *ens.Resolver
+
+	// signLimiter caps signed read responses (X-AMN-Sign-Response). Nil
+	// when unlimited.
+	signLimiter *ratelimit.Limiter
+
+	// feed fans task transitions out to GET /v1/ws/feed clients.
+	feed *FeedBroadcaster
+	// feedSeen holds the ids of recently published feed events; nil when
+	// feed dedup is off.
+	feedSeen *recentIDs
+	// insertHooks run after objects are stored; see service.InsertHooks.
+	insertHooks *service.InsertHooks
+	// objectQueue is repo when it queues submissions, else nil.
+	objectQueue service.ObjectQueue
+
+	// ipLimiter and tokenLimiter rate-limit anonymous and authenticated
+	// clients; see limitClients. Nil when unlimited.
+	ipLimiter    *ratelimit.Keyed
+	tokenLimiter *ratelimit.Keyed
+	// trustedProxies may set the client address; see realIP.
+	trustedProxies []netip.Prefix
+
+	// verifier annotates envelopes for ?verify=true reads.
+	verifier *readVerifier
+
+	// tvl caches GET /v1/tvl totals.
+	tvl tvlCache
+
+	// snapshot caches GET /v1/snapshot.
+	snapshot snapshotCache
+
+	// verifyPool runs task and accept signature checks. Nil when
+	// AMN_VERIFY_WORKERS is 0.
+	verifyPool *verifypool.Pool
+}
+
+// taskService returns the task service over h's repos and config. It is
+// built per call so handlers constructed directly in tests see their fields.
+func (h *handlers) taskService() *service.TaskService {
+	s := &service.TaskService{
+		Tasks:         h.taskRepo,
+		Objects:       h.repo,
+		Config:        h.cfg,
+		ChainLimiters: h.chainTaskLimiters,
+	}
+	if h.contractCallers != nil {
+		s.Chains = h.contractCallers
+	}
+	if h.verifyPool != nil {
+		s.Verifier = h.verifyPool
+	}
+	return s
+}
+
+// legacyObjects returns next, or a 404 pointing at /v1/meta when
+// AMN_DISABLE_LEGACY_OBJECTS turns the legacy envelope endpoints off.
+func (h *handlers) legacyObjects(next http.HandlerFunc) http.HandlerFunc {
+	if h.cfg.DisableLegacyObjects {
+		return endpointDisabled("legacy envelope", "AMN_DISABLE_LEGACY_OBJECTS")
+	}
+	return next
+}
+
+// v2Tasks is legacyObjects for the structured task endpoints and
+// AMN_DISABLE_V2_TASKS.
+func (h *handlers) v2Tasks(next http.HandlerFunc) http.HandlerFunc {
+	if h.cfg.DisableV2Tasks {
+		return endpointDisabled("structured task", "AMN_DISABLE_V2_TASKS")
+	}
+	return next
+}
+
+func endpointDisabled(family, setting string) http.HandlerFunc {
+	msg := fmt.Sprintf("%s endpoints are disabled on this indexer (%s); see capabilities in GET /v1/meta", family, setting)
+	return func(w http.ResponseWriter, r *http.Request) {
+		util.WriteError(w, http.StatusNotFound, "endpoint_disabled", msg)
+	}
+}
+
+// objectService returns the object service over h.repo. Submissions are
+// queued when the repo supports it (store.QueuedRepo, AMN_INGEST_ASYNC).
+func (h *handlers) objectService() *service.ObjectService {
+	return &service.ObjectService{Objects: h.repo, Queue: h.objectQueue, Hooks: h.insertHooks, RequireUTCCreatedAt: h.cfg.RequireUTCCreatedAt}
+}
+
+// unlessPrefix applies mw to every request whose path does not start with
+// prefix. Long-lived WebSocket routes use it to skip the request timeout.
+func unlessPrefix(prefix string, mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+func TestSplitRouters(t *testing.T) {
+	repo := &maintenanceRepo{}
+	public, private := NewSplitRouters(nil, repo, config.Config{AdminToken: "secret", MaxBodyBytes: 1 << 20}, nil)
+
+	do := func(router http.Handler, method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, tc := range []struct {
+		router       http.Handler
+		name         string
+		method, path string
+		want         int
+	}{
+		{public, "public", http.MethodGet, "/v1/health", http.StatusOK},
+		{public, "public", http.MethodGet, "/v1/tasks", http.StatusOK},
+		{public, "public", http.MethodPost, "/v1/tasks", http.StatusNotFound},
+		{public, "public", http.MethodPost, "/v1/bids", http.StatusNotFound},
+		{public, "public", http.MethodGet, "/v1/admin/audit", http.StatusNotFound},
+		{public, "public", http.MethodGet, "/metrics", http.StatusNotFound},
+		{public, "public", http.MethodGet, "/debug/pprof/", http.StatusNotFound},
+		{private, "private", http.MethodGet, "/v1/health", http.StatusOK},
+		{private, "private", http.MethodPost, "/v1/tasks", http.StatusBadRequest},
+		{private, "private", http.MethodGet, "/v1/tasks", http.StatusNotFound},
+		{private, "private", http.MethodGet, "/debug/pprof/", http.StatusOK},
+	} {
+		if got := do(tc.router, tc.method, tc.path, "{}"); got != tc.want {
+			t.Errorf("%s %s %s: status %d, want %d", tc.name, tc.method, tc.path, got, tc.want)
+		}
+	}
+
+	// The admin API on the private listener still toggles maintenance mode.
+	if code := do(private, http.MethodPost, "/v1/admin/maintenance", `{"enabled":true}`); code != http.StatusOK {
+		t.Fatalf("enable maintenance: status %d", code)
+	}
+	if code := do(private, http.MethodPost, "/v1/tasks", "{}"); code != http.StatusServiceUnavailable {
+		t.Errorf("write during maintenance: status %d, want 503", code)
+	}
+}
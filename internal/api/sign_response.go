@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+// Response signing headers. The signature is ed25519 over the response body,
+// which is sent in RFC 8785 canonical form so it can be verified as received.
+// The key ID is the hex public key, the same value as public_key in /v1/meta.
+const (
+	headerSignResponse      = "X-AMN-Sign-Response"
+	headerResponseSignature = "X-AMN-Signature"
+	headerResponseKeyID     = "X-AMN-Key-ID"
+)
+
+// wantsSignedResponse reports whether the caller asked for a signed response
+// via the X-AMN-Sign-Response header or ?signed=true.
+func wantsSignedResponse(r *http.Request) bool {
+	if v, err := strconv.ParseBool(r.Header.Get(headerSignResponse)); err == nil && v {
+		return true
+	}
+	v, err := strconv.ParseBool(r.URL.Query().Get("signed"))
+	return err == nil && v
+}
+
+// bufferedResponse holds a response until the handler returns so the body
+// can be signed before anything is sent.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// signResponse signs successful JSON responses for callers that request it.
+// It buffers the whole body, so it is only suitable for single-resource
+// endpoints, not lists or streams. Requests are served unsigned when no
+// signing key is configured.
+func (h *handlers) signResponse(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !wantsSignedResponse(r) || h.cfg.SigningKeyHex == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if h.signLimiter != nil && !h.signLimiter.Allow() {
+			util.WriteError(w, http.StatusTooManyRequests, "sign_rate_limit_exceeded", "too many signed requests")
+			return
+		}
+
+		buf := &bufferedResponse{header: w.Header()}
+		next.ServeHTTP(buf, r)
+		if buf.status == 0 {
+			buf.status = http.StatusOK
+		}
+
+		body := buf.body.Bytes()
+		if buf.status == http.StatusOK && strings.HasPrefix(buf.header.Get("Content-Type"), "application/json") {
+			if signed, pubKeyHex, sigHex, ok := h.signBody(body); ok {
+				body = signed
+				buf.header.Set(headerResponseSignature, sigHex)
+				buf.header.Set(headerResponseKeyID, pubKeyHex)
+			}
+		}
+		buf.header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(buf.status)
+		w.Write(body)
+	})
+}
+
+// signBody canonicalizes body and signs it. ok is false, and the response
+// goes out unsigned, if the key or body is unusable.
+func (h *handlers) signBody(body []byte) (canonical []byte, pubKeyHex, sigHex string, ok bool) {
+	privKey, err := h.signingKey()
+	if err != nil {
+		log.Printf("invalid INDEXER_SIGNING_KEY: %v", err)
+		return nil, "", "", false
+	}
+	canonical, err = canonicaljson.CanonicalizeRaw(body)
+	if err != nil {
+		log.Printf("canonicalize response: %v", err)
+		return nil, "", "", false
+	}
+	sig := ed25519.Sign(privKey, canonical)
+	return canonical, hex.EncodeToString(privKey.Public().(ed25519.PublicKey)), hex.EncodeToString(sig), true
+}
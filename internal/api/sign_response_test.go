@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+type objectRepo struct {
+	store.Repo
+	objects map[string]*envelope.Envelope
+}
+
+func (r *objectRepo) GetObjectByID(_ context.Context, id string) (*store.Object, error) {
+	if env, ok := r.objects[id]; ok {
+		return &store.Object{Envelope: *env}, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func signedTestRouter(perMinute int) http.Handler {
+	tasks := &acceptRepo{tasks: map[string]*store.Task{
+		"t-1": {TaskID: "t-1", ChainID: 11155111, Status: store.TaskStatusCreated, Title: "signed"},
+	}}
+	objects := &objectRepo{objects: map[string]*envelope.Envelope{
+		"o-1": {ObjectType: "bid", ObjectVersion: "0.1", ObjectID: "o-1", CreatedAt: "2025-01-01T00:00:00Z",
+			Payload: json.RawMessage(`{"z":1,"a":2}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: "pk"}, Signature: "sig"},
+	}}
+	return NewRouter(objects, tasks, config.Config{
+		SigningKeyHex:            "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		SignedResponsesPerMinute: perMinute,
+	}, nil)
+}
+
+func TestSignResponse_VerifiesAgainstMetaKey(t *testing.T) {
+	router := signedTestRouter(0)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/meta", nil))
+	var meta struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &meta); err != nil || meta.PublicKey == "" {
+		t.Fatalf("meta: %v %s", err, rec.Body)
+	}
+	pub, _ := hex.DecodeString(meta.PublicKey)
+
+	for _, tc := range []struct {
+		name   string
+		target string
+		header bool
+	}{
+		{"task_header", "/v1/tasks/t-1", true},
+		{"object_query", "/v1/objects/o-1?signed=true", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.header {
+				req.Header.Set("X-AMN-Sign-Response", "true")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("X-AMN-Key-ID"); got != meta.PublicKey {
+				t.Errorf("key id = %q, want meta public key %q", got, meta.PublicKey)
+			}
+			sig, err := hex.DecodeString(rec.Header().Get("X-AMN-Signature"))
+			if err != nil {
+				t.Fatalf("signature header: %v", err)
+			}
+			if !ed25519.Verify(pub, rec.Body.Bytes(), sig) {
+				t.Errorf("signature does not verify over body %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestSignResponse_UnsignedAndErrors(t *testing.T) {
+	router := signedTestRouter(1)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/t-1", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("X-AMN-Signature") != "" {
+		t.Errorf("unrequested: status %d, signature %q", rec.Code, rec.Header().Get("X-AMN-Signature"))
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/objects/missing?signed=true", nil))
+	if rec.Code != http.StatusNotFound || rec.Header().Get("X-AMN-Signature") != "" {
+		t.Errorf("not found: status %d, signature %q", rec.Code, rec.Header().Get("X-AMN-Signature"))
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/t-1?signed=true", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("over limit: status %d, want 429", rec.Code)
+	}
+}
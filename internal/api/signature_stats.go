@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+const (
+	sigSchemeEd25519 = service.SchemeEd25519
+	sigSchemeEIP191  = service.SchemeEIP191
+)
+
+type signatureStat = service.SignatureStat
+
+// ── GET /v1/stats/signatures ───────────────────────────────────────────────
+
+// GetSignatureStats returns process-lifetime verification counts per
+// signature scheme. Counts reset on restart; use /metrics for history.
+func (h *handlers) GetSignatureStats(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSON(w, http.StatusOK, map[string]any{"schemes": service.SignatureStats()})
+}
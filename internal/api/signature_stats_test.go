@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+type insertRepo struct{ store.Repo }
+
+func (insertRepo) InsertObject(context.Context, *envelope.Envelope) error { return nil }
+
+func TestSignatureStats_CountsVerifiedAndFailed(t *testing.T) {
+	router := NewRouter(insertRepo{}, nil, config.Config{MaxBodyBytes: 1 << 20}, nil)
+
+	stats := func() map[string]signatureStat {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/stats/signatures", nil))
+		var resp struct {
+			Schemes []signatureStat `json:"schemes"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode %s: %v", rec.Body, err)
+		}
+		out := map[string]signatureStat{}
+		for _, s := range resp.Schemes {
+			out[s.Scheme] = s
+		}
+		return out
+	}
+	before := stats()
+	if _, ok := before[sigSchemeEIP191]; !ok {
+		t.Errorf("eip191 missing from %+v", before)
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	env := envelope.Envelope{
+		ObjectType: "bid", ObjectVersion: "0.1", ObjectID: "stats-bid", CreatedAt: "2025-01-01T00:00:00Z",
+		Payload: json.RawMessage(`{"task_id":"t"}`),
+		Signer:  envelope.Signer{Algo: "ed25519", PubKey: base64.StdEncoding.EncodeToString(pub)},
+	}
+	preimage, _ := env.SignedPreimageBytes()
+	env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, preimage))
+	good, _ := json.Marshal(env)
+	env.Signature = base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))
+	bad, _ := json.Marshal(env)
+
+	for body, want := range map[string]int{string(good): http.StatusCreated, string(bad): http.StatusBadRequest} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/bids", strings.NewReader(body)))
+		if rec.Code != want {
+			t.Fatalf("status %d, want %d: %s", rec.Code, want, rec.Body)
+		}
+	}
+
+	after := stats()[sigSchemeEd25519]
+	if after.Verified != before[sigSchemeEd25519].Verified+1 || after.Failed != before[sigSchemeEd25519].Failed+1 {
+		t.Errorf("ed25519 before %+v, after %+v", before[sigSchemeEd25519], after)
+	}
+}
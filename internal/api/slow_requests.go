@@ -0,0 +1,53 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/timing"
+)
+
+// logSlowRequests times each request and logs one that took at least
+// AMN_SLOW_REQUEST_MS, with the duration of every repo call it made (see
+// store.TimedRepo), so a slow endpoint can be traced to its queries.
+func (h *handlers) logSlowRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, c := timing.NewContext(r.Context())
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+		next.ServeHTTP(ww, r.WithContext(ctx))
+		total := time.Since(start)
+		if total < h.cfg.SlowRequestThreshold {
+			return
+		}
+		route := r.URL.Path
+		if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+			route = rc.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		log.Printf("[slow] request_id=%s method=%s route=%s status=%d total=%s repo_total=%s ops=%s",
+			middleware.GetReqID(ctx), r.Method, route, status, total.Round(time.Microsecond),
+			c.Total().Round(time.Microsecond), formatOps(c.Ops()))
+	})
+}
+
+// formatOps renders ops as name=duration pairs in the order they finished,
+// e.g. "GetTask=12ms,ListAccepts=3.1ms", or "-" when there are none.
+func formatOps(ops []timing.Op) string {
+	if len(ops) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(ops))
+	for i, op := range ops {
+		parts[i] = op.Name + "=" + op.Duration.Round(time.Microsecond).String()
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// slowTaskRepo and slowObjectRepo sleep in the calls GET
+// /v1/tasks/{id}/objects makes.
+type slowTaskRepo struct {
+	store.TaskRepo
+}
+
+func (slowTaskRepo) GetTask(context.Context, string) (*store.Task, error) {
+	time.Sleep(30 * time.Millisecond)
+	return &store.Task{TaskID: "t-1", Status: store.TaskStatusCreated}, nil
+}
+
+type slowObjectRepo struct {
+	store.Repo
+}
+
+func (slowObjectRepo) ListObjectsForTask(context.Context, string, string, int) ([]envelope.Envelope, error) {
+	time.Sleep(20 * time.Millisecond)
+	return nil, nil
+}
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestSlowRequestLog_BreaksDownRepoCalls(t *testing.T) {
+	logs := captureLog(t)
+	router := NewRouter(slowObjectRepo{}, slowTaskRepo{}, config.Config{SlowRequestThreshold: 40 * time.Millisecond}, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/t-1/objects", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	line := logs.String()
+	for _, want := range []string{"[slow] ", "method=GET", "route=/v1/tasks/{taskID}/objects", "status=200"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log %q lacks %q", line, want)
+		}
+	}
+	if !regexp.MustCompile(`request_id=\S+/\S+-\d+ `).MatchString(line) {
+		t.Errorf("log %q lacks the request ID", line)
+	}
+	ops := regexp.MustCompile(`ops=GetTask=(\S+),ListObjectsForTask=(\S+)`).FindStringSubmatch(line)
+	if ops == nil {
+		t.Fatalf("log %q lacks the repo breakdown in call order", line)
+	}
+	for i, min := range []time.Duration{30 * time.Millisecond, 20 * time.Millisecond} {
+		if d, err := time.ParseDuration(ops[i+1]); err != nil || d < min {
+			t.Errorf("op %d took %s (%v), want at least %s", i, ops[i+1], err, min)
+		}
+	}
+}
+
+func TestSlowRequestLog_FastRequestsAreNotLogged(t *testing.T) {
+	logs := captureLog(t)
+	router := NewRouter(slowObjectRepo{}, slowTaskRepo{}, config.Config{SlowRequestThreshold: time.Minute}, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tasks/t-1/objects", nil))
+	if strings.Contains(logs.String(), "[slow]") {
+		t.Errorf("request under the threshold was logged: %s", logs)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// taskFieldNames are the keys of taskResponse: the names ?fields= may select.
+var taskFieldNames = func() []string {
+	rt := reflect.TypeOf(taskResponse{})
+	names := make([]string, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}()
+
+// taskFieldSet is a ?fields= selection of task keys for GET /v1/tasks and
+// GET /v1/tasks/{taskID}. nil selects every field.
+type taskFieldSet map[string]bool
+
+// parseTaskFields reads ?fields=task_id,status,... An absent or empty
+// parameter selects every field; an unknown name is an error.
+func parseTaskFields(r *http.Request) (taskFieldSet, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	set := taskFieldSet{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := slices.BinarySearch(taskFieldNames, name); !ok {
+			return nil, fmt.Errorf("unknown field %q in fields (known: %s)", name, strings.Join(taskFieldNames, ","))
+		}
+		set[name] = true
+	}
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// task returns resp with only the selected keys, or resp itself when every
+// field is selected. Selected fields that resp omits when empty stay absent.
+func (f taskFieldSet) task(resp taskResponse) any {
+	if f == nil {
+		return resp
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return resp
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return resp
+	}
+	for k := range all {
+		if !f[k] {
+			delete(all, k)
+		}
+	}
+	return all
+}
+
+// tasks applies task to every item.
+func (f taskFieldSet) tasks(items []taskResponse) []any {
+	out := make([]any, len(items))
+	for i, t := range items {
+		out[i] = f.task(t)
+	}
+	return out
+}
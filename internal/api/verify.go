@@ -0,0 +1,144 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/util"
+)
+
+const (
+	// readVerifyWorkers bounds the signature checks run concurrently for one
+	// ?verify=true response.
+	readVerifyWorkers = 8
+	// maxReadVerifyCache bounds the verdict cache; it is cleared when full.
+	maxReadVerifyCache = 50000
+)
+
+// objectVerification is the verdict attached to an envelope by ?verify=true.
+// CheckedAt is when the verdict was computed, which may predate the request
+// when it came from the cache.
+type objectVerification struct {
+	Valid     bool      `json:"valid"`
+	CheckedAt time.Time `json:"checked_at"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// verifiedObject is a stored object annotated with its read-time verdict.
+type verifiedObject struct {
+	store.Object
+	Verification objectVerification `json:"verification"`
+}
+
+// verifiedEnvelope is an envelope annotated with its read-time verdict.
+type verifiedEnvelope struct {
+	envelope.Envelope
+	Verification objectVerification `json:"verification"`
+}
+
+// readVerifier re-checks stored envelopes on demand: the envelope must still
+// pass validation and signature verification, and its signer must not be
+// revoked. Objects are immutable, so verdicts are cached per object_id and
+// revocation set.
+type readVerifier struct {
+	revoked map[string]bool // standard base64 signer keys
+	version string          // identifies the revocation set in cache keys
+	now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]objectVerification
+}
+
+// newReadVerifier builds a verifier for the given revoked signer keys (base64
+// or did:key). Keys that do not decode are ignored; config validation
+// rejects them at startup.
+func newReadVerifier(revokedSigners []string) *readVerifier {
+	revoked := make(map[string]bool, len(revokedSigners))
+	for _, k := range revokedSigners {
+		if norm, err := crypto.NormalizeSignerKey(k); err == nil {
+			revoked[norm] = true
+		}
+	}
+	keys := make([]string, 0, len(revoked))
+	for k := range revoked {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, ",")))
+	return &readVerifier{
+		revoked: revoked,
+		version: hex.EncodeToString(sum[:8]),
+		now:     time.Now,
+		cache:   make(map[string]objectVerification),
+	}
+}
+
+// check returns the verdict for env, from the cache when possible.
+func (v *readVerifier) check(env *envelope.Envelope) objectVerification {
+	key := env.ObjectID + "|" + v.version
+	v.mu.Lock()
+	res, ok := v.cache[key]
+	v.mu.Unlock()
+	if ok {
+		return res
+	}
+
+	res = objectVerification{Valid: true, CheckedAt: v.now().UTC()}
+	if err := env.ValidateBasic(); err != nil {
+		res.Valid, res.Reason = false, err.Error()
+	} else if err := service.CountVerification(service.SchemeEd25519, env.Verify()); err != nil {
+		res.Valid, res.Reason = false, err.Error()
+	} else if signer, _ := env.SignerKey(); v.revoked[signer] {
+		res.Valid, res.Reason = false, "signer_revoked"
+	}
+
+	v.mu.Lock()
+	if len(v.cache) >= maxReadVerifyCache {
+		clear(v.cache)
+	}
+	v.cache[key] = res
+	v.mu.Unlock()
+	return res
+}
+
+// objects annotates items with their verdicts.
+func (v *readVerifier) objects(items []store.Object) []verifiedObject {
+	out := make([]verifiedObject, len(items))
+	util.ForEach(len(items), readVerifyWorkers, func(i int) {
+		out[i] = verifiedObject{Object: items[i], Verification: v.check(&items[i].Envelope)}
+	})
+	return out
+}
+
+// envelopes annotates items with their verdicts.
+func (v *readVerifier) envelopes(items []envelope.Envelope) []verifiedEnvelope {
+	out := make([]verifiedEnvelope, len(items))
+	util.ForEach(len(items), readVerifyWorkers, func(i int) {
+		out[i] = verifiedEnvelope{Envelope: items[i], Verification: v.check(&items[i])}
+	})
+	return out
+}
+
+// parseVerify reads the verify query parameter of an envelope read.
+func parseVerify(r *http.Request) (bool, error) {
+	s := r.URL.Query().Get("verify")
+	if s == "" {
+		return false, nil
+	}
+	verify, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.New("verify must be true or false")
+	}
+	return verify, nil
+}
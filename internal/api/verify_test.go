@@ -0,0 +1,127 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/service"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// signedTestObject returns a stored bid signed by priv.
+func signedTestObject(t *testing.T, id string, priv ed25519.PrivateKey) store.Object {
+	t.Helper()
+	env := envelope.Envelope{
+		ObjectType: "bid", ObjectVersion: "0.1", ObjectID: id, CreatedAt: "2025-01-01T00:00:00Z",
+		Payload: json.RawMessage(`{"task_id":"t"}`),
+		Signer: envelope.Signer{
+			Algo:   "ed25519",
+			PubKey: base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey)),
+		},
+	}
+	preimage, err := env.SignedPreimageBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, preimage))
+	return store.Object{Envelope: env}
+}
+
+// edStat returns the ed25519 verification counts.
+func edStat() signatureStat {
+	for _, s := range service.SignatureStats() {
+		if s.Scheme == sigSchemeEd25519 {
+			return s
+		}
+	}
+	return signatureStat{}
+}
+
+func TestListObjects_Verify(t *testing.T) {
+	_, good, _ := ed25519.GenerateKey(nil)
+	revokedPub, revoked, _ := ed25519.GenerateKey(nil)
+
+	tampered := signedTestObject(t, "o-tampered", good)
+	tampered.Payload = json.RawMessage(`{"task_id":"other"}`)
+	repo := &queryRepo{items: []store.Object{
+		signedTestObject(t, "o-good", good),
+		tampered,
+		signedTestObject(t, "o-revoked", revoked),
+	}}
+	router := NewRouter(repo, nil, config.Config{
+		RevokedSigners: []string{base64.StdEncoding.EncodeToString(revokedPub)},
+	}, nil)
+
+	list := func(target string) (int, []verifiedObject) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var resp struct {
+			Items []verifiedObject `json:"items"`
+		}
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode %s: %v", rec.Body, err)
+			}
+		}
+		return rec.Code, resp.Items
+	}
+
+	before := edStat()
+	code, items := list("/v1/bids?verify=true")
+	if code != http.StatusOK || len(items) != 3 {
+		t.Fatalf("status %d, %d items", code, len(items))
+	}
+	if after := edStat(); after.Verified != before.Verified+2 || after.Failed != before.Failed+1 {
+		t.Errorf("ed25519 counts before %+v, after %+v", before, after)
+	}
+	want := map[string]string{"o-good": "", "o-tampered": "verify: ed25519 signature verification failed", "o-revoked": "signer_revoked"}
+	for _, it := range items {
+		v := it.Verification
+		if v.Valid != (want[it.ObjectID] == "") || v.Reason != want[it.ObjectID] || v.CheckedAt.IsZero() {
+			t.Errorf("%s: verification = %+v, want reason %q", it.ObjectID, v, want[it.ObjectID])
+		}
+	}
+
+	// The signer listing annotates too; without verify, items carry no verdict.
+	signer := url.QueryEscape(repo.items[2].Signer.PubKey)
+	if _, items := list("/v1/objects?verify=1&signer_pubkey=" + signer); len(items) != 3 || items[2].Verification.Reason != "signer_revoked" {
+		t.Errorf("signer listing: %+v", items)
+	}
+	if _, items := list("/v1/bids"); len(items) != 3 || !items[0].Verification.CheckedAt.IsZero() {
+		t.Errorf("unverified listing: %+v", items)
+	}
+	if code, _ := list("/v1/bids?verify=maybe"); code != http.StatusBadRequest {
+		t.Errorf("verify=maybe: status %d, want 400", code)
+	}
+}
+
+func TestReadVerifier_CachesPerRevocationSet(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	obj := signedTestObject(t, "o-1", priv)
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := newReadVerifier(nil)
+	v.now = func() time.Time { return clock }
+	first := v.check(&obj.Envelope)
+	clock = clock.Add(time.Minute)
+	if again := v.check(&obj.Envelope); !again.CheckedAt.Equal(first.CheckedAt) || !again.Valid {
+		t.Errorf("second check = %+v, want cached %+v", again, first)
+	}
+
+	// A different revocation set has its own cache entries.
+	revoking := newReadVerifier([]string{base64.StdEncoding.EncodeToString(pub)})
+	if revoking.version == v.version {
+		t.Fatal("revocation sets share a cache version")
+	}
+	if got := revoking.check(&obj.Envelope); got.Valid || got.Reason != "signer_revoked" {
+		t.Errorf("revoked check = %+v", got)
+	}
+}
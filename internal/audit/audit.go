@@ -0,0 +1,97 @@
+// Package audit names the anomalies and operator actions the indexer records
+// in audit_events. Each type has a fixed severity; Record persists an event
+// with it. Events are listed with GET /v1/admin/audit and counted per type in
+// amn_audit_events_total.
+package audit
+
+import (
+	"context"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// Chain watcher anomalies.
+const (
+	// UnexpectedOnchainCreate: a Created event for a task_hash not
+	// registered on the chain.
+	UnexpectedOnchainCreate = "unexpected_onchain_create"
+	// InvalidTransition: a WorkerSet, Released or Refunded event for a task
+	// another transaction already released or refunded. The event is still
+	// applied; the chain is authoritative.
+	InvalidTransition = "invalid_transition"
+	// ReleasedWithoutDelivery: a task was released onchain with no artifact
+	// referencing it.
+	ReleasedWithoutDelivery = "released_without_delivery"
+	// FeeMismatch: FeePaid disagrees with the fee computed from the task.
+	FeeMismatch = "fee_mismatch"
+	// TokenMismatch: CreatedV2 escrows a different token than the task
+	// was registered with.
+	TokenMismatch = "token_mismatch"
+	// OversizedLog: a log was skipped for exceeding max_log_data_bytes.
+	OversizedLog = "oversized_log_skipped"
+	// WorkerMismatch: WorkerSet names a different worker than the one who
+	// accepted off-chain. Both are kept; the task is not marked
+	// accepted_onchain.
+	WorkerMismatch = "worker_mismatch"
+	// EventParked: an event could not be applied because of DB failures.
+	// Replay it with POST /v1/admin/reprocess-tx.
+	EventParked = "watcher_event_parked"
+)
+
+// Operator actions and recoveries.
+const (
+	WorkerTierChanged      = "worker_tier_changed"
+	MaintenanceModeChanged = "maintenance_mode_changed"
+	ObjectNotesChanged     = "object_notes_changed"
+	ObjectDeleted          = "object_deleted"
+	TaskDeleted            = "task_deleted"
+	// AcceptReverted: an accepted task was never funded within
+	// AMN_UNFUNDED_ACCEPT_TIMEOUT_SECONDS and went back to created.
+	AcceptReverted = "accept_reverted"
+	// TaskStatusRecovered is recorded by store.RecoverStuckTasks, which
+	// cannot import this package.
+	TaskStatusRecovered = "task_status_recovered"
+)
+
+var severities = map[string]string{
+	UnexpectedOnchainCreate: store.AuditSeverityWarn,
+	InvalidTransition:       store.AuditSeverityCritical,
+	ReleasedWithoutDelivery: store.AuditSeverityWarn,
+	FeeMismatch:             store.AuditSeverityWarn,
+	TokenMismatch:           store.AuditSeverityWarn,
+	OversizedLog:            store.AuditSeverityWarn,
+	WorkerMismatch:          store.AuditSeverityCritical,
+	EventParked:             store.AuditSeverityCritical,
+	WorkerTierChanged:       store.AuditSeverityInfo,
+	MaintenanceModeChanged:  store.AuditSeverityWarn,
+	ObjectNotesChanged:      store.AuditSeverityInfo,
+	ObjectDeleted:           store.AuditSeverityWarn,
+	TaskDeleted:             store.AuditSeverityWarn,
+	AcceptReverted:          store.AuditSeverityWarn,
+	TaskStatusRecovered:     store.AuditSeverityWarn,
+}
+
+// Severity returns the severity events of type typ are recorded with.
+// Unknown types are info.
+func Severity(typ string) string {
+	if s, ok := severities[typ]; ok {
+		return s
+	}
+	return store.AuditSeverityInfo
+}
+
+// Sink stores audit events; store.TaskRepo satisfies it.
+type Sink interface {
+	InsertAuditEvent(ctx context.Context, e *store.AuditEvent) error
+}
+
+// Record stores an event of type typ. chainID is nil for events not tied to
+// a chain.
+func Record(ctx context.Context, sink Sink, typ string, chainID *int, detail map[string]any) error {
+	return sink.InsertAuditEvent(ctx, &store.AuditEvent{
+		Type:     typ,
+		Severity: Severity(typ),
+		ChainID:  chainID,
+		Detail:   detail,
+	})
+}
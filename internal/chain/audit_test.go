@@ -0,0 +1,183 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// auditRepo keeps one task, applies onchain updates to it and records audit
+// events.
+type auditRepo struct {
+	store.TaskRepo
+	task      *store.Task
+	delivered bool
+	audits    []*store.AuditEvent
+}
+
+func (r *auditRepo) GetTaskByHash(_ context.Context, _ int, hash string) (*store.Task, error) {
+	if hash != r.task.TaskHash {
+		return nil, store.ErrNotFound
+	}
+	t := *r.task
+	return &t, nil
+}
+
+func (r *auditRepo) UpdateOnchainReleased(_ context.Context, _ int, _, txHash string, _ time.Time) error {
+	r.task.Status, r.task.OnchainTxHash = store.TaskStatusReleased, txHash
+	return nil
+}
+
+func (r *auditRepo) UpdateOnchainRefunded(_ context.Context, _ int, _, txHash string, _ time.Time) error {
+	r.task.Status, r.task.OnchainTxHash = store.TaskStatusRefunded, txHash
+	return nil
+}
+
+// UpdateOnchainWorkerSet mirrors the store: a differing worker is recorded
+// beside the off-chain one and flagged.
+func (r *auditRepo) UpdateOnchainWorkerSet(_ context.Context, _ int, _, worker, txHash string) error {
+	worker = strings.ToLower(worker)
+	r.task.OnchainWorkerAddress, r.task.OnchainTxHash = worker, txHash
+	r.task.WorkerMismatch = r.task.WorkerAddress != "" && r.task.WorkerAddress != worker
+	if !r.task.WorkerMismatch {
+		r.task.WorkerAddress, r.task.Status = worker, store.TaskStatusAcceptedOnchain
+	}
+	return nil
+}
+
+func (r *auditRepo) RecordFee(context.Context, *store.FeeEntry) (bool, error) { return false, nil }
+
+func (r *auditRepo) HasArtifact(context.Context, string) (bool, error) { return r.delivered, nil }
+
+func (r *auditRepo) InsertAuditEvent(_ context.Context, e *store.AuditEvent) error {
+	r.audits = append(r.audits, e)
+	return nil
+}
+
+func TestHandleLog_AuditsAnomaliesOnce(t *testing.T) {
+	taskHash := common.HexToHash("0xaa")
+	newRepo := func() *auditRepo {
+		return &auditRepo{task: &store.Task{
+			TaskID: "audit-task", TaskHash: taskHashFromTopic(taskHash), AmountWei: "1000", IndexerFeeBPS: 20,
+			Status: store.TaskStatusAcceptedOnchain,
+		}}
+	}
+	client := &stubClient{head: 100}
+	ctx := context.Background()
+	event := func(w *Watcher, name string, topic common.Hash, tx string) types.Log {
+		return types.Log{Topics: []common.Hash{w.parsedABI.Events[name].ID, topic}, TxHash: common.HexToHash(tx), BlockNumber: 90}
+	}
+	wantAudits := func(t *testing.T, repo *auditRepo, types ...string) {
+		t.Helper()
+		if len(repo.audits) != len(types) {
+			t.Fatalf("audits = %+v, want types %v", repo.audits, types)
+		}
+		for i, typ := range types {
+			if e := repo.audits[i]; e.Type != typ || e.Severity != audit.Severity(typ) || e.ChainID == nil || *e.ChainID != 11155111 {
+				t.Errorf("audit %d = %+v, want %s", i, e, typ)
+			}
+		}
+	}
+
+	t.Run("unexpected_onchain_create", func(t *testing.T) {
+		repo := newRepo()
+		w := newTestWatcher(t, client, repo)
+		data, _ := w.parsedABI.Events["Created"].Inputs.NonIndexed().Pack(big.NewInt(1000), uint64(1767225600))
+		vLog := event(w, "Created", common.HexToHash("0xbb"), "0x01")
+		vLog.Data = data
+		if _, err := w.handleLog(ctx, client, vLog); err != ErrUnknownTaskHash {
+			t.Fatalf("err = %v, want ErrUnknownTaskHash", err)
+		}
+		wantAudits(t, repo, audit.UnexpectedOnchainCreate)
+	})
+
+	t.Run("released_without_delivery", func(t *testing.T) {
+		repo := newRepo()
+		w := newTestWatcher(t, client, repo)
+		if _, err := w.handleLog(ctx, client, event(w, "Released", taskHash, "0x02")); err != nil {
+			t.Fatal(err)
+		}
+		wantAudits(t, repo, audit.ReleasedWithoutDelivery)
+		// Redelivering the same release is not a new anomaly.
+		if _, err := w.handleLog(ctx, client, event(w, "Released", taskHash, "0x02")); err != nil {
+			t.Fatal(err)
+		}
+		wantAudits(t, repo, audit.ReleasedWithoutDelivery)
+	})
+
+	t.Run("delivered_release", func(t *testing.T) {
+		repo := newRepo()
+		repo.delivered = true
+		w := newTestWatcher(t, client, repo)
+		if _, err := w.handleLog(ctx, client, event(w, "Released", taskHash, "0x03")); err != nil {
+			t.Fatal(err)
+		}
+		wantAudits(t, repo)
+	})
+
+	workerSet := func(w *Watcher, worker common.Address, tx string) types.Log {
+		vLog := event(w, "WorkerSet", taskHash, tx)
+		vLog.Topics = append(vLog.Topics, common.BytesToHash(worker.Bytes()))
+		return vLog
+	}
+	accepter := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+
+	t.Run("worker_set_matches_accept", func(t *testing.T) {
+		repo := newRepo()
+		repo.task.Status, repo.task.WorkerAddress = store.TaskStatusAccepted, strings.ToLower(accepter.Hex())
+		w := newTestWatcher(t, client, repo)
+		if _, err := w.handleLog(ctx, client, workerSet(w, accepter, "0x06")); err != nil {
+			t.Fatal(err)
+		}
+		wantAudits(t, repo)
+		if repo.task.WorkerMismatch || repo.task.Status != store.TaskStatusAcceptedOnchain {
+			t.Errorf("task = %+v, want accepted_onchain without mismatch", repo.task)
+		}
+	})
+
+	t.Run("worker_mismatch", func(t *testing.T) {
+		repo := newRepo()
+		repo.task.Status, repo.task.WorkerAddress = store.TaskStatusAccepted, strings.ToLower(accepter.Hex())
+		w := newTestWatcher(t, client, repo)
+		other := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+		if _, err := w.handleLog(ctx, client, workerSet(w, other, "0x07")); err != nil {
+			t.Fatal(err)
+		}
+		wantAudits(t, repo, audit.WorkerMismatch)
+		if d := repo.audits[0].Detail; d["worker_address"] != strings.ToLower(accepter.Hex()) || d["onchain_worker_address"] != strings.ToLower(other.Hex()) {
+			t.Errorf("detail = %v", d)
+		}
+		if !repo.task.WorkerMismatch || repo.task.WorkerAddress != strings.ToLower(accepter.Hex()) || repo.task.Status != store.TaskStatusAccepted {
+			t.Errorf("task = %+v, want the accepter kept and the mismatch flagged", repo.task)
+		}
+		// Redelivering the same WorkerSet is not a new anomaly.
+		if _, err := w.handleLog(ctx, client, workerSet(w, other, "0x07")); err != nil {
+			t.Fatal(err)
+		}
+		wantAudits(t, repo, audit.WorkerMismatch)
+	})
+
+	t.Run("invalid_transition", func(t *testing.T) {
+		repo := newRepo()
+		repo.delivered = true
+		w := newTestWatcher(t, client, repo)
+		if _, err := w.handleLog(ctx, client, event(w, "Released", taskHash, "0x04")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.handleLog(ctx, client, event(w, "Refunded", taskHash, "0x05")); err != nil {
+			t.Fatal(err)
+		}
+		wantAudits(t, repo, audit.InvalidTransition)
+		if d := repo.audits[0].Detail; d["event"] != "Refunded" || d["status"] != store.TaskStatusReleased || d["prior_tx_hash"] != common.HexToHash("0x04").Hex() {
+			t.Errorf("detail = %v", d)
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// Retry and circuit breaker settings for watcher DB writes.
+const (
+	// dbWriteAttempts is how many times an event handler is run before the
+	// event is parked.
+	dbWriteAttempts = 3
+	// dbBreakerThreshold is the number of consecutive failed attempts after
+	// which log processing pauses until a DB probe succeeds.
+	dbBreakerThreshold = 5
+	// schemaBackoffMax caps the wait between attempts while the schema is
+	// not ready.
+	schemaBackoffMax = time.Minute
+)
+
+// AuditEventParked is the audit event type recorded for events that could not
+// be applied because of DB failures. Replay them with POST /v1/admin/reprocess-tx.
+const AuditEventParked = audit.EventParked
+
+var (
+	dbRetries = metrics.NewCounterVec("amn_watcher_db_retries_total",
+		"Event handler attempts retried after a DB failure.", "chain_id")
+	parkedEvents = metrics.NewCounterVec("amn_watcher_parked_events_total",
+		"Events parked in the audit trail after exhausting DB retries.", "chain_id")
+	duplicateLogs = metrics.NewCounterVec("amn_watcher_duplicate_logs_total",
+		"Logs skipped because they were applied within the dedup window.", "chain_id")
+)
+
+// isDBFailure reports whether an event handler error may be transient. Domain
+// outcomes (unknown task, malformed log, missing row) are not retried.
+func isDBFailure(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, context.Canceled),
+		errors.Is(err, ErrUnknownTaskHash),
+		errors.Is(err, ErrMalformedLog),
+		errors.Is(err, store.ErrNotFound),
+		errors.Is(err, store.ErrConflict):
+		return false
+	}
+	return true
+}
+
+// dbBreaker counts consecutive DB failures across a watcher's handlers.
+type dbBreaker struct {
+	mu       sync.Mutex
+	failures int
+}
+
+// record notes the outcome of one attempt and reports whether the breaker is
+// open afterwards.
+func (b *dbBreaker) record(failed bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if failed {
+		b.failures++
+	} else {
+		b.failures = 0
+	}
+	return b.failures >= dbBreakerThreshold
+}
+
+func (b *dbBreaker) open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= dbBreakerThreshold
+}
+
+// withDBRetry runs apply up to dbWriteAttempts times while it fails with a
+// DB error, backing off exponentially from w.retryBackoff. Missing schema
+// is waited out instead; see applyWhenSchemaReady.
+func (w *Watcher) withDBRetry(ctx context.Context, apply func() error) error {
+	backoff := w.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := w.applyWhenSchemaReady(ctx, apply)
+		failed := isDBFailure(err)
+		w.setDBPaused(w.breaker.record(failed))
+		if !failed || attempt == dbWriteAttempts {
+			return err
+		}
+		dbRetries.Inc(strconv.Itoa(w.chainID))
+		log.Printf("[watcher chain=%d] DB write failed (attempt %d/%d): %v", w.chainID, attempt, dbWriteAttempts, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// applyWhenSchemaReady runs apply and, while it fails because tables or
+// columns are missing (migrations not run yet), runs it again with backoff
+// capped at schemaBackoffMax. The wait is logged once rather than per
+// attempt, and does not count towards the breaker or park the event.
+func (w *Watcher) applyWhenSchemaReady(ctx context.Context, apply func() error) error {
+	err := apply()
+	if !store.IsSchemaNotReady(err) {
+		return err
+	}
+	log.Printf("[watcher chain=%d] database schema not ready: %v — waiting for migrations", w.chainID, err)
+	w.setSchemaWaiting(true)
+	defer w.setSchemaWaiting(false)
+	backoff := w.retryBackoff
+	for store.IsSchemaNotReady(err) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, schemaBackoffMax)
+		err = apply()
+	}
+	log.Printf("[watcher chain=%d] database schema ready — resuming log processing", w.chainID)
+	return err
+}
+
+func (w *Watcher) setSchemaWaiting(waiting bool) {
+	w.updateStatus(func(s *Status) { s.SchemaWaiting = waiting })
+}
+
+// waitForDB blocks while the breaker is open, probing the DB every
+// w.probeInterval. It returns false if ctx is cancelled first.
+func (w *Watcher) waitForDB(ctx context.Context) bool {
+	if !w.breaker.open() {
+		return true
+	}
+	log.Printf("[watcher chain=%d] DB circuit open — pausing log processing", w.chainID)
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(w.probeInterval):
+		}
+		// Any answer from the DB, including "not found", means it is reachable.
+		_, err := w.taskRepo.GetTaskByHash(ctx, w.chainID, taskHashFromTopic([32]byte{}))
+		if err == nil || errors.Is(err, store.ErrNotFound) {
+			w.breaker.record(false)
+			w.setDBPaused(false)
+			log.Printf("[watcher chain=%d] DB probe succeeded — resuming log processing", w.chainID)
+			return true
+		}
+	}
+}
+
+func (w *Watcher) setDBPaused(paused bool) {
+	w.updateStatus(func(s *Status) { s.DBPaused = paused })
+}
+
+// processLog applies a subscribed or polled log, waiting out an open breaker
+// first. A log applied within the dedup window is skipped. Logs without
+// enough confirmations are held back for applyPending, and events that still
+// fail with a DB error are parked instead of dropped.
+func (w *Watcher) processLog(ctx context.Context, client Client, vLog types.Log) {
+	key := logKey{txHash: vLog.TxHash, index: vLog.Index}
+	if vLog.Removed {
+		w.dedup.forget(key)
+		w.pending.remove(key)
+		w.lost.remove(key)
+	} else if w.dedup.seen(key) {
+		duplicateLogs.Inc(strconv.Itoa(w.chainID))
+		return
+	}
+	if !w.waitForDB(ctx) {
+		return
+	}
+	event, err := w.handleLog(ctx, client, vLog)
+	if errors.Is(err, ErrNotConfirmed) {
+		w.deferLog(vLog)
+		return
+	}
+	if event != "" && err == nil {
+		w.dedup.add(key)
+	}
+	if event == "" || !isDBFailure(err) || ctx.Err() != nil {
+		return
+	}
+	w.parkEvent(ctx, event, vLog, err)
+}
+
+// parkEvent records an unapplied event in the audit trail so it can be
+// replayed once the DB is healthy.
+func (w *Watcher) parkEvent(ctx context.Context, event string, vLog types.Log, cause error) {
+	parkedEvents.Inc(strconv.Itoa(w.chainID))
+	chainID := w.chainID
+	err := audit.Record(ctx, w.taskRepo, AuditEventParked, &chainID, map[string]any{
+		"event":        event,
+		"tx_hash":      vLog.TxHash.Hex(),
+		"log_index":    vLog.Index,
+		"block_number": vLog.BlockNumber,
+		"error":        cause.Error(),
+	})
+	if err != nil {
+		log.Printf("[watcher chain=%d] could not park %s tx=%s log=%d: %v — event lost until reprocessed",
+			w.chainID, event, vLog.TxHash.Hex(), vLog.Index, err)
+		return
+	}
+	log.Printf("[watcher chain=%d] parked %s tx=%s log=%d after %d attempts: %v",
+		w.chainID, event, vLog.TxHash.Hex(), vLog.Index, dbWriteAttempts, cause)
+}
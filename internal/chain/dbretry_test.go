@@ -0,0 +1,175 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// flakyRepo fails UpdateOnchainReleased with a connection error for the first
+// failures calls (forever if failures < 0).
+type flakyRepo struct {
+	store.TaskRepo
+	failures int
+	calls    int
+	released int
+	probes   int
+	audits   []*store.AuditEvent
+}
+
+func (r *flakyRepo) UpdateOnchainReleased(context.Context, int, string, string, time.Time) error {
+	r.calls++
+	if r.failures < 0 || r.calls <= r.failures {
+		return errors.New("conn reset by peer")
+	}
+	r.released++
+	return nil
+}
+
+func (r *flakyRepo) GetTaskByHash(context.Context, int, string) (*store.Task, error) {
+	r.probes++
+	return nil, store.ErrNotFound
+}
+
+func (r *flakyRepo) InsertAuditEvent(_ context.Context, e *store.AuditEvent) error {
+	r.audits = append(r.audits, e)
+	return nil
+}
+
+func newFlakyWatcher(t *testing.T, repo store.TaskRepo) (*Watcher, types.Log) {
+	t.Helper()
+	w := newTestWatcher(t, &stubClient{head: 100}, repo)
+	w.retryBackoff = time.Millisecond
+	w.probeInterval = time.Millisecond
+	return w, types.Log{
+		Address:     common.HexToAddress(testContract),
+		Topics:      []common.Hash{w.parsedABI.Events["Released"].ID, common.HexToHash("0xaa")},
+		TxHash:      common.HexToHash("0x01"),
+		BlockNumber: 90,
+	}
+}
+
+func TestProcessLog_RetriesTransientDBFailure(t *testing.T) {
+	repo := &flakyRepo{failures: 2}
+	w, vLog := newFlakyWatcher(t, repo)
+	label := strconv.Itoa(w.chainID)
+	retriesBefore := dbRetries.Value(label)
+
+	w.processLog(context.Background(), &stubClient{head: 100}, vLog)
+
+	if repo.calls != 3 || repo.released != 1 {
+		t.Errorf("calls = %d, released = %d; want 3 attempts, applied once", repo.calls, repo.released)
+	}
+	if n := dbRetries.Value(label) - retriesBefore; n != 2 {
+		t.Errorf("retries metric += %v, want 2", n)
+	}
+	if len(repo.audits) != 0 || w.Status().DBPaused {
+		t.Errorf("audits = %d, paused = %v after recovery", len(repo.audits), w.Status().DBPaused)
+	}
+}
+
+func TestProcessLog_ParksAndTripsBreaker(t *testing.T) {
+	repo := &flakyRepo{failures: -1}
+	w, vLog := newFlakyWatcher(t, repo)
+	client := &stubClient{head: 100}
+	ctx := context.Background()
+
+	w.processLog(ctx, client, vLog)
+	if len(repo.audits) != 1 || repo.audits[0].Type != AuditEventParked {
+		t.Fatalf("expected one parked event, got %+v", repo.audits)
+	}
+	if d := repo.audits[0].Detail; d["event"] != "Released" || d["tx_hash"] != vLog.TxHash.Hex() {
+		t.Errorf("parked detail = %v", d)
+	}
+	if w.Status().DBPaused {
+		t.Fatal("breaker open after 3 failures, threshold is 5")
+	}
+
+	w.processLog(ctx, client, vLog)
+	if !w.Status().DBPaused {
+		t.Fatal("breaker still closed after 6 consecutive failures")
+	}
+
+	// The next log waits for a successful probe before being attempted.
+	repo.failures = 0
+	w.processLog(ctx, client, vLog)
+	if repo.probes == 0 || w.Status().DBPaused || repo.released != 1 {
+		t.Errorf("probes = %d, paused = %v, released = %d", repo.probes, w.Status().DBPaused, repo.released)
+	}
+}
+
+// unmigratedRepo fails UpdateOnchainReleased with an undefined table error
+// until the migrations "run" after missing calls.
+type unmigratedRepo struct {
+	flakyRepo
+	missing int
+}
+
+func (r *unmigratedRepo) UpdateOnchainReleased(context.Context, int, string, string, time.Time) error {
+	r.calls++
+	if r.calls <= r.missing {
+		return fmt.Errorf("update task: %w", &pgconn.PgError{Code: "42P01", Message: `relation "tasks" does not exist`})
+	}
+	r.released++
+	return nil
+}
+
+func TestProcessLog_WaitsForSchema(t *testing.T) {
+	repo := &unmigratedRepo{missing: 4}
+	w, vLog := newFlakyWatcher(t, repo)
+	label := strconv.Itoa(w.chainID)
+	retriesBefore := dbRetries.Value(label)
+
+	w.processLog(context.Background(), &stubClient{head: 100}, vLog)
+
+	if repo.calls != 5 || repo.released != 1 {
+		t.Errorf("calls = %d, released = %d; want applied once the schema appeared", repo.calls, repo.released)
+	}
+	if len(repo.audits) != 0 {
+		t.Errorf("event parked while waiting for the schema: %+v", repo.audits)
+	}
+	if n := dbRetries.Value(label) - retriesBefore; n != 0 {
+		t.Errorf("retries metric += %v, want 0", n)
+	}
+	if s := w.Status(); s.DBPaused || s.SchemaWaiting {
+		t.Errorf("status after schema wait: paused = %v, schema waiting = %v", s.DBPaused, s.SchemaWaiting)
+	}
+}
+
+func TestProcessLog_SchemaWaitStopsOnCancel(t *testing.T) {
+	repo := &unmigratedRepo{missing: 1 << 30}
+	w, vLog := newFlakyWatcher(t, repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.processLog(ctx, &stubClient{head: 100}, vLog)
+		close(done)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for !w.Status().SchemaWaiting {
+		select {
+		case <-deadline:
+			t.Fatal("watcher never reported waiting for the schema")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("processLog did not return after cancel")
+	}
+	if len(repo.audits) != 0 {
+		t.Errorf("event parked on cancel: %+v", repo.audits)
+	}
+}
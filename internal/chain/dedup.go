@@ -0,0 +1,88 @@
+package chain
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Defaults for the recently-applied log window when the chain config does
+// not set log_dedup_size / log_dedup_ttl_seconds.
+const (
+	defaultLogDedupSize = 4096
+	defaultLogDedupTTL  = 60 * time.Second
+)
+
+// logKey identifies a log within one chain; each watcher has its own window.
+type logKey struct {
+	txHash common.Hash
+	index  uint
+}
+
+// logDedup is a bounded LRU of recently applied logs. Providers redeliver a
+// log across the subscription and poll paths, mostly in the gap fill after a
+// reconnect; skipping those saves the DB round trips and duplicate audit and
+// feed events. It is only an optimisation: the handlers stay idempotent.
+type logDedup struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	now   func() time.Time
+	order *list.List // of *dedupEntry, most recent first
+	byKey map[logKey]*list.Element
+}
+
+type dedupEntry struct {
+	key logKey
+	at  time.Time
+}
+
+func newLogDedup(size int, ttl time.Duration) *logDedup {
+	return &logDedup{size: size, ttl: ttl, now: time.Now, order: list.New(), byKey: make(map[logKey]*list.Element)}
+}
+
+// seen reports whether k was applied within the TTL.
+func (d *logDedup) seen(k logKey) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	el, ok := d.byKey[k]
+	if !ok {
+		return false
+	}
+	if d.now().Sub(el.Value.(*dedupEntry).at) >= d.ttl {
+		d.order.Remove(el)
+		delete(d.byKey, k)
+		return false
+	}
+	return true
+}
+
+// add records k as applied now, evicting the least recently added key when
+// the window is full.
+func (d *logDedup) add(k logKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if el, ok := d.byKey[k]; ok {
+		el.Value.(*dedupEntry).at = d.now()
+		d.order.MoveToFront(el)
+		return
+	}
+	d.byKey[k] = d.order.PushFront(&dedupEntry{key: k, at: d.now()})
+	for d.order.Len() > d.size {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.byKey, oldest.Value.(*dedupEntry).key)
+	}
+}
+
+// forget drops k, so a log re-included after a reorg is applied again.
+func (d *logDedup) forget(k logKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if el, ok := d.byKey[k]; ok {
+		d.order.Remove(el)
+		delete(d.byKey, k)
+	}
+}
@@ -0,0 +1,82 @@
+package chain
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProcessLog_SkipsRecentDuplicate(t *testing.T) {
+	repo := &flakyRepo{}
+	w, vLog := newFlakyWatcher(t, repo)
+	client := &stubClient{head: 100}
+	ctx := context.Background()
+	label := strconv.Itoa(w.chainID)
+	dupsBefore := duplicateLogs.Value(label)
+
+	w.processLog(ctx, client, vLog)
+	w.processLog(ctx, client, vLog)
+	if repo.released != 1 {
+		t.Fatalf("released = %d, want the redelivered log skipped", repo.released)
+	}
+	if n := duplicateLogs.Value(label) - dupsBefore; n != 1 {
+		t.Errorf("duplicate metric += %v, want 1", n)
+	}
+
+	// Another log in the same transaction is not a duplicate.
+	other := vLog
+	other.Index = 1
+	w.processLog(ctx, client, other)
+	if repo.released != 2 {
+		t.Errorf("released = %d after a second log index, want 2", repo.released)
+	}
+
+	// A reorg removal clears the key so the re-included log is applied.
+	removed := vLog
+	removed.Removed = true
+	w.processLog(ctx, client, removed)
+	w.processLog(ctx, client, vLog)
+	if repo.released != 3 {
+		t.Errorf("released = %d after reorg re-inclusion, want 3", repo.released)
+	}
+}
+
+func TestProcessLog_FailedLogNotDeduplicated(t *testing.T) {
+	repo := &flakyRepo{failures: 3}
+	w, vLog := newFlakyWatcher(t, repo)
+	client := &stubClient{head: 100}
+	ctx := context.Background()
+
+	w.processLog(ctx, client, vLog) // parked after 3 failed attempts
+	w.processLog(ctx, client, vLog)
+	if repo.released != 1 {
+		t.Errorf("released = %d, want the redelivery of a parked log applied", repo.released)
+	}
+}
+
+func TestLogDedup_TTLAndEviction(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := newLogDedup(2, time.Minute)
+	d.now = func() time.Time { return now }
+	key := func(i uint) logKey { return logKey{txHash: common.HexToHash("0x01"), index: i} }
+
+	d.add(key(0))
+	now = now.Add(59 * time.Second)
+	if !d.seen(key(0)) {
+		t.Error("key expired before its TTL")
+	}
+	now = now.Add(time.Second)
+	if d.seen(key(0)) {
+		t.Error("key still seen after its TTL")
+	}
+
+	d.add(key(1))
+	d.add(key(2))
+	d.add(key(3))
+	if d.seen(key(1)) || !d.seen(key(2)) || !d.seen(key(3)) {
+		t.Error("oldest key not evicted when the window is full")
+	}
+}
@@ -0,0 +1,46 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CodeReader is the subset of *ethclient.Client used to fetch contract code.
+type CodeReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+var (
+	// ErrNoCode is returned by VerifyEscrowCode when the address has no
+	// contract code (an EOA or an undeployed address).
+	ErrNoCode = errors.New("chain: no contract code at escrow address")
+	// ErrCodeHashMismatch is returned by VerifyEscrowCode when the code does
+	// not hash to the expected value.
+	ErrCodeHashMismatch = errors.New("chain: escrow code hash mismatch")
+)
+
+// VerifyEscrowCode checks that addr holds contract code at the latest block
+// and, if expectedCodeHash is non-empty, that keccak256 of the code equals
+// it (0x-prefixed hex, case-insensitive).
+func VerifyEscrowCode(ctx context.Context, client CodeReader, addr common.Address, expectedCodeHash string) error {
+	code, err := client.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return fmt.Errorf("chain: eth_getCode: %w", err)
+	}
+	if len(code) == 0 {
+		return ErrNoCode
+	}
+	if expectedCodeHash == "" {
+		return nil
+	}
+	if got := crypto.Keccak256Hash(code).Hex(); !strings.EqualFold(got, expectedCodeHash) {
+		return fmt.Errorf("%w: got %s, want %s", ErrCodeHashMismatch, got, expectedCodeHash)
+	}
+	return nil
+}
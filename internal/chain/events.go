@@ -0,0 +1,179 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// AuditEventTokenMismatch is recorded when a CreatedV2 event escrows a
+// different token than the task was registered with.
+const AuditEventTokenMismatch = audit.TokenMismatch
+
+// eventHandler applies one version of a settlement event. Versions of the
+// same event (Created, CreatedV2) share Event and differ in Version.
+type eventHandler struct {
+	Name    string // ABI event name in settlementABIJSON
+	Event   string // event the versions have in common
+	Version int
+	apply   func(w *Watcher, ctx context.Context, vLog types.Log) error
+}
+
+// eventHandlers are the settlement events the watcher applies. Supporting a
+// new event version takes its ABI entry in settlementABIJSON and a row here.
+var eventHandlers = []eventHandler{
+	{Name: "Created", Event: "Created", Version: 1, apply: (*Watcher).onCreated},
+	{Name: "CreatedV2", Event: "Created", Version: 2, apply: (*Watcher).onCreatedV2},
+	{Name: "WorkerSet", Event: "WorkerSet", Version: 1, apply: (*Watcher).onWorkerSet},
+	{Name: "Released", Event: "Released", Version: 1, apply: (*Watcher).onReleased},
+	{Name: "Refunded", Event: "Refunded", Version: 1, apply: (*Watcher).onRefunded},
+	{Name: "FeePaid", Event: "FeePaid", Version: 1, apply: (*Watcher).onFeePaid},
+}
+
+// newDispatch indexes eventHandlers by the topic hash of their ABI event.
+func newDispatch(parsed abi.ABI) (map[common.Hash]eventHandler, error) {
+	dispatch := make(map[common.Hash]eventHandler, len(eventHandlers))
+	for _, h := range eventHandlers {
+		ev, ok := parsed.Events[h.Name]
+		if !ok {
+			return nil, fmt.Errorf("settlement ABI has no event %s", h.Name)
+		}
+		dispatch[ev.ID] = h
+	}
+	return dispatch, nil
+}
+
+// onCreatedV2 applies CreatedV2, which adds the escrowed token (the zero
+// address for native currency) to Created.
+func (w *Watcher) onCreatedV2(ctx context.Context, vLog types.Log) error {
+	values, err := w.parsedABI.Unpack("CreatedV2", vLog.Data)
+	if err != nil || len(values) != 3 {
+		return ErrMalformedLog
+	}
+	terms, ok := createdTerms(values)
+	if !ok {
+		return ErrMalformedLog
+	}
+	token, ok := values[2].(common.Address)
+	if !ok {
+		return ErrMalformedLog
+	}
+	return w.applyCreated(ctx, vLog, terms, &token)
+}
+
+// checkCreatedToken audits a CreatedV2 token that differs from the task's
+// token_address. The event is still applied: the escrow exists either way.
+func (w *Watcher) checkCreatedToken(ctx context.Context, task *store.Task, token common.Address, txHash string) {
+	eventToken := ""
+	if token != (common.Address{}) {
+		eventToken = strings.ToLower(token.Hex())
+	}
+	if eventToken == task.TokenAddress {
+		return
+	}
+	log.Printf("[watcher chain=%d] CreatedV2 for taskID=%s escrows token %q, task has %q",
+		w.chainID, task.TaskID, eventToken, task.TokenAddress)
+	w.audit(ctx, AuditEventTokenMismatch, map[string]any{
+		"task_id":     task.TaskID,
+		"tx_hash":     txHash,
+		"event_token": eventToken,
+		"task_token":  task.TokenAddress,
+	})
+}
+
+// auditTransition loads the task a WorkerSet, Released or Refunded log
+// refers to before it is applied, and records invalid_transition if another
+// transaction already released or refunded it. It returns the task, or nil
+// if it is unknown or could not be read.
+func (w *Watcher) auditTransition(ctx context.Context, event, taskHash, txHash string) *store.Task {
+	task, err := w.taskByHash(ctx, taskHash)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[watcher chain=%d] %s transition check: %v", w.chainID, event, err)
+		}
+		return nil
+	}
+	terminal := task.Status == store.TaskStatusReleased || task.Status == store.TaskStatusRefunded
+	if terminal && !strings.EqualFold(task.OnchainTxHash, txHash) {
+		log.Printf("[watcher chain=%d] %s for taskID=%s already %s by tx=%s",
+			w.chainID, event, task.TaskID, task.Status, task.OnchainTxHash)
+		w.audit(ctx, audit.InvalidTransition, map[string]any{
+			"task_id":       task.TaskID,
+			"tx_hash":       txHash,
+			"event":         event,
+			"status":        task.Status,
+			"prior_tx_hash": task.OnchainTxHash,
+		})
+	}
+	return task
+}
+
+// auditDelivery records released_without_delivery if no artifact references
+// the released task.
+func (w *Watcher) auditDelivery(ctx context.Context, task *store.Task, txHash string) {
+	delivered, err := w.taskRepo.HasArtifact(ctx, task.TaskID)
+	if err != nil {
+		log.Printf("[watcher chain=%d] delivery check for taskID=%s: %v", w.chainID, task.TaskID, err)
+		return
+	}
+	if !delivered {
+		w.audit(ctx, audit.ReleasedWithoutDelivery, map[string]any{
+			"task_id":        task.TaskID,
+			"tx_hash":        txHash,
+			"worker_address": task.WorkerAddress,
+		})
+	}
+}
+
+// auditWorkerMismatch records worker_mismatch when WorkerSet named a worker
+// other than the task's off-chain accepter. A replay of an already recorded
+// mismatch is not recorded again.
+func (w *Watcher) auditWorkerMismatch(ctx context.Context, task *store.Task, workerAddr, txHash string) {
+	if task.WorkerAddress == "" || strings.EqualFold(task.WorkerAddress, workerAddr) {
+		return
+	}
+	if task.WorkerMismatch && strings.EqualFold(task.OnchainWorkerAddress, workerAddr) {
+		return
+	}
+	log.Printf("[watcher chain=%d] WorkerSet for taskID=%s names %s, accepted off-chain by %s",
+		w.chainID, task.TaskID, workerAddr, task.WorkerAddress)
+	w.audit(ctx, audit.WorkerMismatch, map[string]any{
+		"task_id":                task.TaskID,
+		"tx_hash":                txHash,
+		"worker_address":         task.WorkerAddress,
+		"onchain_worker_address": strings.ToLower(workerAddr),
+	})
+}
+
+// recordUnknownLog keeps a contract log whose topic matches no registered
+// event. Failures are logged; they never hold up the watcher.
+func (w *Watcher) recordUnknownLog(ctx context.Context, vLog types.Log) {
+	topics := make([]string, len(vLog.Topics))
+	for i, t := range vLog.Topics {
+		topics[i] = t.Hex()
+	}
+	log.Printf("[watcher chain=%d] unknown event topic=%s tx=%s index=%d — recorded for analysis",
+		w.chainID, topics[0], vLog.TxHash.Hex(), vLog.Index)
+	err := w.taskRepo.RecordUnknownLog(ctx, &store.UnknownLog{
+		ChainID:     w.chainID,
+		Address:     vLog.Address.Hex(),
+		TxHash:      vLog.TxHash.Hex(),
+		LogIndex:    vLog.Index,
+		BlockNumber: vLog.BlockNumber,
+		Topics:      topics,
+		Data:        "0x" + hex.EncodeToString(vLog.Data),
+	})
+	if err != nil {
+		log.Printf("[watcher chain=%d] RecordUnknownLog error: %v", w.chainID, err)
+	}
+}
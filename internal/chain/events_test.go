@@ -0,0 +1,173 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// createdRepo keeps one task and records Created updates, audits and
+// unknown logs. A soft-deleted task is only found under store.WithDeleted.
+type createdRepo struct {
+	store.TaskRepo
+	task    *store.Task
+	created []string // tx hashes
+	terms   []*store.OnchainTerms
+	audits  []*store.AuditEvent
+	unknown []*store.UnknownLog
+}
+
+func (r *createdRepo) GetTaskByHash(ctx context.Context, _ int, hash string) (*store.Task, error) {
+	if hash != r.task.TaskHash || (r.task.DeletedAt != nil && !store.IncludesDeleted(ctx)) {
+		return nil, store.ErrNotFound
+	}
+	return r.task, nil
+}
+
+func (r *createdRepo) UpdateOnchainCreated(_ context.Context, _, txHash string, terms *store.OnchainTerms, _ time.Time) error {
+	r.created = append(r.created, txHash)
+	r.terms = append(r.terms, terms)
+	return nil
+}
+
+func (r *createdRepo) InsertAuditEvent(_ context.Context, e *store.AuditEvent) error {
+	r.audits = append(r.audits, e)
+	return nil
+}
+
+func (r *createdRepo) RecordUnknownLog(_ context.Context, l *store.UnknownLog) error {
+	r.unknown = append(r.unknown, l)
+	return nil
+}
+
+func TestHandleLog_CreatedVersions(t *testing.T) {
+	const token = "0x00000000000000000000000000000000000000c0"
+	taskHash := common.HexToHash("0xaa")
+	repo := &createdRepo{task: &store.Task{TaskID: "v-task", TaskHash: taskHashFromTopic(taskHash), TokenAddress: token}}
+	client := &stubClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+	ctx := context.Background()
+	employer := common.BytesToHash(common.HexToAddress("0xe1").Bytes())
+
+	v1Data, err := w.parsedABI.Events["Created"].Inputs.NonIndexed().Pack(big.NewInt(1000), uint64(1767225600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2 := func(tx string, tok common.Address) types.Log {
+		data, err := w.parsedABI.Events["CreatedV2"].Inputs.NonIndexed().Pack(big.NewInt(1000), uint64(1767225600), tok)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.Log{
+			Topics: []common.Hash{w.parsedABI.Events["CreatedV2"].ID, taskHash, employer},
+			Data:   data, TxHash: common.HexToHash(tx), BlockNumber: 90,
+		}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		log   types.Log
+		event string
+	}{
+		{"v1", types.Log{
+			Topics: []common.Hash{w.parsedABI.Events["Created"].ID, taskHash, employer},
+			Data:   v1Data, TxHash: common.HexToHash("0x01"), BlockNumber: 90,
+		}, "Created"},
+		{"v2", v2("0x02", common.HexToAddress(token)), "CreatedV2"},
+	} {
+		if event, err := w.handleLog(ctx, client, tc.log); err != nil || event != tc.event {
+			t.Errorf("%s: event %q, err %v; want %s", tc.name, event, err, tc.event)
+		}
+	}
+	if len(repo.created) != 2 || len(repo.audits) != 0 {
+		t.Fatalf("created %v, audits %+v", repo.created, repo.audits)
+	}
+	for i, terms := range repo.terms {
+		if terms == nil || *terms != (store.OnchainTerms{AmountWei: "1000", DeadlineUnix: 1767225600}) {
+			t.Errorf("created[%d] terms = %+v, want the event's amount and deadline", i, terms)
+		}
+	}
+
+	// A CreatedV2 escrowing native currency for an ERC-20 task is applied
+	// and audited.
+	if _, err := w.handleLog(ctx, client, v2("0x03", common.Address{})); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.created) != 3 || len(repo.audits) != 1 || repo.audits[0].Type != AuditEventTokenMismatch ||
+		repo.audits[0].Detail["event_token"] != "" || repo.audits[0].Detail["task_token"] != token {
+		t.Errorf("mismatch: created %v, audits %+v", repo.created, repo.audits)
+	}
+
+	short := v2("0x04", common.Address{})
+	short.Data = short.Data[:64]
+	if _, err := w.handleLog(ctx, client, short); err != ErrMalformedLog {
+		t.Errorf("truncated CreatedV2: err %v, want ErrMalformedLog", err)
+	}
+}
+
+func TestHandleLog_CreatedForSoftDeletedTask(t *testing.T) {
+	taskHash := common.HexToHash("0xaa")
+	deleted := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &createdRepo{task: &store.Task{TaskID: "gone", TaskHash: taskHashFromTopic(taskHash), DeletedAt: &deleted}}
+	client := &stubClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+
+	data, err := w.parsedABI.Events["Created"].Inputs.NonIndexed().Pack(big.NewInt(1000), uint64(1767225600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vLog := types.Log{
+		Topics: []common.Hash{w.parsedABI.Events["Created"].ID, taskHash, common.HexToHash("0xe1")},
+		Data:   data, TxHash: common.HexToHash("0x01"), BlockNumber: 90,
+	}
+	if _, err := w.handleLog(context.Background(), client, vLog); err != nil {
+		t.Fatalf("handleLog: %v", err)
+	}
+	if len(repo.created) != 1 || len(repo.unknown) != 0 || len(repo.audits) != 0 {
+		t.Errorf("created %v, unknown %+v, audits %+v; want the soft-deleted task funded", repo.created, repo.unknown, repo.audits)
+	}
+}
+
+func TestHandleLog_RecordsUnknownTopics(t *testing.T) {
+	repo := &createdRepo{task: &store.Task{}}
+	client := &stubClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+	topic := common.HexToHash("0xfeed")
+
+	vLog := types.Log{
+		Address: common.HexToAddress(testContract),
+		Topics:  []common.Hash{topic, common.HexToHash("0xaa")},
+		Data:    []byte{0xde, 0xad}, TxHash: common.HexToHash("0x05"), Index: 3, BlockNumber: 90,
+	}
+	if event, err := w.handleLog(context.Background(), client, vLog); err != nil || event != "" {
+		t.Fatalf("unknown topic: event %q, err %v", event, err)
+	}
+	if len(repo.unknown) != 1 {
+		t.Fatalf("recorded %d unknown logs, want 1", len(repo.unknown))
+	}
+	got := repo.unknown[0]
+	if got.ChainID != 11155111 || got.Topics[0] != topic.Hex() || len(got.Topics) != 2 ||
+		got.Data != "0xdead" || got.LogIndex != 3 || got.TxHash != vLog.TxHash.Hex() {
+		t.Errorf("unknown log = %+v", got)
+	}
+}
+
+func TestNewDispatch_EveryHandlerInABI(t *testing.T) {
+	w := newTestWatcher(t, &stubClient{}, &createdRepo{})
+	if len(w.dispatch) != len(eventHandlers) {
+		t.Fatalf("dispatch has %d topics, want %d", len(w.dispatch), len(eventHandlers))
+	}
+	versions := map[string][]int{}
+	for _, h := range w.dispatch {
+		versions[h.Event] = append(versions[h.Event], h.Version)
+	}
+	if len(versions["Created"]) != 2 {
+		t.Errorf("Created versions = %v, want 1 and 2", versions["Created"])
+	}
+}
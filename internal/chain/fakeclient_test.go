@@ -0,0 +1,199 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// fakeClient is a Client over a synthetic chain: the test appends logs and
+// moves the head, and FilterLogs answers from them. Unless live is set,
+// subscriptions are not supported and runOnce falls back to polling; with it,
+// emitted logs are also delivered to the subscriber.
+type fakeClient struct {
+	mu      sync.Mutex
+	live    bool
+	head    uint64
+	logs    []types.Log
+	queries [][2]uint64 // FilterLogs ranges, inclusive
+	sub     *fakeSub
+}
+
+// emit appends vLog and delivers it to the subscriber, blocking while the
+// subscription channel is full.
+func (c *fakeClient) emit(vLog types.Log) {
+	c.mu.Lock()
+	c.logs = append(c.logs, vLog)
+	sub := c.sub
+	c.mu.Unlock()
+	if sub != nil {
+		sub.ch <- vLog
+	}
+}
+
+// subscribed reports whether a subscription is open.
+func (c *fakeClient) subscribed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sub != nil
+}
+
+func (c *fakeClient) setHead(head uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.head = head
+}
+
+func (c *fakeClient) BlockNumber(context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head, nil
+}
+
+func (c *fakeClient) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	from, to := uint64(0), c.head
+	if q.FromBlock != nil {
+		from = q.FromBlock.Uint64()
+	}
+	if q.ToBlock != nil {
+		to = q.ToBlock.Uint64()
+	}
+	c.queries = append(c.queries, [2]uint64{from, to})
+	var out []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber >= from && l.BlockNumber <= to {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (c *fakeClient) SubscribeFilterLogs(_ context.Context, _ ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.live {
+		return nil, errors.New("subscriptions not supported")
+	}
+	c.sub = &fakeSub{ch: ch, err: make(chan error)}
+	return c.sub, nil
+}
+
+type fakeSub struct {
+	ch  chan<- types.Log
+	err chan error
+}
+
+func (s *fakeSub) Unsubscribe()      {}
+func (s *fakeSub) Err() <-chan error { return s.err }
+
+// TransactionReceipt answers with the emitted logs of txHash.
+func (c *fakeClient) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var r types.Receipt
+	for _, l := range c.logs {
+		if l.TxHash == txHash {
+			r.Logs = append(r.Logs, &l)
+		}
+	}
+	if len(r.Logs) == 0 {
+		return nil, ethereum.NotFound
+	}
+	return &r, nil
+}
+
+func (c *fakeClient) Close() {}
+
+// chainRepo holds one task and records the onchain writes the watcher makes,
+// as "<event> <task hash>". Safe for use from the watcher goroutine.
+type chainRepo struct {
+	store.TaskRepo
+	mu    sync.Mutex
+	task  store.Task
+	calls []string
+}
+
+func (r *chainRepo) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *chainRepo) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func (r *chainRepo) GetTaskByHash(_ context.Context, _ int, hash string) (*store.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hash != r.task.TaskHash {
+		return nil, store.ErrNotFound
+	}
+	t := r.task
+	return &t, nil
+}
+
+func (r *chainRepo) UpdateOnchainCreated(_ context.Context, _, _ string, _ *store.OnchainTerms, _ time.Time) error {
+	r.record("created " + r.task.TaskHash)
+	return nil
+}
+
+func (r *chainRepo) UpdateOnchainWorkerSet(_ context.Context, _ int, taskHash, _, _ string) error {
+	r.record("worker_set " + taskHash)
+	return nil
+}
+
+func (r *chainRepo) UpdateOnchainReleased(_ context.Context, _ int, taskHash, _ string, _ time.Time) error {
+	r.record("released " + taskHash)
+	return nil
+}
+
+func (r *chainRepo) UpdateOnchainRefunded(_ context.Context, _ int, taskHash, _ string, _ time.Time) error {
+	r.record("refunded " + taskHash)
+	return nil
+}
+
+func (r *chainRepo) RecordFee(_ context.Context, e *store.FeeEntry) (bool, error) {
+	r.record("fee " + e.TaskID)
+	return false, nil
+}
+
+func (r *chainRepo) HasArtifact(context.Context, string) (bool, error) { return true, nil }
+
+func (r *chainRepo) InsertAuditEvent(_ context.Context, e *store.AuditEvent) error {
+	r.record("audit " + e.Type)
+	return nil
+}
+
+func (r *chainRepo) RecordUnknownLog(_ context.Context, l *store.UnknownLog) error {
+	r.record("unknown " + l.TxHash)
+	return nil
+}
+
+// settlementLog builds a log of the named settlement event from the test
+// contract, with topics after the event ID and ABI-packed data values.
+func settlementLog(w *Watcher, event string, block uint64, tx string, topics []common.Hash, data ...any) types.Log {
+	ev := w.parsedABI.Events[event]
+	packed, err := ev.Inputs.NonIndexed().Pack(data...)
+	if err != nil {
+		panic(err)
+	}
+	return types.Log{
+		Address:     common.HexToAddress(testContract),
+		Topics:      append([]common.Hash{ev.ID}, topics...),
+		Data:        packed,
+		BlockNumber: block,
+		TxHash:      common.HexToHash(tx),
+	}
+}
@@ -0,0 +1,101 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// AuditEventFeeMismatch is recorded when a FeePaid amount differs from the
+// fee computed from the task.
+const AuditEventFeeMismatch = audit.FeeMismatch
+
+var bpsDenominator = big.NewInt(10000)
+
+// IndexerFee returns amountWei * feeBPS / 10000, rounded down.
+func IndexerFee(amountWei string, feeBPS int) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(amountWei, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount_wei %q", amountWei)
+	}
+	if feeBPS < 0 {
+		return nil, fmt.Errorf("invalid indexer_fee_bps %d", feeBPS)
+	}
+	fee := amount.Mul(amount, big.NewInt(int64(feeBPS)))
+	return fee.Quo(fee, bpsDenominator), nil
+}
+
+// recordFee writes the fee ledger row for taskHash. eventFee is the FeePaid
+// amount, or nil when called for Released. Tasks this indexer does not know
+// are skipped.
+func (w *Watcher) recordFee(ctx context.Context, taskHash, txHash string, at time.Time, eventFee *big.Int) error {
+	task, err := w.taskByHash(ctx, taskHash)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	computed, err := IndexerFee(task.AmountWei, task.IndexerFeeBPS)
+	if err != nil {
+		log.Printf("[watcher chain=%d] fee for taskID=%s not recorded: %v", w.chainID, task.TaskID, err)
+		return nil
+	}
+	e := &store.FeeEntry{
+		TaskID:         task.TaskID,
+		ChainID:        w.chainID,
+		ComputedFeeWei: computed.String(),
+		ReleasedAt:     at,
+		TxHash:         txHash,
+	}
+	if eventFee != nil {
+		e.EventFeeWei = eventFee.String()
+	}
+	mismatch, err := w.taskRepo.RecordFee(ctx, e)
+	if err != nil {
+		log.Printf("[watcher chain=%d] RecordFee error: %v", w.chainID, err)
+		return err
+	}
+	if mismatch && eventFee != nil {
+		log.Printf("[watcher chain=%d] FeePaid for taskID=%s is %s wei, computed %s wei",
+			w.chainID, task.TaskID, e.EventFeeWei, e.ComputedFeeWei)
+		w.audit(ctx, AuditEventFeeMismatch, map[string]any{
+			"task_id":          task.TaskID,
+			"tx_hash":          txHash,
+			"event_fee_wei":    e.EventFeeWei,
+			"computed_fee_wei": e.ComputedFeeWei,
+		})
+	}
+	return nil
+}
+
+func (w *Watcher) onFeePaid(ctx context.Context, vLog types.Log) error {
+	if len(vLog.Topics) < 2 {
+		return ErrMalformedLog
+	}
+	values, err := w.parsedABI.Unpack("FeePaid", vLog.Data)
+	if err != nil || len(values) != 1 {
+		return ErrMalformedLog
+	}
+	amount, ok := values[0].(*big.Int)
+	if !ok {
+		return ErrMalformedLog
+	}
+	taskHash := taskHashFromTopic(vLog.Topics[1])
+	txHash := vLog.TxHash.Hex()
+
+	if err := w.recordFee(ctx, taskHash, txHash, time.Now(), amount); err != nil {
+		return err
+	}
+	w.markEvent(vLog.BlockNumber)
+	log.Printf("[watcher chain=%d] FeePaid: taskHash=%s amount=%s tx=%s", w.chainID, taskHash, amount, txHash)
+	return nil
+}
@@ -0,0 +1,117 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestIndexerFee_Rounding(t *testing.T) {
+	for _, tc := range []struct {
+		amount string
+		bps    int
+		want   string
+	}{
+		{"1", 20, "0"},   // 1-wei task: 0.002 wei rounds down to 0
+		{"499", 20, "0"}, // 0.998 wei
+		{"500", 20, "1"}, // exactly 1 wei
+		{"999", 20, "1"}, // 1.998 wei
+		{"1000000000000000000", 20, "2000000000000000"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 10000,
+			"115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{"12345", 0, "0"},
+	} {
+		got, err := IndexerFee(tc.amount, tc.bps)
+		if err != nil {
+			t.Fatalf("IndexerFee(%s, %d): %v", tc.amount, tc.bps, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("IndexerFee(%s, %d) = %s, want %s", tc.amount, tc.bps, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "1e18", "-5"} {
+		if _, err := IndexerFee(bad, 20); err == nil {
+			t.Errorf("IndexerFee(%q) succeeded", bad)
+		}
+	}
+}
+
+// feeRepo keeps one task and its ledger row in memory.
+type feeRepo struct {
+	store.TaskRepo
+	task   *store.Task
+	entry  *store.FeeEntry
+	audits []*store.AuditEvent
+}
+
+func (r *feeRepo) UpdateOnchainReleased(context.Context, int, string, string, time.Time) error {
+	return nil
+}
+
+func (r *feeRepo) GetTaskByHash(_ context.Context, _ int, hash string) (*store.Task, error) {
+	if hash != r.task.TaskHash {
+		return nil, store.ErrNotFound
+	}
+	return r.task, nil
+}
+
+func (r *feeRepo) HasArtifact(context.Context, string) (bool, error) { return true, nil }
+
+func (r *feeRepo) RecordFee(_ context.Context, e *store.FeeEntry) (bool, error) {
+	if r.entry != nil && e.EventFeeWei == "" {
+		e.EventFeeWei = r.entry.EventFeeWei
+	}
+	r.entry = e
+	return e.EventFeeWei != "" && e.EventFeeWei != e.ComputedFeeWei, nil
+}
+
+func (r *feeRepo) InsertAuditEvent(_ context.Context, e *store.AuditEvent) error {
+	r.audits = append(r.audits, e)
+	return nil
+}
+
+func TestWatcher_RecordsFees(t *testing.T) {
+	taskHash := common.HexToHash("0xaa")
+	repo := &feeRepo{task: &store.Task{
+		TaskID: "fee-task", TaskHash: taskHashFromTopic(taskHash), AmountWei: "1000000", IndexerFeeBPS: 20,
+	}}
+	client := &stubClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+	ctx := context.Background()
+
+	released := types.Log{Topics: []common.Hash{w.parsedABI.Events["Released"].ID, taskHash}, BlockNumber: 90}
+	if _, err := w.handleLog(ctx, client, released); err != nil {
+		t.Fatal(err)
+	}
+	if repo.entry == nil || repo.entry.ComputedFeeWei != "2000" || repo.entry.EventFeeWei != "" {
+		t.Fatalf("after Released: entry %+v", repo.entry)
+	}
+
+	feePaid := func(amount int64) types.Log {
+		data, err := w.parsedABI.Events["FeePaid"].Inputs.NonIndexed().Pack(big.NewInt(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.Log{Topics: []common.Hash{w.parsedABI.Events["FeePaid"].ID, taskHash}, Data: data, BlockNumber: 90}
+	}
+	if event, err := w.handleLog(ctx, client, feePaid(2000)); err != nil || event != "FeePaid" {
+		t.Fatalf("FeePaid: event %q, err %v", event, err)
+	}
+	if repo.entry.EventFeeWei != "2000" || len(repo.audits) != 0 {
+		t.Errorf("matching FeePaid: entry %+v, audits %d", repo.entry, len(repo.audits))
+	}
+
+	if _, err := w.handleLog(ctx, client, feePaid(1999)); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.audits) != 1 || repo.audits[0].Type != AuditEventFeeMismatch ||
+		repo.audits[0].Detail["event_fee_wei"] != "1999" || repo.audits[0].Detail["computed_fee_wei"] != "2000" {
+		t.Errorf("mismatched FeePaid: audits %+v", repo.audits)
+	}
+}
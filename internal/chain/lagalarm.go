@@ -0,0 +1,88 @@
+package chain
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Events a watcher raises about itself rather than about a task. The
+// indexer. prefix keeps them apart from task events wherever both are
+// delivered.
+const (
+	EventChainLagging   = "indexer.chain_lagging"
+	EventChainRecovered = "indexer.chain_recovered"
+)
+
+// LagAlarm is an EventChainLagging or EventChainRecovered raised by a
+// watcher whose lag crossed its chain's lag_alarm_blocks or
+// lag_alarm_clear_blocks.
+type LagAlarm struct {
+	Event       string
+	ChainID     int
+	HeadBlock   uint64
+	SyncedBlock uint64
+	LagBlocks   uint64
+	// ThresholdBlocks is the threshold that was crossed: lag_alarm_blocks
+	// for chain_lagging, the clear threshold for chain_recovered.
+	ThresholdBlocks int
+	// Since is when the chain started lagging. It is the same on a
+	// chain_lagging and the chain_recovered that ends it.
+	Since time.Time
+	At    time.Time
+}
+
+// ID returns the stable id of a: indexer:chain_id:event:since, with since
+// in Unix nanoseconds. Each lagging episode has one id per event.
+func (a LagAlarm) ID() string {
+	return fmt.Sprintf("indexer:%d:%s:%d", a.ChainID, a.Event, a.Since.UnixNano())
+}
+
+// LagHook is called with each LagAlarm a watcher raises. Hooks run on the
+// goroutine that updated the watcher status and must not block.
+type LagHook func(a LagAlarm)
+
+// lagAlarm is the hysteresis state of a watcher's lag alarm: it trips when
+// the lag exceeds trip and clears once it is back to clear or less, so a lag
+// hovering around one threshold raises one event, not one per update.
+type lagAlarm struct {
+	trip, clear int // trip == 0 disables the alarm
+	lagging     bool
+	since       time.Time
+}
+
+// observe returns the alarm raised by moving to status s at now, if any.
+func (l *lagAlarm) observe(s Status, now time.Time) (LagAlarm, bool) {
+	if l.trip <= 0 {
+		return LagAlarm{}, false
+	}
+	lag := s.BlockLag()
+	a := LagAlarm{ChainID: s.ChainID, HeadBlock: s.HeadBlock, SyncedBlock: s.SyncedBlock, LagBlocks: lag, At: now}
+	switch {
+	case !l.lagging && lag > uint64(l.trip):
+		l.lagging, l.since = true, now
+		a.Event, a.ThresholdBlocks = EventChainLagging, l.trip
+	case l.lagging && lag <= uint64(l.clear):
+		l.lagging = false
+		a.Event, a.ThresholdBlocks = EventChainRecovered, l.clear
+	default:
+		return LagAlarm{}, false
+	}
+	a.Since = l.since
+	return a, true
+}
+
+// OnLagAlarm registers h to run for every LagAlarm the watcher raises.
+func (w *Watcher) OnLagAlarm(h LagHook) {
+	w.mu.Lock()
+	w.lagHooks = append(w.lagHooks, h)
+	w.mu.Unlock()
+}
+
+// fireLagAlarm logs a and runs the lag hooks, outside w.mu.
+func (w *Watcher) fireLagAlarm(a LagAlarm, hooks []LagHook) {
+	log.Printf("[watcher chain=%d] %s: lag %d blocks (threshold %d)", a.ChainID, a.Event, a.LagBlocks, a.ThresholdBlocks)
+	for _, h := range hooks {
+		h(a)
+	}
+}
@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+func TestLagAlarm_Hysteresis(t *testing.T) {
+	l := lagAlarm{trip: 10, clear: 3}
+	t0 := time.Unix(1700000000, 0)
+
+	steps := []struct {
+		head, synced uint64
+		want         string // event raised, if any
+	}{
+		{100, 95, ""},
+		{100, 89, EventChainLagging}, // lag 11 > 10
+		{105, 95, ""},                // lag 10: still lagging, not raised again
+		{120, 100, ""},               // lag 20
+		{110, 106, ""},               // lag 4: below trip, above clear
+		{110, 107, EventChainRecovered},
+		{111, 101, ""}, // lag 10: back up to the trip, not over it
+		{120, 108, EventChainLagging},
+	}
+	var raised []LagAlarm
+	for i, st := range steps {
+		a, ok := l.observe(Status{ChainID: 1, HeadBlock: st.head, SyncedBlock: st.synced}, t0.Add(time.Duration(i)*time.Minute))
+		if got := a.Event; got != st.want || ok != (st.want != "") {
+			t.Fatalf("step %d (lag %d): raised %q (%v), want %q", i, st.head-st.synced, got, ok, st.want)
+		}
+		if ok {
+			raised = append(raised, a)
+		}
+	}
+
+	lagging, recovered, again := raised[0], raised[1], raised[2]
+	if lagging.LagBlocks != 11 || lagging.ThresholdBlocks != 10 || recovered.LagBlocks != 3 || recovered.ThresholdBlocks != 3 {
+		t.Errorf("alarms = %+v", raised)
+	}
+	if !lagging.Since.Equal(t0.Add(time.Minute)) || !recovered.Since.Equal(lagging.Since) || !recovered.At.Equal(t0.Add(5*time.Minute)) {
+		t.Errorf("episode times: lagging %+v, recovered %+v", lagging, recovered)
+	}
+	if lagging.ID() != "indexer:1:indexer.chain_lagging:1700000060000000000" {
+		t.Errorf("ID = %q", lagging.ID())
+	}
+	if again.ID() == lagging.ID() || !again.Since.Equal(again.At) {
+		t.Errorf("second episode %+v reuses the first's id", again)
+	}
+}
+
+func TestLagAlarm_Disabled(t *testing.T) {
+	l := lagAlarm{}
+	if a, ok := l.observe(Status{HeadBlock: 1_000_000}, time.Now()); ok {
+		t.Errorf("disabled alarm raised %+v", a)
+	}
+}
+
+func TestWatcher_RaisesLagAlarms(t *testing.T) {
+	w, err := NewWatcher("stub://", config.ChainConfig{
+		ChainID:            11155111,
+		SettlementContract: testContract,
+		MinConfirmations:   2,
+		LagAlarmBlocks:     50,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	w.OnLagAlarm(func(a LagAlarm) { events = append(events, a.Event) })
+
+	// Poll mode reports the head and the confirmed block together.
+	w.setHead(1000, true)
+	w.updateStatus(func(s *Status) { s.HeadBlock = 1100 })
+	if !w.Status().Lagging {
+		t.Error("Status().Lagging = false while lagging")
+	}
+	w.updateStatus(func(s *Status) { s.HeadBlock = 1120 })
+	w.markEvent(1090) // lag 30: above the default clear threshold (25)
+	w.markEvent(1100)
+	if w.Status().Lagging {
+		t.Error("Status().Lagging = true after recovery")
+	}
+
+	want := []string{EventChainLagging, EventChainRecovered}
+	if len(events) != len(want) || events[0] != want[0] || events[1] != want[1] {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
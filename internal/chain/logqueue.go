@@ -0,0 +1,157 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+// Defaults for the subscription buffers when the chain config does not set
+// log_buffer_size / log_queue_size.
+const (
+	defaultLogBufferSize = 64
+	defaultLogQueueSize  = 10000
+)
+
+var (
+	subscriptionErrors = metrics.NewCounterVec("amn_watcher_subscription_errors_total",
+		"Log subscriptions ended by an error, by cause.", "chain_id", "cause")
+	droppedLogs = metrics.NewCounterVec("amn_watcher_dropped_logs_total",
+		"Subscribed logs dropped because the log queue was full.", "chain_id")
+)
+
+// logQueue sits between the subscription channel and log processing. The
+// receive loop only moves logs from the channel into it, so slow DB writes
+// do not back up into go-ethereum's subscription buffer, which ends the
+// subscription with a queue overflow once full. It holds at most limit logs.
+type logQueue struct {
+	mu    sync.Mutex
+	items []types.Log
+	limit int
+	ready chan struct{} // signalled when items becomes non-empty
+}
+
+func newLogQueue(limit int) *logQueue {
+	return &logQueue{limit: limit, ready: make(chan struct{}, 1)}
+}
+
+// push appends vLog, or returns false if the queue is full.
+func (q *logQueue) push(vLog types.Log) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) >= q.limit {
+		return false
+	}
+	q.items = append(q.items, vLog)
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pop removes the oldest log.
+func (q *logQueue) pop() (types.Log, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return types.Log{}, false
+	}
+	vLog := q.items[0]
+	q.items[0] = types.Log{}
+	q.items = q.items[1:]
+	if len(q.items) == 0 {
+		q.items = nil // release the backing array after a burst
+	}
+	return vLog, true
+}
+
+func (q *logQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// minBlock returns the lowest block of a queued log.
+func (q *logQueue) minBlock() (uint64, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var lowest uint64
+	for i, l := range q.items {
+		if i == 0 || l.BlockNumber < lowest {
+			lowest = l.BlockNumber
+		}
+	}
+	return lowest, len(q.items) > 0
+}
+
+// enqueueLog hands a subscribed log to consumeLogs, dropping it if the queue
+// is full. A dropped log is lost until its transaction is reprocessed.
+func (w *Watcher) enqueueLog(q *logQueue, vLog types.Log) {
+	if q.push(vLog) {
+		return
+	}
+	droppedLogs.Inc(strconv.Itoa(w.chainID))
+	w.lost.add(vLog)
+	w.updateStatus(func(s *Status) { s.LostLogs = w.lost.len() })
+	log.Printf("[watcher chain=%d] log queue full (%d) — dropping tx=%s log=%d; replay with POST /v1/admin/reprocess-tx",
+		w.chainID, q.limit, vLog.TxHash.Hex(), vLog.Index)
+}
+
+// consumeLogs applies queued logs in arrival order and refreshes the head
+// every w.headInterval, until ctx is cancelled or the head cannot be read.
+func (w *Watcher) consumeLogs(ctx context.Context, client Client, q *logQueue) error {
+	headTicker := time.NewTicker(w.headInterval)
+	defer headTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-headTicker.C:
+			head, err := client.BlockNumber(ctx)
+			if err != nil {
+				return err
+			}
+			w.setHead(head, true)
+			w.applyPending(ctx, client, head)
+		case <-q.ready:
+			for ctx.Err() == nil {
+				vLog, ok := q.pop()
+				if !ok {
+					break
+				}
+				w.processLog(ctx, client, vLog)
+				w.applyPending(ctx, client, max(w.Status().HeadBlock, vLog.BlockNumber))
+			}
+		}
+	}
+}
+
+// subscriptionErrorCause classifies the error that ended a subscription for
+// amn_watcher_subscription_errors_total.
+func subscriptionErrorCause(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, rpc.ErrSubscriptionQueueOverflow):
+		return "queue_overflow"
+	case errors.Is(err, rpc.ErrClientQuit):
+		return "client_closed"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr),
+		err != nil && err.Error() == "connection lost":
+		return "connection_lost"
+	default:
+		return "other"
+	}
+}
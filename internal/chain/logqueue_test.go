@@ -0,0 +1,215 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// gatedRepo blocks refunds until gate is closed and records them by tx hash.
+type gatedRepo struct {
+	*chainRepo
+	gate    chan struct{}
+	waiting atomic.Int32 // refunds blocked on gate
+}
+
+func (r *gatedRepo) UpdateOnchainRefunded(_ context.Context, _ int, _, txHash string, _ time.Time) error {
+	r.waiting.Add(1)
+	<-r.gate
+	r.record(txHash)
+	return nil
+}
+
+// startSubscribed runs w against a live fake client until the test ends.
+func startSubscribed(t *testing.T, w *Watcher, client *fakeClient) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.runOnce(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("runOnce: %v", err)
+		}
+	})
+	for deadline := time.Now().Add(2 * time.Second); !client.subscribed(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the subscription")
+		}
+	}
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestSubscription_SlowRepoDoesNotBackPressure(t *testing.T) {
+	const n = 3000
+	taskHash := common.HexToHash("0xee")
+	repo := &gatedRepo{chainRepo: &chainRepo{}, gate: make(chan struct{})}
+	client := &fakeClient{live: true, head: 100}
+	w := newTestWatcher(t, client, repo)
+	w.logBufferSize = 4
+	w.headInterval = time.Hour
+	startSubscribed(t, w, client)
+
+	// With the repo stalled, a receive loop that applied logs itself would
+	// stop reading after a few logs and block the subscription.
+	emitted := make(chan struct{})
+	go func() {
+		defer close(emitted)
+		for i := range n {
+			client.emit(settlementLog(w, "Refunded", 50, fmt.Sprintf("0x%x", i+1), []common.Hash{taskHash}))
+		}
+	}()
+	select {
+	case <-emitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription blocked behind a slow repo")
+	}
+	waitUntil(t, "the queue to fill", func() bool { return w.Status().LogQueueDepth >= n-1 })
+
+	close(repo.gate)
+	waitUntil(t, "every log applied", func() bool { return len(repo.recorded()) == n })
+	for i, tx := range repo.recorded() {
+		if want := common.HexToHash(fmt.Sprintf("0x%x", i+1)).Hex(); tx != want {
+			t.Fatalf("log %d applied tx %s, want %s: out of order", i, tx, want)
+		}
+	}
+	if s := w.Status(); s.LogQueueDepth != 0 || s.LogChannelDepth != 0 {
+		t.Errorf("depths after draining: %+v", s)
+	}
+}
+
+func TestSubscription_FullQueueDropsAndCounts(t *testing.T) {
+	taskHash := common.HexToHash("0xef")
+	repo := &gatedRepo{chainRepo: &chainRepo{}, gate: make(chan struct{})}
+	client := &fakeClient{live: true, head: 100}
+	w := newTestWatcher(t, client, repo)
+	w.logBufferSize, w.logQueueSize = 4, 10
+	w.headInterval = time.Hour
+	label := strconv.Itoa(w.chainID)
+	before := droppedLogs.Value(label)
+	startSubscribed(t, w, client)
+
+	// The first log is taken off the queue and stalls in the repo; ten more
+	// fill the queue and the other nine are dropped.
+	emit := func(i int) {
+		client.emit(settlementLog(w, "Refunded", 50, fmt.Sprintf("0x%x", i+1), []common.Hash{taskHash}))
+	}
+	emit(0)
+	waitUntil(t, "the first log to stall", func() bool { return repo.waiting.Load() == 1 })
+	for i := 1; i < 20; i++ {
+		emit(i)
+	}
+	waitUntil(t, "the channel to drain", func() bool {
+		s := w.Status()
+		return s.LogChannelDepth == 0 && droppedLogs.Value(label)-before+float64(s.LogQueueDepth) == 19
+	})
+	if n := droppedLogs.Value(label) - before; n != 9 {
+		t.Errorf("dropped %v logs, want 9", n)
+	}
+	close(repo.gate)
+	waitUntil(t, "the queued logs", func() bool { return len(repo.recorded()) == 11 })
+}
+
+func TestSubscription_SyncedBlockFollowsAppliedLogs(t *testing.T) {
+	taskHash := common.HexToHash("0xef")
+	repo := &gatedRepo{chainRepo: &chainRepo{}, gate: make(chan struct{})}
+	client := &fakeClient{live: true, head: 100}
+	w := newTestWatcher(t, client, repo)
+	w.logBufferSize, w.logQueueSize = 4, 1
+	w.headInterval = time.Millisecond
+	startSubscribed(t, w, client)
+	release := sync.OnceFunc(func() { close(repo.gate) })
+	t.Cleanup(release)
+	waitUntil(t, "the confirmed head", func() bool { return w.Status().SyncedBlock == 98 })
+
+	// The log at block 90 stalls in the repo, the one at 95 waits in the
+	// queue and the one at 96 is dropped: a head refresh puts the synced
+	// block below the queued one.
+	client.emit(settlementLog(w, "Refunded", 90, "0x1", []common.Hash{taskHash}))
+	waitUntil(t, "the first log to stall", func() bool { return repo.waiting.Load() == 1 })
+	client.emit(settlementLog(w, "Refunded", 95, "0x2", []common.Hash{taskHash}))
+	client.emit(settlementLog(w, "Refunded", 96, "0x3", []common.Hash{taskHash}))
+	waitUntil(t, "the dropped log", func() bool { s := w.Status(); return s.LostLogs == 1 && s.LogQueueDepth == 1 })
+	w.setHead(100, true)
+	if s := w.Status(); s.SyncedBlock != 94 {
+		t.Errorf("synced block %d with a log queued at 95, want 94", s.SyncedBlock)
+	}
+
+	// Once the queue drains the dropped log still holds it back.
+	release()
+	waitUntil(t, "the queued logs", func() bool { return len(repo.recorded()) == 2 })
+	waitUntil(t, "synced block 95", func() bool { return w.Status().SyncedBlock == 95 })
+	time.Sleep(20 * time.Millisecond)
+	if s := w.Status(); s.SyncedBlock != 95 || s.LostLogs != 1 {
+		t.Fatalf("status %+v before reprocessing, want synced block 95 and one lost log", s)
+	}
+
+	// Reprocessing its transaction releases it.
+	if _, err := w.ReprocessTx(context.Background(), common.HexToHash("0x3")); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "the confirmed head", func() bool { s := w.Status(); return s.LostLogs == 0 && s.SyncedBlock == 98 })
+	if calls := repo.recorded(); len(calls) != 3 {
+		t.Errorf("repo calls %v, want three refunds", calls)
+	}
+}
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestSubscriptionErrorCause(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{rpc.ErrSubscriptionQueueOverflow, "queue_overflow"},
+		{rpc.ErrClientQuit, "client_closed"},
+		{fmt.Errorf("read: %w", timeoutErr{}), "timeout"},
+		{context.DeadlineExceeded, "timeout"},
+		{io.EOF, "connection_lost"},
+		{errors.New("connection lost"), "connection_lost"},
+		{errors.New("something else"), "other"},
+	} {
+		if got := subscriptionErrorCause(tc.err); got != tc.want {
+			t.Errorf("%v: cause %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRunOnce_CountsSubscriptionErrors(t *testing.T) {
+	client := &fakeClient{live: true, head: 100}
+	w := newTestWatcher(t, client, &chainRepo{})
+	label := strconv.Itoa(w.chainID)
+	before := subscriptionErrors.Value(label, "queue_overflow")
+	done := make(chan error, 1)
+	go func() { done <- w.runOnce(context.Background()) }()
+	waitUntil(t, "the subscription", client.subscribed)
+
+	client.sub.err <- rpc.ErrSubscriptionQueueOverflow
+	if err := <-done; !errors.Is(err, rpc.ErrSubscriptionQueueOverflow) {
+		t.Errorf("runOnce = %v, want the subscription error", err)
+	}
+	if n := subscriptionErrors.Value(label, "queue_overflow") - before; n != 1 {
+		t.Errorf("queue_overflow errors += %v, want 1", n)
+	}
+}
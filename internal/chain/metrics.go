@@ -0,0 +1,71 @@
+package chain
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+// RegisterMetrics exposes liveness gauges for the given watchers in the
+// default metrics registry.
+func RegisterMetrics(watchers []*Watcher) {
+	gauge := func(name, help string, value func(s Status) (float64, bool)) {
+		metrics.Register(metrics.GaugeFunc{Name: name, Help: help, Fn: func() []metrics.Sample {
+			out := make([]metrics.Sample, 0, len(watchers))
+			for _, w := range watchers {
+				s := w.Status()
+				v, ok := value(s)
+				if !ok {
+					continue
+				}
+				out = append(out, metrics.Sample{
+					Labels: map[string]string{"chain_id": strconv.Itoa(s.ChainID)},
+					Value:  v,
+				})
+			}
+			return out
+		}})
+	}
+
+	gauge("amn_watcher_connected", "1 if the chain watcher is connected to its RPC endpoint.",
+		func(s Status) (float64, bool) {
+			if s.Connected {
+				return 1, true
+			}
+			return 0, true
+		})
+	gauge("amn_watcher_head_block", "Latest chain head block seen by the watcher.",
+		func(s Status) (float64, bool) { return float64(s.HeadBlock), true })
+	gauge("amn_watcher_block_lag", "Blocks between the chain head and the last processed block.",
+		func(s Status) (float64, bool) { return float64(s.BlockLag()), true })
+	gauge("amn_watcher_db_paused", "1 while log processing is paused by the DB circuit breaker.",
+		func(s Status) (float64, bool) {
+			if s.DBPaused {
+				return 1, true
+			}
+			return 0, true
+		})
+	gauge("amn_watcher_lagging", "1 while the chain's lag alarm is raised (lag_alarm_blocks).",
+		func(s Status) (float64, bool) {
+			if s.Lagging {
+				return 1, true
+			}
+			return 0, true
+		})
+	gauge("amn_watcher_pending_logs", "Subscribed logs held back until they have min_confirmations.",
+		func(s Status) (float64, bool) { return float64(s.PendingLogs), true })
+	gauge("amn_watcher_lost_logs", "Subscribed logs dropped before they were applied and not yet reprocessed.",
+		func(s Status) (float64, bool) { return float64(s.LostLogs), true })
+	gauge("amn_watcher_log_channel_depth", "Subscribed logs waiting in the subscription channel.",
+		func(s Status) (float64, bool) { return float64(s.LogChannelDepth), true })
+	gauge("amn_watcher_log_queue_depth", "Subscribed logs received but not yet applied.",
+		func(s Status) (float64, bool) { return float64(s.LogQueueDepth), true })
+	gauge("amn_watcher_seconds_since_last_event", "Seconds since a settlement event was last applied.",
+		func(s Status) (float64, bool) {
+			if s.LastEventAt.IsZero() {
+				return 0, false
+			}
+			return time.Since(s.LastEventAt).Seconds(), true
+		})
+}
@@ -0,0 +1,171 @@
+package chain
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxPendingLogs bounds the logs held back for confirmations. Settlement
+// events arrive a handful per block, so hitting it means the head has not
+// moved for a long time.
+const maxPendingLogs = 4096
+
+// pendingLogs holds subscribed logs that arrived before they had
+// minConfirmations on top. The subscription delivers each log once, as soon
+// as it is mined, so a log that fails the confirmation check there has to be
+// kept and re-evaluated as the head advances. It lives on the Watcher so a
+// reconnect does not lose it.
+type pendingLogs struct {
+	mu    sync.Mutex
+	byKey map[logKey]types.Log
+}
+
+func newPendingLogs() *pendingLogs {
+	return &pendingLogs{byKey: make(map[logKey]types.Log)}
+}
+
+// add holds vLog, replacing a redelivered copy. It returns false if the
+// buffer is full.
+func (p *pendingLogs) add(vLog types.Log) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := logKey{txHash: vLog.TxHash, index: vLog.Index}
+	if _, ok := p.byKey[k]; !ok && len(p.byKey) >= maxPendingLogs {
+		return false
+	}
+	p.byKey[k] = vLog
+	return true
+}
+
+// remove drops k, for a log the chain reorganised away before it was applied.
+func (p *pendingLogs) remove(k logKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.byKey, k)
+}
+
+// due removes and returns the logs with minConfirmations on top at head, in
+// chain order.
+func (p *pendingLogs) due(head uint64, minConfirmations int) []types.Log {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []types.Log
+	for k, l := range p.byKey {
+		if l.BlockNumber+uint64(minConfirmations) <= head {
+			out = append(out, l)
+			delete(p.byKey, k)
+		}
+	}
+	slices.SortFunc(out, func(a, b types.Log) int {
+		return cmp.Or(cmp.Compare(a.BlockNumber, b.BlockNumber), cmp.Compare(a.Index, b.Index))
+	})
+	return out
+}
+
+func (p *pendingLogs) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.byKey)
+}
+
+// minBlock returns the lowest block of a held log.
+func (p *pendingLogs) minBlock() (uint64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var lowest uint64
+	found := false
+	for _, l := range p.byKey {
+		if !found || l.BlockNumber < lowest {
+			lowest, found = l.BlockNumber, true
+		}
+	}
+	return lowest, found
+}
+
+// lostLogs remembers subscribed logs that were dropped before they were
+// applied, because the log queue or the pending buffer was full. The synced
+// block stays below the earliest of them until ReprocessTx replays its
+// transaction.
+type lostLogs struct {
+	mu    sync.Mutex
+	byKey map[logKey]uint64 // block number
+}
+
+func newLostLogs() *lostLogs {
+	return &lostLogs{byKey: make(map[logKey]uint64)}
+}
+
+func (l *lostLogs) add(vLog types.Log) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.byKey[logKey{txHash: vLog.TxHash, index: vLog.Index}] = vLog.BlockNumber
+}
+
+// remove drops k, for a log the chain reorganised away.
+func (l *lostLogs) remove(k logKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.byKey, k)
+}
+
+// forgetTx drops every log of txHash, once it has been reprocessed.
+func (l *lostLogs) forgetTx(txHash common.Hash) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k := range l.byKey {
+		if k.txHash == txHash {
+			delete(l.byKey, k)
+		}
+	}
+}
+
+func (l *lostLogs) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.byKey)
+}
+
+// minBlock returns the lowest block of a lost log.
+func (l *lostLogs) minBlock() (uint64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var lowest uint64
+	found := false
+	for _, b := range l.byKey {
+		if !found || b < lowest {
+			lowest, found = b, true
+		}
+	}
+	return lowest, found
+}
+
+// deferLog holds back a log that failed the confirmation check.
+func (w *Watcher) deferLog(vLog types.Log) {
+	if !w.pending.add(vLog) {
+		log.Printf("[watcher chain=%d] pending log buffer full (%d) — dropping tx=%s log=%d until reprocessed",
+			w.chainID, maxPendingLogs, vLog.TxHash.Hex(), vLog.Index)
+		w.lost.add(vLog)
+	}
+	w.updateStatus(func(s *Status) {
+		s.PendingLogs = w.pending.len()
+		s.LostLogs = w.lost.len()
+	})
+}
+
+// applyPending processes the held-back logs that are confirmed at head. A log
+// the RPC still reports as unconfirmed goes back into the buffer.
+func (w *Watcher) applyPending(ctx context.Context, client Client, head uint64) {
+	if w.minConfirmations == 0 {
+		return
+	}
+	for _, vLog := range w.pending.due(head, w.minConfirmations) {
+		w.processLog(ctx, client, vLog)
+	}
+	w.updateStatus(func(s *Status) { s.PendingLogs = w.pending.len() })
+}
@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestPendingLogs_DueInChainOrder(t *testing.T) {
+	p := newPendingLogs()
+	for _, l := range []types.Log{
+		{BlockNumber: 12, Index: 0, TxHash: common.HexToHash("0x03")},
+		{BlockNumber: 10, Index: 4, TxHash: common.HexToHash("0x02")},
+		{BlockNumber: 10, Index: 1, TxHash: common.HexToHash("0x01")},
+		{BlockNumber: 10, Index: 1, TxHash: common.HexToHash("0x01")}, // redelivered
+	} {
+		p.add(l)
+	}
+	if got := p.due(11, 2); len(got) != 0 {
+		t.Errorf("due at 11 = %v, want none", got)
+	}
+	got := p.due(13, 2)
+	if len(got) != 2 || got[0].Index != 1 || got[1].Index != 4 || p.len() != 1 {
+		t.Errorf("due at 13 = %+v, %d left", got, p.len())
+	}
+}
+
+func TestPendingLogs_Bounded(t *testing.T) {
+	p := newPendingLogs()
+	for i := range maxPendingLogs {
+		if !p.add(types.Log{BlockNumber: 1, Index: uint(i)}) {
+			t.Fatalf("add %d refused", i)
+		}
+	}
+	if p.add(types.Log{BlockNumber: 1, Index: maxPendingLogs}) {
+		t.Error("add past the bound accepted")
+	}
+	if !p.add(types.Log{BlockNumber: 1, Index: 0}) {
+		t.Error("redelivery of a held log refused")
+	}
+}
+
+func TestProcessLog_HoldsUnconfirmed(t *testing.T) {
+	taskHash := common.HexToHash("0xdd")
+	repo := &chainRepo{task: store.Task{TaskID: "h-task", TaskHash: taskHashFromTopic(taskHash)}}
+	client := &fakeClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+	ctx := context.Background()
+
+	w.processLog(ctx, client, settlementLog(w, "Refunded", 100, "0x01", []common.Hash{taskHash}))
+	if w.pending.len() != 1 || len(repo.recorded()) != 0 {
+		t.Fatalf("unconfirmed log: %d held, repo calls %v", w.pending.len(), repo.recorded())
+	}
+	w.applyPending(ctx, client, 101)
+	if w.pending.len() != 1 {
+		t.Fatal("applied a log one block short of min_confirmations")
+	}
+	client.setHead(102)
+	w.applyPending(ctx, client, 102)
+	if calls := repo.recorded(); w.pending.len() != 0 || len(calls) != 1 || calls[0] != "refunded "+repo.task.TaskHash {
+		t.Errorf("after confirmation: %d held, repo calls %v", w.pending.len(), calls)
+	}
+}
@@ -0,0 +1,61 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrTxNotFound is returned by ReprocessTx when the RPC has no receipt for the
+// transaction.
+var ErrTxNotFound = errors.New("transaction receipt not found")
+
+// ReprocessedLog describes the outcome of re-applying one contract log.
+type ReprocessedLog struct {
+	LogIndex uint   `json:"log_index"`
+	Event    string `json:"event,omitempty"`
+	Applied  bool   `json:"applied"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ReprocessTx fetches the receipt for txHash and runs every log emitted by the
+// settlement contract through the normal event handling path. It is used to
+// repair state when a provider dropped a log, and releases the synced block
+// if the watcher itself dropped one of the transaction's logs.
+func (w *Watcher) ReprocessTx(ctx context.Context, txHash common.Hash) ([]ReprocessedLog, error) {
+	client, err := w.dial(ctx, w.rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+	defer client.Close()
+
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTxNotFound
+		}
+		return nil, fmt.Errorf("get receipt: %w", err)
+	}
+
+	log.Printf("[watcher chain=%d] reprocessing tx=%s (%d logs)", w.chainID, txHash.Hex(), len(receipt.Logs))
+
+	out := []ReprocessedLog{}
+	for _, l := range receipt.Logs {
+		if l == nil || l.Address != w.contractAddr {
+			continue
+		}
+		event, err := w.handleLog(ctx, client, *l)
+		r := ReprocessedLog{LogIndex: l.Index, Event: event, Applied: event != "" && err == nil}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		out = append(out, r)
+	}
+	w.lost.forgetTx(txHash)
+	w.updateStatus(func(s *Status) { s.LostLogs = w.lost.len() })
+	return out, nil
+}
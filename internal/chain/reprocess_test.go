@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// stubClient serves a fixed head and receipt set.
+type stubClient struct {
+	head     uint64
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (c *stubClient) BlockNumber(context.Context) (uint64, error) { return c.head, nil }
+func (c *stubClient) FilterLogs(context.Context, ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+func (c *stubClient) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+func (c *stubClient) TransactionReceipt(_ context.Context, h common.Hash) (*types.Receipt, error) {
+	if r, ok := c.receipts[h]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+func (c *stubClient) Close() {}
+
+// recordingRepo records onchain updates; other TaskRepo methods are unused.
+type recordingRepo struct {
+	store.TaskRepo
+	released []string
+	sources  []store.EventSource
+}
+
+func (r *recordingRepo) UpdateOnchainReleased(ctx context.Context, _ int, taskHash, _ string, _ time.Time) error {
+	r.released = append(r.released, taskHash)
+	src, _ := store.EventSourceFrom(ctx)
+	r.sources = append(r.sources, src)
+	return nil
+}
+
+func (r *recordingRepo) GetTaskByHash(context.Context, int, string) (*store.Task, error) {
+	return nil, store.ErrNotFound
+}
+
+const testContract = "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"
+
+func newTestWatcher(t *testing.T, client Client, repo store.TaskRepo) *Watcher {
+	t.Helper()
+	w, err := NewWatcher("stub://", config.ChainConfig{
+		ChainID:            11155111,
+		SettlementContract: testContract,
+		MinConfirmations:   2,
+	}, repo)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	w.dial = func(context.Context, string) (Client, error) { return client, nil }
+	return w
+}
+
+func TestReprocessTx_AppliesContractLogs(t *testing.T) {
+	repo := &recordingRepo{}
+	client := &stubClient{head: 100, receipts: map[common.Hash]*types.Receipt{}}
+	w := newTestWatcher(t, client, repo)
+
+	txHash := common.HexToHash("0x01")
+	taskHash := common.HexToHash("0xaa")
+	client.receipts[txHash] = &types.Receipt{Logs: []*types.Log{
+		{ // Released from the settlement contract
+			Address:     common.HexToAddress(testContract),
+			Topics:      []common.Hash{w.parsedABI.Events["Released"].ID, taskHash},
+			TxHash:      txHash,
+			BlockNumber: 90,
+			Index:       0,
+		},
+		{ // Unrelated contract — ignored
+			Address:     common.HexToAddress("0x0000000000000000000000000000000000000001"),
+			Topics:      []common.Hash{w.parsedABI.Events["Released"].ID, taskHash},
+			BlockNumber: 90,
+			Index:       1,
+		},
+		{ // Too recent for min_confirmations=2
+			Address:     common.HexToAddress(testContract),
+			Topics:      []common.Hash{w.parsedABI.Events["Released"].ID, taskHash},
+			BlockNumber: 99,
+			Index:       2,
+		},
+	}}
+
+	got, err := w.ReprocessTx(context.Background(), txHash)
+	if err != nil {
+		t.Fatalf("ReprocessTx: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 contract logs, got %d: %+v", len(got), got)
+	}
+	if !got[0].Applied || got[0].Event != "Released" {
+		t.Errorf("log 0: expected applied Released, got %+v", got[0])
+	}
+	if got[1].Applied || got[1].Error == "" {
+		t.Errorf("log 2: expected unconfirmed, got %+v", got[1])
+	}
+	if len(repo.released) != 1 || repo.released[0] != taskHashFromTopic(taskHash) {
+		t.Errorf("unexpected repo updates: %v", repo.released)
+	}
+	// The write carries its log, so hooks see the same event id on every
+	// reprocess.
+	if want := (store.EventSource{ChainID: 11155111, TxHash: txHash.Hex(), LogIndex: 0}); len(repo.sources) != 1 || repo.sources[0] != want {
+		t.Errorf("event sources = %+v, want %+v", repo.sources, want)
+	}
+}
+
+func TestReprocessTx_UnknownTx(t *testing.T) {
+	client := &stubClient{head: 100, receipts: map[common.Hash]*types.Receipt{}}
+	w := newTestWatcher(t, client, &recordingRepo{})
+
+	if _, err := w.ReprocessTx(context.Background(), common.HexToHash("0x02")); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("expected ErrTxNotFound, got %v", err)
+	}
+}
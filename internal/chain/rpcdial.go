@@ -0,0 +1,71 @@
+package chain
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+// DialRPC connects to a chain's RPC endpoint, applying the chain's TLS
+// settings (see config.ChainConfig.RPCTLSConfig) and auth headers to both
+// HTTP and WebSocket transports. Without them it is ethclient.DialContext.
+func DialRPC(ctx context.Context, rpcURL string, ch config.ChainConfig) (*ethclient.Client, error) {
+	dialURL, opts, err := rpcDialOptions(rpcURL, ch)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts) == 0 {
+		return ethclient.DialContext(ctx, rpcURL)
+	}
+	client, err := rpc.DialOptions(ctx, dialURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// rpcDialOptions returns the URL to dial and the client options for ch.
+// Userinfo is moved out of the URL into an Authorization header, so
+// credentials never appear in URL-bearing errors or logs.
+func rpcDialOptions(rpcURL string, ch config.ChainConfig) (string, []rpc.ClientOption, error) {
+	var opts []rpc.ClientOption
+	tlsCfg, err := ch.RPCTLSConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	if tlsCfg != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg
+		wsDialer := *websocket.DefaultDialer
+		wsDialer.TLSClientConfig = tlsCfg
+		opts = append(opts, rpc.WithHTTPClient(&http.Client{Transport: transport}), rpc.WithWebsocketDialer(wsDialer))
+	}
+
+	headers := make(http.Header)
+	for name, value := range ch.RPCHeaders {
+		headers.Set(name, value)
+	}
+	user, pass := ch.RPCBasicAuthUser, ch.RPCBasicAuthPassword
+	if u, err := url.Parse(rpcURL); err == nil && u.User != nil {
+		if user == "" {
+			user = u.User.Username()
+			pass, _ = u.User.Password()
+		}
+		u.User = nil
+		rpcURL = u.String()
+	}
+	if user != "" {
+		headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+pass)))
+	}
+	if len(headers) > 0 {
+		opts = append(opts, rpc.WithHeaders(headers))
+	}
+	return rpcURL, opts, nil
+}
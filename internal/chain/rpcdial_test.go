@@ -0,0 +1,117 @@
+package chain
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+func TestDialRPC_TLSSettings(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x2a"}`))
+	}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		chain  config.ChainConfig
+		wantOK bool
+	}{
+		{"system_roots", config.ChainConfig{}, false},
+		{"custom_ca", config.ChainConfig{RPCCAFile: caFile}, true},
+		{"insecure", config.ChainConfig{RPCInsecureSkipVerify: true}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, err := DialRPC(ctx, srv.URL, tc.chain)
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer client.Close()
+			n, err := client.BlockNumber(ctx)
+			if tc.wantOK && (err != nil || n != 42) {
+				t.Errorf("BlockNumber = %d, %v; want 42", n, err)
+			}
+			if !tc.wantOK && err == nil {
+				t.Error("BlockNumber succeeded against an untrusted certificate")
+			}
+		})
+	}
+}
+
+func TestDialRPC_AuthHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer srv.Close()
+	withUserinfo := "http://alice:s3cret@" + strings.TrimPrefix(srv.URL, "http://")
+
+	for _, tc := range []struct {
+		name       string
+		url        string
+		chain      config.ChainConfig
+		wantHeader string
+		wantValue  string
+	}{
+		{"api_key_header", srv.URL, config.ChainConfig{RPCHeaders: map[string]string{"X-Api-Key": "k-123"}}, "X-Api-Key", "k-123"},
+		{"basic_auth", srv.URL, config.ChainConfig{RPCBasicAuthUser: "bob", RPCBasicAuthPassword: "pw"}, "Authorization", "Basic Ym9iOnB3"},
+		{"url_userinfo", withUserinfo, config.ChainConfig{}, "Authorization", "Basic YWxpY2U6czNjcmV0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			ctx := context.Background()
+			client, err := DialRPC(ctx, tc.url, tc.chain)
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer client.Close()
+			if _, err := client.BlockNumber(ctx); err != nil {
+				t.Fatalf("BlockNumber: %v", err)
+			}
+			if v := got.Get(tc.wantHeader); v != tc.wantValue {
+				t.Errorf("%s = %q, want %q", tc.wantHeader, v, tc.wantValue)
+			}
+		})
+	}
+}
+
+func TestDialRPC_UserinfoNotInErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() // nothing listens: every call fails
+
+	ctx := context.Background()
+	client, err := DialRPC(ctx, "http://alice:s3cret@"+addr, config.ChainConfig{})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	_, err = client.BlockNumber(ctx)
+	if err == nil {
+		t.Fatal("BlockNumber succeeded with no server")
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("error leaks the password: %v", err)
+	}
+}
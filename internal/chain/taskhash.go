@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// getTaskHashABIJSON is the settlement contract's task hash view function.
+const getTaskHashABIJSON = `[
+  {
+    "inputs": [{"name": "taskID", "type": "string"}],
+    "name": "getTaskHash",
+    "outputs": [{"name": "", "type": "bytes32"}],
+    "stateMutability": "pure",
+    "type": "function"
+  }
+]`
+
+var getTaskHashABI = mustParseABI(getTaskHashABIJSON)
+
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// ErrNoContractResult is returned by VerifyTaskHashOnChain when the call
+// returns no data, typically because there is no contract at the address or
+// it does not implement getTaskHash.
+var ErrNoContractResult = errors.New("chain: getTaskHash returned no data")
+
+// VerifyTaskHashOnChain calls the settlement contract's
+// getTaskHash(string) view function at the latest block and returns the
+// result as a lower-case 0x-prefixed hex string. client is usually an
+// *ethclient.Client.
+func VerifyTaskHashOnChain(ctx context.Context, client ethereum.ContractCaller, contractAddr common.Address, taskID string) (string, error) {
+	input, err := getTaskHashABI.Pack("getTaskHash", taskID)
+	if err != nil {
+		return "", fmt.Errorf("chain: pack getTaskHash: %w", err)
+	}
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &contractAddr, Data: input}, nil)
+	if err != nil {
+		return "", fmt.Errorf("chain: call getTaskHash: %w", err)
+	}
+	if len(out) == 0 {
+		return "", ErrNoContractResult
+	}
+	values, err := getTaskHashABI.Unpack("getTaskHash", out)
+	if err != nil {
+		return "", fmt.Errorf("chain: unpack getTaskHash: %w", err)
+	}
+	hash, ok := values[0].([32]byte)
+	if !ok {
+		return "", fmt.Errorf("chain: unexpected getTaskHash result type %T", values[0])
+	}
+	return strings.ToLower(common.Hash(hash).Hex()), nil
+}
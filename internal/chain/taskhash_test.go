@@ -0,0 +1,59 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// keccakCaller emulates a contract whose getTaskHash returns keccak256(taskID).
+type keccakCaller struct {
+	gotTo common.Address
+	out   []byte // overrides the computed result when non-nil
+}
+
+func (c *keccakCaller) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	c.gotTo = *msg.To
+	if c.out != nil {
+		return c.out, nil
+	}
+	if !bytes.Equal(msg.Data[:4], getTaskHashABI.Methods["getTaskHash"].ID) {
+		return nil, errors.New("unexpected selector")
+	}
+	args, err := getTaskHashABI.Methods["getTaskHash"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256([]byte(args[0].(string))), nil
+}
+
+func TestVerifyTaskHashOnChain(t *testing.T) {
+	contract := common.HexToAddress(testContract)
+	caller := &keccakCaller{}
+
+	got, err := VerifyTaskHashOnChain(context.Background(), caller, contract, "task-001")
+	if err != nil {
+		t.Fatalf("VerifyTaskHashOnChain: %v", err)
+	}
+	want := "0x" + common.Bytes2Hex(crypto.Keccak256([]byte("task-001")))
+	if got != want {
+		t.Errorf("hash = %s, want %s", got, want)
+	}
+	if caller.gotTo != contract {
+		t.Errorf("called %s, want %s", caller.gotTo.Hex(), contract.Hex())
+	}
+}
+
+func TestVerifyTaskHashOnChain_NoCode(t *testing.T) {
+	caller := &keccakCaller{out: []byte{}}
+	_, err := VerifyTaskHashOnChain(context.Background(), caller, common.HexToAddress(testContract), "task-001")
+	if !errors.Is(err, ErrNoContractResult) {
+		t.Errorf("expected ErrNoContractResult, got %v", err)
+	}
+}
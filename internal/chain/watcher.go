@@ -4,22 +4,30 @@ package chain
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"log"
+	"math"
 	"math/big"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
 	"github.com/AgentMesh-Net/indexer-go/internal/config"
 	"github.com/AgentMesh-Net/indexer-go/internal/store"
 )
 
-// settlementABI is the minimal ABI fragment for the four events we watch.
+// settlementABI is the minimal ABI fragment for the events we watch. FeePaid
+// is optional: contracts that do not emit it have their fees computed from
+// the task on Released. CreatedV2 is Created with the escrowed token, emitted
+// by upgraded implementations behind the settlement proxy; both versions are
+// applied. Handlers are registered per event in eventHandlers (events.go).
 // We declare them inline to avoid depending on an external ABI file.
 const settlementABIJSON = `[
   {
@@ -33,6 +41,18 @@ const settlementABIJSON = `[
     "name": "Created",
     "type": "event"
   },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true,  "name": "taskHash",  "type": "bytes32"},
+      {"indexed": true,  "name": "employer",  "type": "address"},
+      {"indexed": false, "name": "amount",    "type": "uint256"},
+      {"indexed": false, "name": "deadline",  "type": "uint64"},
+      {"indexed": false, "name": "token",     "type": "address"}
+    ],
+    "name": "CreatedV2",
+    "type": "event"
+  },
   {
     "anonymous": false,
     "inputs": [
@@ -57,20 +77,130 @@ const settlementABIJSON = `[
     ],
     "name": "Refunded",
     "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true,  "name": "taskHash", "type": "bytes32"},
+      {"indexed": false, "name": "amount",   "type": "uint256"}
+    ],
+    "name": "FeePaid",
+    "type": "event"
   }
 ]`
 
+// Client is the subset of *ethclient.Client used by the watcher.
+type Client interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	Close()
+}
+
+// chainDialer dials with the chain's RPC TLS settings; see DialRPC.
+func chainDialer(ch config.ChainConfig) func(ctx context.Context, rpcURL string) (Client, error) {
+	return func(ctx context.Context, rpcURL string) (Client, error) {
+		return DialRPC(ctx, rpcURL, ch)
+	}
+}
+
 // Watcher monitors a single chain for settlement contract events and
 // syncs task state in the database.
 type Watcher struct {
 	rpcURL           string
 	contractAddr     common.Address
 	minConfirmations int
+	maxLogData       int
 	chainID          int
 	taskRepo         store.TaskRepo
 	parsedABI        abi.ABI
+	dispatch         map[common.Hash]eventHandler // by topic 0
+	dial             func(ctx context.Context, rpcURL string) (Client, error)
+	dedup            *logDedup
+	pending          *pendingLogs  // see pending.go
+	lost             *lostLogs     // see pending.go
+	logBufferSize    int           // subscription channel capacity
+	logQueueSize     int           // see logqueue.go
+	pollInterval     time.Duration // poll mode only; see pollLogs
+	headInterval     time.Duration // subscription mode only; see runOnce
+
+	// DB failure handling; see dbretry.go.
+	breaker       dbBreaker
+	retryBackoff  time.Duration
+	probeInterval time.Duration
+
+	mu     sync.Mutex
+	status Status
+	// Lag alarm state and hooks; see lagalarm.go.
+	lagAlarm lagAlarm
+	lagHooks []LagHook
+	// The live subscription's buffers, for Status; nil when not subscribed.
+	subLogs  chan types.Log
+	subQueue *logQueue
 }
 
+// Status is a point-in-time snapshot of a watcher's liveness.
+type Status struct {
+	ChainID int `json:"chain_id"`
+	// Connected is true while a subscription or poll loop is running.
+	Connected bool `json:"connected"`
+	// Mode is "subscription" or "poll" while connected.
+	Mode string `json:"mode,omitempty"`
+	// HeadBlock is the latest chain head seen; SyncedBlock is the highest
+	// block whose logs have been processed.
+	HeadBlock   uint64 `json:"head_block"`
+	SyncedBlock uint64 `json:"synced_block"`
+	// SyncedAt is when SyncedBlock last advanced.
+	SyncedAt time.Time `json:"synced_at,omitempty"`
+	// HeadCheckedAt is when HeadBlock was last refreshed.
+	HeadCheckedAt time.Time `json:"head_checked_at,omitempty"`
+	// LastEventAt is when one of the settlement events was last applied.
+	LastEventAt time.Time `json:"last_event_at,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	// DBPaused is true while log processing is paused by the DB circuit
+	// breaker.
+	DBPaused bool `json:"db_paused,omitempty"`
+	// SchemaWaiting is true while an event is held back because the
+	// database schema is not ready.
+	SchemaWaiting bool `json:"schema_waiting,omitempty"`
+	// PendingLogs counts subscribed logs waiting for confirmations.
+	PendingLogs int `json:"pending_logs,omitempty"`
+	// LostLogs counts subscribed logs dropped before they were applied;
+	// SyncedBlock stays below them until their transactions are reprocessed.
+	LostLogs int `json:"lost_logs,omitempty"`
+	// LogChannelDepth and LogQueueDepth are the subscribed logs waiting in
+	// the subscription channel and in the queue in front of processing.
+	LogChannelDepth int `json:"log_channel_depth,omitempty"`
+	LogQueueDepth   int `json:"log_queue_depth,omitempty"`
+	// Lagging is true between an indexer.chain_lagging and the
+	// indexer.chain_recovered that ends it.
+	Lagging bool `json:"lagging,omitempty"`
+}
+
+// BlockLag returns HeadBlock - SyncedBlock, or 0 if synced is ahead.
+func (s Status) BlockLag() uint64 {
+	if s.HeadBlock > s.SyncedBlock {
+		return s.HeadBlock - s.SyncedBlock
+	}
+	return 0
+}
+
+// setSynced sets SyncedBlock, stamping SyncedAt if it changed.
+func (s *Status) setSynced(block uint64) {
+	if block != s.SyncedBlock {
+		s.SyncedBlock = block
+		s.SyncedAt = time.Now()
+	}
+}
+
+// defaultMaxLogDataBytes bounds settlement log data when the chain config does
+// not. The largest watched event (CreatedV2) carries 96 bytes of data.
+const defaultMaxLogDataBytes = 1024
+
+// headCheckInterval is how often consumeLogs refreshes the chain head.
+const headCheckInterval = 30 * time.Second
+
 // NewWatcher creates a Watcher for the given chain config.
 // rpcURL is the WebSocket or HTTP RPC endpoint for the chain.
 func NewWatcher(rpcURL string, chainCfg config.ChainConfig, taskRepo store.TaskRepo) (*Watcher, error) {
@@ -78,16 +208,125 @@ func NewWatcher(rpcURL string, chainCfg config.ChainConfig, taskRepo store.TaskR
 	if err != nil {
 		return nil, err
 	}
+	dispatch, err := newDispatch(parsedABI)
+	if err != nil {
+		return nil, err
+	}
+	maxLogData := chainCfg.MaxLogDataBytes
+	if maxLogData == 0 {
+		maxLogData = defaultMaxLogDataBytes
+	}
+	dedupSize, dedupTTL := chainCfg.LogDedupSize, time.Duration(chainCfg.LogDedupTTLSeconds)*time.Second
+	if dedupSize == 0 {
+		dedupSize = defaultLogDedupSize
+	}
+	if dedupTTL == 0 {
+		dedupTTL = defaultLogDedupTTL
+	}
+	logBufferSize, logQueueSize := chainCfg.LogBufferSize, chainCfg.LogQueueSize
+	if logBufferSize == 0 {
+		logBufferSize = defaultLogBufferSize
+	}
+	if logQueueSize == 0 {
+		logQueueSize = defaultLogQueueSize
+	}
 	return &Watcher{
 		rpcURL:           rpcURL,
+		maxLogData:       maxLogData,
 		contractAddr:     common.HexToAddress(chainCfg.SettlementContract),
 		minConfirmations: chainCfg.MinConfirmations,
 		chainID:          chainCfg.ChainID,
 		taskRepo:         taskRepo,
 		parsedABI:        parsedABI,
+		dispatch:         dispatch,
+		dial:             chainDialer(chainCfg),
+		dedup:            newLogDedup(dedupSize, dedupTTL),
+		pending:          newPendingLogs(),
+		lost:             newLostLogs(),
+		logBufferSize:    logBufferSize,
+		logQueueSize:     logQueueSize,
+		pollInterval:     12 * time.Second,
+		headInterval:     headCheckInterval,
+		retryBackoff:     500 * time.Millisecond,
+		probeInterval:    5 * time.Second,
+		status:           Status{ChainID: chainCfg.ChainID},
+		lagAlarm:         lagAlarm{trip: chainCfg.LagAlarmBlocks, clear: chainCfg.LagAlarmClear()},
 	}, nil
 }
 
+// ChainID returns the chain this watcher follows.
+func (w *Watcher) ChainID() int {
+	return w.chainID
+}
+
+// Status returns a snapshot of the watcher's liveness state.
+func (w *Watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := w.status
+	if w.subQueue != nil {
+		s.LogChannelDepth = len(w.subLogs)
+		s.LogQueueDepth = w.subQueue.len()
+	}
+	return s
+}
+
+func (w *Watcher) setSubscriptionBuffers(logs chan types.Log, q *logQueue) {
+	w.mu.Lock()
+	w.subLogs, w.subQueue = logs, q
+	w.mu.Unlock()
+}
+
+// updateStatus applies fn to the status and evaluates the lag alarm against
+// the result.
+func (w *Watcher) updateStatus(fn func(s *Status)) {
+	w.mu.Lock()
+	fn(&w.status)
+	alarm, raised := w.lagAlarm.observe(w.status, time.Now())
+	w.status.Lagging = w.lagAlarm.lagging
+	hooks := w.lagHooks
+	w.mu.Unlock()
+	if raised {
+		w.fireLagAlarm(alarm, hooks)
+	}
+}
+
+// setHead records the chain head. With synced, the synced block follows the
+// confirmed head as far as capSynced allows.
+func (w *Watcher) setHead(head uint64, synced bool) {
+	w.updateStatus(func(s *Status) {
+		s.HeadBlock = head
+		s.HeadCheckedAt = time.Now()
+		if synced {
+			s.setSynced(w.capSynced(head - min(head, uint64(w.minConfirmations))))
+		}
+	})
+}
+
+func (w *Watcher) markEvent(block uint64) {
+	w.updateStatus(func(s *Status) {
+		s.LastEventAt = time.Now()
+		s.setSynced(max(s.SyncedBlock, w.capSynced(block)))
+	})
+}
+
+// capSynced lowers block to just below the earliest subscribed log that has
+// not been applied: one still queued, held for confirmations or dropped. The
+// synced block then only covers logs that were actually applied. Callers
+// hold w.mu.
+func (w *Watcher) capSynced(block uint64) uint64 {
+	floors := []func() (uint64, bool){w.pending.minBlock, w.lost.minBlock}
+	if w.subQueue != nil {
+		floors = append(floors, w.subQueue.minBlock)
+	}
+	for _, floor := range floors {
+		if b, ok := floor(); ok {
+			block = min(block, max(b, 1)-1)
+		}
+	}
+	return block
+}
+
 // Run starts the watcher loop. It reconnects automatically on error and
 // exits when ctx is cancelled. Errors are logged but never panic.
 //
@@ -101,7 +340,15 @@ func (w *Watcher) Run(ctx context.Context) {
 		default:
 		}
 
-		if err := w.runOnce(ctx); err != nil {
+		err := w.runOnce(ctx)
+		w.updateStatus(func(s *Status) {
+			s.Connected = false
+			s.Mode = ""
+			if err != nil {
+				s.LastError = err.Error()
+			}
+		})
+		if err != nil {
 			log.Printf("[watcher chain=%d] error: %v — reconnecting in 10s", w.chainID, err)
 		}
 
@@ -113,19 +360,33 @@ func (w *Watcher) Run(ctx context.Context) {
 	}
 }
 
+// filterQuery builds a log filter for the settlement contract restricted to
+// the watched event signatures (OR'd in the first topic position), so the RPC
+// does not return events we would ignore.
+func (w *Watcher) filterQuery(from, to *big.Int) ethereum.FilterQuery {
+	ids := make([]common.Hash, len(eventHandlers))
+	for i, h := range eventHandlers {
+		ids[i] = w.parsedABI.Events[h.Name].ID
+	}
+	return ethereum.FilterQuery{
+		FromBlock: from,
+		ToBlock:   to,
+		Addresses: []common.Address{w.contractAddr},
+		Topics:    [][]common.Hash{ids},
+	}
+}
+
 // runOnce connects and subscribes; returns on error or context cancel.
 func (w *Watcher) runOnce(ctx context.Context) error {
-	client, err := ethclient.DialContext(ctx, w.rpcURL)
+	client, err := w.dial(ctx, w.rpcURL)
 	if err != nil {
 		return err
 	}
 	defer client.Close()
 
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{w.contractAddr},
-	}
+	query := w.filterQuery(nil, nil)
 
-	logs := make(chan types.Log, 64)
+	logs := make(chan types.Log, w.logBufferSize)
 	sub, err := client.SubscribeFilterLogs(ctx, query, logs)
 	if err != nil {
 		// Fallback: use polling via FilterLogs for HTTP endpoints
@@ -134,22 +395,57 @@ func (w *Watcher) runOnce(ctx context.Context) error {
 	defer sub.Unsubscribe()
 
 	log.Printf("[watcher chain=%d] subscribed to %s", w.chainID, w.contractAddr.Hex())
+	w.updateStatus(func(s *Status) {
+		s.Connected = true
+		s.Mode = "subscription"
+		s.LastError = ""
+	})
+
+	// While the subscription is live every log up to the head has been
+	// delivered, so the synced block tracks the confirmed head, held below
+	// any delivered log not yet applied (capSynced). Logs that arrive
+	// unconfirmed are held back (see pending.go) and applied on a later head
+	// refresh, or as soon as a log from a later block shows the head has
+	// moved far enough.
+	if head, err := client.BlockNumber(ctx); err == nil {
+		w.setHead(head, true)
+		w.applyPending(ctx, client, head)
+	}
+
+	// This loop only moves logs into the queue; consumeLogs applies them, so
+	// a slow DB cannot fill the subscription channel (see logqueue.go).
+	queue := newLogQueue(w.logQueueSize)
+	w.setSubscriptionBuffers(logs, queue)
+	defer w.setSubscriptionBuffers(nil, nil)
+	consumeCtx, stop := context.WithCancel(ctx)
+	consumed := make(chan error, 1)
+	go func() { consumed <- w.consumeLogs(consumeCtx, client, queue) }()
+	defer func() {
+		stop()
+		<-consumed
+	}()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case err := <-sub.Err():
+			subscriptionErrors.Inc(strconv.Itoa(w.chainID), subscriptionErrorCause(err))
+			return err
+		case err := <-consumed:
+			consumed <- err // for the deferred wait
 			return err
 		case vLog := <-logs:
-			w.handleLog(ctx, client, vLog)
+			w.enqueueLog(queue, vLog)
 		}
 	}
 }
 
 // pollLogs is a fallback for HTTP RPC endpoints that don't support subscriptions.
-// It polls every 12 seconds starting from the latest block.
-func (w *Watcher) pollLogs(ctx context.Context, client *ethclient.Client) error {
+// It polls every pollInterval starting from the latest block, and only asks
+// for blocks with minConfirmations on top: a log fetched any earlier would
+// fail the confirmation check and never be fetched again.
+func (w *Watcher) pollLogs(ctx context.Context, client Client) error {
 	log.Printf("[watcher chain=%d] subscription not available, falling back to poll mode", w.chainID)
 
 	latestBlock, err := client.BlockNumber(ctx)
@@ -157,8 +453,14 @@ func (w *Watcher) pollLogs(ctx context.Context, client *ethclient.Client) error
 		return err
 	}
 	fromBlock := new(big.Int).SetUint64(latestBlock)
-
-	ticker := time.NewTicker(12 * time.Second)
+	w.setHead(latestBlock, true)
+	w.updateStatus(func(s *Status) {
+		s.Connected = true
+		s.Mode = "poll"
+		s.LastError = ""
+	})
+
+	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -172,16 +474,15 @@ func (w *Watcher) pollLogs(ctx context.Context, client *ethclient.Client) error
 		if err != nil {
 			return err
 		}
-		if currentBlock <= fromBlock.Uint64() {
+		w.setHead(currentBlock, false)
+		w.applyPending(ctx, client, currentBlock) // left over from a subscription
+		confirmed := currentBlock - min(currentBlock, uint64(w.minConfirmations))
+		if confirmed < fromBlock.Uint64() {
 			continue
 		}
 
-		toBlock := new(big.Int).SetUint64(currentBlock)
-		query := ethereum.FilterQuery{
-			FromBlock: fromBlock,
-			ToBlock:   toBlock,
-			Addresses: []common.Address{w.contractAddr},
-		}
+		toBlock := new(big.Int).SetUint64(confirmed)
+		query := w.filterQuery(fromBlock, toBlock)
 
 		fetched, err := client.FilterLogs(ctx, query)
 		if err != nil {
@@ -190,20 +491,37 @@ func (w *Watcher) pollLogs(ctx context.Context, client *ethclient.Client) error
 		}
 
 		for _, vLog := range fetched {
-			w.handleLog(ctx, client, vLog)
+			w.processLog(ctx, client, vLog)
 		}
+		w.updateStatus(func(s *Status) { s.setSynced(max(s.SyncedBlock, w.capSynced(confirmed))) })
 
-		fromBlock = new(big.Int).SetUint64(currentBlock + 1)
+		fromBlock = new(big.Int).SetUint64(confirmed + 1)
 	}
 }
 
-// handleLog dispatches a log to the appropriate event handler after
-// confirming it has enough confirmations.
-func (w *Watcher) handleLog(ctx context.Context, client *ethclient.Client, vLog types.Log) {
+// Errors reported by handleLog for logs that were not applied.
+var (
+	ErrLogRemoved      = errors.New("log removed by reorg")
+	ErrNotConfirmed    = errors.New("log does not have enough confirmations yet")
+	ErrMalformedLog    = errors.New("log has too few topics for its event")
+	ErrUnknownTaskHash = errors.New("no task with this task_hash")
+	ErrOversizedLog    = errors.New("log data exceeds max_log_data_bytes")
+)
+
+// AuditEventOversizedLog is recorded when a settlement log is skipped because
+// its data is larger than the configured bound.
+const AuditEventOversizedLog = audit.OversizedLog
+
+// handleLog dispatches a log to its registered event handler after
+// confirming it has enough confirmations. It returns the ABI event name (""
+// for logs that are not settlement events) and a non-nil error if the log was
+// not applied. Handlers failing with a DB error are retried (withDBRetry).
+// Logs with an unregistered topic are recorded in unknown_logs.
+func (w *Watcher) handleLog(ctx context.Context, client Client, vLog types.Log) (string, error) {
 	// Skip removed (reorg) logs
 	if vLog.Removed {
 		log.Printf("[watcher chain=%d] skipping removed log tx=%s", w.chainID, vLog.TxHash.Hex())
-		return
+		return "", ErrLogRemoved
 	}
 
 	// Check confirmations
@@ -211,32 +529,53 @@ func (w *Watcher) handleLog(ctx context.Context, client *ethclient.Client, vLog
 		currentBlock, err := client.BlockNumber(ctx)
 		if err != nil {
 			log.Printf("[watcher chain=%d] cannot get block number: %v", w.chainID, err)
-			return
+			return "", err
 		}
 		if currentBlock < vLog.BlockNumber+uint64(w.minConfirmations) {
 			log.Printf("[watcher chain=%d] log block=%d current=%d minConf=%d — waiting",
 				w.chainID, vLog.BlockNumber, currentBlock, w.minConfirmations)
-			return
+			return "", ErrNotConfirmed
 		}
 	}
 
 	if len(vLog.Topics) == 0 {
-		return
+		return "", nil
 	}
 
-	eventID := vLog.Topics[0]
+	// Bound the data before any decoding so a crafted log cannot force a
+	// large allocation.
+	if len(vLog.Data) > w.maxLogData {
+		w.auditOversizedLog(ctx, vLog)
+		return "", ErrOversizedLog
+	}
 
-	switch eventID {
-	case w.parsedABI.Events["Created"].ID:
-		w.onCreated(ctx, vLog)
-	case w.parsedABI.Events["WorkerSet"].ID:
-		w.onWorkerSet(ctx, vLog)
-	case w.parsedABI.Events["Released"].ID:
-		w.onReleased(ctx, vLog)
-	case w.parsedABI.Events["Refunded"].ID:
-		w.onRefunded(ctx, vLog)
-	default:
-		// Unknown event — ignore
+	h, ok := w.dispatch[vLog.Topics[0]]
+	if !ok {
+		w.recordUnknownLog(ctx, vLog)
+		return "", nil
+	}
+	ctx = store.WithEventSource(ctx, store.EventSource{ChainID: w.chainID, TxHash: vLog.TxHash.Hex(), LogIndex: vLog.Index})
+	return h.Name, w.withDBRetry(ctx, func() error { return h.apply(w, ctx, vLog) })
+}
+
+func (w *Watcher) auditOversizedLog(ctx context.Context, vLog types.Log) {
+	log.Printf("[watcher chain=%d] skipping log tx=%s index=%d: %d data bytes exceeds limit %d",
+		w.chainID, vLog.TxHash.Hex(), vLog.Index, len(vLog.Data), w.maxLogData)
+	w.audit(ctx, AuditEventOversizedLog, map[string]any{
+		"tx_hash":      vLog.TxHash.Hex(),
+		"log_index":    vLog.Index,
+		"block_number": vLog.BlockNumber,
+		"data_bytes":   len(vLog.Data),
+		"limit":        w.maxLogData,
+	})
+}
+
+// audit records an audit event for this chain. Failures are logged; they
+// never hold up the watcher.
+func (w *Watcher) audit(ctx context.Context, typ string, detail map[string]any) {
+	chainID := w.chainID
+	if err := audit.Record(ctx, w.taskRepo, typ, &chainID, detail); err != nil {
+		log.Printf("[watcher chain=%d] %s audit: %v", w.chainID, typ, err)
 	}
 }
 
@@ -247,73 +586,135 @@ func taskHashFromTopic(topic common.Hash) string {
 	return "0x" + hex.EncodeToString(topic.Bytes())
 }
 
-func (w *Watcher) onCreated(ctx context.Context, vLog types.Log) {
+func (w *Watcher) onCreated(ctx context.Context, vLog types.Log) error {
+	values, err := w.parsedABI.Unpack("Created", vLog.Data)
+	if err != nil || len(values) != 2 {
+		return ErrMalformedLog
+	}
+	terms, ok := createdTerms(values)
+	if !ok {
+		return ErrMalformedLog
+	}
+	return w.applyCreated(ctx, vLog, terms, nil)
+}
+
+// createdTerms reads the leading amount and deadline of an unpacked Created
+// or CreatedV2 event.
+func createdTerms(values []any) (*store.OnchainTerms, bool) {
+	amount, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, false
+	}
+	deadline, ok := values[1].(uint64)
+	if !ok || deadline > math.MaxInt64 {
+		return nil, false
+	}
+	return &store.OnchainTerms{AmountWei: amount.String(), DeadlineUnix: int64(deadline)}, true
+}
+
+// taskByHash looks up the task a log refers to. Soft-deleted tasks are
+// included: they can still be funded and settled onchain.
+func (w *Watcher) taskByHash(ctx context.Context, taskHash string) (*store.Task, error) {
+	return w.taskRepo.GetTaskByHash(store.WithDeleted(ctx), w.chainID, taskHash)
+}
+
+// applyCreated marks the task created onchain with the event's terms. token
+// is the escrowed token of a CreatedV2 event and nil for Created, which does
+// not carry one.
+func (w *Watcher) applyCreated(ctx context.Context, vLog types.Log, terms *store.OnchainTerms, token *common.Address) error {
 	if len(vLog.Topics) < 2 {
-		return
+		return ErrMalformedLog
 	}
 	taskHash := taskHashFromTopic(vLog.Topics[1])
 	txHash := vLog.TxHash.Hex()
 	blockTime := time.Now() // approximate; use block timestamp in production if needed
 
-	task, err := w.taskRepo.GetTaskByHash(ctx, taskHash)
+	task, err := w.taskByHash(ctx, taskHash)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			log.Printf("[watcher chain=%d] Created event for unknown taskHash=%s tx=%s — audit: unexpected_onchain_create",
-				w.chainID, taskHash, txHash)
-		} else {
-			log.Printf("[watcher chain=%d] GetTaskByHash error: %v", w.chainID, err)
+		if errors.Is(err, store.ErrNotFound) {
+			log.Printf("[watcher chain=%d] Created event for unknown taskHash=%s tx=%s", w.chainID, taskHash, txHash)
+			w.audit(ctx, audit.UnexpectedOnchainCreate, map[string]any{
+				"task_hash":    taskHash,
+				"tx_hash":      txHash,
+				"block_number": vLog.BlockNumber,
+			})
+			return ErrUnknownTaskHash
 		}
-		return
+		log.Printf("[watcher chain=%d] GetTaskByHash error: %v", w.chainID, err)
+		return err
+	}
+	if token != nil {
+		w.checkCreatedToken(ctx, task, *token, txHash)
 	}
 
-	if err := w.taskRepo.UpdateOnchainCreated(ctx, task.TaskID, txHash, blockTime); err != nil {
+	if err := w.taskRepo.UpdateOnchainCreated(ctx, task.TaskID, txHash, terms, blockTime); err != nil {
 		log.Printf("[watcher chain=%d] UpdateOnchainCreated error: %v", w.chainID, err)
-		return
+		return err
 	}
+	w.markEvent(vLog.BlockNumber)
 	log.Printf("[watcher chain=%d] Created: taskID=%s taskHash=%s tx=%s", w.chainID, task.TaskID, taskHash, txHash)
+	return nil
 }
 
-func (w *Watcher) onWorkerSet(ctx context.Context, vLog types.Log) {
+func (w *Watcher) onWorkerSet(ctx context.Context, vLog types.Log) error {
 	if len(vLog.Topics) < 3 {
-		return
+		return ErrMalformedLog
 	}
 	taskHash := taskHashFromTopic(vLog.Topics[1])
 	workerAddr := common.BytesToAddress(vLog.Topics[2].Bytes()).Hex()
 	txHash := vLog.TxHash.Hex()
 
-	if err := w.taskRepo.UpdateOnchainWorkerSet(ctx, taskHash, strings.ToLower(workerAddr), txHash); err != nil {
+	prev := w.auditTransition(ctx, "WorkerSet", taskHash, txHash)
+	if err := w.taskRepo.UpdateOnchainWorkerSet(ctx, w.chainID, taskHash, workerAddr, txHash); err != nil {
 		log.Printf("[watcher chain=%d] UpdateOnchainWorkerSet error: %v", w.chainID, err)
-		return
+		return err
 	}
+	if prev != nil {
+		w.auditWorkerMismatch(ctx, prev, workerAddr, txHash)
+	}
+	w.markEvent(vLog.BlockNumber)
 	log.Printf("[watcher chain=%d] WorkerSet: taskHash=%s worker=%s tx=%s", w.chainID, taskHash, workerAddr, txHash)
+	return nil
 }
 
-func (w *Watcher) onReleased(ctx context.Context, vLog types.Log) {
+func (w *Watcher) onReleased(ctx context.Context, vLog types.Log) error {
 	if len(vLog.Topics) < 2 {
-		return
+		return ErrMalformedLog
 	}
 	taskHash := taskHashFromTopic(vLog.Topics[1])
 	txHash := vLog.TxHash.Hex()
 	at := time.Now()
 
-	if err := w.taskRepo.UpdateOnchainReleased(ctx, taskHash, txHash, at); err != nil {
+	prev := w.auditTransition(ctx, "Released", taskHash, txHash)
+	if err := w.taskRepo.UpdateOnchainReleased(ctx, w.chainID, taskHash, txHash, at); err != nil {
 		log.Printf("[watcher chain=%d] UpdateOnchainReleased error: %v", w.chainID, err)
-		return
+		return err
+	}
+	if prev != nil && prev.Status != store.TaskStatusReleased {
+		w.auditDelivery(ctx, prev, txHash)
 	}
+	if err := w.recordFee(ctx, taskHash, txHash, at, nil); err != nil {
+		return err
+	}
+	w.markEvent(vLog.BlockNumber)
 	log.Printf("[watcher chain=%d] Released: taskHash=%s tx=%s", w.chainID, taskHash, txHash)
+	return nil
 }
 
-func (w *Watcher) onRefunded(ctx context.Context, vLog types.Log) {
+func (w *Watcher) onRefunded(ctx context.Context, vLog types.Log) error {
 	if len(vLog.Topics) < 2 {
-		return
+		return ErrMalformedLog
 	}
 	taskHash := taskHashFromTopic(vLog.Topics[1])
 	txHash := vLog.TxHash.Hex()
 	at := time.Now()
 
-	if err := w.taskRepo.UpdateOnchainRefunded(ctx, taskHash, txHash, at); err != nil {
+	w.auditTransition(ctx, "Refunded", taskHash, txHash)
+	if err := w.taskRepo.UpdateOnchainRefunded(ctx, w.chainID, taskHash, txHash, at); err != nil {
 		log.Printf("[watcher chain=%d] UpdateOnchainRefunded error: %v", w.chainID, err)
-		return
+		return err
 	}
+	w.markEvent(vLog.BlockNumber)
 	log.Printf("[watcher chain=%d] Refunded: taskHash=%s tx=%s", w.chainID, taskHash, txHash)
+	return nil
 }
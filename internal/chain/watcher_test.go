@@ -0,0 +1,227 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestFilterQuery_TopicsFromABI(t *testing.T) {
+	w := newTestWatcher(t, &stubClient{}, &recordingRepo{})
+
+	q := w.filterQuery(big.NewInt(10), big.NewInt(20))
+
+	if len(q.Addresses) != 1 || q.Addresses[0] != common.HexToAddress(testContract) {
+		t.Fatalf("unexpected addresses: %v", q.Addresses)
+	}
+	if q.FromBlock.Int64() != 10 || q.ToBlock.Int64() != 20 {
+		t.Errorf("unexpected block range: %v..%v", q.FromBlock, q.ToBlock)
+	}
+	if len(q.Topics) != 1 {
+		t.Fatalf("expected a single OR'd first-topic set, got %d positions", len(q.Topics))
+	}
+
+	want := map[common.Hash]string{
+		crypto.Keccak256Hash([]byte("Created(bytes32,address,uint256,uint64)")):           "Created",
+		crypto.Keccak256Hash([]byte("CreatedV2(bytes32,address,uint256,uint64,address)")): "CreatedV2",
+		crypto.Keccak256Hash([]byte("WorkerSet(bytes32,address)")):                        "WorkerSet",
+		crypto.Keccak256Hash([]byte("Released(bytes32)")):                                 "Released",
+		crypto.Keccak256Hash([]byte("Refunded(bytes32)")):                                 "Refunded",
+		crypto.Keccak256Hash([]byte("FeePaid(bytes32,uint256)")):                          "FeePaid",
+	}
+	if len(q.Topics[0]) != len(want) {
+		t.Fatalf("expected %d event IDs, got %d", len(want), len(q.Topics[0]))
+	}
+	for _, id := range q.Topics[0] {
+		if _, ok := want[id]; !ok {
+			t.Errorf("unexpected topic %s", id.Hex())
+		}
+		delete(want, id)
+	}
+	for _, name := range want {
+		t.Errorf("missing topic for %s", name)
+	}
+}
+
+func TestHandleLog_SkipsOversizedData(t *testing.T) {
+	repo := &flakyRepo{}
+	client := &stubClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+	vLog := types.Log{
+		Address:     common.HexToAddress(testContract),
+		Topics:      []common.Hash{w.parsedABI.Events["Released"].ID, common.HexToHash("0xaa")},
+		Data:        make([]byte, defaultMaxLogDataBytes+1),
+		BlockNumber: 90,
+	}
+
+	if _, err := w.handleLog(context.Background(), client, vLog); !errors.Is(err, ErrOversizedLog) {
+		t.Fatalf("expected ErrOversizedLog, got %v", err)
+	}
+	if repo.calls != 0 {
+		t.Error("oversized log reached the event handler")
+	}
+	if len(repo.audits) != 1 || repo.audits[0].Type != AuditEventOversizedLog ||
+		repo.audits[0].Detail["data_bytes"] != defaultMaxLogDataBytes+1 {
+		t.Errorf("audits = %+v", repo.audits)
+	}
+
+	vLog.Data = vLog.Data[:defaultMaxLogDataBytes]
+	if event, err := w.handleLog(context.Background(), client, vLog); err != nil || event != "Released" {
+		t.Errorf("log at the limit: event %q, err %v", event, err)
+	}
+}
+
+func TestHandleLog_Dispatch(t *testing.T) {
+	taskHash := common.HexToHash("0xaa")
+	repo := &chainRepo{task: store.Task{TaskID: "d-task", TaskHash: taskHashFromTopic(taskHash), AmountWei: "1000", IndexerFeeBPS: 20}}
+	client := &fakeClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+	ctx := context.Background()
+	employer := common.BytesToHash(common.HexToAddress("0xe1").Bytes())
+	worker := common.BytesToHash(common.HexToAddress("0xa1").Bytes())
+	hash := repo.task.TaskHash
+
+	for _, tc := range []struct {
+		log   types.Log
+		event string
+		err   error
+		call  string // last recorded repo call, "" for none
+	}{
+		{settlementLog(w, "Created", 90, "0x01", []common.Hash{taskHash, employer}, big.NewInt(1000), uint64(1767225600)), "Created", nil, "created " + hash},
+		{settlementLog(w, "WorkerSet", 90, "0x02", []common.Hash{taskHash, worker}), "WorkerSet", nil, "worker_set " + hash},
+		{settlementLog(w, "FeePaid", 90, "0x03", []common.Hash{taskHash}, big.NewInt(2)), "FeePaid", nil, "fee d-task"},
+		{settlementLog(w, "Released", 90, "0x04", []common.Hash{taskHash}), "Released", nil, "fee d-task"},
+		{settlementLog(w, "Refunded", 90, "0x05", []common.Hash{taskHash}), "Refunded", nil, "refunded " + hash},
+		// Not yet confirmed at head 100 with min_confirmations 2.
+		{settlementLog(w, "Refunded", 99, "0x06", []common.Hash{taskHash}), "", ErrNotConfirmed, ""},
+		{settlementLog(w, "WorkerSet", 90, "0x07", []common.Hash{taskHash}), "WorkerSet", ErrMalformedLog, ""},
+		{types.Log{Topics: []common.Hash{common.HexToHash("0xfeed")}, BlockNumber: 90, TxHash: common.HexToHash("0x08")}, "", nil,
+			"unknown " + common.HexToHash("0x08").Hex()},
+	} {
+		before := len(repo.recorded())
+		event, err := w.handleLog(ctx, client, tc.log)
+		if event != tc.event || !errors.Is(err, tc.err) {
+			t.Errorf("tx %s: event %q, err %v; want %q, %v", tc.log.TxHash.Hex(), event, err, tc.event, tc.err)
+		}
+		calls := repo.recorded()
+		var last string
+		if len(calls) > before {
+			last = calls[len(calls)-1]
+		}
+		if last != tc.call {
+			t.Errorf("tx %s: last repo call %q, want %q", tc.log.TxHash.Hex(), last, tc.call)
+		}
+	}
+}
+
+func TestPollLogs_AppliesLogsOnceConfirmed(t *testing.T) {
+	taskHash := common.HexToHash("0xbb")
+	repo := &chainRepo{task: store.Task{TaskID: "p-task", TaskHash: taskHashFromTopic(taskHash)}}
+	client := &fakeClient{head: 100}
+	w := newTestWatcher(t, client, repo)
+	w.pollInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.runOnce(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("runOnce: %v", err)
+		}
+	}()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s; repo calls %v, status %+v", what, repo.recorded(), w.Status())
+			}
+		}
+	}
+	waitFor("poll mode", func() bool { return w.Status().Mode == "poll" })
+
+	// Mined at 101; with min_confirmations 2 it is not fetched until 103.
+	client.emit(settlementLog(w, "Refunded", 101, "0x01", []common.Hash{taskHash}))
+	client.setHead(102)
+	time.Sleep(20 * time.Millisecond)
+	if calls := repo.recorded(); len(calls) != 0 {
+		t.Fatalf("applied before confirmation: %v", calls)
+	}
+
+	client.setHead(103)
+	waitFor("the refund", func() bool { return len(repo.recorded()) > 0 })
+	waitFor("synced block", func() bool { return w.Status().SyncedBlock >= 101 })
+	client.setHead(110)
+	waitFor("head 110", func() bool { return w.Status().SyncedBlock == 108 })
+	if calls := repo.recorded(); len(calls) != 1 || calls[0] != "refunded "+repo.task.TaskHash {
+		t.Errorf("repo calls %v, want one refund", calls)
+	}
+	if s := w.Status(); s.SyncedAt.IsZero() || time.Since(s.SyncedAt) > time.Minute {
+		t.Errorf("SyncedAt = %v after the synced block advanced", s.SyncedAt)
+	}
+}
+
+func TestRunOnce_SubscriptionHoldsUnconfirmedLogs(t *testing.T) {
+	taskHash := common.HexToHash("0xcc")
+	repo := &chainRepo{task: store.Task{TaskID: "s-task", TaskHash: taskHashFromTopic(taskHash)}}
+	client := &fakeClient{live: true, head: 100}
+	w := newTestWatcher(t, client, repo)
+	w.headInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.runOnce(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("runOnce: %v", err)
+		}
+	}()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s; repo calls %v, status %+v", what, repo.recorded(), w.Status())
+			}
+		}
+	}
+	waitFor("subscription", func() bool { return w.Status().Mode == "subscription" && client.subscribed() })
+	if s := w.Status(); s.SyncedBlock != 98 {
+		t.Errorf("synced block %d at head 100, want 98", s.SyncedBlock)
+	}
+
+	// Delivered as soon as it is mined; with min_confirmations 2 it is held
+	// until the head reaches 102 instead of being dropped.
+	refund := settlementLog(w, "Refunded", 100, "0x01", []common.Hash{taskHash})
+	client.emit(refund)
+	waitFor("the held log", func() bool { return w.Status().PendingLogs == 1 })
+	client.setHead(101)
+	time.Sleep(20 * time.Millisecond)
+	if calls := repo.recorded(); len(calls) != 0 {
+		t.Fatalf("applied before confirmation: %v", calls)
+	}
+	client.setHead(102)
+	waitFor("the refund", func() bool { return len(repo.recorded()) == 1 })
+	waitFor("empty buffer", func() bool { return w.Status().PendingLogs == 0 })
+
+	// A held log the chain reorganises away is never applied.
+	release := settlementLog(w, "Released", 102, "0x02", []common.Hash{taskHash})
+	client.emit(release)
+	waitFor("the held release", func() bool { return w.Status().PendingLogs == 1 })
+	release.Removed = true
+	client.emit(release)
+	waitFor("the removal", func() bool { return w.Status().PendingLogs == 0 })
+	client.setHead(110)
+	waitFor("head 110", func() bool { return w.Status().SyncedBlock == 108 })
+	if calls := repo.recorded(); len(calls) != 1 || calls[0] != "refunded "+repo.task.TaskHash {
+		t.Errorf("repo calls %v, want one refund", calls)
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate_ChainMetadata(t *testing.T) {
+	base := func(ch ChainConfig) Config {
+		ch.ChainID = 11155111
+		ch.SettlementContract = "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"
+		return Config{DBDSN: "postgres://x", SupportedChains: []ChainConfig{ch}}
+	}
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name    string
+		chain   ChainConfig
+		wantErr string
+	}{
+		{"none", ChainConfig{}, ""},
+		{"full", ChainConfig{Name: "Sepolia", Symbol: "ETH", Decimals: 18, ExplorerTxURLTemplate: "https://sepolia.etherscan.io/tx/{tx_hash}"}, ""},
+		{"no_placeholder", ChainConfig{ExplorerTxURLTemplate: "https://sepolia.etherscan.io/tx/"}, "placeholder"},
+		{"relative", ChainConfig{ExplorerTxURLTemplate: "/tx/{tx_hash}"}, "absolute"},
+		{"negative_decimals", ChainConfig{Decimals: -1}, "decimals"},
+		{"rpc_insecure", ChainConfig{RPCInsecureSkipVerify: true}, ""},
+		{"rpc_ca_missing", ChainConfig{RPCCAFile: "/nonexistent/ca.pem"}, "rpc_ca_file"},
+		{"rpc_ca_not_pem", ChainConfig{RPCCAFile: notPEM}, "no PEM certificates"},
+		{"rpc_ca_and_insecure", ChainConfig{RPCCAFile: notPEM, RPCInsecureSkipVerify: true}, "mutually exclusive"},
+		{"rpc_auth", ChainConfig{RPCHeaders: map[string]string{"X-Api-Key": "k"}, RPCBasicAuthUser: "u", RPCBasicAuthPassword: "p"}, ""},
+		{"rpc_password_only", ChainConfig{RPCBasicAuthPassword: "p"}, "needs rpc_basic_auth_user"},
+		{"log_dedup", ChainConfig{LogDedupSize: 100, LogDedupTTLSeconds: 5}, ""},
+		{"log_dedup_negative", ChainConfig{LogDedupTTLSeconds: -1}, "log_dedup_ttl_seconds"},
+		{"log_queue", ChainConfig{LogBufferSize: 256, LogQueueSize: 50000}, ""},
+		{"log_queue_negative", ChainConfig{LogQueueSize: -1}, "log_queue_size"},
+		{"max_lag", ChainConfig{MinConfirmations: 3, MaxLagBlocks: 10}, ""},
+		{"max_lag_below_confirmations", ChainConfig{MinConfirmations: 3, MaxLagBlocks: 2}, "max_lag_blocks"},
+		{"lag_alarm", ChainConfig{LagAlarmBlocks: 100, LagAlarmClearBlocks: 20}, ""},
+		{"lag_alarm_default_clear", ChainConfig{LagAlarmBlocks: 100}, ""},
+		{"lag_alarm_clear_not_below", ChainConfig{LagAlarmBlocks: 100, LagAlarmClearBlocks: 100}, "lag_alarm_clear_blocks"},
+		{"lag_alarm_negative", ChainConfig{LagAlarmBlocks: -1}, "lag_alarm_blocks"},
+		{"min_amount", ChainConfig{MinAmountWei: "1000000"}, ""},
+		{"min_amount_negative", ChainConfig{MinAmountWei: "-1"}, "min_amount_wei"},
+		{"rpc_auth_conflict", ChainConfig{RPCHeaders: map[string]string{"authorization": "Bearer x"}, RPCBasicAuthUser: "u"}, "conflicts"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := base(tc.chain).Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("err = %v, want mention of %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestExplorerTxURL(t *testing.T) {
+	c := ChainConfig{ExplorerTxURLTemplate: "https://explorer.example/tx/{tx_hash}?ref={tx_hash}"}
+	if got := c.ExplorerTxURL("0xab"); got != "https://explorer.example/tx/0xab?ref=0xab" {
+		t.Errorf("got %q", got)
+	}
+	if got := c.ExplorerTxURL(""); got != "" {
+		t.Errorf("empty hash: got %q", got)
+	}
+	if got := (ChainConfig{}).ExplorerTxURL("0xab"); got != "" {
+		t.Errorf("no template: got %q", got)
+	}
+}
+
+func TestValidate_RevokedSigners(t *testing.T) {
+	c := Config{
+		DBDSN:           "postgres://x",
+		SupportedChains: []ChainConfig{{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+		RevokedSigners:  []string{"5pCB+DwMAPVHm8aabzPlBWx3kBVX94EOijtjcU4/Gzc="},
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.RevokedSigners = append(c.RevokedSigners, "not-a-key")
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "AMN_REVOKED_SIGNERS") {
+		t.Fatalf("err = %v, want AMN_REVOKED_SIGNERS", err)
+	}
+}
+
+func TestTrustedProxyPrefixes(t *testing.T) {
+	c := Config{
+		DBDSN:           "postgres://x",
+		SupportedChains: []ChainConfig{{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+		TrustedProxies:  []string{"10.0.0.0/8", "192.168.1.7", "fd00::1/64"},
+	}
+	got, err := c.TrustedProxyPrefixes()
+	if err != nil || fmt.Sprint(got) != "[10.0.0.0/8 192.168.1.7/32 fd00::/64]" {
+		t.Fatalf("prefixes = %v, %v", got, err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.TrustedProxies = append(c.TrustedProxies, "proxy.internal")
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "AMN_TRUSTED_PROXIES") {
+		t.Fatalf("err = %v, want AMN_TRUSTED_PROXIES", err)
+	}
+}
+
+func TestValidate_DevModeRefusedInProduction(t *testing.T) {
+	c := Config{
+		DBDSN:           "postgres://x",
+		SupportedChains: []ChainConfig{{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+		DevMode:         true,
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("dev mode alone: %v", err)
+	}
+	for name, mutate := range map[string]func(*Config){
+		"signing_key":         func(c *Config) { c.SigningKeyHex = strings.Repeat("ab", 32) },
+		"require_signed_meta": func(c *Config) { c.RequireSignedMeta = true },
+	} {
+		c := c
+		mutate(&c)
+		if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "AMN_DEV_MODE") {
+			t.Errorf("%s: err = %v, want AMN_DEV_MODE", name, err)
+		}
+	}
+}
+
+func TestValidate_EndpointFamilies(t *testing.T) {
+	c := Config{
+		DBDSN:                "postgres://x",
+		SupportedChains:      []ChainConfig{{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+		DisableLegacyObjects: true,
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("legacy objects off: %v", err)
+	}
+	c.DisableV2Tasks = true
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "AMN_DISABLE_V2_TASKS") {
+		t.Errorf("both families off: err = %v", err)
+	}
+}
+
+func TestValidate_FeedFormat(t *testing.T) {
+	c := Config{
+		DBDSN:           "postgres://x",
+		SupportedChains: []ChainConfig{{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+	}
+	for _, format := range []string{"native", "cloudevents"} {
+		c.FeedFormat = format
+		if err := c.Validate(); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+	c.FeedFormat = "xml"
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "AMN_FEED_FORMAT") {
+		t.Errorf("xml: err = %v", err)
+	}
+}
+
+func TestValidate_TelemetrySignatureScheme(t *testing.T) {
+	c := Config{
+		DBDSN:           "postgres://x",
+		SupportedChains: []ChainConfig{{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+		TelemetryURL:    "https://collector.example",
+	}
+	for _, scheme := range []string{"hmac-sha256", "ed25519"} {
+		c.TelemetrySignatureScheme = scheme
+		if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "INDEXER_SIGNING_KEY") {
+			t.Errorf("%s without key: err = %v", scheme, err)
+		}
+	}
+	c.SigningKeyHex = strings.Repeat("ab", 32)
+	for _, scheme := range []string{"hmac-sha256", "ed25519"} {
+		c.TelemetrySignatureScheme = scheme
+		if err := c.Validate(); err != nil {
+			t.Errorf("%s: %v", scheme, err)
+		}
+	}
+	c.TelemetrySignatureScheme = "rsa"
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "AMN_TELEMETRY_SIGNATURE_SCHEME") {
+		t.Errorf("rsa: err = %v", err)
+	}
+}
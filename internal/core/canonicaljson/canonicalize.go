@@ -3,11 +3,35 @@ package canonicaljson
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+// Failure classes returned by CanonicalizeRaw. Every error it returns wraps
+// exactly one of them.
+var (
+	// ErrInvalidUTF8 means the input is not valid UTF-8 or contains a
+	// \u escape that is an unpaired UTF-16 surrogate.
+	ErrInvalidUTF8 = errors.New("invalid UTF-8")
+	// ErrUnsupportedNumber means a number cannot be represented as a finite
+	// IEEE 754 double (overflow, NaN, Infinity).
+	ErrUnsupportedNumber = errors.New("unsupported number")
+	// ErrMalformedJSON covers every other syntax error, including duplicate
+	// keys and non-JSON number forms.
+	ErrMalformedJSON = errors.New("malformed JSON")
 )
 
+var failures = metrics.NewCounterVec("amn_canonicaljson_failures_total",
+	"Canonicalization failures by class (invalid_utf8, unsupported_number, malformed_json).", "class")
+
 // Canonicalize takes a Go value, marshals it to JSON, then applies RFC 8785 JCS
 // canonicalization and returns the canonical UTF-8 bytes.
 func Canonicalize(v any) ([]byte, error) {
@@ -19,10 +43,105 @@ func Canonicalize(v any) ([]byte, error) {
 }
 
 // CanonicalizeRaw takes raw JSON bytes and returns RFC 8785 canonical form.
+//
+// Input is checked strictly: the underlying transformer silently passes
+// invalid UTF-8 through, replaces some unpaired surrogates and accepts
+// number forms JSON does not allow, so those are rejected here.
 func CanonicalizeRaw(raw json.RawMessage) ([]byte, error) {
+	if !utf8.Valid(raw) {
+		return nil, fail(ErrInvalidUTF8, errors.New("input is not valid UTF-8"))
+	}
+	if err := checkSurrogates(raw); err != nil {
+		return nil, fail(ErrInvalidUTF8, err)
+	}
 	out, err := jsoncanonicalizer.Transform(raw)
 	if err != nil {
-		return nil, fmt.Errorf("canonicaljson: transform: %w", err)
+		return nil, fail(classify(err), err)
+	}
+	if !json.Valid(raw) {
+		return nil, fail(ErrMalformedJSON, errors.New("input is not valid JSON"))
 	}
 	return out, nil
 }
+
+func fail(class, err error) error {
+	failures.Inc(FailureClass(class))
+	return fmt.Errorf("canonicaljson: %w: %w", class, err)
+}
+
+// FailureClass returns the metrics/API label for an error returned by
+// CanonicalizeRaw, or "" if err is not a canonicalization failure.
+func FailureClass(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidUTF8):
+		return "invalid_utf8"
+	case errors.Is(err, ErrUnsupportedNumber):
+		return "unsupported_number"
+	case errors.Is(err, ErrMalformedJSON):
+		return "malformed_json"
+	}
+	return ""
+}
+
+// classify maps a transformer error to a failure class. The transformer only
+// reports plain error strings, strconv errors from number parsing and its own
+// "Invalid JSON number" for NaN/Infinity.
+func classify(err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return ErrUnsupportedNumber
+	}
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "Invalid JSON number"):
+		return ErrUnsupportedNumber
+	case strings.HasPrefix(msg, "Missing surrogate"):
+		return ErrInvalidUTF8
+	}
+	return ErrMalformedJSON
+}
+
+// checkSurrogates rejects \u escapes that do not form a valid UTF-16
+// surrogate pair. Backslashes only occur inside strings, so no string
+// tracking is needed.
+func checkSurrogates(raw []byte) error {
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 >= len(raw) {
+			continue
+		}
+		if raw[i+1] != 'u' {
+			i++ // skip the escaped character, which may itself be a backslash
+			continue
+		}
+		r, ok := hexRune(raw, i+2)
+		if !ok {
+			continue // left for the transformer to report
+		}
+		i += 5
+		if !utf16.IsSurrogate(r) {
+			continue
+		}
+		if r >= 0xdc00 {
+			return fmt.Errorf("unpaired low surrogate \\u%04x", r)
+		}
+		if i+6 >= len(raw) || raw[i+1] != '\\' || raw[i+2] != 'u' {
+			return fmt.Errorf("unpaired high surrogate \\u%04x", r)
+		}
+		low, ok := hexRune(raw, i+3)
+		if !ok || low < 0xdc00 || low > 0xdfff {
+			return fmt.Errorf("unpaired high surrogate \\u%04x", r)
+		}
+		i += 6
+	}
+	return nil
+}
+
+func hexRune(raw []byte, at int) (rune, bool) {
+	if at+4 > len(raw) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(raw[at:at+4]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(n), true
+}
@@ -1,7 +1,15 @@
 package canonicaljson
 
 import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestVector1_ObjectMemberOrdering(t *testing.T) {
@@ -83,3 +91,168 @@ func TestCanonicalize_NestedObjects(t *testing.T) {
 		t.Errorf("got %s, want %s", got, expected)
 	}
 }
+
+//go:embed testdata
+var testdata embed.FS
+
+func TestRFC8785TestVectors(t *testing.T) {
+	inputs, err := fs.Glob(testdata, "testdata/rfc8785/input/*.json")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no test vectors found")
+	}
+	for _, in := range inputs {
+		name := path.Base(in)
+		t.Run(strings.TrimSuffix(name, ".json"), func(t *testing.T) {
+			input, err := testdata.ReadFile(in)
+			if err != nil {
+				t.Fatalf("read input: %v", err)
+			}
+			want, err := testdata.ReadFile(path.Join("testdata/rfc8785/output", name))
+			if err != nil {
+				t.Fatalf("read output: %v", err)
+			}
+			got, err := CanonicalizeRaw(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeRaw_EdgeCases(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// JCS does not apply Unicode normalization: NFC and NFD forms stay distinct.
+		{"nfc_preserved", `{"s":"\u00e9"}`, "{\"s\":\"\u00e9\"}"},
+		{"nfd_preserved", `{"s":"e\u0301"}`, "{\"s\":\"e\u0301\"}"},
+		// Escaped surrogate pairs are emitted as the literal UTF-8 code point.
+		{"surrogate_pair", `{"s":"\ud83d\ude00"}`, "{\"s\":\"\U0001F600\"}"},
+		// Keys are sorted by UTF-16 code units, so a supplementary-plane key
+		// (high surrogate 0xD83D) sorts before U+FB33.
+		{"surrogate_key_order", `{"\ufb33":1,"\ud83d\ude00":2}`, "{\"\U0001F600\":2,\"\ufb33\":1}"},
+		// Numbers are serialized as IEEE-754 doubles in ES6 form.
+		{"large_integer_precision", `[9007199254740993]`, `[9007199254740992]`},
+		{"large_exponent", `[1.7976931348623157e308]`, `[1.7976931348623157e+308]`},
+		{"tiny_exponent", `[5e-324]`, `[5e-324]`},
+		{"exponent_threshold", `[1e21,1e20]`, `[1e+21,100000000000000000000]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CanonicalizeRaw([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeRaw_NumberOutOfRange(t *testing.T) {
+	if _, err := CanonicalizeRaw([]byte(`[1e400]`)); err == nil {
+		t.Error("expected error for number outside IEEE-754 double range")
+	}
+}
+
+func TestCanonicalizeRaw_DeeplyNested(t *testing.T) {
+	const depth = 500
+	input := strings.Repeat(`{"b":1,"a":`, depth) + "[]" + strings.Repeat("}", depth)
+	want := strings.Repeat(`{"a":`, depth) + "[]" + strings.Repeat(`,"b":1}`, depth)
+
+	got, err := CanonicalizeRaw([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("deeply nested output mismatch (len got=%d want=%d)", len(got), len(want))
+	}
+}
+
+func TestCanonicalizeRaw_FailureClasses(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"invalid_utf8", "{\"s\":\"\xff\"}", ErrInvalidUTF8},
+		{"lone_high_surrogate", `{"s":"\ud800"}`, ErrInvalidUTF8},
+		{"high_surrogate_then_ascii", `{"s":"\ud800\u0041"}`, ErrInvalidUTF8},
+		{"lone_low_surrogate", `{"s":"\udc00x"}`, ErrInvalidUTF8},
+		{"overflow", `{"n":1e400}`, ErrUnsupportedNumber},
+		{"nan", `{"n":NaN}`, ErrUnsupportedNumber},
+		{"infinity", `[Infinity]`, ErrUnsupportedNumber},
+		{"hex_float", `{"n":0x1p-2}`, ErrMalformedJSON},
+		{"truncated", `{"a":`, ErrMalformedJSON},
+		{"duplicate_key", `{"a":1,"a":2}`, ErrMalformedJSON},
+		{"bad_literal", `[nul]`, ErrMalformedJSON},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := failures.Value(FailureClass(tc.want))
+			_, err := CanonicalizeRaw([]byte(tc.input))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+			if got := failures.Value(FailureClass(tc.want)); got != before+1 {
+				t.Errorf("%s counter = %g, want %g", FailureClass(tc.want), got, before+1)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeRaw_EscapedBackslashIsNotSurrogate(t *testing.T) {
+	// `\\ud800` is a literal backslash followed by "ud800", not an escape.
+	got, err := CanonicalizeRaw([]byte(`{"s":"\\ud800","p":"\ud83d\ude00"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"p":"😀","s":"\\ud800"}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// FuzzCanonicalizeRaw checks that canonicalization never panics, that every
+// error is classified, and that successful output is valid, stable JSON.
+// Seeds are the RFC 8785 vectors plus the inputs in testdata/fuzz.
+func FuzzCanonicalizeRaw(f *testing.F) {
+	inputs, err := fs.Glob(testdata, "testdata/rfc8785/input/*.json")
+	if err != nil {
+		f.Fatalf("glob: %v", err)
+	}
+	for _, in := range inputs {
+		b, err := testdata.ReadFile(in)
+		if err != nil {
+			f.Fatalf("read %s: %v", in, err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		out, err := CanonicalizeRaw(raw)
+		if err != nil {
+			if FailureClass(err) == "" {
+				t.Fatalf("unclassified error for %q: %v", raw, err)
+			}
+			return
+		}
+		if !utf8.Valid(out) || !json.Valid(out) {
+			t.Fatalf("output is not valid JSON: %q", out)
+		}
+		again, err := CanonicalizeRaw(out)
+		if err != nil {
+			t.Fatalf("canonical output rejected: %q: %v", out, err)
+		}
+		if !bytes.Equal(again, out) {
+			t.Fatalf("not idempotent:\n%q\n%q", out, again)
+		}
+	})
+}
@@ -5,6 +5,7 @@ package crypto
 import (
 	"crypto/ed25519"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 )
 
@@ -34,6 +35,19 @@ func DecodeSignature(s string) ([]byte, error) {
 	return b, nil
 }
 
+// PrivateKeyFromSeedHex decodes a 32-byte hex ed25519 seed (as configured in
+// INDEXER_SIGNING_KEY) into a private key.
+func PrivateKeyFromSeedHex(s string) (ed25519.PrivateKey, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	if len(raw) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key: expected %d bytes, got %d", ed25519.SeedSize, len(raw))
+	}
+	return ed25519.NewKeyFromSeed(raw), nil
+}
+
 // VerifyEd25519 verifies an ed25519 signature over message bytes.
 func VerifyEd25519(pubkey ed25519.PublicKey, message, sig []byte) bool {
 	return ed25519.Verify(pubkey, message, sig)
@@ -75,3 +75,20 @@ func TestVerifyEd25519_Invalid(t *testing.T) {
 		t.Error("expected invalid signature to fail verification")
 	}
 }
+
+func TestPrivateKeyFromSeedHex(t *testing.T) {
+	seed := "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	priv, err := PrivateKeyFromSeedHex(seed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(priv) != ed25519.PrivateKeySize {
+		t.Errorf("expected %d bytes, got %d", ed25519.PrivateKeySize, len(priv))
+	}
+	if _, err := PrivateKeyFromSeedHex("abcd"); err == nil {
+		t.Error("expected error for short seed")
+	}
+	if _, err := PrivateKeyFromSeedHex("zz"); err == nil {
+		t.Error("expected error for non-hex seed")
+	}
+}
@@ -0,0 +1,137 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// didKeyPrefix starts a did:key identifier whose method-specific id is a
+// multibase base58btc ('z') string.
+const didKeyPrefix = "did:key:"
+
+// maxDIDKeyLen bounds a did:key before base58 decoding, which takes time
+// quadratic in its length. An ed25519 did:key is 56 characters.
+const maxDIDKeyLen = 64
+
+// Multicodec codes of public key types seen in did:key identifiers.
+const multicodecEd25519Pub = 0xed
+
+var multicodecNames = map[uint64]string{
+	0xe7:   "secp256k1-pub",
+	0xec:   "x25519-pub",
+	0x1200: "p256-pub",
+	0x1201: "p384-pub",
+	0x1205: "rsa-pub",
+}
+
+// IsDIDKey reports whether s is a did:key identifier.
+func IsDIDKey(s string) bool {
+	return strings.HasPrefix(s, didKeyPrefix)
+}
+
+// DecodeSignerKey decodes an envelope signer.pubkey, which is either standard
+// base64 (see DecodePubKey) or an ed25519 did:key identifier
+// (did:key:z6Mk...).
+func DecodeSignerKey(s string) (ed25519.PublicKey, error) {
+	if IsDIDKey(s) {
+		return DecodeDIDKey(s)
+	}
+	return DecodePubKey(s)
+}
+
+// NormalizeSignerKey returns the standard base64 form of a signer.pubkey in
+// either accepted format.
+func NormalizeSignerKey(s string) (string, error) {
+	pub, err := DecodeSignerKey(s)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), nil
+}
+
+// DecodeDIDKey parses an ed25519 did:key identifier into its 32-byte public
+// key. Other key types are rejected.
+func DecodeDIDKey(s string) (ed25519.PublicKey, error) {
+	if len(s) > maxDIDKeyLen {
+		return nil, fmt.Errorf("did:key: longer than %d characters", maxDIDKeyLen)
+	}
+	mb, ok := strings.CutPrefix(s, didKeyPrefix)
+	if !ok {
+		return nil, fmt.Errorf("did:key: missing %q prefix", didKeyPrefix)
+	}
+	if !strings.HasPrefix(mb, "z") {
+		return nil, fmt.Errorf("did:key: unsupported multibase encoding (want base58btc 'z')")
+	}
+	raw, err := decodeBase58(mb[1:])
+	if err != nil {
+		return nil, fmt.Errorf("did:key: %w", err)
+	}
+	code, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return nil, fmt.Errorf("did:key: invalid multicodec prefix")
+	}
+	if code != multicodecEd25519Pub {
+		name := multicodecNames[code]
+		if name == "" {
+			name = fmt.Sprintf("0x%x", code)
+		}
+		return nil, fmt.Errorf("did:key: unsupported key type %s (only ed25519-pub is accepted)", name)
+	}
+	key := raw[n:]
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("did:key: expected %d key bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// EncodeDIDKey returns the did:key identifier for an ed25519 public key.
+func EncodeDIDKey(pub ed25519.PublicKey) string {
+	raw := binary.AppendUvarint(nil, multicodecEd25519Pub)
+	return didKeyPrefix + "z" + encodeBase58(append(raw, pub...))
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58 decodes Bitcoin-alphabet base58. Leading '1's are zero bytes.
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty base58 string")
+	}
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(base58Alphabet, s[i])
+		if d < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+func encodeBase58(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < len(b) && b[i] == 0; i++ {
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
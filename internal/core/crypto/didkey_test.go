@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// Vectors from the did:key method specification (w3c-ccg/did-method-key).
+const (
+	specEd25519DID    = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+	specEd25519Base58 = "4zvwRjXUKGfvwnParsHAS3HuSVzV5cA4McphgmoCtajS"
+	specSecp256k1DID  = "did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme"
+	specP256DID       = "did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169"
+)
+
+func TestDecodeDIDKey_SpecVector(t *testing.T) {
+	pub, err := DecodeDIDKey(specEd25519DID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, err := decodeBase58(specEd25519Base58)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pub) != string(want) {
+		t.Errorf("key = %x, want %x", pub, want)
+	}
+	if got := EncodeDIDKey(pub); got != specEd25519DID {
+		t.Errorf("EncodeDIDKey = %s, want %s", got, specEd25519DID)
+	}
+}
+
+func TestDecodeSignerKey_BothForms(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	b64 := base64.StdEncoding.EncodeToString(pub)
+	for _, s := range []string{b64, EncodeDIDKey(pub)} {
+		got, err := NormalizeSignerKey(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if got != b64 {
+			t.Errorf("%s normalized to %s, want %s", s, got, b64)
+		}
+	}
+}
+
+func TestDecodeDIDKey_Rejects(t *testing.T) {
+	short := didKeyPrefix + "z" + encodeBase58(append([]byte{0xed, 0x01}, make([]byte, 31)...))
+	cases := []struct {
+		in, wantErr string
+	}{
+		{specSecp256k1DID, "secp256k1-pub"},
+		{specP256DID, "p256-pub"},
+		{"did:key:m7QFAhb", "multibase"},
+		{"did:key:z6Mk0OIl", "invalid base58"},
+		{short, "key bytes"},
+		{didKeyPrefix + "z" + strings.Repeat("2", 200_000), "longer than"},
+	}
+	for _, tc := range cases {
+		_, err := DecodeSignerKey(tc.in)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: err = %v, want mention of %q", tc.in, err, tc.wantErr)
+		}
+	}
+}
@@ -75,7 +75,7 @@ func (e *Envelope) ValidateBasic() error {
 	}
 
 	// Validate base64 decode lengths
-	if _, err := crypto.DecodePubKey(e.Signer.PubKey); err != nil {
+	if _, err := crypto.DecodeSignerKey(e.Signer.PubKey); err != nil {
 		return fmt.Errorf("signer.pubkey: %w", err)
 	}
 	if _, err := crypto.DecodeSignature(e.Signature); err != nil {
@@ -106,7 +106,7 @@ func (e *Envelope) SignedPreimageBytes() ([]byte, error) {
 // Verify performs full signature verification: decodes the public key and
 // signature, computes the signing preimage, and verifies the ed25519 signature.
 func (e *Envelope) Verify() error {
-	pubkey, err := crypto.DecodePubKey(e.Signer.PubKey)
+	pubkey, err := crypto.DecodeSignerKey(e.Signer.PubKey)
 	if err != nil {
 		return fmt.Errorf("verify: %w", err)
 	}
@@ -124,6 +124,13 @@ func (e *Envelope) Verify() error {
 	return nil
 }
 
+// SignerKey returns the signer's public key in standard base64, whether
+// signer.pubkey is given as base64 or as a did:key identifier. The preimage
+// always uses signer.pubkey exactly as submitted.
+func (e *Envelope) SignerKey() (string, error) {
+	return crypto.NormalizeSignerKey(e.Signer.PubKey)
+}
+
 // PayloadTaskID extracts the task_id field from the payload, if present.
 func (e *Envelope) PayloadTaskID() (string, bool) {
 	var p struct {
@@ -1,9 +1,13 @@
 package envelope
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
 )
 
 // Test vectors generated with real ed25519 keys.
@@ -197,3 +201,28 @@ func TestAcceptMissingTaskID(t *testing.T) {
 		t.Fatal("expected PayloadTaskID to return false for empty payload")
 	}
 }
+
+func TestVerify_DIDKeySigner(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	env := Envelope{
+		ObjectType: "bid", ObjectVersion: "0.1", ObjectID: "did-bid", CreatedAt: "2025-01-01T00:00:00Z",
+		Payload: json.RawMessage(`{"task_id":"t"}`),
+		Signer:  Signer{Algo: "ed25519", PubKey: crypto.EncodeDIDKey(pub)},
+	}
+	preimage, err := env.SignedPreimageBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, preimage))
+
+	if err := env.ValidateBasic(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := env.Verify(); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	key, err := env.SignerKey()
+	if err != nil || key != base64.StdEncoding.EncodeToString(pub) {
+		t.Errorf("SignerKey = %q, %v", key, err)
+	}
+}
@@ -0,0 +1,58 @@
+package envelope
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/jsonschema"
+)
+
+// Payload schemas live in schemas/<object_version>/<object_type>.json, so a
+// new object version brings its own set.
+//
+//go:embed schemas
+var schemaFS embed.FS
+
+// payloadSchemas maps object_version to object_type to schema.
+var payloadSchemas = mustLoadSchemas()
+
+func mustLoadSchemas() map[string]map[string]*jsonschema.Schema {
+	out := make(map[string]map[string]*jsonschema.Schema)
+	versions, err := schemaFS.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	for _, v := range versions {
+		out[v.Name()] = make(map[string]*jsonschema.Schema)
+		for objectType := range ValidObjectTypes {
+			raw, err := schemaFS.ReadFile(path.Join("schemas", v.Name(), objectType+".json"))
+			if err != nil {
+				panic(fmt.Sprintf("payload schema %s/%s: %v", v.Name(), objectType, err))
+			}
+			s, err := jsonschema.Compile(raw)
+			if err != nil {
+				panic(fmt.Sprintf("payload schema %s/%s: %v", v.Name(), objectType, err))
+			}
+			out[v.Name()][objectType] = s
+		}
+	}
+	return out
+}
+
+// ValidatePayload checks the payload against the schema for the envelope's
+// object_type and object_version. Call it after ValidateBasic. The error
+// names the failing location, e.g. "payload/task_id: is required".
+func (e *Envelope) ValidatePayload() error {
+	s := payloadSchemas[e.ObjectVersion][e.ObjectType]
+	if s == nil {
+		return fmt.Errorf("no payload schema for %s v%s", e.ObjectType, e.ObjectVersion)
+	}
+	err := s.Validate(e.Payload)
+	var ve *jsonschema.ValidationError
+	if errors.As(err, &ve) {
+		return fmt.Errorf("payload%s: %s", ve.Path, ve.Message)
+	}
+	return err
+}
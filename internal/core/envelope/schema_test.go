@@ -0,0 +1,43 @@
+package envelope
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidatePayload(t *testing.T) {
+	cases := []struct {
+		objectType string
+		payload    string
+		wantErr    string // substring; empty = valid
+	}{
+		{"task", `{"title":"write docs","description":"d","extra":1}`, ""},
+		{"task", `{"description":"no title"}`, "payload/title: is required"},
+		{"task", `{"title":""}`, "payload/title: must be at least 1 characters"},
+		{"task", `{"title":42}`, "payload/title: expected string, got integer"},
+		{"bid", `{"task_id":"t-1","price":"10"}`, ""},
+		{"bid", `{}`, "payload/task_id: is required"},
+		{"accept", `{"task_id":"t-1"}`, ""},
+		{"accept", `{"task_id":["t-1"]}`, "payload/task_id: expected string, got array"},
+		{"artifact", `{"task_id":"t-1","uri":"ipfs://x"}`, ""},
+		{"artifact", `{"task_id":""}`, "payload/task_id: must be at least 1 characters"},
+	}
+	for _, tc := range cases {
+		env := &Envelope{ObjectType: tc.objectType, ObjectVersion: "0.1", Payload: json.RawMessage(tc.payload)}
+		err := env.ValidatePayload()
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s %s: unexpected error %v", tc.objectType, tc.payload, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%s %s: error %v, want %q", tc.objectType, tc.payload, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidatePayload_UnknownVersion(t *testing.T) {
+	env := &Envelope{ObjectType: "task", ObjectVersion: "9.9", Payload: json.RawMessage(`{"title":"t"}`)}
+	if err := env.ValidatePayload(); err == nil {
+		t.Fatal("expected error for a version without schemas")
+	}
+}
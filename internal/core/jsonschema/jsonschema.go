@@ -0,0 +1,254 @@
+// Package jsonschema validates JSON documents against a small subset of JSON
+// Schema (draft 2020-12): type, required, properties, additionalProperties
+// (boolean only), items, enum, minLength, maxLength, pattern, minimum and
+// maximum. Other keywords are rejected at compile time so a schema never
+// silently checks less than it appears to.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError reports the first place a document fails its schema. Path
+// is a JSON Pointer ("" for the document root).
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + e.Message
+}
+
+// Schema is a compiled schema.
+type Schema struct {
+	Types                []string
+	Required             []string
+	Properties           map[string]*Schema
+	AdditionalProperties *bool
+	Items                *Schema
+	Enum                 []json.RawMessage
+	MinLength            *int
+	MaxLength            *int
+	Pattern              *regexp.Regexp
+	Minimum              *big.Float
+	Maximum              *big.Float
+}
+
+// annotations are keywords that do not affect validation.
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "examples": true,
+}
+
+// Compile parses a schema document.
+func Compile(raw []byte) (*Schema, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	s := &Schema{}
+	for key, v := range doc {
+		var err error
+		switch key {
+		case "type":
+			var one string
+			if json.Unmarshal(v, &one) == nil {
+				s.Types = []string{one}
+			} else {
+				err = json.Unmarshal(v, &s.Types)
+			}
+		case "required":
+			err = json.Unmarshal(v, &s.Required)
+		case "properties":
+			var props map[string]json.RawMessage
+			if err = json.Unmarshal(v, &props); err == nil {
+				s.Properties = make(map[string]*Schema, len(props))
+				for name, p := range props {
+					if s.Properties[name], err = Compile(p); err != nil {
+						return nil, fmt.Errorf("properties.%s: %w", name, err)
+					}
+				}
+			}
+		case "additionalProperties":
+			err = json.Unmarshal(v, &s.AdditionalProperties)
+		case "items":
+			s.Items, err = Compile(v)
+		case "enum":
+			err = json.Unmarshal(v, &s.Enum)
+		case "minLength":
+			err = json.Unmarshal(v, &s.MinLength)
+		case "maxLength":
+			err = json.Unmarshal(v, &s.MaxLength)
+		case "pattern":
+			var p string
+			if err = json.Unmarshal(v, &p); err == nil {
+				s.Pattern, err = regexp.Compile(p)
+			}
+		case "minimum", "maximum":
+			f, ok := new(big.Float).SetString(string(v))
+			if !ok {
+				err = fmt.Errorf("not a number")
+			} else if key == "minimum" {
+				s.Minimum = f
+			} else {
+				s.Maximum = f
+			}
+		default:
+			if !annotations[key] {
+				return nil, fmt.Errorf("jsonschema: unsupported keyword %q", key)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: %s: %w", key, err)
+		}
+	}
+	return s, nil
+}
+
+// Validate checks the JSON document raw against s. It returns a
+// *ValidationError for the first failure, or an error if raw is not JSON.
+func (s *Schema) Validate(raw []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("jsonschema: %w", err)
+	}
+	return s.validate(v, "")
+}
+
+func (s *Schema) validate(v any, path string) error {
+	fail := func(format string, args ...any) error {
+		return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+	}
+	if len(s.Types) > 0 && !matchesAny(v, s.Types) {
+		return fail("expected %s, got %s", strings.Join(s.Types, " or "), typeOf(v))
+	}
+	if len(s.Enum) > 0 {
+		got, _ := json.Marshal(v)
+		found := false
+		for _, e := range s.Enum {
+			want, _ := canonical(e)
+			if bytes.Equal(got, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fail("value is not one of the allowed values")
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &ValidationError{Path: path + "/" + pointerEscape(name), Message: "is required"}
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return &ValidationError{Path: path + "/" + pointerEscape(name), Message: "is not allowed"}
+				}
+				continue
+			}
+			if err := p.validate(v[name], path+"/"+pointerEscape(name)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			return fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != nil && !s.Pattern.MatchString(v) {
+			return fail("does not match pattern %q", s.Pattern.String())
+		}
+	case json.Number:
+		f, _ := new(big.Float).SetString(v.String())
+		if s.Minimum != nil && f.Cmp(s.Minimum) < 0 {
+			return fail("must be >= %s", s.Minimum.Text('g', -1))
+		}
+		if s.Maximum != nil && f.Cmp(s.Maximum) > 0 {
+			return fail("must be <= %s", s.Maximum.Text('g', -1))
+		}
+	}
+	return nil
+}
+
+func matchesAny(v any, types []string) bool {
+	got := typeOf(v)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type name of a value decoded with UseNumber.
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if f, ok := new(big.Float).SetString(v.String()); ok && f.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// canonical re-encodes raw so enum values compare equal to decoded values
+// regardless of whitespace.
+func canonical(raw json.RawMessage) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func pointerEscape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
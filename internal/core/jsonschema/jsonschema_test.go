@@ -0,0 +1,57 @@
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	s, err := Compile([]byte(`{
+		"type": "object",
+		"required": ["id"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 4},
+			"n": {"type": "integer", "minimum": 1, "maximum": 10},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}},
+			"a/b": {"type": ["string", "null"]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		doc, path string // path "" = valid
+	}{
+		{`{"id":"abc","n":3,"tags":["a","b"],"a/b":null}`, ""},
+		{`{"n":3}`, "/id"},
+		{`{"id":"ABC"}`, "/id"},
+		{`{"id":"abcde"}`, "/id"},
+		{`{"id":"a","n":1.5}`, "/n"},
+		{`{"id":"a","n":11}`, "/n"},
+		{`{"id":"a","tags":["a","c"]}`, "/tags/1"},
+		{`{"id":"a","a/b":1}`, "/a~1b"},
+		{`{"id":"a","other":1}`, "/other"},
+		{`[]`, ""},
+	}
+	for _, tc := range cases {
+		err := s.Validate([]byte(tc.doc))
+		var ve *ValidationError
+		switch {
+		case tc.doc == `[]`:
+			if !errors.As(err, &ve) || ve.Path != "" {
+				t.Errorf("%s: error %v, want root validation error", tc.doc, err)
+			}
+		case tc.path == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.doc, err)
+		case tc.path != "" && (!errors.As(err, &ve) || ve.Path != tc.path):
+			t.Errorf("%s: error %v, want path %s", tc.doc, err, tc.path)
+		}
+	}
+}
+
+func TestCompile_UnsupportedKeyword(t *testing.T) {
+	if _, err := Compile([]byte(`{"type":"object","oneOf":[]}`)); err == nil {
+		t.Fatal("expected unsupported keyword error")
+	}
+}
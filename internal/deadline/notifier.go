@@ -0,0 +1,95 @@
+// Package deadline warns workers before an accepted task's deadline passes,
+// so a missed deadline does not silently turn into a refund.
+package deadline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// notificationKind is the task_notifications kind used for deadline warnings.
+const notificationKind = "deadline_approaching"
+
+// TaskSource is the subset of store.TaskRepo used by the notifier.
+type TaskSource interface {
+	ListAcceptedTasksDueBetween(ctx context.Context, from, to time.Time) ([]*store.Task, error)
+	ClaimTaskNotification(ctx context.Context, taskID, kind string, window time.Duration) (bool, error)
+}
+
+// Notifier emits store.TaskEventDeadlineApproaching for accepted tasks whose
+// deadline is within one of its warning windows. Each window is claimed in
+// the store before the event is emitted, so a task is notified at most once
+// per window even across restarts or several indexer processes.
+type Notifier struct {
+	tasks   TaskSource
+	windows []time.Duration // ascending
+	emit    store.TransitionHook
+	now     func() time.Time
+}
+
+// NewNotifier creates a Notifier for the given warning windows (e.g. 24h
+// and 1h). emit receives each event; in production it is
+// store.HookedTaskRepo.Emit, which feeds every transition subscriber.
+func NewNotifier(tasks TaskSource, windows []time.Duration, emit store.TransitionHook) *Notifier {
+	ws := append([]time.Duration(nil), windows...)
+	sort.Slice(ws, func(i, j int) bool { return ws[i] < ws[j] })
+	return &Notifier{tasks: tasks, windows: ws, emit: emit, now: time.Now}
+}
+
+// Run scans once per interval until ctx is cancelled.
+//
+// Intended to be called as: go notifier.Run(ctx, interval)
+func (n *Notifier) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if _, err := n.ScanOnce(ctx); err != nil {
+			log.Printf("[deadline] scan failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// ScanOnce emits an event for every task that has entered a window it has
+// not been notified for, and returns how many it emitted. A task that enters
+// several windows at once, such as one accepted 30 minutes before its
+// deadline, gets a single event; all of those windows are claimed.
+func (n *Notifier) ScanOnce(ctx context.Context) (int, error) {
+	if len(n.windows) == 0 {
+		return 0, nil
+	}
+	now := n.now()
+	tasks, err := n.tasks.ListAcceptedTasksDueBetween(ctx, now, now.Add(n.windows[len(n.windows)-1]))
+	if err != nil {
+		return 0, err
+	}
+	emitted := 0
+	for _, t := range tasks {
+		remaining := time.Unix(t.DeadlineUnix, 0).Sub(now)
+		claimed := false
+		for _, w := range n.windows {
+			if remaining > w {
+				continue
+			}
+			ok, err := n.tasks.ClaimTaskNotification(ctx, t.TaskID, notificationKind, w)
+			if err != nil {
+				return emitted, fmt.Errorf("task %s: %w", t.TaskID, err)
+			}
+			claimed = claimed || ok
+		}
+		if claimed {
+			n.emit(ctx, store.TaskEventDeadlineApproaching, t)
+			emitted++
+		}
+	}
+	return emitted, nil
+}
@@ -0,0 +1,102 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+type claimKey struct {
+	taskID string
+	window time.Duration
+}
+
+type fakeTasks struct {
+	tasks  []*store.Task
+	claims map[claimKey]bool
+}
+
+func (f *fakeTasks) ListAcceptedTasksDueBetween(_ context.Context, from, to time.Time) ([]*store.Task, error) {
+	var out []*store.Task
+	for _, t := range f.tasks {
+		accepted := t.Status == store.TaskStatusAccepted || t.Status == store.TaskStatusAcceptedOnchain
+		if accepted && t.DeadlineUnix > from.Unix() && t.DeadlineUnix <= to.Unix() {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTasks) ClaimTaskNotification(_ context.Context, taskID, kind string, window time.Duration) (bool, error) {
+	k := claimKey{taskID, window}
+	if f.claims[k] {
+		return false, nil
+	}
+	f.claims[k] = true
+	return true, nil
+}
+
+func TestNotifier_OncePerWindow(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := &fakeTasks{claims: map[claimKey]bool{}, tasks: []*store.Task{
+		{TaskID: "due", Status: store.TaskStatusAccepted, DeadlineUnix: start.Add(30 * time.Hour).Unix()},
+		{TaskID: "open", Status: store.TaskStatusCreated, DeadlineUnix: start.Add(30 * time.Hour).Unix()},
+	}}
+	var events []string
+	n := NewNotifier(tasks, []time.Duration{time.Hour, 24 * time.Hour}, func(_ context.Context, event string, task *store.Task) {
+		if event != store.TaskEventDeadlineApproaching {
+			t.Errorf("event = %q", event)
+		}
+		events = append(events, task.TaskID)
+	})
+	clock := start
+	n.now = func() time.Time { return clock }
+
+	for _, step := range []struct {
+		at   time.Duration // since start
+		want int
+	}{
+		{0, 0},                             // 30h left: outside every window
+		{7 * time.Hour, 1},                 // 23h left: enters 24h
+		{7*time.Hour + time.Minute, 0},     // still 24h: already notified
+		{29*time.Hour + 30*time.Minute, 1}, // 30m left: enters 1h
+		{29*time.Hour + 45*time.Minute, 0},
+		{31 * time.Hour, 0}, // past the deadline
+	} {
+		clock = start.Add(step.at)
+		got, err := n.ScanOnce(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Errorf("at +%s: emitted %d, want %d", step.at, got, step.want)
+		}
+	}
+	if len(events) != 2 || events[0] != "due" || events[1] != "due" {
+		t.Errorf("events = %v, want due twice", events)
+	}
+}
+
+func TestNotifier_LateAcceptGetsOneEvent(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := &fakeTasks{claims: map[claimKey]bool{}, tasks: []*store.Task{
+		{TaskID: "late", Status: store.TaskStatusAcceptedOnchain, DeadlineUnix: now.Add(30 * time.Minute).Unix()},
+	}}
+	emitted := 0
+	n := NewNotifier(tasks, []time.Duration{24 * time.Hour, time.Hour}, func(context.Context, string, *store.Task) { emitted++ })
+	n.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := n.ScanOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if emitted != 1 {
+		t.Errorf("emitted %d events, want 1", emitted)
+	}
+	if !tasks.claims[claimKey{"late", time.Hour}] || !tasks.claims[claimKey{"late", 24 * time.Hour}] {
+		t.Errorf("claims = %v, want both windows claimed", tasks.claims)
+	}
+}
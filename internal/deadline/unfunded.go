@@ -0,0 +1,68 @@
+package deadline
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/audit"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// UnfundedSource is the subset of store.TaskRepo used by the Reverter.
+type UnfundedSource interface {
+	RevertUnfundedAccepts(ctx context.Context, acceptedBefore time.Time) ([]store.UnfundedAccept, error)
+	audit.Sink
+}
+
+// Reverter releases workers from tasks whose employer never funded the
+// escrow: tasks accepted off-chain more than timeout ago and never seen
+// onchain go back to created. The store.TaskEventAcceptReverted event is
+// fired by store.HookedTaskRepo; each revert is also audited.
+type Reverter struct {
+	tasks   UnfundedSource
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// NewReverter creates a Reverter for AMN_UNFUNDED_ACCEPT_TIMEOUT_SECONDS.
+func NewReverter(tasks UnfundedSource, timeout time.Duration) *Reverter {
+	return &Reverter{tasks: tasks, timeout: timeout, now: time.Now}
+}
+
+// Run scans once per interval until ctx is cancelled.
+//
+// Intended to be called as: go reverter.Run(ctx, interval)
+func (r *Reverter) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if _, err := r.ScanOnce(ctx); err != nil {
+			log.Printf("[unfunded] scan failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// ScanOnce reverts every task that has waited longer than the timeout and
+// returns how many it reverted.
+func (r *Reverter) ScanOnce(ctx context.Context) (int, error) {
+	reverted, err := r.tasks.RevertUnfundedAccepts(ctx, r.now().Add(-r.timeout))
+	for _, u := range reverted {
+		log.Printf("[unfunded] taskID=%s employer=%s accepted at %s never funded: accepted -> created", u.TaskID, u.EmployerAddress, u.AcceptedAt.Format(time.RFC3339))
+		chainID := u.ChainID
+		if err := audit.Record(ctx, r.tasks, audit.AcceptReverted, &chainID, map[string]any{
+			"task_id":          u.TaskID,
+			"employer_address": u.EmployerAddress,
+			"worker_address":   u.WorkerAddress,
+			"accepted_at":      u.AcceptedAt,
+		}); err != nil {
+			log.Printf("[unfunded] audit %s: %v", u.TaskID, err)
+		}
+	}
+	return len(reverted), err
+}
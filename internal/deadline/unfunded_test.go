@@ -0,0 +1,66 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// fakeUnfunded holds accepted tasks keyed by task_id with their accept time.
+type fakeUnfunded struct {
+	accepted map[string]time.Time
+	audits   []*store.AuditEvent
+}
+
+func (f *fakeUnfunded) RevertUnfundedAccepts(_ context.Context, acceptedBefore time.Time) ([]store.UnfundedAccept, error) {
+	var out []store.UnfundedAccept
+	for id, at := range f.accepted {
+		if at.Before(acceptedBefore) {
+			out = append(out, store.UnfundedAccept{TaskID: id, ChainID: 1, EmployerAddress: "0xe", AcceptedAt: at})
+			delete(f.accepted, id)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeUnfunded) InsertAuditEvent(_ context.Context, e *store.AuditEvent) error {
+	f.audits = append(f.audits, e)
+	return nil
+}
+
+func TestReverter_AfterTimeout(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := &fakeUnfunded{accepted: map[string]time.Time{
+		"early": start,
+		"late":  start.Add(12 * time.Hour),
+	}}
+	r := NewReverter(tasks, 24*time.Hour)
+	clock := start
+	r.now = func() time.Time { return clock }
+
+	for _, step := range []struct {
+		at   time.Duration // since start
+		want int
+	}{
+		{0, 0},
+		{24 * time.Hour, 0},             // exactly at the timeout: not yet
+		{24*time.Hour + time.Minute, 1}, // early
+		{30 * time.Hour, 0},
+		{36*time.Hour + time.Minute, 1}, // late
+		{72 * time.Hour, 0},
+	} {
+		clock = start.Add(step.at)
+		n, err := r.ScanOnce(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != step.want {
+			t.Errorf("at +%s: reverted %d, want %d", step.at, n, step.want)
+		}
+	}
+	if len(tasks.audits) != 2 || tasks.audits[0].Type != "accept_reverted" || tasks.audits[0].Detail["task_id"] != "early" {
+		t.Errorf("audits = %+v", tasks.audits)
+	}
+}
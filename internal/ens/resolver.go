@@ -0,0 +1,242 @@
+// Package ens resolves Ethereum addresses to their primary ENS names for
+// display in API responses.
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/memlimit"
+)
+
+// RegistryAddress is the ENS registry on Ethereum mainnet.
+var RegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// CacheTTL is how long a lookup result (including "no name") is reused.
+const CacheTTL = time.Hour
+
+// lookupTimeout bounds a background lookup started by Name.
+const lookupTimeout = 10 * time.Second
+
+const registryABIJSON = `[
+  {"inputs":[{"name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+const resolverABIJSON = `[
+  {"inputs":[{"name":"node","type":"bytes32"}],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+  {"inputs":[{"name":"node","type":"bytes32"}],"name":"addr","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+var (
+	registryABI = mustParseABI(registryABIJSON)
+	resolverABI = mustParseABI(resolverABIJSON)
+)
+
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// ErrNoName is returned when an address has no primary name, or its reverse
+// record does not resolve back to the address.
+var ErrNoName = errors.New("ens: no primary name")
+
+type cacheEntry struct {
+	name    string // empty: no name
+	expires time.Time
+}
+
+// Resolver performs ENS reverse resolution with forward verification and
+// caches results for CacheTTL.
+type Resolver struct {
+	client   ethereum.ContractCaller
+	registry common.Address
+	now      func() time.Time
+
+	cache    sync.Map // lower-case address -> cacheEntry
+	inflight sync.Map // lower-case address -> struct{}
+}
+
+// NewResolver creates a Resolver using client, which must be connected to
+// Ethereum mainnet.
+func NewResolver(client ethereum.ContractCaller) *Resolver {
+	return &Resolver{client: client, registry: RegistryAddress, now: time.Now}
+}
+
+// Dial connects to a mainnet RPC endpoint and returns a Resolver.
+func Dial(ctx context.Context, rpcURL string) (*Resolver, error) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("ens: dial: %w", err)
+	}
+	return NewResolver(client), nil
+}
+
+// LookupAddress returns the primary ENS name of address. The reverse record
+// must resolve forward to the same address, otherwise ErrNoName is returned.
+// Results are cached.
+func (r *Resolver) LookupAddress(ctx context.Context, address string) (string, error) {
+	key := strings.ToLower(address)
+	if e, ok := r.cache.Load(key); ok && r.now().Before(e.(cacheEntry).expires) {
+		if e.(cacheEntry).name == "" {
+			return "", ErrNoName
+		}
+		return e.(cacheEntry).name, nil
+	}
+
+	name, err := r.lookup(ctx, common.HexToAddress(address))
+	if err != nil && !errors.Is(err, ErrNoName) {
+		return "", err
+	}
+	r.cache.Store(key, cacheEntry{name: name, expires: r.now().Add(CacheTTL)})
+	if name == "" {
+		return "", ErrNoName
+	}
+	return name, nil
+}
+
+// cacheEntryBytes approximates the memory of one cache entry besides the
+// name: the sync.Map node and a lower-case address key.
+const cacheEntryBytes = 160
+
+// Size returns the approximate bytes held by the name cache.
+func (r *Resolver) Size() int64 {
+	var n int64
+	r.cache.Range(func(_, v any) bool {
+		n += cacheEntryBytes + int64(len(v.(cacheEntry).name))
+		return true
+	})
+	return n
+}
+
+// Evict drops about fraction of the cached names (memlimit.Cache), expired
+// ones first. Dropped names are looked up again on the next request.
+func (r *Resolver) Evict(fraction float64) int {
+	var keys, expired []any
+	now := r.now()
+	r.cache.Range(func(k, v any) bool {
+		if now.Before(v.(cacheEntry).expires) {
+			keys = append(keys, k)
+		} else {
+			expired = append(expired, k)
+		}
+		return true
+	})
+	drop := memlimit.EvictCount(len(keys)+len(expired), fraction)
+	victims := append(expired, keys...)[:drop]
+	for _, k := range victims {
+		r.cache.Delete(k)
+	}
+	return len(victims)
+}
+
+// Name returns the cached name for address without blocking. On a cache miss
+// it starts a background lookup and returns "", so the name appears on a
+// later request.
+func (r *Resolver) Name(address string) string {
+	if !common.IsHexAddress(address) {
+		return ""
+	}
+	key := strings.ToLower(address)
+	if e, ok := r.cache.Load(key); ok && r.now().Before(e.(cacheEntry).expires) {
+		return e.(cacheEntry).name
+	}
+	if _, busy := r.inflight.LoadOrStore(key, struct{}{}); !busy {
+		go func() {
+			defer r.inflight.Delete(key)
+			ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+			defer cancel()
+			if _, err := r.LookupAddress(ctx, key); err != nil && !errors.Is(err, ErrNoName) {
+				log.Printf("[ens] lookup %s: %v", key, err)
+			}
+		}()
+	}
+	return ""
+}
+
+func (r *Resolver) lookup(ctx context.Context, addr common.Address) (string, error) {
+	reverseNode := Namehash(strings.ToLower(addr.Hex()[2:]) + ".addr.reverse")
+	resolver, err := r.resolverOf(ctx, reverseNode)
+	if err != nil {
+		return "", err
+	}
+	var name string
+	if err := r.call(ctx, resolverABI, resolver, "name", reverseNode, &name); err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", ErrNoName
+	}
+
+	// Forward verification: anyone can set a reverse record claiming any name.
+	node := Namehash(name)
+	fwdResolver, err := r.resolverOf(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	var resolved common.Address
+	if err := r.call(ctx, resolverABI, fwdResolver, "addr", node, &resolved); err != nil {
+		return "", err
+	}
+	if resolved != addr {
+		return "", ErrNoName
+	}
+	return name, nil
+}
+
+func (r *Resolver) resolverOf(ctx context.Context, node common.Hash) (common.Address, error) {
+	var resolver common.Address
+	if err := r.call(ctx, registryABI, r.registry, "resolver", node, &resolver); err != nil {
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, ErrNoName
+	}
+	return resolver, nil
+}
+
+func (r *Resolver) call(ctx context.Context, contract abi.ABI, to common.Address, method string, node common.Hash, out any) error {
+	input, err := contract.Pack(method, node)
+	if err != nil {
+		return fmt.Errorf("ens: pack %s: %w", method, err)
+	}
+	res, err := r.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: input}, nil)
+	if err != nil {
+		return fmt.Errorf("ens: call %s: %w", method, err)
+	}
+	if len(res) == 0 {
+		return ErrNoName
+	}
+	if err := contract.UnpackIntoInterface(out, method, res); err != nil {
+		return fmt.Errorf("ens: unpack %s: %w", method, err)
+	}
+	return nil
+}
+
+// Namehash implements the ENS namehash algorithm (EIP-137). name must already
+// be normalised.
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = common.BytesToHash(crypto.Keccak256(node[:], crypto.Keccak256([]byte(labels[i]))))
+	}
+	return node
+}
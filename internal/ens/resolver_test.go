@@ -0,0 +1,145 @@
+package ens
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	user         = common.HexToAddress("0x00000000000000000000000000000000000000e1")
+	resolverAddr = common.HexToAddress("0x00000000000000000000000000000000000000f1")
+)
+
+// fakeENS emulates the registry and a single public resolver.
+type fakeENS struct {
+	names     map[common.Hash]string         // reverse node -> name
+	addrs     map[common.Hash]common.Address // forward node -> address
+	resolvers map[common.Hash]common.Address // node -> resolver
+	calls     atomic.Int32
+}
+
+func (f *fakeENS) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.calls.Add(1)
+	var contract abi.ABI
+	if *msg.To == RegistryAddress {
+		contract = registryABI
+	} else {
+		contract = resolverABI
+	}
+	method, err := contract.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	node := common.Hash(args[0].([32]byte))
+	switch method.Name {
+	case "resolver":
+		return method.Outputs.Pack(f.resolvers[node])
+	case "name":
+		return method.Outputs.Pack(f.names[node])
+	case "addr":
+		return method.Outputs.Pack(f.addrs[node])
+	}
+	return nil, errors.New("unexpected method")
+}
+
+func newFake(name string, forward common.Address) *fakeENS {
+	reverse := Namehash(common.Bytes2Hex(user.Bytes()) + ".addr.reverse")
+	return &fakeENS{
+		names:     map[common.Hash]string{reverse: name},
+		addrs:     map[common.Hash]common.Address{Namehash(name): forward},
+		resolvers: map[common.Hash]common.Address{reverse: resolverAddr, Namehash(name): resolverAddr},
+	}
+}
+
+func TestNamehash(t *testing.T) {
+	// Vectors from EIP-137.
+	if got := Namehash(""); got != (common.Hash{}) {
+		t.Errorf("namehash('') = %s", got.Hex())
+	}
+	if got, want := Namehash("eth").Hex(), "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"; got != want {
+		t.Errorf("namehash(eth) = %s, want %s", got, want)
+	}
+	if got, want := Namehash("foo.eth").Hex(), "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"; got != want {
+		t.Errorf("namehash(foo.eth) = %s, want %s", got, want)
+	}
+}
+
+func TestLookupAddress(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("verified", func(t *testing.T) {
+		r := NewResolver(newFake("alice.eth", user))
+		name, err := r.LookupAddress(ctx, user.Hex())
+		if err != nil || name != "alice.eth" {
+			t.Fatalf("got %q, %v", name, err)
+		}
+	})
+
+	t.Run("forward_mismatch", func(t *testing.T) {
+		r := NewResolver(newFake("mallory.eth", common.HexToAddress("0x01")))
+		if _, err := r.LookupAddress(ctx, user.Hex()); !errors.Is(err, ErrNoName) {
+			t.Fatalf("expected ErrNoName, got %v", err)
+		}
+	})
+
+	t.Run("no_reverse_record", func(t *testing.T) {
+		r := NewResolver(&fakeENS{})
+		if _, err := r.LookupAddress(ctx, user.Hex()); !errors.Is(err, ErrNoName) {
+			t.Fatalf("expected ErrNoName, got %v", err)
+		}
+	})
+
+	t.Run("cached_for_ttl", func(t *testing.T) {
+		fake := newFake("alice.eth", user)
+		r := NewResolver(fake)
+		now := time.Now()
+		r.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			if _, err := r.LookupAddress(ctx, user.Hex()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// registry.resolver + name, then registry.resolver + addr.
+		if got := fake.calls.Load(); got != 4 {
+			t.Fatalf("expected 4 RPC calls for one lookup, got %d", got)
+		}
+
+		now = now.Add(CacheTTL + time.Second)
+		if _, err := r.LookupAddress(ctx, user.Hex()); err != nil {
+			t.Fatal(err)
+		}
+		if fake.calls.Load() != 8 {
+			t.Errorf("expected a fresh lookup after TTL, calls = %d", fake.calls.Load())
+		}
+	})
+}
+
+func TestName_BackgroundResolution(t *testing.T) {
+	r := NewResolver(newFake("alice.eth", user))
+	if got := r.Name(user.Hex()); got != "" {
+		t.Fatalf("first call should miss, got %q", got)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for r.Name(user.Hex()) != "alice.eth" {
+		if time.Now().After(deadline) {
+			t.Fatal("background lookup did not populate the cache")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := r.Name("not-an-address"); got != "" {
+		t.Errorf("invalid address resolved to %q", got)
+	}
+}
@@ -40,13 +40,26 @@ func eip191PersonalSignHash(msgHash []byte) []byte {
 	return Keccak256(full)
 }
 
+// PersonalSignHash returns the digest a personal_sign signature over msgHash
+// actually signs, keccak256 of the prefixed hash.
+func PersonalSignHash(msgHash []byte) []byte {
+	return eip191PersonalSignHash(msgHash)
+}
+
 // RecoverPersonalSign recovers the signer address from an EIP-191
 // personal_sign signature over msgHash (the pre-computed message hash,
 // i.e. keccak256(message)).
 //
 // sig must be 0x-prefixed hex of the 65-byte [R||S||V] signature as
-// produced by MetaMask/ethers signMessage.
+// produced by MetaMask/ethers signMessage. V must be 0, 1, 27 or 28.
 func RecoverPersonalSign(msgHash []byte, sig string) (string, error) {
+	return RecoverPersonalSignWithChainID(msgHash, sig, 0)
+}
+
+// RecoverPersonalSignWithChainID is RecoverPersonalSign that additionally
+// accepts an EIP-155-style V (chainID*2+35 or chainID*2+36) when chainID > 0.
+// Only chain IDs whose adjusted V fits in the single V byte can be used.
+func RecoverPersonalSignWithChainID(msgHash []byte, sig string, chainID uint64) (string, error) {
 	sigBytes, err := decodeHex(sig)
 	if err != nil {
 		return "", fmt.Errorf("%w: %v", ErrInvalidSignature, err)
@@ -55,11 +68,13 @@ func RecoverPersonalSign(msgHash []byte, sig string) (string, error) {
 		return "", fmt.Errorf("%w: expected 65 bytes, got %d", ErrInvalidSignature, len(sigBytes))
 	}
 
-	// Normalise V: Ethereum personal_sign uses V=27/28; crypto.SigToPub expects V=0/1.
+	// Normalise V: crypto.SigToPub expects a recovery id of 0/1.
 	sigBytes = append([]byte(nil), sigBytes...) // copy
-	if sigBytes[64] >= 27 {
-		sigBytes[64] -= 27
+	recID, err := normalizeV(sigBytes[64], chainID)
+	if err != nil {
+		return "", err
 	}
+	sigBytes[64] = recID
 
 	prefixedHash := eip191PersonalSignHash(msgHash)
 
@@ -72,6 +87,26 @@ func RecoverPersonalSign(msgHash []byte, sig string) (string, error) {
 	return strings.ToLower(addr.Hex()), nil
 }
 
+// normalizeV maps a signature V byte to a 0/1 recovery id. Accepted values are
+// 0/1 (raw), 27/28 (Ethereum personal_sign) and, when chainID > 0, the
+// EIP-155 values chainID*2+35/36.
+func normalizeV(v byte, chainID uint64) (byte, error) {
+	switch v {
+	case 0, 1:
+		return v, nil
+	case 27, 28:
+		return v - 27, nil
+	}
+	if chainID > 0 {
+		if base := chainID*2 + 35; uint64(v) == base || uint64(v) == base+1 {
+			return byte(uint64(v) - base), nil
+		}
+		return 0, fmt.Errorf("%w: unsupported v=%d (accepted: 0, 1, 27, 28, %d, %d)",
+			ErrInvalidSignature, v, chainID*2+35, chainID*2+36)
+	}
+	return 0, fmt.Errorf("%w: unsupported v=%d (accepted: 0, 1, 27, 28)", ErrInvalidSignature, v)
+}
+
 // VerifyPersonalSign verifies that signature was produced by the owner of
 // expectedAddress over keccak256(message).
 //
@@ -2,7 +2,9 @@ package ethutil_test
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -123,3 +125,58 @@ func TestKeccak256Hex(t *testing.T) {
 		t.Fatalf("Keccak256Hex(\"\") = %s, want %s", got, want)
 	}
 }
+
+func TestRecoverPersonalSign_VClasses(t *testing.T) {
+	key, addr := genKey(t)
+	message := []byte("task-v-classes")
+	msgHash := ethutil.Keccak256(message)
+
+	sig27 := personalSign(t, key, message) // V = 27/28
+	raw, err := hex.DecodeString(strings.TrimPrefix(sig27, "0x"))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	recID := raw[64] - 27
+
+	withV := func(v byte) string {
+		b := append([]byte(nil), raw...)
+		b[64] = v
+		return "0x" + hex.EncodeToString(b)
+	}
+
+	cases := []struct {
+		name    string
+		v       byte
+		chainID uint64
+		ok      bool
+	}{
+		{"raw_0_1", recID, 0, true},
+		{"personal_27_28", recID + 27, 0, true},
+		{"v_2_rejected", 2, 0, false},
+		{"v_26_rejected", 26, 0, false},
+		{"eip155_without_chain_rejected", recID + 37, 0, false},
+		{"eip155_chain_1", recID + 37, 1, true},
+		{"eip155_wrong_chain_rejected", recID + 37, 5, false},
+		{"v_255_rejected", 255, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ethutil.RecoverPersonalSignWithChainID(msgHash, withV(tc.v), tc.chainID)
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("expected success, got %v", err)
+				}
+				if !strings.EqualFold(got, addr) {
+					t.Fatalf("recovered %s, want %s", got, addr)
+				}
+				return
+			}
+			if !errors.Is(err, ethutil.ErrInvalidSignature) {
+				t.Fatalf("expected ErrInvalidSignature, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "v=") {
+				t.Errorf("error should name the observed v: %v", err)
+			}
+		})
+	}
+}
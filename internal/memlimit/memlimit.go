@@ -0,0 +1,177 @@
+// Package memlimit keeps the indexer's in-memory caches (client rate-limit
+// buckets, read-auth challenges, ENS names, ...) within a shared byte budget.
+//
+// Each cache registers with its approximate size. A janitor periodically
+// sums the sizes and, when the total exceeds the budget or the Go heap
+// exceeds a soft limit, asks every cache to drop the same fraction of its
+// entries, so larger caches give back more memory. Caches keep their own
+// hard caps; memlimit only shrinks them earlier under pressure.
+package memlimit
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+// Cache is an in-memory structure the janitor can shrink. Implementations
+// must be safe for concurrent use.
+type Cache interface {
+	// Size returns the approximate bytes held.
+	Size() int64
+	// Evict drops about fraction (0, 1] of the entries, preferring ones that
+	// are cheapest to lose, and returns how many it dropped.
+	Evict(fraction float64) int
+}
+
+// Interval is how often cmd/indexer runs the Default janitor.
+const Interval = 10 * time.Second
+
+// minEvictFraction keeps a pass that is only just over budget from evicting
+// a handful of entries at a time.
+const minEvictFraction = 0.1
+
+var evictions = metrics.NewCounterVec("amn_memlimit_evictions_total",
+	"Cache entries dropped under memory pressure, by cache.", "cache")
+
+// Janitor holds the registered caches and the limits they share.
+type Janitor struct {
+	mu        sync.Mutex
+	caches    map[string]Cache
+	budget    int64  // bytes across caches; 0 = no budget
+	heapLimit uint64 // bytes of Go heap; 0 = not checked
+
+	heapAlloc func() uint64
+}
+
+// Default is the process-wide janitor. Caches register with it where they are
+// built; cmd/indexer sets its limits and runs it.
+var Default = New(0, 0)
+
+// New returns a Janitor with the given cache byte budget and heap soft limit.
+// Either may be 0 to disable it.
+func New(budget int64, heapLimit uint64) *Janitor {
+	return &Janitor{caches: map[string]Cache{}, budget: budget, heapLimit: heapLimit, heapAlloc: readHeapAlloc}
+}
+
+// SetLimits replaces the budget and heap soft limit.
+func (j *Janitor) SetLimits(budget int64, heapLimit uint64) {
+	j.mu.Lock()
+	j.budget, j.heapLimit = budget, heapLimit
+	j.mu.Unlock()
+}
+
+// Register adds c under name, replacing any cache registered with that name.
+func (j *Janitor) Register(name string, c Cache) {
+	j.mu.Lock()
+	j.caches[name] = c
+	j.mu.Unlock()
+}
+
+// Register adds c to the Default janitor.
+func Register(name string, c Cache) { Default.Register(name, c) }
+
+// Sizes returns the current size of every registered cache.
+func (j *Janitor) Sizes() map[string]int64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	out := make(map[string]int64, len(j.caches))
+	for name, c := range j.caches {
+		out[name] = c.Size()
+	}
+	return out
+}
+
+// Check runs one pass: if the caches exceed the budget, or the heap exceeds
+// the soft limit, every cache evicts the fraction by which the larger of the
+// two is over. It returns the entries evicted per cache.
+func (j *Janitor) Check() map[string]int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var total int64
+	sizes := make(map[string]int64, len(j.caches))
+	for name, c := range j.caches {
+		sizes[name] = c.Size()
+		total += sizes[name]
+	}
+	var fraction float64
+	if j.budget > 0 && total > j.budget {
+		fraction = float64(total-j.budget) / float64(total)
+	}
+	if j.heapLimit > 0 {
+		if heap := j.heapAlloc(); heap > j.heapLimit {
+			fraction = max(fraction, float64(heap-j.heapLimit)/float64(heap))
+		}
+	}
+	if fraction == 0 || total == 0 {
+		return nil
+	}
+	fraction = min(max(fraction, minEvictFraction), 1)
+
+	out := make(map[string]int, len(j.caches))
+	for name, c := range j.caches {
+		if sizes[name] == 0 {
+			continue
+		}
+		n := c.Evict(fraction)
+		out[name] = n
+		evictions.Add(float64(n), name)
+	}
+	return out
+}
+
+// Run calls Check every interval until ctx is done.
+func (j *Janitor) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			j.Check()
+		}
+	}
+}
+
+// RegisterMetrics exposes per-cache sizes of the Default janitor.
+func RegisterMetrics() {
+	metrics.Register(metrics.GaugeFunc{
+		Name: "amn_memlimit_cache_bytes",
+		Help: "Approximate bytes held by each memory-limited cache.",
+		Fn: func() []metrics.Sample {
+			sizes := Default.Sizes()
+			names := make([]string, 0, len(sizes))
+			for name := range sizes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			out := make([]metrics.Sample, len(names))
+			for i, name := range names {
+				out[i] = metrics.Sample{Labels: map[string]string{"cache": name}, Value: float64(sizes[name])}
+			}
+			return out
+		},
+	})
+}
+
+func readHeapAlloc() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// EvictCount returns how many of n entries to drop for fraction, at least
+// one when n > 0.
+func EvictCount(n int, fraction float64) int {
+	if n == 0 {
+		return 0
+	}
+	k := int(float64(n)*fraction + 0.5)
+	return min(max(k, 1), n)
+}
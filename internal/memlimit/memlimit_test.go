@@ -0,0 +1,104 @@
+package memlimit
+
+import (
+	"sync"
+	"testing"
+)
+
+// sliceCache holds entries of a fixed size.
+type sliceCache struct {
+	mu        sync.Mutex
+	entries   int
+	entrySize int64
+}
+
+func (c *sliceCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(c.entries) * c.entrySize
+}
+
+func (c *sliceCache) Evict(fraction float64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := EvictCount(c.entries, fraction)
+	c.entries -= n
+	return n
+}
+
+func TestJanitor_UnderBudgetEvictsNothing(t *testing.T) {
+	j := New(1000, 0)
+	c := &sliceCache{entries: 10, entrySize: 100}
+	j.Register("c", c)
+	if got := j.Check(); got != nil || c.entries != 10 {
+		t.Errorf("Check = %v, entries = %d", got, c.entries)
+	}
+}
+
+func TestJanitor_ProportionalEviction(t *testing.T) {
+	j := New(1000, 0)
+	big := &sliceCache{entries: 300, entrySize: 10}   // 3000 bytes
+	small := &sliceCache{entries: 100, entrySize: 10} // 1000 bytes
+	j.Register("big", big)
+	j.Register("small", small)
+
+	// 4000 bytes against a 1000 byte budget: both drop 75% of entries.
+	got := j.Check()
+	if got["big"] != 225 || got["small"] != 75 {
+		t.Fatalf("evicted = %v, want big 225 small 75", got)
+	}
+	if sum := big.Size() + small.Size(); sum > 1000 {
+		t.Errorf("after Check caches hold %d bytes, budget 1000", sum)
+	}
+	if v := evictions.Value("big"); v < 225 {
+		t.Errorf("amn_memlimit_evictions_total{cache=big} = %v", v)
+	}
+}
+
+func TestJanitor_GrowthPastBudget(t *testing.T) {
+	const budget = 10000
+	j := New(budget, 0)
+	c := &sliceCache{entrySize: 64}
+	j.Register("c", c)
+	for i := 0; i < 50; i++ {
+		c.mu.Lock()
+		c.entries += 100
+		c.mu.Unlock()
+		j.Check()
+		if size := c.Size(); size > budget {
+			t.Fatalf("round %d: %d bytes held after Check, budget %d", i, size, budget)
+		}
+	}
+}
+
+func TestJanitor_HeapSoftLimit(t *testing.T) {
+	j := New(0, 1000)
+	heap := uint64(500)
+	j.heapAlloc = func() uint64 { return heap }
+	c := &sliceCache{entries: 100, entrySize: 1}
+	j.Register("c", c)
+
+	if got := j.Check(); got != nil {
+		t.Fatalf("under heap limit: evicted %v", got)
+	}
+	heap = 2000 // half the heap is over the limit
+	if got := j.Check(); got["c"] != 50 {
+		t.Errorf("over heap limit: evicted %v, want 50", got)
+	}
+	heap = 1010 // barely over: at least minEvictFraction
+	if got := j.Check(); got["c"] != 5 {
+		t.Errorf("barely over: evicted %v, want 5 of 50", got)
+	}
+}
+
+func TestEvictCount(t *testing.T) {
+	for _, tc := range []struct {
+		n        int
+		fraction float64
+		want     int
+	}{{0, 0.5, 0}, {1, 0.01, 1}, {10, 0.25, 3}, {10, 1, 10}, {10, 2, 10}} {
+		if got := EvictCount(tc.n, tc.fraction); got != tc.want {
+			t.Errorf("EvictCount(%d, %v) = %d, want %d", tc.n, tc.fraction, got, tc.want)
+		}
+	}
+}
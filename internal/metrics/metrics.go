@@ -0,0 +1,174 @@
+// Package metrics is a minimal Prometheus text-format metrics registry.
+//
+// It supports labelled counters and pull-based collectors, which is all the
+// indexer needs; it deliberately avoids the full client_golang dependency.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Sample is a single metric value with its labels.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Collector produces gauge samples at scrape time.
+type Collector interface {
+	// Describe returns the metric name and help text.
+	Describe() (name, help string)
+	// Collect returns the current samples.
+	Collect() []Sample
+}
+
+// Registry holds counters and collectors.
+type Registry struct {
+	mu         sync.Mutex
+	counters   []*CounterVec
+	collectors []Collector
+}
+
+// Default is the process-wide registry served on /metrics.
+var Default = &Registry{}
+
+// CounterVec is a monotonically increasing counter partitioned by labels.
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]float64 // key: label values joined by \xff
+}
+
+// NewCounterVec creates and registers a counter in the Default registry.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labelNames...)
+}
+
+// NewCounterVec creates and registers a counter in r.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labelNames: labelNames, values: map[string]float64{}}
+	r.mu.Lock()
+	r.counters = append(r.counters, c)
+	r.mu.Unlock()
+	return c
+}
+
+// Inc increments the counter for the given label values (one per label name).
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter for the given label values.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+// Value returns the current value for the given label values.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+// Register adds a collector to the Default registry.
+func Register(c Collector) {
+	Default.Register(c)
+}
+
+// Register adds a collector to r.
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mu.Unlock()
+}
+
+// WriteText writes all metrics in Prometheus text exposition format.
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.Lock()
+	counters := append([]*CounterVec(nil), r.counters...)
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+		c.mu.Lock()
+		keys := make([]string, 0, len(c.values))
+		for k := range c.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			labels := map[string]string{}
+			if len(c.labelNames) > 0 {
+				for i, v := range strings.Split(k, "\xff") {
+					if i < len(c.labelNames) {
+						labels[c.labelNames[i]] = v
+					}
+				}
+			}
+			writeSample(w, c.name, Sample{Labels: labels, Value: c.values[k]})
+		}
+		c.mu.Unlock()
+	}
+
+	// Collectors sharing a name (e.g. one per chain watcher) are grouped under
+	// a single HELP/TYPE header.
+	var names []string
+	helps := map[string]string{}
+	samples := map[string][]Sample{}
+	for _, c := range collectors {
+		name, help := c.Describe()
+		if _, ok := helps[name]; !ok {
+			names = append(names, name)
+			helps[name] = help
+		}
+		samples[name] = append(samples[name], c.Collect()...)
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, helps[name], name)
+		for _, s := range samples[name] {
+			writeSample(w, name, s)
+		}
+	}
+}
+
+func writeSample(w io.Writer, name string, s Sample) {
+	if len(s.Labels) == 0 {
+		fmt.Fprintf(w, "%s %g\n", name, s.Value)
+		return
+	}
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%q", k, s.Labels[k])
+	}
+	fmt.Fprintf(w, "%s{%s} %g\n", name, strings.Join(parts, ","), s.Value)
+}
+
+// GaugeFunc adapts a function to a Collector.
+type GaugeFunc struct {
+	Name string
+	Help string
+	Fn   func() []Sample
+}
+
+// Describe implements Collector.
+func (g GaugeFunc) Describe() (string, string) { return g.Name, g.Help }
+
+// Collect implements Collector.
+func (g GaugeFunc) Collect() []Sample { return g.Fn() }
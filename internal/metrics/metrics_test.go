@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := &Registry{}
+	c := r.NewCounterVec("amn_test_total", "Test counter.", "kind")
+	c.Inc("a")
+	c.Add(2, "b")
+	c.Inc("a")
+
+	for _, chain := range []string{"1", "2"} {
+		chain := chain
+		r.Register(GaugeFunc{Name: "amn_test_gauge", Help: "Test gauge.", Fn: func() []Sample {
+			return []Sample{{Labels: map[string]string{"chain_id": chain}, Value: 1.5}}
+		}})
+	}
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+
+	want := `# HELP amn_test_total Test counter.
+# TYPE amn_test_total counter
+amn_test_total{kind="a"} 2
+amn_test_total{kind="b"} 2
+# HELP amn_test_gauge Test gauge.
+# TYPE amn_test_gauge gauge
+amn_test_gauge{chain_id="1"} 1.5
+amn_test_gauge{chain_id="2"} 1.5
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	if c.Value("a") != 2 {
+		t.Errorf("Value(a) = %v, want 2", c.Value("a"))
+	}
+}
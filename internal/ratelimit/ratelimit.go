@@ -0,0 +1,134 @@
+// Package ratelimit provides a small token-bucket rate limiter.
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/memlimit"
+)
+
+// Limiter is a token bucket that refills at a fixed rate up to burst tokens.
+// It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewLimiter creates a Limiter allowing ratePerSecond events on average with
+// bursts of up to burst events. The bucket starts full.
+func NewLimiter(ratePerSecond float64, burst int) *Limiter {
+	return &Limiter{
+		rate:   ratePerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// PerMinute creates a Limiter allowing n events per minute with a burst of n.
+func PerMinute(n int) *Limiter {
+	return NewLimiter(float64(n)/60, n)
+}
+
+// Allow reports whether an event may happen now, consuming a token if so.
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Keyed holds a separate PerMinute bucket for every key, e.g. one per client.
+// To bound memory it holds at most maxKeys buckets, forgetting the least
+// recently used one to make room, so only the idlest client starts over with
+// a full bucket. It is safe for concurrent use.
+type Keyed struct {
+	mu        sync.Mutex
+	perMinute int
+	maxKeys   int
+	order     *list.List // of *keyedBucket, most recently used first
+	buckets   map[string]*list.Element
+	now       func() time.Time
+}
+
+type keyedBucket struct {
+	key string
+	l   *Limiter
+}
+
+// NewKeyed creates a Keyed limiter allowing perMinute events per key.
+func NewKeyed(perMinute, maxKeys int) *Keyed {
+	return &Keyed{perMinute: perMinute, maxKeys: maxKeys, order: list.New(), buckets: make(map[string]*list.Element), now: time.Now}
+}
+
+// Allow reports whether an event for key may happen now.
+func (k *Keyed) Allow(key string) bool {
+	k.mu.Lock()
+	el, ok := k.buckets[key]
+	if ok {
+		k.order.MoveToFront(el)
+	} else {
+		for k.order.Len() >= max(k.maxKeys, 1) {
+			k.remove(k.order.Back())
+		}
+		l := PerMinute(k.perMinute)
+		l.now = k.now
+		el = k.order.PushFront(&keyedBucket{key: key, l: l})
+		k.buckets[key] = el
+	}
+	l := el.Value.(*keyedBucket).l
+	k.mu.Unlock()
+	return l.Allow()
+}
+
+func (k *Keyed) remove(el *list.Element) {
+	k.order.Remove(el)
+	delete(k.buckets, el.Value.(*keyedBucket).key)
+}
+
+// keyedBucketBytes approximates the memory of one Keyed bucket besides its
+// key: the map entry, the list element and the Limiter.
+const keyedBucketBytes = 192
+
+// Size returns the approximate bytes held by k's buckets.
+func (k *Keyed) Size() int64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	var n int64
+	for key := range k.buckets {
+		n += int64(len(key)) + keyedBucketBytes
+	}
+	return n
+}
+
+// Evict forgets about fraction of the buckets, least recently used first
+// (memlimit.Cache). A forgotten client starts again with a full bucket, as
+// when maxKeys is reached.
+func (k *Keyed) Evict(fraction float64) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	drop := memlimit.EvictCount(k.order.Len(), fraction)
+	for range drop {
+		k.remove(k.order.Back())
+	}
+	return drop
+}
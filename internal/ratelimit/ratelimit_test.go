@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/memlimit"
+)
+
+func TestLimiter_BurstThenRefill(t *testing.T) {
+	clock := time.Unix(0, 0)
+	l := PerMinute(2)
+	l.now = func() time.Time { return clock }
+
+	if !l.Allow() || !l.Allow() {
+		t.Fatal("expected burst of 2 to be allowed")
+	}
+	if l.Allow() {
+		t.Fatal("expected third event to be limited")
+	}
+
+	clock = clock.Add(30 * time.Second)
+	if !l.Allow() {
+		t.Fatal("expected one token after 30s at 2/min")
+	}
+	if l.Allow() {
+		t.Fatal("expected bucket empty again")
+	}
+}
+
+func TestKeyed_SeparateBuckets(t *testing.T) {
+	clock := time.Unix(0, 0)
+	k := NewKeyed(1, 2)
+	k.now = func() time.Time { return clock }
+
+	if !k.Allow("a") || k.Allow("a") {
+		t.Fatal("expected one event for a")
+	}
+	if !k.Allow("b") {
+		t.Fatal("b shares a's bucket")
+	}
+	// A third key forgets the least recently used bucket, b; a stays empty
+	// however many new keys arrive.
+	if k.Allow("a") {
+		t.Fatal("a refilled")
+	}
+	for _, key := range []string{"c", "d", "e"} {
+		if !k.Allow(key) || k.Allow("a") {
+			t.Fatalf("new key %s reset a's bucket", key)
+		}
+	}
+	if !k.Allow("b") {
+		t.Fatal("expected b to be forgotten at maxKeys")
+	}
+
+	clock = clock.Add(time.Minute)
+	if !k.Allow("a") {
+		t.Fatal("expected a to refill after a minute")
+	}
+}
+
+func TestKeyed_EvictedUnderMemoryBudget(t *testing.T) {
+	k := NewKeyed(1, 1<<20)
+	j := memlimit.New(100*keyedBucketBytes, 0)
+	j.Register("keyed", k)
+
+	// Drive distinct clients past the budget, as an address-spraying client
+	// would, letting the janitor run between bursts.
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 50; i++ {
+			k.Allow(fmt.Sprintf("client-%d-%d", round, i))
+		}
+		j.Check()
+	}
+	if size := k.Size(); size > 100*(keyedBucketBytes+16) {
+		t.Errorf("Keyed holds %d bytes after Check, budget about %d", size, 100*keyedBucketBytes)
+	}
+	if got := k.Evict(1); got == 0 || k.Size() != 0 {
+		t.Errorf("Evict(1) = %d, %d bytes left", got, k.Size())
+	}
+}
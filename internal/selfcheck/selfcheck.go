@@ -0,0 +1,179 @@
+// Package selfcheck implements the `indexer check` deployment self-test. Each
+// check runs independently so operators see every failure at once.
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
+)
+
+// Check is a single named dependency probe.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the outcome of one Check.
+type Result struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// ChainProbe is the subset of *ethclient.Client used to verify a chain RPC.
+type ChainProbe interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	Close()
+}
+
+// Deps are the external dependencies probed by Checks. Every field is a
+// function so tests can make each dependency fail independently.
+type Deps struct {
+	// PingDB checks the database is reachable.
+	PingDB func(ctx context.Context) error
+	// PingReplica checks the read replica is reachable. Only run when
+	// AMN_DB_REPLICA_DSN is set.
+	PingReplica func(ctx context.Context) error
+	// CheckSchema checks the migrations have been applied.
+	CheckSchema func(ctx context.Context) error
+	// DialChain connects to a chain RPC endpoint.
+	DialChain func(ctx context.Context, rpcURL string, ch config.ChainConfig) (ChainProbe, error)
+	// LookupHost resolves a hostname.
+	LookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+// Checks builds the list of checks for cfg.
+func Checks(cfg config.Config, deps Deps) []Check {
+	checks := []Check{
+		{Name: "config", Run: func(context.Context) error { return cfg.Validate() }},
+		{Name: "database", Run: deps.PingDB},
+		{Name: "migrations", Run: deps.CheckSchema},
+	}
+
+	if cfg.DBReplicaDSN != "" {
+		checks = append(checks, Check{Name: "database replica", Run: deps.PingReplica})
+	}
+
+	if cfg.SigningKeyHex != "" {
+		checks = append(checks, Check{Name: "signing key", Run: func(context.Context) error {
+			_, err := crypto.PrivateKeyFromSeedHex(cfg.SigningKeyHex)
+			return err
+		}})
+	}
+
+	for _, ch := range cfg.SupportedChains {
+		ch := ch
+		rpcURL, ok := cfg.RPCURLs[ch.ChainID]
+		if !ok || rpcURL == "" {
+			continue
+		}
+		checks = append(checks, Check{
+			Name: fmt.Sprintf("chain %d rpc", ch.ChainID),
+			Run: func(ctx context.Context) error {
+				return checkChain(ctx, deps.DialChain, rpcURL, ch)
+			},
+		})
+	}
+
+	if cfg.TelemetryURL != "" {
+		checks = append(checks, Check{Name: "telemetry endpoint", Run: func(ctx context.Context) error {
+			return checkResolvable(ctx, deps.LookupHost, cfg.TelemetryURL)
+		}})
+	}
+	return checks
+}
+
+func checkChain(ctx context.Context, dial func(context.Context, string, config.ChainConfig) (ChainProbe, error), rpcURL string, ch config.ChainConfig) error {
+	client, err := dial(ctx, rpcURL, ch)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	defer client.Close()
+
+	id, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("eth_chainId: %w", err)
+	}
+	if id.Cmp(big.NewInt(int64(ch.ChainID))) != 0 {
+		return fmt.Errorf("rpc reports chain id %s, configured %d", id, ch.ChainID)
+	}
+	code, err := client.CodeAt(ctx, common.HexToAddress(ch.SettlementContract), nil)
+	if err != nil {
+		return fmt.Errorf("eth_getCode: %w", err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("no contract code at %s", ch.SettlementContract)
+	}
+	return nil
+}
+
+func checkResolvable(ctx context.Context, lookup func(context.Context, string) ([]string, error), rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parse url: %w", err)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("url %q has no host", rawURL)
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if _, err := lookup(ctx, host); err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	return nil
+}
+
+// Run executes every check with a per-check timeout and returns all results.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		err := c.Run(cctx)
+		cancel()
+		r := Result{Name: c.Name, OK: err == nil, Detail: "ok"}
+		if err != nil {
+			r.Detail = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// Failed returns the results that did not pass.
+func Failed(results []Result) []Result {
+	var out []Result
+	for _, r := range results {
+		if !r.OK {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// WriteTable writes results as a human-readable table.
+func WriteTable(w io.Writer, results []Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	for _, r := range results {
+		status := "PASS"
+		if !r.OK {
+			status = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name, status, r.Detail)
+	}
+	tw.Flush()
+}
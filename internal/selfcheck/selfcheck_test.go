@@ -0,0 +1,118 @@
+package selfcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+)
+
+type stubProbe struct {
+	chainID *big.Int
+	code    []byte
+	err     error
+}
+
+func (p stubProbe) ChainID(context.Context) (*big.Int, error) { return p.chainID, p.err }
+func (p stubProbe) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return p.code, nil
+}
+func (p stubProbe) Close() {}
+
+func healthyConfig() config.Config {
+	return config.Config{
+		DBDSN:         "postgres://x",
+		FeeBPS:        20,
+		SigningKeyHex: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		SupportedChains: []config.ChainConfig{
+			{ChainID: 11155111, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C", MinConfirmations: 2},
+		},
+		RPCURLs:      map[int]string{11155111: "https://rpc.example"},
+		TelemetryURL: "https://collector.example/report",
+	}
+}
+
+func healthyDeps() Deps {
+	return Deps{
+		PingDB:      func(context.Context) error { return nil },
+		CheckSchema: func(context.Context) error { return nil },
+		DialChain: func(context.Context, string, config.ChainConfig) (ChainProbe, error) {
+			return stubProbe{chainID: big.NewInt(11155111), code: []byte{0x60}}, nil
+		},
+		LookupHost: func(context.Context, string) ([]string, error) { return []string{"127.0.0.1"}, nil },
+	}
+}
+
+func runAll(cfg config.Config, deps Deps) []Result {
+	return Run(context.Background(), Checks(cfg, deps), time.Second)
+}
+
+func TestChecks_AllPass(t *testing.T) {
+	results := runAll(healthyConfig(), healthyDeps())
+	if failed := Failed(results); len(failed) != 0 {
+		t.Fatalf("expected all checks to pass, got %+v", failed)
+	}
+	if len(results) != 6 {
+		t.Errorf("expected 6 checks, got %d", len(results))
+	}
+}
+
+func TestChecks_EachDependencyFailsIndependently(t *testing.T) {
+	boom := errors.New("boom")
+	cases := []struct {
+		name   string
+		check  string
+		mutate func(cfg *config.Config, deps *Deps)
+	}{
+		{"config", "config", func(cfg *config.Config, _ *Deps) { cfg.FeeBPS = -1 }},
+		{"db", "database", func(_ *config.Config, d *Deps) { d.PingDB = func(context.Context) error { return boom } }},
+		{"schema", "migrations", func(_ *config.Config, d *Deps) { d.CheckSchema = func(context.Context) error { return boom } }},
+		{"replica", "database replica", func(cfg *config.Config, d *Deps) {
+			cfg.DBReplicaDSN = "postgres://replica"
+			d.PingReplica = func(context.Context) error { return boom }
+		}},
+		{"signing_key", "signing key", func(cfg *config.Config, _ *Deps) { cfg.SigningKeyHex = "abcd" }},
+		{"rpc_dial", "chain 11155111 rpc", func(_ *config.Config, d *Deps) {
+			d.DialChain = func(context.Context, string, config.ChainConfig) (ChainProbe, error) { return nil, boom }
+		}},
+		{"rpc_chain_mismatch", "chain 11155111 rpc", func(_ *config.Config, d *Deps) {
+			d.DialChain = func(context.Context, string, config.ChainConfig) (ChainProbe, error) {
+				return stubProbe{chainID: big.NewInt(1), code: []byte{0x60}}, nil
+			}
+		}},
+		{"rpc_no_code", "chain 11155111 rpc", func(_ *config.Config, d *Deps) {
+			d.DialChain = func(context.Context, string, config.ChainConfig) (ChainProbe, error) {
+				return stubProbe{chainID: big.NewInt(11155111)}, nil
+			}
+		}},
+		{"telemetry_dns", "telemetry endpoint", func(_ *config.Config, d *Deps) {
+			d.LookupHost = func(context.Context, string) ([]string, error) { return nil, boom }
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, deps := healthyConfig(), healthyDeps()
+			tc.mutate(&cfg, &deps)
+			failed := Failed(runAll(cfg, deps))
+			if len(failed) != 1 || failed[0].Name != tc.check {
+				t.Fatalf("expected only %q to fail, got %+v", tc.check, failed)
+			}
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	WriteTable(&buf, []Result{{Name: "database", OK: true, Detail: "ok"}, {Name: "migrations", Detail: "missing"}})
+	out := buf.String()
+	if !strings.Contains(out, "database") || !strings.Contains(out, "PASS") || !strings.Contains(out, "FAIL") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+// InsertHook runs after an envelope has been stored. Its error is logged and
+// counted; it never fails the submission.
+type InsertHook func(ctx context.Context, env *envelope.Envelope) error
+
+var (
+	insertHookFailures = metrics.NewCounterVec("amn_insert_hook_failures_total",
+		"Insert hooks that returned an error or panicked, by object type.", "object_type")
+	storedObjects = metrics.NewCounterVec("amn_objects_stored_total",
+		"Objects stored, synchronously or by the ingestion queue, by object type.", "object_type")
+)
+
+// InsertHooks is a registry of hooks run after objects are stored.
+//
+// Hooks run one at a time, in registration order, on the goroutine that
+// stored the object: the request for synchronous submissions, an ingestion
+// worker for queued ones (see store.QueuedRepo.OnStored). They run after the
+// insert has committed, so a hook may read the object back, but there is no
+// outbox: a hook missed because the process stopped is not retried.
+type InsertHooks struct {
+	mu    sync.RWMutex
+	hooks []typedHook
+}
+
+type typedHook struct {
+	objectType string // "" for every type
+	fn         InsertHook
+}
+
+// NewInsertHooks returns an empty registry.
+func NewInsertHooks() *InsertHooks {
+	return &InsertHooks{}
+}
+
+// DefaultInsertHooks is the registry the API's object service uses. It comes
+// with the amn_objects_stored_total counter registered.
+var DefaultInsertHooks = func() *InsertHooks {
+	r := NewInsertHooks()
+	r.Register("", countStored)
+	return r
+}()
+
+// RegisterInsertHook registers h in DefaultInsertHooks for objects of
+// objectType, or for every object when objectType is "".
+func RegisterInsertHook(objectType string, h InsertHook) {
+	DefaultInsertHooks.Register(objectType, h)
+}
+
+// Register adds h for objects of objectType, or for every object when
+// objectType is "".
+func (r *InsertHooks) Register(objectType string, h InsertHook) {
+	r.mu.Lock()
+	r.hooks = append(r.hooks, typedHook{objectType: objectType, fn: h})
+	r.mu.Unlock()
+}
+
+// Run calls the hooks registered for env's type. A hook that fails or panics
+// is logged and counted and does not stop the ones after it. Cancelling ctx
+// does not reach the hooks, so a client hanging up after its object was
+// stored does not cut them short.
+func (r *InsertHooks) Run(ctx context.Context, env *envelope.Envelope) {
+	if r == nil {
+		return
+	}
+	r.mu.RLock()
+	hooks := r.hooks
+	r.mu.RUnlock()
+	ctx = context.WithoutCancel(ctx)
+	for _, h := range hooks {
+		if h.objectType != "" && h.objectType != env.ObjectType {
+			continue
+		}
+		if err := runInsertHook(ctx, h.fn, env); err != nil {
+			insertHookFailures.Inc(env.ObjectType)
+			log.Printf("[hooks] insert hook for %s object_id=%s: %v", env.ObjectType, env.ObjectID, err)
+		}
+	}
+}
+
+func runInsertHook(ctx context.Context, h InsertHook, env *envelope.Envelope) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return h(ctx, env)
+}
+
+// countStored is the built-in hook behind amn_objects_stored_total.
+func countStored(_ context.Context, env *envelope.Envelope) error {
+	storedObjects.Inc(env.ObjectType)
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+)
+
+func TestInsertHooks_OrderAndIsolation(t *testing.T) {
+	hooks := NewInsertHooks()
+	var calls []string
+	record := func(name string, err error) InsertHook {
+		return func(ctx context.Context, env *envelope.Envelope) error {
+			if ctx.Err() != nil {
+				t.Errorf("%s: hook context cancelled", name)
+			}
+			calls = append(calls, name+" "+env.ObjectID)
+			return err
+		}
+	}
+	hooks.Register("artifact", record("artifact", nil))
+	hooks.Register("", record("any", errors.New("downstream unavailable")))
+	hooks.Register("artifact", func(context.Context, *envelope.Envelope) error { panic("boom") })
+	hooks.Register("bid", record("bid", nil))
+	hooks.Register("artifact", record("artifact-last", nil))
+	failuresBefore := insertHookFailures.Value("artifact")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hooks.Run(ctx, &envelope.Envelope{ObjectType: "artifact", ObjectID: "a-1"})
+	hooks.Run(ctx, &envelope.Envelope{ObjectType: "bid", ObjectID: "b-1"})
+
+	want := []string{"artifact a-1", "any a-1", "artifact-last a-1", "any b-1", "bid b-1"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if n := insertHookFailures.Value("artifact") - failuresBefore; n != 2 {
+		t.Errorf("artifact hook failures += %v, want 2 (error and panic)", n)
+	}
+}
+
+func TestObjectService_RunsHooksAfterInsert(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	repo := &memObjectRepo{objects: map[string]*envelope.Envelope{}}
+	hooks := NewInsertHooks()
+	var seen []string
+	hooks.Register("bid", func(_ context.Context, env *envelope.Envelope) error {
+		if _, ok := repo.objects[env.ObjectID]; !ok {
+			t.Errorf("hook for %s ran before the insert", env.ObjectID)
+		}
+		seen = append(seen, env.ObjectID)
+		return nil
+	})
+	s := &ObjectService{Objects: repo, Hooks: hooks}
+	ctx := context.Background()
+
+	bid := signedEnvelope(t, priv, "bid", "hook-bid-1", `{"task_id":"t"}`)
+	if _, err := s.Submit(ctx, bid, "bid"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if _, err := s.Submit(ctx, bid, "bid"); KindOf(err) != KindConflict {
+		t.Fatalf("resubmit: %v, want conflict", err)
+	}
+	// Queued envelopes reach the hooks from the queue once stored.
+	s.Queue = &fullQueue{cap: 1}
+	if _, err := s.Submit(ctx, signedEnvelope(t, priv, "bid", "hook-bid-2", `{"task_id":"t"}`), "bid"); err != nil {
+		t.Fatalf("queued Submit: %v", err)
+	}
+	if !slices.Equal(seen, []string{"hook-bid-1"}) {
+		t.Errorf("hooks ran for %v, want only the stored bid", seen)
+	}
+}
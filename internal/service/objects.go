@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// ObjectQueue accepts envelopes for asynchronous insertion.
+// store.QueuedRepo implements it.
+type ObjectQueue interface {
+	Enqueue(env *envelope.Envelope) error
+}
+
+// ObjectService validates and stores signed envelopes.
+type ObjectService struct {
+	Objects store.Repo
+	// Queue, if set, receives envelopes accepted by Submit instead of
+	// writing them synchronously.
+	Queue ObjectQueue
+	// Hooks, if set, run after each synchronous insert. Queued envelopes
+	// reach them through the queue (store.QueuedRepo.OnStored).
+	Hooks *InsertHooks
+	// RequireUTCCreatedAt rejects envelopes whose created_at is not in UTC
+	// ("Z"), so stored strings agree with the created_at ordering column
+	// (AMN_REQUIRE_UTC_CREATED_AT).
+	RequireUTCCreatedAt bool
+}
+
+// Submit validates env, checks it has objectType, verifies its signature and
+// stores it. With a Queue the envelope is queued instead and queued is true;
+// a full queue is reported as KindUnavailable.
+func (s *ObjectService) Submit(ctx context.Context, env *envelope.Envelope, objectType string) (queued bool, err error) {
+	if err := s.checkEnvelope(env, objectType); err != nil {
+		return false, err
+	}
+	if s.Queue != nil {
+		if err := s.Queue.Enqueue(env); err != nil {
+			return false, &Error{Kind: KindUnavailable, Code: "ingest_queue_full",
+				Message: "ingestion queue is full, retry later", Err: err}
+		}
+		return true, nil
+	}
+	return false, s.insert(ctx, env)
+}
+
+// SubmitAccept is Submit for accept envelopes, which must also reference an
+// existing task object signed by the same key.
+func (s *ObjectService) SubmitAccept(ctx context.Context, env *envelope.Envelope) error {
+	if err := s.checkEnvelope(env, "accept"); err != nil {
+		return err
+	}
+
+	// Accept-specific: payload.task_id must be present and non-empty
+	taskID, ok := env.PayloadTaskID()
+	if !ok {
+		return invalid("accept payload must contain a non-empty task_id")
+	}
+
+	// Lookup referenced task
+	task, err := s.Objects.GetObjectByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return notFound("referenced task not found: " + taskID)
+		}
+		return internal("failed to lookup task", err)
+	}
+
+	// Verify referenced object is actually a task
+	if task.ObjectType != "task" {
+		return unprocessable("referenced object is not a task")
+	}
+
+	// Accept signer must equal task signer
+	if !SameSigner(env, &task.Envelope) {
+		return unprocessable("accept signer must match task signer")
+	}
+	return s.insert(ctx, env)
+}
+
+func (s *ObjectService) insert(ctx context.Context, env *envelope.Envelope) error {
+	if err := s.Objects.InsertObject(ctx, env); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return conflict("object_id already exists")
+		}
+		return internal("failed to store object", err)
+	}
+	s.Hooks.Run(ctx, env)
+	return nil
+}
+
+func (s *ObjectService) checkEnvelope(env *envelope.Envelope, objectType string) error {
+	if err := env.ValidateBasic(); err != nil {
+		return &Error{Kind: KindInvalid, Code: validateErrorCode(err), Message: err.Error(), Err: err}
+	}
+	if s.RequireUTCCreatedAt && !strings.HasSuffix(env.CreatedAt, "Z") {
+		return invalid("created_at must be UTC with a Z offset, got %s", env.CreatedAt)
+	}
+	if env.ObjectType != objectType {
+		return invalid("object_type must be %s for this endpoint", objectType)
+	}
+	if err := CountVerification(SchemeEd25519, env.Verify()); err != nil {
+		return &Error{Kind: KindInvalid, Code: verifyErrorCode(err), Message: err.Error(), Err: err}
+	}
+	// After Verify, so payloads that cannot be canonicalized keep their
+	// specific error codes.
+	if err := env.ValidatePayload(); err != nil {
+		return &Error{Kind: KindInvalid, Code: "invalid_request", Message: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// SameSigner compares envelope signers by key, so a base64 key and the
+// did:key for it are the same signer.
+func SameSigner(a, b *envelope.Envelope) bool {
+	ka, errA := a.SignerKey()
+	kb, errB := b.SignerKey()
+	return errA == nil && errB == nil && ka == kb
+}
+
+// validateErrorCode maps an envelope ValidateBasic error to an API error code.
+func validateErrorCode(err error) string {
+	msg := err.Error()
+	if strings.Contains(msg, "object_version") {
+		return "unsupported_version"
+	}
+	if strings.Contains(msg, "signature") || strings.Contains(msg, "pubkey") || strings.Contains(msg, "base64") {
+		return "invalid_signature"
+	}
+	return "invalid_request"
+}
+
+// verifyErrorCode maps an envelope Verify error to an API error code. Payloads
+// that cannot be canonicalized get a code per failure class
+// (invalid_utf8, unsupported_number, malformed_json); everything else is a
+// bad signature.
+func verifyErrorCode(err error) string {
+	if class := canonicaljson.FailureClass(err); class != "" {
+		return class
+	}
+	return "invalid_signature"
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// memObjectRepo is an in-memory Repo covering object submission.
+type memObjectRepo struct {
+	store.Repo
+	objects map[string]*envelope.Envelope
+}
+
+func (r *memObjectRepo) InsertObject(_ context.Context, env *envelope.Envelope) error {
+	if _, ok := r.objects[env.ObjectID]; ok {
+		return store.ErrConflict
+	}
+	r.objects[env.ObjectID] = env
+	return nil
+}
+
+func (r *memObjectRepo) GetObjectByID(_ context.Context, id string) (*store.Object, error) {
+	if env, ok := r.objects[id]; ok {
+		return &store.Object{Envelope: *env}, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func signedEnvelope(t *testing.T, priv ed25519.PrivateKey, objectType, objectID, payload string) *envelope.Envelope {
+	t.Helper()
+	env := &envelope.Envelope{
+		ObjectType: objectType, ObjectVersion: "0.1", ObjectID: objectID, CreatedAt: "2025-01-01T00:00:00Z",
+		Payload: json.RawMessage(payload),
+		Signer:  envelope.Signer{Algo: "ed25519", PubKey: base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))},
+	}
+	preimage, err := env.SignedPreimageBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, preimage))
+	return env
+}
+
+func TestObjectService_Submit(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	repo := &memObjectRepo{objects: map[string]*envelope.Envelope{}}
+	s := &ObjectService{Objects: repo}
+	ctx := context.Background()
+
+	bid := signedEnvelope(t, priv, "bid", "bid-1", `{"task_id":"t"}`)
+	if _, err := s.Submit(ctx, bid, "bid"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	_, err := s.Submit(ctx, bid, "bid")
+	wantKind(t, err, KindConflict, "conflict")
+	_, err = s.Submit(ctx, signedEnvelope(t, priv, "bid", "bid-2", `{}`), "artifact")
+	wantKind(t, err, KindInvalid, "invalid_request")
+
+	tampered := signedEnvelope(t, priv, "bid", "bid-3", `{"n":1}`)
+	tampered.Payload = json.RawMessage(`{"n":2}`)
+	_, err = s.Submit(ctx, tampered, "bid")
+	wantKind(t, err, KindInvalid, "invalid_signature")
+}
+
+// fullQueue accepts cap envelopes and then reports full.
+type fullQueue struct {
+	cap    int
+	queued []*envelope.Envelope
+}
+
+func (q *fullQueue) Enqueue(env *envelope.Envelope) error {
+	if len(q.queued) >= q.cap {
+		return store.ErrQueueFull
+	}
+	q.queued = append(q.queued, env)
+	return nil
+}
+
+func TestObjectService_SubmitQueued(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	repo := &memObjectRepo{objects: map[string]*envelope.Envelope{}}
+	q := &fullQueue{cap: 1}
+	s := &ObjectService{Objects: repo, Queue: q}
+	ctx := context.Background()
+
+	queued, err := s.Submit(ctx, signedEnvelope(t, priv, "bid", "q-1", `{"task_id":"t"}`), "bid")
+	if err != nil || !queued || len(q.queued) != 1 || len(repo.objects) != 0 {
+		t.Fatalf("queued=%v err=%v, queue %d, stored %d", queued, err, len(q.queued), len(repo.objects))
+	}
+	_, err = s.Submit(ctx, signedEnvelope(t, priv, "bid", "q-2", `{"task_id":"t"}`), "bid")
+	wantKind(t, err, KindUnavailable, "ingest_queue_full")
+}
+
+func TestObjectService_SubmitAccept(t *testing.T) {
+	_, employer, _ := ed25519.GenerateKey(nil)
+	_, stranger, _ := ed25519.GenerateKey(nil)
+	repo := &memObjectRepo{objects: map[string]*envelope.Envelope{}}
+	s := &ObjectService{Objects: repo}
+	ctx := context.Background()
+
+	if _, err := s.Submit(ctx, signedEnvelope(t, employer, "task", "task-1", `{"title":"x"}`), "task"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SubmitAccept(ctx, signedEnvelope(t, employer, "accept", "acc-1", `{"task_id":"task-1"}`)); err != nil {
+		t.Fatalf("SubmitAccept: %v", err)
+	}
+	wantKind(t, s.SubmitAccept(ctx, signedEnvelope(t, stranger, "accept", "acc-2", `{"task_id":"task-1"}`)),
+		KindUnprocessable, "invalid_request")
+	wantKind(t, s.SubmitAccept(ctx, signedEnvelope(t, employer, "accept", "acc-3", `{"task_id":"nope"}`)),
+		KindNotFound, "not_found")
+	wantKind(t, s.SubmitAccept(ctx, signedEnvelope(t, employer, "accept", "acc-4", `{}`)),
+		KindInvalid, "invalid_request")
+}
+
+func TestObjectService_SubmitRejectsPayloadSchema(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	s := &ObjectService{Objects: &memObjectRepo{objects: map[string]*envelope.Envelope{}}}
+
+	_, err := s.Submit(context.Background(), signedEnvelope(t, priv, "bid", "bid-1", `{"task_id":7}`), "bid")
+	wantKind(t, err, KindInvalid, "invalid_request")
+	if err == nil || !strings.Contains(err.Error(), "payload/task_id") {
+		t.Errorf("error %v does not name the failing path", err)
+	}
+}
+
+func TestObjectService_RequireUTCCreatedAt(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	ctx := context.Background()
+	withCreatedAt := func(id, createdAt string) *envelope.Envelope {
+		env := signedEnvelope(t, priv, "bid", id, `{"task_id":"t"}`)
+		env.CreatedAt = createdAt
+		preimage, _ := env.SignedPreimageBytes()
+		env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, preimage))
+		return env
+	}
+
+	lenient := &ObjectService{Objects: &memObjectRepo{objects: map[string]*envelope.Envelope{}}}
+	if _, err := lenient.Submit(ctx, withCreatedAt("tz-1", "2025-01-01T05:00:00+05:00"), "bid"); err != nil {
+		t.Fatalf("offset without AMN_REQUIRE_UTC_CREATED_AT: %v", err)
+	}
+
+	strict := &ObjectService{Objects: &memObjectRepo{objects: map[string]*envelope.Envelope{}}, RequireUTCCreatedAt: true}
+	_, err := strict.Submit(ctx, withCreatedAt("tz-2", "2025-01-01T05:00:00+05:00"), "bid")
+	wantKind(t, err, KindInvalid, "invalid_request")
+	if _, err := strict.Submit(ctx, withCreatedAt("tz-3", "2025-01-01T00:00:00Z"), "bid"); err != nil {
+		t.Fatalf("Z offset rejected: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// Reverification is the outcome of re-checking a task's stored creation
+// request.
+type Reverification struct {
+	TaskID string `json:"task_id"`
+	// RequestSHA256 is the hex SHA-256 of the stored request bytes, the
+	// same value task responses carry as raw_request_sha256.
+	RequestSHA256   string `json:"request_sha256"`
+	EmployerAddress string `json:"employer_address,omitempty"`
+	Valid           bool   `json:"valid"`
+	// Error says why the request no longer verifies. Empty when Valid.
+	Error string `json:"error,omitempty"`
+}
+
+// ReverifyTask re-runs the task_hash and employer signature checks against
+// the creation request stored with taskID, exactly as recorded. It uses only
+// the stored bytes, so later changes to the task row do not affect it.
+// Tasks created before requests were recorded are reported as not found.
+func (s *TaskService) ReverifyTask(ctx context.Context, taskID string) (*Reverification, error) {
+	raw, err := s.Tasks.GetTaskRawRequest(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, notFound("task not found")
+		}
+		return nil, internal("failed to load task request", err)
+	}
+	if raw == nil {
+		return nil, newError(KindNotFound, "raw_request_missing", "no creation request is stored for this task")
+	}
+
+	sum := sha256.Sum256(raw)
+	out := &Reverification{TaskID: taskID, RequestSHA256: hex.EncodeToString(sum[:])}
+	var req CreateTaskRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		out.Error = "stored request does not decode: " + err.Error()
+		return out, nil
+	}
+	out.EmployerAddress = strings.ToLower(req.EmployerAddress)
+	switch {
+	case req.TaskID != taskID:
+		out.Error = "stored request is for task_id " + req.TaskID
+	case !strings.EqualFold(req.TaskHash, TaskHash(req.TaskID)):
+		out.Error = "task_hash does not match keccak256(task_id)"
+	default:
+		if err := CountVerification(SchemeEIP191, ethutil.VerifyPersonalSign([]byte(req.TaskID), req.Signature, req.EmployerAddress)); err != nil {
+			out.Error = "signature: " + err.Error()
+		} else {
+			out.Valid = true
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,103 @@
+// Package service holds the task and object validation and mutation logic
+// shared by every transport. Methods return *Error for caller mistakes and
+// conflicts; transports map its Kind to their own status codes.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	reHexAddr = regexp.MustCompile(`(?i)^0x[0-9a-fA-F]{40}$`)
+	reHexHash = regexp.MustCompile(`(?i)^0x[0-9a-fA-F]{64}$`)
+	reHexSig  = regexp.MustCompile(`(?i)^0x[0-9a-fA-F]{130}$`) // 65 bytes = 130 hex chars
+)
+
+// Kind classifies a service error independently of any transport.
+type Kind int
+
+const (
+	// KindInternal is a storage or other server-side failure.
+	KindInternal Kind = iota
+	// KindInvalid means the request is malformed: a required field is
+	// missing or a value has the wrong format.
+	KindInvalid
+	// KindUnauthorized means a required signature is missing or wrong.
+	KindUnauthorized
+	// KindForbidden means the caller is identified but not allowed.
+	KindForbidden
+	// KindNotFound means a referenced resource does not exist.
+	KindNotFound
+	// KindConflict means the request clashes with stored state.
+	KindConflict
+	// KindRateLimited means a per-chain or similar limit was hit.
+	KindRateLimited
+	// KindUnavailable means a dependency such as a chain RPC is unreachable.
+	KindUnavailable
+	// KindPreconditionFailed means state the caller said it acted on has
+	// since changed.
+	KindPreconditionFailed
+	// KindUnprocessable means the request is well-formed but breaks a rule
+	// that depends on its content or on stored state, such as an unsupported
+	// chain or a task_hash that does not match task_id.
+	KindUnprocessable
+)
+
+// Error is a typed domain error. Code is the stable API error code
+// (e.g. "invalid_request", "tier_limit_exceeded") and Message is safe to
+// show to the caller.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Err is the underlying cause, if any. It is not shown to callers.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// KindOf returns the Kind of err, or KindInternal if err is not an *Error.
+func KindOf(err error) Kind {
+	var se *Error
+	if errors.As(err, &se) {
+		return se.Kind
+	}
+	return KindInternal
+}
+
+func newError(kind Kind, code, format string, args ...any) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func invalid(format string, args ...any) *Error {
+	return newError(KindInvalid, "invalid_request", format, args...)
+}
+
+func unprocessable(format string, args ...any) *Error {
+	return newError(KindUnprocessable, "invalid_request", format, args...)
+}
+
+func notFound(msg string) *Error {
+	return newError(KindNotFound, "not_found", "%s", msg)
+}
+
+func conflict(format string, args ...any) *Error {
+	return newError(KindConflict, "conflict", format, args...)
+}
+
+func unavailable(msg string, cause error) *Error {
+	return &Error{Kind: KindUnavailable, Code: "chain_unavailable", Message: msg, Err: cause}
+}
+
+func internal(msg string, cause error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal", Message: msg, Err: cause}
+}
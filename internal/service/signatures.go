@@ -0,0 +1,48 @@
+package service
+
+import "github.com/AgentMesh-Net/indexer-go/internal/metrics"
+
+// Signature schemes verified by the services, used as the scheme label.
+const (
+	SchemeEd25519 = "ed25519" // envelope signatures
+	SchemeEIP191  = "eip191"  // personal_sign over task/accept IDs
+)
+
+// signatureSchemes lists every scheme reported by SignatureStats, so unused
+// schemes show up as zero rather than being absent.
+var signatureSchemes = []string{SchemeEd25519, SchemeEIP191}
+
+var signatureVerifications = metrics.NewCounterVec("amn_signature_verifications_total",
+	"Signature verifications by scheme and result (ok, failed).", "scheme", "result")
+
+// CountVerification records the outcome of a signature verification and
+// returns err unchanged, so it can wrap the verify call in place. Every
+// verification is counted, including the API's read-time checks.
+func CountVerification(scheme string, err error) error {
+	result := "ok"
+	if err != nil {
+		result = "failed"
+	}
+	signatureVerifications.Inc(scheme, result)
+	return err
+}
+
+// SignatureStat is the process-lifetime verification count for one scheme.
+type SignatureStat struct {
+	Scheme   string `json:"scheme"`
+	Verified uint64 `json:"verified"`
+	Failed   uint64 `json:"failed"`
+}
+
+// SignatureStats returns verification counts for every known scheme.
+func SignatureStats() []SignatureStat {
+	stats := make([]SignatureStat, 0, len(signatureSchemes))
+	for _, s := range signatureSchemes {
+		stats = append(stats, SignatureStat{
+			Scheme:   s,
+			Verified: uint64(signatureVerifications.Value(s, "ok")),
+			Failed:   uint64(signatureVerifications.Value(s, "failed")),
+		})
+	}
+	return stats
+}
@@ -0,0 +1,556 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/chain"
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/ratelimit"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/verifypool"
+)
+
+// ChainReader is the subset of *ethclient.Client used while creating tasks:
+// contract view calls and code lookups.
+type ChainReader interface {
+	ethereum.ContractCaller
+	chain.CodeReader
+}
+
+// ChainClients returns a ChainReader for a configured chain.
+type ChainClients interface {
+	Client(ctx context.Context, chainID int) (ChainReader, error)
+}
+
+// TaskService creates and accepts structured tasks.
+type TaskService struct {
+	Tasks   store.TaskRepo
+	Objects store.Repo
+	Config  config.Config
+	// Chains serves contract reads for the optional onchain checks. It is
+	// only used when one of them is enabled in Config.
+	Chains ChainClients
+	// ChainLimiters rate-limits task creation per chain_id. Chains without a
+	// limit have no entry.
+	ChainLimiters map[int]*ratelimit.Limiter
+	// Verifier runs employer and worker signature checks. Nil runs them on
+	// the calling goroutine.
+	Verifier Verifier
+}
+
+// Verifier runs a signature check, typically on a bounded worker pool
+// (*verifypool.Pool). It returns verifypool.ErrOverloaded when it cannot
+// take more work, and ctx.Err() if ctx ends first.
+type Verifier interface {
+	Do(ctx context.Context, verify func() error) error
+}
+
+// CreateTaskRequest is a task submission.
+type CreateTaskRequest struct {
+	TaskID          string         `json:"task_id"`
+	Title           string         `json:"title"`
+	ChainID         int            `json:"chain_id"`
+	AmountWei       string         `json:"amount_wei"`
+	DeadlineUnix    int64          `json:"deadline_unix"`
+	EmployerAddress string         `json:"employer_address"`
+	TaskHash        string         `json:"task_hash"`
+	EscrowAddress   string         `json:"escrow_address"`
+	Signature       string         `json:"signature"` // required: EIP-191 personal_sign over keccak256(task_id)
+	Payload         map[string]any `json:"payload"`   // optional extra metadata
+	// Sequence is an optional per-employer, strictly increasing number that
+	// lets employers detect gaps and duplicates in their own task stream.
+	Sequence *int64 `json:"sequence,omitempty"`
+	// EnvelopeObjectID optionally links the task to the task envelope it was
+	// negotiated from.
+	EnvelopeObjectID string `json:"envelope_object_id,omitempty"`
+	// Visibility is "public" (default) or "private". A private task is
+	// disclosed in full only to the employer and AllowedWorkers, and only
+	// they may accept it.
+	Visibility     string   `json:"visibility,omitempty"`
+	AllowedWorkers []string `json:"allowed_workers,omitempty"`
+	// TokenAddress is the ERC-20 token the escrow holds, or empty for the
+	// chain's native currency. amount_wei is then in the token's base units.
+	TokenAddress string `json:"token_address,omitempty"`
+}
+
+// AcceptTaskRequest is a worker's accept of a structured task.
+type AcceptTaskRequest struct {
+	AcceptID      string `json:"accept_id"`
+	WorkerAddress string `json:"worker_address"`
+	Signature     string `json:"signature"` // required: EIP-191 personal_sign over keccak256(task_id + accept_id)
+	// ExpectedUpdatedAt is the task updated_at the worker last saw. If set
+	// and the task has changed since, the accept fails with
+	// precondition_failed and nothing is stored.
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at,omitempty"`
+}
+
+// CreateTask validates req, verifies the employer signature and any enabled
+// onchain checks, and stores the task.
+func (s *TaskService) CreateTask(ctx context.Context, req CreateTaskRequest) (*store.Task, error) {
+	task, err := s.prepareTask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Tasks.InsertTask(ctx, task, s.Config.MaxOpenTasksPerEmployer); err != nil {
+		return nil, insertTaskError(err, req)
+	}
+	return task, nil
+}
+
+// CreateTaskResult is the outcome of one request in a CreateTasks batch:
+// the stored task, or the *Error CreateTask would have returned.
+type CreateTaskResult struct {
+	Task *store.Task
+	Err  error
+}
+
+// CreateTasks validates and verifies each request as CreateTask does and
+// stores the valid ones in a single transaction. With allOrNothing, one
+// failure stores nothing: the other items fail with batch_aborted. The error
+// is for the batch as a whole (empty, over AMN_MAX_BATCH_TASKS, storage).
+func (s *TaskService) CreateTasks(ctx context.Context, reqs []CreateTaskRequest, allOrNothing bool) ([]CreateTaskResult, error) {
+	if len(reqs) == 0 {
+		return nil, invalid("tasks must not be empty")
+	}
+	if max := s.Config.MaxBatchTasks; max > 0 && len(reqs) > max {
+		return nil, invalid("batch has %d tasks (max %d)", len(reqs), max)
+	}
+
+	results := make([]CreateTaskResult, len(reqs))
+	var tasks []*store.Task
+	var indexes []int // results index of each entry in tasks
+	failed := false
+	for i, req := range reqs {
+		task, err := s.prepareTask(ctx, req)
+		if err != nil {
+			results[i].Err, failed = err, true
+			continue
+		}
+		tasks = append(tasks, task)
+		indexes = append(indexes, i)
+	}
+	aborted := failed && allOrNothing
+	if !aborted && len(tasks) > 0 {
+		errs, err := s.Tasks.InsertTasks(ctx, tasks, s.Config.MaxOpenTasksPerEmployer, allOrNothing)
+		if err != nil && !errors.Is(err, store.ErrBatchAborted) {
+			return nil, internal("failed to store tasks", err)
+		}
+		aborted = err != nil
+		for j, task := range tasks {
+			i := indexes[j]
+			if errs[j] != nil {
+				results[i].Err = insertTaskError(errs[j], reqs[i])
+			} else {
+				results[i].Task = task
+			}
+		}
+	}
+	if aborted {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = CreateTaskResult{Err: newError(KindConflict, "batch_aborted",
+					"not stored: another task in the all-or-nothing batch failed")}
+			}
+		}
+	}
+	return results, nil
+}
+
+// TaskHashScheme names how POST /v1/tasks derives the expected task_hash.
+const TaskHashScheme = "keccak256(utf8(task_id))"
+
+// TaskHash returns the task_hash expected for taskID under TaskHashScheme.
+func TaskHash(taskID string) string {
+	return ethutil.Keccak256Hex([]byte(taskID))
+}
+
+// prepareTask validates req and runs the signature and onchain checks,
+// returning the task to store.
+func (s *TaskService) prepareTask(ctx context.Context, req CreateTaskRequest) (*store.Task, error) {
+	// Validate required fields
+	if req.TaskID == "" {
+		return nil, invalid("task_id is required")
+	}
+	if req.ChainID == 0 {
+		return nil, invalid("chain_id is required")
+	}
+	if !reHexAddr.MatchString(req.EmployerAddress) {
+		return nil, invalid("employer_address must be 0x + 40 hex chars")
+	}
+	if req.EscrowAddress != "" && !reHexAddr.MatchString(req.EscrowAddress) {
+		return nil, invalid("escrow_address must be 0x + 40 hex chars; omit it for the chain's settlement contract")
+	}
+	if !reHexHash.MatchString(req.TaskHash) {
+		return nil, invalid("task_hash must be 0x + 64 hex chars")
+	}
+
+	// Validate amount_wei > 0
+	amtStr := strings.TrimSpace(req.AmountWei)
+	amt, ok := new(big.Int).SetString(amtStr, 10)
+	if !ok || amt.Sign() <= 0 {
+		return nil, invalid("amount_wei must be a positive integer string")
+	}
+
+	if req.Sequence != nil && *req.Sequence <= 0 {
+		return nil, invalid("sequence must be a positive integer")
+	}
+
+	// Validate deadline
+	if req.DeadlineUnix <= 0 || req.DeadlineUnix > (1<<62) {
+		return nil, invalid("deadline_unix out of valid range")
+	}
+
+	visibility, allowedWorkers, err := taskVisibility(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TokenAddress != "" &&
+		(!reHexAddr.MatchString(req.TokenAddress) || common.HexToAddress(req.TokenAddress) == (common.Address{})) {
+		return nil, invalid("token_address must be a non-zero 0x + 40 hex chars address; omit it for native currency")
+	}
+
+	// Verify task_hash == keccak256(utf8(task_id))
+	expected := TaskHash(req.TaskID)
+	if !strings.EqualFold(req.TaskHash, expected) {
+		return nil, unprocessable("task_hash mismatch: expected %s, got %s", expected, req.TaskHash)
+	}
+
+	// Employer signature verification (EIP-191 personal_sign over keccak256(task_id))
+	if err := s.verifyPersonalSign(ctx, []byte(req.TaskID), req.Signature, req.EmployerAddress, "employer_address"); err != nil {
+		return nil, err
+	}
+
+	// Validate chain_id is supported
+	chainCfg, ok := s.Config.Chain(req.ChainID)
+	if !ok {
+		supported := make([]string, len(s.Config.SupportedChains))
+		for i, c := range s.Config.SupportedChains {
+			supported[i] = strconv.Itoa(c.ChainID)
+		}
+		return nil, unprocessable("chain_id %d not supported (supported: %s)", req.ChainID, strings.Join(supported, ","))
+	}
+	if min := s.Config.MinAmountFor(req.ChainID); amt.Cmp(min) < 0 {
+		return nil, unprocessable("amount_wei %s is below the minimum %s for chain_id %d", amt, min, req.ChainID)
+	}
+	escrow := req.EscrowAddress
+	if escrow == "" {
+		escrow = chainCfg.SettlementContract
+	}
+
+	// Checked before the onchain checks so a client over the limit cannot
+	// drive RPC calls.
+	if l := s.ChainLimiters[req.ChainID]; l != nil && !l.Allow() {
+		return nil, newError(KindRateLimited, "chain_rate_limit_exceeded",
+			"task creation rate limit exceeded for chain_id %d", req.ChainID)
+	}
+
+	if err := s.verifyOnchain(ctx, req, chainCfg, escrow); err != nil {
+		return nil, err
+	}
+
+	if req.EnvelopeObjectID != "" {
+		env, err := s.Objects.GetObjectByID(ctx, req.EnvelopeObjectID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, unprocessable("envelope_object_id not found")
+			}
+			return nil, internal("failed to look up envelope_object_id", err)
+		}
+		if env.ObjectType != "task" {
+			return nil, unprocessable("envelope_object_id must reference a task object, got %s", env.ObjectType)
+		}
+	}
+
+	// Kept byte for byte so the signature can be re-verified later
+	// (ReverifyTask).
+	raw, err := canonicaljson.Canonicalize(req)
+	if err != nil {
+		return nil, invalid("request cannot be canonicalized: %s", err.Error())
+	}
+
+	return &store.Task{
+		TaskID:            req.TaskID,
+		TaskHash:          strings.ToLower(req.TaskHash),
+		ChainID:           req.ChainID,
+		EscrowAddress:     escrow,
+		EmployerAddress:   req.EmployerAddress,
+		EmployerSignature: strings.ToLower(req.Signature),
+		AmountWei:         amtStr,
+		DeadlineUnix:      req.DeadlineUnix,
+		Title:             req.Title,
+		Status:            store.TaskStatusCreated,
+		IndexerFeeBPS:     s.Config.FeeBPS,
+		EmployerSequence:  req.Sequence,
+		EnvelopeObjectID:  req.EnvelopeObjectID,
+		Visibility:        visibility,
+		AllowedWorkers:    allowedWorkers,
+		TokenAddress:      req.TokenAddress,
+		RawRequest:        raw,
+	}, nil
+}
+
+// insertTaskError maps a store error for req's task to an *Error.
+func insertTaskError(err error, req CreateTaskRequest) error {
+	if errors.Is(err, store.ErrConflict) {
+		return conflict("task_id already exists")
+	}
+	if errors.Is(err, store.ErrSequenceConflict) {
+		return newError(KindConflict, "sequence_conflict",
+			"sequence %d is not greater than the last sequence for employer_address", *req.Sequence)
+	}
+	var limitErr *store.OpenTaskLimitError
+	if errors.As(err, &limitErr) {
+		return newError(KindRateLimited, "open_task_limit",
+			"employer_address has %d open tasks (limit %d)", limitErr.Open, limitErr.Limit)
+	}
+	if e := addressError(err); e != nil {
+		return e
+	}
+	return internal("failed to store task", err)
+}
+
+// addressError maps a *store.AddressError, the store rejecting a malformed
+// address, to an invalid_request error. It returns nil for other errors.
+func addressError(err error) *Error {
+	var addrErr *store.AddressError
+	if !errors.As(err, &addrErr) {
+		return nil
+	}
+	return invalid("%s must be 0x + 40 hex chars", addrErr.Field)
+}
+
+// verifyOnchain runs the escrow code and onchain task_hash checks enabled in
+// s.Config.
+func (s *TaskService) verifyOnchain(ctx context.Context, req CreateTaskRequest, chainCfg config.ChainConfig, escrow string) error {
+	// Optional: confirm escrow_address is a contract we recognize
+	if s.Config.EnableEscrowCodeVerification {
+		client, err := s.Chains.Client(ctx, req.ChainID)
+		if err != nil {
+			log.Printf("[tasks] escrow code verification chain=%d: %v", req.ChainID, err)
+			return unavailable("escrow code verification unavailable", err)
+		}
+		err = chain.VerifyEscrowCode(ctx, client, common.HexToAddress(escrow), chainCfg.EscrowCodeHash)
+		switch {
+		case errors.Is(err, chain.ErrNoCode):
+			return unprocessable("escrow_address is not a contract")
+		case errors.Is(err, chain.ErrCodeHashMismatch):
+			return unprocessable("escrow_address is not a recognized settlement contract")
+		case err != nil:
+			log.Printf("[tasks] escrow code verification chain=%d escrow=%s: %v", req.ChainID, escrow, err)
+			return unavailable("escrow code verification unavailable", err)
+		}
+	}
+
+	// Optional: confirm the settlement contract derives the same task_hash
+	if s.Config.EnableOnchainHashVerification {
+		client, err := s.Chains.Client(ctx, req.ChainID)
+		if err != nil {
+			log.Printf("[tasks] onchain hash verification chain=%d: %v", req.ChainID, err)
+			return unavailable("onchain task_hash verification unavailable", err)
+		}
+		onchainHash, err := chain.VerifyTaskHashOnChain(ctx, client, common.HexToAddress(chainCfg.SettlementContract), req.TaskID)
+		if err != nil {
+			log.Printf("[tasks] onchain hash verification chain=%d taskID=%s: %v", req.ChainID, req.TaskID, err)
+			return unavailable("onchain task_hash verification unavailable", err)
+		}
+		if !strings.EqualFold(onchainHash, req.TaskHash) {
+			return newError(KindUnprocessable, "task_hash_onchain_mismatch",
+				"task_hash mismatch: contract returned %s, got %s", onchainHash, req.TaskHash)
+		}
+	}
+	return nil
+}
+
+// AcceptTask records a worker's accept of a task in the created state and
+// moves the task to accepted. Repeating an accept that already succeeded
+// returns the stored accept with replayed set.
+func (s *TaskService) AcceptTask(ctx context.Context, taskID string, req AcceptTaskRequest) (accept *store.Accept, replayed bool, err error) {
+	if req.AcceptID == "" {
+		return nil, false, invalid("accept_id is required")
+	}
+	if !reHexAddr.MatchString(req.WorkerAddress) {
+		return nil, false, invalid("worker_address must be 0x + 40 hex chars")
+	}
+
+	// Worker signature verification (EIP-191 personal_sign over keccak256(task_id + accept_id))
+	if err := s.verifyPersonalSign(ctx, []byte(taskID+req.AcceptID), req.Signature, req.WorkerAddress, "worker_address"); err != nil {
+		return nil, false, err
+	}
+
+	// A retry of an accept that already succeeded is answered from the stored
+	// accept, before the task state check (the task is no longer 'created').
+	if existing, err := s.replayAccept(ctx, taskID, req); existing != nil || err != nil {
+		return existing, existing != nil, err
+	}
+
+	// Verify task exists and is in created state
+	task, err := s.Tasks.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, false, notFound("task not found")
+		}
+		return nil, false, internal("failed to get task", err)
+	}
+	// Checked before the state so uninvited workers learn nothing about it.
+	if task.Visibility == store.TaskVisibilityPrivate && !invited(task, req.WorkerAddress) {
+		return nil, false, newError(KindForbidden, "worker_not_invited", "worker_address is not invited to this private task")
+	}
+	if task.Status != store.TaskStatusCreated {
+		return nil, false, conflict("task is not in 'created' state (current: %s)", task.Status)
+	}
+	if err := s.checkChainLive(task, time.Now()); err != nil {
+		return nil, false, err
+	}
+
+	// Worker trust tier must cover the task value
+	tier, err := s.EffectiveWorkerTier(ctx, req.WorkerAddress)
+	if err != nil {
+		return nil, false, internal("failed to get worker tier", err)
+	}
+	if !TierAllows(tier, task.AmountWei) {
+		return nil, false, newError(KindForbidden, "tier_limit_exceeded",
+			"task amount_wei %s exceeds worker tier %d limit %s", task.AmountWei, tier.Tier, tier.MaxTaskAmountWei)
+	}
+
+	accept = &store.Accept{
+		AcceptID:        req.AcceptID,
+		TaskID:          taskID,
+		WorkerAddress:   req.WorkerAddress,
+		WorkerSignature: strings.ToLower(req.Signature),
+		IfUpdatedAt:     req.ExpectedUpdatedAt,
+	}
+	if err := s.Tasks.InsertAccept(ctx, accept); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			// Lost a race with a concurrent identical retry?
+			if existing, err := s.replayAccept(ctx, taskID, req); existing != nil || err != nil {
+				return existing, existing != nil, err
+			}
+			// No accept with this accept_id on the task, so the worker's
+			// earlier accept under another accept_id won.
+			return nil, false, conflict("worker has already accepted this task")
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, false, notFound("task not found")
+		}
+		if errors.Is(err, store.ErrTaskChanged) {
+			return nil, false, newError(KindPreconditionFailed, "precondition_failed",
+				"task changed since %s; re-read it and confirm the terms", req.ExpectedUpdatedAt.UTC().Format(time.RFC3339Nano))
+		}
+		if e := addressError(err); e != nil {
+			return nil, false, e
+		}
+		return nil, false, internal("failed to store accept", err)
+	}
+
+	if err := s.Tasks.UpdateTaskWorker(ctx, taskID, accept.WorkerAddress, store.TaskStatusAccepted); err != nil {
+		return nil, false, internal("failed to update task", err)
+	}
+	return accept, false, nil
+}
+
+// replayAccept looks up a repeated accept. accept_id is scoped to the task,
+// so the same accept_id on another task is not a repeat. It returns the
+// stored accept if accept_id exists on the task with the same worker and
+// signature, a conflict if it exists with different values, and (nil, nil)
+// if accept_id is unknown for the task.
+func (s *TaskService) replayAccept(ctx context.Context, taskID string, req AcceptTaskRequest) (*store.Accept, error) {
+	existing, err := s.Tasks.GetAccept(ctx, taskID, req.AcceptID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, internal("failed to get accept", err)
+	}
+	if !strings.EqualFold(existing.WorkerAddress, req.WorkerAddress) ||
+		!strings.EqualFold(existing.WorkerSignature, req.Signature) {
+		return nil, conflict("accept_id already exists for this task")
+	}
+	return existing, nil
+}
+
+// EffectiveWorkerTier returns the stored tier for addr, or the default tier 0
+// (limited by Config.DefaultWorkerMaxTaskWei) if none is stored.
+func (s *TaskService) EffectiveWorkerTier(ctx context.Context, addr string) (*store.WorkerTier, error) {
+	t, err := s.Tasks.GetWorkerTier(ctx, addr)
+	if errors.Is(err, store.ErrNotFound) {
+		// No row comes back to carry the address in its stored form.
+		return &store.WorkerTier{WorkerAddress: strings.ToLower(addr), Tier: 0, MaxTaskAmountWei: s.Config.DefaultWorkerMaxTaskWei}, nil
+	}
+	return t, err
+}
+
+// TierAllows reports whether amountWei is within the tier's limit.
+func TierAllows(t *store.WorkerTier, amountWei string) bool {
+	if t.MaxTaskAmountWei == "" {
+		return true
+	}
+	limit, ok := new(big.Int).SetString(t.MaxTaskAmountWei, 10)
+	if !ok {
+		return false
+	}
+	amt, ok := new(big.Int).SetString(amountWei, 10)
+	if !ok {
+		return false
+	}
+	return amt.Cmp(limit) <= 0
+}
+
+// checkChainLive refuses work on a task whose chain is no longer
+// configured, once Config.ChainRetiredGrace has passed since the task was
+// flagged. A task not flagged yet counts as flagged now.
+func (s *TaskService) checkChainLive(task *store.Task, now time.Time) error {
+	if _, ok := s.Config.Chain(task.ChainID); ok {
+		return nil
+	}
+	retiredAt := now
+	if task.ChainRetiredAt != nil {
+		retiredAt = *task.ChainRetiredAt
+	}
+	if now.Before(retiredAt.Add(s.Config.ChainRetiredGrace)) {
+		return nil
+	}
+	return newError(KindConflict, "chain_retired", "chain_id %d is no longer supported by this indexer", task.ChainID)
+}
+
+// verifyPersonalSign checks an EIP-191 signature over message by the address
+// in the request field named field, on s.Verifier when set.
+func (s *TaskService) verifyPersonalSign(ctx context.Context, message []byte, sig, address, field string) error {
+	if sig == "" {
+		return newError(KindUnauthorized, "unauthorized", "signature is required")
+	}
+	if !reHexSig.MatchString(sig) {
+		return invalid("signature must be 0x + 130 hex chars")
+	}
+	verify := func() error {
+		return CountVerification(SchemeEIP191, ethutil.VerifyPersonalSign(message, sig, address))
+	}
+	var err error
+	if s.Verifier == nil {
+		err = verify()
+	} else if err = s.Verifier.Do(ctx, verify); errors.Is(err, verifypool.ErrOverloaded) {
+		return &Error{Kind: KindUnavailable, Code: "verification_overloaded",
+			Message: "signature verification is overloaded, retry later", Err: err}
+	} else if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return internal("signature verification cancelled", err)
+	}
+	if err != nil {
+		if errors.Is(err, ethutil.ErrSignerMismatch) || errors.Is(err, ethutil.ErrInvalidSignature) {
+			return newError(KindUnauthorized, "unauthorized",
+				"signature verification failed: signer does not match %s", field)
+		}
+		return invalid("signature error: %s", err.Error())
+	}
+	return nil
+}
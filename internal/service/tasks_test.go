@@ -0,0 +1,665 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+	"github.com/AgentMesh-Net/indexer-go/internal/ratelimit"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/verifypool"
+)
+
+// memTaskRepo is an in-memory TaskRepo covering task creation and accepts.
+type memTaskRepo struct {
+	store.TaskRepo
+	tasks   map[string]*store.Task
+	accepts map[string]*store.Accept // by task_id + "/" + accept_id
+	tiers   map[string]*store.WorkerTier
+}
+
+func newMemTaskRepo() *memTaskRepo {
+	return &memTaskRepo{
+		tasks:   map[string]*store.Task{},
+		accepts: map[string]*store.Accept{},
+		tiers:   map[string]*store.WorkerTier{},
+	}
+}
+
+func (r *memTaskRepo) InsertTask(_ context.Context, t *store.Task, maxOpen int) error {
+	// Like PostgresTaskRepo, store addresses lowercase.
+	t.EscrowAddress, t.EmployerAddress = strings.ToLower(t.EscrowAddress), strings.ToLower(t.EmployerAddress)
+	t.WorkerAddress, t.TokenAddress = strings.ToLower(t.WorkerAddress), strings.ToLower(t.TokenAddress)
+	if _, ok := r.tasks[t.TaskID]; ok {
+		return store.ErrConflict
+	}
+	if maxOpen > 0 {
+		var open int64
+		for _, other := range r.tasks {
+			if other.EmployerAddress == t.EmployerAddress && slices.Contains(store.OpenTaskStatuses, other.Status) {
+				open++
+			}
+		}
+		if open >= int64(maxOpen) {
+			return &store.OpenTaskLimitError{Limit: maxOpen, Open: open}
+		}
+	}
+	r.tasks[t.TaskID] = t
+	return nil
+}
+
+func (r *memTaskRepo) InsertTasks(ctx context.Context, tasks []*store.Task, maxOpen int, allOrNothing bool) ([]error, error) {
+	before := maps.Clone(r.tasks)
+	errs := make([]error, len(tasks))
+	failed := false
+	for i, t := range tasks {
+		if errs[i] = r.InsertTask(ctx, t, maxOpen); errs[i] != nil {
+			failed = true
+		}
+	}
+	if failed && allOrNothing {
+		r.tasks = before
+		return errs, store.ErrBatchAborted
+	}
+	return errs, nil
+}
+
+func (r *memTaskRepo) GetTask(_ context.Context, id string) (*store.Task, error) {
+	if t, ok := r.tasks[id]; ok {
+		return t, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r *memTaskRepo) GetTaskRawRequest(_ context.Context, id string) ([]byte, error) {
+	if t, ok := r.tasks[id]; ok {
+		return t.RawRequest, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r *memTaskRepo) GetAccept(_ context.Context, taskID, acceptID string) (*store.Accept, error) {
+	if a, ok := r.accepts[taskID+"/"+acceptID]; ok {
+		return a, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (r *memTaskRepo) InsertAccept(_ context.Context, a *store.Accept) error {
+	a.WorkerAddress = strings.ToLower(a.WorkerAddress)
+	if _, ok := r.accepts[a.TaskID+"/"+a.AcceptID]; ok {
+		return store.ErrConflict
+	}
+	r.accepts[a.TaskID+"/"+a.AcceptID] = a
+	return nil
+}
+
+func (r *memTaskRepo) UpdateTaskWorker(_ context.Context, taskID, worker, status string) error {
+	r.tasks[taskID].WorkerAddress, r.tasks[taskID].Status = worker, status
+	return nil
+}
+
+func (r *memTaskRepo) GetWorkerTier(_ context.Context, addr string) (*store.WorkerTier, error) {
+	if t, ok := r.tiers[addr]; ok {
+		return t, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+// badAddressRepo rejects every write the way PostgresTaskRepo rejects a
+// malformed address.
+type badAddressRepo struct{ *memTaskRepo }
+
+func (badAddressRepo) InsertTask(_ context.Context, t *store.Task, _ int) error {
+	return &store.AddressError{Field: "escrow_address", Value: t.EscrowAddress}
+}
+
+func (r badAddressRepo) InsertTasks(ctx context.Context, tasks []*store.Task, maxOpen int, _ bool) ([]error, error) {
+	errs := make([]error, len(tasks))
+	for i, t := range tasks {
+		errs[i] = r.InsertTask(ctx, t, maxOpen)
+	}
+	return errs, nil
+}
+
+func (badAddressRepo) InsertAccept(_ context.Context, a *store.Accept) error {
+	return &store.AddressError{Field: "worker_address", Value: a.WorkerAddress}
+}
+
+const testChainID = 11155111
+
+func testConfig() config.Config {
+	return config.Config{
+		FeeBPS:          20,
+		SupportedChains: []config.ChainConfig{{ChainID: testChainID, SettlementContract: "0xf2223eA479736FA2c70fa0BB1430346D937C7C3C"}},
+	}
+}
+
+func personalSign(t *testing.T, key *ecdsa.PrivateKey, msg string) string {
+	t.Helper()
+	prefixed := append([]byte("\x19Ethereum Signed Message:\n32"), ethutil.Keccak256([]byte(msg))...)
+	sig, err := crypto.Sign(ethutil.Keccak256(prefixed), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig)
+}
+
+func createReq(t *testing.T, key *ecdsa.PrivateKey, taskID string) CreateTaskRequest {
+	return CreateTaskRequest{
+		TaskID:          taskID,
+		ChainID:         testChainID,
+		AmountWei:       "1000",
+		DeadlineUnix:    1767225600,
+		EmployerAddress: crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		TaskHash:        ethutil.Keccak256Hex([]byte(taskID)),
+		Signature:       personalSign(t, key, taskID),
+	}
+}
+
+func wantKind(t *testing.T, err error, kind Kind, code string) {
+	t.Helper()
+	var se *Error
+	if !errors.As(err, &se) || se.Kind != kind || se.Code != code {
+		t.Fatalf("err = %#v, want kind %d code %q", err, kind, code)
+	}
+}
+
+func TestCreateTask(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	repo := newMemTaskRepo()
+	s := &TaskService{Tasks: repo, Config: testConfig()}
+	ctx := context.Background()
+
+	task, err := s.CreateTask(ctx, createReq(t, key, "task-1"))
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.EmployerAddress != strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex()) ||
+		task.EscrowAddress != "0xf2223ea479736fa2c70fa0bb1430346d937c7c3c" || task.IndexerFeeBPS != 20 ||
+		task.Status != store.TaskStatusCreated || repo.tasks["task-1"] != task {
+		t.Errorf("stored task = %+v", task)
+	}
+
+	_, err = s.CreateTask(ctx, createReq(t, key, "task-1"))
+	wantKind(t, err, KindConflict, "conflict")
+
+	req := createReq(t, key, "task-2")
+	req.Signature = ""
+	_, err = s.CreateTask(ctx, req)
+	wantKind(t, err, KindUnauthorized, "unauthorized")
+
+	other, _ := crypto.GenerateKey()
+	req.Signature = personalSign(t, other, "task-2")
+	_, err = s.CreateTask(ctx, req)
+	wantKind(t, err, KindUnauthorized, "unauthorized")
+
+	req = createReq(t, key, "task-3")
+	req.ChainID = 1
+	_, err = s.CreateTask(ctx, req)
+	wantKind(t, err, KindUnprocessable, "invalid_request")
+}
+
+func TestCreateTask_EscrowAddressFormat(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	s := &TaskService{Tasks: newMemTaskRepo(), Config: testConfig()}
+	for _, escrow := range []string{"0x1234", "f2223eA479736FA2c70fa0BB1430346D937C7C3C", "0xg2223eA479736FA2c70fa0BB1430346D937C7C3C"} {
+		req := createReq(t, key, "task-"+escrow)
+		req.EscrowAddress = escrow
+		_, err := s.CreateTask(context.Background(), req)
+		wantKind(t, err, KindInvalid, "invalid_request")
+	}
+}
+
+func TestCreateTask_ChainRateLimit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	s := &TaskService{
+		Tasks:         newMemTaskRepo(),
+		Config:        testConfig(),
+		ChainLimiters: map[int]*ratelimit.Limiter{testChainID: ratelimit.PerMinute(1)},
+	}
+	if _, err := s.CreateTask(context.Background(), createReq(t, key, "rl-1")); err != nil {
+		t.Fatal(err)
+	}
+	_, err := s.CreateTask(context.Background(), createReq(t, key, "rl-2"))
+	wantKind(t, err, KindRateLimited, "chain_rate_limit_exceeded")
+}
+
+// downChains counts client requests and fails each one.
+type downChains struct{ calls int }
+
+func (c *downChains) Client(context.Context, int) (ChainReader, error) {
+	c.calls++
+	return nil, errors.New("rpc down")
+}
+
+func TestCreateTask_ChainRateLimitBeforeRPC(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	cfg := testConfig()
+	cfg.EnableEscrowCodeVerification = true
+	chains := &downChains{}
+	s := &TaskService{
+		Tasks:         newMemTaskRepo(),
+		Config:        cfg,
+		Chains:        chains,
+		ChainLimiters: map[int]*ratelimit.Limiter{testChainID: ratelimit.PerMinute(1)},
+	}
+	_, err := s.CreateTask(context.Background(), createReq(t, key, "rl-1"))
+	wantKind(t, err, KindUnavailable, "chain_unavailable")
+	_, err = s.CreateTask(context.Background(), createReq(t, key, "rl-2"))
+	wantKind(t, err, KindRateLimited, "chain_rate_limit_exceeded")
+	if chains.calls != 1 {
+		t.Errorf("RPC client requested %d times, want 1", chains.calls)
+	}
+}
+
+func TestCreateTask_OpenTaskLimit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	cfg := testConfig()
+	cfg.MaxOpenTasksPerEmployer = 2
+	repo := newMemTaskRepo()
+	s := &TaskService{Tasks: repo, Config: cfg}
+	for _, id := range []string{"open-1", "open-2"} {
+		if _, err := s.CreateTask(context.Background(), createReq(t, key, id)); err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+	}
+	_, err := s.CreateTask(context.Background(), createReq(t, key, "open-3"))
+	wantKind(t, err, KindRateLimited, "open_task_limit")
+
+	// A released task frees its slot.
+	repo.tasks["open-1"].Status = store.TaskStatusReleased
+	if _, err := s.CreateTask(context.Background(), createReq(t, key, "open-3")); err != nil {
+		t.Errorf("after release: %v", err)
+	}
+}
+
+func TestCreateTasks_MixedBatch(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ctx := context.Background()
+	batch := func() []CreateTaskRequest {
+		bad := createReq(t, key, "batch-bad")
+		bad.AmountWei = "0"
+		return []CreateTaskRequest{
+			createReq(t, key, "batch-1"),
+			bad,
+			createReq(t, key, "batch-2"),
+			createReq(t, key, "batch-1"), // duplicate within the batch
+		}
+	}
+
+	repo := newMemTaskRepo()
+	s := &TaskService{Tasks: repo, Config: testConfig()}
+	results, err := s.CreateTasks(ctx, batch(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Task == nil || results[2].Task == nil || len(repo.tasks) != 2 {
+		t.Fatalf("per-item: results %+v, %d stored", results, len(repo.tasks))
+	}
+	wantKind(t, results[1].Err, KindInvalid, "invalid_request")
+	wantKind(t, results[3].Err, KindConflict, "conflict")
+
+	repo = newMemTaskRepo()
+	s = &TaskService{Tasks: repo, Config: testConfig()}
+	results, err = s.CreateTasks(ctx, batch(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.tasks) != 0 {
+		t.Fatalf("all-or-nothing stored %d tasks", len(repo.tasks))
+	}
+	wantKind(t, results[0].Err, KindConflict, "batch_aborted")
+	wantKind(t, results[1].Err, KindInvalid, "invalid_request")
+
+	// A store-level failure also aborts the batch.
+	reqs := batch()
+	results, err = s.CreateTasks(ctx, []CreateTaskRequest{reqs[0], reqs[2], reqs[3]}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKind(t, results[0].Err, KindConflict, "batch_aborted")
+	wantKind(t, results[2].Err, KindConflict, "conflict")
+	if len(repo.tasks) != 0 {
+		t.Errorf("aborted batch stored %d tasks", len(repo.tasks))
+	}
+
+	s.Config.MaxBatchTasks = 3
+	if _, err := s.CreateTasks(ctx, batch(), false); err == nil {
+		t.Error("oversized batch accepted")
+	}
+	_, err = s.CreateTasks(ctx, nil, false)
+	wantKind(t, err, KindInvalid, "invalid_request")
+}
+
+func TestCreateTask_MinAmount(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	cfg := testConfig()
+	cfg.MinAmountWei = "5000"
+	s := &TaskService{Tasks: newMemTaskRepo(), Config: cfg}
+	ctx := context.Background()
+
+	cases := []struct {
+		taskID, amount, chainMin string
+		ok                       bool
+	}{
+		{"min-at", "5000", "", true},
+		{"min-below", "4999", "", false},
+		{"chain-at", "1000", "1000", true},
+		{"chain-below", "999", "1000", false},
+		{"chain-zero", "1", "0", true},
+	}
+	for _, tc := range cases {
+		s.Config.SupportedChains[0].MinAmountWei = tc.chainMin
+		req := createReq(t, key, tc.taskID)
+		req.AmountWei = tc.amount
+		_, err := s.CreateTask(ctx, req)
+		if tc.ok {
+			if err != nil {
+				t.Errorf("%s: %v", tc.taskID, err)
+			}
+			continue
+		}
+		wantKind(t, err, KindUnprocessable, "invalid_request")
+	}
+}
+
+func TestAcceptTask(t *testing.T) {
+	worker, _ := crypto.GenerateKey()
+	workerAddr := crypto.PubkeyToAddress(worker.PublicKey).Hex()
+	repo := newMemTaskRepo()
+	repo.tasks["t-1"] = &store.Task{TaskID: "t-1", ChainID: testChainID, Status: store.TaskStatusCreated, AmountWei: "1000"}
+	repo.tasks["t-big"] = &store.Task{TaskID: "t-big", ChainID: testChainID, Status: store.TaskStatusCreated, AmountWei: "5000"}
+	cfg := testConfig()
+	cfg.DefaultWorkerMaxTaskWei = "1000"
+	s := &TaskService{Tasks: repo, Config: cfg}
+	ctx := context.Background()
+
+	req := AcceptTaskRequest{AcceptID: "a-1", WorkerAddress: workerAddr, Signature: personalSign(t, worker, "t-1a-1")}
+	accept, replayed, err := s.AcceptTask(ctx, "t-1", req)
+	if err != nil || replayed {
+		t.Fatalf("AcceptTask: replayed=%v err=%v", replayed, err)
+	}
+	if accept.WorkerAddress != strings.ToLower(workerAddr) || repo.tasks["t-1"].Status != store.TaskStatusAccepted {
+		t.Errorf("accept = %+v, task = %+v", accept, repo.tasks["t-1"])
+	}
+
+	// An identical retry is a replay even though the task is no longer created.
+	if _, replayed, err := s.AcceptTask(ctx, "t-1", req); err != nil || !replayed {
+		t.Errorf("retry: replayed=%v err=%v", replayed, err)
+	}
+
+	other, _ := crypto.GenerateKey()
+	_, _, err = s.AcceptTask(ctx, "t-1", AcceptTaskRequest{
+		AcceptID: "a-1", WorkerAddress: crypto.PubkeyToAddress(other.PublicKey).Hex(), Signature: personalSign(t, other, "t-1a-1"),
+	})
+	wantKind(t, err, KindConflict, "conflict")
+
+	// The same accept_id on another task is a new accept, not a conflict.
+	repo.tasks["t-2"] = &store.Task{TaskID: "t-2", ChainID: testChainID, Status: store.TaskStatusCreated, AmountWei: "1000"}
+	accept, replayed, err = s.AcceptTask(ctx, "t-2", AcceptTaskRequest{
+		AcceptID: "a-1", WorkerAddress: crypto.PubkeyToAddress(other.PublicKey).Hex(), Signature: personalSign(t, other, "t-2a-1"),
+	})
+	if err != nil || replayed || accept.TaskID != "t-2" {
+		t.Errorf("same accept_id, other task: accept=%+v replayed=%v err=%v", accept, replayed, err)
+	}
+
+	_, _, err = s.AcceptTask(ctx, "t-big", AcceptTaskRequest{
+		AcceptID: "a-2", WorkerAddress: workerAddr, Signature: personalSign(t, worker, "t-biga-2"),
+	})
+	wantKind(t, err, KindForbidden, "tier_limit_exceeded")
+
+	_, _, err = s.AcceptTask(ctx, "missing", AcceptTaskRequest{
+		AcceptID: "a-3", WorkerAddress: workerAddr, Signature: personalSign(t, worker, "missinga-3"),
+	})
+	wantKind(t, err, KindNotFound, "not_found")
+}
+
+func TestTaskWrites_StoreAddressErrorIsInvalid(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	repo := badAddressRepo{newMemTaskRepo()}
+	repo.tasks["t-1"] = &store.Task{TaskID: "t-1", ChainID: testChainID, Status: store.TaskStatusCreated, AmountWei: "1000"}
+	s := &TaskService{Tasks: repo, Config: testConfig()}
+	ctx := context.Background()
+
+	_, err := s.CreateTask(ctx, createReq(t, key, "task-1"))
+	wantKind(t, err, KindInvalid, "invalid_request")
+
+	results, err := s.CreateTasks(ctx, []CreateTaskRequest{createReq(t, key, "task-2")}, false)
+	if err != nil {
+		t.Fatalf("CreateTasks: %v", err)
+	}
+	wantKind(t, results[0].Err, KindInvalid, "invalid_request")
+
+	worker := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	_, _, err = s.AcceptTask(ctx, "t-1", AcceptTaskRequest{AcceptID: "a-1", WorkerAddress: worker, Signature: personalSign(t, key, "t-1a-1")})
+	wantKind(t, err, KindInvalid, "invalid_request")
+}
+
+func TestAcceptTask_ChainRetired(t *testing.T) {
+	worker, _ := crypto.GenerateKey()
+	workerAddr := crypto.PubkeyToAddress(worker.PublicKey).Hex()
+	// Chain 5 was dropped from the config while these tasks were open.
+	flagged := time.Now().Add(-time.Hour)
+	repo := newMemTaskRepo()
+	repo.tasks["r-new"] = &store.Task{TaskID: "r-new", ChainID: 5, Status: store.TaskStatusCreated, AmountWei: "1", ChainRetiredAt: &flagged}
+	repo.tasks["r-old"] = &store.Task{TaskID: "r-old", ChainID: 5, Status: store.TaskStatusCreated, AmountWei: "1", ChainRetiredAt: &flagged}
+	repo.tasks["r-unflagged"] = &store.Task{TaskID: "r-unflagged", ChainID: 5, Status: store.TaskStatusCreated, AmountWei: "1"}
+	cfg := testConfig()
+	s := &TaskService{Tasks: repo, Config: cfg}
+	ctx := context.Background()
+	accept := func(taskID string) error {
+		_, _, err := s.AcceptTask(ctx, taskID, AcceptTaskRequest{
+			AcceptID: "a-" + taskID, WorkerAddress: workerAddr, Signature: personalSign(t, worker, taskID+"a-"+taskID),
+		})
+		return err
+	}
+
+	wantKind(t, accept("r-old"), KindConflict, "chain_retired")
+	wantKind(t, accept("r-unflagged"), KindConflict, "chain_retired")
+
+	s.Config.ChainRetiredGrace = 2 * time.Hour
+	if err := accept("r-new"); err != nil {
+		t.Fatalf("within grace: %v", err)
+	}
+	s.Config.ChainRetiredGrace = 30 * time.Minute
+	wantKind(t, accept("r-old"), KindConflict, "chain_retired")
+}
+
+func TestCreateTask_PrivateVisibility(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	s := &TaskService{Tasks: newMemTaskRepo(), Config: testConfig()}
+	ctx := context.Background()
+	const worker = "0xAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+
+	req := createReq(t, key, "priv-1")
+	req.Visibility = store.TaskVisibilityPrivate
+	req.AllowedWorkers = []string{worker, strings.ToLower(worker)}
+	task, err := s.CreateTask(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.Visibility != store.TaskVisibilityPrivate || len(task.AllowedWorkers) != 1 || task.AllowedWorkers[0] != strings.ToLower(worker) {
+		t.Errorf("stored visibility %q, allowed %v", task.Visibility, task.AllowedWorkers)
+	}
+
+	for name, mutate := range map[string]func(*CreateTaskRequest){
+		"no_workers":       func(r *CreateTaskRequest) { r.AllowedWorkers = nil },
+		"bad_worker":       func(r *CreateTaskRequest) { r.AllowedWorkers = []string{"0x1234"} },
+		"public_with_list": func(r *CreateTaskRequest) { r.Visibility = "" },
+		"unknown":          func(r *CreateTaskRequest) { r.Visibility = "secret" },
+	} {
+		req := createReq(t, key, "priv-"+name)
+		req.Visibility, req.AllowedWorkers = store.TaskVisibilityPrivate, []string{worker}
+		mutate(&req)
+		_, err := s.CreateTask(ctx, req)
+		if KindOf(err) != KindInvalid {
+			t.Errorf("%s: err = %v, want invalid", name, err)
+		}
+	}
+}
+
+func TestCreateTask_TokenAddress(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	s := &TaskService{Tasks: newMemTaskRepo(), Config: testConfig()}
+	ctx := context.Background()
+	const token = "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"
+
+	native, err := s.CreateTask(ctx, createReq(t, key, "native-1"))
+	if err != nil || native.TokenAddress != "" {
+		t.Fatalf("native task: token %q, err %v", native.TokenAddress, err)
+	}
+
+	req := createReq(t, key, "erc20-1")
+	req.TokenAddress = token
+	task, err := s.CreateTask(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.TokenAddress != strings.ToLower(token) {
+		t.Errorf("token_address = %q", task.TokenAddress)
+	}
+
+	for i, bad := range []string{"0x1234", "usdc", "0x0000000000000000000000000000000000000000"} {
+		req := createReq(t, key, fmt.Sprintf("erc20-bad-%d", i))
+		req.TokenAddress = bad
+		_, err := s.CreateTask(ctx, req)
+		wantKind(t, err, KindInvalid, "invalid_request")
+	}
+}
+
+func TestAcceptTask_PrivateRequiresInvite(t *testing.T) {
+	invitee, _ := crypto.GenerateKey()
+	stranger, _ := crypto.GenerateKey()
+	inviteeAddr := crypto.PubkeyToAddress(invitee.PublicKey).Hex()
+	repo := newMemTaskRepo()
+	repo.tasks["p-1"] = &store.Task{
+		TaskID: "p-1", ChainID: testChainID, Status: store.TaskStatusCreated, AmountWei: "1",
+		Visibility: store.TaskVisibilityPrivate, AllowedWorkers: []string{strings.ToLower(inviteeAddr)},
+	}
+	s := &TaskService{Tasks: repo, Config: testConfig()}
+	ctx := context.Background()
+
+	_, _, err := s.AcceptTask(ctx, "p-1", AcceptTaskRequest{
+		AcceptID: "a-x", WorkerAddress: crypto.PubkeyToAddress(stranger.PublicKey).Hex(), Signature: personalSign(t, stranger, "p-1a-x"),
+	})
+	wantKind(t, err, KindForbidden, "worker_not_invited")
+
+	if _, _, err := s.AcceptTask(ctx, "p-1", AcceptTaskRequest{
+		AcceptID: "a-1", WorkerAddress: inviteeAddr, Signature: personalSign(t, invitee, "p-1a-1"),
+	}); err != nil {
+		t.Fatalf("invited worker: %v", err)
+	}
+}
+
+func TestCreateTask_VerifierPool(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pool := verifypool.New(2, 64, time.Second)
+	defer pool.Close()
+
+	// Concurrent requests share the pool, as handlers do; memTaskRepo is not
+	// safe for concurrent use, so each gets its own.
+	const n = 32
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := &TaskService{Tasks: newMemTaskRepo(), Config: testConfig(), Verifier: pool}
+			_, errs[i] = s.CreateTask(context.Background(), createReq(t, key, fmt.Sprintf("pooled-%d", i)))
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("task %d: %v", i, err)
+		}
+	}
+
+	// A bad signature is still rejected when checked on the pool.
+	s := &TaskService{Tasks: newMemTaskRepo(), Config: testConfig(), Verifier: pool}
+	req := createReq(t, key, "pooled-bad")
+	req.Signature = createReq(t, key, "other").Signature
+	_, err := s.CreateTask(context.Background(), req)
+	wantKind(t, err, KindUnauthorized, "unauthorized")
+}
+
+type overloadedVerifier struct{}
+
+func (overloadedVerifier) Do(context.Context, func() error) error { return verifypool.ErrOverloaded }
+
+func TestCreateTask_VerifierOverloaded(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	s := &TaskService{Tasks: newMemTaskRepo(), Config: testConfig(), Verifier: overloadedVerifier{}}
+	_, err := s.CreateTask(context.Background(), createReq(t, key, "overloaded"))
+	wantKind(t, err, KindUnavailable, "verification_overloaded")
+}
+
+func TestReverifyTask(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	repo := newMemTaskRepo()
+	s := &TaskService{Tasks: repo, Config: testConfig()}
+	ctx := context.Background()
+
+	req := createReq(t, key, "reverify-1")
+	req.Title = "original"
+	if _, err := s.CreateTask(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	okBefore := signatureVerifications.Value(SchemeEIP191, "ok")
+	first, err := s.ReverifyTask(ctx, "reverify-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Valid || first.RequestSHA256 == "" || first.Error != "" {
+		t.Fatalf("reverification = %+v", first)
+	}
+	if n := signatureVerifications.Value(SchemeEIP191, "ok") - okBefore; n != 1 {
+		t.Errorf("counted %v successful verifications, want 1", n)
+	}
+
+	// Later changes to the task row do not touch the stored request.
+	task := repo.tasks["reverify-1"]
+	task.Title, task.Status, task.WorkerAddress = "changed", store.TaskStatusAccepted, "0x00000000000000000000000000000000000000bb"
+	again, err := s.ReverifyTask(ctx, "reverify-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *again != *first {
+		t.Errorf("after task mutation = %+v, want %+v", again, first)
+	}
+
+	// A tampered stored request no longer verifies.
+	task.RawRequest = []byte(strings.Replace(string(task.RawRequest), req.EmployerAddress, "0x00000000000000000000000000000000000000cc", 1))
+	failedBefore := signatureVerifications.Value(SchemeEIP191, "failed")
+	tampered, err := s.ReverifyTask(ctx, "reverify-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tampered.Valid || tampered.RequestSHA256 == first.RequestSHA256 {
+		t.Errorf("tampered request = %+v, want invalid", tampered)
+	}
+	if n := signatureVerifications.Value(SchemeEIP191, "failed") - failedBefore; n != 1 {
+		t.Errorf("counted %v failed verifications, want 1", n)
+	}
+
+	task.RawRequest = nil
+	_, err = s.ReverifyTask(ctx, "reverify-1")
+	wantKind(t, err, KindNotFound, "raw_request_missing")
+	_, err = s.ReverifyTask(ctx, "nope")
+	wantKind(t, err, KindNotFound, "not_found")
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// maxAllowedWorkers caps the invite list of a private task.
+const maxAllowedWorkers = 100
+
+// CanView reports whether addr may see t in full: anyone for a public task,
+// only the employer or an allowed worker for a private one. addr is empty
+// for anonymous readers.
+func CanView(t *store.Task, addr string) bool {
+	if t.Visibility != store.TaskVisibilityPrivate {
+		return true
+	}
+	return addr != "" && (strings.EqualFold(addr, t.EmployerAddress) || invited(t, addr))
+}
+
+// invited reports whether addr is on the allowed worker list of t.
+func invited(t *store.Task, addr string) bool {
+	return slices.ContainsFunc(t.AllowedWorkers, func(a string) bool { return strings.EqualFold(a, addr) })
+}
+
+// taskVisibility validates the visibility and allowed_workers of req and
+// returns them normalized: lowercase and without duplicates.
+func taskVisibility(req CreateTaskRequest) (string, []string, error) {
+	switch req.Visibility {
+	case "", store.TaskVisibilityPublic:
+		if len(req.AllowedWorkers) > 0 {
+			return "", nil, invalid("allowed_workers is only valid for private tasks")
+		}
+		return store.TaskVisibilityPublic, nil, nil
+	case store.TaskVisibilityPrivate:
+	default:
+		return "", nil, invalid("visibility must be public or private")
+	}
+	if len(req.AllowedWorkers) == 0 {
+		return "", nil, invalid("allowed_workers is required for private tasks")
+	}
+	if len(req.AllowedWorkers) > maxAllowedWorkers {
+		return "", nil, invalid("allowed_workers may list at most %d addresses", maxAllowedWorkers)
+	}
+	allowed := make([]string, 0, len(req.AllowedWorkers))
+	for _, a := range req.AllowedWorkers {
+		if !reHexAddr.MatchString(a) {
+			return "", nil, invalid("allowed_workers: %q must be 0x + 40 hex chars", a)
+		}
+		if a = strings.ToLower(a); !slices.Contains(allowed, a) {
+			allowed = append(allowed, a)
+		}
+	}
+	return store.TaskVisibilityPrivate, allowed, nil
+}
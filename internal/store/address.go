@@ -0,0 +1,61 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// reAddress matches a normalized address: 0x + 40 lowercase hex chars. The
+// address CHECK constraints in migrations/021_address_checks.sql use the
+// same pattern.
+var reAddress = regexp.MustCompile(`^0x[0-9a-f]{40}$`)
+
+// AddressError is returned by repo methods given an address argument that is
+// not 0x + 40 hex chars. Nothing is written.
+type AddressError struct {
+	Field string // column name, e.g. "employer_address"
+	Value string
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("%s %q must be 0x + 40 hex chars", e.Field, e.Value)
+}
+
+// normalizeAddress returns addr in lowercase, the only form stored. Mixed
+// and EIP-55 checksummed input is accepted.
+func normalizeAddress(field, addr string) (string, error) {
+	lower := strings.ToLower(addr)
+	if !reAddress.MatchString(lower) {
+		return "", &AddressError{Field: field, Value: addr}
+	}
+	return lower, nil
+}
+
+// normalizeTaskAddresses normalizes t's addresses in place. WorkerAddress
+// and TokenAddress may be empty.
+func normalizeTaskAddresses(t *Task) error {
+	var err error
+	if t.EscrowAddress, err = normalizeAddress("escrow_address", t.EscrowAddress); err != nil {
+		return err
+	}
+	if t.EmployerAddress, err = normalizeAddress("employer_address", t.EmployerAddress); err != nil {
+		return err
+	}
+	if t.WorkerAddress != "" {
+		if t.WorkerAddress, err = normalizeAddress("worker_address", t.WorkerAddress); err != nil {
+			return err
+		}
+	}
+	if t.TokenAddress != "" {
+		if t.TokenAddress, err = normalizeAddress("token_address", t.TokenAddress); err != nil {
+			return err
+		}
+	}
+	for i, w := range t.AllowedWorkers {
+		if t.AllowedWorkers[i], err = normalizeAddress("allowed_workers", w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	checksummed := common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed").Hex()
+	if checksummed == "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed" {
+		t.Fatal("fixture is not mixed case")
+	}
+	got, err := normalizeAddress("worker_address", checksummed)
+	if err != nil || got != "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed" {
+		t.Errorf("normalizeAddress(%s) = %q, %v", checksummed, got, err)
+	}
+
+	for _, bad := range []string{"", "0x0", "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaedff", "0xZaaeb6053f3e94c9b9a09f33669435e7ef1beaed"} {
+		_, err := normalizeAddress("worker_address", bad)
+		var ae *AddressError
+		if !errors.As(err, &ae) || ae.Field != "worker_address" || ae.Value != bad {
+			t.Errorf("normalizeAddress(%q) error = %v, want *AddressError", bad, err)
+		}
+	}
+}
+
+func TestNormalizeTaskAddresses(t *testing.T) {
+	task := &Task{
+		EscrowAddress:   "0x00000000000000000000000000000000000000E5",
+		EmployerAddress: "0x00000000000000000000000000000000000000E1",
+		AllowedWorkers:  []string{"0x00000000000000000000000000000000000000A1"},
+		TokenAddress:    "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238",
+	}
+	if err := normalizeTaskAddresses(task); err != nil {
+		t.Fatal(err)
+	}
+	if task.EscrowAddress != testEscrow || task.EmployerAddress != testEmployer ||
+		task.WorkerAddress != "" || task.AllowedWorkers[0] != testWorker ||
+		task.TokenAddress != "0x1c7d4b196cb0c7b01d743fbc6116a902379c7238" {
+		t.Errorf("normalized task = %+v", task)
+	}
+
+	task.AllowedWorkers = append(task.AllowedWorkers, "0xnope")
+	var ae *AddressError
+	if err := normalizeTaskAddresses(task); !errors.As(err, &ae) || ae.Field != "allowed_workers" {
+		t.Errorf("bad allowed worker: err = %v", err)
+	}
+}
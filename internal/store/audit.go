@@ -0,0 +1,192 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+var auditEvents = metrics.NewCounterVec("amn_audit_events_total",
+	"Audit events recorded, by type.", "type")
+
+// Audit event severities.
+const (
+	AuditSeverityInfo     = "info"
+	AuditSeverityWarn     = "warn"
+	AuditSeverityCritical = "critical"
+)
+
+// ValidAuditSeverities is the set of accepted severity values.
+var ValidAuditSeverities = map[string]bool{
+	AuditSeverityInfo:     true,
+	AuditSeverityWarn:     true,
+	AuditSeverityCritical: true,
+}
+
+// AuditEvent is a persisted audit trail entry.
+type AuditEvent struct {
+	ID        int64
+	Type      string
+	Severity  string
+	ChainID   *int
+	Detail    map[string]any
+	CreatedAt time.Time
+	// AcknowledgedAt is nil until an operator acknowledges the event.
+	AcknowledgedAt *time.Time
+	AcknowledgedBy string
+}
+
+// AuditFilter narrows ListAuditEvents. Zero fields do not filter.
+type AuditFilter struct {
+	Severity string
+	Type     string
+	ChainID  int
+	Since    *time.Time // inclusive
+	Until    *time.Time // exclusive
+	// Unacknowledged restricts results to events not yet acknowledged.
+	Unacknowledged bool
+}
+
+// execer is satisfied by *pgxpool.Pool and pgx.Tx.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func (r *PostgresTaskRepo) InsertAuditEvent(ctx context.Context, e *AuditEvent) error {
+	return insertAuditEvent(ctx, r.pool, e)
+}
+
+func insertAuditEvent(ctx context.Context, db execer, e *AuditEvent) error {
+	if e.Severity == "" {
+		e.Severity = AuditSeverityInfo
+	}
+	detail := e.Detail
+	if detail == nil {
+		detail = map[string]any{}
+	}
+	detailJSON, err := json.Marshal(detail)
+	if err != nil {
+		return fmt.Errorf("marshal audit detail: %w", err)
+	}
+	const q = `INSERT INTO audit_events (type, severity, chain_id, detail) VALUES ($1, $2, $3, $4)`
+	if _, err := db.Exec(ctx, q, e.Type, e.Severity, e.ChainID, detailJSON); err != nil {
+		return fmt.Errorf("insert audit event: %w", err)
+	}
+	auditEvents.Inc(e.Type)
+	return nil
+}
+
+// ListAuditEvents returns audit events matching f, ordered by
+// created_at DESC, id DESC, with the same keyset pagination as ListObjects.
+func (r *PostgresTaskRepo) ListAuditEvents(ctx context.Context, f AuditFilter, limit int, cursor *Cursor) ([]*AuditEvent, *Cursor, error) {
+	q := `SELECT ` + auditColumns + ` FROM audit_events WHERE 1=1`
+	args := []any{}
+	if f.Severity != "" {
+		args = append(args, f.Severity)
+		q += fmt.Sprintf(" AND severity = $%d", len(args))
+	}
+	if f.Type != "" {
+		args = append(args, f.Type)
+		q += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	if f.ChainID > 0 {
+		args = append(args, f.ChainID)
+		q += fmt.Sprintf(" AND chain_id = $%d", len(args))
+	}
+	if f.Since != nil {
+		args = append(args, *f.Since)
+		q += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if f.Until != nil {
+		args = append(args, *f.Until)
+		q += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if f.Unacknowledged {
+		q += " AND acknowledged_at IS NULL"
+	}
+	if cursor != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, cursor.CreatedAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse cursor time: %w", err)
+		}
+		cursorID, err := strconv.ParseInt(cursor.ObjectID, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse cursor id: %w", err)
+		}
+		args = append(args, cursorTime, cursorID)
+		q += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	q += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*AuditEvent
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows: %w", err)
+	}
+
+	var next *Cursor
+	if len(events) > limit {
+		last := events[limit-1]
+		next = &Cursor{
+			CreatedAt: last.CreatedAt.UTC().Format(time.RFC3339Nano),
+			ObjectID:  strconv.FormatInt(last.ID, 10),
+		}
+		events = events[:limit]
+	}
+	return events, next, nil
+}
+
+// AckAuditEvent marks an audit event acknowledged by by, or clears the
+// acknowledgement when ack is false. It returns the updated event, or
+// ErrNotFound.
+func (r *PostgresTaskRepo) AckAuditEvent(ctx context.Context, id int64, ack bool, by string) (*AuditEvent, error) {
+	q := `UPDATE audit_events SET acknowledged_at = now(), acknowledged_by = $2 WHERE id = $1 RETURNING ` + auditColumns
+	args := []any{id, by}
+	if !ack {
+		q = `UPDATE audit_events SET acknowledged_at = NULL, acknowledged_by = NULL WHERE id = $1 RETURNING ` + auditColumns
+		args = args[:1]
+	}
+	e, err := scanAuditEvent(r.pool.QueryRow(ctx, q, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("ack audit event: %w", err)
+	}
+	return e, nil
+}
+
+const auditColumns = `id, type, severity, chain_id, detail, created_at, acknowledged_at, COALESCE(acknowledged_by,'')`
+
+func scanAuditEvent(row pgx.Row) (*AuditEvent, error) {
+	e := &AuditEvent{}
+	var detail []byte
+	if err := row.Scan(&e.ID, &e.Type, &e.Severity, &e.ChainID, &detail, &e.CreatedAt, &e.AcknowledgedAt, &e.AcknowledgedBy); err != nil {
+		return nil, fmt.Errorf("scan audit event: %w", err)
+	}
+	if err := json.Unmarshal(detail, &e.Detail); err != nil {
+		return nil, fmt.Errorf("unmarshal audit detail: %w", err)
+	}
+	return e, nil
+}
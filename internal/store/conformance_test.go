@@ -0,0 +1,18 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+	"github.com/AgentMesh-Net/indexer-go/internal/store/storetest"
+)
+
+func TestPostgresRepo_Conformance(t *testing.T) {
+	repo, _ := store.PostgresTestRepos(t)
+	storetest.RunRepo(t, func(*testing.T) store.Repo { return repo })
+}
+
+func TestPostgresTaskRepo_Conformance(t *testing.T) {
+	_, tasks := store.PostgresTestRepos(t)
+	storetest.RunTaskRepo(t, func(*testing.T) store.TaskRepo { return tasks })
+}
@@ -24,10 +24,13 @@ func NewPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
-// RunMigrations executes the schema migration.
-func RunMigrations(ctx context.Context, pool *pgxpool.Pool, sql string) error {
-	if _, err := pool.Exec(ctx, sql); err != nil {
-		return fmt.Errorf("exec migration: %w", err)
+// DescribeDSN returns host:port/database for dsn, without credentials or
+// connection parameters, or "" if dsn cannot be parsed.
+func DescribeDSN(dsn string) string {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return ""
 	}
-	return nil
+	cc := config.ConnConfig
+	return fmt.Sprintf("%s:%d/%s", cc.Host, cc.Port, cc.Database)
 }
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// includeDeletedKey is the context key set by WithDeleted.
+type includeDeletedKey struct{}
+
+// WithDeleted returns a context under which task and object reads also
+// return soft-deleted rows, with DeletedAt set. Only admin requests should
+// carry it.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// IncludesDeleted reports whether ctx was returned by WithDeleted. The
+// Postgres queries pass it as a parameter to `(deleted_at IS NULL OR $n)`.
+func IncludesDeleted(ctx context.Context) bool {
+	v, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return v
+}
+
+// utcPtr returns *t in UTC, or nil.
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -1,9 +1,53 @@
 package store
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // ErrConflict is returned when an object_id already exists.
 var ErrConflict = errors.New("object already exists")
 
 // ErrNotFound is returned when an object is not found.
 var ErrNotFound = errors.New("object not found")
+
+// GoneError is returned for an object deleted with a tombstone. It matches
+// ErrNotFound under errors.Is, so callers that do not distinguish the two
+// see a plain miss.
+type GoneError struct {
+	DeletedAt time.Time
+}
+
+func (e *GoneError) Error() string {
+	return "object deleted at " + e.DeletedAt.UTC().Format(time.RFC3339)
+}
+
+func (e *GoneError) Is(target error) bool { return target == ErrNotFound }
+
+// ErrSequenceConflict is returned when an employer sequence number is not
+// strictly greater than the last one recorded for that employer.
+var ErrSequenceConflict = errors.New("employer sequence out of order or duplicate")
+
+// ErrBatchAborted is returned by InsertTasks when an all-or-nothing batch
+// was rolled back because at least one task failed.
+var ErrBatchAborted = errors.New("batch rolled back: a task failed")
+
+// ErrTaskChanged is returned by InsertAccept when the task's updated_at no
+// longer matches Accept.IfUpdatedAt.
+var ErrTaskChanged = errors.New("task changed since it was read")
+
+// ErrTaskOnchain is returned by DeleteTask for a task already created
+// onchain.
+var ErrTaskOnchain = errors.New("task was created onchain")
+
+// OpenTaskLimitError is returned by InsertTask when the employer already has
+// Limit open tasks.
+type OpenTaskLimitError struct {
+	Limit int
+	Open  int64
+}
+
+func (e *OpenTaskLimitError) Error() string {
+	return fmt.Sprintf("employer has %d open tasks (limit %d)", e.Open, e.Limit)
+}
@@ -0,0 +1,10 @@
+package store
+
+import "testing"
+
+// PostgresTestRepos returns Postgres repos on the test database for the external
+// conformance tests, skipping t when AMN_TEST_DB_DSN is unset.
+func PostgresTestRepos(t *testing.T) (*PostgresRepo, *PostgresTaskRepo) {
+	tasks := testPool(t)
+	return NewPostgresRepo(tasks.pool), tasks
+}
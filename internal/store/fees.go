@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FeeEntry is one released task's row in the fee ledger. Wei amounts are
+// decimal strings.
+type FeeEntry struct {
+	TaskID  string
+	ChainID int
+	// ComputedFeeWei is amount_wei * indexer_fee_bps / 10000, rounded down.
+	ComputedFeeWei string
+	// EventFeeWei is the amount from the contract's FeePaid event, or ""
+	// if none has been seen.
+	EventFeeWei string
+	// FeeWei prefers EventFeeWei; Mismatch is set when both are known and
+	// differ. Both are computed by the store.
+	FeeWei     string
+	Mismatch   bool
+	ReleasedAt time.Time
+	TxHash     string
+}
+
+// FeeFilter narrows fee queries. Zero fields do not filter.
+type FeeFilter struct {
+	ChainID int
+	From    *time.Time // inclusive, on released_at
+	To      *time.Time // exclusive, on released_at
+}
+
+// FeeTotal sums the fee ledger for one chain.
+type FeeTotal struct {
+	ChainID    int
+	Tasks      int64
+	FeeWei     string
+	Mismatches int64
+}
+
+// RecordFee upserts the ledger row for e.TaskID. An empty EventFeeWei keeps
+// any event amount already recorded, so Released and FeePaid may arrive in
+// either order. It reports whether the row now has a mismatch.
+func (r *PostgresTaskRepo) RecordFee(ctx context.Context, e *FeeEntry) (bool, error) {
+	const q = `
+INSERT INTO fee_ledger (task_id, chain_id, computed_fee_wei, event_fee_wei, released_at, tx_hash)
+VALUES ($1, $2, $3::numeric, NULLIF($4, '')::numeric, $5, $6)
+ON CONFLICT (task_id) DO UPDATE SET
+    computed_fee_wei = EXCLUDED.computed_fee_wei,
+    event_fee_wei    = COALESCE(EXCLUDED.event_fee_wei, fee_ledger.event_fee_wei)
+RETURNING mismatch`
+	var mismatch bool
+	err := r.pool.QueryRow(ctx, q, e.TaskID, e.ChainID, e.ComputedFeeWei, e.EventFeeWei, e.ReleasedAt, e.TxHash).Scan(&mismatch)
+	if err != nil {
+		return false, fmt.Errorf("record fee: %w", err)
+	}
+	return mismatch, nil
+}
+
+func feeWhere(f FeeFilter) (string, []any) {
+	where, args := " WHERE true", []any{}
+	if f.ChainID > 0 {
+		args = append(args, f.ChainID)
+		where += fmt.Sprintf(" AND chain_id = $%d", len(args))
+	}
+	if f.From != nil {
+		args = append(args, *f.From)
+		where += fmt.Sprintf(" AND released_at >= $%d", len(args))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		where += fmt.Sprintf(" AND released_at < $%d", len(args))
+	}
+	return where, args
+}
+
+// SumFees returns per-chain fee totals, ordered by chain_id.
+func (r *PostgresTaskRepo) SumFees(ctx context.Context, f FeeFilter) ([]FeeTotal, error) {
+	where, args := feeWhere(f)
+	q := `SELECT chain_id, count(*), sum(fee_wei)::text, count(*) FILTER (WHERE mismatch)
+FROM fee_ledger` + where + ` GROUP BY chain_id ORDER BY chain_id`
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sum fees: %w", err)
+	}
+	defer rows.Close()
+	var out []FeeTotal
+	for rows.Next() {
+		var t FeeTotal
+		if err := rows.Scan(&t.ChainID, &t.Tasks, &t.FeeWei, &t.Mismatches); err != nil {
+			return nil, fmt.Errorf("scan fee total: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// ListFees returns ledger rows oldest release first.
+func (r *PostgresTaskRepo) ListFees(ctx context.Context, f FeeFilter) ([]*FeeEntry, error) {
+	where, args := feeWhere(f)
+	q := `SELECT task_id, chain_id, computed_fee_wei::text, COALESCE(event_fee_wei::text, ''),
+       fee_wei::text, mismatch, released_at, tx_hash
+FROM fee_ledger` + where + ` ORDER BY released_at, task_id`
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list fees: %w", err)
+	}
+	defer rows.Close()
+	var out []*FeeEntry
+	for rows.Next() {
+		e := &FeeEntry{}
+		if err := rows.Scan(&e.TaskID, &e.ChainID, &e.ComputedFeeWei, &e.EventFeeWei,
+			&e.FeeWei, &e.Mismatch, &e.ReleasedAt, &e.TxHash); err != nil {
+			return nil, fmt.Errorf("scan fee entry: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
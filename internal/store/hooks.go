@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Task events passed to a TransitionHook.
+const (
+	TaskEventCreated        = "created"
+	TaskEventAccepted       = "accepted"
+	TaskEventOnchainCreated = "onchain_created"
+	TaskEventWorkerSet      = "worker_set"
+	TaskEventReleased       = "released"
+	TaskEventRefunded       = "refunded"
+	// TaskEventWorkerMismatch replaces TaskEventWorkerSet when the onchain
+	// worker differs from the off-chain accepter.
+	TaskEventWorkerMismatch = "worker_mismatch"
+	// TaskEventAcceptReverted: an accepted task whose escrow was never
+	// created went back to created; see RevertUnfundedAccepts. The task
+	// carries no worker any more.
+	TaskEventAcceptReverted = "accept_reverted"
+	// TaskEventDeadlineApproaching is emitted by the deadline notifier, not
+	// by a write.
+	TaskEventDeadlineApproaching = "task_deadline_approaching"
+)
+
+// EventSource identifies the contract log behind a task write. The chain
+// watcher attaches it to the context of the writes a log causes, so a hook
+// can tell a log applied again (a reorg, a reprocessed transaction) from a
+// new event.
+type EventSource struct {
+	ChainID  int
+	TxHash   string
+	LogIndex uint
+}
+
+type eventSourceKey struct{}
+
+// WithEventSource returns ctx carrying src.
+func WithEventSource(ctx context.Context, src EventSource) context.Context {
+	return context.WithValue(ctx, eventSourceKey{}, src)
+}
+
+// EventSourceFrom returns the EventSource attached to ctx, if any.
+func EventSourceFrom(ctx context.Context) (EventSource, bool) {
+	src, ok := ctx.Value(eventSourceKey{}).(EventSource)
+	return src, ok
+}
+
+// EventID returns the stable id of event caused by the log:
+// chain_id:tx_hash:log_index:event.
+func (s EventSource) EventID(event string) string {
+	return fmt.Sprintf("%d:%s:%d:%s", s.ChainID, s.TxHash, s.LogIndex, event)
+}
+
+// TransitionHook is called after a task write succeeds, with the event that
+// caused it and the task as stored afterwards. Hooks run synchronously on the
+// writer's goroutine and must not block.
+type TransitionHook func(ctx context.Context, event string, t *Task)
+
+// HookedTaskRepo wraps a TaskRepo and runs the registered hooks after every
+// write that changes a task's lifecycle state. Tasks are re-read after the
+// write so hooks always see the stored row.
+type HookedTaskRepo struct {
+	TaskRepo
+
+	mu    sync.RWMutex
+	hooks []TransitionHook
+}
+
+// NewHookedTaskRepo wraps repo.
+func NewHookedTaskRepo(repo TaskRepo) *HookedTaskRepo {
+	return &HookedTaskRepo{TaskRepo: repo}
+}
+
+// OnTransition registers h to run after every task transition.
+func (r *HookedTaskRepo) OnTransition(h TransitionHook) {
+	r.mu.Lock()
+	r.hooks = append(r.hooks, h)
+	r.mu.Unlock()
+}
+
+// Emit runs the registered hooks for an event that did not come from a
+// write through r, with t as the task.
+func (r *HookedTaskRepo) Emit(ctx context.Context, event string, t *Task) {
+	r.fire(ctx, event, func() (*Task, error) { return t, nil })
+}
+
+func (r *HookedTaskRepo) fire(ctx context.Context, event string, load func() (*Task, error)) {
+	r.mu.RLock()
+	hooks := r.hooks
+	r.mu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+	t, err := load()
+	if err != nil {
+		log.Printf("[hooks] %s: reload task: %v", event, err)
+		return
+	}
+	for _, h := range hooks {
+		h(ctx, event, t)
+	}
+}
+
+func (r *HookedTaskRepo) byID(ctx context.Context, taskID string) func() (*Task, error) {
+	return func() (*Task, error) { return r.TaskRepo.GetTask(ctx, taskID) }
+}
+
+func (r *HookedTaskRepo) byHash(ctx context.Context, chainID int, taskHash string) func() (*Task, error) {
+	return func() (*Task, error) { return r.TaskRepo.GetTaskByHash(ctx, chainID, taskHash) }
+}
+
+func (r *HookedTaskRepo) InsertTask(ctx context.Context, t *Task, maxOpen int) error {
+	if err := r.TaskRepo.InsertTask(ctx, t, maxOpen); err != nil {
+		return err
+	}
+	r.fire(ctx, TaskEventCreated, r.byID(ctx, t.TaskID))
+	return nil
+}
+
+func (r *HookedTaskRepo) InsertTasks(ctx context.Context, tasks []*Task, maxOpen int, allOrNothing bool) ([]error, error) {
+	errs, err := r.TaskRepo.InsertTasks(ctx, tasks, maxOpen, allOrNothing)
+	if err != nil {
+		return errs, err
+	}
+	for i, t := range tasks {
+		if errs[i] == nil {
+			r.fire(ctx, TaskEventCreated, r.byID(ctx, t.TaskID))
+		}
+	}
+	return errs, nil
+}
+
+func (r *HookedTaskRepo) UpdateTaskWorker(ctx context.Context, taskID, workerAddress, status string) error {
+	if err := r.TaskRepo.UpdateTaskWorker(ctx, taskID, workerAddress, status); err != nil {
+		return err
+	}
+	r.fire(ctx, TaskEventAccepted, r.byID(ctx, taskID))
+	return nil
+}
+
+func (r *HookedTaskRepo) UpdateOnchainCreated(ctx context.Context, taskID, txHash string, terms *OnchainTerms, at time.Time) error {
+	if err := r.TaskRepo.UpdateOnchainCreated(ctx, taskID, txHash, terms, at); err != nil {
+		return err
+	}
+	r.fire(ctx, TaskEventOnchainCreated, r.byID(ctx, taskID))
+	return nil
+}
+
+func (r *HookedTaskRepo) UpdateOnchainWorkerSet(ctx context.Context, chainID int, taskHash, workerAddress, txHash string) error {
+	if err := r.TaskRepo.UpdateOnchainWorkerSet(ctx, chainID, taskHash, workerAddress, txHash); err != nil {
+		return err
+	}
+	t, err := r.TaskRepo.GetTaskByHash(ctx, chainID, taskHash)
+	event := TaskEventWorkerSet
+	if err == nil && t.WorkerMismatch {
+		event = TaskEventWorkerMismatch
+	}
+	r.fire(ctx, event, func() (*Task, error) { return t, err })
+	return nil
+}
+
+func (r *HookedTaskRepo) UpdateOnchainReleased(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error {
+	if err := r.TaskRepo.UpdateOnchainReleased(ctx, chainID, taskHash, txHash, at); err != nil {
+		return err
+	}
+	r.fire(ctx, TaskEventReleased, r.byHash(ctx, chainID, taskHash))
+	return nil
+}
+
+func (r *HookedTaskRepo) UpdateOnchainRefunded(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error {
+	if err := r.TaskRepo.UpdateOnchainRefunded(ctx, chainID, taskHash, txHash, at); err != nil {
+		return err
+	}
+	r.fire(ctx, TaskEventRefunded, r.byHash(ctx, chainID, taskHash))
+	return nil
+}
+
+func (r *HookedTaskRepo) RevertUnfundedAccepts(ctx context.Context, acceptedBefore time.Time) ([]UnfundedAccept, error) {
+	reverted, err := r.TaskRepo.RevertUnfundedAccepts(ctx, acceptedBefore)
+	for _, u := range reverted {
+		r.fire(ctx, TaskEventAcceptReverted, r.byID(ctx, u.TaskID))
+	}
+	return reverted, err
+}
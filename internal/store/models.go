@@ -1,6 +1,10 @@
 package store
 
-import "time"
+import (
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+)
 
 // ObjectRow represents a row in the objects table.
 type ObjectRow struct {
@@ -13,3 +17,24 @@ type ObjectRow struct {
 	PayloadJSON   []byte
 	InsertedAt    time.Time
 }
+
+// Object is a stored envelope as returned to readers. FirstSeenAt is the
+// inserted_at column: when this indexer stored the object, as opposed to the
+// created_at the signer claims.
+type Object struct {
+	envelope.Envelope
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	// DeletedAt is set for a soft-deleted object, which reads return only
+	// under WithDeleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ObjectNotes is the operator annotation on a stored object. It is kept
+// beside the signed envelope and never changes it.
+type ObjectNotes struct {
+	ObjectID string
+	// Notes is empty when the object has no annotation.
+	Notes string
+	// UpdatedAt is when Notes was last set; nil if never.
+	UpdatedAt *time.Time
+}
@@ -11,12 +11,14 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
 	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
 )
 
 // PostgresRepo implements Repo using PostgreSQL.
 type PostgresRepo struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	replica *pgxpool.Pool // nil: reads use pool; see WithReplica
 }
 
 // NewPostgresRepo creates a new PostgresRepo.
@@ -24,32 +26,55 @@ func NewPostgresRepo(pool *pgxpool.Pool) *PostgresRepo {
 	return &PostgresRepo{pool: pool}
 }
 
-func (r *PostgresRepo) InsertObject(ctx context.Context, env *envelope.Envelope) error {
+const insertObjectSQL = `INSERT INTO objects (object_id, object_type, object_version, created_at, signer_pubkey, signer_did, envelope_json, payload_json)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)`
+
+// objectArgs returns the insertObjectSQL arguments for env.
+func objectArgs(env *envelope.Envelope) ([]any, error) {
 	envJSON, err := json.Marshal(env)
 	if err != nil {
-		return fmt.Errorf("marshal envelope: %w", err)
+		return nil, fmt.Errorf("marshal envelope: %w", err)
 	}
 
 	createdAt, err := time.Parse(time.RFC3339Nano, env.CreatedAt)
 	if err != nil {
 		createdAt, err = time.Parse(time.RFC3339, env.CreatedAt)
 		if err != nil {
-			return fmt.Errorf("parse created_at: %w", err)
+			return nil, fmt.Errorf("parse created_at: %w", err)
 		}
 	}
+	// The column is the instant; the envelope keeps the signed string as sent.
+	createdAt = createdAt.UTC()
 
-	const q = `INSERT INTO objects (object_id, object_type, object_version, created_at, signer_pubkey, envelope_json, payload_json)
-VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	// signer_pubkey is base64 so lookups by either signer form match; a
+	// did:key identifier is kept alongside in signer_did.
+	signerKey, err := env.SignerKey()
+	if err != nil {
+		return nil, fmt.Errorf("signer key: %w", err)
+	}
+	signerDID := ""
+	if crypto.IsDIDKey(env.Signer.PubKey) {
+		signerDID = env.Signer.PubKey
+	}
 
-	_, err = r.pool.Exec(ctx, q,
+	return []any{
 		env.ObjectID,
 		env.ObjectType,
 		env.ObjectVersion,
 		createdAt,
-		env.Signer.PubKey,
+		signerKey,
+		signerDID,
 		envJSON,
 		env.Payload,
-	)
+	}, nil
+}
+
+func (r *PostgresRepo) InsertObject(ctx context.Context, env *envelope.Envelope) error {
+	args, err := objectArgs(env)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, insertObjectSQL, args...)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -60,75 +85,288 @@ VALUES ($1, $2, $3, $4, $5, $6, $7)`
 	return nil
 }
 
-func (r *PostgresRepo) ListObjects(ctx context.Context, objectType string, limit int, cursor *Cursor) ([]envelope.Envelope, *Cursor, error) {
-	var rows pgx.Rows
-	var err error
+// InsertObjects stores envs in one round trip and returns one error per
+// envelope: nil, ErrConflict if its object_id already exists, or the failure.
+func (r *PostgresRepo) InsertObjects(ctx context.Context, envs []*envelope.Envelope) []error {
+	errs := make([]error, len(envs))
+	batch := &pgx.Batch{}
+	queued := make([]int, 0, len(envs))
+	for i, env := range envs {
+		args, err := objectArgs(env)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		// DO NOTHING keeps one duplicate from aborting the rest of the batch.
+		batch.Queue(insertObjectSQL+` ON CONFLICT (object_id) DO NOTHING`, args...)
+		queued = append(queued, i)
+	}
+	if len(queued) == 0 {
+		return errs
+	}
 
-	if cursor != nil {
-		cursorTime, parseErr := time.Parse(time.RFC3339Nano, cursor.CreatedAt)
-		if parseErr != nil {
-			return nil, nil, fmt.Errorf("parse cursor time: %w", parseErr)
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+	for _, i := range queued {
+		tag, err := br.Exec()
+		switch {
+		case err != nil:
+			errs[i] = fmt.Errorf("insert: %w", err)
+		case tag.RowsAffected() == 0:
+			errs[i] = ErrConflict
 		}
-		const q = `SELECT envelope_json FROM objects
-WHERE object_type = $1
-  AND (created_at, object_id) < ($2, $3)
-ORDER BY created_at DESC, object_id DESC
-LIMIT $4`
-		rows, err = r.pool.Query(ctx, q, objectType, cursorTime, cursor.ObjectID, limit+1)
-	} else {
-		const q = `SELECT envelope_json FROM objects
-WHERE object_type = $1
-ORDER BY created_at DESC, object_id DESC
-LIMIT $2`
-		rows, err = r.pool.Query(ctx, q, objectType, limit+1)
 	}
+	return errs
+}
+
+func (r *PostgresRepo) ListObjects(ctx context.Context, objectType string, limit int, cursor *Cursor) ([]Object, *Cursor, error) {
+	return r.QueryObjects(ctx, ObjectFilter{ObjectType: objectType, Limit: limit, Cursor: cursor})
+}
+
+func (r *PostgresRepo) ListObjectsBySigner(ctx context.Context, signerPubKey, objectType string, limit int, cursor *Cursor) ([]Object, *Cursor, error) {
+	return r.QueryObjects(ctx, ObjectFilter{ObjectType: objectType, SignerPubKey: signerPubKey, Limit: limit, Cursor: cursor})
+}
+
+// QueryObjects builds the WHERE clause from the set fields of f; the
+// (created_at, object_id) or (inserted_at, object_id) keyset keeps the order
+// stable regardless of which predicates are present.
+//
+// Only some combinations can walk an index in page order: object_type alone
+// (idx_objects_type_created_id, idx_objects_type_inserted_id), signer with
+// object_type (idx_objects_signer_type_created_id,
+// idx_objects_signer_type_inserted_id), and task_id with object_type in
+// created order (idx_objects_payload_task_id). A signer or task_id without
+// object_type uses the same indexes to find the rows but sorts all of them;
+// the amount and content_hash indexes only narrow the rows; and with neither
+// object_type nor signer nor a payload filter the query scans the table.
+// The API always sets object_type or signer.
+func (r *PostgresRepo) QueryObjects(ctx context.Context, f ObjectFilter) ([]Object, *Cursor, error) {
+	sortCol, cmp, dir := "created_at", "<", "DESC"
+	if f.Order == OrderReceived {
+		sortCol, cmp, dir = "inserted_at", ">", "ASC"
+	}
+	q := `SELECT envelope_json, inserted_at, deleted_at, ` + sortCol + `, object_id FROM objects WHERE (deleted_at IS NULL OR $1)`
+	args := []any{IncludesDeleted(ctx)}
+	if f.SignerPubKey != "" {
+		args = append(args, f.SignerPubKey)
+		q += fmt.Sprintf(" AND signer_pubkey = $%d", len(args))
+	}
+	if f.ObjectType != "" {
+		args = append(args, f.ObjectType)
+		q += fmt.Sprintf(" AND object_type = $%d", len(args))
+	}
+	if f.TaskID != "" {
+		args = append(args, f.TaskID)
+		q += fmt.Sprintf(" AND payload_task_id = $%d", len(args))
+	}
+	if f.ContentHash != "" {
+		args = append(args, f.ContentHash)
+		q += fmt.Sprintf(" AND payload_content_hash = $%d", len(args))
+	}
+	if f.MinAmountWei != "" {
+		args = append(args, f.MinAmountWei)
+		q += fmt.Sprintf(" AND payload_amount_wei >= $%d::numeric", len(args))
+	}
+	if f.MaxAmountWei != "" {
+		args = append(args, f.MaxAmountWei)
+		q += fmt.Sprintf(" AND payload_amount_wei <= $%d::numeric", len(args))
+	}
+	if f.Since != nil {
+		args = append(args, *f.Since)
+		q += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if f.Until != nil {
+		args = append(args, *f.Until)
+		q += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if f.Order == OrderReceived && !f.ReceivedBefore.IsZero() {
+		args = append(args, f.ReceivedBefore)
+		q += fmt.Sprintf(" AND inserted_at < $%d", len(args))
+	}
+	if f.Cursor != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, f.Cursor.CreatedAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse cursor time: %w", err)
+		}
+		args = append(args, cursorTime, f.Cursor.ObjectID)
+		q += fmt.Sprintf(" AND (%s, object_id) %s ($%d, $%d)", sortCol, cmp, len(args)-1, len(args))
+	}
+	args = append(args, f.Limit+1)
+	q += fmt.Sprintf(" ORDER BY %[1]s %[2]s, object_id %[2]s LIMIT $%[3]d", sortCol, dir, len(args))
+
+	rows, err := r.reader(ctx).Query(ctx, q, args...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("query: %w", err)
 	}
+	items, last, more, err := scanObjectPage(rows, f.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if more || (f.Order == OrderReceived && last != nil) {
+		last.Order = f.Order
+		return items, last, nil
+	}
+	return items, nil, nil
+}
+
+func (r *PostgresRepo) ListObjectsForTask(ctx context.Context, taskID, linkedObjectID string, limit int) ([]envelope.Envelope, error) {
+	const q = `SELECT envelope_json FROM objects
+WHERE (payload_task_id = $1 OR object_id = NULLIF($2, ''))
+  AND (deleted_at IS NULL OR $3)
+ORDER BY created_at ASC, object_id ASC
+LIMIT $4`
+	rows, err := r.reader(ctx).Query(ctx, q, taskID, linkedObjectID, IncludesDeleted(ctx), limit)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	return scanEnvelopes(rows)
+}
+
+// scanObjectPage reads up to limit+1 (envelope_json, inserted_at, deleted_at,
+// sort time, object_id) rows. last is the cursor after the final returned item and more
+// reports whether another page exists. It closes rows.
+//
+// The cursor is built from the sort column as stored, not from the
+// envelope's created_at string: the two can differ in precision and offset,
+// and the keyset comparison must use exactly the value Postgres compares.
+func scanObjectPage(rows pgx.Rows, limit int) (items []Object, last *Cursor, more bool, err error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		if len(items) == limit {
+			// The extra row only signals that another page exists.
+			more = true
+			break
+		}
+		var envJSON []byte
+		var insertedAt, sortTime time.Time
+		var deletedAt *time.Time
+		var objectID string
+		if err := rows.Scan(&envJSON, &insertedAt, &deletedAt, &sortTime, &objectID); err != nil {
+			return nil, nil, false, fmt.Errorf("scan: %w", err)
+		}
+		obj := Object{FirstSeenAt: insertedAt.UTC(), DeletedAt: utcPtr(deletedAt)}
+		if err := json.Unmarshal(envJSON, &obj.Envelope); err != nil {
+			return nil, nil, false, fmt.Errorf("unmarshal: %w", err)
+		}
+		items = append(items, obj)
+		last = &Cursor{CreatedAt: sortTime.UTC().Format(time.RFC3339Nano), ObjectID: objectID}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, false, fmt.Errorf("rows: %w", err)
+	}
+	return items, last, more, nil
+}
+
+// scanEnvelopes reads all envelope_json rows. It closes rows.
+func scanEnvelopes(rows pgx.Rows) ([]envelope.Envelope, error) {
 	defer rows.Close()
 
 	var items []envelope.Envelope
 	for rows.Next() {
 		var envJSON []byte
 		if err := rows.Scan(&envJSON); err != nil {
-			return nil, nil, fmt.Errorf("scan: %w", err)
+			return nil, fmt.Errorf("scan: %w", err)
 		}
 		var env envelope.Envelope
 		if err := json.Unmarshal(envJSON, &env); err != nil {
-			return nil, nil, fmt.Errorf("unmarshal: %w", err)
+			return nil, fmt.Errorf("unmarshal: %w", err)
 		}
 		items = append(items, env)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, nil, fmt.Errorf("rows: %w", err)
+		return nil, fmt.Errorf("rows: %w", err)
 	}
+	return items, nil
+}
 
-	var next *Cursor
-	if len(items) > limit {
-		last := items[limit-1]
-		next = &Cursor{
-			CreatedAt: last.CreatedAt,
-			ObjectID:  last.ObjectID,
+func (r *PostgresRepo) GetObjectByID(ctx context.Context, id string) (*Object, error) {
+	const q = `SELECT envelope_json, inserted_at, deleted_at FROM objects WHERE object_id = $1`
+	var envJSON []byte
+	var obj Object
+	var deletedAt *time.Time
+	err := r.reader(ctx).QueryRow(ctx, q, id).Scan(&envJSON, &obj.FirstSeenAt, &deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
 		}
-		items = items[:limit]
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	if deletedAt != nil && !IncludesDeleted(ctx) {
+		return nil, &GoneError{DeletedAt: deletedAt.UTC()}
+	}
+	obj.DeletedAt = utcPtr(deletedAt)
+	if err := json.Unmarshal(envJSON, &obj.Envelope); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
 	}
+	obj.FirstSeenAt = obj.FirstSeenAt.UTC()
+	return &obj, nil
+}
 
-	return items, next, nil
+func (r *PostgresRepo) GetObjectNotes(ctx context.Context, objectID string) (*ObjectNotes, error) {
+	const q = `SELECT object_id, COALESCE(admin_notes, ''), admin_notes_updated_at FROM objects WHERE object_id = $1 AND deleted_at IS NULL`
+	return scanObjectNotes(r.pool.QueryRow(ctx, q, objectID))
 }
 
-func (r *PostgresRepo) GetObjectByID(ctx context.Context, id string) (*envelope.Envelope, error) {
-	const q = `SELECT envelope_json FROM objects WHERE object_id = $1`
-	var envJSON []byte
-	err := r.pool.QueryRow(ctx, q, id).Scan(&envJSON)
-	if err != nil {
+func (r *PostgresRepo) SetObjectNotes(ctx context.Context, objectID, notes string) (*ObjectNotes, error) {
+	const q = `
+UPDATE objects SET admin_notes = NULLIF($2, ''), admin_notes_updated_at = now()
+WHERE object_id = $1 AND deleted_at IS NULL
+RETURNING object_id, COALESCE(admin_notes, ''), admin_notes_updated_at`
+	return scanObjectNotes(r.pool.QueryRow(ctx, q, objectID, notes))
+}
+
+func scanObjectNotes(row pgx.Row) (*ObjectNotes, error) {
+	var n ObjectNotes
+	if err := row.Scan(&n.ObjectID, &n.Notes, &n.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
 		}
+		return nil, fmt.Errorf("object notes: %w", err)
+	}
+	if n.UpdatedAt != nil {
+		at := n.UpdatedAt.UTC()
+		n.UpdatedAt = &at
+	}
+	return &n, nil
+}
+
+func (r *PostgresRepo) DeleteObject(ctx context.Context, id string, tombstone bool) error {
+	q := `DELETE FROM objects WHERE object_id = $1`
+	if tombstone {
+		q = `UPDATE objects SET deleted_at = now() WHERE object_id = $1 AND deleted_at IS NULL`
+	}
+	tag, err := r.pool.Exec(ctx, q, id)
+	if err != nil {
+		return fmt.Errorf("delete object: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+	// Nothing changed: tell an unknown object from an existing tombstone.
+	_, err = r.GetObjectByID(ctx, id)
+	if err == nil {
+		return fmt.Errorf("delete object: %s was not removed", id)
+	}
+	return err
+}
+
+func (r *PostgresRepo) CountObjectsByType(ctx context.Context) (map[string]int64, error) {
+	const q = `SELECT object_type, count(*) FROM objects WHERE deleted_at IS NULL GROUP BY object_type`
+	rows, err := r.reader(ctx).Query(ctx, q)
+	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
-	var env envelope.Envelope
-	if err := json.Unmarshal(envJSON, &env); err != nil {
-		return nil, fmt.Errorf("unmarshal: %w", err)
+	defer rows.Close()
+
+	out := make(map[string]int64)
+	for rows.Next() {
+		var typ string
+		var n int64
+		if err := rows.Scan(&typ, &n); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		out[typ] = n
 	}
-	return &env, nil
+	return out, rows.Err()
 }
@@ -0,0 +1,543 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+)
+
+// testSignerKey returns a base64 32-byte signer key derived from name, so
+// fixtures stay distinct and decode like a real ed25519 key.
+func testSignerKey(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestObjectArgs_SignerKey(t *testing.T) {
+	env := &envelope.Envelope{
+		ObjectType: "bid", ObjectVersion: "0.1", ObjectID: "args", CreatedAt: "2025-01-01T00:00:00Z",
+		Payload: json.RawMessage(`{}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("args")}, Signature: "sig",
+	}
+	args, err := objectArgs(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[4] != testSignerKey("args") || args[5] != "" {
+		t.Errorf("signer args = %v, %v", args[4], args[5])
+	}
+
+	env.Signer.PubKey = "pk"
+	if _, err := objectArgs(env); err == nil {
+		t.Error("objectArgs accepted an undecodable signer key")
+	}
+}
+
+func TestListObjectsForTask_LinkedAndUnlinked(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	ids := []string{"corr-task-env", "corr-bid", "corr-accept", "corr-other-bid"}
+	for _, id := range ids {
+		if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id = $1`, id); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+	}
+	insert := func(id, typ, createdAt string, payload map[string]any) {
+		raw, _ := json.Marshal(payload)
+		env := &envelope.Envelope{
+			ObjectType: typ, ObjectVersion: "0.1", ObjectID: id, CreatedAt: createdAt,
+			Payload: raw, Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("pk")}, Signature: "sig",
+		}
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatalf("InsertObject %s: %v", id, err)
+		}
+	}
+	// The task envelope does not carry the structured task_id; it is only
+	// reachable through the link.
+	insert("corr-task-env", "task", "2025-01-01T00:00:00Z", map[string]any{"title": "t"})
+	insert("corr-bid", "bid", "2025-01-01T00:01:00Z", map[string]any{"task_id": "corr-task"})
+	insert("corr-accept", "accept", "2025-01-01T00:02:00Z", map[string]any{"task_id": "corr-task"})
+	insert("corr-other-bid", "bid", "2025-01-01T00:03:00Z", map[string]any{"task_id": "someone-else"})
+
+	got := func(linked string) []string {
+		items, err := repo.ListObjectsForTask(ctx, "corr-task", linked, 100)
+		if err != nil {
+			t.Fatalf("ListObjectsForTask: %v", err)
+		}
+		out := make([]string, len(items))
+		for i, it := range items {
+			out[i] = it.ObjectID
+		}
+		return out
+	}
+
+	if ids := got(""); len(ids) != 2 || ids[0] != "corr-bid" || ids[1] != "corr-accept" {
+		t.Errorf("unlinked = %v, want [corr-bid corr-accept]", ids)
+	}
+	if ids := got("corr-task-env"); len(ids) != 3 || ids[0] != "corr-task-env" {
+		t.Errorf("linked = %v, want corr-task-env first of 3", ids)
+	}
+}
+
+func TestListObjects_IdenticalCreatedAtPagination(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_type = 'artifact' AND object_id LIKE 'bulk-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	// Sub-microsecond digits: Postgres rounds to microseconds, so cursors must
+	// come from the stored value rather than this string.
+	const createdAt = "2030-01-01T00:00:00.1234567+02:00"
+	const n = 500
+	for i := 0; i < n; i++ {
+		env := &envelope.Envelope{
+			ObjectType: "artifact", ObjectVersion: "0.1", ObjectID: fmt.Sprintf("bulk-%03d", i),
+			CreatedAt: createdAt, Payload: json.RawMessage(`{}`),
+			Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("bulk")}, Signature: "sig",
+		}
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatalf("InsertObject %d: %v", i, err)
+		}
+	}
+
+	for _, limit := range []int{1, 7, 50, 499, 500} {
+		seen := map[string]bool{}
+		var cursor *Cursor
+		for pages := 0; ; pages++ {
+			if pages > n+1 {
+				t.Fatalf("limit %d: pagination did not terminate", limit)
+			}
+			items, next, err := repo.ListObjectsBySigner(ctx, testSignerKey("bulk"), "artifact", limit, cursor)
+			if err != nil {
+				t.Fatalf("limit %d: %v", limit, err)
+			}
+			for _, it := range items {
+				if seen[it.ObjectID] {
+					t.Fatalf("limit %d: duplicate %s", limit, it.ObjectID)
+				}
+				seen[it.ObjectID] = true
+			}
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		if len(seen) != n {
+			t.Errorf("limit %d: saw %d objects, want %d", limit, len(seen), n)
+		}
+	}
+}
+
+func TestQueryObjects_SignerTypeWindow(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id LIKE 'qo-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	insert := func(id, typ, signer, createdAt string) {
+		env := &envelope.Envelope{
+			ObjectType: typ, ObjectVersion: "0.1", ObjectID: id, CreatedAt: createdAt,
+			Payload: json.RawMessage(`{}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey(signer)}, Signature: "sig",
+		}
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatalf("InsertObject %s: %v", id, err)
+		}
+	}
+	insert("qo-dec", "task", "qo-x", "2025-12-31T23:59:59Z")
+	insert("qo-jan-1", "task", "qo-x", "2026-01-01T00:00:00Z")
+	insert("qo-jan-2", "task", "qo-x", "2026-01-15T00:00:00Z")
+	insert("qo-jan-3", "task", "qo-x", "2026-01-15T00:00:00Z")
+	insert("qo-jan-bid", "bid", "qo-x", "2026-01-20T00:00:00Z")
+	insert("qo-jan-other", "task", "qo-y", "2026-01-20T00:00:00Z")
+	insert("qo-feb", "task", "qo-x", "2026-02-01T00:00:00Z")
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	query := func(f ObjectFilter) []string {
+		var ids []string
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatal("pagination did not terminate")
+			}
+			items, next, err := repo.QueryObjects(ctx, f)
+			if err != nil {
+				t.Fatalf("QueryObjects: %v", err)
+			}
+			for _, it := range items {
+				ids = append(ids, it.ObjectID)
+			}
+			if next == nil {
+				return ids
+			}
+			f.Cursor = next
+		}
+	}
+
+	got := query(ObjectFilter{ObjectType: "task", SignerPubKey: testSignerKey("qo-x"), Since: &since, Until: &until, Limit: 1})
+	if want := "qo-jan-3,qo-jan-2,qo-jan-1"; strings.Join(got, ",") != want {
+		t.Errorf("signer+type+window = %v, want %s", got, want)
+	}
+	got = query(ObjectFilter{SignerPubKey: testSignerKey("qo-x"), Since: &since, Limit: 2})
+	if want := "qo-feb,qo-jan-bid,qo-jan-3,qo-jan-2,qo-jan-1"; strings.Join(got, ",") != want {
+		t.Errorf("signer+since = %v, want %s", got, want)
+	}
+	got = query(ObjectFilter{SignerPubKey: testSignerKey("qo-x"), ObjectType: "task", Until: &since, Limit: 50})
+	if want := "qo-dec"; strings.Join(got, ",") != want {
+		t.Errorf("signer+type+until = %v, want %s", got, want)
+	}
+}
+
+func TestQueryObjects_TypeWindowBoundaries(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id LIKE 'tw-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	for id, createdAt := range map[string]string{
+		"tw-before": "2031-12-31T23:59:59.999Z",
+		"tw-start":  "2032-01-01T00:00:00Z",
+		"tw-offset": "2032-01-01T05:00:00+05:00", // same instant as tw-start
+		"tw-mid":    "2032-01-15T00:00:00Z",
+		"tw-end":    "2032-02-01T00:00:00Z",
+	} {
+		env := &envelope.Envelope{
+			ObjectType: "artifact", ObjectVersion: "0.1", ObjectID: id, CreatedAt: createdAt,
+			Payload: json.RawMessage(`{}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("tw-signer")}, Signature: "sig",
+		}
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatalf("InsertObject %s: %v", id, err)
+		}
+	}
+
+	after := time.Date(2032, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2032, 2, 1, 0, 0, 0, 0, time.UTC)
+	f := ObjectFilter{ObjectType: "artifact", Since: &after, Until: &before, Limit: 1}
+	var ids []string
+	for {
+		items, next, err := repo.QueryObjects(ctx, f)
+		if err != nil {
+			t.Fatalf("QueryObjects: %v", err)
+		}
+		for _, it := range items {
+			ids = append(ids, it.ObjectID)
+		}
+		if next == nil {
+			break
+		}
+		f.Cursor = next
+	}
+	if want := "tw-mid,tw-start,tw-offset"; strings.Join(ids, ",") != want {
+		t.Errorf("window = %v, want %s (start inclusive, end exclusive)", ids, want)
+	}
+}
+
+func TestQueryObjects_ReceivedOrderSeesBackdated(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id LIKE 'ro-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	insert := func(id, createdAt string) {
+		env := &envelope.Envelope{
+			ObjectType: "bid", ObjectVersion: "0.1", ObjectID: id, CreatedAt: createdAt,
+			Payload: json.RawMessage(`{}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("ro-signer")}, Signature: "sig",
+		}
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatalf("InsertObject %s: %v", id, err)
+		}
+	}
+	sync := func(f ObjectFilter) ([]string, *Cursor) {
+		var ids []string
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatal("pagination did not terminate")
+			}
+			items, next, err := repo.QueryObjects(ctx, f)
+			if err != nil {
+				t.Fatalf("QueryObjects: %v", err)
+			}
+			for _, it := range items {
+				if it.FirstSeenAt.IsZero() {
+					t.Errorf("%s: first_seen_at not set", it.ObjectID)
+				}
+				ids = append(ids, it.ObjectID)
+			}
+			if len(items) == 0 {
+				return ids, f.Cursor
+			}
+			f.Cursor = next
+		}
+	}
+
+	insert("ro-1", "2026-01-01T00:00:00Z")
+	insert("ro-2", "2026-01-02T00:00:00Z")
+	f := ObjectFilter{SignerPubKey: testSignerKey("ro-signer"), Order: OrderReceived, Limit: 1}
+	got, cursor := sync(f)
+	if strings.Join(got, ",") != "ro-1,ro-2" || cursor == nil || cursor.Order != OrderReceived {
+		t.Fatalf("initial sync = %v, cursor %+v", got, cursor)
+	}
+
+	// Claims to predate everything already synced.
+	insert("ro-backdated", "2000-01-01T00:00:00Z")
+	f.Cursor = cursor
+	if got, _ := sync(f); strings.Join(got, ",") != "ro-backdated" {
+		t.Errorf("forward sync = %v, want [ro-backdated]", got)
+	}
+
+	// In created order the same object sorts behind pages already read.
+	items, _, err := repo.QueryObjects(ctx, ObjectFilter{SignerPubKey: testSignerKey("ro-signer"), Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ObjectID != "ro-2" || items[1].ObjectID != "ro-1" {
+		t.Errorf("created order first page = %v", items)
+	}
+}
+
+func TestInsertObject_CreatedAtOffsets(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id LIKE 'tz-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	// The same instant, sent with and without an offset.
+	for id, createdAt := range map[string]string{"tz-offset": "2026-01-01T05:00:00+05:00", "tz-utc": "2026-01-01T00:00:00Z"} {
+		env := &envelope.Envelope{
+			ObjectType: "bid", ObjectVersion: "0.1", ObjectID: id, CreatedAt: createdAt,
+			Payload: json.RawMessage(`{}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("tz-signer")}, Signature: "sig",
+		}
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatalf("InsertObject %s: %v", id, err)
+		}
+	}
+
+	want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for id, raw := range map[string]string{"tz-offset": "2026-01-01T05:00:00+05:00", "tz-utc": "2026-01-01T00:00:00Z"} {
+		var col time.Time
+		if err := taskRepo.pool.QueryRow(ctx, `SELECT created_at FROM objects WHERE object_id = $1`, id).Scan(&col); err != nil {
+			t.Fatal(err)
+		}
+		if !col.Equal(want) {
+			t.Errorf("%s: created_at column = %s, want %s", id, col, want)
+		}
+		obj, err := repo.GetObjectByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj.CreatedAt != raw {
+			t.Errorf("%s: envelope created_at = %q, want the signed string %q", id, obj.CreatedAt, raw)
+		}
+	}
+}
+
+func TestObjectNotes_LeaveEnvelopeUntouched(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id = 'notes-bid'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	env := &envelope.Envelope{
+		ObjectType: "bid", ObjectVersion: "0.1", ObjectID: "notes-bid", CreatedAt: "2026-01-01T00:00:00Z",
+		Payload: json.RawMessage(`{"task_id":"t"}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("notes-signer")}, Signature: "sig",
+	}
+	if err := repo.InsertObject(ctx, env); err != nil {
+		t.Fatal(err)
+	}
+	before, err := repo.GetObjectByID(ctx, "notes-bid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := repo.GetObjectNotes(ctx, "notes-bid"); err != nil || n.Notes != "" || n.UpdatedAt != nil {
+		t.Fatalf("initial notes = %+v, %v", n, err)
+	}
+	n, err := repo.SetObjectNotes(ctx, "notes-bid", "flagged for review")
+	if err != nil || n.Notes != "flagged for review" || n.UpdatedAt == nil {
+		t.Fatalf("SetObjectNotes = %+v, %v", n, err)
+	}
+	after, err := repo.GetObjectByID(ctx, "notes-bid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b1, _ := json.Marshal(before.Envelope)
+	b2, _ := json.Marshal(after.Envelope)
+	if string(b1) != string(b2) {
+		t.Errorf("envelope changed:\n%s\n%s", b1, b2)
+	}
+
+	if n, err := repo.SetObjectNotes(ctx, "notes-bid", ""); err != nil || n.Notes != "" {
+		t.Errorf("clear = %+v, %v", n, err)
+	}
+	if _, err := repo.SetObjectNotes(ctx, "notes-missing", "x"); err != ErrNotFound {
+		t.Errorf("unknown object: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDeleteObject_Tombstone(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id LIKE 'del-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	for _, id := range []string{"del-tomb", "del-hard"} {
+		if err := repo.InsertObject(ctx, &envelope.Envelope{
+			ObjectType: "artifact", ObjectVersion: "0.1", ObjectID: id, CreatedAt: "2026-01-01T00:00:00Z",
+			Payload: json.RawMessage(`{"task_id":"del-task"}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("del-signer")}, Signature: "sig",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.DeleteObject(ctx, "del-tomb", true); err != nil {
+		t.Fatalf("DeleteObject(tombstone): %v", err)
+	}
+	var gone *GoneError
+	if _, err := repo.GetObjectByID(ctx, "del-tomb"); !errors.As(err, &gone) || !errors.Is(err, ErrNotFound) || gone.DeletedAt.IsZero() {
+		t.Errorf("GetObjectByID(tombstone): err = %v, want a *GoneError matching ErrNotFound", err)
+	}
+	if err := repo.DeleteObject(ctx, "del-tomb", true); !errors.As(err, &gone) {
+		t.Errorf("second DeleteObject: err = %v, want *GoneError", err)
+	}
+	if err := repo.InsertObject(ctx, &envelope.Envelope{
+		ObjectType: "artifact", ObjectVersion: "0.1", ObjectID: "del-tomb", CreatedAt: "2026-01-01T00:00:00Z",
+		Payload: json.RawMessage(`{}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("del-signer")}, Signature: "sig",
+	}); !errors.Is(err, ErrConflict) {
+		t.Errorf("reinserting a tombstoned id: err = %v, want ErrConflict", err)
+	}
+	if _, err := repo.GetObjectNotes(ctx, "del-tomb"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetObjectNotes(tombstone): err = %v, want ErrNotFound", err)
+	}
+
+	items, _, err := repo.QueryObjects(ctx, ObjectFilter{SignerPubKey: testSignerKey("del-signer"), Limit: 10})
+	if err != nil || len(items) != 1 || items[0].ObjectID != "del-hard" {
+		t.Errorf("QueryObjects = %+v, %v; want only del-hard", items, err)
+	}
+	if envs, err := repo.ListObjectsForTask(ctx, "del-task", "del-tomb", 100); err != nil || len(envs) != 1 {
+		t.Errorf("ListObjectsForTask = %d envelopes, %v; want 1", len(envs), err)
+	}
+
+	if err := repo.DeleteObject(ctx, "del-hard", false); err != nil {
+		t.Fatalf("DeleteObject: %v", err)
+	}
+	if _, err := repo.GetObjectByID(ctx, "del-hard"); !errors.Is(err, ErrNotFound) || errors.As(err, &gone) {
+		t.Errorf("GetObjectByID(removed): err = %v, want plain ErrNotFound", err)
+	}
+	if has, err := taskRepo.HasArtifact(ctx, "del-task"); err != nil || has {
+		t.Errorf("HasArtifact after deleting every artifact = %v, %v", has, err)
+	}
+	if err := repo.DeleteObject(ctx, "del-unknown", true); !errors.Is(err, ErrNotFound) || errors.As(err, &gone) {
+		t.Errorf("DeleteObject(unknown): err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPayloadColumns_FiltersUseIndexes(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id LIKE 'pcol-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	for i, o := range []struct{ id, typ, payload string }{
+		{"pcol-bid-low", "bid", `{"task_id":"pcol-task","amount_wei":"100"}`},
+		{"pcol-bid-high", "bid", `{"task_id":"pcol-task","amount_wei":"1000000000000000000000"}`},
+		{"pcol-bid-text", "bid", `{"task_id":"pcol-task","amount_wei":"lots"}`},
+		{"pcol-artifact", "artifact", `{"task_id":"pcol-task","content_hash":"sha256:pcol"}`},
+	} {
+		if err := repo.InsertObject(ctx, &envelope.Envelope{
+			ObjectType: o.typ, ObjectVersion: "0.1", ObjectID: o.id, CreatedAt: fmt.Sprintf("2026-01-01T00:0%d:00Z", i),
+			Payload: json.RawMessage(o.payload), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("pcol-signer")}, Signature: "sig",
+		}); err != nil {
+			t.Fatalf("InsertObject %s: %v", o.id, err)
+		}
+	}
+
+	ids := func(f ObjectFilter) string {
+		t.Helper()
+		f.Limit = 10
+		items, _, err := repo.QueryObjects(ctx, f)
+		if err != nil {
+			t.Fatalf("QueryObjects(%+v): %v", f, err)
+		}
+		out := make([]string, len(items))
+		for i, it := range items {
+			out[i] = it.ObjectID
+		}
+		return strings.Join(out, ",")
+	}
+	if got := ids(ObjectFilter{ObjectType: "bid", TaskID: "pcol-task"}); got != "pcol-bid-text,pcol-bid-high,pcol-bid-low" {
+		t.Errorf("task_id = %s", got)
+	}
+	// Past int64 and uint64, and a non-numeric amount never matches.
+	if got := ids(ObjectFilter{ObjectType: "bid", MinAmountWei: "18446744073709551616"}); got != "pcol-bid-high" {
+		t.Errorf("min_amount_wei = %s", got)
+	}
+	if got := ids(ObjectFilter{TaskID: "pcol-task", MaxAmountWei: "100"}); got != "pcol-bid-low" {
+		t.Errorf("max_amount_wei = %s", got)
+	}
+	if got := ids(ObjectFilter{ContentHash: "sha256:pcol"}); got != "pcol-artifact" {
+		t.Errorf("content_hash = %s", got)
+	}
+	if has, err := taskRepo.HasArtifact(ctx, "pcol-task"); err != nil || !has {
+		t.Errorf("HasArtifact = %v, %v", has, err)
+	}
+
+	// With sequential scans priced out, each filter must be answerable
+	// from its index.
+	for _, tc := range []struct {
+		index, query string
+		args         []any
+	}{
+		{"idx_objects_payload_task_id", `SELECT object_id FROM objects WHERE payload_task_id = $1 AND object_type = 'bid' ORDER BY created_at DESC, object_id DESC LIMIT 10`, []any{"pcol-task"}},
+		{"idx_objects_bid_amount", `SELECT object_id FROM objects WHERE payload_amount_wei >= $1::numeric`, []any{"18446744073709551616"}},
+		{"idx_objects_artifact_content_hash", `SELECT object_id FROM objects WHERE payload_content_hash = $1`, []any{"sha256:pcol"}},
+	} {
+		tx, err := taskRepo.pool.Begin(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec(ctx, `SET LOCAL enable_seqscan = off`); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := tx.Query(ctx, `EXPLAIN `+tc.query, tc.args...)
+		if err != nil {
+			t.Fatalf("EXPLAIN %s: %v", tc.index, err)
+		}
+		var plan strings.Builder
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				t.Fatal(err)
+			}
+			plan.WriteString(line + "\n")
+		}
+		rows.Close()
+		tx.Rollback(ctx)
+		if !strings.Contains(plan.String(), tc.index) {
+			t.Errorf("plan does not use %s:\n%s", tc.index, plan.String())
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+// ErrQueueFull is returned by QueuedRepo.Enqueue when the queue has no room
+// or has been closed.
+var ErrQueueFull = errors.New("ingest queue full")
+
+// BatchInserter is implemented by repos that can store several objects in
+// one round trip. Each returned error corresponds to the envelope at the
+// same index.
+type BatchInserter interface {
+	InsertObjects(ctx context.Context, envs []*envelope.Envelope) []error
+}
+
+// queueFlushTimeout bounds a single batch write.
+const queueFlushTimeout = 10 * time.Second
+
+var ingestedObjects = metrics.NewCounterVec("amn_ingest_objects_total",
+	"Objects written by the async ingestion queue, by result (stored, conflict, failed).", "result")
+
+// QueuedRepo wraps a Repo with a bounded queue drained by a pool of workers
+// that insert objects in batches. InsertObject stays synchronous; callers
+// opt in to async writes with Enqueue.
+type QueuedRepo struct {
+	Repo
+
+	batchSize int
+	ch        chan *envelope.Envelope
+	wg        sync.WaitGroup
+	onStored  func(context.Context, *envelope.Envelope)
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewQueuedRepo wraps repo and starts workers goroutines. Each worker writes
+// up to batchSize queued objects at a time, using InsertObjects when repo
+// implements BatchInserter. Call Close to drain the queue.
+func NewQueuedRepo(repo Repo, workers, queueSize, batchSize int) *QueuedRepo {
+	q := &QueuedRepo{
+		Repo:      repo,
+		batchSize: batchSize,
+		ch:        make(chan *envelope.Envelope, queueSize),
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Enqueue queues env for insertion without waiting. It returns ErrQueueFull
+// instead of blocking when the queue is at capacity.
+func (q *QueuedRepo) Enqueue(env *envelope.Envelope) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueFull
+	}
+	select {
+	case q.ch <- env:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Depth returns the number of queued objects not yet picked up by a worker.
+func (q *QueuedRepo) Depth() int {
+	return len(q.ch)
+}
+
+// Close stops accepting objects and waits until every queued object has
+// been written.
+func (q *QueuedRepo) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+// OnStored sets fn to run on the worker after each queued object is stored.
+// Call it before the first Enqueue.
+func (q *QueuedRepo) OnStored(fn func(context.Context, *envelope.Envelope)) {
+	q.onStored = fn
+}
+
+// RegisterMetrics exposes the queue depth in the default metrics registry.
+func (q *QueuedRepo) RegisterMetrics() {
+	metrics.Register(metrics.GaugeFunc{
+		Name: "amn_ingest_queue_depth",
+		Help: "Objects waiting in the async ingestion queue.",
+		Fn:   func() []metrics.Sample { return []metrics.Sample{{Value: float64(q.Depth())}} },
+	})
+}
+
+// work takes whatever is already queued, up to batchSize, after each
+// receive, so batches grow with load without delaying a lone object.
+func (q *QueuedRepo) work() {
+	defer q.wg.Done()
+	batch := make([]*envelope.Envelope, 0, q.batchSize)
+	for env := range q.ch {
+		batch = append(batch[:0], env)
+	fill:
+		for len(batch) < q.batchSize {
+			select {
+			case env, ok := <-q.ch:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, env)
+			default:
+				break fill
+			}
+		}
+		q.flush(batch)
+	}
+}
+
+func (q *QueuedRepo) flush(batch []*envelope.Envelope) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueFlushTimeout)
+	defer cancel()
+
+	var errs []error
+	if b, ok := q.Repo.(BatchInserter); ok {
+		errs = b.InsertObjects(ctx, batch)
+	} else {
+		errs = make([]error, len(batch))
+		for i, env := range batch {
+			errs[i] = q.Repo.InsertObject(ctx, env)
+		}
+	}
+
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			ingestedObjects.Inc("stored")
+			if q.onStored != nil {
+				q.onStored(ctx, batch[i])
+			}
+		case errors.Is(err, ErrConflict):
+			ingestedObjects.Inc("conflict")
+		default:
+			ingestedObjects.Inc("failed")
+			log.Printf("[ingest] object_id=%s not stored: %v", batch[i].ObjectID, err)
+		}
+	}
+}
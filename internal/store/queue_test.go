@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+)
+
+// batchRepo records batched inserts; object IDs starting with "dup" conflict.
+type batchRepo struct {
+	Repo
+
+	mu      sync.Mutex
+	stored  []string
+	batches []int
+}
+
+func (r *batchRepo) InsertObjects(_ context.Context, envs []*envelope.Envelope) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, len(envs))
+	errs := make([]error, len(envs))
+	for i, env := range envs {
+		if strings.HasPrefix(env.ObjectID, "dup") {
+			errs[i] = ErrConflict
+			continue
+		}
+		r.stored = append(r.stored, env.ObjectID)
+	}
+	return errs
+}
+
+func TestQueuedRepo_DrainsInBatches(t *testing.T) {
+	inner := &batchRepo{}
+	q := NewQueuedRepo(inner, 2, 100, 8)
+	conflictsBefore := ingestedObjects.Value("conflict")
+
+	for i := 0; i < 40; i++ {
+		if err := q.Enqueue(&envelope.Envelope{ObjectID: fmt.Sprintf("obj-%02d", i)}); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+	}
+	if err := q.Enqueue(&envelope.Envelope{ObjectID: "dup-1"}); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	if len(inner.stored) != 40 {
+		t.Errorf("stored %d objects, want 40", len(inner.stored))
+	}
+	for _, n := range inner.batches {
+		if n > 8 {
+			t.Errorf("batch of %d exceeds batch size 8", n)
+		}
+	}
+	if n := ingestedObjects.Value("conflict") - conflictsBefore; n != 1 {
+		t.Errorf("conflict metric += %v, want 1", n)
+	}
+	if err := q.Enqueue(&envelope.Envelope{ObjectID: "late"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("enqueue after Close: %v, want ErrQueueFull", err)
+	}
+}
+
+func TestQueuedRepo_FullQueue(t *testing.T) {
+	q := NewQueuedRepo(&batchRepo{}, 0, 1, 1) // no workers: nothing drains
+	if err := q.Enqueue(&envelope.Envelope{ObjectID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(&envelope.Envelope{ObjectID: "b"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("second enqueue: %v, want ErrQueueFull", err)
+	}
+	if q.Depth() != 1 {
+		t.Errorf("depth = %d, want 1", q.Depth())
+	}
+}
+
+func TestInsertObjects_Batch(t *testing.T) {
+	taskRepo := testPool(t)
+	repo := NewPostgresRepo(taskRepo.pool)
+	ctx := context.Background()
+
+	ids := []string{"batch-1", "batch-2"}
+	for _, id := range ids {
+		if _, err := taskRepo.pool.Exec(ctx, `DELETE FROM objects WHERE object_id = $1`, id); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+	}
+	env := func(id string) *envelope.Envelope {
+		return &envelope.Envelope{
+			ObjectType: "bid", ObjectVersion: "0.1", ObjectID: id, CreatedAt: "2025-01-01T00:00:00Z",
+			Payload: []byte(`{}`), Signer: envelope.Signer{Algo: "ed25519", PubKey: testSignerKey("pk")}, Signature: "sig",
+		}
+	}
+	if err := repo.InsertObject(ctx, env("batch-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := repo.InsertObjects(ctx, []*envelope.Envelope{env("batch-1"), env("batch-2"), {ObjectID: "bad", CreatedAt: "nope"}})
+	if !errors.Is(errs[0], ErrConflict) || errs[1] != nil || errs[2] == nil {
+		t.Fatalf("errs = %v, want [conflict, nil, error]", errs)
+	}
+	if _, err := repo.GetObjectByID(ctx, "batch-2"); err != nil {
+		t.Errorf("batch-2 not stored: %v", err)
+	}
+}
+
+func TestQueuedRepo_OnStored(t *testing.T) {
+	q := NewQueuedRepo(&batchRepo{}, 1, 10, 4)
+	var stored []string
+	q.OnStored(func(_ context.Context, env *envelope.Envelope) { stored = append(stored, env.ObjectID) })
+	for _, id := range []string{"a", "dup-b", "c"} {
+		if err := q.Enqueue(&envelope.Envelope{ObjectID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Close()
+	if !slices.Equal(stored, []string{"a", "c"}) {
+		t.Errorf("OnStored saw %v, want the stored objects in order", stored)
+	}
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StuckAcceptedAfter is how long a task may sit in the off-chain "accepted"
+// state without an onchain WorkerSet before startup recovery reopens it.
+const StuckAcceptedAfter = 24 * time.Hour
+
+// RecoverStuckTasks reopens tasks left in a transitional state by a previous
+// run. Tasks that have been "accepted" off-chain for longer than
+// StuckAcceptedAfter without any onchain progress are reset to "created" and
+// their worker cleared, so other workers can accept them.
+//
+// Only the off-chain "accepted" state is transitional in the current schema;
+// onchain states are owned by the chain watcher and are never touched here.
+// Each recovery is also recorded as a task_status_recovered audit event. It
+// returns the number of recovered tasks.
+func RecoverStuckTasks(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	const q = `
+UPDATE tasks
+SET status = $1, worker_address = NULL, updated_at = now()
+WHERE status = $2
+  AND updated_at < now() - make_interval(secs => $3)
+  AND onchain_created_at IS NULL
+  AND deleted_at IS NULL
+RETURNING task_id, task_hash`
+	rows, err := pool.Query(ctx, q, TaskStatusCreated, TaskStatusAccepted, StuckAcceptedAfter.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("recover stuck tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var recovered []map[string]any
+	for rows.Next() {
+		var taskID, taskHash string
+		if err := rows.Scan(&taskID, &taskHash); err != nil {
+			return int64(len(recovered)), fmt.Errorf("scan recovered task: %w", err)
+		}
+		log.Printf("[recovery] taskID=%s taskHash=%s accepted -> created — audit: task_status_recovered", taskID, taskHash)
+		recovered = append(recovered, map[string]any{"task_id": taskID, "task_hash": taskHash, "from": TaskStatusAccepted, "to": TaskStatusCreated})
+	}
+	if err := rows.Err(); err != nil {
+		return int64(len(recovered)), err
+	}
+
+	for _, d := range recovered {
+		// Type is audit.TaskStatusRecovered; audit imports this package.
+		if err := insertAuditEvent(ctx, pool, &AuditEvent{Type: "task_status_recovered", Severity: AuditSeverityWarn, Detail: d}); err != nil {
+			log.Printf("[recovery] %v", err)
+		}
+	}
+	return int64(len(recovered)), nil
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/AgentMesh-Net/indexer-go/migrations"
+)
+
+// testPool connects to AMN_TEST_DB_DSN and applies migrations, skipping the
+// test when no database is configured.
+func testPool(t *testing.T) *PostgresTaskRepo {
+	t.Helper()
+	dsn := os.Getenv("AMN_TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("AMN_TEST_DB_DSN not set; skipping database test")
+	}
+	ctx := context.Background()
+	pool, err := NewPool(ctx, dsn)
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	runner := migrations.NewRunner()
+	runner.Logf = nil
+	if _, err := runner.Apply(ctx, pool); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewPostgresTaskRepo(pool)
+}
+
+func TestRecoverStuckTasks(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	insert := func(id, hash, status, age string) {
+		t.Helper()
+		_, err := repo.pool.Exec(ctx, `
+INSERT INTO tasks (task_id, task_hash, chain_id, escrow_address, employer_address, worker_address,
+                   amount_wei, deadline_unix, status, created_at, updated_at)
+VALUES ($1, $2, 1, $5, $6, $7, '1', 1, $3, now() - $4::interval, now() - $4::interval)
+ON CONFLICT (task_id) DO UPDATE SET status = EXCLUDED.status, updated_at = EXCLUDED.updated_at,
+                                    worker_address = EXCLUDED.worker_address`,
+			id, hash, status, age, testEscrow, testEmployer, testWorker)
+		if err != nil {
+			t.Fatalf("insert %s: %v", id, err)
+		}
+	}
+	insert("recovery-stuck", "0xrecovery-stuck", TaskStatusAccepted, "48 hours")
+	insert("recovery-fresh", "0xrecovery-fresh", TaskStatusAccepted, "1 hour")
+	insert("recovery-onchain", "0xrecovery-onchain", TaskStatusAcceptedOnchain, "48 hours")
+
+	n, err := RecoverStuckTasks(ctx, repo.pool)
+	if err != nil {
+		t.Fatalf("RecoverStuckTasks: %v", err)
+	}
+	if n < 1 {
+		t.Fatalf("expected at least one recovered task, got %d", n)
+	}
+
+	for id, want := range map[string]string{
+		"recovery-stuck":   TaskStatusCreated,
+		"recovery-fresh":   TaskStatusAccepted,
+		"recovery-onchain": TaskStatusAcceptedOnchain,
+	} {
+		task, err := repo.GetTask(ctx, id)
+		if err != nil {
+			t.Fatalf("GetTask %s: %v", id, err)
+		}
+		if task.Status != want {
+			t.Errorf("%s: status = %s, want %s", id, task.Status, want)
+		}
+	}
+	if task, _ := repo.GetTask(ctx, "recovery-stuck"); task.WorkerAddress != "" {
+		t.Errorf("recovered task should have no worker, got %q", task.WorkerAddress)
+	}
+}
+
+func TestListAuditEvents_Pagination(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const typ = "test_audit_pagination"
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM audit_events WHERE type = $1`, typ); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	for i, sev := range []string{AuditSeverityInfo, AuditSeverityWarn, AuditSeverityCritical, AuditSeverityWarn, AuditSeverityWarn} {
+		if err := repo.InsertAuditEvent(ctx, &AuditEvent{Type: typ, Severity: sev, Detail: map[string]any{"n": i}}); err != nil {
+			t.Fatalf("InsertAuditEvent: %v", err)
+		}
+	}
+
+	f := AuditFilter{Type: typ, Severity: AuditSeverityWarn}
+	var seen []int64
+	var cursor *Cursor
+	for page := 0; page < 5; page++ {
+		events, next, err := repo.ListAuditEvents(ctx, f, 2, cursor)
+		if err != nil {
+			t.Fatalf("ListAuditEvents: %v", err)
+		}
+		for _, e := range events {
+			if e.Severity != AuditSeverityWarn {
+				t.Errorf("unexpected severity %q", e.Severity)
+			}
+			seen = append(seen, e.ID)
+		}
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected 3 warn events across pages, got %v", seen)
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i] >= seen[i-1] {
+			t.Errorf("events not newest-first: %v", seen)
+		}
+	}
+}
+
+func TestAckAuditEvent(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const typ = "test_audit_ack"
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM audit_events WHERE type = $1`, typ); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := repo.InsertAuditEvent(ctx, &AuditEvent{Type: typ, Severity: AuditSeverityCritical}); err != nil {
+			t.Fatalf("InsertAuditEvent: %v", err)
+		}
+	}
+	events, _, err := repo.ListAuditEvents(ctx, AuditFilter{Type: typ}, 10, nil)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("ListAuditEvents: %v, %d events", err, len(events))
+	}
+
+	acked, err := repo.AckAuditEvent(ctx, events[0].ID, true, "oncall")
+	if err != nil {
+		t.Fatalf("AckAuditEvent: %v", err)
+	}
+	if acked.AcknowledgedAt == nil || acked.AcknowledgedBy != "oncall" {
+		t.Errorf("acked event = %+v", acked)
+	}
+
+	open, _, err := repo.ListAuditEvents(ctx, AuditFilter{Type: typ, Unacknowledged: true}, 10, nil)
+	if err != nil || len(open) != 1 || open[0].ID != events[1].ID {
+		t.Fatalf("unacknowledged = %+v, err = %v", open, err)
+	}
+
+	unacked, err := repo.AckAuditEvent(ctx, events[0].ID, false, "")
+	if err != nil || unacked.AcknowledgedAt != nil || unacked.AcknowledgedBy != "" {
+		t.Errorf("unack = %+v, err = %v", unacked, err)
+	}
+
+	if _, err := repo.AckAuditEvent(ctx, -1, true, "oncall"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
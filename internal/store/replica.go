@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaOKKey is the context key set by AllowReplica.
+type replicaOKKey struct{}
+
+// AllowReplica returns a context under which read methods may be served by
+// the read replica (AMN_DB_REPLICA_DSN), if one is configured. A replica can
+// lag the primary, so only reads that tolerate missing a just-committed
+// write should carry it; the API sets it for GET requests. Everything else,
+// including the chain watcher, hooks and reads done on a write path, uses
+// the primary.
+func AllowReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaOKKey{}, true)
+}
+
+func replicaAllowed(ctx context.Context) bool {
+	v, _ := ctx.Value(replicaOKKey{}).(bool)
+	return v
+}
+
+// WithReplica routes r's read methods to replica under AllowReplica. It
+// returns r.
+func (r *PostgresRepo) WithReplica(replica *pgxpool.Pool) *PostgresRepo {
+	r.replica = replica
+	return r
+}
+
+// reader is the pool read methods query: the replica when one is set and
+// ctx allows it, else the primary.
+func (r *PostgresRepo) reader(ctx context.Context) *pgxpool.Pool {
+	if r.replica != nil && replicaAllowed(ctx) {
+		return r.replica
+	}
+	return r.pool
+}
+
+// WithReplica routes r's read methods to replica under AllowReplica. It
+// returns r.
+func (r *PostgresTaskRepo) WithReplica(replica *pgxpool.Pool) *PostgresTaskRepo {
+	r.replica = replica
+	return r
+}
+
+// reader is as for PostgresRepo.reader.
+func (r *PostgresTaskRepo) reader(ctx context.Context) *pgxpool.Pool {
+	if r.replica != nil && replicaAllowed(ctx) {
+		return r.replica
+	}
+	return r.pool
+}
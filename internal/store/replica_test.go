@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestReader_RoutesToReplica(t *testing.T) {
+	ctx := context.Background()
+	// Pools connect lazily; these are never used.
+	primary, err := pgxpool.New(ctx, "postgres://primary.invalid/indexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+	replica, err := pgxpool.New(ctx, "postgres://replica.invalid/indexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+	allowed := AllowReplica(ctx)
+
+	repo, tasks := NewPostgresRepo(primary), NewPostgresTaskRepo(primary)
+	if repo.reader(allowed) != primary || tasks.reader(allowed) != primary {
+		t.Error("without a replica, reads must use the primary")
+	}
+	repo.WithReplica(replica)
+	tasks.WithReplica(replica)
+	if repo.reader(ctx) != primary || tasks.reader(ctx) != primary {
+		t.Error("reads without AllowReplica must use the primary")
+	}
+	if repo.reader(allowed) != replica || tasks.reader(allowed) != replica {
+		t.Error("reads under AllowReplica must use the replica")
+	}
+}
+
+func TestReplica_ReadsUseReplicaPool(t *testing.T) {
+	primary := testPool(t)
+	replica, err := NewPool(context.Background(), os.Getenv("AMN_TEST_DB_DSN"))
+	if err != nil {
+		t.Fatalf("NewPool(replica): %v", err)
+	}
+	t.Cleanup(replica.Close)
+	tasks := NewPostgresTaskRepo(primary.pool).WithReplica(replica)
+	repo := NewPostgresRepo(primary.pool).WithReplica(replica)
+	ctx := context.Background()
+
+	task := &Task{
+		TaskID: "replica-1", TaskHash: "0xreplica1", ChainID: 1, EscrowAddress: testEscrow,
+		EmployerAddress: testEmployer, AmountWei: "1", DeadlineUnix: 1, Status: TaskStatusCreated,
+	}
+	_, _ = primary.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id = $1`, task.TaskID)
+	acquired := func() (int64, int64) {
+		return primary.pool.Stat().AcquireCount(), replica.Stat().AcquireCount()
+	}
+
+	p0, r0 := acquired()
+	if err := tasks.InsertTask(ctx, task, 0); err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+	if _, err := tasks.GetTask(ctx, task.TaskID); err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	p1, r1 := acquired()
+	if p1 == p0 || r1 != r0 {
+		t.Errorf("write and plain read: primary %d->%d, replica %d->%d; want primary only", p0, p1, r0, r1)
+	}
+
+	allowed := AllowReplica(ctx)
+	if _, err := tasks.GetTask(allowed, task.TaskID); err != nil {
+		t.Fatalf("GetTask(replica): %v", err)
+	}
+	if _, err := tasks.ListTasks(allowed, TaskFilter{Limit: 1}); err != nil {
+		t.Fatalf("ListTasks(replica): %v", err)
+	}
+	if _, err := tasks.CountTasksByStatus(allowed); err != nil {
+		t.Fatalf("CountTasksByStatus(replica): %v", err)
+	}
+	if _, _, err := repo.ListObjects(allowed, "bid", 1, nil); err != nil {
+		t.Fatalf("ListObjects(replica): %v", err)
+	}
+	p2, r2 := acquired()
+	if p2 != p1 || r2-r1 != 4 {
+		t.Errorf("replica reads: primary %d->%d, replica %d->%d; want 4 on the replica only", p1, p2, r1, r2)
+	}
+}
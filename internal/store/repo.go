@@ -2,14 +2,55 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
 )
 
 // Cursor represents a pagination cursor for list queries.
 type Cursor struct {
+	// CreatedAt is the sort time of the last item returned: created_at, or
+	// inserted_at for OrderReceived cursors.
 	CreatedAt string `json:"c"`
 	ObjectID  string `json:"i"`
+	// Order is the ObjectFilter.Order the cursor was issued for. Empty for
+	// OrderCreated.
+	Order string `json:"o,omitempty"`
+	// IssuedAt is the unix time the cursor was handed out. Zero for cursors
+	// issued before staleness checks existed.
+	IssuedAt int64 `json:"t,omitempty"`
+}
+
+// Object list orders.
+const (
+	// OrderCreated sorts newest first by the signer-supplied created_at.
+	OrderCreated = ""
+	// OrderReceived sorts oldest first by inserted_at, the time this indexer
+	// stored the object. A backdated created_at cannot place an object behind
+	// a page a consumer has already read, so this is the order to sync in.
+	OrderReceived = "received"
+	// OrderUpdated marks cursors issued by ListTasksUpdatedSince.
+	OrderUpdated = "updated"
+)
+
+// ObjectFilter selects objects for QueryObjects. Zero fields do not filter.
+type ObjectFilter struct {
+	ObjectType   string
+	SignerPubKey string
+	Since        *time.Time // inclusive, on created_at
+	Until        *time.Time // exclusive, on created_at
+	// Payload filters, on the generated payload_* columns.
+	TaskID       string
+	ContentHash  string // artifacts only
+	MinAmountWei string // bids only; decimal, inclusive
+	MaxAmountWei string // bids only; decimal, inclusive
+	Order        string // OrderCreated or OrderReceived
+	Limit        int
+	Cursor       *Cursor
+
+	// ReceivedBefore bounds inserted_at (exclusive) in OrderReceived; zero
+	// means no bound.
+	ReceivedBefore time.Time
 }
 
 // Repo defines the storage interface for protocol objects.
@@ -19,8 +60,43 @@ type Repo interface {
 
 	// ListObjects returns objects of the given type with cursor-based pagination.
 	// Results are ordered by created_at DESC, object_id DESC.
-	ListObjects(ctx context.Context, objectType string, limit int, cursor *Cursor) (items []envelope.Envelope, next *Cursor, err error)
+	ListObjects(ctx context.Context, objectType string, limit int, cursor *Cursor) (items []Object, next *Cursor, err error)
+
+	// ListObjectsBySigner returns objects signed by signerPubKey, optionally
+	// restricted to objectType (empty = all types), with the same ordering and
+	// pagination as ListObjects.
+	ListObjectsBySigner(ctx context.Context, signerPubKey, objectType string, limit int, cursor *Cursor) (items []Object, next *Cursor, err error)
+
+	// QueryObjects returns objects matching every set field of f, with the
+	// same ordering and pagination as ListObjects unless f.Order is
+	// OrderReceived. In received order next is set for every non-empty page,
+	// so a consumer can resume from the last object it saw.
+	QueryObjects(ctx context.Context, f ObjectFilter) (items []Object, next *Cursor, err error)
+
+	// ListObjectsForTask returns the objects whose payload.task_id is taskID,
+	// plus the object linkedObjectID if non-empty, oldest first and at most
+	// limit of them.
+	ListObjectsForTask(ctx context.Context, taskID, linkedObjectID string, limit int) ([]envelope.Envelope, error)
 
 	// GetObjectByID retrieves a single object by object_id.
-	GetObjectByID(ctx context.Context, id string) (*envelope.Envelope, error)
+	GetObjectByID(ctx context.Context, id string) (*Object, error)
+
+	// DeleteObject removes an object from every read. With tombstone the row
+	// stays behind with deleted_at set, GetObjectByID returns a *GoneError
+	// for it and its object_id cannot be reused; otherwise the row is
+	// dropped. Returns ErrNotFound for an unknown object and a *GoneError
+	// for one already tombstoned.
+	DeleteObject(ctx context.Context, id string, tombstone bool) error
+
+	// CountObjectsByType returns the number of stored objects per object_type.
+	CountObjectsByType(ctx context.Context) (map[string]int64, error)
+
+	// GetObjectNotes returns the operator annotation on an object. Returns
+	// ErrNotFound if the object does not exist.
+	GetObjectNotes(ctx context.Context, objectID string) (*ObjectNotes, error)
+
+	// SetObjectNotes replaces the operator annotation on an object; empty
+	// notes clear it. The envelope is not touched. Returns ErrNotFound if the
+	// object does not exist.
+	SetObjectNotes(ctx context.Context, objectID, notes string) (*ObjectNotes, error)
 }
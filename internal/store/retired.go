@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListOpenTaskChains returns the distinct chain IDs of open tasks, lowest
+// first.
+func (r *PostgresTaskRepo) ListOpenTaskChains(ctx context.Context) ([]int, error) {
+	const q = `SELECT DISTINCT chain_id FROM tasks WHERE status = ANY($1) AND deleted_at IS NULL ORDER BY chain_id`
+	rows, err := r.pool.Query(ctx, q, OpenTaskStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("list open task chains: %w", err)
+	}
+	defer rows.Close()
+	var out []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan chain id: %w", err)
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// SetChainRetired flags the open tasks on chainID as being on a retired
+// chain, keeping the time of an earlier flag, or with retired false clears
+// the flag from every task on it. It returns the number of tasks changed.
+func (r *PostgresTaskRepo) SetChainRetired(ctx context.Context, chainID int, retired bool) (int64, error) {
+	q := `UPDATE tasks SET chain_retired_at = NULL, updated_at = now() WHERE chain_id = $1 AND chain_retired_at IS NOT NULL`
+	args := []any{chainID}
+	if retired {
+		q = `UPDATE tasks SET chain_retired_at = now(), updated_at = now()
+WHERE chain_id = $1 AND status = ANY($2) AND chain_retired_at IS NULL AND deleted_at IS NULL`
+		args = append(args, OpenTaskStatuses)
+	}
+	tag, err := r.pool.Exec(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("set chain retired: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrSchemaNotReady is returned by SchemaReady when tables or columns the
+// indexer needs are missing, usually because migrations have not been
+// applied (or one failed part way).
+var ErrSchemaNotReady = errors.New("database schema not ready")
+
+// requiredSchema lists the tables the current migrations create, with the
+// columns added to them by later migrations. A table listed without columns
+// only has to exist.
+var requiredSchema = []struct {
+	table   string
+	columns []string
+}{
+	{"objects", []string{"deleted_at", "payload_task_id", "payload_amount_wei", "payload_content_hash"}},
+	{"tasks", []string{"employer_sequence", "created_tx_hash", "released_tx_hash", "visibility", "allowed_workers", "token_address", "raw_request",
+		"onchain_worker_address", "worker_mismatch", "deleted_at", "chain_retired_at",
+		"onchain_amount_wei", "onchain_deadline_unix"}},
+	{"accepts", []string{"worker_signature", "terms_amount_wei", "terms_indexer_fee_bps"}},
+	{"worker_tiers", nil},
+	{"employer_sequences", nil},
+	{"audit_events", []string{"acknowledged_at"}},
+	{"task_notifications", nil},
+	{"fee_ledger", nil},
+	{"unknown_logs", nil},
+	{"task_status_events", nil},
+}
+
+// SchemaReady checks that every table and column in requiredSchema exists
+// in the current schema. Missing ones are reported in an error wrapping
+// ErrSchemaNotReady.
+func SchemaReady(ctx context.Context, pool *pgxpool.Pool) error {
+	tables := make([]string, len(requiredSchema))
+	for i, t := range requiredSchema {
+		tables[i] = t.table
+	}
+	rows, err := pool.Query(ctx, `
+SELECT table_name, column_name FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = ANY($1)`, tables)
+	if err != nil {
+		return fmt.Errorf("check schema: %w", err)
+	}
+	defer rows.Close()
+	found := map[string]map[string]bool{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("check schema: %w", err)
+		}
+		if found[table] == nil {
+			found[table] = map[string]bool{}
+		}
+		found[table][column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("check schema: %w", err)
+	}
+
+	var missingTables, missingColumns []string
+	for _, t := range requiredSchema {
+		cols, ok := found[t.table]
+		if !ok {
+			missingTables = append(missingTables, t.table)
+			continue
+		}
+		for _, c := range t.columns {
+			if !cols[c] {
+				missingColumns = append(missingColumns, t.table+"."+c)
+			}
+		}
+	}
+	if len(missingTables) == 0 && len(missingColumns) == 0 {
+		return nil
+	}
+	var parts []string
+	if len(missingTables) > 0 {
+		parts = append(parts, "missing tables "+strings.Join(missingTables, ", "))
+	}
+	if len(missingColumns) > 0 {
+		parts = append(parts, "missing columns "+strings.Join(missingColumns, ", "))
+	}
+	return fmt.Errorf("%w: %s (migrations not applied?)", ErrSchemaNotReady, strings.Join(parts, "; "))
+}
+
+// CheckSchema verifies that the migrations have been applied; see
+// SchemaReady.
+func CheckSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	return SchemaReady(ctx, pool)
+}
+
+// IsSchemaNotReady reports whether err is ErrSchemaNotReady or a Postgres
+// undefined table or column error, as any query returns before the
+// migrations that create them have run.
+func IsSchemaNotReady(err error) bool {
+	if errors.Is(err, ErrSchemaNotReady) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "42P01" || pgErr.Code == "42703")
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/AgentMesh-Net/indexer-go/migrations"
+)
+
+func TestIsSchemaNotReady(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrNotFound, false},
+		{fmt.Errorf("check: %w", ErrSchemaNotReady), true},
+		{fmt.Errorf("update task: %w", &pgconn.PgError{Code: "42P01"}), true},
+		{&pgconn.PgError{Code: "42703"}, true},
+		{&pgconn.PgError{Code: "23505"}, false},
+	} {
+		if got := IsSchemaNotReady(tc.err); got != tc.want {
+			t.Errorf("IsSchemaNotReady(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+// TestSchemaReady migrates a schema of its own, so it can share a database,
+// and then drops one table.
+func TestSchemaReady(t *testing.T) {
+	dsn := os.Getenv("AMN_TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("AMN_TEST_DB_DSN not set; skipping database test")
+	}
+	ctx := context.Background()
+	admin, err := NewPool(ctx, dsn)
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	t.Cleanup(admin.Close)
+	schema := fmt.Sprintf("schema_ready_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE") })
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := SchemaReady(ctx, pool); !errors.Is(err, ErrSchemaNotReady) || !strings.Contains(err.Error(), "tasks") {
+		t.Fatalf("empty schema: err = %v, want ErrSchemaNotReady naming tasks", err)
+	}
+	runner := migrations.NewRunner()
+	runner.Logf = nil
+	if _, err := runner.Apply(ctx, pool); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := SchemaReady(ctx, pool); err != nil {
+		t.Fatalf("migrated schema: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, "DROP TABLE unknown_logs"); err != nil {
+		t.Fatal(err)
+	}
+	err = SchemaReady(ctx, pool)
+	if !errors.Is(err, ErrSchemaNotReady) || !strings.Contains(err.Error(), "missing tables unknown_logs") {
+		t.Errorf("missing table: err = %v", err)
+	}
+	if strings.Contains(err.Error(), "tasks") {
+		t.Errorf("missing table: err = %v names tables that exist", err)
+	}
+
+	if _, err := pool.Exec(ctx, "DELETE FROM unknown_logs"); !IsSchemaNotReady(err) {
+		t.Errorf("query on the dropped table: err = %v, want IsSchemaNotReady", err)
+	}
+}
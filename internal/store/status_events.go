@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TaskStatusEvent is a row of task_status_events, one task status change,
+// with the task fields current when it is read. OldStatus is empty for the
+// event recorded when the task was created.
+type TaskStatusEvent struct {
+	ID              int64
+	TaskID          string
+	TaskHash        string
+	ChainID         int
+	OldStatus       string
+	NewStatus       string
+	AmountWei       string
+	EmployerAddress string
+	WorkerAddress   string
+	Title           string
+	CreatedAt       time.Time
+}
+
+// ListTaskStatusEvents returns status changes of public, non-deleted tasks,
+// ordered by created_at DESC, id DESC, with the same keyset pagination as
+// ListAuditEvents.
+func (r *PostgresTaskRepo) ListTaskStatusEvents(ctx context.Context, limit int, cursor *Cursor) ([]*TaskStatusEvent, *Cursor, error) {
+	q := `
+SELECT e.id, e.task_id, t.task_hash, t.chain_id, COALESCE(e.old_status, ''), e.new_status,
+       t.amount_wei, t.employer_address, COALESCE(t.worker_address, ''), COALESCE(t.title, ''), e.created_at
+FROM task_status_events e
+JOIN tasks t ON t.task_id = e.task_id
+WHERE t.visibility = 'public' AND t.deleted_at IS NULL`
+	args := []any{}
+	if cursor != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, cursor.CreatedAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse cursor time: %w", err)
+		}
+		cursorID, err := strconv.ParseInt(cursor.ObjectID, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse cursor id: %w", err)
+		}
+		args = append(args, cursorTime, cursorID)
+		q += fmt.Sprintf(" AND (e.created_at, e.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	q += fmt.Sprintf(" ORDER BY e.created_at DESC, e.id DESC LIMIT $%d", len(args))
+
+	rows, err := r.reader(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list task status events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*TaskStatusEvent
+	for rows.Next() {
+		e := &TaskStatusEvent{}
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.TaskHash, &e.ChainID, &e.OldStatus, &e.NewStatus,
+			&e.AmountWei, &e.EmployerAddress, &e.WorkerAddress, &e.Title, &e.CreatedAt); err != nil {
+			return nil, nil, fmt.Errorf("scan task status event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows: %w", err)
+	}
+
+	var next *Cursor
+	if len(events) > limit {
+		last := events[limit-1]
+		next = &Cursor{
+			CreatedAt: last.CreatedAt.UTC().Format(time.RFC3339Nano),
+			ObjectID:  strconv.FormatInt(last.ID, 10),
+		}
+		events = events[:limit]
+	}
+	return events, next, nil
+}
@@ -0,0 +1,758 @@
+// Package storetest is a conformance suite for store.Repo and
+// store.TaskRepo implementations: conflict and not-found mapping, pagination
+// order and tie-breaks, insert guards and the transactional accept. Every
+// backend runs the same suite from its own tests; the Postgres backend does
+// so in internal/store when AMN_TEST_DB_DSN is set.
+//
+// The suite does not need an empty store. Each run uses fresh IDs, its own
+// chain ID, employer and signer key, so it can share a database with other
+// tests.
+package storetest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+// RepoFactory returns the object repository under test.
+type RepoFactory func(t *testing.T) store.Repo
+
+// TaskRepoFactory returns the task repository under test.
+type TaskRepoFactory func(t *testing.T) store.TaskRepo
+
+// run namespaces the data of one suite run.
+type run struct {
+	id      string
+	chainID int
+	address func(n int) string
+}
+
+func newRun() run {
+	now := time.Now().UnixNano()
+	return run{
+		id:      strconv.FormatInt(now, 36),
+		chainID: 100_000_000 + int(now%1_000_000_000),
+		address: func(n int) string { return fmt.Sprintf("0x%024x%016x", n, now) },
+	}
+}
+
+func (r run) name(s string) string { return "conf-" + r.id + "-" + s }
+
+// RunRepo runs the object repository conformance tests.
+func RunRepo(t *testing.T, newRepo RepoFactory) {
+	ctx := context.Background()
+	r := newRun()
+	// Signer keys are base64 32-byte values unique to the run: the store
+	// decodes them.
+	signerKey := func(name string) string {
+		sum := sha256.Sum256([]byte(r.name(name)))
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}
+	signer := signerKey("signer")
+	object := func(id, createdAt, taskID string) *envelope.Envelope {
+		return &envelope.Envelope{
+			ObjectType: "bid", ObjectVersion: "0.1", ObjectID: r.name(id), CreatedAt: createdAt,
+			Payload: json.RawMessage(`{"task_id":"` + taskID + `"}`),
+			Signer:  envelope.Signer{Algo: "ed25519", PubKey: signer}, Signature: "sig",
+		}
+	}
+
+	t.Run("InsertAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		env := object("get", "2026-01-01T00:00:00Z", "")
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatalf("InsertObject: %v", err)
+		}
+		if err := repo.InsertObject(ctx, env); !errors.Is(err, store.ErrConflict) {
+			t.Errorf("duplicate InsertObject: err = %v, want ErrConflict", err)
+		}
+		got, err := repo.GetObjectByID(ctx, env.ObjectID)
+		if err != nil || got.ObjectID != env.ObjectID || got.FirstSeenAt.IsZero() {
+			t.Errorf("GetObjectByID = %+v, %v", got, err)
+		}
+		if _, err := repo.GetObjectByID(ctx, r.name("missing")); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetObjectByID(unknown): err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("InsertObjectsPerItemErrors", func(t *testing.T) {
+		repo := newRepo(t)
+		batch, ok := repo.(store.BatchInserter)
+		if !ok {
+			t.Skip("repo does not implement store.BatchInserter")
+		}
+		existing := object("batch-existing", "2026-01-01T00:00:00Z", "")
+		if err := repo.InsertObject(ctx, existing); err != nil {
+			t.Fatal(err)
+		}
+		fresh := object("batch-fresh", "2026-01-01T00:00:00Z", "")
+		errs := batch.InsertObjects(ctx, []*envelope.Envelope{fresh, fresh, existing})
+		if len(errs) != 3 || errs[0] != nil || !errors.Is(errs[1], store.ErrConflict) || !errors.Is(errs[2], store.ErrConflict) {
+			t.Errorf("InsertObjects errs = %v, want [nil ErrConflict ErrConflict]", errs)
+		}
+	})
+
+	t.Run("QueryObjectsPagination", func(t *testing.T) {
+		repo := newRepo(t)
+		// Three objects share a created_at: object_id breaks the tie.
+		var want []string
+		for i, at := range []string{"2026-02-01T00:00:02Z", "2026-02-01T00:00:01Z", "2026-02-01T00:00:01Z", "2026-02-01T00:00:01Z", "2026-02-01T00:00:00Z"} {
+			env := object("page-"+strconv.Itoa(i), at, "")
+			if err := repo.InsertObject(ctx, env); err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, env.ObjectID)
+		}
+		slices.Reverse(want[1:4]) // newest first, then object_id descending
+
+		var got []string
+		var cursor *store.Cursor
+		for page := 0; ; page++ {
+			if page > len(want) {
+				t.Fatal("pagination does not terminate")
+			}
+			items, next, err := repo.QueryObjects(ctx, store.ObjectFilter{ObjectType: "bid", SignerPubKey: signer, Since: ptr(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)), Until: ptr(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)), Limit: 2, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("QueryObjects: %v", err)
+			}
+			for _, it := range items {
+				got = append(got, it.ObjectID)
+			}
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("pages = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ListObjectsBySigner", func(t *testing.T) {
+		repo := newRepo(t)
+		own, other := signerKey("by-signer"), signerKey("by-signer-other")
+		var want, wantBids []string
+		for i, o := range []struct{ typ, key, at string }{
+			{"bid", own, "2026-06-01T00:00:03Z"},
+			{"bid", other, "2026-06-01T00:00:02Z"},
+			{"artifact", own, "2026-06-01T00:00:01Z"},
+			{"bid", own, "2026-06-01T00:00:01Z"},
+			{"bid", own, "2026-06-01T00:00:00Z"},
+		} {
+			env := object("by-signer-"+strconv.Itoa(i), o.at, "")
+			env.ObjectType, env.Signer.PubKey = o.typ, o.key
+			if err := repo.InsertObject(ctx, env); err != nil {
+				t.Fatal(err)
+			}
+			if o.key == own {
+				want = append(want, env.ObjectID)
+				if o.typ == "bid" {
+					wantBids = append(wantBids, env.ObjectID)
+				}
+			}
+		}
+		// The two objects at :01 tie on created_at: object_id descending.
+		want[1], want[2] = want[2], want[1]
+
+		pages := func(objectType string) []string {
+			var got []string
+			var cursor *store.Cursor
+			for page := 0; ; page++ {
+				if page > len(want) {
+					t.Fatal("pagination does not terminate")
+				}
+				items, next, err := repo.ListObjectsBySigner(ctx, own, objectType, 2, cursor)
+				if err != nil {
+					t.Fatalf("ListObjectsBySigner: %v", err)
+				}
+				for _, it := range items {
+					got = append(got, it.ObjectID)
+				}
+				if next == nil {
+					return got
+				}
+				cursor = next
+			}
+		}
+		if got := pages(""); !slices.Equal(got, want) {
+			t.Errorf("pages = %v, want %v", got, want)
+		}
+		if got := pages("bid"); !slices.Equal(got, wantBids) {
+			t.Errorf("bid pages = %v, want %v", got, wantBids)
+		}
+	})
+
+	t.Run("QueryObjectsReceivedBefore", func(t *testing.T) {
+		repo := newRepo(t)
+		env := object("received", "2020-01-01T00:00:00Z", "")
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatal(err)
+		}
+		ids := func(bound time.Time) []string {
+			items, _, err := repo.QueryObjects(ctx, store.ObjectFilter{SignerPubKey: signer, Order: store.OrderReceived, ReceivedBefore: bound, Limit: 100})
+			if err != nil {
+				t.Fatalf("QueryObjects: %v", err)
+			}
+			var out []string
+			for _, it := range items {
+				out = append(out, it.ObjectID)
+			}
+			return out
+		}
+		if got := ids(time.Now().Add(-time.Hour)); slices.Contains(got, env.ObjectID) {
+			t.Errorf("bound before the insert: %v includes %s", got, env.ObjectID)
+		}
+		if got := ids(time.Now().Add(time.Hour)); !slices.Contains(got, env.ObjectID) {
+			t.Errorf("bound after the insert: %v, want %s", got, env.ObjectID)
+		}
+	})
+
+	t.Run("ListObjectsForTask", func(t *testing.T) {
+		repo := newRepo(t)
+		taskID := r.name("task")
+		later := object("hist-later", "2026-03-01T00:00:01Z", taskID)
+		earlier := object("hist-earlier", "2026-03-01T00:00:00Z", taskID)
+		linked := object("hist-linked", "2026-03-01T00:00:02Z", "")
+		for _, env := range []*envelope.Envelope{later, earlier, linked, object("hist-other", "2026-03-01T00:00:00Z", r.name("other"))} {
+			if err := repo.InsertObject(ctx, env); err != nil {
+				t.Fatal(err)
+			}
+		}
+		list := func(limit int) []string {
+			envs, err := repo.ListObjectsForTask(ctx, taskID, linked.ObjectID, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range envs {
+				got = append(got, e.ObjectID)
+			}
+			return got
+		}
+		if got, want := list(10), []string{earlier.ObjectID, later.ObjectID, linked.ObjectID}; !slices.Equal(got, want) {
+			t.Errorf("ListObjectsForTask = %v, want %v", got, want)
+		}
+		if got, want := list(2), []string{earlier.ObjectID, later.ObjectID}; !slices.Equal(got, want) {
+			t.Errorf("ListObjectsForTask(limit 2) = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("DeleteObject", func(t *testing.T) {
+		repo := newRepo(t)
+		tomb, gone := object("del-tomb", "2026-04-01T00:00:00Z", ""), object("del-gone", "2026-04-01T00:00:00Z", "")
+		for _, env := range []*envelope.Envelope{tomb, gone} {
+			if err := repo.InsertObject(ctx, env); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repo.DeleteObject(ctx, tomb.ObjectID, true); err != nil {
+			t.Fatalf("DeleteObject(tombstone): %v", err)
+		}
+		var ge *store.GoneError
+		if _, err := repo.GetObjectByID(ctx, tomb.ObjectID); !errors.As(err, &ge) || !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetObjectByID(tombstone): err = %v, want *GoneError matching ErrNotFound", err)
+		}
+		if err := repo.DeleteObject(ctx, tomb.ObjectID, true); !errors.As(err, &ge) {
+			t.Errorf("DeleteObject(tombstone again): err = %v, want *GoneError", err)
+		}
+		if err := repo.InsertObject(ctx, tomb); !errors.Is(err, store.ErrConflict) {
+			t.Errorf("InsertObject(tombstoned id): err = %v, want ErrConflict", err)
+		}
+		if err := repo.DeleteObject(ctx, gone.ObjectID, false); err != nil {
+			t.Fatalf("DeleteObject: %v", err)
+		}
+		if _, err := repo.GetObjectByID(ctx, gone.ObjectID); !errors.Is(err, store.ErrNotFound) || errors.As(err, &ge) {
+			t.Errorf("GetObjectByID(removed): err = %v, want plain ErrNotFound", err)
+		}
+		if err := repo.DeleteObject(ctx, r.name("del-unknown"), true); !errors.Is(err, store.ErrNotFound) || errors.As(err, &ge) {
+			t.Errorf("DeleteObject(unknown): err = %v, want plain ErrNotFound", err)
+		}
+		items, _, err := repo.QueryObjects(ctx, store.ObjectFilter{SignerPubKey: signer, Since: ptr(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)), Until: ptr(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)), Limit: 10})
+		if err != nil || len(items) != 0 {
+			t.Errorf("QueryObjects after deletes = %d items, %v; want none", len(items), err)
+		}
+
+		admin := store.WithDeleted(ctx)
+		if got, err := repo.GetObjectByID(admin, tomb.ObjectID); err != nil || got.DeletedAt == nil {
+			t.Errorf("GetObjectByID(tombstone, WithDeleted) = %+v, %v; want DeletedAt set", got, err)
+		}
+		items, _, err = repo.QueryObjects(admin, store.ObjectFilter{SignerPubKey: signer, Since: ptr(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)), Until: ptr(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)), Limit: 10})
+		if err != nil || len(items) != 1 || items[0].ObjectID != tomb.ObjectID || items[0].DeletedAt == nil {
+			t.Errorf("QueryObjects(WithDeleted) = %+v, %v; want only the tombstone", items, err)
+		}
+	})
+
+	t.Run("ObjectNotes", func(t *testing.T) {
+		repo := newRepo(t)
+		env := object("notes", "2026-05-01T00:00:00Z", "")
+		if err := repo.InsertObject(ctx, env); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := repo.SetObjectNotes(ctx, env.ObjectID, "checked"); err != nil || n.Notes != "checked" || n.UpdatedAt == nil {
+			t.Errorf("SetObjectNotes = %+v, %v", n, err)
+		}
+		if n, err := repo.GetObjectNotes(ctx, env.ObjectID); err != nil || n.Notes != "checked" {
+			t.Errorf("GetObjectNotes = %+v, %v", n, err)
+		}
+		if _, err := repo.SetObjectNotes(ctx, r.name("notes-unknown"), "x"); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("SetObjectNotes(unknown): err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetObjectNotes(ctx, r.name("notes-unknown")); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetObjectNotes(unknown): err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// RunTaskRepo runs the task repository conformance tests.
+func RunTaskRepo(t *testing.T, newRepo TaskRepoFactory) {
+	ctx := context.Background()
+	r := newRun()
+	escrow, employer, worker := r.address(1), r.address(2), r.address(3)
+	task := func(id string) *store.Task {
+		return &store.Task{
+			TaskID: r.name(id), TaskHash: "0x" + r.name(id), ChainID: r.chainID, EscrowAddress: escrow,
+			EmployerAddress: employer, AmountWei: "1000", DeadlineUnix: 1, Title: "conformance", Status: store.TaskStatusCreated,
+		}
+	}
+	insert := func(t *testing.T, repo store.TaskRepo, ts ...*store.Task) {
+		t.Helper()
+		for _, tk := range ts {
+			if err := repo.InsertTask(ctx, tk, 0); err != nil {
+				t.Fatalf("InsertTask %s: %v", tk.TaskID, err)
+			}
+		}
+	}
+
+	t.Run("InsertAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		tk := task("get")
+		insert(t, repo, tk)
+		if err := repo.InsertTask(ctx, task("get"), 0); !errors.Is(err, store.ErrConflict) {
+			t.Errorf("duplicate InsertTask: err = %v, want ErrConflict", err)
+		}
+		got, err := repo.GetTask(ctx, tk.TaskID)
+		if err != nil || got.TaskHash != tk.TaskHash || got.Status != store.TaskStatusCreated || got.CreatedAt.IsZero() {
+			t.Errorf("GetTask = %+v, %v", got, err)
+		}
+		if got, err := repo.GetTaskByHash(ctx, r.chainID, tk.TaskHash); err != nil || got.TaskID != tk.TaskID {
+			t.Errorf("GetTaskByHash = %+v, %v", got, err)
+		}
+		if _, err := repo.GetTaskByHash(ctx, r.chainID+1, tk.TaskHash); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetTaskByHash(other chain): err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetTask(ctx, r.name("missing")); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetTask(unknown): err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("EmployerSequence", func(t *testing.T) {
+		repo := newRepo(t)
+		seqEmployer := r.address(4)
+		withSeq := func(id string, seq int64) *store.Task {
+			tk := task(id)
+			tk.EmployerAddress, tk.EmployerSequence = seqEmployer, &seq
+			return tk
+		}
+		insert(t, repo, withSeq("seq-5", 5))
+		for _, seq := range []int64{5, 4} {
+			if err := repo.InsertTask(ctx, withSeq("seq-"+strconv.FormatInt(seq, 10)+"-again", seq), 0); !errors.Is(err, store.ErrSequenceConflict) {
+				t.Errorf("sequence %d after 5: err = %v, want ErrSequenceConflict", seq, err)
+			}
+		}
+		insert(t, repo, withSeq("seq-6", 6))
+		if next, err := repo.NextEmployerSequence(ctx, seqEmployer); err != nil || next != 7 {
+			t.Errorf("NextEmployerSequence = %d, %v; want 7", next, err)
+		}
+	})
+
+	t.Run("OpenTaskLimit", func(t *testing.T) {
+		repo := newRepo(t)
+		tk := task("limit-1")
+		tk.EmployerAddress = r.address(5)
+		if err := repo.InsertTask(ctx, tk, 1); err != nil {
+			t.Fatal(err)
+		}
+		over := task("limit-2")
+		over.EmployerAddress = tk.EmployerAddress
+		var le *store.OpenTaskLimitError
+		if err := repo.InsertTask(ctx, over, 1); !errors.As(err, &le) || le.Limit != 1 || le.Open != 1 {
+			t.Errorf("InsertTask over the limit: err = %v, want *OpenTaskLimitError{1, 1}", err)
+		}
+		if _, err := repo.GetTask(ctx, over.TaskID); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("task over the limit was stored: err = %v", err)
+		}
+	})
+
+	t.Run("ListTasksOrder", func(t *testing.T) {
+		repo := newRepo(t)
+		// One batch, so a backend stamping created_at per transaction gives
+		// every task the same time and only task_id orders them.
+		batch := []*store.Task{task("list-a"), task("list-c"), task("list-b")}
+		private := task("list-private")
+		private.Visibility, private.AllowedWorkers = store.TaskVisibilityPrivate, []string{worker}
+		batch = append(batch, private)
+		errs, err := repo.InsertTasks(ctx, batch, 0, true)
+		if err != nil || slices.ContainsFunc(errs, func(e error) bool { return e != nil }) {
+			t.Fatalf("InsertTasks: %v %v", errs, err)
+		}
+
+		var got []*store.Task
+		for offset := 0; offset < 6; offset += 2 {
+			page, err := repo.ListTasks(ctx, store.TaskFilter{ChainID: r.chainID, Limit: 2, Offset: offset})
+			if err != nil {
+				t.Fatalf("ListTasks: %v", err)
+			}
+			got = append(got, page...)
+		}
+		var ids []string
+		for _, tk := range got {
+			if tk.TaskID == private.TaskID {
+				t.Error("ListTasks returned a private task")
+			}
+			ids = append(ids, tk.TaskID)
+		}
+		for _, id := range []string{"list-a", "list-b", "list-c"} {
+			if !slices.Contains(ids, r.name(id)) {
+				t.Errorf("paged ListTasks = %v, missing %s", ids, r.name(id))
+			}
+		}
+		for i := 1; i < len(got); i++ {
+			a, b := got[i-1], got[i]
+			if a.CreatedAt.Before(b.CreatedAt) || (a.CreatedAt.Equal(b.CreatedAt) && a.TaskID <= b.TaskID) {
+				t.Errorf("ListTasks order: %s (%s) before %s (%s), want created_at then task_id descending",
+					a.TaskID, a.CreatedAt, b.TaskID, b.CreatedAt)
+			}
+		}
+	})
+
+	t.Run("ListTasksUpdatedSince", func(t *testing.T) {
+		repo := newRepo(t)
+		since := time.Now().Add(-time.Minute)
+		chainID := r.chainID + 2
+		for _, id := range []string{"delta-a", "delta-b", "delta-c"} {
+			tk := task(id)
+			tk.ChainID = chainID
+			insert(t, repo, tk)
+		}
+		var got []*store.Task
+		var cursor *store.Cursor
+		for page := 0; ; page++ {
+			if page > 3 {
+				t.Fatal("pagination does not terminate")
+			}
+			items, next, err := repo.ListTasksUpdatedSince(ctx, since, time.Time{}, chainID, "", 2, cursor)
+			if err != nil {
+				t.Fatalf("ListTasksUpdatedSince: %v", err)
+			}
+			got = append(got, items...)
+			if next == nil {
+				break
+			}
+			if next.Order != store.OrderUpdated {
+				t.Errorf("cursor order = %q, want %q", next.Order, store.OrderUpdated)
+			}
+			cursor = next
+		}
+		if len(got) != 3 {
+			t.Fatalf("ListTasksUpdatedSince returned %d tasks, want 3", len(got))
+		}
+		if items, _, err := repo.ListTasksUpdatedSince(ctx, since, since.Add(time.Second), chainID, "", 10, nil); err != nil || len(items) != 0 {
+			t.Errorf("ListTasksUpdatedSince(until before the inserts) = %d tasks, %v; want none", len(items), err)
+		}
+		if err := repo.DeleteTask(ctx, got[0].TaskID, true); err != nil {
+			t.Fatal(err)
+		}
+		items, _, err := repo.ListTasksUpdatedSince(ctx, got[2].UpdatedAt, time.Time{}, chainID, "", 10, nil)
+		if err != nil || len(items) != 1 || items[0].TaskID != got[0].TaskID || items[0].DeletedAt == nil {
+			t.Errorf("ListTasksUpdatedSince after soft delete = %+v, %v; want %s with DeletedAt", items, err, got[0].TaskID)
+		}
+		for i := 1; i < len(got); i++ {
+			a, b := got[i-1], got[i]
+			if a.UpdatedAt.After(b.UpdatedAt) || (a.UpdatedAt.Equal(b.UpdatedAt) && a.TaskID >= b.TaskID) {
+				t.Errorf("order: %s before %s, want updated_at then task_id ascending", a.TaskID, b.TaskID)
+			}
+		}
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		repo := newRepo(t)
+		tk := task("accept")
+		insert(t, repo, tk)
+		stored, err := repo.GetTask(ctx, tk.TaskID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.InsertAccept(ctx, &store.Accept{AcceptID: r.name("acc-orphan"), TaskID: r.name("missing"), WorkerAddress: worker}); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("InsertAccept(unknown task): err = %v, want ErrNotFound", err)
+		}
+		stale := stored.UpdatedAt.Add(-time.Second)
+		if err := repo.InsertAccept(ctx, &store.Accept{AcceptID: r.name("acc-stale"), TaskID: tk.TaskID, WorkerAddress: worker, IfUpdatedAt: &stale}); !errors.Is(err, store.ErrTaskChanged) {
+			t.Errorf("InsertAccept(stale IfUpdatedAt): err = %v, want ErrTaskChanged", err)
+		}
+		if _, err := repo.GetAccept(ctx, tk.TaskID, r.name("acc-stale")); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("rejected accept was stored: err = %v", err)
+		}
+
+		first := &store.Accept{AcceptID: r.name("acc-2"), TaskID: tk.TaskID, WorkerAddress: worker, IfUpdatedAt: &stored.UpdatedAt}
+		if err := repo.InsertAccept(ctx, first); err != nil {
+			t.Fatalf("InsertAccept: %v", err)
+		}
+		if first.Terms == nil || first.Terms.AmountWei != "1000" || first.Terms.TitleHash == "" {
+			t.Errorf("accept terms = %+v, want a snapshot of the task", first.Terms)
+		}
+		if err := repo.InsertAccept(ctx, &store.Accept{AcceptID: first.AcceptID, TaskID: tk.TaskID, WorkerAddress: worker}); !errors.Is(err, store.ErrConflict) {
+			t.Errorf("duplicate InsertAccept: err = %v, want ErrConflict", err)
+		}
+		second := &store.Accept{AcceptID: r.name("acc-1"), TaskID: tk.TaskID, WorkerAddress: r.address(6)}
+		if err := repo.InsertAccept(ctx, second); err != nil {
+			t.Fatal(err)
+		}
+
+		accepts, err := repo.ListAccepts(ctx, tk.TaskID)
+		if err != nil || len(accepts) != 2 {
+			t.Fatalf("ListAccepts = %d, %v; want 2", len(accepts), err)
+		}
+		if a, b := accepts[0], accepts[1]; a.CreatedAt.After(b.CreatedAt) || (a.CreatedAt.Equal(b.CreatedAt) && a.AcceptID > b.AcceptID) {
+			t.Errorf("ListAccepts order = [%s %s], want created_at then accept_id ascending", a.AcceptID, b.AcceptID)
+		}
+
+		// accept_id is unique per task, not globally.
+		other := task("accept-other")
+		insert(t, repo, other)
+		if err := repo.InsertAccept(ctx, &store.Accept{AcceptID: first.AcceptID, TaskID: other.TaskID, WorkerAddress: worker}); err != nil {
+			t.Fatalf("InsertAccept(same accept_id, other task): %v", err)
+		}
+		if got, err := repo.GetAccept(ctx, tk.TaskID, first.AcceptID); err != nil || got.TaskID != tk.TaskID {
+			t.Errorf("GetAccept(task, acc-2) = %+v, %v", got, err)
+		}
+		if got, err := repo.GetAccept(ctx, other.TaskID, first.AcceptID); err != nil || got.TaskID != other.TaskID {
+			t.Errorf("GetAccept(other, acc-2) = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("UpdateTaskWorker", func(t *testing.T) {
+		repo := newRepo(t)
+		tk := task("worker")
+		insert(t, repo, tk)
+		if err := repo.UpdateTaskWorker(ctx, tk.TaskID, worker, store.TaskStatusAccepted); err != nil {
+			t.Fatalf("UpdateTaskWorker: %v", err)
+		}
+		if got, err := repo.GetTask(ctx, tk.TaskID); err != nil || got.WorkerAddress != worker || got.Status != store.TaskStatusAccepted {
+			t.Errorf("after UpdateTaskWorker: %+v, %v", got, err)
+		}
+		if err := repo.UpdateTaskWorker(ctx, r.name("missing"), worker, store.TaskStatusAccepted); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("UpdateTaskWorker(unknown): err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("DeleteTask", func(t *testing.T) {
+		repo := newRepo(t)
+		soft, hard := task("del-soft"), task("del-hard")
+		insert(t, repo, soft, hard)
+		if err := repo.DeleteTask(ctx, soft.TaskID, true); err != nil {
+			t.Fatalf("DeleteTask(soft): %v", err)
+		}
+		if _, err := repo.GetTask(ctx, soft.TaskID); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetTask(soft-deleted): err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetTaskByHash(ctx, r.chainID, soft.TaskHash); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetTaskByHash(soft-deleted): err = %v, want ErrNotFound", err)
+		}
+		if got, err := repo.GetTaskByHash(store.WithDeleted(ctx), r.chainID, soft.TaskHash); err != nil || got.TaskID != soft.TaskID {
+			t.Errorf("GetTaskByHash(soft-deleted, WithDeleted) = %+v, %v", got, err)
+		}
+		if err := repo.UpdateTaskWorker(ctx, soft.TaskID, worker, store.TaskStatusAccepted); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("UpdateTaskWorker(soft-deleted): err = %v, want ErrNotFound", err)
+		}
+		if err := repo.DeleteTask(ctx, soft.TaskID, true); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("DeleteTask(soft again): err = %v, want ErrNotFound", err)
+		}
+		admin := store.WithDeleted(ctx)
+		if got, err := repo.GetTask(admin, soft.TaskID); err != nil || got.DeletedAt == nil {
+			t.Errorf("GetTask(WithDeleted) = %+v, %v; want DeletedAt set", got, err)
+		}
+		listed := func(c context.Context) []string {
+			tasks, err := repo.ListTasks(c, store.TaskFilter{ChainID: r.chainID, Limit: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, tk := range tasks {
+				ids = append(ids, tk.TaskID)
+			}
+			return ids
+		}
+		if ids := listed(ctx); slices.Contains(ids, soft.TaskID) {
+			t.Errorf("ListTasks = %v, includes the soft-deleted task", ids)
+		}
+		if ids := listed(admin); !slices.Contains(ids, soft.TaskID) {
+			t.Errorf("ListTasks(WithDeleted) = %v, want %s", ids, soft.TaskID)
+		}
+
+		if err := repo.DeleteTask(ctx, hard.TaskID, false); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		if _, err := repo.GetTask(admin, hard.TaskID); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetTask(hard-deleted, WithDeleted): err = %v, want ErrNotFound", err)
+		}
+		if err := repo.DeleteTask(ctx, r.name("missing"), false); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("DeleteTask(unknown): err = %v, want ErrNotFound", err)
+		}
+
+		funded := task("del-funded")
+		insert(t, repo, funded)
+		if err := repo.UpdateOnchainCreated(ctx, funded.TaskID, "0x05", nil, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		for _, soft := range []bool{true, false} {
+			if err := repo.DeleteTask(ctx, funded.TaskID, soft); !errors.Is(err, store.ErrTaskOnchain) {
+				t.Errorf("DeleteTask(funded, soft=%v): err = %v, want ErrTaskOnchain", soft, err)
+			}
+		}
+		if _, err := repo.GetTask(ctx, funded.TaskID); err != nil {
+			t.Errorf("GetTask(funded) after refused delete: %v", err)
+		}
+	})
+
+	t.Run("UnfundedAccepts", func(t *testing.T) {
+		repo := newRepo(t)
+		ghost := r.address(8)
+		unfunded, funded := task("unfunded"), task("funded")
+		unfunded.EmployerAddress, funded.EmployerAddress = ghost, ghost
+		insert(t, repo, unfunded, funded)
+		for _, tk := range []*store.Task{unfunded, funded} {
+			if err := repo.UpdateTaskWorker(ctx, tk.TaskID, worker, store.TaskStatusAccepted); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repo.UpdateOnchainCreated(ctx, funded.TaskID, "0x04", nil, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.GetTask(ctx, unfunded.TaskID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if items, err := repo.ListUnfundedAccepts(ctx, got.UpdatedAt, 1000); err != nil || slices.ContainsFunc(items, func(u store.UnfundedAccept) bool { return u.TaskID == unfunded.TaskID }) {
+			t.Errorf("ListUnfundedAccepts(before the accept) = %+v, %v; want the task left out", items, err)
+		}
+		cutoff := got.UpdatedAt.Add(time.Millisecond)
+		items, err := repo.ListUnfundedAccepts(ctx, cutoff, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ours []store.UnfundedAccept
+		for _, u := range items {
+			if u.EmployerAddress == ghost {
+				ours = append(ours, u)
+			}
+		}
+		if len(ours) != 1 || ours[0].TaskID != unfunded.TaskID || ours[0].WorkerAddress != worker || !ours[0].AcceptedAt.Equal(got.UpdatedAt) {
+			t.Errorf("ListUnfundedAccepts = %+v, want only %s", ours, unfunded.TaskID)
+		}
+		if counts, err := repo.CountUnfundedAcceptsByEmployer(ctx, cutoff); err != nil || counts[ghost] != 1 {
+			t.Errorf("CountUnfundedAcceptsByEmployer[%s] = %d, %v; want 1", ghost, counts[ghost], err)
+		}
+
+		reverted, err := repo.RevertUnfundedAccepts(ctx, cutoff)
+		if err != nil || !slices.ContainsFunc(reverted, func(u store.UnfundedAccept) bool { return u.TaskID == unfunded.TaskID && u.WorkerAddress == worker }) {
+			t.Errorf("RevertUnfundedAccepts = %+v, %v; want %s with its worker", reverted, err, unfunded.TaskID)
+		}
+		if got, err := repo.GetTask(ctx, unfunded.TaskID); err != nil || got.Status != store.TaskStatusCreated || got.WorkerAddress != "" {
+			t.Errorf("after revert: %+v, %v; want created with no worker", got, err)
+		}
+		if got, err := repo.GetTask(ctx, funded.TaskID); err != nil || got.Status != store.TaskStatusAccepted {
+			t.Errorf("funded task after revert: %+v, %v; want it untouched", got, err)
+		}
+	})
+
+	t.Run("ChainRetired", func(t *testing.T) {
+		repo := newRepo(t)
+		open := task("retired-open")
+		insert(t, repo, open)
+		chains, err := repo.ListOpenTaskChains(ctx)
+		if err != nil || !slices.Contains(chains, r.chainID) {
+			t.Fatalf("ListOpenTaskChains = %v, %v; want %d included", chains, err, r.chainID)
+		}
+
+		// The chain is dropped from the configuration with the task open.
+		if n, err := repo.SetChainRetired(ctx, r.chainID, true); err != nil || n != 1 {
+			t.Fatalf("SetChainRetired = %d, %v; want 1", n, err)
+		}
+		got, err := repo.GetTask(ctx, open.TaskID)
+		if err != nil || got.ChainRetiredAt == nil {
+			t.Fatalf("GetTask = %+v, %v; want chain_retired_at set", got, err)
+		}
+		if n, err := repo.SetChainRetired(ctx, r.chainID, true); err != nil || n != 0 {
+			t.Errorf("second SetChainRetired = %d, %v; want the flag kept", n, err)
+		}
+		list, err := repo.ListTasks(ctx, store.TaskFilter{ChainID: r.chainID, Limit: 10})
+		if err != nil || len(list) != 1 || list[0].ChainRetiredAt == nil || !list[0].ChainRetiredAt.Equal(*got.ChainRetiredAt) {
+			t.Errorf("ListTasks = %+v, %v; want the task with its first flag time", list, err)
+		}
+
+		// Configured again.
+		if n, err := repo.SetChainRetired(ctx, r.chainID, false); err != nil || n != 1 {
+			t.Errorf("clear SetChainRetired = %d, %v; want 1", n, err)
+		}
+		if got, err := repo.GetTask(ctx, open.TaskID); err != nil || got.ChainRetiredAt != nil {
+			t.Errorf("after clear: %+v, %v; want no flag", got, err)
+		}
+	})
+
+	t.Run("OnchainUpdates", func(t *testing.T) {
+		repo := newRepo(t)
+		tk := task("onchain")
+		insert(t, repo, tk)
+		if err := repo.UpdateOnchainCreated(ctx, r.name("missing"), "0x01", nil, time.Now()); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("UpdateOnchainCreated(unknown): err = %v, want ErrNotFound", err)
+		}
+		// Hash-keyed updates for a hash registered on another chain are no-ops.
+		if err := repo.UpdateOnchainReleased(ctx, r.chainID+1, tk.TaskHash, "0x02", time.Now()); err != nil {
+			t.Errorf("UpdateOnchainReleased(other chain): %v", err)
+		}
+		if got, _ := repo.GetTask(ctx, tk.TaskID); got == nil || got.Status != store.TaskStatusCreated {
+			t.Errorf("other chain's release changed the task: %+v", got)
+		}
+		// The escrow holds less, for longer, than the task declared.
+		terms := &store.OnchainTerms{AmountWei: "999", DeadlineUnix: tk.DeadlineUnix + 60}
+		if err := repo.UpdateOnchainCreated(ctx, tk.TaskID, "0x03", terms, time.Now()); err != nil {
+			t.Fatalf("UpdateOnchainCreated: %v", err)
+		}
+		if err := repo.UpdateOnchainReleased(ctx, r.chainID, tk.TaskHash, "0x04", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := repo.GetTask(ctx, tk.TaskID); got == nil || got.OnchainCreatedAt == nil || got.Status != store.TaskStatusReleased {
+			t.Errorf("after created and released: %+v", got)
+		} else if got.AmountWei != tk.AmountWei || got.OnchainAmountWei != "999" || got.OnchainDeadlineUnix == nil || *got.OnchainDeadlineUnix != terms.DeadlineUnix {
+			t.Errorf("terms: declared %s, onchain %s / %v; want both kept", got.AmountWei, got.OnchainAmountWei, got.OnchainDeadlineUnix)
+		}
+	})
+
+	t.Run("NotFoundMapping", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, err := repo.GetAccept(ctx, r.name("missing"), r.name("missing")); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetAccept(unknown): err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetWorkerTier(ctx, r.address(7)); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetWorkerTier(unknown): err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.AckAuditEvent(ctx, math.MaxInt64, true, "conformance"); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("AckAuditEvent(unknown): err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
 )
 
 // TaskStatus enumerates task lifecycle states.
@@ -21,6 +24,33 @@ const (
 	TaskStatusCancelled      = "cancelled"
 )
 
+// OpenTaskStatuses are the statuses that count against an employer's open
+// task limit. Released, refunded and cancelled tasks free their slot.
+var OpenTaskStatuses = []string{TaskStatusCreated, TaskStatusAccepted, TaskStatusAcceptedOnchain}
+
+// Task visibility. Private tasks are left out of ListTasks and only
+// disclosed in full to the employer and the task's allowed workers.
+const (
+	TaskVisibilityPublic  = "public"
+	TaskVisibilityPrivate = "private"
+)
+
+// Onchain sync states for TaskFilter.Onchain.
+const (
+	OnchainSynced   = "synced"   // onchain_created_at is set
+	OnchainUnsynced = "unsynced" // never seen onchain
+)
+
+// TaskFilter selects tasks for ListTasks. Zero fields do not filter.
+type TaskFilter struct {
+	ChainID       int
+	Status        string
+	Onchain       string     // OnchainSynced or OnchainUnsynced
+	CreatedBefore *time.Time // exclusive, on created_at
+	Limit         int
+	Offset        int
+}
+
 // Task represents a structured task row.
 type Task struct {
 	TaskID             string
@@ -39,6 +69,42 @@ type Task struct {
 	ReleasedAt         *time.Time
 	RefundedAt         *time.Time
 	OnchainTxHash      string
+	// OnchainAmountWei and OnchainDeadlineUnix are the amount and deadline
+	// of the task's Created event, empty and nil until it is seen. They
+	// may differ from AmountWei and DeadlineUnix, which the employer declared.
+	OnchainAmountWei    string
+	OnchainDeadlineUnix *int64
+	// EmployerSequence is the optional employer-supplied ordering number.
+	EmployerSequence   *int64
+	// EnvelopeObjectID optionally links the task to the envelope object it
+	// was negotiated from.
+	EnvelopeObjectID   string
+	// Visibility is TaskVisibilityPublic or TaskVisibilityPrivate.
+	Visibility         string
+	// AllowedWorkers are the lowercase worker addresses invited to a private
+	// task. Empty for public tasks.
+	AllowedWorkers     []string
+	// TokenAddress is the ERC-20 token the escrow is denominated in, or ""
+	// for the chain's native currency. AmountWei is in the token's base units.
+	TokenAddress       string
+	// RawRequest is the canonical JSON creation request, stored by
+	// InsertTask. Reads do not load it; see GetTaskRawRequest.
+	RawRequest         []byte
+	// RawRequestSHA256 is the hex SHA-256 of the stored raw request,
+	// populated by GetTask. Empty for tasks without one.
+	RawRequestSHA256   string
+	// OnchainWorkerAddress is the worker from the task's last WorkerSet
+	// event. It differs from WorkerAddress only when WorkerMismatch is set.
+	OnchainWorkerAddress string
+	// WorkerMismatch is set when WorkerSet named a different worker than
+	// the one who accepted off-chain; WorkerAddress keeps the accepter.
+	WorkerMismatch     bool
+	// DeletedAt is set for a soft-deleted task, which reads return only
+	// under WithDeleted.
+	DeletedAt          *time.Time
+	// ChainRetiredAt is set on an open task whose chain was dropped from
+	// the configuration; see SetChainRetired.
+	ChainRetiredAt     *time.Time
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
 }
@@ -50,26 +116,147 @@ type Accept struct {
 	WorkerAddress   string
 	WorkerSignature string
 	CreatedAt       time.Time
+	// WorkerTier is the worker's trust tier, populated by ListAccepts.
+	WorkerTier int
+	// Terms is the task terms snapshot taken by InsertAccept. Nil for accepts
+	// stored before snapshots existed.
+	Terms *AcceptTerms
+	// IfUpdatedAt, when set, is the task updated_at the worker last saw.
+	// InsertAccept returns ErrTaskChanged if the task has changed since. It
+	// is a precondition only and is not stored.
+	IfUpdatedAt *time.Time
+}
+
+// AcceptTerms is the snapshot of task terms at the time of acceptance.
+type AcceptTerms struct {
+	AmountWei     string
+	DeadlineUnix  int64
+	TitleHash     string // keccak256(utf8(title))
+	IndexerFeeBPS int
 }
 
-// TaskRepo defines structured task/accept storage operations.
+// TxTaskMatch is a task touched by an onchain transaction. Event is one of
+// the TxEvent* constants.
+type TxTaskMatch struct {
+	Event string
+	Task  *Task
+}
+
+// Onchain events recorded against a task's per-purpose tx hash columns.
+const (
+	TxEventCreated   = "created"
+	TxEventWorkerSet = "worker_set"
+	TxEventReleased  = "released"
+	TxEventRefunded  = "refunded"
+)
+
+// WorkerTier caps the task value a worker may accept. An empty
+// MaxTaskAmountWei means no limit.
+type WorkerTier struct {
+	WorkerAddress    string
+	Tier             int
+	MaxTaskAmountWei string
+	UpdatedBy        string
+	UpdatedAt        time.Time
+}
+
+// TaskRepo defines structured task/accept storage operations. Address
+// arguments are accepted in any case and stored lowercase; malformed ones
+// fail with *AddressError.
 type TaskRepo interface {
-	InsertTask(ctx context.Context, t *Task) error
+	// InsertTask stores t. maxOpen > 0 caps the employer's open tasks (see
+	// OpenTaskStatuses); the cap is checked in the insert transaction and
+	// exceeding it returns *OpenTaskLimitError.
+	InsertTask(ctx context.Context, t *Task, maxOpen int) error
+	// InsertTasks stores a batch of tasks in one transaction; see
+	// PostgresTaskRepo.InsertTasks.
+	InsertTasks(ctx context.Context, tasks []*Task, maxOpen int, allOrNothing bool) ([]error, error)
+	// CountOpenTasks returns how many of the employer's tasks are open.
+	CountOpenTasks(ctx context.Context, employerAddress string) (int64, error)
 	GetTask(ctx context.Context, taskID string) (*Task, error)
-	GetTaskByHash(ctx context.Context, taskHash string) (*Task, error)
-	ListTasks(ctx context.Context, chainID int, status string, limit, offset int) ([]*Task, error)
+	// GetTaskRawRequest returns the canonical creation request stored with
+	// the task, or nil for tasks created before it was recorded.
+	GetTaskRawRequest(ctx context.Context, taskID string) ([]byte, error)
+	// NextEmployerSequence returns the smallest sequence number the employer
+	// may submit next (1 if the employer has never used sequences).
+	NextEmployerSequence(ctx context.Context, employerAddress string) (int64, error)
+	// GetTaskByHash returns the task registered on chainID with taskHash.
+	// Onchain events are matched this way so a log on one chain never
+	// resolves to a task registered for another.
+	GetTaskByHash(ctx context.Context, chainID int, taskHash string) (*Task, error)
+	// FindTasksByTxHash returns every task touched by an onchain transaction,
+	// one entry per (task, event) pair.
+	FindTasksByTxHash(ctx context.Context, txHash string) ([]*TxTaskMatch, error)
+	// ListTasks returns public tasks matching f, newest first with ties
+	// broken by task_id descending. Private tasks are never listed.
+	ListTasks(ctx context.Context, f TaskFilter) ([]*Task, error)
+	// ListTasksUpdatedSince returns public tasks with updated_at in
+	// (since, until], oldest change first, paginated by (updated_at, task_id).
+	// Soft-deleted tasks are included with DeletedAt set, so a consumer
+	// learns of the delete. Zero until, zero chainID and empty status do not
+	// filter.
+	ListTasksUpdatedSince(ctx context.Context, since, until time.Time, chainID int, status string, limit int, cursor *Cursor) ([]*Task, *Cursor, error)
+	// InsertAccept returns ErrConflict if the task already has an accept
+	// with a.AcceptID or one from a.WorkerAddress. accept_id is unique per
+	// task, not globally.
 	InsertAccept(ctx context.Context, a *Accept) error
+	GetAccept(ctx context.Context, taskID, acceptID string) (*Accept, error)
+	ListAccepts(ctx context.Context, taskID string) ([]*Accept, error)
+	// UpdateTaskWorker sets the task's worker and status. Returns
+	// ErrNotFound for an unknown task.
 	UpdateTaskWorker(ctx context.Context, taskID, workerAddress, status string) error
-	// Onchain sync methods
-	UpdateOnchainCreated(ctx context.Context, taskID, txHash string, at time.Time) error
-	UpdateOnchainWorkerSet(ctx context.Context, taskHash, workerAddress, txHash string) error
-	UpdateOnchainReleased(ctx context.Context, taskHash, txHash string, at time.Time) error
-	UpdateOnchainRefunded(ctx context.Context, taskHash, txHash string, at time.Time) error
+	// DeleteTask removes a task from every read. With soft the row stays
+	// behind with deleted_at set; otherwise it is dropped together with its
+	// accepts. Returns ErrNotFound for an unknown task, and with soft for one
+	// already deleted. Tasks created onchain are kept: their escrow still has
+	// to settle. DeleteTask returns ErrTaskOnchain for them, checked in the
+	// same statement so a task funded meanwhile is never deleted.
+	DeleteTask(ctx context.Context, taskID string, soft bool) error
+	// Chains dropped from the configuration; see retired.go
+	ListOpenTaskChains(ctx context.Context) ([]int, error)
+	SetChainRetired(ctx context.Context, chainID int, retired bool) (int64, error)
+	CountTasksByStatus(ctx context.Context) (map[string]int64, error)
+	// Unfunded accepts; see unfunded.go
+	ListUnfundedAccepts(ctx context.Context, acceptedBefore time.Time, limit int) ([]UnfundedAccept, error)
+	CountUnfundedAcceptsByEmployer(ctx context.Context, acceptedBefore time.Time) (map[string]int64, error)
+	RevertUnfundedAccepts(ctx context.Context, acceptedBefore time.Time) ([]UnfundedAccept, error)
+	// Deadline notifications
+	ListAcceptedTasksDueBetween(ctx context.Context, from, to time.Time) ([]*Task, error)
+	ClaimTaskNotification(ctx context.Context, taskID, kind string, window time.Duration) (bool, error)
+	// Worker tiers
+	GetWorkerTier(ctx context.Context, workerAddress string) (*WorkerTier, error)
+	SetWorkerTier(ctx context.Context, t *WorkerTier) error
+	// Fee ledger; see fees.go
+	RecordFee(ctx context.Context, e *FeeEntry) (mismatch bool, err error)
+	SumFees(ctx context.Context, f FeeFilter) ([]FeeTotal, error)
+	ListFees(ctx context.Context, f FeeFilter) ([]*FeeEntry, error)
+	// Escrowed value; see tvl.go
+	SumTVL(ctx context.Context) ([]TVLTotal, error)
+	// HasArtifact reports whether an artifact object references taskID in
+	// its payload.
+	HasArtifact(ctx context.Context, taskID string) (bool, error)
+	// Unrecognized settlement logs; see unknown_logs.go
+	RecordUnknownLog(ctx context.Context, l *UnknownLog) error
+	// Audit trail
+	InsertAuditEvent(ctx context.Context, e *AuditEvent) error
+	ListAuditEvents(ctx context.Context, f AuditFilter, limit int, cursor *Cursor) ([]*AuditEvent, *Cursor, error)
+	AckAuditEvent(ctx context.Context, id int64, ack bool, by string) (*AuditEvent, error)
+	// Task status history; see status_events.go
+	ListTaskStatusEvents(ctx context.Context, limit int, cursor *Cursor) ([]*TaskStatusEvent, *Cursor, error)
+	// Onchain sync methods. Those keyed by task hash only touch the task
+	// registered on chainID; an event for a hash registered elsewhere is a
+	// no-op. UpdateOnchainCreated returns ErrNotFound for an unknown task,
+	// and leaves the onchain terms unset when terms is nil.
+	UpdateOnchainCreated(ctx context.Context, taskID, txHash string, terms *OnchainTerms, at time.Time) error
+	UpdateOnchainWorkerSet(ctx context.Context, chainID int, taskHash, workerAddress, txHash string) error
+	UpdateOnchainReleased(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error
+	UpdateOnchainRefunded(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error
 }
 
 // PostgresTaskRepo implements TaskRepo using PostgreSQL.
 type PostgresTaskRepo struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	replica *pgxpool.Pool // nil: reads use pool; see WithReplica
 }
 
 // NewPostgresTaskRepo creates a PostgresTaskRepo.
@@ -77,16 +264,120 @@ func NewPostgresTaskRepo(pool *pgxpool.Pool) *PostgresTaskRepo {
 	return &PostgresTaskRepo{pool: pool}
 }
 
-func (r *PostgresTaskRepo) InsertTask(ctx context.Context, t *Task) error {
+// InsertTask stores a task. Its addresses are normalized in place; see
+// normalizeAddress. If t.EmployerSequence is set it must be strictly
+// greater than the employer's last recorded sequence, otherwise
+// ErrSequenceConflict is returned and nothing is stored. With maxOpen > 0,
+// inserts for one employer are serialized by a transaction-scoped advisory
+// lock so concurrent creates cannot both take the last slot.
+func (r *PostgresTaskRepo) InsertTask(ctx context.Context, t *Task, maxOpen int) error {
+	if err := normalizeTaskAddresses(t); err != nil {
+		return err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertTaskTx(ctx, tx, t, maxOpen); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit task: %w", err)
+	}
+	return nil
+}
+
+// InsertTasks stores tasks in one transaction, each under its own savepoint,
+// and returns what InsertTask would have returned for each in errs. With
+// allOrNothing, a failed task rolls back the whole batch: errs still tells
+// which failed, and the error is ErrBatchAborted.
+func (r *PostgresTaskRepo) InsertTasks(ctx context.Context, tasks []*Task, maxOpen int, allOrNothing bool) ([]error, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	errs := make([]error, len(tasks))
+	failed := false
+	for i, t := range tasks {
+		if err := normalizeTaskAddresses(t); err != nil {
+			errs[i], failed = err, true
+			continue
+		}
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("savepoint: %w", err)
+		}
+		if err := insertTaskTx(ctx, sp, t, maxOpen); err != nil {
+			if rerr := sp.Rollback(ctx); rerr != nil {
+				return nil, fmt.Errorf("rollback to savepoint: %w", rerr)
+			}
+			errs[i], failed = err, true
+			continue
+		}
+		if err := sp.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("release savepoint: %w", err)
+		}
+	}
+	if failed && allOrNothing {
+		return errs, ErrBatchAborted
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tasks: %w", err)
+	}
+	return errs, nil
+}
+
+// insertTaskTx is InsertTask within tx; t's addresses are already normalized.
+func insertTaskTx(ctx context.Context, tx pgx.Tx, t *Task, maxOpen int) error {
+	if maxOpen > 0 {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('open_tasks:' || $1))`, t.EmployerAddress); err != nil {
+			return fmt.Errorf("lock employer: %w", err)
+		}
+		var open int64
+		if err := tx.QueryRow(ctx, countOpenTasksQuery, t.EmployerAddress, OpenTaskStatuses).Scan(&open); err != nil {
+			return fmt.Errorf("count open tasks: %w", err)
+		}
+		if open >= int64(maxOpen) {
+			return &OpenTaskLimitError{Limit: maxOpen, Open: open}
+		}
+	}
+
+	if t.EmployerSequence != nil {
+		const seqQ = `
+INSERT INTO employer_sequences (employer_address, last_sequence, updated_at)
+VALUES ($1, $2, now())
+ON CONFLICT (employer_address) DO UPDATE
+SET last_sequence = EXCLUDED.last_sequence, updated_at = now()
+WHERE employer_sequences.last_sequence < EXCLUDED.last_sequence
+RETURNING last_sequence`
+		var last int64
+		if err := tx.QueryRow(ctx, seqQ, t.EmployerAddress, *t.EmployerSequence).Scan(&last); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrSequenceConflict
+			}
+			return fmt.Errorf("advance employer sequence: %w", err)
+		}
+	}
+
 	const q = `
 INSERT INTO tasks (task_id, task_hash, chain_id, escrow_address, employer_address,
                    employer_signature, amount_wei, deadline_unix, title, status,
-                   indexer_fee_bps, created_at, updated_at)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),now())`
-	_, err := r.pool.Exec(ctx, q,
+                   indexer_fee_bps, employer_sequence, envelope_object_id, visibility, allowed_workers,
+                   token_address, raw_request, created_at, updated_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,NULLIF($13,''),COALESCE(NULLIF($14,''),'public'),$15,NULLIF($16,''),$17,now(),now())`
+	allowed := t.AllowedWorkers
+	if allowed == nil {
+		allowed = []string{}
+	}
+	_, err := tx.Exec(ctx, q,
 		t.TaskID, t.TaskHash, t.ChainID, t.EscrowAddress, t.EmployerAddress,
 		t.EmployerSignature, t.AmountWei, t.DeadlineUnix, t.Title, t.Status,
-		t.IndexerFeeBPS,
+		t.IndexerFeeBPS, t.EmployerSequence, t.EnvelopeObjectID, t.Visibility, allowed,
+		t.TokenAddress, t.RawRequest,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -98,22 +389,56 @@ VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),now())`
 	return nil
 }
 
+const countOpenTasksQuery = `SELECT count(*) FROM tasks WHERE employer_address = $1 AND status = ANY($2) AND deleted_at IS NULL`
+
+func (r *PostgresTaskRepo) CountOpenTasks(ctx context.Context, employerAddress string) (int64, error) {
+	employerAddress, err := normalizeAddress("employer_address", employerAddress)
+	if err != nil {
+		return 0, err
+	}
+	var open int64
+	if err := r.pool.QueryRow(ctx, countOpenTasksQuery, employerAddress, OpenTaskStatuses).Scan(&open); err != nil {
+		return 0, fmt.Errorf("count open tasks: %w", err)
+	}
+	return open, nil
+}
+
+func (r *PostgresTaskRepo) NextEmployerSequence(ctx context.Context, employerAddress string) (int64, error) {
+	employerAddress, err := normalizeAddress("employer_address", employerAddress)
+	if err != nil {
+		return 0, err
+	}
+	var last int64
+	err = r.pool.QueryRow(ctx,
+		`SELECT last_sequence FROM employer_sequences WHERE employer_address = $1`, employerAddress,
+	).Scan(&last)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 1, nil
+		}
+		return 0, fmt.Errorf("next employer sequence: %w", err)
+	}
+	return last + 1, nil
+}
+
 func (r *PostgresTaskRepo) GetTask(ctx context.Context, taskID string) (*Task, error) {
 	const q = `
 SELECT task_id, task_hash, chain_id, escrow_address, employer_address,
        COALESCE(employer_signature,''), COALESCE(worker_address,''),
        amount_wei, deadline_unix, COALESCE(title,''), status, indexer_fee_bps,
        onchain_created_at, released_at, refunded_at, COALESCE(onchain_tx_hash,''),
-       created_at, updated_at
-FROM tasks WHERE task_id = $1`
-	row := r.pool.QueryRow(ctx, q, taskID)
+       employer_sequence, COALESCE(envelope_object_id,''), visibility, allowed_workers,
+       COALESCE(token_address,''), COALESCE(onchain_worker_address,''), worker_mismatch, deleted_at, chain_retired_at, COALESCE(onchain_amount_wei,''), onchain_deadline_unix, COALESCE(encode(sha256(raw_request), 'hex'),''), created_at, updated_at
+FROM tasks WHERE task_id = $1 AND (deleted_at IS NULL OR $2)`
+	row := r.reader(ctx).QueryRow(ctx, q, taskID, IncludesDeleted(ctx))
 	t := &Task{}
 	err := row.Scan(
 		&t.TaskID, &t.TaskHash, &t.ChainID, &t.EscrowAddress, &t.EmployerAddress,
 		&t.EmployerSignature, &t.WorkerAddress,
 		&t.AmountWei, &t.DeadlineUnix, &t.Title, &t.Status, &t.IndexerFeeBPS,
 		&t.OnchainCreatedAt, &t.ReleasedAt, &t.RefundedAt, &t.OnchainTxHash,
-		&t.CreatedAt, &t.UpdatedAt,
+		&t.EmployerSequence, &t.EnvelopeObjectID, &t.Visibility, &t.AllowedWorkers,
+		&t.TokenAddress, &t.OnchainWorkerAddress, &t.WorkerMismatch, &t.DeletedAt, &t.ChainRetiredAt, &t.OnchainAmountWei, &t.OnchainDeadlineUnix, &t.RawRequestSHA256, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -124,22 +449,38 @@ FROM tasks WHERE task_id = $1`
 	return t, nil
 }
 
-func (r *PostgresTaskRepo) GetTaskByHash(ctx context.Context, taskHash string) (*Task, error) {
+// GetTaskRawRequest returns the creation request stored with the task, or
+// nil if none was recorded. It returns ErrNotFound for an unknown task.
+func (r *PostgresTaskRepo) GetTaskRawRequest(ctx context.Context, taskID string) ([]byte, error) {
+	var raw []byte
+	err := r.pool.QueryRow(ctx, `SELECT raw_request FROM tasks WHERE task_id = $1 AND (deleted_at IS NULL OR $2)`, taskID, IncludesDeleted(ctx)).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get task raw request: %w", err)
+	}
+	return raw, nil
+}
+
+func (r *PostgresTaskRepo) GetTaskByHash(ctx context.Context, chainID int, taskHash string) (*Task, error) {
 	const q = `
 SELECT task_id, task_hash, chain_id, escrow_address, employer_address,
        COALESCE(employer_signature,''), COALESCE(worker_address,''),
        amount_wei, deadline_unix, COALESCE(title,''), status, indexer_fee_bps,
        onchain_created_at, released_at, refunded_at, COALESCE(onchain_tx_hash,''),
-       created_at, updated_at
-FROM tasks WHERE task_hash = $1`
-	row := r.pool.QueryRow(ctx, q, taskHash)
+       employer_sequence, COALESCE(envelope_object_id,''), visibility, allowed_workers,
+       COALESCE(token_address,''), COALESCE(onchain_worker_address,''), worker_mismatch, deleted_at, chain_retired_at, COALESCE(onchain_amount_wei,''), onchain_deadline_unix, created_at, updated_at
+FROM tasks WHERE task_hash = $1 AND chain_id = $2 AND (deleted_at IS NULL OR $3)`
+	row := r.reader(ctx).QueryRow(ctx, q, taskHash, chainID, IncludesDeleted(ctx))
 	t := &Task{}
 	err := row.Scan(
 		&t.TaskID, &t.TaskHash, &t.ChainID, &t.EscrowAddress, &t.EmployerAddress,
 		&t.EmployerSignature, &t.WorkerAddress,
 		&t.AmountWei, &t.DeadlineUnix, &t.Title, &t.Status, &t.IndexerFeeBPS,
 		&t.OnchainCreatedAt, &t.ReleasedAt, &t.RefundedAt, &t.OnchainTxHash,
-		&t.CreatedAt, &t.UpdatedAt,
+		&t.EmployerSequence, &t.EnvelopeObjectID, &t.Visibility, &t.AllowedWorkers,
+		&t.TokenAddress, &t.OnchainWorkerAddress, &t.WorkerMismatch, &t.DeletedAt, &t.ChainRetiredAt, &t.OnchainAmountWei, &t.OnchainDeadlineUnix, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -150,30 +491,88 @@ FROM tasks WHERE task_hash = $1`
 	return t, nil
 }
 
-func (r *PostgresTaskRepo) ListTasks(ctx context.Context, chainID int, status string, limit, offset int) ([]*Task, error) {
+func (r *PostgresTaskRepo) FindTasksByTxHash(ctx context.Context, txHash string) ([]*TxTaskMatch, error) {
+	// One indexed branch per tx hash column rather than an OR across columns.
+	const cols = `task_id, task_hash, chain_id, escrow_address, employer_address,
+       COALESCE(employer_signature,''), COALESCE(worker_address,''),
+       amount_wei, deadline_unix, COALESCE(title,''), status, indexer_fee_bps,
+       onchain_created_at, released_at, refunded_at, COALESCE(onchain_tx_hash,''),
+       employer_sequence, COALESCE(envelope_object_id,''), visibility, allowed_workers,
+       COALESCE(token_address,''), COALESCE(onchain_worker_address,''), worker_mismatch, deleted_at, chain_retired_at, COALESCE(onchain_amount_wei,''), onchain_deadline_unix, created_at, updated_at`
+	q := `
+SELECT '` + TxEventCreated + `', ` + cols + ` FROM tasks WHERE created_tx_hash = $1 AND (deleted_at IS NULL OR $2)
+UNION ALL
+SELECT '` + TxEventWorkerSet + `', ` + cols + ` FROM tasks WHERE worker_set_tx_hash = $1 AND (deleted_at IS NULL OR $2)
+UNION ALL
+SELECT '` + TxEventReleased + `', ` + cols + ` FROM tasks WHERE released_tx_hash = $1 AND (deleted_at IS NULL OR $2)
+UNION ALL
+SELECT '` + TxEventRefunded + `', ` + cols + ` FROM tasks WHERE refunded_tx_hash = $1 AND (deleted_at IS NULL OR $2)
+ORDER BY created_at, task_id`
+
+	rows, err := r.reader(ctx).Query(ctx, q, strings.ToLower(txHash), IncludesDeleted(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("find tasks by tx hash: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*TxTaskMatch
+	for rows.Next() {
+		m := &TxTaskMatch{Task: &Task{}}
+		t := m.Task
+		if err := rows.Scan(
+			&m.Event,
+			&t.TaskID, &t.TaskHash, &t.ChainID, &t.EscrowAddress, &t.EmployerAddress,
+			&t.EmployerSignature, &t.WorkerAddress,
+			&t.AmountWei, &t.DeadlineUnix, &t.Title, &t.Status, &t.IndexerFeeBPS,
+			&t.OnchainCreatedAt, &t.ReleasedAt, &t.RefundedAt, &t.OnchainTxHash,
+			&t.EmployerSequence, &t.EnvelopeObjectID, &t.Visibility, &t.AllowedWorkers,
+			&t.TokenAddress, &t.OnchainWorkerAddress, &t.WorkerMismatch, &t.DeletedAt, &t.ChainRetiredAt, &t.OnchainAmountWei, &t.OnchainDeadlineUnix, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+func (r *PostgresTaskRepo) ListTasks(ctx context.Context, f TaskFilter) ([]*Task, error) {
 	q := `
 SELECT task_id, task_hash, chain_id, escrow_address, employer_address,
        COALESCE(employer_signature,''), COALESCE(worker_address,''),
        amount_wei, deadline_unix, COALESCE(title,''), status, indexer_fee_bps,
        onchain_created_at, released_at, refunded_at, COALESCE(onchain_tx_hash,''),
-       created_at, updated_at
-FROM tasks WHERE 1=1`
-	args := []any{}
-	idx := 1
-	if chainID > 0 {
+       employer_sequence, COALESCE(envelope_object_id,''), visibility, allowed_workers,
+       COALESCE(token_address,''), COALESCE(onchain_worker_address,''), worker_mismatch, deleted_at, chain_retired_at, COALESCE(onchain_amount_wei,''), onchain_deadline_unix, created_at, updated_at
+FROM tasks WHERE visibility = 'public' AND (deleted_at IS NULL OR $1)`
+	args := []any{IncludesDeleted(ctx)}
+	idx := 2
+	if f.ChainID > 0 {
 		q += fmt.Sprintf(" AND chain_id = $%d", idx)
-		args = append(args, chainID)
+		args = append(args, f.ChainID)
 		idx++
 	}
-	if status != "" {
+	if f.Status != "" {
 		q += fmt.Sprintf(" AND status = $%d", idx)
-		args = append(args, status)
+		args = append(args, f.Status)
+		idx++
+	}
+	switch f.Onchain {
+	case OnchainSynced:
+		q += " AND onchain_created_at IS NOT NULL"
+	case OnchainUnsynced:
+		q += " AND onchain_created_at IS NULL"
+	}
+	if f.CreatedBefore != nil {
+		q += fmt.Sprintf(" AND created_at < $%d", idx)
+		args = append(args, *f.CreatedBefore)
 		idx++
 	}
-	q += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", idx, idx+1)
-	args = append(args, limit, offset)
+	// task_id breaks ties: a batch insert gives every task the same
+	// created_at, and offset pages must not overlap.
+	q += fmt.Sprintf(" ORDER BY created_at DESC, task_id DESC LIMIT $%d OFFSET $%d", idx, idx+1)
+	args = append(args, f.Limit, f.Offset)
 
-	rows, err := r.pool.Query(ctx, q, args...)
+	rows, err := r.reader(ctx).Query(ctx, q, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list tasks: %w", err)
 	}
@@ -187,7 +586,8 @@ FROM tasks WHERE 1=1`
 			&t.EmployerSignature, &t.WorkerAddress,
 			&t.AmountWei, &t.DeadlineUnix, &t.Title, &t.Status, &t.IndexerFeeBPS,
 			&t.OnchainCreatedAt, &t.ReleasedAt, &t.RefundedAt, &t.OnchainTxHash,
-			&t.CreatedAt, &t.UpdatedAt,
+			&t.EmployerSequence, &t.EnvelopeObjectID, &t.Visibility, &t.AllowedWorkers,
+			&t.TokenAddress, &t.OnchainWorkerAddress, &t.WorkerMismatch, &t.DeletedAt, &t.ChainRetiredAt, &t.OnchainAmountWei, &t.OnchainDeadlineUnix, &t.CreatedAt, &t.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan task: %w", err)
 		}
@@ -196,9 +596,120 @@ FROM tasks WHERE 1=1`
 	return tasks, rows.Err()
 }
 
+// ListTasksUpdatedSince is served by idx_tasks_public_updated_at. Cursors
+// carry the stored updated_at and task_id, with Order set to OrderUpdated.
+func (r *PostgresTaskRepo) ListTasksUpdatedSince(ctx context.Context, since, until time.Time, chainID int, status string, limit int, cursor *Cursor) ([]*Task, *Cursor, error) {
+	q := `
+SELECT task_id, task_hash, chain_id, escrow_address, employer_address,
+       COALESCE(employer_signature,''), COALESCE(worker_address,''),
+       amount_wei, deadline_unix, COALESCE(title,''), status, indexer_fee_bps,
+       onchain_created_at, released_at, refunded_at, COALESCE(onchain_tx_hash,''),
+       employer_sequence, COALESCE(envelope_object_id,''), visibility, allowed_workers,
+       COALESCE(token_address,''), COALESCE(onchain_worker_address,''), worker_mismatch, deleted_at, chain_retired_at, COALESCE(onchain_amount_wei,''), onchain_deadline_unix, created_at, updated_at
+FROM tasks WHERE visibility = 'public' AND updated_at > $1`
+	args := []any{since}
+	if !until.IsZero() {
+		args = append(args, until)
+		q += fmt.Sprintf(" AND updated_at <= $%d", len(args))
+	}
+	if chainID > 0 {
+		args = append(args, chainID)
+		q += fmt.Sprintf(" AND chain_id = $%d", len(args))
+	}
+	if status != "" {
+		args = append(args, status)
+		q += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if cursor != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, cursor.CreatedAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse cursor time: %w", err)
+		}
+		args = append(args, cursorTime, cursor.ObjectID)
+		q += fmt.Sprintf(" AND (updated_at, task_id) > ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	q += fmt.Sprintf(" ORDER BY updated_at ASC, task_id ASC LIMIT $%d", len(args))
+
+	rows, err := r.reader(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list tasks updated since: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		t := &Task{}
+		if err := rows.Scan(
+			&t.TaskID, &t.TaskHash, &t.ChainID, &t.EscrowAddress, &t.EmployerAddress,
+			&t.EmployerSignature, &t.WorkerAddress,
+			&t.AmountWei, &t.DeadlineUnix, &t.Title, &t.Status, &t.IndexerFeeBPS,
+			&t.OnchainCreatedAt, &t.ReleasedAt, &t.RefundedAt, &t.OnchainTxHash,
+			&t.EmployerSequence, &t.EnvelopeObjectID, &t.Visibility, &t.AllowedWorkers,
+			&t.TokenAddress, &t.OnchainWorkerAddress, &t.WorkerMismatch, &t.DeletedAt, &t.ChainRetiredAt, &t.OnchainAmountWei, &t.OnchainDeadlineUnix, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows: %w", err)
+	}
+
+	var next *Cursor
+	if len(tasks) > limit {
+		last := tasks[limit-1]
+		next = &Cursor{
+			CreatedAt: last.UpdatedAt.UTC().Format(time.RFC3339Nano),
+			ObjectID:  last.TaskID,
+			Order:     OrderUpdated,
+		}
+		tasks = tasks[:limit]
+	}
+	return tasks, next, nil
+}
+
+// InsertAccept stores an accept together with a snapshot of the task's
+// current terms, read in the same transaction. a.WorkerAddress is normalized
+// in place and a.Terms is set on success.
 func (r *PostgresTaskRepo) InsertAccept(ctx context.Context, a *Accept) error {
-	const q = `INSERT INTO accepts (accept_id, task_id, worker_address, worker_signature, created_at) VALUES ($1,$2,$3,$4,now())`
-	_, err := r.pool.Exec(ctx, q, a.AcceptID, a.TaskID, a.WorkerAddress, a.WorkerSignature)
+	worker, err := normalizeAddress("worker_address", a.WorkerAddress)
+	if err != nil {
+		return err
+	}
+	a.WorkerAddress = worker
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var terms AcceptTerms
+	var title string
+	var updatedAt time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT amount_wei, deadline_unix, COALESCE(title,''), indexer_fee_bps, updated_at FROM tasks WHERE task_id = $1 AND deleted_at IS NULL FOR SHARE`,
+		a.TaskID,
+	).Scan(&terms.AmountWei, &terms.DeadlineUnix, &title, &terms.IndexerFeeBPS, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("snapshot task terms: %w", err)
+	}
+	// The row lock held until commit keeps the terms from changing between
+	// this check and the insert. Postgres keeps microseconds.
+	if a.IfUpdatedAt != nil && !a.IfUpdatedAt.Truncate(time.Microsecond).Equal(updatedAt.Truncate(time.Microsecond)) {
+		return ErrTaskChanged
+	}
+	terms.TitleHash = ethutil.Keccak256Hex([]byte(title))
+
+	const q = `
+INSERT INTO accepts (accept_id, task_id, worker_address, worker_signature, created_at,
+                     terms_amount_wei, terms_deadline_unix, terms_title_hash, terms_indexer_fee_bps)
+VALUES ($1,$2,$3,$4,now(),$5,$6,$7,$8)`
+	_, err = tx.Exec(ctx, q, a.AcceptID, a.TaskID, a.WorkerAddress, a.WorkerSignature,
+		terms.AmountWei, terms.DeadlineUnix, terms.TitleHash, terms.IndexerFeeBPS)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -206,50 +717,297 @@ func (r *PostgresTaskRepo) InsertAccept(ctx context.Context, a *Accept) error {
 		}
 		return fmt.Errorf("insert accept: %w", err)
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit accept: %w", err)
+	}
+	a.Terms = &terms
 	return nil
 }
 
+func (r *PostgresTaskRepo) GetAccept(ctx context.Context, taskID, acceptID string) (*Accept, error) {
+	const q = `
+SELECT accept_id, task_id, worker_address, COALESCE(worker_signature,''), created_at
+FROM accepts WHERE task_id = $1 AND accept_id = $2`
+	a := &Accept{}
+	err := r.reader(ctx).QueryRow(ctx, q, taskID, acceptID).Scan(&a.AcceptID, &a.TaskID, &a.WorkerAddress, &a.WorkerSignature, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get accept: %w", err)
+	}
+	return a, nil
+}
+
+func (r *PostgresTaskRepo) ListAccepts(ctx context.Context, taskID string) ([]*Accept, error) {
+	const q = `
+SELECT a.accept_id, a.task_id, a.worker_address, COALESCE(a.worker_signature,''), a.created_at,
+       COALESCE(wt.tier, 0),
+       a.terms_amount_wei, a.terms_deadline_unix, a.terms_title_hash, a.terms_indexer_fee_bps
+FROM accepts a
+LEFT JOIN worker_tiers wt ON wt.worker_address = a.worker_address
+WHERE a.task_id = $1
+ORDER BY a.created_at ASC, a.accept_id ASC`
+	rows, err := r.reader(ctx).Query(ctx, q, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list accepts: %w", err)
+	}
+	defer rows.Close()
+
+	var accepts []*Accept
+	for rows.Next() {
+		a := &Accept{}
+		var amountWei, titleHash *string
+		var deadline *int64
+		var feeBPS *int
+		if err := rows.Scan(&a.AcceptID, &a.TaskID, &a.WorkerAddress, &a.WorkerSignature, &a.CreatedAt, &a.WorkerTier,
+			&amountWei, &deadline, &titleHash, &feeBPS); err != nil {
+			return nil, fmt.Errorf("scan accept: %w", err)
+		}
+		if amountWei != nil && deadline != nil && titleHash != nil && feeBPS != nil {
+			a.Terms = &AcceptTerms{AmountWei: *amountWei, DeadlineUnix: *deadline, TitleHash: *titleHash, IndexerFeeBPS: *feeBPS}
+		}
+		accepts = append(accepts, a)
+	}
+	return accepts, rows.Err()
+}
+
 func (r *PostgresTaskRepo) UpdateTaskWorker(ctx context.Context, taskID, workerAddress, status string) error {
-	const q = `UPDATE tasks SET worker_address=$1, status=$2, updated_at=now() WHERE task_id=$3`
-	_, err := r.pool.Exec(ctx, q, workerAddress, status, taskID)
+	workerAddress, err := normalizeAddress("worker_address", workerAddress)
+	if err != nil {
+		return err
+	}
+	const q = `UPDATE tasks SET worker_address=$1, status=$2, updated_at=now() WHERE task_id=$3 AND deleted_at IS NULL`
+	tag, err := r.pool.Exec(ctx, q, workerAddress, status, taskID)
 	if err != nil {
 		return fmt.Errorf("update task worker: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresTaskRepo) DeleteTask(ctx context.Context, taskID string, soft bool) error {
+	q := `DELETE FROM tasks WHERE task_id = $1 AND onchain_created_at IS NULL`
+	if soft {
+		q = `UPDATE tasks SET deleted_at = now(), updated_at = now() WHERE task_id = $1 AND onchain_created_at IS NULL AND deleted_at IS NULL`
+	}
+	tag, err := r.pool.Exec(ctx, q, taskID)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+	// Nothing deleted: tell a funded task from a missing one.
+	var onchain bool
+	err = r.pool.QueryRow(ctx,
+		`SELECT onchain_created_at IS NOT NULL FROM tasks WHERE task_id = $1 AND (deleted_at IS NULL OR NOT $2)`,
+		taskID, soft,
+	).Scan(&onchain)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return fmt.Errorf("delete task: %w", err)
+	case onchain:
+		return ErrTaskOnchain
+	}
+	return ErrNotFound
+}
+
+// ListAcceptedTasksDueBetween returns accepted and accepted_onchain tasks
+// whose deadline falls in (from, to], soonest first.
+func (r *PostgresTaskRepo) ListAcceptedTasksDueBetween(ctx context.Context, from, to time.Time) ([]*Task, error) {
+	const q = `
+SELECT task_id, task_hash, chain_id, escrow_address, employer_address,
+       COALESCE(employer_signature,''), COALESCE(worker_address,''),
+       amount_wei, deadline_unix, COALESCE(title,''), status, indexer_fee_bps,
+       onchain_created_at, released_at, refunded_at, COALESCE(onchain_tx_hash,''),
+       employer_sequence, COALESCE(envelope_object_id,''), visibility, allowed_workers,
+       COALESCE(token_address,''), COALESCE(onchain_worker_address,''), worker_mismatch, deleted_at, chain_retired_at, COALESCE(onchain_amount_wei,''), onchain_deadline_unix, created_at, updated_at
+FROM tasks
+WHERE status IN ($1, $2) AND deadline_unix > $3 AND deadline_unix <= $4 AND deleted_at IS NULL
+ORDER BY deadline_unix, task_id`
+	rows, err := r.pool.Query(ctx, q, TaskStatusAccepted, TaskStatusAcceptedOnchain, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("list tasks due: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		t := &Task{}
+		if err := rows.Scan(
+			&t.TaskID, &t.TaskHash, &t.ChainID, &t.EscrowAddress, &t.EmployerAddress,
+			&t.EmployerSignature, &t.WorkerAddress,
+			&t.AmountWei, &t.DeadlineUnix, &t.Title, &t.Status, &t.IndexerFeeBPS,
+			&t.OnchainCreatedAt, &t.ReleasedAt, &t.RefundedAt, &t.OnchainTxHash,
+			&t.EmployerSequence, &t.EnvelopeObjectID, &t.Visibility, &t.AllowedWorkers,
+			&t.TokenAddress, &t.OnchainWorkerAddress, &t.WorkerMismatch, &t.DeletedAt, &t.ChainRetiredAt, &t.OnchainAmountWei, &t.OnchainDeadlineUnix, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// ClaimTaskNotification records that the kind notification for window has
+// been sent for taskID. It returns false if it was already recorded, so each
+// (task, kind, window) is notified at most once.
+func (r *PostgresTaskRepo) ClaimTaskNotification(ctx context.Context, taskID, kind string, window time.Duration) (bool, error) {
+	const q = `
+INSERT INTO task_notifications (task_id, kind, window_seconds)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING`
+	tag, err := r.pool.Exec(ctx, q, taskID, kind, int64(window/time.Second))
+	if err != nil {
+		return false, fmt.Errorf("claim task notification: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostgresTaskRepo) CountTasksByStatus(ctx context.Context) (map[string]int64, error) {
+	const q = `SELECT status, count(*) FROM tasks WHERE deleted_at IS NULL GROUP BY status`
+	rows, err := r.reader(ctx).Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var n int64
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan task count: %w", err)
+		}
+		out[status] = n
+	}
+	return out, rows.Err()
+}
+
+// ── Worker tiers ───────────────────────────────────────────────────────────────
+
+// GetWorkerTier returns the tier for workerAddress, or ErrNotFound if the
+// worker has never been assigned one.
+func (r *PostgresTaskRepo) GetWorkerTier(ctx context.Context, workerAddress string) (*WorkerTier, error) {
+	workerAddress, err := normalizeAddress("worker_address", workerAddress)
+	if err != nil {
+		return nil, err
+	}
+	const q = `
+SELECT worker_address, tier, COALESCE(max_task_amount_wei,''), COALESCE(updated_by,''), updated_at
+FROM worker_tiers WHERE worker_address = $1`
+	t := &WorkerTier{}
+	err = r.reader(ctx).QueryRow(ctx, q, workerAddress).Scan(
+		&t.WorkerAddress, &t.Tier, &t.MaxTaskAmountWei, &t.UpdatedBy, &t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get worker tier: %w", err)
+	}
+	return t, nil
+}
+
+// SetWorkerTier upserts t. t.WorkerAddress is normalized in place.
+func (r *PostgresTaskRepo) SetWorkerTier(ctx context.Context, t *WorkerTier) error {
+	worker, err := normalizeAddress("worker_address", t.WorkerAddress)
+	if err != nil {
+		return err
+	}
+	t.WorkerAddress = worker
+	const q = `
+INSERT INTO worker_tiers (worker_address, tier, max_task_amount_wei, updated_by, updated_at)
+VALUES ($1, $2, NULLIF($3,''), NULLIF($4,''), now())
+ON CONFLICT (worker_address) DO UPDATE
+SET tier = EXCLUDED.tier,
+    max_task_amount_wei = EXCLUDED.max_task_amount_wei,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()`
+	_, err = r.pool.Exec(ctx, q, t.WorkerAddress, t.Tier, t.MaxTaskAmountWei, t.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("set worker tier: %w", err)
+	}
 	return nil
 }
 
+func (r *PostgresTaskRepo) HasArtifact(ctx context.Context, taskID string) (bool, error) {
+	const q = `SELECT EXISTS (SELECT 1 FROM objects WHERE payload_task_id = $1 AND object_type = 'artifact' AND deleted_at IS NULL)`
+	var ok bool
+	if err := r.reader(ctx).QueryRow(ctx, q, taskID).Scan(&ok); err != nil {
+		return false, fmt.Errorf("has artifact: %w", err)
+	}
+	return ok, nil
+}
+
 // ── Onchain sync methods ───────────────────────────────────────────────────────
 
-func (r *PostgresTaskRepo) UpdateOnchainCreated(ctx context.Context, taskID, txHash string, at time.Time) error {
-	const q = `UPDATE tasks SET onchain_created_at=$1, onchain_tx_hash=$2, updated_at=now() WHERE task_id=$3`
-	_, err := r.pool.Exec(ctx, q, at, txHash, taskID)
+// OnchainTerms are the amount and deadline a Created event escrowed.
+type OnchainTerms struct {
+	AmountWei    string
+	DeadlineUnix int64
+}
+
+func (r *PostgresTaskRepo) UpdateOnchainCreated(ctx context.Context, taskID, txHash string, terms *OnchainTerms, at time.Time) error {
+	const q = `
+UPDATE tasks SET onchain_created_at=$1, onchain_tx_hash=$2, created_tx_hash=lower($2),
+    onchain_amount_wei=COALESCE($4, onchain_amount_wei), onchain_deadline_unix=COALESCE($5, onchain_deadline_unix), updated_at=now()
+WHERE task_id=$3`
+	var amount *string
+	var deadline *int64
+	if terms != nil {
+		amount, deadline = &terms.AmountWei, &terms.DeadlineUnix
+	}
+	tag, err := r.pool.Exec(ctx, q, at, txHash, taskID, amount, deadline)
 	if err != nil {
 		return fmt.Errorf("update onchain created: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
-func (r *PostgresTaskRepo) UpdateOnchainWorkerSet(ctx context.Context, taskHash, workerAddress, txHash string) error {
-	const q = `UPDATE tasks SET worker_address=$1, status=$2, onchain_tx_hash=$3, updated_at=now() WHERE task_hash=$4`
-	_, err := r.pool.Exec(ctx, q, workerAddress, TaskStatusAcceptedOnchain, txHash, taskHash)
+func (r *PostgresTaskRepo) UpdateOnchainWorkerSet(ctx context.Context, chainID int, taskHash, workerAddress, txHash string) error {
+	workerAddress, err := normalizeAddress("worker_address", workerAddress)
+	if err != nil {
+		return err
+	}
+	// A worker set onchain that differs from the off-chain accepter is
+	// recorded beside it rather than replacing it, and the task is not
+	// marked accepted_onchain until the two agree.
+	const q = `
+UPDATE tasks SET
+    onchain_worker_address = $1,
+    worker_mismatch = COALESCE(worker_address,'') NOT IN ('', $1),
+    worker_address = COALESCE(NULLIF(worker_address,''), $1),
+    status = CASE WHEN COALESCE(worker_address,'') IN ('', $1) THEN $2 ELSE status END,
+    onchain_tx_hash=$3, worker_set_tx_hash=lower($3), updated_at=now()
+WHERE task_hash=$4 AND chain_id=$5`
+	_, err = r.pool.Exec(ctx, q, workerAddress, TaskStatusAcceptedOnchain, txHash, taskHash, chainID)
 	if err != nil {
 		return fmt.Errorf("update onchain worker set: %w", err)
 	}
 	return nil
 }
 
-func (r *PostgresTaskRepo) UpdateOnchainReleased(ctx context.Context, taskHash, txHash string, at time.Time) error {
-	const q = `UPDATE tasks SET status=$1, released_at=$2, onchain_tx_hash=$3, updated_at=now() WHERE task_hash=$4`
-	_, err := r.pool.Exec(ctx, q, TaskStatusReleased, at, txHash, taskHash)
+func (r *PostgresTaskRepo) UpdateOnchainReleased(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error {
+	const q = `UPDATE tasks SET status=$1, released_at=$2, onchain_tx_hash=$3, released_tx_hash=lower($3), updated_at=now() WHERE task_hash=$4 AND chain_id=$5`
+	_, err := r.pool.Exec(ctx, q, TaskStatusReleased, at, txHash, taskHash, chainID)
 	if err != nil {
 		return fmt.Errorf("update onchain released: %w", err)
 	}
 	return nil
 }
 
-func (r *PostgresTaskRepo) UpdateOnchainRefunded(ctx context.Context, taskHash, txHash string, at time.Time) error {
-	const q = `UPDATE tasks SET status=$1, refunded_at=$2, onchain_tx_hash=$3, updated_at=now() WHERE task_hash=$4`
-	_, err := r.pool.Exec(ctx, q, TaskStatusRefunded, at, txHash, taskHash)
+func (r *PostgresTaskRepo) UpdateOnchainRefunded(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error {
+	const q = `UPDATE tasks SET status=$1, refunded_at=$2, onchain_tx_hash=$3, refunded_tx_hash=lower($3), updated_at=now() WHERE task_hash=$4 AND chain_id=$5`
+	_, err := r.pool.Exec(ctx, q, TaskStatusRefunded, at, txHash, taskHash, chainID)
 	if err != nil {
 		return fmt.Errorf("update onchain refunded: %w", err)
 	}
@@ -0,0 +1,908 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/ethutil"
+)
+
+// Fixture addresses; stored addresses must be 0x + 40 hex chars.
+const (
+	testEscrow   = "0x00000000000000000000000000000000000000e5"
+	testEmployer = "0x00000000000000000000000000000000000000e1"
+	testWorker   = "0x00000000000000000000000000000000000000a1"
+)
+
+func TestInsertAccept_TermsSnapshotSurvivesTaskChange(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id = 'terms-task'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	task := &Task{
+		TaskID: "terms-task", TaskHash: "0xterms-task", ChainID: 1,
+		EscrowAddress: testEscrow, EmployerAddress: testEmployer,
+		AmountWei: "100", DeadlineUnix: 1000, Title: "original",
+		Status: TaskStatusCreated, IndexerFeeBPS: 20,
+	}
+	if err := repo.InsertTask(ctx, task, 0); err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+	if err := repo.InsertAccept(ctx, &Accept{AcceptID: "terms-accept", TaskID: "terms-task", WorkerAddress: testWorker}); err != nil {
+		t.Fatalf("InsertAccept: %v", err)
+	}
+
+	// Employer changes the task after acceptance.
+	if _, err := repo.pool.Exec(ctx,
+		`UPDATE tasks SET amount_wei='1', deadline_unix=1, title='changed', indexer_fee_bps=99 WHERE task_id='terms-task'`); err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+
+	accepts, err := repo.ListAccepts(ctx, "terms-task")
+	if err != nil {
+		t.Fatalf("ListAccepts: %v", err)
+	}
+	if len(accepts) != 1 || accepts[0].Terms == nil {
+		t.Fatalf("expected one accept with terms, got %+v", accepts)
+	}
+	got := *accepts[0].Terms
+	want := AcceptTerms{AmountWei: "100", DeadlineUnix: 1000, TitleHash: ethutil.Keccak256Hex([]byte("original")), IndexerFeeBPS: 20}
+	if got != want {
+		t.Errorf("terms = %+v, want %+v", got, want)
+	}
+}
+
+func TestInsertTask_EmployerSequence(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const employer = "0x5e9000000000000000000000000000000000e001"
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM employer_sequences WHERE employer_address = $1`, employer); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE employer_address = $1`, employer); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+
+	insert := func(id string, seq int64) error {
+		return repo.InsertTask(ctx, &Task{
+			TaskID: id, TaskHash: "0x" + id, ChainID: 1, EscrowAddress: testEscrow,
+			EmployerAddress: employer, AmountWei: "1", DeadlineUnix: 1,
+			Status: TaskStatusCreated, EmployerSequence: &seq,
+		}, 0)
+	}
+
+	if next, _ := repo.NextEmployerSequence(ctx, employer); next != 1 {
+		t.Fatalf("next before any task = %d, want 1", next)
+	}
+	if err := insert("seq-5", 5); err != nil {
+		t.Fatalf("insert seq 5: %v", err)
+	}
+	if err := insert("seq-5-dup", 5); !errors.Is(err, ErrSequenceConflict) {
+		t.Fatalf("duplicate sequence: expected ErrSequenceConflict, got %v", err)
+	}
+	if err := insert("seq-3", 3); !errors.Is(err, ErrSequenceConflict) {
+		t.Fatalf("out-of-order sequence: expected ErrSequenceConflict, got %v", err)
+	}
+	if err := insert("seq-7", 7); err != nil {
+		t.Fatalf("insert seq 7: %v", err)
+	}
+	if next, _ := repo.NextEmployerSequence(ctx, employer); next != 8 {
+		t.Errorf("next = %d, want 8", next)
+	}
+	if _, err := repo.GetTask(ctx, "seq-5-dup"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("rejected task must not be stored, got %v", err)
+	}
+}
+
+func TestFindTasksByTxHash_SharedHash(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const tx = "0x00000000000000000000000000000000000000000000000000000000000000bb"
+	for _, id := range []string{"txsearch-a", "txsearch-b"} {
+		if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id = $1`, id); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+		if err := repo.InsertTask(ctx, &Task{
+			TaskID: id, TaskHash: "0x" + id, ChainID: 1, EscrowAddress: testEscrow,
+			EmployerAddress: testEmployer, AmountWei: "1", DeadlineUnix: 1, Status: TaskStatusCreated,
+		}, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", id, err)
+		}
+	}
+
+	now := time.Now()
+	// Mixed-case hashes are stored lower-cased.
+	if err := repo.UpdateOnchainCreated(ctx, "txsearch-a", "0x"+strings.ToUpper(tx[2:]), nil, now); err != nil {
+		t.Fatalf("UpdateOnchainCreated: %v", err)
+	}
+	if err := repo.UpdateOnchainReleased(ctx, 1, "0xtxsearch-b", tx, now); err != nil {
+		t.Fatalf("UpdateOnchainReleased: %v", err)
+	}
+
+	matches, err := repo.FindTasksByTxHash(ctx, strings.ToUpper(tx))
+	if err != nil {
+		t.Fatalf("FindTasksByTxHash: %v", err)
+	}
+	got := map[string]string{}
+	for _, m := range matches {
+		got[m.Task.TaskID] = m.Event
+	}
+	want := map[string]string{"txsearch-a": TxEventCreated, "txsearch-b": TxEventReleased}
+	if len(matches) != 2 || got["txsearch-a"] != want["txsearch-a"] || got["txsearch-b"] != want["txsearch-b"] {
+		t.Errorf("matches = %v, want %v", got, want)
+	}
+}
+
+func TestListTasks_ExcludesPrivate(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'vis-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	const chainID = 424242
+	for _, task := range []*Task{
+		{TaskID: "vis-public", Visibility: TaskVisibilityPublic},
+		{TaskID: "vis-private", Visibility: TaskVisibilityPrivate, AllowedWorkers: []string{testWorker}},
+	} {
+		task.TaskHash, task.ChainID = "0x"+task.TaskID, chainID
+		task.EscrowAddress, task.EmployerAddress = testEscrow, testEmployer
+		task.AmountWei, task.DeadlineUnix, task.Status = "1", 1000, TaskStatusCreated
+		if err := repo.InsertTask(ctx, task, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", task.TaskID, err)
+		}
+	}
+
+	tasks, err := repo.ListTasks(ctx, TaskFilter{ChainID: chainID, Limit: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].TaskID != "vis-public" {
+		t.Errorf("listed %d tasks, want only vis-public", len(tasks))
+	}
+
+	got, err := repo.GetTask(ctx, "vis-private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Visibility != TaskVisibilityPrivate || len(got.AllowedWorkers) != 1 || got.AllowedWorkers[0] != testWorker {
+		t.Errorf("private task read back as %q %v", got.Visibility, got.AllowedWorkers)
+	}
+}
+
+func TestListTasks_OnchainFilter(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'sync-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	const chainID = 454545
+	for _, id := range []string{"sync-funded", "sync-abandoned"} {
+		if err := repo.InsertTask(ctx, &Task{
+			TaskID: id, TaskHash: "0x" + id, ChainID: chainID, EscrowAddress: testEscrow, EmployerAddress: testEmployer,
+			AmountWei: "1", DeadlineUnix: 1000, Status: TaskStatusCreated,
+		}, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", id, err)
+		}
+	}
+	if err := repo.UpdateOnchainCreated(ctx, "sync-funded", "0x01", nil, time.Now()); err != nil {
+		t.Fatalf("UpdateOnchainCreated: %v", err)
+	}
+
+	list := func(f TaskFilter) []string {
+		t.Helper()
+		f.ChainID, f.Limit = chainID, 50
+		tasks, err := repo.ListTasks(ctx, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.TaskID)
+		}
+		return ids
+	}
+	if got := list(TaskFilter{}); len(got) != 2 {
+		t.Errorf("no filter = %v, want both", got)
+	}
+	if got := list(TaskFilter{Onchain: OnchainSynced}); len(got) != 1 || got[0] != "sync-funded" {
+		t.Errorf("synced = %v, want [sync-funded]", got)
+	}
+	if got := list(TaskFilter{Onchain: OnchainUnsynced}); len(got) != 1 || got[0] != "sync-abandoned" {
+		t.Errorf("unsynced = %v, want [sync-abandoned]", got)
+	}
+	past := time.Now().Add(-time.Hour)
+	if got := list(TaskFilter{Onchain: OnchainUnsynced, CreatedBefore: &past}); len(got) != 0 {
+		t.Errorf("unsynced older than 1h = %v, want none", got)
+	}
+}
+
+func TestListTasksUpdatedSince_ReturnsOnlyChanged(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'delta-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	const chainID = 434343
+	for _, id := range []string{"delta-a", "delta-b"} {
+		if err := repo.InsertTask(ctx, &Task{
+			TaskID: id, TaskHash: "0x" + id, ChainID: chainID, EscrowAddress: testEscrow, EmployerAddress: testEmployer,
+			AmountWei: "1", DeadlineUnix: 1000, Status: TaskStatusCreated,
+		}, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", id, err)
+		}
+	}
+
+	// Initial sync, one task per page.
+	var synced []*Task
+	var cursor *Cursor
+	for {
+		page, next, err := repo.ListTasksUpdatedSince(ctx, time.Time{}, time.Time{}, chainID, "", 1, cursor)
+		if err != nil {
+			t.Fatalf("ListTasksUpdatedSince: %v", err)
+		}
+		synced = append(synced, page...)
+		if next == nil {
+			break
+		}
+		if next.Order != OrderUpdated {
+			t.Fatalf("cursor order = %q, want %q", next.Order, OrderUpdated)
+		}
+		cursor = next
+	}
+	if len(synced) != 2 {
+		t.Fatalf("initial sync returned %d tasks, want 2", len(synced))
+	}
+	since := synced[len(synced)-1].UpdatedAt
+
+	if err := repo.UpdateTaskWorker(ctx, "delta-a", testWorker, TaskStatusAccepted); err != nil {
+		t.Fatalf("UpdateTaskWorker: %v", err)
+	}
+
+	changed, next, err := repo.ListTasksUpdatedSince(ctx, since, time.Time{}, chainID, "", 50, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != nil || len(changed) != 1 || changed[0].TaskID != "delta-a" || changed[0].Status != TaskStatusAccepted {
+		t.Errorf("delta after update = %d tasks (next %v), want only delta-a", len(changed), next)
+	}
+}
+
+func TestTaskNotifications_ClaimOnce(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'due-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	now := time.Now()
+	for _, task := range []*Task{
+		{TaskID: "due-accepted", Status: TaskStatusAccepted, DeadlineUnix: now.Add(time.Hour).Unix()},
+		{TaskID: "due-created", Status: TaskStatusCreated, DeadlineUnix: now.Add(time.Hour).Unix()},
+		{TaskID: "due-later", Status: TaskStatusAccepted, DeadlineUnix: now.Add(48 * time.Hour).Unix()},
+	} {
+		task.TaskHash, task.ChainID, task.AmountWei = "0x"+task.TaskID, 1, "1"
+		task.EscrowAddress, task.EmployerAddress = testEscrow, testEmployer
+		if err := repo.InsertTask(ctx, task, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", task.TaskID, err)
+		}
+	}
+
+	due, err := repo.ListAcceptedTasksDueBetween(ctx, now, now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, d := range due {
+		if strings.HasPrefix(d.TaskID, "due-") {
+			ids = append(ids, d.TaskID)
+		}
+	}
+	if len(ids) != 1 || ids[0] != "due-accepted" {
+		t.Errorf("due = %v, want [due-accepted]", ids)
+	}
+
+	for i, want := range []bool{true, false} {
+		got, err := repo.ClaimTaskNotification(ctx, "due-accepted", "deadline_approaching", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("claim %d = %v, want %v", i, got, want)
+		}
+	}
+	if ok, _ := repo.ClaimTaskNotification(ctx, "due-accepted", "deadline_approaching", 24*time.Hour); !ok {
+		t.Error("claim for another window was refused")
+	}
+}
+
+func TestRecordFee_EitherOrder(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'fee-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	const chainID = 545454
+	released := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, id := range []string{"fee-a", "fee-b"} {
+		if err := repo.InsertTask(ctx, &Task{
+			TaskID: id, TaskHash: "0x" + id, ChainID: chainID, EscrowAddress: testEscrow, EmployerAddress: testEmployer,
+			AmountWei: "10000", DeadlineUnix: 1000, Status: TaskStatusCreated,
+		}, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", id, err)
+		}
+	}
+	record := func(id, event string) bool {
+		mismatch, err := repo.RecordFee(ctx, &FeeEntry{
+			TaskID: id, ChainID: chainID, ComputedFeeWei: "20", EventFeeWei: event, ReleasedAt: released, TxHash: "0xtx",
+		})
+		if err != nil {
+			t.Fatalf("RecordFee %s: %v", id, err)
+		}
+		return mismatch
+	}
+
+	// fee-a: Released then a matching FeePaid. fee-b: a mismatched FeePaid, then
+	// Released, which must not clear the event amount.
+	if record("fee-a", "") || record("fee-a", "20") {
+		t.Error("fee-a reported a mismatch")
+	}
+	if !record("fee-b", "19") || !record("fee-b", "") {
+		t.Error("fee-b mismatch not reported")
+	}
+
+	totals, err := repo.SumFees(ctx, FeeFilter{ChainID: chainID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 1 || totals[0].Tasks != 2 || totals[0].FeeWei != "39" || totals[0].Mismatches != 1 {
+		t.Errorf("totals = %+v", totals)
+	}
+	after := released.Add(time.Second)
+	if totals, err := repo.SumFees(ctx, FeeFilter{ChainID: chainID, From: &after}); err != nil || len(totals) != 0 {
+		t.Errorf("totals after release = %+v, %v", totals, err)
+	}
+}
+
+// TestAddresses_NormalizedByEveryMethod passes EIP-55 checksummed addresses
+// to every repo method that takes one and expects lowercase rows back.
+func TestAddresses_NormalizedByEveryMethod(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const (
+		escrow   = "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+		employer = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+		worker   = "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"
+	)
+	lower := strings.ToLower
+	for _, q := range []string{
+		`DELETE FROM tasks WHERE task_id = 'addr-task'`,
+		`DELETE FROM employer_sequences WHERE lower(employer_address) = lower('` + employer + `')`,
+		`DELETE FROM worker_tiers WHERE lower(worker_address) = lower('` + worker + `')`,
+	} {
+		if _, err := repo.pool.Exec(ctx, q); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+	}
+
+	seq := int64(3)
+	task := &Task{
+		TaskID: "addr-task", TaskHash: "0xaddr-task", ChainID: 1, EscrowAddress: escrow, EmployerAddress: employer,
+		AmountWei: "1", DeadlineUnix: 1000, Status: TaskStatusCreated, EmployerSequence: &seq,
+		Visibility: TaskVisibilityPrivate, AllowedWorkers: []string{worker},
+	}
+	if err := repo.InsertTask(ctx, task, 0); err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+	if task.EscrowAddress != lower(escrow) || task.EmployerAddress != lower(employer) || task.AllowedWorkers[0] != lower(worker) {
+		t.Errorf("InsertTask did not normalize in place: %+v", task)
+	}
+	if next, err := repo.NextEmployerSequence(ctx, employer); err != nil || next != 4 {
+		t.Errorf("NextEmployerSequence(checksummed) = %d, %v; want 4", next, err)
+	}
+
+	accept := &Accept{AcceptID: "addr-accept", TaskID: "addr-task", WorkerAddress: worker}
+	if err := repo.InsertAccept(ctx, accept); err != nil {
+		t.Fatalf("InsertAccept: %v", err)
+	}
+	if got, err := repo.GetAccept(ctx, "addr-task", "addr-accept"); err != nil || got.WorkerAddress != lower(worker) {
+		t.Errorf("stored accept worker = %v, %v", got, err)
+	}
+	if err := repo.UpdateTaskWorker(ctx, "addr-task", worker, TaskStatusAccepted); err != nil {
+		t.Fatalf("UpdateTaskWorker: %v", err)
+	}
+	if err := repo.UpdateOnchainWorkerSet(ctx, 1, "0xaddr-task", worker, "0xtx"); err != nil {
+		t.Fatalf("UpdateOnchainWorkerSet: %v", err)
+	}
+	got, err := repo.GetTask(ctx, "addr-task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.EscrowAddress != lower(escrow) || got.EmployerAddress != lower(employer) ||
+		got.WorkerAddress != lower(worker) || got.AllowedWorkers[0] != lower(worker) {
+		t.Errorf("stored task addresses = %s %s %s %v", got.EscrowAddress, got.EmployerAddress, got.WorkerAddress, got.AllowedWorkers)
+	}
+
+	if err := repo.SetWorkerTier(ctx, &WorkerTier{WorkerAddress: worker, Tier: 2}); err != nil {
+		t.Fatalf("SetWorkerTier: %v", err)
+	}
+	if tier, err := repo.GetWorkerTier(ctx, worker); err != nil || tier.WorkerAddress != lower(worker) || tier.Tier != 2 {
+		t.Errorf("GetWorkerTier(checksummed) = %+v, %v", tier, err)
+	}
+
+	// Malformed addresses are rejected before any write.
+	var ae *AddressError
+	for name, err := range map[string]error{
+		"InsertTask":             repo.InsertTask(ctx, &Task{TaskID: "addr-bad", EscrowAddress: "0x0", EmployerAddress: employer}, 0),
+		"InsertAccept":           repo.InsertAccept(ctx, &Accept{AcceptID: "addr-bad", TaskID: "addr-task", WorkerAddress: "0xw"}),
+		"UpdateTaskWorker":       repo.UpdateTaskWorker(ctx, "addr-task", "worker", TaskStatusAccepted),
+		"UpdateOnchainWorkerSet": repo.UpdateOnchainWorkerSet(ctx, 1, "0xaddr-task", "", "0xtx"),
+		"SetWorkerTier":          repo.SetWorkerTier(ctx, &WorkerTier{WorkerAddress: "0x1234"}),
+	} {
+		if !errors.As(err, &ae) {
+			t.Errorf("%s with a malformed address: err = %v, want *AddressError", name, err)
+		}
+	}
+	if _, err := repo.NextEmployerSequence(ctx, "nope"); !errors.As(err, &ae) {
+		t.Errorf("NextEmployerSequence(nope): err = %v", err)
+	}
+	if _, err := repo.GetWorkerTier(ctx, "nope"); !errors.As(err, &ae) {
+		t.Errorf("GetWorkerTier(nope): err = %v", err)
+	}
+	if _, err := repo.CountOpenTasks(ctx, "nope"); !errors.As(err, &ae) {
+		t.Errorf("CountOpenTasks(nope): err = %v", err)
+	}
+}
+
+func TestRecordUnknownLog_Idempotent(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const chainID = 464646
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM unknown_logs WHERE chain_id = $1`, chainID); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	l := &UnknownLog{
+		ChainID: chainID, Address: testEscrow, TxHash: "0xAB", LogIndex: 2, BlockNumber: 90,
+		Topics: []string{"0xFEED", "0xaa"}, Data: "0x",
+	}
+	for i := 0; i < 2; i++ {
+		if err := repo.RecordUnknownLog(ctx, l); err != nil {
+			t.Fatalf("RecordUnknownLog #%d: %v", i+1, err)
+		}
+	}
+	var n int
+	var topic0 string
+	err := repo.pool.QueryRow(ctx, `SELECT count(*), min(topic0) FROM unknown_logs WHERE chain_id = $1`, chainID).Scan(&n, &topic0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || topic0 != "0xfeed" {
+		t.Errorf("stored %d rows, topic0 %q; want 1, 0xfeed", n, topic0)
+	}
+}
+
+func TestInsertTask_OpenTaskLimit(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const employer = "0x0be0000000000000000000000000000000000e01"
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE employer_address = $1`, employer); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+
+	// Concurrent creates against a cap of 3: exactly 3 get in.
+	const limit = 3
+	errs := make(chan error, 8)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("open-cap-%d", i)
+			errs <- repo.InsertTask(ctx, &Task{
+				TaskID: id, TaskHash: "0x" + id, ChainID: 1, EscrowAddress: testEscrow,
+				EmployerAddress: employer, AmountWei: "1", DeadlineUnix: 1,
+				Status: TaskStatusCreated,
+			}, limit)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	var ok, limited int
+	for err := range errs {
+		var le *OpenTaskLimitError
+		switch {
+		case err == nil:
+			ok++
+		case errors.As(err, &le):
+			limited++
+		default:
+			t.Fatalf("InsertTask: %v", err)
+		}
+	}
+	if ok != limit || limited != 8-limit {
+		t.Fatalf("%d inserted, %d limited; want %d and %d", ok, limited, limit, 8-limit)
+	}
+	if open, err := repo.CountOpenTasks(ctx, employer); err != nil || open != limit {
+		t.Fatalf("CountOpenTasks = %d, %v; want %d", open, err, limit)
+	}
+
+	// A released task frees its slot.
+	if _, err := repo.pool.Exec(ctx, `UPDATE tasks SET status = $1 WHERE task_id = (
+		SELECT task_id FROM tasks WHERE employer_address = $2 LIMIT 1)`, TaskStatusReleased, employer); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InsertTask(ctx, &Task{
+		TaskID: "open-cap-after", TaskHash: "0xopen-cap-after", ChainID: 1, EscrowAddress: testEscrow,
+		EmployerAddress: employer, AmountWei: "1", DeadlineUnix: 1, Status: TaskStatusCreated,
+	}, limit); err != nil {
+		t.Errorf("insert after release: %v", err)
+	}
+}
+
+func TestInsertAccept_IfUpdatedAt(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM accepts WHERE task_id = 'ifmatch-task'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id = 'ifmatch-task'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if err := repo.InsertTask(ctx, &Task{
+		TaskID: "ifmatch-task", TaskHash: "0xifmatch-task", ChainID: 1,
+		EscrowAddress: testEscrow, EmployerAddress: testEmployer,
+		AmountWei: "100", DeadlineUnix: 1000, Status: TaskStatusCreated,
+	}, 0); err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+	seen, err := repo.GetTask(ctx, "ifmatch-task")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The employer moves the deadline after the worker read the task.
+	if _, err := repo.pool.Exec(ctx,
+		`UPDATE tasks SET deadline_unix = 1, updated_at = updated_at + interval '1 second' WHERE task_id = 'ifmatch-task'`); err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+	stale := seen.UpdatedAt
+	if err := repo.InsertAccept(ctx, &Accept{AcceptID: "ifmatch-stale", TaskID: "ifmatch-task", WorkerAddress: testWorker, IfUpdatedAt: &stale}); !errors.Is(err, ErrTaskChanged) {
+		t.Fatalf("stale precondition: err = %v, want ErrTaskChanged", err)
+	}
+	if _, err := repo.GetAccept(ctx, "ifmatch-task", "ifmatch-stale"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("stale accept was stored: %v", err)
+	}
+
+	current, err := repo.GetTask(ctx, "ifmatch-task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := current.UpdatedAt
+	if err := repo.InsertAccept(ctx, &Accept{AcceptID: "ifmatch-fresh", TaskID: "ifmatch-task", WorkerAddress: testWorker, IfUpdatedAt: &fresh}); err != nil {
+		t.Errorf("current precondition: %v", err)
+	}
+}
+
+func TestInsertTasks_Batch(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'batch-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	batch := func(prefix string) []*Task {
+		var tasks []*Task
+		for _, id := range []string{prefix + "-1", prefix + "-2", prefix + "-1"} {
+			tasks = append(tasks, &Task{
+				TaskID: id, TaskHash: "0x" + id, ChainID: 1, EscrowAddress: testEscrow,
+				EmployerAddress: testEmployer, AmountWei: "1", DeadlineUnix: 1, Status: TaskStatusCreated,
+			})
+		}
+		return tasks
+	}
+	exists := func(id string) bool {
+		_, err := repo.GetTask(ctx, id)
+		return err == nil
+	}
+
+	// Per item: the duplicate fails under its savepoint, the rest commit.
+	errs, err := repo.InsertTasks(ctx, batch("batch-each"), 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs[0] != nil || errs[1] != nil || !errors.Is(errs[2], ErrConflict) {
+		t.Fatalf("errs = %v", errs)
+	}
+	if !exists("batch-each-1") || !exists("batch-each-2") {
+		t.Error("per-item batch did not store its valid tasks")
+	}
+
+	// All or nothing: the duplicate rolls back the whole batch.
+	errs, err = repo.InsertTasks(ctx, batch("batch-all"), 0, true)
+	if !errors.Is(err, ErrBatchAborted) || !errors.Is(errs[2], ErrConflict) {
+		t.Fatalf("err = %v, errs = %v", err, errs)
+	}
+	if exists("batch-all-1") || exists("batch-all-2") {
+		t.Error("aborted batch stored tasks")
+	}
+}
+
+func TestOnchainUpdates_ScopedByChain(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id = 'scoped-task'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	const chainA, chainB = 1, 2
+	if err := repo.InsertTask(ctx, &Task{
+		TaskID: "scoped-task", TaskHash: "0xscoped-task", ChainID: chainA, EscrowAddress: testEscrow,
+		EmployerAddress: testEmployer, AmountWei: "1", DeadlineUnix: 1, Status: TaskStatusCreated,
+	}, 0); err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+
+	// The same hash seen on another chain resolves to nothing and changes nothing.
+	if _, err := repo.GetTaskByHash(ctx, chainB, "0xscoped-task"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTaskByHash(chain B): err = %v, want ErrNotFound", err)
+	}
+	if err := repo.UpdateOnchainWorkerSet(ctx, chainB, "0xscoped-task", testWorker, "0xb1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateOnchainReleased(ctx, chainB, "0xscoped-task", "0xb2", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateOnchainRefunded(ctx, chainB, "0xscoped-task", "0xb3", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetTaskByHash(ctx, chainA, "0xscoped-task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != TaskStatusCreated || got.WorkerAddress != "" || got.OnchainTxHash != "" {
+		t.Fatalf("chain B events changed the chain A task: %+v", got)
+	}
+
+	if err := repo.UpdateOnchainReleased(ctx, chainA, "0xscoped-task", "0xa1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetTaskByHash(ctx, chainA, "0xscoped-task"); got == nil || got.Status != TaskStatusReleased {
+		t.Errorf("chain A release not applied: %+v", got)
+	}
+}
+
+func TestSumTVL(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	const chainA, chainB = 616161, 626262
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE chain_id = ANY($1)`, []int{chainA, chainB}); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	const token = "0x00000000000000000000000000000000000000aa"
+	for _, task := range []struct {
+		id, token, amount string
+		chainID           int
+		onchain, released bool
+	}{
+		{"tvl-a1", "", "100000000000000000000000000000", chainA, true, false},
+		{"tvl-a2", "", "100000000000000000000000000000", chainA, true, false},
+		{"tvl-a-token", token, "7", chainA, true, false},
+		{"tvl-a-released", "", "1000", chainA, true, true},
+		{"tvl-a-unsynced", "", "1000", chainA, false, false},
+		{"tvl-b1", "", "42", chainB, true, false},
+	} {
+		if err := repo.InsertTask(ctx, &Task{
+			TaskID: task.id, TaskHash: "0x" + task.id, ChainID: task.chainID, EscrowAddress: testEscrow,
+			EmployerAddress: testEmployer, TokenAddress: task.token, AmountWei: task.amount, DeadlineUnix: 1000,
+			Status: TaskStatusCreated,
+		}, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", task.id, err)
+		}
+		if task.onchain {
+			if err := repo.UpdateOnchainCreated(ctx, task.id, "0x01", nil, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if task.released {
+			if err := repo.UpdateOnchainReleased(ctx, task.chainID, "0x"+task.id, "0x02", time.Now()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	totals, err := repo.SumTVL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []TVLTotal
+	for _, tot := range totals {
+		if tot.ChainID == chainA || tot.ChainID == chainB {
+			got = append(got, tot)
+		}
+	}
+	want := []TVLTotal{
+		{ChainID: chainA, Tasks: 2, AmountWei: "200000000000000000000000000000"},
+		{ChainID: chainA, TokenAddress: token, Tasks: 1, AmountWei: "7"},
+		{ChainID: chainB, Tasks: 1, AmountWei: "42"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("totals = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("totals[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTaskRawRequest_SurvivesTaskUpdates(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'raw-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	raw := []byte(`{"amount_wei":"1","task_id":"raw-1"}`)
+	for id, req := range map[string][]byte{"raw-1": raw, "raw-legacy": nil} {
+		if err := repo.InsertTask(ctx, &Task{
+			TaskID: id, TaskHash: "0x" + id, ChainID: 1, EscrowAddress: testEscrow,
+			EmployerAddress: testEmployer, AmountWei: "1", DeadlineUnix: 1, Status: TaskStatusCreated,
+			RawRequest: req,
+		}, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", id, err)
+		}
+	}
+	if err := repo.UpdateTaskWorker(ctx, "raw-1", testWorker, TaskStatusAccepted); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateOnchainCreated(ctx, "raw-1", "0x01", nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetTaskRawRequest(ctx, "raw-1")
+	if err != nil || string(got) != string(raw) {
+		t.Fatalf("GetTaskRawRequest = %q, %v; want %q", got, err, raw)
+	}
+	task, err := repo.GetTask(ctx, "raw-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256(raw); task.RawRequestSHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("RawRequestSHA256 = %q", task.RawRequestSHA256)
+	}
+
+	if got, err := repo.GetTaskRawRequest(ctx, "raw-legacy"); err != nil || got != nil {
+		t.Errorf("legacy task: %q, %v; want nil", got, err)
+	}
+	if legacy, _ := repo.GetTask(ctx, "raw-legacy"); legacy == nil || legacy.RawRequestSHA256 != "" {
+		t.Errorf("legacy task RawRequestSHA256 = %+v", legacy)
+	}
+	if _, err := repo.GetTaskRawRequest(ctx, "raw-unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown task: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateOnchainWorkerSet_Mismatch(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'ws-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	const other = "0x00000000000000000000000000000000000000b2"
+	for _, id := range []string{"ws-match", "ws-mismatch", "ws-no-accept"} {
+		if err := repo.InsertTask(ctx, &Task{
+			TaskID: id, TaskHash: "0x" + id, ChainID: 1, EscrowAddress: testEscrow,
+			EmployerAddress: testEmployer, AmountWei: "1", DeadlineUnix: 1, Status: TaskStatusCreated,
+		}, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", id, err)
+		}
+	}
+	for _, id := range []string{"ws-match", "ws-mismatch"} {
+		if err := repo.UpdateTaskWorker(ctx, id, testWorker, TaskStatusAccepted); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id, worker := range map[string]string{"ws-match": testWorker, "ws-mismatch": other, "ws-no-accept": other} {
+		if err := repo.UpdateOnchainWorkerSet(ctx, 1, "0x"+id, worker, "0x"+id); err != nil {
+			t.Fatalf("UpdateOnchainWorkerSet %s: %v", id, err)
+		}
+	}
+	for id, want := range map[string]Task{
+		"ws-match":     {WorkerAddress: testWorker, OnchainWorkerAddress: testWorker, Status: TaskStatusAcceptedOnchain},
+		"ws-mismatch":  {WorkerAddress: testWorker, OnchainWorkerAddress: other, Status: TaskStatusAccepted, WorkerMismatch: true},
+		"ws-no-accept": {WorkerAddress: other, OnchainWorkerAddress: other, Status: TaskStatusAcceptedOnchain},
+	} {
+		got, err := repo.GetTask(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.WorkerAddress != want.WorkerAddress || got.OnchainWorkerAddress != want.OnchainWorkerAddress ||
+			got.Status != want.Status || got.WorkerMismatch != want.WorkerMismatch {
+			t.Errorf("%s: worker %s onchain %s status %s mismatch %v; want %+v",
+				id, got.WorkerAddress, got.OnchainWorkerAddress, got.Status, got.WorkerMismatch, want)
+		}
+	}
+
+	// A later WorkerSet naming the accepter resolves the mismatch.
+	if err := repo.UpdateOnchainWorkerSet(ctx, 1, "0xws-mismatch", testWorker, "0xws-fix"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetTask(ctx, "ws-mismatch"); got == nil || got.WorkerMismatch || got.Status != TaskStatusAcceptedOnchain {
+		t.Errorf("after matching WorkerSet: %+v", got)
+	}
+}
+
+func TestListTaskStatusEvents(t *testing.T) {
+	repo := testPool(t)
+	ctx := context.Background()
+
+	if _, err := repo.pool.Exec(ctx, `DELETE FROM tasks WHERE task_id LIKE 'sev-%'`); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	for _, task := range []*Task{
+		{TaskID: "sev-public", Visibility: TaskVisibilityPublic},
+		{TaskID: "sev-private", Visibility: TaskVisibilityPrivate, AllowedWorkers: []string{testWorker}},
+	} {
+		task.TaskHash, task.ChainID = "0x"+task.TaskID, 1
+		task.EscrowAddress, task.EmployerAddress = testEscrow, testEmployer
+		task.AmountWei, task.DeadlineUnix, task.Status, task.Title = "1000", 1000, TaskStatusCreated, "t"
+		if err := repo.InsertTask(ctx, task, 0); err != nil {
+			t.Fatalf("InsertTask %s: %v", task.TaskID, err)
+		}
+		if err := repo.UpdateTaskWorker(ctx, task.TaskID, testWorker, TaskStatusAccepted); err != nil {
+			t.Fatalf("UpdateTaskWorker %s: %v", task.TaskID, err)
+		}
+	}
+	// Rewriting the same status is not a transition.
+	if err := repo.UpdateTaskWorker(ctx, "sev-public", testWorker, TaskStatusAccepted); err != nil {
+		t.Fatal(err)
+	}
+
+	events, _, err := repo.ListTaskStatusEvents(ctx, 200, nil)
+	if err != nil {
+		t.Fatalf("ListTaskStatusEvents: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		if strings.HasPrefix(e.TaskID, "sev-") {
+			got = append(got, e.TaskID+":"+e.OldStatus+">"+e.NewStatus)
+			if e.AmountWei != "1000" || e.EmployerAddress != testEmployer || e.TaskHash != "0x"+e.TaskID {
+				t.Errorf("event row %+v does not carry the task fields", e)
+			}
+		}
+	}
+	want := []string{"sev-public:created>accepted", "sev-public:>created"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v (newest first, private task excluded)", got, want)
+	}
+
+	page, next, err := repo.ListTaskStatusEvents(ctx, 1, nil)
+	if err != nil || len(page) != 1 || next == nil {
+		t.Fatalf("first page = %d events, next %v, err %v", len(page), next, err)
+	}
+	rest, _, err := repo.ListTaskStatusEvents(ctx, 1, next)
+	if err != nil || len(rest) != 1 || rest[0].ID == page[0].ID {
+		t.Errorf("second page = %+v, %v", rest, err)
+	}
+}
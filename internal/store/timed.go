@@ -0,0 +1,276 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/core/envelope"
+	"github.com/AgentMesh-Net/indexer-go/internal/timing"
+)
+
+// TimedRepo wraps a Repo and records the duration of every call in the
+// request's timing.Collector, for slow-request logs. Without a Collector in
+// the context a call costs one context lookup.
+type TimedRepo struct {
+	Repo
+}
+
+// NewTimedRepo wraps r.
+func NewTimedRepo(r Repo) *TimedRepo {
+	return &TimedRepo{Repo: r}
+}
+
+// TimedTaskRepo is TimedRepo for a TaskRepo.
+type TimedTaskRepo struct {
+	TaskRepo
+}
+
+// NewTimedTaskRepo wraps r.
+func NewTimedTaskRepo(r TaskRepo) *TimedTaskRepo {
+	return &TimedTaskRepo{TaskRepo: r}
+}
+
+func (r *TimedRepo) InsertObject(ctx context.Context, env *envelope.Envelope) error {
+	defer timing.Start(ctx, "InsertObject")()
+	return r.Repo.InsertObject(ctx, env)
+}
+
+func (r *TimedRepo) ListObjects(ctx context.Context, objectType string, limit int, cursor *Cursor) (items []Object, next *Cursor, err error) {
+	defer timing.Start(ctx, "ListObjects")()
+	return r.Repo.ListObjects(ctx, objectType, limit, cursor)
+}
+
+func (r *TimedRepo) ListObjectsBySigner(ctx context.Context, signerPubKey, objectType string, limit int, cursor *Cursor) (items []Object, next *Cursor, err error) {
+	defer timing.Start(ctx, "ListObjectsBySigner")()
+	return r.Repo.ListObjectsBySigner(ctx, signerPubKey, objectType, limit, cursor)
+}
+
+func (r *TimedRepo) QueryObjects(ctx context.Context, f ObjectFilter) (items []Object, next *Cursor, err error) {
+	defer timing.Start(ctx, "QueryObjects")()
+	return r.Repo.QueryObjects(ctx, f)
+}
+
+func (r *TimedRepo) ListObjectsForTask(ctx context.Context, taskID, linkedObjectID string, limit int) ([]envelope.Envelope, error) {
+	defer timing.Start(ctx, "ListObjectsForTask")()
+	return r.Repo.ListObjectsForTask(ctx, taskID, linkedObjectID, limit)
+}
+
+func (r *TimedRepo) GetObjectByID(ctx context.Context, id string) (*Object, error) {
+	defer timing.Start(ctx, "GetObjectByID")()
+	return r.Repo.GetObjectByID(ctx, id)
+}
+
+func (r *TimedRepo) DeleteObject(ctx context.Context, id string, tombstone bool) error {
+	defer timing.Start(ctx, "DeleteObject")()
+	return r.Repo.DeleteObject(ctx, id, tombstone)
+}
+
+func (r *TimedRepo) CountObjectsByType(ctx context.Context) (map[string]int64, error) {
+	defer timing.Start(ctx, "CountObjectsByType")()
+	return r.Repo.CountObjectsByType(ctx)
+}
+
+func (r *TimedRepo) GetObjectNotes(ctx context.Context, objectID string) (*ObjectNotes, error) {
+	defer timing.Start(ctx, "GetObjectNotes")()
+	return r.Repo.GetObjectNotes(ctx, objectID)
+}
+
+func (r *TimedRepo) SetObjectNotes(ctx context.Context, objectID, notes string) (*ObjectNotes, error) {
+	defer timing.Start(ctx, "SetObjectNotes")()
+	return r.Repo.SetObjectNotes(ctx, objectID, notes)
+}
+
+func (r *TimedTaskRepo) InsertTask(ctx context.Context, t *Task, maxOpen int) error {
+	defer timing.Start(ctx, "InsertTask")()
+	return r.TaskRepo.InsertTask(ctx, t, maxOpen)
+}
+
+func (r *TimedTaskRepo) InsertTasks(ctx context.Context, tasks []*Task, maxOpen int, allOrNothing bool) ([]error, error) {
+	defer timing.Start(ctx, "InsertTasks")()
+	return r.TaskRepo.InsertTasks(ctx, tasks, maxOpen, allOrNothing)
+}
+
+func (r *TimedTaskRepo) CountOpenTasks(ctx context.Context, employerAddress string) (int64, error) {
+	defer timing.Start(ctx, "CountOpenTasks")()
+	return r.TaskRepo.CountOpenTasks(ctx, employerAddress)
+}
+
+func (r *TimedTaskRepo) GetTask(ctx context.Context, taskID string) (*Task, error) {
+	defer timing.Start(ctx, "GetTask")()
+	return r.TaskRepo.GetTask(ctx, taskID)
+}
+
+func (r *TimedTaskRepo) GetTaskRawRequest(ctx context.Context, taskID string) ([]byte, error) {
+	defer timing.Start(ctx, "GetTaskRawRequest")()
+	return r.TaskRepo.GetTaskRawRequest(ctx, taskID)
+}
+
+func (r *TimedTaskRepo) NextEmployerSequence(ctx context.Context, employerAddress string) (int64, error) {
+	defer timing.Start(ctx, "NextEmployerSequence")()
+	return r.TaskRepo.NextEmployerSequence(ctx, employerAddress)
+}
+
+func (r *TimedTaskRepo) GetTaskByHash(ctx context.Context, chainID int, taskHash string) (*Task, error) {
+	defer timing.Start(ctx, "GetTaskByHash")()
+	return r.TaskRepo.GetTaskByHash(ctx, chainID, taskHash)
+}
+
+func (r *TimedTaskRepo) FindTasksByTxHash(ctx context.Context, txHash string) ([]*TxTaskMatch, error) {
+	defer timing.Start(ctx, "FindTasksByTxHash")()
+	return r.TaskRepo.FindTasksByTxHash(ctx, txHash)
+}
+
+func (r *TimedTaskRepo) ListTasks(ctx context.Context, f TaskFilter) ([]*Task, error) {
+	defer timing.Start(ctx, "ListTasks")()
+	return r.TaskRepo.ListTasks(ctx, f)
+}
+
+func (r *TimedTaskRepo) ListTasksUpdatedSince(ctx context.Context, since, until time.Time, chainID int, status string, limit int, cursor *Cursor) ([]*Task, *Cursor, error) {
+	defer timing.Start(ctx, "ListTasksUpdatedSince")()
+	return r.TaskRepo.ListTasksUpdatedSince(ctx, since, until, chainID, status, limit, cursor)
+}
+
+func (r *TimedTaskRepo) InsertAccept(ctx context.Context, a *Accept) error {
+	defer timing.Start(ctx, "InsertAccept")()
+	return r.TaskRepo.InsertAccept(ctx, a)
+}
+
+func (r *TimedTaskRepo) GetAccept(ctx context.Context, taskID, acceptID string) (*Accept, error) {
+	defer timing.Start(ctx, "GetAccept")()
+	return r.TaskRepo.GetAccept(ctx, taskID, acceptID)
+}
+
+func (r *TimedTaskRepo) ListAccepts(ctx context.Context, taskID string) ([]*Accept, error) {
+	defer timing.Start(ctx, "ListAccepts")()
+	return r.TaskRepo.ListAccepts(ctx, taskID)
+}
+
+func (r *TimedTaskRepo) UpdateTaskWorker(ctx context.Context, taskID, workerAddress, status string) error {
+	defer timing.Start(ctx, "UpdateTaskWorker")()
+	return r.TaskRepo.UpdateTaskWorker(ctx, taskID, workerAddress, status)
+}
+
+func (r *TimedTaskRepo) DeleteTask(ctx context.Context, taskID string, soft bool) error {
+	defer timing.Start(ctx, "DeleteTask")()
+	return r.TaskRepo.DeleteTask(ctx, taskID, soft)
+}
+
+func (r *TimedTaskRepo) ListOpenTaskChains(ctx context.Context) ([]int, error) {
+	defer timing.Start(ctx, "ListOpenTaskChains")()
+	return r.TaskRepo.ListOpenTaskChains(ctx)
+}
+
+func (r *TimedTaskRepo) SetChainRetired(ctx context.Context, chainID int, retired bool) (int64, error) {
+	defer timing.Start(ctx, "SetChainRetired")()
+	return r.TaskRepo.SetChainRetired(ctx, chainID, retired)
+}
+
+func (r *TimedTaskRepo) CountTasksByStatus(ctx context.Context) (map[string]int64, error) {
+	defer timing.Start(ctx, "CountTasksByStatus")()
+	return r.TaskRepo.CountTasksByStatus(ctx)
+}
+
+func (r *TimedTaskRepo) ListUnfundedAccepts(ctx context.Context, acceptedBefore time.Time, limit int) ([]UnfundedAccept, error) {
+	defer timing.Start(ctx, "ListUnfundedAccepts")()
+	return r.TaskRepo.ListUnfundedAccepts(ctx, acceptedBefore, limit)
+}
+
+func (r *TimedTaskRepo) CountUnfundedAcceptsByEmployer(ctx context.Context, acceptedBefore time.Time) (map[string]int64, error) {
+	defer timing.Start(ctx, "CountUnfundedAcceptsByEmployer")()
+	return r.TaskRepo.CountUnfundedAcceptsByEmployer(ctx, acceptedBefore)
+}
+
+func (r *TimedTaskRepo) RevertUnfundedAccepts(ctx context.Context, acceptedBefore time.Time) ([]UnfundedAccept, error) {
+	defer timing.Start(ctx, "RevertUnfundedAccepts")()
+	return r.TaskRepo.RevertUnfundedAccepts(ctx, acceptedBefore)
+}
+
+func (r *TimedTaskRepo) ListAcceptedTasksDueBetween(ctx context.Context, from, to time.Time) ([]*Task, error) {
+	defer timing.Start(ctx, "ListAcceptedTasksDueBetween")()
+	return r.TaskRepo.ListAcceptedTasksDueBetween(ctx, from, to)
+}
+
+func (r *TimedTaskRepo) ClaimTaskNotification(ctx context.Context, taskID, kind string, window time.Duration) (bool, error) {
+	defer timing.Start(ctx, "ClaimTaskNotification")()
+	return r.TaskRepo.ClaimTaskNotification(ctx, taskID, kind, window)
+}
+
+func (r *TimedTaskRepo) GetWorkerTier(ctx context.Context, workerAddress string) (*WorkerTier, error) {
+	defer timing.Start(ctx, "GetWorkerTier")()
+	return r.TaskRepo.GetWorkerTier(ctx, workerAddress)
+}
+
+func (r *TimedTaskRepo) SetWorkerTier(ctx context.Context, t *WorkerTier) error {
+	defer timing.Start(ctx, "SetWorkerTier")()
+	return r.TaskRepo.SetWorkerTier(ctx, t)
+}
+
+func (r *TimedTaskRepo) RecordFee(ctx context.Context, e *FeeEntry) (mismatch bool, err error) {
+	defer timing.Start(ctx, "RecordFee")()
+	return r.TaskRepo.RecordFee(ctx, e)
+}
+
+func (r *TimedTaskRepo) SumFees(ctx context.Context, f FeeFilter) ([]FeeTotal, error) {
+	defer timing.Start(ctx, "SumFees")()
+	return r.TaskRepo.SumFees(ctx, f)
+}
+
+func (r *TimedTaskRepo) ListFees(ctx context.Context, f FeeFilter) ([]*FeeEntry, error) {
+	defer timing.Start(ctx, "ListFees")()
+	return r.TaskRepo.ListFees(ctx, f)
+}
+
+func (r *TimedTaskRepo) SumTVL(ctx context.Context) ([]TVLTotal, error) {
+	defer timing.Start(ctx, "SumTVL")()
+	return r.TaskRepo.SumTVL(ctx)
+}
+
+func (r *TimedTaskRepo) HasArtifact(ctx context.Context, taskID string) (bool, error) {
+	defer timing.Start(ctx, "HasArtifact")()
+	return r.TaskRepo.HasArtifact(ctx, taskID)
+}
+
+func (r *TimedTaskRepo) RecordUnknownLog(ctx context.Context, l *UnknownLog) error {
+	defer timing.Start(ctx, "RecordUnknownLog")()
+	return r.TaskRepo.RecordUnknownLog(ctx, l)
+}
+
+func (r *TimedTaskRepo) InsertAuditEvent(ctx context.Context, e *AuditEvent) error {
+	defer timing.Start(ctx, "InsertAuditEvent")()
+	return r.TaskRepo.InsertAuditEvent(ctx, e)
+}
+
+func (r *TimedTaskRepo) ListAuditEvents(ctx context.Context, f AuditFilter, limit int, cursor *Cursor) ([]*AuditEvent, *Cursor, error) {
+	defer timing.Start(ctx, "ListAuditEvents")()
+	return r.TaskRepo.ListAuditEvents(ctx, f, limit, cursor)
+}
+
+func (r *TimedTaskRepo) ListTaskStatusEvents(ctx context.Context, limit int, cursor *Cursor) ([]*TaskStatusEvent, *Cursor, error) {
+	defer timing.Start(ctx, "ListTaskStatusEvents")()
+	return r.TaskRepo.ListTaskStatusEvents(ctx, limit, cursor)
+}
+
+func (r *TimedTaskRepo) AckAuditEvent(ctx context.Context, id int64, ack bool, by string) (*AuditEvent, error) {
+	defer timing.Start(ctx, "AckAuditEvent")()
+	return r.TaskRepo.AckAuditEvent(ctx, id, ack, by)
+}
+
+func (r *TimedTaskRepo) UpdateOnchainCreated(ctx context.Context, taskID, txHash string, terms *OnchainTerms, at time.Time) error {
+	defer timing.Start(ctx, "UpdateOnchainCreated")()
+	return r.TaskRepo.UpdateOnchainCreated(ctx, taskID, txHash, terms, at)
+}
+
+func (r *TimedTaskRepo) UpdateOnchainWorkerSet(ctx context.Context, chainID int, taskHash, workerAddress, txHash string) error {
+	defer timing.Start(ctx, "UpdateOnchainWorkerSet")()
+	return r.TaskRepo.UpdateOnchainWorkerSet(ctx, chainID, taskHash, workerAddress, txHash)
+}
+
+func (r *TimedTaskRepo) UpdateOnchainReleased(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error {
+	defer timing.Start(ctx, "UpdateOnchainReleased")()
+	return r.TaskRepo.UpdateOnchainReleased(ctx, chainID, taskHash, txHash, at)
+}
+
+func (r *TimedTaskRepo) UpdateOnchainRefunded(ctx context.Context, chainID int, taskHash, txHash string, at time.Time) error {
+	defer timing.Start(ctx, "UpdateOnchainRefunded")()
+	return r.TaskRepo.UpdateOnchainRefunded(ctx, chainID, taskHash, txHash, at)
+}
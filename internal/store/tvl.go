@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// TVLTotal is the value escrowed onchain for one chain and token: tasks seen
+// created onchain and not yet released, refunded or cancelled.
+type TVLTotal struct {
+	ChainID int
+	// TokenAddress is empty for the chain's native currency.
+	TokenAddress string
+	Tasks        int64
+	// AmountWei is the decimal sum of amount_wei, summed as numeric so it
+	// cannot overflow.
+	AmountWei string
+}
+
+// SumTVL returns escrowed totals ordered by chain_id and token_address.
+func (r *PostgresTaskRepo) SumTVL(ctx context.Context) ([]TVLTotal, error) {
+	const q = `
+SELECT chain_id, COALESCE(token_address, ''), count(*), sum(amount_wei::numeric)::text
+FROM tasks
+WHERE onchain_created_at IS NOT NULL AND status <> ALL($1) AND deleted_at IS NULL
+GROUP BY 1, 2
+ORDER BY 1, 2`
+	closed := []string{TaskStatusReleased, TaskStatusRefunded, TaskStatusCancelled}
+	rows, err := r.reader(ctx).Query(ctx, q, closed)
+	if err != nil {
+		return nil, fmt.Errorf("sum tvl: %w", err)
+	}
+	defer rows.Close()
+	var out []TVLTotal
+	for rows.Next() {
+		var t TVLTotal
+		if err := rows.Scan(&t.ChainID, &t.TokenAddress, &t.Tasks, &t.AmountWei); err != nil {
+			return nil, fmt.Errorf("scan tvl total: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
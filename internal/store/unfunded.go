@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UnfundedAccept is a task a worker accepted off-chain whose employer never
+// created the escrow. AcceptedAt is the task's updated_at, which for an
+// off-chain accepted task is when the accept was recorded.
+type UnfundedAccept struct {
+	TaskID          string
+	ChainID         int
+	EmployerAddress string
+	WorkerAddress   string
+	AmountWei       string
+	AcceptedAt      time.Time
+}
+
+// ListUnfundedAccepts returns public tasks in the off-chain accepted state,
+// accepted before acceptedBefore and never seen onchain, oldest first.
+func (r *PostgresTaskRepo) ListUnfundedAccepts(ctx context.Context, acceptedBefore time.Time, limit int) ([]UnfundedAccept, error) {
+	const q = `
+SELECT task_id, chain_id, employer_address, COALESCE(worker_address,''), amount_wei, updated_at
+FROM tasks
+WHERE status = $1 AND onchain_created_at IS NULL AND updated_at < $2
+  AND visibility = 'public' AND deleted_at IS NULL
+ORDER BY updated_at, task_id
+LIMIT $3`
+	rows, err := r.reader(ctx).Query(ctx, q, TaskStatusAccepted, acceptedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list unfunded accepts: %w", err)
+	}
+	defer rows.Close()
+	var out []UnfundedAccept
+	for rows.Next() {
+		var u UnfundedAccept
+		if err := rows.Scan(&u.TaskID, &u.ChainID, &u.EmployerAddress, &u.WorkerAddress, &u.AmountWei, &u.AcceptedAt); err != nil {
+			return nil, fmt.Errorf("scan unfunded accept: %w", err)
+		}
+		u.AcceptedAt = u.AcceptedAt.UTC()
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// CountUnfundedAcceptsByEmployer counts, per employer, the tasks
+// ListUnfundedAccepts would return for acceptedBefore, private ones included.
+func (r *PostgresTaskRepo) CountUnfundedAcceptsByEmployer(ctx context.Context, acceptedBefore time.Time) (map[string]int64, error) {
+	const q = `
+SELECT employer_address, count(*)
+FROM tasks
+WHERE status = $1 AND onchain_created_at IS NULL AND updated_at < $2 AND deleted_at IS NULL
+GROUP BY employer_address`
+	rows, err := r.reader(ctx).Query(ctx, q, TaskStatusAccepted, acceptedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("count unfunded accepts: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string]int64)
+	for rows.Next() {
+		var employer string
+		var n int64
+		if err := rows.Scan(&employer, &n); err != nil {
+			return nil, fmt.Errorf("scan unfunded accept count: %w", err)
+		}
+		out[employer] = n
+	}
+	return out, rows.Err()
+}
+
+// RevertUnfundedAccepts resets every task accepted off-chain before
+// acceptedBefore and never seen onchain to created, clearing its worker so
+// others can accept it. It returns the reverted tasks as they were, worker
+// included.
+func (r *PostgresTaskRepo) RevertUnfundedAccepts(ctx context.Context, acceptedBefore time.Time) ([]UnfundedAccept, error) {
+	const q = `
+WITH stale AS (
+    SELECT task_id, worker_address, updated_at FROM tasks
+    WHERE status = $2 AND onchain_created_at IS NULL AND updated_at < $3 AND deleted_at IS NULL
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE tasks t SET status = $1, worker_address = NULL, updated_at = now()
+FROM stale
+WHERE t.task_id = stale.task_id
+RETURNING t.task_id, t.chain_id, t.employer_address, COALESCE(stale.worker_address,''), t.amount_wei, stale.updated_at`
+	rows, err := r.pool.Query(ctx, q, TaskStatusCreated, TaskStatusAccepted, acceptedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("revert unfunded accepts: %w", err)
+	}
+	defer rows.Close()
+	var out []UnfundedAccept
+	for rows.Next() {
+		var u UnfundedAccept
+		if err := rows.Scan(&u.TaskID, &u.ChainID, &u.EmployerAddress, &u.WorkerAddress, &u.AmountWei, &u.AcceptedAt); err != nil {
+			return nil, fmt.Errorf("scan reverted task: %w", err)
+		}
+		u.AcceptedAt = u.AcceptedAt.UTC()
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
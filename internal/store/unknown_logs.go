@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// UnknownLog is a log from a settlement contract whose first topic matches
+// no registered event, kept for later analysis (e.g. an event version added
+// by a contract upgrade). Hashes and data are 0x-prefixed hex.
+type UnknownLog struct {
+	ChainID     int
+	Address     string
+	TxHash      string
+	LogIndex    uint
+	BlockNumber uint64
+	Topics      []string
+	Data        string
+}
+
+// RecordUnknownLog stores l once per (chain_id, tx_hash, log_index);
+// redeliveries are ignored.
+func (r *PostgresTaskRepo) RecordUnknownLog(ctx context.Context, l *UnknownLog) error {
+	const q = `
+INSERT INTO unknown_logs (chain_id, tx_hash, log_index, block_number, address, topic0, topics, data)
+VALUES ($1, lower($2), $3, $4, lower($5), lower($6), $7, $8)
+ON CONFLICT (chain_id, tx_hash, log_index) DO NOTHING`
+	topic0 := ""
+	if len(l.Topics) > 0 {
+		topic0 = l.Topics[0]
+	}
+	_, err := r.pool.Exec(ctx, q, l.ChainID, l.TxHash, int64(l.LogIndex), int64(l.BlockNumber), l.Address, topic0, l.Topics, l.Data)
+	if err != nil {
+		return fmt.Errorf("record unknown log: %w", err)
+	}
+	return nil
+}
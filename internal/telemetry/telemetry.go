@@ -0,0 +1,210 @@
+// Package telemetry implements the opt-in usage reporter that publishes a
+// signed, anonymised activity summary to the AgentMesh directory.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/crypto"
+)
+
+// TaskCounter is the subset of store.TaskRepo used by the reporter.
+type TaskCounter interface {
+	CountTasksByStatus(ctx context.Context) (map[string]int64, error)
+}
+
+// ObjectCounter is the subset of store.Repo used by the reporter.
+type ObjectCounter interface {
+	CountObjectsByType(ctx context.Context) (map[string]int64, error)
+}
+
+// Summary is the signed part of a report. It must never carry addresses,
+// payloads or any other per-user data.
+type Summary struct {
+	Name          string           `json:"name"`
+	URL           string           `json:"url"`
+	Version       string           `json:"version"`
+	Chains        []int            `json:"chains"`
+	TasksByStatus map[string]int64 `json:"tasks_by_status"`
+	ObjectsByType map[string]int64 `json:"objects_by_type"`
+	GeneratedAt   string           `json:"generated_at"`
+}
+
+// Report is the body POSTed to the telemetry collector. Signature is the
+// ed25519 signature over the RFC 8785 canonical JSON of Summary.
+type Report struct {
+	Summary   Summary `json:"summary"`
+	PublicKey string  `json:"public_key"`
+	Signature string  `json:"signature"`
+}
+
+// Build assembles and signs a report from the current stats. It fails
+// without a signing key rather than return an unsigned report.
+func Build(ctx context.Context, cfg config.Config, tasks TaskCounter, objects ObjectCounter) (*Report, error) {
+	if cfg.SigningKeyHex == "" {
+		return nil, errors.New("telemetry: no INDEXER_SIGNING_KEY to sign the report")
+	}
+	byStatus, err := tasks.CountTasksByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: count tasks: %w", err)
+	}
+	byType, err := objects.CountObjectsByType(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: count objects: %w", err)
+	}
+
+	chains := make([]int, len(cfg.SupportedChains))
+	for i, c := range cfg.SupportedChains {
+		chains[i] = c.ChainID
+	}
+
+	rep := &Report{Summary: Summary{
+		Name:          cfg.IndexerName,
+		URL:           cfg.IndexerBaseURL,
+		Version:       cfg.Version,
+		Chains:        chains,
+		TasksByStatus: byStatus,
+		ObjectsByType: byType,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+	}}
+
+	priv, err := crypto.PrivateKeyFromSeedHex(cfg.SigningKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: %w", err)
+	}
+	canonical, err := canonicaljson.Canonicalize(rep.Summary)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: %w", err)
+	}
+	rep.PublicKey = hex.EncodeToString(priv.Public().(ed25519.PublicKey))
+	rep.Signature = hex.EncodeToString(ed25519.Sign(priv, canonical))
+	return rep, nil
+}
+
+// Reporter periodically sends reports to a collector URL.
+type Reporter struct {
+	cfg     config.Config
+	tasks   TaskCounter
+	objects ObjectCounter
+	client  *http.Client
+
+	minBackoff time.Duration
+}
+
+// NewReporter creates a Reporter posting to cfg.TelemetryURL every
+// cfg.TelemetryInterval.
+func NewReporter(cfg config.Config, tasks TaskCounter, objects ObjectCounter) *Reporter {
+	if cfg.TelemetryInterval <= 0 {
+		cfg.TelemetryInterval = time.Hour
+	}
+	return &Reporter{
+		cfg:        cfg,
+		tasks:      tasks,
+		objects:    objects,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		minBackoff: 30 * time.Second,
+	}
+}
+
+// Run sends a report immediately and then once per interval until ctx is
+// cancelled. Failed sends are retried with exponential backoff capped at the
+// interval.
+//
+// Intended to be called as: go reporter.Run(ctx)
+func (r *Reporter) Run(ctx context.Context) {
+	backoff := r.minBackoff
+	for {
+		wait := r.cfg.TelemetryInterval
+		if err := r.SendOnce(ctx); err != nil {
+			log.Printf("[telemetry] send failed: %v — retrying in %s", err, backoff)
+			wait = backoff
+			backoff *= 2
+			if backoff > r.cfg.TelemetryInterval {
+				backoff = r.cfg.TelemetryInterval
+			}
+		} else {
+			backoff = r.minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// SendOnce builds a report and POSTs it to the collector.
+func (r *Reporter) SendOnce(ctx context.Context) error {
+	rep, err := Build(ctx, r.cfg, r.tasks, r.objects)
+	if err != nil {
+		return err
+	}
+	body, err := canonicaljson.Canonicalize(rep)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.TelemetryURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := signRequest(req.Header, r.cfg, body); err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Request signature headers, named as for signed API responses. The
+// signature covers the exact POST body and is sent as "<scheme>=<hex>".
+// For ed25519 the key ID is the hex public key published in /v1/meta.
+const (
+	headerSignature = "X-AMN-Signature"
+	headerKeyID     = "X-AMN-Key-ID"
+)
+
+// signRequest sets the signature headers for body using
+// cfg.TelemetrySignatureScheme. The HMAC scheme without a secret sends no
+// signature header.
+func signRequest(h http.Header, cfg config.Config, body []byte) error {
+	switch cfg.TelemetrySignatureScheme {
+	case "", "hmac-sha256":
+		if cfg.TelemetryHMACSecret == "" {
+			return nil
+		}
+		mac := hmac.New(sha256.New, []byte(cfg.TelemetryHMACSecret))
+		mac.Write(body)
+		h.Set(headerSignature, "hmac-sha256="+hex.EncodeToString(mac.Sum(nil)))
+	case "ed25519":
+		priv, err := crypto.PrivateKeyFromSeedHex(cfg.SigningKeyHex)
+		if err != nil {
+			return fmt.Errorf("telemetry: %w", err)
+		}
+		h.Set(headerSignature, "ed25519="+hex.EncodeToString(ed25519.Sign(priv, body)))
+		h.Set(headerKeyID, hex.EncodeToString(priv.Public().(ed25519.PublicKey)))
+	default:
+		return fmt.Errorf("telemetry: unknown signature scheme %q", cfg.TelemetrySignatureScheme)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/config"
+	"github.com/AgentMesh-Net/indexer-go/internal/core/canonicaljson"
+)
+
+type fakeStats struct{}
+
+func (fakeStats) CountTasksByStatus(context.Context) (map[string]int64, error) {
+	return map[string]int64{"created": 3, "released": 1}, nil
+}
+
+func (fakeStats) CountObjectsByType(context.Context) (map[string]int64, error) {
+	return map[string]int64{"bid": 7}, nil
+}
+
+func testConfig(url string) config.Config {
+	return config.Config{
+		IndexerName:       "test-indexer",
+		IndexerBaseURL:    "https://indexer.example",
+		Version:           "9.9.9",
+		SigningKeyHex:     "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		SupportedChains:   []config.ChainConfig{{ChainID: 11155111}},
+		TelemetryURL:      url,
+		TelemetryInterval: time.Hour,
+	}
+}
+
+func TestSendOnce_SignedReportReachesCollector(t *testing.T) {
+	var got []byte
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer collector.Close()
+
+	r := NewReporter(testConfig(collector.URL), fakeStats{}, fakeStats{})
+	if err := r.SendOnce(context.Background()); err != nil {
+		t.Fatalf("SendOnce: %v", err)
+	}
+
+	var rep Report
+	if err := json.Unmarshal(got, &rep); err != nil {
+		t.Fatalf("collector body not JSON: %v", err)
+	}
+	if rep.Summary.TasksByStatus["created"] != 3 || rep.Summary.ObjectsByType["bid"] != 7 {
+		t.Errorf("unexpected counts: %+v", rep.Summary)
+	}
+	if len(rep.Summary.Chains) != 1 || rep.Summary.Chains[0] != 11155111 {
+		t.Errorf("unexpected chains: %v", rep.Summary.Chains)
+	}
+
+	pub, _ := hex.DecodeString(rep.PublicKey)
+	sig, _ := hex.DecodeString(rep.Signature)
+	canonical, err := canonicaljson.Canonicalize(rep.Summary)
+	if err != nil {
+		t.Fatalf("canonicalize: %v", err)
+	}
+	if !ed25519.Verify(pub, canonical, sig) {
+		t.Error("report signature does not verify")
+	}
+}
+
+func TestSendOnce_SignatureSchemes(t *testing.T) {
+	var body []byte
+	var header http.Header
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	}))
+	defer collector.Close()
+
+	send := func(mutate func(*config.Config)) {
+		t.Helper()
+		cfg := testConfig(collector.URL)
+		mutate(&cfg)
+		if err := NewReporter(cfg, fakeStats{}, fakeStats{}).SendOnce(context.Background()); err != nil {
+			t.Fatalf("SendOnce: %v", err)
+		}
+	}
+
+	t.Run("hmac-sha256", func(t *testing.T) {
+		send(func(c *config.Config) { c.TelemetrySignatureScheme, c.TelemetryHMACSecret = "hmac-sha256", "s3cret" })
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(body)
+		if got, want := header.Get("X-AMN-Signature"), "hmac-sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("X-AMN-Signature = %q, want %q", got, want)
+		}
+		if header.Get("X-AMN-Key-ID") != "" {
+			t.Error("HMAC request carries a key ID")
+		}
+	})
+
+	t.Run("hmac_without_secret", func(t *testing.T) {
+		send(func(c *config.Config) { c.TelemetrySignatureScheme = "hmac-sha256" })
+		if got := header.Get("X-AMN-Signature"); got != "" {
+			t.Errorf("X-AMN-Signature = %q, want none", got)
+		}
+	})
+
+	t.Run("ed25519", func(t *testing.T) {
+		send(func(c *config.Config) { c.TelemetrySignatureScheme = "ed25519" })
+		sigHex, ok := strings.CutPrefix(header.Get("X-AMN-Signature"), "ed25519=")
+		if !ok {
+			t.Fatalf("X-AMN-Signature = %q, want ed25519= prefix", header.Get("X-AMN-Signature"))
+		}
+		pub, _ := hex.DecodeString(header.Get("X-AMN-Key-ID"))
+		sig, _ := hex.DecodeString(sigHex)
+		if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, body, sig) {
+			t.Error("request signature does not verify against the key ID")
+		}
+	})
+}
+
+func TestSendOnce_RefusesUnsignedReport(t *testing.T) {
+	posted := false
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = true
+	}))
+	defer collector.Close()
+
+	cfg := testConfig(collector.URL)
+	cfg.SigningKeyHex = ""
+	err := NewReporter(cfg, fakeStats{}, fakeStats{}).SendOnce(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "INDEXER_SIGNING_KEY") {
+		t.Errorf("SendOnce without key: err = %v", err)
+	}
+	if posted {
+		t.Error("unsigned report reached the collector")
+	}
+}
+
+func TestSendOnce_CollectorError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	r := NewReporter(testConfig(collector.URL), fakeStats{}, fakeStats{})
+	err := r.SendOnce(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("expected 503 error, got %v", err)
+	}
+}
+
+func TestRun_RetriesWithBackoff(t *testing.T) {
+	calls := make(chan struct{}, 8)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls <- struct{}{}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer collector.Close()
+
+	r := NewReporter(testConfig(collector.URL), fakeStats{}, fakeStats{})
+	r.minBackoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-calls:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected retry %d", i+1)
+		}
+	}
+}
+
+func TestBuild_NeverIncludesAddresses(t *testing.T) {
+	rep, err := Build(context.Background(), testConfig(""), fakeStats{}, fakeStats{})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	raw, _ := json.Marshal(rep)
+	if strings.Contains(string(raw), "0x") {
+		t.Errorf("report leaked an address-like value: %s", raw)
+	}
+}
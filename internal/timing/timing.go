@@ -0,0 +1,80 @@
+// Package timing collects the duration of the operations one request
+// performs, so a slow request can be logged with a breakdown of where its
+// time went. A request without a Collector in its context pays only a
+// context lookup per operation.
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Op is one timed operation.
+type Op struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Collector accumulates the operations of one request. It is safe for
+// concurrent use; the op list is allocated on the first Record.
+type Collector struct {
+	mu  sync.Mutex
+	ops []Op
+}
+
+type ctxKey struct{}
+
+// NewContext returns ctx carrying a new Collector, and the Collector.
+func NewContext(ctx context.Context) (context.Context, *Collector) {
+	c := &Collector{}
+	return context.WithValue(ctx, ctxKey{}, c), c
+}
+
+// FromContext returns the Collector in ctx, or nil.
+func FromContext(ctx context.Context) *Collector {
+	c, _ := ctx.Value(ctxKey{}).(*Collector)
+	return c
+}
+
+func noop() {}
+
+// Start begins timing the operation name and returns the function that
+// records it, typically deferred:
+//
+//	defer timing.Start(ctx, "GetTask")()
+//
+// Without a Collector in ctx it records nothing.
+func Start(ctx context.Context, name string) func() {
+	c := FromContext(ctx)
+	if c == nil {
+		return noop
+	}
+	start := time.Now()
+	return func() { c.Record(name, time.Since(start)) }
+}
+
+// Record adds an operation.
+func (c *Collector) Record(name string, d time.Duration) {
+	c.mu.Lock()
+	c.ops = append(c.ops, Op{Name: name, Duration: d})
+	c.mu.Unlock()
+}
+
+// Ops returns the recorded operations in the order they finished.
+func (c *Collector) Ops() []Op {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Op(nil), c.ops...)
+}
+
+// Total returns the summed duration of the recorded operations.
+func (c *Collector) Total() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total time.Duration
+	for _, op := range c.ops {
+		total += op.Duration
+	}
+	return total
+}
@@ -0,0 +1,33 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStart_RecordsIntoCollector(t *testing.T) {
+	ctx, c := NewContext(context.Background())
+	if FromContext(ctx) != c {
+		t.Fatal("FromContext did not return the collector")
+	}
+	done := Start(ctx, "GetTask")
+	time.Sleep(5 * time.Millisecond)
+	done()
+	c.Record("ListAccepts", time.Millisecond)
+
+	ops := c.Ops()
+	if len(ops) != 2 || ops[0].Name != "GetTask" || ops[0].Duration < 5*time.Millisecond || ops[1].Name != "ListAccepts" {
+		t.Fatalf("ops = %+v", ops)
+	}
+	if c.Total() != ops[0].Duration+ops[1].Duration {
+		t.Errorf("Total = %s", c.Total())
+	}
+}
+
+func TestStart_WithoutCollectorDoesNotAllocate(t *testing.T) {
+	ctx := context.Background()
+	if n := testing.AllocsPerRun(100, func() { Start(ctx, "GetTask")() }); n != 0 {
+		t.Errorf("Start without a collector allocates %v times", n)
+	}
+}
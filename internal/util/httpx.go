@@ -3,8 +3,10 @@ package util
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/AgentMesh-Net/indexer-go/internal/store"
 )
@@ -50,31 +52,48 @@ func ParseLimit(r *http.Request, defaultLimit, maxLimit int) int {
 	return n
 }
 
-// ParseCursor decodes the opaque cursor query parameter.
-func ParseCursor(r *http.Request) *store.Cursor {
+// ErrCursorExpired is returned by ParseCursor when a cursor is older than the
+// configured TTL.
+var ErrCursorExpired = errors.New("cursor expired; restart paging without a cursor")
+
+// ParseCursor decodes the opaque cursor query parameter. Malformed cursors are
+// ignored (nil, nil). If ttl > 0 and the cursor carries an issued-at time older
+// than ttl, ErrCursorExpired is returned.
+func ParseCursor(r *http.Request, ttl time.Duration) (*store.Cursor, error) {
 	s := r.URL.Query().Get("cursor")
 	if s == "" {
-		return nil
+		return nil, nil
 	}
 	raw, err := base64.RawURLEncoding.DecodeString(s)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 	var c store.Cursor
 	if err := json.Unmarshal(raw, &c); err != nil {
-		return nil
+		return nil, nil
 	}
 	if c.CreatedAt == "" || c.ObjectID == "" {
-		return nil
+		return nil, nil
+	}
+	if _, err := time.Parse(time.RFC3339Nano, c.CreatedAt); err != nil {
+		return nil, nil
 	}
-	return &c
+	if ttl > 0 && c.IssuedAt > 0 && time.Since(time.Unix(c.IssuedAt, 0)) > ttl {
+		return nil, ErrCursorExpired
+	}
+	return &c, nil
 }
 
-// EncodeCursor encodes a cursor into an opaque string.
+// EncodeCursor encodes a cursor into an opaque string, stamping the issued-at
+// time if it is not already set.
 func EncodeCursor(c *store.Cursor) string {
 	if c == nil {
 		return ""
 	}
-	raw, _ := json.Marshal(c)
+	out := *c
+	if out.IssuedAt == 0 {
+		out.IssuedAt = time.Now().Unix()
+	}
+	raw, _ := json.Marshal(out)
 	return base64.RawURLEncoding.EncodeToString(raw)
 }
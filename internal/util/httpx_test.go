@@ -0,0 +1,85 @@
+package util
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/store"
+)
+
+func TestParseCursor_RoundTrip(t *testing.T) {
+	enc := EncodeCursor(&store.Cursor{CreatedAt: "2025-01-01T00:00:00Z", ObjectID: "obj-1"})
+	r := httptest.NewRequest("GET", "/v1/bids?cursor="+url.QueryEscape(enc), nil)
+
+	got, err := ParseCursor(r, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || got.ObjectID != "obj-1" || got.IssuedAt == 0 {
+		t.Fatalf("unexpected cursor: %+v", got)
+	}
+}
+
+func TestParseCursor_Expired(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour).Unix()
+	enc := EncodeCursor(&store.Cursor{CreatedAt: "2025-01-01T00:00:00Z", ObjectID: "obj-1", IssuedAt: old})
+	r := httptest.NewRequest("GET", "/v1/bids?cursor="+url.QueryEscape(enc), nil)
+
+	if _, err := ParseCursor(r, time.Hour); !errors.Is(err, ErrCursorExpired) {
+		t.Fatalf("expected ErrCursorExpired, got %v", err)
+	}
+	// TTL of 0 disables the check.
+	if got, err := ParseCursor(r, 0); err != nil || got == nil {
+		t.Fatalf("expected cursor with ttl=0, got %+v, %v", got, err)
+	}
+}
+
+func TestParseCursor_LegacyWithoutIssuedAt(t *testing.T) {
+	// Cursors minted before issued-at existed carry no "t" and are accepted.
+	r := httptest.NewRequest("GET", "/v1/bids?cursor=eyJjIjoiMjAyNS0wMS0wMVQwMDowMDowMFoiLCJpIjoib2JqLTEifQ", nil)
+	got, err := ParseCursor(r, time.Second)
+	if err != nil || got == nil {
+		t.Fatalf("expected legacy cursor accepted, got %+v, %v", got, err)
+	}
+}
+
+func TestParseCursor_UnparseableTimeIgnored(t *testing.T) {
+	enc := EncodeCursor(&store.Cursor{CreatedAt: "yesterday", ObjectID: "obj-1"})
+	r := httptest.NewRequest("GET", "/v1/bids?cursor="+url.QueryEscape(enc), nil)
+	if got, err := ParseCursor(r, time.Hour); got != nil || err != nil {
+		t.Fatalf("expected malformed cursor ignored, got %+v, %v", got, err)
+	}
+}
+
+func FuzzParseCursor(f *testing.F) {
+	f.Add(EncodeCursor(&store.Cursor{CreatedAt: "2025-01-01T00:00:00.123456Z", ObjectID: "obj-1"}))
+	f.Add("eyJjIjoiMjAyNS0wMS0wMVQwMDowMDowMFoiLCJpIjoib2JqLTEifQ")
+	f.Add("")
+	f.Add("%%%")
+	f.Add("e30")
+	f.Fuzz(func(t *testing.T, raw string) {
+		r := httptest.NewRequest("GET", "/v1/bids", nil)
+		r.URL.RawQuery = url.Values{"cursor": {raw}}.Encode()
+
+		c, err := ParseCursor(r, time.Hour)
+		if err != nil {
+			if !errors.Is(err, ErrCursorExpired) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return
+		}
+		if c == nil {
+			return
+		}
+		// Any cursor handed to the store must be usable as-is.
+		if c.ObjectID == "" {
+			t.Fatalf("cursor without object id: %+v", c)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, c.CreatedAt); err != nil {
+			t.Fatalf("cursor with unparseable time %q: %v", c.CreatedAt, err)
+		}
+	})
+}
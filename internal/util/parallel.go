@@ -0,0 +1,30 @@
+package util
+
+import "sync"
+
+// ForEach calls fn(i) for every i in [0, n) on at most limit goroutines and
+// returns when all calls have finished. limit < 1 is treated as 1.
+func ForEach(n, limit int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > n {
+		limit = n
+	}
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < limit; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
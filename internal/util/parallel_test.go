@@ -0,0 +1,35 @@
+package util
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEach_BoundsConcurrency(t *testing.T) {
+	const n, limit = 50, 4
+	var running, peak atomic.Int32
+	done := make([]bool, n)
+	ForEach(n, limit, func(i int) {
+		cur := running.Add(1)
+		for {
+			p := peak.Load()
+			if cur <= p || peak.CompareAndSwap(p, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		done[i] = true
+		running.Add(-1)
+	})
+	for i, ok := range done {
+		if !ok {
+			t.Fatalf("item %d not processed", i)
+		}
+	}
+	if p := peak.Load(); p > limit {
+		t.Errorf("peak concurrency %d exceeds limit %d", p, limit)
+	}
+
+	ForEach(0, limit, func(int) { t.Error("fn called for n = 0") })
+}
@@ -0,0 +1,168 @@
+// Package verifypool runs signature verifications on a fixed set of worker
+// goroutines. A burst of submissions queues briefly instead of spreading
+// secp256k1 recovery over every request goroutine; once the queue is full, or
+// a job has waited too long, callers get ErrOverloaded and can shed load.
+package verifypool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AgentMesh-Net/indexer-go/internal/metrics"
+)
+
+// ErrOverloaded is returned by Pool.Do when the queue is full, the job
+// waited longer than the queue timeout, or the pool is closed.
+var ErrOverloaded = errors.New("verification queue overloaded")
+
+var (
+	jobsTotal = metrics.NewCounterVec("amn_verification_jobs_total",
+		"Verification jobs by result (done, overloaded, cancelled).", "result")
+	waitSeconds = metrics.NewCounterVec("amn_verification_queue_wait_seconds_total",
+		"Total time verification jobs waited in the queue before a worker ran them.")
+
+	// queued is the number of jobs waiting in every pool, exported by
+	// RegisterMetrics.
+	queued      atomic.Int64
+	registerOne sync.Once
+)
+
+// RegisterMetrics exposes the queue depth in the default metrics registry.
+// It is safe to call more than once.
+func RegisterMetrics() {
+	registerOne.Do(func() {
+		metrics.Register(metrics.GaugeFunc{
+			Name: "amn_verification_queue_depth",
+			Help: "Verification jobs waiting for a worker.",
+			Fn:   func() []metrics.Sample { return []metrics.Sample{{Value: float64(queued.Load())}} },
+		})
+	})
+}
+
+// Job states. A job is claimed exactly once: by a worker, which runs it, or
+// by the caller giving up on it, after which workers skip it.
+const (
+	jobQueued int32 = iota
+	jobRunning
+	jobAbandoned
+)
+
+type job struct {
+	fn       func() error
+	done     chan error // buffered; the caller may have stopped listening
+	state    atomic.Int32
+	enqueued time.Time
+}
+
+// Pool is a bounded verification executor. Create it with New.
+type Pool struct {
+	timeout time.Duration
+	ch      chan *job
+	wg      sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New starts workers goroutines draining a queue of queueSize jobs. A job
+// not picked up within timeout fails with ErrOverloaded; timeout <= 0 waits
+// for as long as the caller's context allows.
+func New(workers, queueSize int, timeout time.Duration) *Pool {
+	p := &Pool{timeout: timeout, ch: make(chan *job, queueSize)}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Do runs fn on a worker and returns its error. It does not block to
+// enqueue: a full queue returns ErrOverloaded at once. While fn is queued,
+// ctx cancellation or the queue timeout abandons it and Do returns
+// ctx.Err() or ErrOverloaded; once a worker has started fn, Do waits for it
+// unless ctx is cancelled.
+func (p *Pool) Do(ctx context.Context, fn func() error) error {
+	j := &job{fn: fn, done: make(chan error, 1), enqueued: time.Now()}
+	if err := p.enqueue(j); err != nil {
+		jobsTotal.Inc("overloaded")
+		return err
+	}
+
+	var expired <-chan time.Time
+	if p.timeout > 0 {
+		t := time.NewTimer(p.timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+	for {
+		select {
+		case err := <-j.done:
+			return err
+		case <-expired:
+			expired = nil
+			if j.abandon() {
+				jobsTotal.Inc("overloaded")
+				return ErrOverloaded
+			}
+			// Already running: wait for the result.
+		case <-ctx.Done():
+			if j.abandon() {
+				jobsTotal.Inc("cancelled")
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *Pool) enqueue(j *job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrOverloaded
+	}
+	select {
+	case p.ch <- j:
+		queued.Add(1)
+		return nil
+	default:
+		return ErrOverloaded
+	}
+}
+
+// abandon claims a queued job for its caller. It reports false if a worker
+// has already started it.
+func (j *job) abandon() bool {
+	return j.state.CompareAndSwap(jobQueued, jobAbandoned)
+}
+
+// Depth returns the number of jobs not yet picked up by a worker.
+func (p *Pool) Depth() int {
+	return len(p.ch)
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.ch)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for j := range p.ch {
+		queued.Add(-1)
+		if !j.state.CompareAndSwap(jobQueued, jobRunning) {
+			continue
+		}
+		waitSeconds.Add(time.Since(j.enqueued).Seconds())
+		j.done <- j.fn()
+		jobsTotal.Inc("done")
+	}
+}
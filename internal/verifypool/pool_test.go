@@ -0,0 +1,127 @@
+package verifypool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_BoundsConcurrencyUnderLoad(t *testing.T) {
+	const workers, callers = 4, 200
+	p := New(workers, callers, time.Second)
+	defer p.Close()
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- p.Do(context.Background(), func() error {
+				cur := running.Add(1)
+				for {
+					old := peak.Load()
+					if cur <= old || peak.CompareAndSwap(old, cur) {
+						break
+					}
+				}
+				time.Sleep(100 * time.Microsecond)
+				running.Add(-1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+	}
+	if got := peak.Load(); got > workers {
+		t.Errorf("peak concurrency = %d, want <= %d", got, workers)
+	}
+}
+
+// blocked returns a pool whose single worker is busy until the returned
+// release func is called.
+func blocked(t *testing.T, queueSize int, timeout time.Duration) (*Pool, func()) {
+	t.Helper()
+	p := New(1, queueSize, timeout)
+	started, release := make(chan struct{}), make(chan struct{})
+	go p.Do(context.Background(), func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	var once sync.Once
+	return p, func() { once.Do(func() { close(release) }) }
+}
+
+func TestPool_FullQueueRejectsAtOnce(t *testing.T) {
+	p, release := blocked(t, 1, time.Minute)
+	defer p.Close()
+	defer release()
+
+	go p.Do(context.Background(), func() error { return nil })
+	for p.Depth() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	start := time.Now()
+	if err := p.Do(context.Background(), func() error { return nil }); !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("err = %v, want ErrOverloaded", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("rejection took %s, want immediate", d)
+	}
+}
+
+func TestPool_QueueTimeout(t *testing.T) {
+	p, release := blocked(t, 4, 20*time.Millisecond)
+	defer p.Close()
+
+	var ran atomic.Bool
+	err := p.Do(context.Background(), func() error { ran.Store(true); return nil })
+	if !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("err = %v, want ErrOverloaded", err)
+	}
+	release()
+	p.Close()
+	if ran.Load() {
+		t.Error("a job abandoned in the queue still ran")
+	}
+}
+
+func TestPool_ContextCancelledWhileQueued(t *testing.T) {
+	p, release := blocked(t, 4, time.Minute)
+	defer p.Close()
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Do(ctx, func() error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestPool_ReturnsJobError(t *testing.T) {
+	p := New(2, 2, time.Second)
+	defer p.Close()
+	want := errors.New("bad signature")
+	if err := p.Do(context.Background(), func() error { return want }); !errors.Is(err, want) {
+		t.Fatalf("err = %v, want %v", err, want)
+	}
+}
+
+func TestPool_ClosedRejects(t *testing.T) {
+	p := New(1, 1, time.Second)
+	p.Close()
+	if err := p.Do(context.Background(), func() error { return nil }); !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("err = %v, want ErrOverloaded", err)
+	}
+}
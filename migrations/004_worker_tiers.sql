@@ -0,0 +1,8 @@
+-- Worker trust tiers: cap the task value a worker may accept
+CREATE TABLE IF NOT EXISTS worker_tiers (
+    worker_address      TEXT        PRIMARY KEY,
+    tier                INTEGER     NOT NULL DEFAULT 0,
+    max_task_amount_wei TEXT,
+    updated_by          TEXT,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
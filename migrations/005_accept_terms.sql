@@ -0,0 +1,6 @@
+-- Snapshot of the task terms a worker agreed to, captured when the accept is stored
+ALTER TABLE accepts
+    ADD COLUMN IF NOT EXISTS terms_amount_wei      TEXT,
+    ADD COLUMN IF NOT EXISTS terms_deadline_unix   BIGINT,
+    ADD COLUMN IF NOT EXISTS terms_title_hash      TEXT,
+    ADD COLUMN IF NOT EXISTS terms_indexer_fee_bps INTEGER;
@@ -0,0 +1,3 @@
+-- Efficient lookup of envelopes by signer, optionally narrowed by type
+CREATE INDEX IF NOT EXISTS idx_objects_signer_type_created_at
+    ON objects (signer_pubkey, object_type, created_at DESC);
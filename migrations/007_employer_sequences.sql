@@ -0,0 +1,9 @@
+-- Opt-in per-employer task sequence numbers for gap/duplicate detection
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS employer_sequence BIGINT;
+
+CREATE TABLE IF NOT EXISTS employer_sequences (
+    employer_address TEXT        PRIMARY KEY,
+    last_sequence    BIGINT      NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
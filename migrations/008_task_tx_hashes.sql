@@ -0,0 +1,23 @@
+-- Per-purpose onchain tx hashes so a transaction can be looked up by index
+-- (onchain_tx_hash only holds the most recent one)
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS created_tx_hash    TEXT,
+    ADD COLUMN IF NOT EXISTS worker_set_tx_hash TEXT,
+    ADD COLUMN IF NOT EXISTS released_tx_hash   TEXT,
+    ADD COLUMN IF NOT EXISTS refunded_tx_hash   TEXT;
+
+-- Backfill from onchain_tx_hash, which holds the last applied event's tx
+UPDATE tasks SET released_tx_hash = lower(onchain_tx_hash)
+    WHERE status = 'released' AND onchain_tx_hash IS NOT NULL AND released_tx_hash IS NULL;
+UPDATE tasks SET refunded_tx_hash = lower(onchain_tx_hash)
+    WHERE status = 'refunded' AND onchain_tx_hash IS NOT NULL AND refunded_tx_hash IS NULL;
+UPDATE tasks SET worker_set_tx_hash = lower(onchain_tx_hash)
+    WHERE status = 'accepted_onchain' AND onchain_tx_hash IS NOT NULL AND worker_set_tx_hash IS NULL;
+UPDATE tasks SET created_tx_hash = lower(onchain_tx_hash)
+    WHERE status IN ('created','accepted') AND onchain_created_at IS NOT NULL
+      AND onchain_tx_hash IS NOT NULL AND created_tx_hash IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_created_tx_hash    ON tasks (created_tx_hash)    WHERE created_tx_hash IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_worker_set_tx_hash ON tasks (worker_set_tx_hash) WHERE worker_set_tx_hash IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_released_tx_hash   ON tasks (released_tx_hash)   WHERE released_tx_hash IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_refunded_tx_hash   ON tasks (refunded_tx_hash)   WHERE refunded_tx_hash IS NOT NULL;
@@ -0,0 +1,16 @@
+-- Persisted audit trail (operator actions, recoveries, onchain anomalies)
+CREATE TABLE IF NOT EXISTS audit_events (
+    id         BIGSERIAL   PRIMARY KEY,
+    type       TEXT        NOT NULL,
+    severity   TEXT        NOT NULL DEFAULT 'info'
+                           CHECK (severity IN ('info','warn','critical')),
+    chain_id   INTEGER,
+    detail     JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at
+    ON audit_events (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_severity_created_at
+    ON audit_events (severity, created_at DESC, id DESC);
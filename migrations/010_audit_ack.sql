@@ -0,0 +1,8 @@
+-- Audit event acknowledgement (lightweight incident queue)
+ALTER TABLE audit_events
+    ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS acknowledged_by TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_unacked_created_at
+    ON audit_events (created_at DESC, id DESC)
+    WHERE acknowledged_at IS NULL;
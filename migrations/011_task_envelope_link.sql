@@ -0,0 +1,7 @@
+-- Link structured tasks to the envelope object they were negotiated from
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS envelope_object_id TEXT;
+
+-- Envelopes of any type that reference a structured task_id in their payload
+CREATE INDEX IF NOT EXISTS idx_objects_payload_task_id
+    ON objects ((envelope_json->'payload'->>'task_id'));
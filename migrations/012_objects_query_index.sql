@@ -0,0 +1,5 @@
+-- Signer + type + time window queries (QueryObjects). Unlike
+-- idx_objects_signer_type_created_at it includes object_id, so the
+-- (created_at, object_id) keyset is answered from the index alone.
+CREATE INDEX IF NOT EXISTS idx_objects_signer_type_created_id
+    ON objects (signer_pubkey, object_type, created_at DESC, object_id DESC);
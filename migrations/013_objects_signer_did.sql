@@ -0,0 +1,7 @@
+-- Envelopes may identify the signer by did:key. signer_pubkey always holds
+-- the normalized base64 key; signer_did keeps the submitted identifier.
+ALTER TABLE objects
+    ADD COLUMN IF NOT EXISTS signer_did TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_objects_signer_did
+    ON objects (signer_did) WHERE signer_did IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Received-order listings (order=received) page by (inserted_at, object_id)
+-- so that objects with a backdated created_at still reach forward-syncing
+-- consumers.
+CREATE INDEX IF NOT EXISTS idx_objects_type_inserted_id
+    ON objects (object_type, inserted_at, object_id);
+
+CREATE INDEX IF NOT EXISTS idx_objects_signer_type_inserted_id
+    ON objects (signer_pubkey, object_type, inserted_at, object_id);
@@ -0,0 +1,9 @@
+-- Private tasks are only disclosed to the employer and invited workers.
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'private')),
+    ADD COLUMN IF NOT EXISTS allowed_workers TEXT[] NOT NULL DEFAULT '{}';
+
+-- GET /v1/tasks lists public tasks only.
+CREATE INDEX IF NOT EXISTS idx_tasks_public_created_at
+    ON tasks (created_at DESC) WHERE visibility = 'public';
@@ -0,0 +1,4 @@
+-- Delta sync (GET /v1/tasks?updated_since=...) pages public tasks by
+-- (updated_at, task_id).
+CREATE INDEX IF NOT EXISTS idx_tasks_public_updated_at
+    ON tasks (updated_at, task_id) WHERE visibility = 'public';
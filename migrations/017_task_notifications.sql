@@ -0,0 +1,12 @@
+-- Notifications already sent per task, so each warning window fires once.
+CREATE TABLE IF NOT EXISTS task_notifications (
+    task_id        TEXT        NOT NULL REFERENCES tasks(task_id) ON DELETE CASCADE,
+    kind           TEXT        NOT NULL,
+    window_seconds BIGINT      NOT NULL,
+    notified_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, kind, window_seconds)
+);
+
+-- The deadline notifier scans accepted tasks by deadline.
+CREATE INDEX IF NOT EXISTS idx_tasks_accepted_deadline
+    ON tasks (deadline_unix) WHERE status IN ('accepted', 'accepted_onchain');
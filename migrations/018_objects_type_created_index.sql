@@ -0,0 +1,5 @@
+-- Type + time window listings (created_after / created_before). Unlike
+-- idx_objects_type_created_at it includes object_id, so the
+-- (created_at, object_id) keyset is answered from the index alone.
+CREATE INDEX IF NOT EXISTS idx_objects_type_created_id
+    ON objects (object_type, created_at DESC, object_id DESC);
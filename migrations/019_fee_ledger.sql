@@ -0,0 +1,17 @@
+-- Indexer fees earned on released tasks. computed_fee_wei is derived from the
+-- task (amount_wei * indexer_fee_bps / 10000, rounded down); event_fee_wei is
+-- what the settlement contract reported in FeePaid, when it emits one.
+CREATE TABLE IF NOT EXISTS fee_ledger (
+    task_id          TEXT           PRIMARY KEY REFERENCES tasks(task_id) ON DELETE CASCADE,
+    chain_id         INT            NOT NULL,
+    computed_fee_wei NUMERIC(78,0)  NOT NULL,
+    event_fee_wei    NUMERIC(78,0),
+    fee_wei          NUMERIC(78,0)  GENERATED ALWAYS AS (COALESCE(event_fee_wei, computed_fee_wei)) STORED,
+    mismatch         BOOLEAN        GENERATED ALWAYS AS (COALESCE(event_fee_wei <> computed_fee_wei, false)) STORED,
+    released_at      TIMESTAMPTZ    NOT NULL,
+    tx_hash          TEXT           NOT NULL,
+    recorded_at      TIMESTAMPTZ    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_ledger_chain_released
+    ON fee_ledger (chain_id, released_at);
@@ -0,0 +1,40 @@
+-- Lowercase addresses written before the store normalized them. Idempotent:
+-- rows that are already lowercase are not touched.
+
+-- Keyed by address: merge case-only duplicates first, keeping the most
+-- recently updated tier and the highest employer sequence.
+DELETE FROM worker_tiers a USING worker_tiers b
+WHERE lower(a.worker_address) = lower(b.worker_address)
+  AND a.worker_address <> b.worker_address
+  AND (a.updated_at, a.worker_address) < (b.updated_at, b.worker_address);
+
+UPDATE worker_tiers SET worker_address = lower(worker_address)
+WHERE worker_address <> lower(worker_address);
+
+DELETE FROM employer_sequences a USING employer_sequences b
+WHERE lower(a.employer_address) = lower(b.employer_address)
+  AND a.employer_address <> b.employer_address
+  AND (a.last_sequence, a.employer_address) < (b.last_sequence, b.employer_address);
+
+UPDATE employer_sequences SET employer_address = lower(employer_address)
+WHERE employer_address <> lower(employer_address);
+
+UPDATE tasks SET
+    escrow_address   = lower(escrow_address),
+    employer_address = lower(employer_address),
+    worker_address   = lower(worker_address),
+    allowed_workers  = ARRAY(SELECT lower(w) FROM unnest(allowed_workers) AS w)
+WHERE escrow_address <> lower(escrow_address)
+   OR employer_address <> lower(employer_address)
+   OR worker_address <> lower(worker_address)
+   OR allowed_workers::text <> lower(allowed_workers::text);
+
+-- An accept whose lowercase form already exists for the task (the same
+-- worker accepting twice under different case) is left for an operator to
+-- resolve rather than deleted.
+UPDATE accepts a SET worker_address = lower(a.worker_address)
+WHERE a.worker_address <> lower(a.worker_address)
+  AND NOT EXISTS (
+      SELECT 1 FROM accepts b
+      WHERE b.task_id = a.task_id AND b.worker_address = lower(a.worker_address)
+  );
@@ -0,0 +1,23 @@
+-- Stored addresses are 0x + 40 lowercase hex chars (see store.normalizeAddress).
+-- The constraints are NOT VALID so rows that predate them and could not be
+-- backfilled by 020 do not block startup; new and updated rows are checked.
+-- Once clean, run ALTER TABLE ... VALIDATE CONSTRAINT to cover every row.
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_addresses_lowercase;
+ALTER TABLE tasks ADD CONSTRAINT tasks_addresses_lowercase CHECK (
+    escrow_address ~ '^0x[0-9a-f]{40}$'
+    AND employer_address ~ '^0x[0-9a-f]{40}$'
+    AND (worker_address IS NULL OR worker_address ~ '^0x[0-9a-f]{40}$')
+    AND array_to_string(allowed_workers, ',') ~ '^(0x[0-9a-f]{40}(,0x[0-9a-f]{40})*)?$'
+) NOT VALID;
+
+ALTER TABLE accepts DROP CONSTRAINT IF EXISTS accepts_worker_address_lowercase;
+ALTER TABLE accepts ADD CONSTRAINT accepts_worker_address_lowercase
+    CHECK (worker_address ~ '^0x[0-9a-f]{40}$') NOT VALID;
+
+ALTER TABLE worker_tiers DROP CONSTRAINT IF EXISTS worker_tiers_worker_address_lowercase;
+ALTER TABLE worker_tiers ADD CONSTRAINT worker_tiers_worker_address_lowercase
+    CHECK (worker_address ~ '^0x[0-9a-f]{40}$') NOT VALID;
+
+ALTER TABLE employer_sequences DROP CONSTRAINT IF EXISTS employer_sequences_employer_address_lowercase;
+ALTER TABLE employer_sequences ADD CONSTRAINT employer_sequences_employer_address_lowercase
+    CHECK (employer_address ~ '^0x[0-9a-f]{40}$') NOT VALID;
@@ -0,0 +1,7 @@
+-- ERC-20 escrows: the token a task's amount_wei is denominated in. NULL means
+-- the chain's native currency.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS token_address TEXT;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_token_address_lowercase;
+ALTER TABLE tasks ADD CONSTRAINT tasks_token_address_lowercase
+    CHECK (token_address IS NULL OR token_address ~ '^0x[0-9a-f]{40}$');
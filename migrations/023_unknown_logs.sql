@@ -0,0 +1,19 @@
+-- Settlement contract logs whose first topic matches no event the watcher
+-- knows, e.g. a new event version emitted after a proxy upgrade. They are
+-- kept rather than dropped so they can be analysed and replayed once
+-- supported.
+CREATE TABLE IF NOT EXISTS unknown_logs (
+    chain_id      INT          NOT NULL,
+    tx_hash       TEXT         NOT NULL,
+    log_index     INT          NOT NULL,
+    block_number  BIGINT       NOT NULL,
+    address       TEXT         NOT NULL,
+    topic0        TEXT         NOT NULL,
+    topics        TEXT[]       NOT NULL,
+    data          TEXT         NOT NULL,
+    first_seen_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (chain_id, tx_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_unknown_logs_topic0
+    ON unknown_logs (topic0, chain_id);
@@ -0,0 +1,4 @@
+-- The POST /v1/tasks request a task was created from, as RFC 8785 canonical
+-- JSON, so the employer signature can be re-verified from the exact bytes
+-- received. NULL for tasks created before it was recorded.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS raw_request BYTEA;
@@ -0,0 +1,4 @@
+-- Operator annotations on stored objects, set through the admin API. They sit
+-- beside the signed envelope and are never part of it.
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS admin_notes TEXT;
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS admin_notes_updated_at TIMESTAMPTZ;
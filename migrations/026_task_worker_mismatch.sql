@@ -0,0 +1,4 @@
+-- The worker named by the last WorkerSet event, kept beside the off-chain
+-- accepter in worker_address. worker_mismatch flags tasks where they differ.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS onchain_worker_address TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS worker_mismatch BOOLEAN NOT NULL DEFAULT false;
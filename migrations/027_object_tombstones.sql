@@ -0,0 +1,4 @@
+-- Objects deleted with AMN_OBJECT_TOMBSTONES keep their row with deleted_at
+-- set, so reads can answer 410 Gone instead of 404. Every other read skips
+-- them.
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
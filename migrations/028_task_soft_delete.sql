@@ -0,0 +1,3 @@
+-- Tasks deleted with AMN_SOFT_DELETE keep their row with deleted_at set.
+-- Every read skips them unless an admin asks for deleted rows.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- Open tasks on a chain dropped from AMN_SUPPORTED_CHAINS_JSON are flagged
+-- at startup, so listings can warn workers and accepts can be refused.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS chain_retired_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- amount and deadline as emitted by the Created event, kept beside the
+-- declared amount_wei and deadline_unix so clients can compare them.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS onchain_amount_wei TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS onchain_deadline_unix BIGINT;
@@ -0,0 +1,29 @@
+-- Commonly filtered payload fields as typed columns Postgres keeps in step
+-- with envelope_json, so queries use plain indexed comparisons instead of
+-- JSONB paths. Adding a stored generated column rewrites the table once.
+-- payload_task_id: bid, accept and artifact task_id.
+-- payload_amount_wei: a bid's amount_wei, when it is a decimal integer.
+-- payload_content_hash: an artifact's content_hash.
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS payload_task_id TEXT
+    GENERATED ALWAYS AS (envelope_json->'payload'->>'task_id') STORED;
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS payload_amount_wei NUMERIC(78, 0)
+    GENERATED ALWAYS AS (CASE WHEN object_type = 'bid' AND envelope_json->'payload'->>'amount_wei' ~ '^[0-9]{1,78}$'
+        THEN (envelope_json->'payload'->>'amount_wei')::numeric END) STORED;
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS payload_content_hash TEXT
+    GENERATED ALWAYS AS (CASE WHEN object_type = 'artifact' THEN envelope_json->'payload'->>'content_hash' END) STORED;
+
+-- 011 created an expression index under this name; replace it with the
+-- column index (IF NOT EXISTS alone would keep the old one).
+DROP INDEX IF EXISTS idx_objects_payload_task_id;
+CREATE INDEX idx_objects_payload_task_id
+    ON objects (payload_task_id, object_type, created_at DESC, object_id DESC)
+    WHERE payload_task_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_objects_bid_amount
+    ON objects (payload_amount_wei)
+    WHERE payload_amount_wei IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_objects_artifact_content_hash
+    ON objects (payload_content_hash)
+    WHERE payload_content_hash IS NOT NULL;
+
+-- Superseded by idx_objects_payload_task_id.
+DROP INDEX IF EXISTS idx_accept_task_id;
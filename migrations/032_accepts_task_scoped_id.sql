@@ -0,0 +1,9 @@
+-- accept_id is unique per task instead of globally, so two tasks may carry
+-- accepts with the same client-chosen accept_id. No backfill is needed: the
+-- old primary key on accept_id alone already ruled out any duplicate the new
+-- (task_id, accept_id) key could reject.
+ALTER TABLE accepts DROP CONSTRAINT IF EXISTS accepts_pkey;
+ALTER TABLE accepts ADD CONSTRAINT accepts_pkey PRIMARY KEY (task_id, accept_id);
+
+-- Superseded by the primary key, which leads with task_id.
+DROP INDEX IF EXISTS idx_accepts_task_id;
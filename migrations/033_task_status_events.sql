@@ -0,0 +1,29 @@
+-- One row per task status change, written by a trigger so every writer
+-- (API, chain watcher, recovery, unfunded accept revert) is covered.
+-- old_status is NULL for the row recorded when a task is created.
+CREATE TABLE IF NOT EXISTS task_status_events (
+    id         BIGSERIAL   PRIMARY KEY,
+    task_id    TEXT        NOT NULL REFERENCES tasks (task_id) ON DELETE CASCADE,
+    old_status TEXT,
+    new_status TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_status_events_created_at
+    ON task_status_events (created_at DESC, id DESC);
+
+CREATE OR REPLACE FUNCTION record_task_status_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_status_events (task_id, new_status) VALUES (NEW.task_id, NEW.status);
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO task_status_events (task_id, old_status, new_status) VALUES (NEW.task_id, OLD.status, NEW.status);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_status_events ON tasks;
+CREATE TRIGGER tasks_status_events
+    AFTER INSERT OR UPDATE OF status ON tasks
+    FOR EACH ROW EXECUTE FUNCTION record_task_status_event();
@@ -0,0 +1,159 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultTable records which migrations have been applied.
+const DefaultTable = "schema_migrations"
+
+// Runner applies SQL migrations and records each one in a tracking table, so
+// a migration runs once per database. Every migration runs in its own
+// transaction together with its tracking row, under an advisory lock, so
+// indexers starting side by side do not apply it twice.
+type Runner struct {
+	// FS holds the migration files.
+	FS fs.FS
+	// Names is the application order. Nil applies every .sql file at the
+	// root of FS in name order, which the numeric prefixes make the intended
+	// one.
+	Names []string
+	// Table is the tracking table.
+	Table string
+	// Logf reports each applied migration.
+	Logf func(format string, args ...any)
+}
+
+// NewRunner returns a Runner over the embedded migrations.
+func NewRunner() *Runner {
+	return &Runner{FS: FS, Table: DefaultTable, Logf: log.Printf}
+}
+
+// Migrations returns the migration names in application order.
+func (r *Runner) Migrations() ([]string, error) {
+	if r.Names != nil {
+		for _, name := range r.Names {
+			if _, err := fs.Stat(r.FS, name); err != nil {
+				return nil, fmt.Errorf("migration %s: %w", name, err)
+			}
+		}
+		return r.Names, nil
+	}
+	entries, err := fs.ReadDir(r.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && path.Ext(e.Name()) == ".sql" {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Apply runs the migrations not yet recorded in the tracking table, in
+// order, and returns the names of those it applied. It stops at the first
+// failure; the migrations before it stay applied. A database migrated before
+// the tracking table existed has every migration run once more, which is
+// safe because they were all written to re-run on each startup.
+func (r *Runner) Apply(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	names, err := r.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	table := pgx.Identifier{r.Table}.Sanitize()
+	if _, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+    name       TEXT PRIMARY KEY,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`); err != nil {
+		return nil, fmt.Errorf("create %s: %w", r.Table, err)
+	}
+
+	applied := []string{}
+	for _, name := range names {
+		sql, err := fs.ReadFile(r.FS, name)
+		if err != nil {
+			return applied, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		ran, err := r.applyOne(ctx, pool, table, name, string(sql))
+		if err != nil {
+			return applied, fmt.Errorf("migration %s: %w", name, err)
+		}
+		if ran {
+			applied = append(applied, name)
+			if r.Logf != nil {
+				r.Logf("migration %s applied", name)
+			}
+		}
+	}
+	return applied, nil
+}
+
+// Pending returns the migrations not recorded in the tracking table, in
+// order. On a database without the table every migration is pending.
+func (r *Runner) Pending(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	names, err := r.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, r.Table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check %s: %w", r.Table, err)
+	}
+	if !exists {
+		return names, nil
+	}
+	rows, err := pool.Query(ctx, `SELECT name FROM `+pgx.Identifier{r.Table}.Sanitize())
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", r.Table, err)
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", r.Table, err)
+	}
+	pending := []string{}
+	for _, name := range names {
+		if !slices.Contains(applied, name) {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+// applyOne runs one migration unless it is already recorded. The advisory
+// lock is keyed on the tracking table and released with the transaction.
+func (r *Runner) applyOne(ctx context.Context, pool *pgxpool.Pool, table, name, sql string) (bool, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, r.Table); err != nil {
+		return false, fmt.Errorf("lock: %w", err)
+	}
+	var done bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE name = $1)`, name).Scan(&done); err != nil {
+		return false, fmt.Errorf("check applied: %w", err)
+	}
+	if done {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return false, fmt.Errorf("exec: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO `+table+` (name) VALUES ($1)`, name); err != nil {
+		return false, fmt.Errorf("record: %w", err)
+	}
+	return true, tx.Commit(ctx)
+}
@@ -0,0 +1,146 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestRunner_MigrationsInNameOrder(t *testing.T) {
+	r := &Runner{FS: fstest.MapFS{
+		"010_b.sql":   {Data: []byte("SELECT 1")},
+		"002_a.sql":   {Data: []byte("SELECT 1")},
+		"README.md":   {Data: []byte("not a migration")},
+		"old/001.sql": {Data: []byte("SELECT 1")},
+	}}
+	got, err := r.Migrations()
+	if err != nil || !slices.Equal(got, []string{"002_a.sql", "010_b.sql"}) {
+		t.Errorf("Migrations() = %v, %v", got, err)
+	}
+
+	r.Names = []string{"010_b.sql", "002_a.sql"}
+	if got, err := r.Migrations(); err != nil || !slices.Equal(got, r.Names) {
+		t.Errorf("with Names: %v, %v", got, err)
+	}
+	r.Names = []string{"003_missing.sql"}
+	if _, err := r.Migrations(); err == nil || !strings.Contains(err.Error(), "003_missing.sql") {
+		t.Errorf("missing name: err = %v", err)
+	}
+}
+
+func TestRunner_EmbedsEveryMigration(t *testing.T) {
+	got, err := NewRunner().Migrations()
+	if err != nil || len(got) == 0 || got[0] != "001_init.sql" {
+		t.Fatalf("Migrations() = %v, %v", got, err)
+	}
+	for i, name := range got {
+		if want := fmt.Sprintf("%03d_", i+1); !strings.HasPrefix(name, want) {
+			t.Errorf("migration %d is %s, want prefix %s", i, name, want)
+		}
+	}
+}
+
+func TestRunner_ApplyRecordsAndSkips(t *testing.T) {
+	dsn := os.Getenv("AMN_TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("AMN_TEST_DB_DSN not set; skipping database test")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	// Tables unique to this run, so it can share a database.
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	table, target := "migrations_test_"+suffix, "migrated_"+suffix
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+table+", "+target)
+	})
+	files := fstest.MapFS{
+		"001_create.sql": {Data: []byte("CREATE TABLE " + target + " (n INT)")},
+		"002_insert.sql": {Data: []byte("INSERT INTO " + target + " VALUES (1)")},
+	}
+	r := &Runner{FS: files, Table: table}
+
+	if got, err := r.Pending(ctx, pool); err != nil || len(got) != 2 {
+		t.Fatalf("Pending before the tracking table exists = %v, %v", got, err)
+	}
+	if got, err := r.Apply(ctx, pool); err != nil || !slices.Equal(got, []string{"001_create.sql", "002_insert.sql"}) {
+		t.Fatalf("first Apply = %v, %v", got, err)
+	}
+	if got, err := r.Pending(ctx, pool); err != nil || len(got) != 0 {
+		t.Fatalf("Pending after Apply = %v, %v", got, err)
+	}
+	if got, err := r.Apply(ctx, pool); err != nil || len(got) != 0 {
+		t.Fatalf("second Apply = %v, %v; want nothing to do", got, err)
+	}
+
+	// A failing migration rolls back with its tracking row and stops the run.
+	files["003_broken.sql"] = &fstest.MapFile{Data: []byte("INSERT INTO " + target + " VALUES (2); SELECT broken")}
+	files["004_after.sql"] = &fstest.MapFile{Data: []byte("INSERT INTO " + target + " VALUES (4)")}
+	if got, err := r.Apply(ctx, pool); err == nil || !strings.Contains(err.Error(), "003_broken.sql") || len(got) != 0 {
+		t.Fatalf("Apply with a broken migration = %v, %v", got, err)
+	}
+	var rows int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM "+target).Scan(&rows); err != nil || rows != 1 {
+		t.Errorf("rows after the failed migration = %d, %v; want 1", rows, err)
+	}
+
+	files["003_broken.sql"].Data = []byte("INSERT INTO " + target + " VALUES (3)")
+	if got, err := r.Apply(ctx, pool); err != nil || !slices.Equal(got, []string{"003_broken.sql", "004_after.sql"}) {
+		t.Errorf("Apply after the fix = %v, %v", got, err)
+	}
+}
+
+// A CREATE INDEX IF NOT EXISTS reusing the name of an index an earlier
+// migration created is a silent no-op on every database; the old index has
+// to be dropped first.
+func TestMigrations_IndexNamesNotShadowed(t *testing.T) {
+	names, err := NewRunner().Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	createRe := regexp.MustCompile(`(?i)CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+	dropRe := regexp.MustCompile(`(?i)DROP\s+INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+EXISTS\s+)?(\w+)`)
+	createdIn := map[string]string{}
+	for _, name := range names {
+		sql, err := fs.ReadFile(FS, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		type stmt struct {
+			at     int
+			index  string
+			create bool
+		}
+		var stmts []stmt
+		for _, m := range createRe.FindAllSubmatchIndex(sql, -1) {
+			stmts = append(stmts, stmt{m[0], string(sql[m[2]:m[3]]), true})
+		}
+		for _, m := range dropRe.FindAllSubmatchIndex(sql, -1) {
+			stmts = append(stmts, stmt{m[0], string(sql[m[2]:m[3]]), false})
+		}
+		slices.SortFunc(stmts, func(a, b stmt) int { return a.at - b.at })
+		for _, s := range stmts {
+			if !s.create {
+				delete(createdIn, s.index)
+				continue
+			}
+			if prev, ok := createdIn[s.index]; ok && prev != name {
+				t.Errorf("%s creates index %s, already created by %s and not dropped", name, s.index, prev)
+			}
+			createdIn[s.index] = name
+		}
+	}
+}